@@ -65,8 +65,23 @@ func main() {
 
 	// Initialize repository, service, and handler
 	projectRepo := repositories.NewProjectRepository(tenantDBManager)
-	projectService := services.NewProjectService(projectRepo, logger)
+	boardRepo := repositories.NewBoardRepository(tenantDBManager)
+	sprintRepo := repositories.NewSprintRepository(tenantDBManager)
+	worklogRepo := repositories.NewWorklogRepository(tenantDBManager)
+	milestoneRepo := repositories.NewMilestoneRepository(tenantDBManager)
+	templateRepo := repositories.NewTemplateRepository(tenantDBManager)
+	projectService := services.NewProjectService(projectRepo, boardRepo, logger)
+	boardService := services.NewBoardService(boardRepo, projectRepo, logger)
+	sprintService := services.NewSprintService(sprintRepo, projectRepo, logger)
+	timeService := services.NewTimeService(worklogRepo, projectRepo, logger)
+	milestoneService := services.NewMilestoneService(milestoneRepo, projectRepo, logger)
+	templateService := services.NewTemplateService(templateRepo, projectRepo, boardRepo, logger)
 	projectHandler := handlers.NewProjectHandler(projectService, logger)
+	boardHandler := handlers.NewBoardHandler(boardService, logger)
+	sprintHandler := handlers.NewSprintHandler(sprintService, logger)
+	timeHandler := handlers.NewTimeHandler(timeService, logger)
+	milestoneHandler := handlers.NewMilestoneHandler(milestoneService, logger)
+	templateHandler := handlers.NewTemplateHandler(templateService, logger)
 
 	// Initialize Gin router
 	router := gin.New()
@@ -114,6 +129,73 @@ func main() {
 		// Project statistics
 		projects.GET("/:id/stats", projectHandler.GetProjectStats)
 		projects.GET("/stats/user", projectHandler.GetUserProjectStats)
+		
+		// Kanban boards
+		projects.GET("/:id/boards", boardHandler.ListBoards)
+		projects.POST("/:id/boards", boardHandler.CreateBoard)
+		projects.GET("/:id/boards/:board_id", boardHandler.GetBoard)
+		projects.PUT("/:id/boards/:board_id", boardHandler.UpdateBoard)
+		projects.DELETE("/:id/boards/:board_id", boardHandler.DeleteBoard)
+		projects.GET("/:id/boards/:board_id/view", boardHandler.GetBoardView)
+		
+		// Scrum sprints
+		projects.GET("/:id/sprints", sprintHandler.ListSprints)
+		projects.POST("/:id/sprints", sprintHandler.CreateSprint)
+		projects.GET("/:id/sprints/:sprint_id", sprintHandler.GetSprint)
+		projects.PUT("/:id/sprints/:sprint_id", sprintHandler.UpdateSprint)
+		projects.DELETE("/:id/sprints/:sprint_id", sprintHandler.DeleteSprint)
+		projects.POST("/:id/sprints/:sprint_id/start", sprintHandler.StartSprint)
+		projects.POST("/:id/sprints/:sprint_id/complete", sprintHandler.CompleteSprint)
+		projects.POST("/:id/sprints/:sprint_id/tickets", sprintHandler.AddTickets)
+		projects.DELETE("/:id/sprints/:sprint_id/tickets/:ticket_id", sprintHandler.RemoveTicket)
+		projects.GET("/:id/sprints/:sprint_id/burndown", sprintHandler.GetBurndown)
+		projects.GET("/:id/backlog", sprintHandler.GetBacklog)
+		projects.GET("/:id/velocity", sprintHandler.GetVelocity)
+		
+		// Time tracking
+		projects.GET("/:id/tickets/:ticket_id/worklogs", timeHandler.ListWorklogs)
+		projects.POST("/:id/tickets/:ticket_id/worklogs", timeHandler.LogWork)
+		projects.PUT("/:id/tickets/:ticket_id/worklogs/:worklog_id", timeHandler.UpdateWorklog)
+		projects.DELETE("/:id/tickets/:ticket_id/worklogs/:worklog_id", timeHandler.DeleteWorklog)
+		projects.GET("/:id/time", timeHandler.GetProjectTime)
+		
+		// Milestones and roadmap
+		projects.GET("/:id/milestones", milestoneHandler.ListMilestones)
+		projects.POST("/:id/milestones", milestoneHandler.CreateMilestone)
+		projects.GET("/:id/milestones/:milestone_id", milestoneHandler.GetMilestone)
+		projects.PUT("/:id/milestones/:milestone_id", milestoneHandler.UpdateMilestone)
+		projects.DELETE("/:id/milestones/:milestone_id", milestoneHandler.DeleteMilestone)
+		projects.POST("/:id/milestones/:milestone_id/tickets", milestoneHandler.AddTickets)
+		projects.DELETE("/:id/milestones/:milestone_id/tickets/:ticket_id", milestoneHandler.RemoveTicket)
+		projects.GET("/:id/roadmap", milestoneHandler.GetRoadmap)
+		
+		// Templates and cloning
+		projects.POST("/:id/template", middleware.RequireManager(), templateHandler.SaveAsTemplate)
+		projects.POST("/:id/clone", middleware.RequireManager(), templateHandler.CloneProject)
+	}
+
+	// Timers and timesheets belong to the signed-in user
+	timeTracking := v1.Group("/time")
+	timeTracking.Use(middleware.AuthMiddleware(jwtService))
+	timeTracking.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	{
+		timeTracking.GET("/timer", timeHandler.GetTimer)
+		timeTracking.POST("/timer/start", timeHandler.StartTimer)
+		timeTracking.POST("/timer/stop", timeHandler.StopTimer)
+		timeTracking.DELETE("/timer", timeHandler.DiscardTimer)
+		timeTracking.GET("/timesheet", timeHandler.GetMyTimesheet)
+		timeTracking.GET("/timesheets/:user_id", middleware.RequireManager(), timeHandler.GetUserTimesheet)
+	}
+	templates := v1.Group("/project-templates")
+	templates.Use(middleware.AuthMiddleware(jwtService))
+	templates.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	{
+		templates.GET("/", templateHandler.ListTemplates)
+		templates.POST("/", middleware.RequireManager(), templateHandler.CreateTemplate)
+		templates.GET("/:template_id", templateHandler.GetTemplate)
+		templates.PUT("/:template_id", middleware.RequireManager(), templateHandler.UpdateTemplate)
+		templates.DELETE("/:template_id", middleware.RequireManager(), templateHandler.DeleteTemplate)
+		templates.POST("/:template_id/projects", templateHandler.CreateFromTemplate)
 	}
 
 	// Create HTTP server
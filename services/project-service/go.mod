@@ -8,6 +8,7 @@ require (
 	github.com/google/uuid v1.4.0
 	github.com/zen/shared v0.0.0
 	go.uber.org/zap v1.26.0
+	gorm.io/gorm v1.25.5
 )
 
 replace github.com/zen/shared => ../../shared
@@ -46,5 +47,4 @@ require (
 	google.golang.org/protobuf v1.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	gorm.io/driver/postgres v1.5.4 // indirect
-	gorm.io/gorm v1.25.5 // indirect
 )
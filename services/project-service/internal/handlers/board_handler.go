@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"project-service/internal/services"
+)
+
+type BoardHandler struct {
+	service services.BoardService
+	logger  *zap.Logger
+}
+
+func NewBoardHandler(service services.BoardService, logger *zap.Logger) *BoardHandler {
+	return &BoardHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Helper function to get user and tenant context
+func (h *BoardHandler) getUserAndTenantContext(c *gin.Context) (string, string, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return userID, tenantContext.TenantID, nil
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *BoardHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, "Access denied")
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Board not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.InternalServerErrorResponse(c, message)
+	}
+}
+
+// ListBoards handles GET /projects/:id/boards
+func (h *BoardHandler) ListBoards(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	boards, err := h.service.ListBoards(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list boards")
+		return
+	}
+
+	utils.SuccessResponse(c, boards, "Boards retrieved successfully")
+}
+
+// CreateBoard handles POST /projects/:id/boards
+func (h *BoardHandler) CreateBoard(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.BoardCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	board, err := h.service.CreateBoard(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create board")
+		return
+	}
+
+	utils.CreatedResponse(c, board, "Board created successfully")
+}
+
+// GetBoard handles GET /projects/:id/boards/:board_id
+func (h *BoardHandler) GetBoard(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	board, err := h.service.GetBoard(userID, tenantID, c.Param("id"), c.Param("board_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get board")
+		return
+	}
+
+	utils.SuccessResponse(c, board, "Board retrieved successfully")
+}
+
+// UpdateBoard handles PUT /projects/:id/boards/:board_id
+func (h *BoardHandler) UpdateBoard(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.BoardUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	board, err := h.service.UpdateBoard(userID, tenantID, c.Param("id"), c.Param("board_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update board")
+		return
+	}
+
+	utils.SuccessResponse(c, board, "Board updated successfully")
+}
+
+// DeleteBoard handles DELETE /projects/:id/boards/:board_id
+func (h *BoardHandler) DeleteBoard(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteBoard(userID, tenantID, c.Param("id"), c.Param("board_id")); err != nil {
+		h.respondError(c, err, "Failed to delete board")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Board deleted successfully")
+}
+
+// GetBoardView handles GET /projects/:id/boards/:board_id/view, the whole board in one
+// call. "limit" caps the cards returned per column (100 by default, at most 500).
+// Cards are moved through the ticket API, which checks WIP limits and the workflow.
+func (h *BoardHandler) GetBoardView(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	view, err := h.service.GetBoardView(userID, tenantID, c.Param("id"), c.Param("board_id"), limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to get board")
+		return
+	}
+
+	utils.SuccessResponse(c, view, "Board retrieved successfully")
+}
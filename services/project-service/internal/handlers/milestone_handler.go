@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"project-service/internal/services"
+)
+
+type MilestoneHandler struct {
+	service services.MilestoneService
+	logger  *zap.Logger
+}
+
+func NewMilestoneHandler(service services.MilestoneService, logger *zap.Logger) *MilestoneHandler {
+	return &MilestoneHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Helper function to get user and tenant context
+func (h *MilestoneHandler) getUserAndTenantContext(c *gin.Context) (string, string, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return userID, tenantContext.TenantID, nil
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *MilestoneHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, "Access denied")
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Milestone not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.InternalServerErrorResponse(c, message)
+	}
+}
+
+// ListMilestones handles GET /projects/:id/milestones
+func (h *MilestoneHandler) ListMilestones(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	milestones, err := h.service.ListMilestones(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list milestones")
+		return
+	}
+
+	utils.SuccessResponse(c, milestones, "Milestones retrieved successfully")
+}
+
+// CreateMilestone handles POST /projects/:id/milestones
+func (h *MilestoneHandler) CreateMilestone(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.MilestoneCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	milestone, err := h.service.CreateMilestone(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create milestone")
+		return
+	}
+
+	utils.CreatedResponse(c, milestone, "Milestone created successfully")
+}
+
+// GetMilestone handles GET /projects/:id/milestones/:milestone_id
+func (h *MilestoneHandler) GetMilestone(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	milestone, err := h.service.GetMilestone(userID, tenantID, c.Param("id"), c.Param("milestone_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get milestone")
+		return
+	}
+
+	utils.SuccessResponse(c, milestone, "Milestone retrieved successfully")
+}
+
+// UpdateMilestone handles PUT /projects/:id/milestones/:milestone_id
+func (h *MilestoneHandler) UpdateMilestone(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.MilestoneUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	milestone, err := h.service.UpdateMilestone(userID, tenantID, c.Param("id"), c.Param("milestone_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update milestone")
+		return
+	}
+
+	utils.SuccessResponse(c, milestone, "Milestone updated successfully")
+}
+
+// DeleteMilestone handles DELETE /projects/:id/milestones/:milestone_id
+func (h *MilestoneHandler) DeleteMilestone(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteMilestone(userID, tenantID, c.Param("id"), c.Param("milestone_id")); err != nil {
+		h.respondError(c, err, "Failed to delete milestone")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Milestone deleted successfully")
+}
+
+// AddTickets handles POST /projects/:id/milestones/:milestone_id/tickets
+func (h *MilestoneHandler) AddTickets(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.MilestoneTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.AddTickets(userID, tenantID, c.Param("id"), c.Param("milestone_id"), &req); err != nil {
+		h.respondError(c, err, "Failed to add tickets to milestone")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Tickets added to milestone successfully")
+}
+
+// RemoveTicket handles DELETE /projects/:id/milestones/:milestone_id/tickets/:ticket_id
+func (h *MilestoneHandler) RemoveTicket(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.RemoveTicket(userID, tenantID, c.Param("id"), c.Param("milestone_id"), c.Param("ticket_id")); err != nil {
+		h.respondError(c, err, "Failed to remove ticket from milestone")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Ticket removed from milestone successfully")
+}
+
+// GetRoadmap handles GET /projects/:id/roadmap, the timeline behind Gantt charts.
+// Dependencies are the tickets' "blocks" links, managed through the ticket API.
+func (h *MilestoneHandler) GetRoadmap(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	roadmap, err := h.service.GetRoadmap(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get roadmap")
+		return
+	}
+
+	utils.SuccessResponse(c, roadmap, "Roadmap retrieved successfully")
+}
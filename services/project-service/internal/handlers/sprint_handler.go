@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"project-service/internal/services"
+)
+
+type SprintHandler struct {
+	service services.SprintService
+	logger  *zap.Logger
+}
+
+func NewSprintHandler(service services.SprintService, logger *zap.Logger) *SprintHandler {
+	return &SprintHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Helper function to get user and tenant context
+func (h *SprintHandler) getUserAndTenantContext(c *gin.Context) (string, string, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return userID, tenantContext.TenantID, nil
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *SprintHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, "Access denied")
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Sprint not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.InternalServerErrorResponse(c, message)
+	}
+}
+
+// ListSprints handles GET /projects/:id/sprints, optionally filtered by "state"
+func (h *SprintHandler) ListSprints(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	state := tenant_models.SprintState(c.Query("state"))
+	sprints, err := h.service.ListSprints(userID, tenantID, c.Param("id"), state)
+	if err != nil {
+		h.respondError(c, err, "Failed to list sprints")
+		return
+	}
+
+	utils.SuccessResponse(c, sprints, "Sprints retrieved successfully")
+}
+
+// CreateSprint handles POST /projects/:id/sprints
+func (h *SprintHandler) CreateSprint(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.SprintCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	sprint, err := h.service.CreateSprint(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create sprint")
+		return
+	}
+
+	utils.CreatedResponse(c, sprint, "Sprint created successfully")
+}
+
+// GetSprint handles GET /projects/:id/sprints/:sprint_id, the sprint with its tickets
+func (h *SprintHandler) GetSprint(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	view, err := h.service.GetSprint(userID, tenantID, c.Param("id"), c.Param("sprint_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get sprint")
+		return
+	}
+
+	utils.SuccessResponse(c, view, "Sprint retrieved successfully")
+}
+
+// UpdateSprint handles PUT /projects/:id/sprints/:sprint_id
+func (h *SprintHandler) UpdateSprint(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.SprintUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	sprint, err := h.service.UpdateSprint(userID, tenantID, c.Param("id"), c.Param("sprint_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update sprint")
+		return
+	}
+
+	utils.SuccessResponse(c, sprint, "Sprint updated successfully")
+}
+
+// DeleteSprint handles DELETE /projects/:id/sprints/:sprint_id
+func (h *SprintHandler) DeleteSprint(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteSprint(userID, tenantID, c.Param("id"), c.Param("sprint_id")); err != nil {
+		h.respondError(c, err, "Failed to delete sprint")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Sprint deleted successfully")
+}
+
+// StartSprint handles POST /projects/:id/sprints/:sprint_id/start
+func (h *SprintHandler) StartSprint(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	// The body is optional; the planned dates apply without one
+	var req tenant_models.SprintStartRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	sprint, err := h.service.StartSprint(userID, tenantID, c.Param("id"), c.Param("sprint_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to start sprint")
+		return
+	}
+
+	utils.SuccessResponse(c, sprint, "Sprint started successfully")
+}
+
+// CompleteSprint handles POST /projects/:id/sprints/:sprint_id/complete. Unfinished
+// tickets move to "carry_over_sprint_id", or to the backlog without one.
+func (h *SprintHandler) CompleteSprint(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.SprintCompleteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	sprint, err := h.service.CompleteSprint(userID, tenantID, c.Param("id"), c.Param("sprint_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to complete sprint")
+		return
+	}
+
+	utils.SuccessResponse(c, sprint, "Sprint completed successfully")
+}
+
+// AddTickets handles POST /projects/:id/sprints/:sprint_id/tickets
+func (h *SprintHandler) AddTickets(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.SprintTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.AddTickets(userID, tenantID, c.Param("id"), c.Param("sprint_id"), &req); err != nil {
+		h.respondError(c, err, "Failed to add tickets to sprint")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Tickets added to sprint successfully")
+}
+
+// RemoveTicket handles DELETE /projects/:id/sprints/:sprint_id/tickets/:ticket_id
+func (h *SprintHandler) RemoveTicket(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.RemoveTicket(userID, tenantID, c.Param("id"), c.Param("sprint_id"), c.Param("ticket_id")); err != nil {
+		h.respondError(c, err, "Failed to remove ticket from sprint")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Ticket moved to the backlog successfully")
+}
+
+// GetBacklog handles GET /projects/:id/backlog
+func (h *SprintHandler) GetBacklog(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200 // Cap at 200
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	cards, total, err := h.service.GetBacklog(userID, tenantID, c.Param("id"), limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to get backlog")
+		return
+	}
+
+	pagination := utils.Pagination{
+		Page:       (offset / limit) + 1,
+		PerPage:    limit,
+		Total:      total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	}
+
+	utils.PaginatedSuccessResponse(c, cards, pagination, "Backlog retrieved successfully")
+}
+
+// GetBurndown handles GET /projects/:id/sprints/:sprint_id/burndown, the daily series
+// behind both the burndown and the burnup chart
+func (h *SprintHandler) GetBurndown(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	burndown, err := h.service.GetBurndown(userID, tenantID, c.Param("id"), c.Param("sprint_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get sprint burndown")
+		return
+	}
+
+	utils.SuccessResponse(c, burndown, "Sprint burndown retrieved successfully")
+}
+
+// GetVelocity handles GET /projects/:id/velocity over the last "sprints" completed
+// sprints (5 by default, at most 20)
+func (h *SprintHandler) GetVelocity(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	sprints, _ := strconv.Atoi(c.Query("sprints"))
+
+	velocity, err := h.service.GetVelocity(userID, tenantID, c.Param("id"), sprints)
+	if err != nil {
+		h.respondError(c, err, "Failed to get velocity")
+		return
+	}
+
+	utils.SuccessResponse(c, velocity, "Velocity retrieved successfully")
+}
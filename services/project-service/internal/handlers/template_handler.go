@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"project-service/internal/services"
+)
+
+type TemplateHandler struct {
+	service services.TemplateService
+	logger  *zap.Logger
+}
+
+func NewTemplateHandler(service services.TemplateService, logger *zap.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Helper function to get user and tenant context
+func (h *TemplateHandler) getUserAndTenantContext(c *gin.Context) (string, string, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return userID, tenantContext.TenantID, nil
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *TemplateHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, "Access denied")
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Template not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.InternalServerErrorResponse(c, message)
+	}
+}
+
+// ListTemplates handles GET /project-templates
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	templates, err := h.service.ListTemplates(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list templates")
+		return
+	}
+
+	utils.SuccessResponse(c, templates, "Templates retrieved successfully")
+}
+
+// CreateTemplate handles POST /project-templates
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.ProjectTemplateCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	template, err := h.service.CreateTemplate(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create template")
+		return
+	}
+
+	utils.CreatedResponse(c, template, "Template created successfully")
+}
+
+// GetTemplate handles GET /project-templates/:template_id
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	template, err := h.service.GetTemplate(userID, tenantID, c.Param("template_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get template")
+		return
+	}
+
+	utils.SuccessResponse(c, template, "Template retrieved successfully")
+}
+
+// UpdateTemplate handles PUT /project-templates/:template_id
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.ProjectTemplateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(userID, tenantID, c.Param("template_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update template")
+		return
+	}
+
+	utils.SuccessResponse(c, template, "Template updated successfully")
+}
+
+// DeleteTemplate handles DELETE /project-templates/:template_id
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteTemplate(userID, tenantID, c.Param("template_id")); err != nil {
+		h.respondError(c, err, "Failed to delete template")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Template deleted successfully")
+}
+
+// CreateFromTemplate handles POST /project-templates/:template_id/projects
+func (h *TemplateHandler) CreateFromTemplate(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.ProjectFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	project, err := h.service.CreateFromTemplate(userID, tenantID, c.Param("template_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create project from template")
+		return
+	}
+
+	utils.CreatedResponse(c, project, "Project created successfully")
+}
+
+// SaveAsTemplate handles POST /projects/:id/template
+func (h *TemplateHandler) SaveAsTemplate(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.ProjectTemplateSaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	template, err := h.service.SaveAsTemplate(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to save project as template")
+		return
+	}
+
+	utils.CreatedResponse(c, template, "Template created successfully")
+}
+
+// CloneProject handles POST /projects/:id/clone
+func (h *TemplateHandler) CloneProject(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.ProjectCloneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	project, err := h.service.CloneProject(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to clone project")
+		return
+	}
+
+	utils.CreatedResponse(c, project, "Project cloned successfully")
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"project-service/internal/services"
+)
+
+type TimeHandler struct {
+	service services.TimeService
+	logger  *zap.Logger
+}
+
+func NewTimeHandler(service services.TimeService, logger *zap.Logger) *TimeHandler {
+	return &TimeHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Helper function to get user and tenant context
+func (h *TimeHandler) getUserAndTenantContext(c *gin.Context) (string, string, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return userID, tenantContext.TenantID, nil
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *TimeHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, "Access denied")
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Ticket or worklog not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.InternalServerErrorResponse(c, message)
+	}
+}
+
+// ListWorklogs handles GET /projects/:id/tickets/:ticket_id/worklogs
+func (h *TimeHandler) ListWorklogs(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	worklogs, err := h.service.ListWorklogs(userID, tenantID, c.Param("id"), c.Param("ticket_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list worklogs")
+		return
+	}
+
+	utils.SuccessResponse(c, worklogs, "Worklogs retrieved successfully")
+}
+
+// LogWork handles POST /projects/:id/tickets/:ticket_id/worklogs
+func (h *TimeHandler) LogWork(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.WorklogCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	worklog, err := h.service.LogWork(userID, tenantID, c.Param("id"), c.Param("ticket_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to log work")
+		return
+	}
+
+	utils.CreatedResponse(c, worklog, "Work logged successfully")
+}
+
+// UpdateWorklog handles PUT /projects/:id/tickets/:ticket_id/worklogs/:worklog_id
+func (h *TimeHandler) UpdateWorklog(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.WorklogUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	worklog, err := h.service.UpdateWorklog(userID, tenantID, c.Param("id"), c.Param("ticket_id"), c.Param("worklog_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update worklog")
+		return
+	}
+
+	utils.SuccessResponse(c, worklog, "Worklog updated successfully")
+}
+
+// DeleteWorklog handles DELETE /projects/:id/tickets/:ticket_id/worklogs/:worklog_id
+func (h *TimeHandler) DeleteWorklog(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteWorklog(userID, tenantID, c.Param("id"), c.Param("ticket_id"), c.Param("worklog_id")); err != nil {
+		h.respondError(c, err, "Failed to delete worklog")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Worklog deleted successfully")
+}
+
+// GetProjectTime handles GET /projects/:id/time, the project's logged time against its
+// estimates. "from" and "to" (YYYY-MM-DD, to inclusive) limit the logged time.
+func (h *TimeHandler) GetProjectTime(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var from, to *time.Time
+	if fromStr := c.Query("from"); fromStr != "" {
+		date, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "from must be a date (YYYY-MM-DD)")
+			return
+		}
+		from = &date
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		date, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "to must be a date (YYYY-MM-DD)")
+			return
+		}
+		date = date.AddDate(0, 0, 1)
+		to = &date
+	}
+
+	rollup, err := h.service.GetProjectTime(userID, tenantID, c.Param("id"), from, to)
+	if err != nil {
+		h.respondError(c, err, "Failed to get project time")
+		return
+	}
+
+	utils.SuccessResponse(c, rollup, "Project time retrieved successfully")
+}
+
+// GetTimer handles GET /time/timer; the data is null when no timer is running
+func (h *TimeHandler) GetTimer(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	timer, err := h.service.GetTimer(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get timer")
+		return
+	}
+
+	utils.SuccessResponse(c, timer, "Timer retrieved successfully")
+}
+
+// StartTimer handles POST /time/timer/start
+func (h *TimeHandler) StartTimer(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.TimerStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	timer, err := h.service.StartTimer(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to start timer")
+		return
+	}
+
+	utils.SuccessResponse(c, timer, "Timer started successfully")
+}
+
+// StopTimer handles POST /time/timer/stop and returns the logged worklog
+func (h *TimeHandler) StopTimer(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	worklog, err := h.service.StopTimer(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to stop timer")
+		return
+	}
+
+	utils.SuccessResponse(c, worklog, "Timer stopped successfully")
+}
+
+// DiscardTimer handles DELETE /time/timer
+func (h *TimeHandler) DiscardTimer(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DiscardTimer(userID, tenantID); err != nil {
+		h.respondError(c, err, "Failed to discard timer")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Timer discarded successfully")
+}
+
+// GetMyTimesheet handles GET /time/timesheet for the week containing "week"
+// (YYYY-MM-DD, this week by default)
+func (h *TimeHandler) GetMyTimesheet(c *gin.Context) {
+	userID, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	h.timesheet(c, tenantID, userID)
+}
+
+// GetUserTimesheet handles GET /time/timesheets/:user_id for managers
+func (h *TimeHandler) GetUserTimesheet(c *gin.Context) {
+	_, tenantID, err := h.getUserAndTenantContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	h.timesheet(c, tenantID, c.Param("user_id"))
+}
+
+func (h *TimeHandler) timesheet(c *gin.Context, tenantID, userID string) {
+	week := time.Now()
+	if weekStr := c.Query("week"); weekStr != "" {
+		date, err := time.Parse("2006-01-02", weekStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "week must be a date (YYYY-MM-DD)")
+			return
+		}
+		week = date
+	}
+
+	timesheet, err := h.service.GetTimesheet(tenantID, userID, week)
+	if err != nil {
+		h.respondError(c, err, "Failed to get timesheet")
+		return
+	}
+
+	utils.SuccessResponse(c, timesheet, "Timesheet retrieved successfully")
+}
@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+type BoardRepository interface {
+	// Board CRUD; columns are written with their board
+	CreateBoard(tenantID string, board *tenant_models.Board) error
+	GetBoard(tenantID, projectID, boardID string) (*tenant_models.Board, error)
+	ListBoards(tenantID, projectID string) ([]*tenant_models.Board, error)
+	UpdateBoard(tenantID string, board *tenant_models.Board) error
+	DeleteBoard(tenantID, projectID, boardID string) error
+
+	// Board contents
+	ColumnCards(tenantID, projectID string, statuses []string, viewer TicketViewer, limit int) ([]*tenant_models.Ticket, int64, error)
+	GetTicketTitles(tenantID string, ticketIDs []string, viewer TicketViewer) (map[string]string, error)
+	CustomStatusNames(tenantID string) ([]string, error)
+}
+
+type boardRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewBoardRepository(tenantDBManager *database.TenantDatabaseManager) BoardRepository {
+	return &boardRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+// CreateBoard stores the board with its columns; a default board takes over from the
+// project's previous one
+func (r *boardRepository) CreateBoard(tenantID string, board *tenant_models.Board) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(board).Error; err != nil {
+			return err
+		}
+		return clearOtherDefaults(tx, board)
+	})
+}
+
+func (r *boardRepository) GetBoard(tenantID, projectID, boardID string) (*tenant_models.Board, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var board tenant_models.Board
+	err = db.Preload("Columns", orderColumns).
+		Where("id = ? AND project_id = ?", boardID, projectID).
+		First(&board).Error
+	if err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+func (r *boardRepository) ListBoards(tenantID, projectID string) ([]*tenant_models.Board, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var boards []*tenant_models.Board
+	err = db.Preload("Columns", orderColumns).
+		Where("project_id = ?", projectID).
+		Order("is_default DESC, name ASC").
+		Find(&boards).Error
+	return boards, err
+}
+
+// UpdateBoard saves the board and replaces its columns: columns keeping their ID are
+// updated, new ones created and the rest removed
+func (r *boardRepository) UpdateBoard(tenantID string, board *tenant_models.Board) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Columns").Save(board).Error; err != nil {
+			return err
+		}
+
+		keep := make([]string, 0, len(board.Columns))
+		for i := range board.Columns {
+			column := &board.Columns[i]
+			column.BoardID = board.ID
+			if column.ID == "" {
+				if err := tx.Create(column).Error; err != nil {
+					return err
+				}
+			} else if err := tx.Save(column).Error; err != nil {
+				return err
+			}
+			keep = append(keep, column.ID)
+		}
+		if err := tx.Where("board_id = ? AND id NOT IN ?", board.ID, keep).Delete(&tenant_models.BoardColumn{}).Error; err != nil {
+			return err
+		}
+
+		return clearOtherDefaults(tx, board)
+	})
+}
+
+// DeleteBoard removes the board and its columns. When it was the project's default,
+// the oldest remaining board becomes the default.
+func (r *boardRepository) DeleteBoard(tenantID, projectID, boardID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var board tenant_models.Board
+		if err := tx.Where("id = ? AND project_id = ?", boardID, projectID).First(&board).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("board_id = ?", boardID).Delete(&tenant_models.BoardColumn{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&board).Error; err != nil {
+			return err
+		}
+		if !board.IsDefault {
+			return nil
+		}
+
+		var next tenant_models.Board
+		err := tx.Where("project_id = ?", projectID).Order("created_at ASC").First(&next).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+// ColumnCards returns the first limit tickets of the project in the statuses that show to
+// the viewer in board order, with how many there are in all. Tickets not ranked yet come last.
+func (r *boardRepository) ColumnCards(tenantID, projectID string, statuses []string, viewer TicketViewer, limit int) ([]*tenant_models.Ticket, int64, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := viewer.scope(db.Model(&tenant_models.Ticket{}).
+		Where("project_id = ? AND status IN ?", projectID, statuses))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tickets []*tenant_models.Ticket
+	err = query.Order("board_rank = '' ASC, board_rank ASC, ticket_number ASC").
+		Limit(limit).
+		Find(&tickets).Error
+	return tickets, total, err
+}
+
+// GetTicketTitles leaves out the tickets that do not show to the viewer
+func (r *boardRepository) GetTicketTitles(tenantID string, ticketIDs []string, viewer TicketViewer) (map[string]string, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[string]string, len(ticketIDs))
+	if len(ticketIDs) == 0 {
+		return titles, nil
+	}
+
+	var tickets []tenant_models.Ticket
+	err = viewer.scope(db.Select("id, title").Where("id IN ?", ticketIDs)).Find(&tickets).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, ticket := range tickets {
+		titles[ticket.ID] = ticket.Title
+	}
+	return titles, nil
+}
+
+// CustomStatusNames lists the tenant's active custom statuses
+func (r *boardRepository) CustomStatusNames(tenantID string) ([]string, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	err = db.Model(&tenant_models.CustomStatus{}).
+		Where("is_active = ?", true).
+		Pluck("name", &names).Error
+	return names, err
+}
+
+func orderColumns(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// clearOtherDefaults keeps a single default board per project
+func clearOtherDefaults(tx *gorm.DB, board *tenant_models.Board) error {
+	if !board.IsDefault {
+		return nil
+	}
+	return tx.Model(&tenant_models.Board{}).
+		Where("project_id = ? AND id <> ?", board.ProjectID, board.ID).
+		Update("is_default", false).Error
+}
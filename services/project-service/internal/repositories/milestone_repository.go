@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// MilestoneTickets sums up the tickets of one milestone
+type MilestoneTickets struct {
+	MilestoneID        string
+	TotalTickets       int
+	DoneTickets        int
+	EstimatedHours     float64
+	DoneEstimatedHours float64
+}
+
+type MilestoneRepository interface {
+	// Milestone CRUD
+	CreateMilestone(tenantID string, milestone *tenant_models.Milestone) error
+	GetMilestone(tenantID, projectID, milestoneID string) (*tenant_models.Milestone, error)
+	ListMilestones(tenantID, projectID string) ([]*tenant_models.Milestone, error)
+	UpdateMilestone(tenantID string, milestone *tenant_models.Milestone) error
+	DeleteMilestone(tenantID string, milestone *tenant_models.Milestone, actorID string) error
+
+	// Milestone tickets. Attaching records a milestone_id history entry for each.
+	ProjectTickets(tenantID, projectID string, ticketIDs []string) ([]*tenant_models.Ticket, error)
+	AttachTickets(tenantID string, tickets []*tenant_models.Ticket, milestoneID *string, actorID string) error
+	MilestoneTickets(tenantID, projectID string, doneStatuses []string) (map[string]*MilestoneTickets, error)
+	DoneStatuses(tenantID string) ([]string, error)
+
+	// Roadmap
+	ScheduledTickets(tenantID, projectID string, viewer TicketViewer) ([]*tenant_models.Ticket, error)
+	CountUnscheduled(tenantID, projectID string, doneStatuses []string) (int64, error)
+	Dependencies(tenantID string, ticketIDs []string) ([]*tenant_models.TicketLink, error)
+}
+
+type milestoneRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewMilestoneRepository(tenantDBManager *database.TenantDatabaseManager) MilestoneRepository {
+	return &milestoneRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *milestoneRepository) CreateMilestone(tenantID string, milestone *tenant_models.Milestone) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(milestone).Error
+}
+
+func (r *milestoneRepository) GetMilestone(tenantID, projectID, milestoneID string) (*tenant_models.Milestone, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestone tenant_models.Milestone
+	err = db.Where("id = ? AND project_id = ?", milestoneID, projectID).First(&milestone).Error
+	if err != nil {
+		return nil, err
+	}
+	return &milestone, nil
+}
+
+// ListMilestones returns the project's milestones by target date
+func (r *milestoneRepository) ListMilestones(tenantID, projectID string) ([]*tenant_models.Milestone, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestones []*tenant_models.Milestone
+	err = db.Where("project_id = ?", projectID).Order("target_date ASC, name ASC").Find(&milestones).Error
+	return milestones, err
+}
+
+func (r *milestoneRepository) UpdateMilestone(tenantID string, milestone *tenant_models.Milestone) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(milestone).Error
+}
+
+// DeleteMilestone removes the milestone and detaches its tickets
+func (r *milestoneRepository) DeleteMilestone(tenantID string, milestone *tenant_models.Milestone, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var tickets []*tenant_models.Ticket
+		if err := tx.Where("milestone_id = ?", milestone.ID).Find(&tickets).Error; err != nil {
+			return err
+		}
+		if err := assignTickets(tx, tickets, "milestone_id", nil, actorID); err != nil {
+			return err
+		}
+		return tx.Delete(milestone).Error
+	})
+}
+
+func (r *milestoneRepository) ProjectTickets(tenantID, projectID string, ticketIDs []string) ([]*tenant_models.Ticket, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*tenant_models.Ticket
+	err = db.Where("project_id = ? AND id IN ?", projectID, ticketIDs).Find(&tickets).Error
+	return tickets, err
+}
+
+// AttachTickets puts the tickets in the milestone, or takes them out when milestoneID is nil
+func (r *milestoneRepository) AttachTickets(tenantID string, tickets []*tenant_models.Ticket, milestoneID *string, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return assignTickets(tx, tickets, "milestone_id", milestoneID, actorID)
+	})
+}
+
+// MilestoneTickets sums up the tickets of each of the project's milestones
+func (r *milestoneRepository) MilestoneTickets(tenantID, projectID string, doneStatuses []string) (map[string]*MilestoneTickets, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*MilestoneTickets
+	err = db.Model(&tenant_models.Ticket{}).
+		Select(`milestone_id,
+			COUNT(*) AS total_tickets,
+			COUNT(*) FILTER (WHERE status IN ?) AS done_tickets,
+			COALESCE(SUM(estimated_hours), 0) AS estimated_hours,
+			COALESCE(SUM(estimated_hours) FILTER (WHERE status IN ?), 0) AS done_estimated_hours`, doneStatuses, doneStatuses).
+		Where("project_id = ? AND milestone_id IS NOT NULL", projectID).
+		Group("milestone_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]*MilestoneTickets, len(rows))
+	for _, row := range rows {
+		totals[row.MilestoneID] = row
+	}
+	return totals, nil
+}
+
+func (r *milestoneRepository) DoneStatuses(tenantID string) ([]string, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return doneStatuses(db)
+}
+
+// ScheduledTickets returns the project's tickets with a start or a due date that show to
+// the viewer
+func (r *milestoneRepository) ScheduledTickets(tenantID, projectID string, viewer TicketViewer) ([]*tenant_models.Ticket, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*tenant_models.Ticket
+	err = viewer.scope(db.Where("project_id = ? AND (start_date IS NOT NULL OR due_date IS NOT NULL)", projectID)).
+		Order("ticket_number ASC").
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// CountUnscheduled counts the project's unfinished tickets with neither a start nor a
+// due date
+func (r *milestoneRepository) CountUnscheduled(tenantID, projectID string, doneStatuses []string) (int64, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Model(&tenant_models.Ticket{}).
+		Where("project_id = ? AND start_date IS NULL AND due_date IS NULL AND status NOT IN ?", projectID, doneStatuses).
+		Count(&count).Error
+	return count, err
+}
+
+// Dependencies returns the "blocks" links between the tickets
+func (r *milestoneRepository) Dependencies(tenantID string, ticketIDs []string) ([]*tenant_models.TicketLink, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []*tenant_models.TicketLink
+	if len(ticketIDs) == 0 {
+		return links, nil
+	}
+	err = db.Where("link_type = ? AND source_ticket_id IN ? AND target_ticket_id IN ?", tenant_models.LinkTypeBlocks, ticketIDs, ticketIDs).
+		Find(&links).Error
+	return links, err
+}
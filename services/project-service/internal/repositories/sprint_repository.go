@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+type SprintRepository interface {
+	// Sprint CRUD
+	CreateSprint(tenantID string, sprint *tenant_models.Sprint) error
+	GetSprint(tenantID, projectID, sprintID string) (*tenant_models.Sprint, error)
+	ListSprints(tenantID, projectID string, state tenant_models.SprintState) ([]*tenant_models.Sprint, error)
+	UpdateSprint(tenantID string, sprint *tenant_models.Sprint) error
+	DeleteSprint(tenantID string, sprint *tenant_models.Sprint, actorID string) error
+	ActiveSprint(tenantID, projectID string) (*tenant_models.Sprint, error)
+	CompletedSprints(tenantID, projectID string, limit int) ([]*tenant_models.Sprint, error)
+
+	// Sprint contents. Moving tickets records a sprint_id history entry for each.
+	SprintTickets(tenantID, sprintID string) ([]*tenant_models.Ticket, error)
+	BacklogTickets(tenantID, projectID string, doneStatuses []string, viewer TicketViewer, limit, offset int) ([]*tenant_models.Ticket, int64, error)
+	ProjectTickets(tenantID, projectID string, ticketIDs []string) ([]*tenant_models.Ticket, error)
+	MoveTickets(tenantID string, tickets []*tenant_models.Ticket, sprintID *string, actorID string) error
+	CompleteSprint(tenantID string, sprint *tenant_models.Sprint, carryOver []*tenant_models.Ticket, target *string, actorID string) error
+	DoneStatuses(tenantID string) ([]string, error)
+
+	// Sprint history for burndown charts
+	ScopeTickets(tenantID, sprintID string) ([]*tenant_models.Ticket, error)
+	TicketHistory(tenantID string, ticketIDs, fields []string) ([]*tenant_models.TicketHistory, error)
+}
+
+type sprintRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewSprintRepository(tenantDBManager *database.TenantDatabaseManager) SprintRepository {
+	return &sprintRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *sprintRepository) CreateSprint(tenantID string, sprint *tenant_models.Sprint) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(sprint).Error
+}
+
+func (r *sprintRepository) GetSprint(tenantID, projectID, sprintID string) (*tenant_models.Sprint, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sprint tenant_models.Sprint
+	err = db.Where("id = ? AND project_id = ?", sprintID, projectID).First(&sprint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+// ListSprints returns the project's sprints in the state, or all of them when state is
+// empty, in the order they were planned
+func (r *sprintRepository) ListSprints(tenantID, projectID string, state tenant_models.SprintState) ([]*tenant_models.Sprint, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Where("project_id = ?", projectID)
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+
+	var sprints []*tenant_models.Sprint
+	err = query.Order("start_date IS NULL ASC, start_date ASC, created_at ASC").Find(&sprints).Error
+	return sprints, err
+}
+
+func (r *sprintRepository) UpdateSprint(tenantID string, sprint *tenant_models.Sprint) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(sprint).Error
+}
+
+// DeleteSprint removes the sprint and returns its tickets to the backlog
+func (r *sprintRepository) DeleteSprint(tenantID string, sprint *tenant_models.Sprint, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var tickets []*tenant_models.Ticket
+		if err := tx.Where("sprint_id = ?", sprint.ID).Find(&tickets).Error; err != nil {
+			return err
+		}
+		if err := assignTickets(tx, tickets, "sprint_id", nil, actorID); err != nil {
+			return err
+		}
+		return tx.Delete(sprint).Error
+	})
+}
+
+func (r *sprintRepository) ActiveSprint(tenantID, projectID string) (*tenant_models.Sprint, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sprint tenant_models.Sprint
+	err = db.Where("project_id = ? AND state = ?", projectID, tenant_models.SprintActive).First(&sprint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+// CompletedSprints returns the project's last limit completed sprints, newest first
+func (r *sprintRepository) CompletedSprints(tenantID, projectID string, limit int) ([]*tenant_models.Sprint, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sprints []*tenant_models.Sprint
+	err = db.Where("project_id = ? AND state = ?", projectID, tenant_models.SprintCompleted).
+		Order("completed_at DESC").
+		Limit(limit).
+		Find(&sprints).Error
+	return sprints, err
+}
+
+// SprintTickets returns the sprint's tickets in board order
+func (r *sprintRepository) SprintTickets(tenantID, sprintID string) ([]*tenant_models.Ticket, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*tenant_models.Ticket
+	err = db.Where("sprint_id = ?", sprintID).
+		Order("board_rank = '' ASC, board_rank ASC, ticket_number ASC").
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// BacklogTickets returns the project's unfinished tickets outside any open sprint that
+// show to the viewer in board order, with how many there are in all. Tickets left behind in a completed
+// sprint, such as ones reopened later, are back in the backlog.
+func (r *sprintRepository) BacklogTickets(tenantID, projectID string, doneStatuses []string, viewer TicketViewer, limit, offset int) ([]*tenant_models.Ticket, int64, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	completed := db.Model(&tenant_models.Sprint{}).
+		Select("id").
+		Where("project_id = ? AND state = ?", projectID, tenant_models.SprintCompleted)
+	query := viewer.scope(db.Model(&tenant_models.Ticket{}).
+		Where("project_id = ? AND status NOT IN ?", projectID, doneStatuses).
+		Where("sprint_id IS NULL OR sprint_id IN (?)", completed))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tickets []*tenant_models.Ticket
+	err = query.Order("board_rank = '' ASC, board_rank ASC, ticket_number ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&tickets).Error
+	return tickets, total, err
+}
+
+func (r *sprintRepository) ProjectTickets(tenantID, projectID string, ticketIDs []string) ([]*tenant_models.Ticket, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*tenant_models.Ticket
+	err = db.Where("project_id = ? AND id IN ?", projectID, ticketIDs).Find(&tickets).Error
+	return tickets, err
+}
+
+// MoveTickets puts the tickets in the sprint, or in the backlog when sprintID is nil
+func (r *sprintRepository) MoveTickets(tenantID string, tickets []*tenant_models.Ticket, sprintID *string, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return assignTickets(tx, tickets, "sprint_id", sprintID, actorID)
+	})
+}
+
+// CompleteSprint saves the completed sprint and moves its unfinished tickets to the
+// target sprint, or to the backlog when target is nil
+func (r *sprintRepository) CompleteSprint(tenantID string, sprint *tenant_models.Sprint, carryOver []*tenant_models.Ticket, target *string, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(sprint).Error; err != nil {
+			return err
+		}
+		return assignTickets(tx, carryOver, "sprint_id", target, actorID)
+	})
+}
+
+// DoneStatuses lists the statuses that count as finished work: resolved and closed,
+// and the tenant's custom statuses in those categories
+func (r *sprintRepository) DoneStatuses(tenantID string) ([]string, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return doneStatuses(db)
+}
+
+// ScopeTickets returns every ticket that has been in the sprint, deleted ones included
+func (r *sprintRepository) ScopeTickets(tenantID, sprintID string) ([]*tenant_models.Ticket, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := db.Model(&tenant_models.TicketHistory{}).
+		Select("ticket_id").
+		Where("field_name = ? AND (old_value = ? OR new_value = ?)", "sprint_id", sprintID, sprintID)
+
+	var tickets []*tenant_models.Ticket
+	err = db.Unscoped().
+		Where("sprint_id = ? OR id IN (?)", sprintID, moved).
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// TicketHistory returns the tickets' changes to the fields, oldest first
+func (r *sprintRepository) TicketHistory(tenantID string, ticketIDs, fields []string) ([]*tenant_models.TicketHistory, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*tenant_models.TicketHistory
+	if len(ticketIDs) == 0 {
+		return history, nil
+	}
+	err = db.Where("ticket_id IN ? AND field_name IN ?", ticketIDs, fields).
+		Order("changed_at ASC").
+		Find(&history).Error
+	return history, err
+}
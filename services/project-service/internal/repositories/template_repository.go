@@ -0,0 +1,291 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// ProjectSetup is what a new project starts with besides itself. Statuses and ticket
+// types are tenant-wide and only created when the tenant has none of that name.
+type ProjectSetup struct {
+	Statuses    []*tenant_models.CustomStatus
+	TicketTypes []*tenant_models.CustomTicketType
+	Members     []*tenant_models.ProjectMember
+	Boards      []*tenant_models.Board
+	Tickets     []*tenant_models.Ticket
+	Links       []*tenant_models.TicketLink
+}
+
+type TemplateRepository interface {
+	// Template CRUD
+	CreateTemplate(tenantID string, template *tenant_models.ProjectTemplate) error
+	GetTemplate(tenantID, templateID string) (*tenant_models.ProjectTemplate, error)
+	ListTemplates(tenantID string) ([]*tenant_models.ProjectTemplate, error)
+	UpdateTemplate(tenantID string, template *tenant_models.ProjectTemplate) error
+	DeleteTemplate(tenantID, templateID string) error
+	TemplateNameTaken(tenantID, name, exceptID string) (bool, error)
+
+	// What a project is made of, for saving it as a template
+	ProjectLabels(tenantID, projectID string) ([]string, error)
+	ProjectStatuses(tenantID, projectID string, boardStatuses []string) ([]*tenant_models.CustomStatus, error)
+	ProjectTicketTypes(tenantID, projectID string) ([]*tenant_models.CustomTicketType, error)
+	OpenTickets(tenantID, projectID string) ([]*tenant_models.Ticket, error)
+	TicketLinks(tenantID string, ticketIDs []string) ([]*tenant_models.TicketLink, error)
+
+	// CreateProject creates the project and its setup in one transaction
+	CreateProject(tenantID string, project *tenant_models.Project, setup *ProjectSetup, actorID string) error
+}
+
+type templateRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewTemplateRepository(tenantDBManager *database.TenantDatabaseManager) TemplateRepository {
+	return &templateRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *templateRepository) CreateTemplate(tenantID string, template *tenant_models.ProjectTemplate) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(template).Error
+}
+
+func (r *templateRepository) GetTemplate(tenantID, templateID string) (*tenant_models.ProjectTemplate, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var template tenant_models.ProjectTemplate
+	err = db.Where("id = ?", templateID).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *templateRepository) ListTemplates(tenantID string) ([]*tenant_models.ProjectTemplate, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []*tenant_models.ProjectTemplate
+	err = db.Order("name ASC").Find(&templates).Error
+	return templates, err
+}
+
+func (r *templateRepository) UpdateTemplate(tenantID string, template *tenant_models.ProjectTemplate) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(template).Error
+}
+
+func (r *templateRepository) DeleteTemplate(tenantID, templateID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	result := db.Delete(&tenant_models.ProjectTemplate{}, "id = ?", templateID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TemplateNameTaken reports whether a template other than exceptID has the name
+func (r *templateRepository) TemplateNameTaken(tenantID, name, exceptID string) (bool, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	query := db.Model(&tenant_models.ProjectTemplate{}).Where("name = ?", name)
+	if exceptID != "" {
+		query = query.Where("id <> ?", exceptID)
+	}
+	var count int64
+	err = query.Count(&count).Error
+	return count > 0, err
+}
+
+// ProjectLabels returns the project's labels and every label its tickets carry
+func (r *templateRepository) ProjectLabels(tenantID, projectID string) ([]string, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	err = db.Raw(`SELECT label FROM (
+			SELECT jsonb_array_elements_text(labels) AS label FROM projects WHERE id = ?
+			UNION
+			SELECT jsonb_array_elements_text(labels) FROM tickets WHERE project_id = ? AND deleted_at IS NULL
+		) AS project_labels
+		ORDER BY label`, projectID, projectID).
+		Scan(&labels).Error
+	return labels, err
+}
+
+// ProjectStatuses returns the custom statuses the project's boards or tickets use
+func (r *templateRepository) ProjectStatuses(tenantID, projectID string, boardStatuses []string) ([]*tenant_models.CustomStatus, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	used := db.Model(&tenant_models.Ticket{}).Select("DISTINCT status").Where("project_id = ?", projectID)
+
+	var statuses []*tenant_models.CustomStatus
+	err = db.Where("name IN (?) OR name IN ?", used, boardStatuses).
+		Order("sort_order ASC, name ASC").
+		Find(&statuses).Error
+	return statuses, err
+}
+
+// ProjectTicketTypes returns the custom ticket types of the project's tickets
+func (r *templateRepository) ProjectTicketTypes(tenantID, projectID string) ([]*tenant_models.CustomTicketType, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	used := db.Model(&tenant_models.Ticket{}).Select("DISTINCT ticket_type").Where("project_id = ?", projectID)
+
+	var types []*tenant_models.CustomTicketType
+	err = db.Where("name IN (?)", used).Order("name ASC").Find(&types).Error
+	return types, err
+}
+
+// OpenTickets returns the project's unfinished tickets in board order
+func (r *templateRepository) OpenTickets(tenantID, projectID string) ([]*tenant_models.Ticket, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := doneStatuses(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*tenant_models.Ticket
+	err = db.Where("project_id = ? AND status NOT IN ?", projectID, done).
+		Order("board_rank ASC, ticket_number ASC").
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// TicketLinks returns the links between the tickets
+func (r *templateRepository) TicketLinks(tenantID string, ticketIDs []string) ([]*tenant_models.TicketLink, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []*tenant_models.TicketLink
+	if len(ticketIDs) == 0 {
+		return links, nil
+	}
+	err = db.Where("source_ticket_id IN ? AND target_ticket_id IN ?", ticketIDs, ticketIDs).
+		Order("created_at ASC").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *templateRepository) CreateProject(tenantID string, project *tenant_models.Project, setup *ProjectSetup, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, status := range setup.Statuses {
+			if err := ensureNamed(tx, status, status.Name); err != nil {
+				return err
+			}
+		}
+		for _, ticketType := range setup.TicketTypes {
+			if err := ensureNamed(tx, ticketType, ticketType.Name); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		for _, member := range setup.Members {
+			if err := tx.Create(member).Error; err != nil {
+				return err
+			}
+		}
+		for _, board := range setup.Boards {
+			if err := tx.Create(board).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(setup.Tickets) == 0 {
+			return nil
+		}
+		if err := tx.Create(&setup.Tickets).Error; err != nil {
+			return err
+		}
+		history := make([]*tenant_models.TicketHistory, 0, len(setup.Tickets))
+		for _, ticket := range setup.Tickets {
+			title := ticket.Title
+			history = append(history, &tenant_models.TicketHistory{
+				TicketID:   ticket.ID,
+				FieldName:  "ticket",
+				NewValue:   &title,
+				ChangeType: tenant_models.ChangeTypeCreate,
+				ChangedBy:  actorID,
+				ChangedAt:  ticket.CreatedAt,
+			})
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		if len(setup.Links) == 0 {
+			return nil
+		}
+		return tx.Create(&setup.Links).Error
+	})
+}
+
+// ensureNamed creates the status or ticket type unless the tenant has one of that name,
+// bringing back one that was deleted
+func ensureNamed(tx *gorm.DB, record interface{}, name string) error {
+	var count int64
+	if err := tx.Model(record).Where("name = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	restored := tx.Unscoped().Model(record).
+		Where("name = ? AND deleted_at IS NOT NULL", name).
+		Updates(map[string]interface{}{"deleted_at": nil, "is_active": true})
+	if restored.Error != nil {
+		return restored.Error
+	}
+	if restored.RowsAffected > 0 {
+		return nil
+	}
+	return tx.Create(record).Error
+}
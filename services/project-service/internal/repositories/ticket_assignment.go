@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// ticketReferences are the ticket fields project-service assigns: which sprint and which
+// milestone a ticket is in
+var ticketReferences = map[string]func(*tenant_models.Ticket) **string{
+	"sprint_id":    func(t *tenant_models.Ticket) **string { return &t.SprintID },
+	"milestone_id": func(t *tenant_models.Ticket) **string { return &t.MilestoneID },
+}
+
+// assignTickets points the field of each ticket at value, nil to clear it, and records
+// the change in the ticket's history
+func assignTickets(tx *gorm.DB, tickets []*tenant_models.Ticket, field string, value *string, actorID string) error {
+	reference := ticketReferences[field]
+	now := time.Now()
+	for _, ticket := range tickets {
+		current := reference(ticket)
+		if optionalValue(*current) == optionalValue(value) {
+			continue
+		}
+		previous := *current
+
+		err := tx.Model(ticket).Updates(map[string]interface{}{
+			field:        value,
+			"updated_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		entry := &tenant_models.TicketHistory{
+			TicketID:   ticket.ID,
+			FieldName:  field,
+			OldValue:   previous,
+			NewValue:   value,
+			ChangeType: tenant_models.ChangeTypeUpdate,
+			ChangedBy:  actorID,
+			ChangedAt:  now,
+		}
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		*current = value
+	}
+	return nil
+}
+
+// doneStatuses lists the statuses that count as finished work: resolved and closed, and
+// the tenant's custom statuses in those categories
+func doneStatuses(db *gorm.DB) ([]string, error) {
+	var names []string
+	err := db.Model(&tenant_models.CustomStatus{}).
+		Where("category IN ?", []tenant_models.StatusCategory{tenant_models.StatusCategoryResolved, tenant_models.StatusCategoryClosed}).
+		Pluck("name", &names).Error
+	if err != nil {
+		return nil, err
+	}
+	return append(names, string(tenant_models.StatusResolved), string(tenant_models.StatusClosed)), nil
+}
+
+func optionalValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
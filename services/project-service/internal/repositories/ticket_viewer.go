@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// TicketViewer is who a ticket list is for. Restricted tickets only show to their
+// reporter and assignee, and to project leads, who see all of them.
+type TicketViewer struct {
+	UserID string
+	Lead   bool
+}
+
+// Sees reports whether the ticket shows to the viewer
+func (v TicketViewer) Sees(ticket *tenant_models.Ticket) bool {
+	return v.Lead || ticket.Visibility != tenant_models.VisibilityRestricted ||
+		optionalValue(ticket.ReporterID) == v.UserID || optionalValue(ticket.AssigneeID) == v.UserID
+}
+
+// scope limits a ticket query to the tickets that show to the viewer
+func (v TicketViewer) scope(query *gorm.DB) *gorm.DB {
+	if v.Lead {
+		return query
+	}
+	return query.Where("visibility <> ? OR reporter_id = ? OR assignee_id = ?",
+		tenant_models.VisibilityRestricted, v.UserID, v.UserID)
+}
@@ -0,0 +1,352 @@
+package repositories
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// TicketTime is the time logged on one of a project's tickets
+type TicketTime struct {
+	TicketID        string
+	TicketNumber    int
+	Title           string
+	Status          tenant_models.TicketStatus
+	EstimatedHours  *float64
+	LoggedMinutes   int64
+	BillableMinutes int64
+}
+
+// UserTime is the time one user logged on a project
+type UserTime struct {
+	UserID          string
+	LoggedMinutes   int64
+	BillableMinutes int64
+}
+
+type WorklogRepository interface {
+	// Worklogs. Every write recomputes the ticket's ActualHours in the same transaction.
+	ListWorklogs(tenantID, ticketID string) ([]*tenant_models.Worklog, error)
+	GetWorklog(tenantID, ticketID, worklogID string) (*tenant_models.Worklog, error)
+	CreateWorklog(tenantID string, worklog *tenant_models.Worklog, actorID string) error
+	UpdateWorklog(tenantID string, worklog *tenant_models.Worklog, actorID string) error
+	DeleteWorklog(tenantID string, worklog *tenant_models.Worklog, actorID string) error
+	GetTicket(tenantID, ticketID string) (*tenant_models.Ticket, error)
+
+	// Timers. Stopping a timer logs its time unless the worklog is nil.
+	GetTimer(tenantID, userID string) (*tenant_models.WorkTimer, error)
+	StartTimer(tenantID string, timer *tenant_models.WorkTimer, stopped *tenant_models.Worklog) error
+	StopTimer(tenantID string, timer *tenant_models.WorkTimer, worklog *tenant_models.Worklog) error
+
+	// Reports
+	UserWorklogs(tenantID, userID string, from, to time.Time) ([]*tenant_models.Worklog, error)
+	GetTickets(tenantID string, ticketIDs []string) ([]*tenant_models.Ticket, error)
+	ProjectTicketTime(tenantID, projectID string, from, to *time.Time, viewer TicketViewer) ([]*TicketTime, error)
+	ProjectUserTime(tenantID, projectID string, from, to *time.Time) ([]*UserTime, error)
+}
+
+type worklogRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewWorklogRepository(tenantDBManager *database.TenantDatabaseManager) WorklogRepository {
+	return &worklogRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *worklogRepository) ListWorklogs(tenantID, ticketID string) ([]*tenant_models.Worklog, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var worklogs []*tenant_models.Worklog
+	err = db.Where("ticket_id = ?", ticketID).Order("started_at DESC").Find(&worklogs).Error
+	return worklogs, err
+}
+
+func (r *worklogRepository) GetWorklog(tenantID, ticketID, worklogID string) (*tenant_models.Worklog, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var worklog tenant_models.Worklog
+	err = db.Where("id = ? AND ticket_id = ?", worklogID, ticketID).First(&worklog).Error
+	if err != nil {
+		return nil, err
+	}
+	return &worklog, nil
+}
+
+func (r *worklogRepository) CreateWorklog(tenantID string, worklog *tenant_models.Worklog, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(worklog).Error; err != nil {
+			return err
+		}
+		return recomputeActualHours(tx, worklog.TicketID, actorID)
+	})
+}
+
+func (r *worklogRepository) UpdateWorklog(tenantID string, worklog *tenant_models.Worklog, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(worklog).Error; err != nil {
+			return err
+		}
+		return recomputeActualHours(tx, worklog.TicketID, actorID)
+	})
+}
+
+func (r *worklogRepository) DeleteWorklog(tenantID string, worklog *tenant_models.Worklog, actorID string) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(worklog).Error; err != nil {
+			return err
+		}
+		return recomputeActualHours(tx, worklog.TicketID, actorID)
+	})
+}
+
+func (r *worklogRepository) GetTicket(tenantID, ticketID string) (*tenant_models.Ticket, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket tenant_models.Ticket
+	if err := db.Where("id = ?", ticketID).First(&ticket).Error; err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *worklogRepository) GetTimer(tenantID, userID string) (*tenant_models.WorkTimer, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var timer tenant_models.WorkTimer
+	if err := db.Where("user_id = ?", userID).First(&timer).Error; err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
+
+// StartTimer replaces the user's running timer, logging the stopped one's time
+func (r *worklogRepository) StartTimer(tenantID string, timer *tenant_models.WorkTimer, stopped *tenant_models.Worklog) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", timer.UserID).Delete(&tenant_models.WorkTimer{}).Error; err != nil {
+			return err
+		}
+		if stopped != nil {
+			if err := tx.Create(stopped).Error; err != nil {
+				return err
+			}
+			if err := recomputeActualHours(tx, stopped.TicketID, stopped.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(timer).Error
+	})
+}
+
+func (r *worklogRepository) StopTimer(tenantID string, timer *tenant_models.WorkTimer, worklog *tenant_models.Worklog) error {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(timer).Error; err != nil {
+			return err
+		}
+		if worklog == nil {
+			return nil
+		}
+		if err := tx.Create(worklog).Error; err != nil {
+			return err
+		}
+		return recomputeActualHours(tx, worklog.TicketID, worklog.UserID)
+	})
+}
+
+// UserWorklogs returns the worklogs the user started in [from, to), oldest first
+func (r *worklogRepository) UserWorklogs(tenantID, userID string, from, to time.Time) ([]*tenant_models.Worklog, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var worklogs []*tenant_models.Worklog
+	err = db.Where("user_id = ? AND started_at >= ? AND started_at < ?", userID, from, to).
+		Order("started_at ASC").
+		Find(&worklogs).Error
+	return worklogs, err
+}
+
+// GetTickets returns the tickets, deleted ones included
+func (r *worklogRepository) GetTickets(tenantID string, ticketIDs []string) ([]*tenant_models.Ticket, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*tenant_models.Ticket
+	if len(ticketIDs) == 0 {
+		return tickets, nil
+	}
+	err = db.Unscoped().Where("id IN ?", ticketIDs).Find(&tickets).Error
+	return tickets, err
+}
+
+// ProjectTicketTime returns the project's tickets that have an estimate or logged time,
+// with the time logged in [from, to) when given. Only tickets that show to the viewer count.
+func (r *worklogRepository) ProjectTicketTime(tenantID, projectID string, from, to *time.Time, viewer TicketViewer) ([]*TicketTime, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	join, args := worklogJoin(from, to)
+	var rows []*TicketTime
+	err = viewer.scope(db.Table("tickets t")).
+		Select(`t.id AS ticket_id, t.ticket_number, t.title, t.status, t.estimated_hours,
+			COALESCE(SUM(w.duration_minutes), 0) AS logged_minutes,
+			COALESCE(SUM(CASE WHEN w.billable THEN w.duration_minutes ELSE 0 END), 0) AS billable_minutes`).
+		Joins(join, args...).
+		Where("t.project_id = ? AND t.deleted_at IS NULL", projectID).
+		Group("t.id").
+		Having("t.estimated_hours IS NOT NULL OR COUNT(w.id) > 0").
+		Order("logged_minutes DESC, t.ticket_number ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ProjectUserTime returns the time each user logged on the project's tickets in
+// [from, to) when given
+func (r *worklogRepository) ProjectUserTime(tenantID, projectID string, from, to *time.Time) ([]*UserTime, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Table("worklogs w").
+		Select(`w.user_id,
+			SUM(w.duration_minutes) AS logged_minutes,
+			SUM(CASE WHEN w.billable THEN w.duration_minutes ELSE 0 END) AS billable_minutes`).
+		Joins("JOIN tickets t ON t.id = w.ticket_id").
+		Where("t.project_id = ? AND t.deleted_at IS NULL", projectID)
+	if from != nil {
+		query = query.Where("w.started_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("w.started_at < ?", *to)
+	}
+
+	var rows []*UserTime
+	err = query.Group("w.user_id").Order("logged_minutes DESC").Scan(&rows).Error
+	return rows, err
+}
+
+// worklogJoin joins tickets to their worklogs, limited to [from, to) when given
+func worklogJoin(from, to *time.Time) (string, []interface{}) {
+	join := "LEFT JOIN worklogs w ON w.ticket_id = t.id"
+	var args []interface{}
+	if from != nil {
+		join += " AND w.started_at >= ?"
+		args = append(args, *from)
+	}
+	if to != nil {
+		join += " AND w.started_at < ?"
+		args = append(args, *to)
+	}
+	return join, args
+}
+
+// recomputeActualHours sets the ticket's ActualHours to the sum of its worklogs and
+// records the change in its history
+func recomputeActualHours(tx *gorm.DB, ticketID, actorID string) error {
+	var ticket tenant_models.Ticket
+	if err := tx.Select("id, actual_hours").Where("id = ?", ticketID).First(&ticket).Error; err != nil {
+		return err
+	}
+
+	var minutes int64
+	err := tx.Model(&tenant_models.Worklog{}).
+		Where("ticket_id = ?", ticketID).
+		Select("COALESCE(SUM(duration_minutes), 0)").
+		Scan(&minutes).Error
+	if err != nil {
+		return err
+	}
+
+	var hours *float64
+	if minutes > 0 {
+		rounded := math.Round(float64(minutes)/60*100) / 100
+		hours = &rounded
+	}
+	oldValue, newValue := formatHours(ticket.ActualHours), formatHours(hours)
+	if oldValue == newValue {
+		return nil
+	}
+
+	now := time.Now()
+	err = tx.Model(&ticket).Updates(map[string]interface{}{
+		"actual_hours": hours,
+		"updated_at":   now,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	entry := &tenant_models.TicketHistory{
+		TicketID:   ticketID,
+		FieldName:  "actual_hours",
+		OldValue:   optionalString(oldValue),
+		NewValue:   optionalString(newValue),
+		ChangeType: tenant_models.ChangeTypeUpdate,
+		ChangedBy:  actorID,
+		ChangedAt:  now,
+	}
+	return tx.Create(entry).Error
+}
+
+func formatHours(hours *float64) string {
+	if hours == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*hours, 'f', -1, 64)
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
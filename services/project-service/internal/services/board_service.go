@@ -0,0 +1,407 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"project-service/internal/repositories"
+)
+
+// builtinStatuses are the statuses every tenant has, whether or not it defines its own
+var builtinStatuses = []tenant_models.TicketStatus{
+	tenant_models.StatusOpen,
+	tenant_models.StatusInProgress,
+	tenant_models.StatusOnHold,
+	tenant_models.StatusResolved,
+	tenant_models.StatusClosed,
+}
+
+// priorityLanes orders priority swimlanes from most to least urgent
+var priorityLanes = map[tenant_models.TicketPriority]int{
+	tenant_models.PriorityBlocker:  0,
+	tenant_models.PriorityCritical: 1,
+	tenant_models.PriorityHigh:     2,
+	tenant_models.PriorityMedium:   3,
+	tenant_models.PriorityLow:      4,
+}
+
+const (
+	defaultBoardCards = 100
+	maxBoardCards     = 500
+)
+
+type BoardService interface {
+	ListBoards(userID, tenantID, projectID string) ([]*tenant_models.Board, error)
+	CreateBoard(userID, tenantID, projectID string, req *tenant_models.BoardCreateRequest) (*tenant_models.Board, error)
+	GetBoard(userID, tenantID, projectID, boardID string) (*tenant_models.Board, error)
+	UpdateBoard(userID, tenantID, projectID, boardID string, req *tenant_models.BoardUpdateRequest) (*tenant_models.Board, error)
+	DeleteBoard(userID, tenantID, projectID, boardID string) error
+
+	// GetBoardView returns the whole board with up to cardLimit cards per column
+	GetBoardView(userID, tenantID, projectID, boardID string, cardLimit int) (*tenant_models.BoardView, error)
+}
+
+type boardService struct {
+	repo     repositories.BoardRepository
+	projects repositories.ProjectRepository
+	logger   *zap.Logger
+}
+
+func NewBoardService(repo repositories.BoardRepository, projects repositories.ProjectRepository, logger *zap.Logger) BoardService {
+	return &boardService{
+		repo:     repo,
+		projects: projects,
+		logger:   logger,
+	}
+}
+
+// defaultBoard is the board new kanban and scrum projects start with
+func defaultBoard(projectID, userID string) *tenant_models.Board {
+	return &tenant_models.Board{
+		ProjectID: projectID,
+		Name:      "Board",
+		Swimlanes: tenant_models.SwimlaneNone,
+		IsDefault: true,
+		CreatedBy: userID,
+		Columns: []tenant_models.BoardColumn{
+			{Name: "To Do", Statuses: models.StringArray{string(tenant_models.StatusOpen)}, Position: 0},
+			{Name: "In Progress", Statuses: models.StringArray{string(tenant_models.StatusInProgress)}, Position: 1},
+			{Name: "On Hold", Statuses: models.StringArray{string(tenant_models.StatusOnHold)}, Position: 2},
+			{Name: "Done", Statuses: models.StringArray{string(tenant_models.StatusResolved), string(tenant_models.StatusClosed)}, Position: 3},
+		},
+	}
+}
+
+func (s *boardService) ListBoards(userID, tenantID, projectID string) ([]*tenant_models.Board, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	return s.repo.ListBoards(tenantID, projectID)
+}
+
+func (s *boardService) CreateBoard(userID, tenantID, projectID string, req *tenant_models.BoardCreateRequest) (*tenant_models.Board, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	existing, err := s.repo.ListBoards(tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &tenant_models.Board{
+		ProjectID:   projectID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Swimlanes:   req.Swimlanes,
+		IsDefault:   req.IsDefault || len(existing) == 0, // A project's first board is its default
+		CreatedBy:   userID,
+	}
+	if board.Swimlanes == "" {
+		board.Swimlanes = tenant_models.SwimlaneNone
+	}
+	if board.Columns, err = s.buildColumns(tenantID, nil, req.Columns); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateBoard(tenantID, board); err != nil {
+		return nil, fmt.Errorf("failed to create board: %w", err)
+	}
+
+	s.logger.Info("Board created",
+		zap.String("board_id", board.ID),
+		zap.String("project_id", projectID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	return board, nil
+}
+
+func (s *boardService) GetBoard(userID, tenantID, projectID, boardID string) (*tenant_models.Board, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	return s.repo.GetBoard(tenantID, projectID, boardID)
+}
+
+func (s *boardService) UpdateBoard(userID, tenantID, projectID, boardID string, req *tenant_models.BoardUpdateRequest) (*tenant_models.Board, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	board, err := s.repo.GetBoard(tenantID, projectID, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		board.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		board.Description = *req.Description
+	}
+	if req.Swimlanes != nil {
+		board.Swimlanes = *req.Swimlanes
+	}
+	if req.IsDefault != nil {
+		// The default moves by making another board the default
+		if board.IsDefault && !*req.IsDefault {
+			return nil, validationError("make another board the default instead")
+		}
+		board.IsDefault = *req.IsDefault
+	}
+	if req.Columns != nil {
+		if board.Columns, err = s.buildColumns(tenantID, board.Columns, *req.Columns); err != nil {
+			return nil, err
+		}
+	}
+	board.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateBoard(tenantID, board); err != nil {
+		return nil, fmt.Errorf("failed to update board: %w", err)
+	}
+
+	s.logger.Info("Board updated",
+		zap.String("board_id", board.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("updated_by", userID))
+
+	return board, nil
+}
+
+func (s *boardService) DeleteBoard(userID, tenantID, projectID, boardID string) error {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return ErrAccessDenied
+	}
+
+	if err := s.repo.DeleteBoard(tenantID, projectID, boardID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Board deleted",
+		zap.String("board_id", boardID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+// buildColumns checks the requested columns and turns them into the board's columns in
+// the order given. Columns may keep the ID of one of the current columns.
+func (s *boardService) buildColumns(tenantID string, current []tenant_models.BoardColumn, requested []tenant_models.BoardColumnRequest) ([]tenant_models.BoardColumn, error) {
+	known, err := knownStatuses(s.repo, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return boardColumns(known, current, requested)
+}
+
+// knownStatuses returns the built-in statuses and the tenant's custom ones
+func knownStatuses(repo repositories.BoardRepository, tenantID string) (map[string]bool, error) {
+	known := make(map[string]bool, len(builtinStatuses))
+	for _, status := range builtinStatuses {
+		known[string(status)] = true
+	}
+	custom, err := repo.CustomStatusNames(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load statuses: %w", err)
+	}
+	for _, name := range custom {
+		known[name] = true
+	}
+	return known, nil
+}
+
+// boardColumns checks the requested columns against the known statuses and builds them,
+// keeping the IDs of current columns
+func boardColumns(known map[string]bool, current []tenant_models.BoardColumn, requested []tenant_models.BoardColumnRequest) ([]tenant_models.BoardColumn, error) {
+	if len(requested) == 0 {
+		return nil, validationError("a board needs at least one column")
+	}
+
+	currentIDs := make(map[string]bool, len(current))
+	for _, column := range current {
+		currentIDs[column.ID] = true
+	}
+
+	names := map[string]bool{}
+	statusColumns := map[string]string{}
+	columns := make([]tenant_models.BoardColumn, 0, len(requested))
+	for i, req := range requested {
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			return nil, validationError("column names may not be blank")
+		}
+		if names[strings.ToLower(name)] {
+			return nil, validationError("two columns are named %q", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if req.ID != "" && !currentIDs[req.ID] {
+			return nil, validationError("column %s is not on this board", req.ID)
+		}
+		if req.WIPLimit < 0 {
+			return nil, validationError("column %q: the WIP limit cannot be negative", name)
+		}
+
+		statuses := models.StringArray{}
+		for _, status := range req.Statuses {
+			status = strings.TrimSpace(status)
+			if !known[status] {
+				return nil, validationError("column %q: unknown status %q", name, status)
+			}
+			if other, taken := statusColumns[status]; taken {
+				return nil, validationError("status %q is in both column %q and column %q", status, other, name)
+			}
+			statusColumns[status] = name
+			statuses = append(statuses, status)
+		}
+
+		columns = append(columns, tenant_models.BoardColumn{
+			ID:       req.ID,
+			Name:     name,
+			Statuses: statuses,
+			WIPLimit: req.WIPLimit,
+			Position: i,
+		})
+	}
+	return columns, nil
+}
+
+func (s *boardService) GetBoardView(userID, tenantID, projectID, boardID string, cardLimit int) (*tenant_models.BoardView, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+	if cardLimit <= 0 {
+		cardLimit = defaultBoardCards
+	}
+	if cardLimit > maxBoardCards {
+		cardLimit = maxBoardCards
+	}
+
+	board, err := s.repo.GetBoard(tenantID, projectID, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &tenant_models.BoardView{
+		Board:   *board,
+		Columns: make([]tenant_models.BoardColumnView, 0, len(board.Columns)),
+	}
+	viewer := ticketViewer(s.projects, userID, tenantID, projectID)
+	cards := make([][]*tenant_models.Ticket, len(board.Columns))
+	for i, column := range board.Columns {
+		tickets, total, err := s.repo.ColumnCards(tenantID, projectID, column.Statuses, viewer, cardLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load column %s: %w", column.Name, err)
+		}
+		cards[i] = tickets
+		view.Columns = append(view.Columns, tenant_models.BoardColumnView{
+			BoardColumn: column,
+			TicketCount: total,
+			OverLimit:   column.WIPLimit > 0 && total > int64(column.WIPLimit),
+			Truncated:   int64(len(tickets)) < total,
+		})
+	}
+
+	view.Swimlanes, err = s.layOutSwimlanes(tenantID, board, cards, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// layOutSwimlanes sorts each column's cards into the board's swimlanes, keeping their
+// rank order. Lanes without a key, such as unassigned cards, come last.
+func (s *boardService) layOutSwimlanes(tenantID string, board *tenant_models.Board, cards [][]*tenant_models.Ticket, viewer repositories.TicketViewer) ([]tenant_models.BoardSwimlane, error) {
+	laneKey := func(ticket *tenant_models.Ticket) string {
+		switch board.Swimlanes {
+		case tenant_models.SwimlaneAssignee:
+			return stringValue(ticket.AssigneeID)
+		case tenant_models.SwimlanePriority:
+			return string(ticket.Priority)
+		case tenant_models.SwimlaneEpic:
+			return stringValue(ticket.ParentTicketID)
+		}
+		return ""
+	}
+
+	lanes := map[string]*tenant_models.BoardSwimlane{}
+	var keys []string
+	for i := range board.Columns {
+		for _, ticket := range cards[i] {
+			key := laneKey(ticket)
+			lane, ok := lanes[key]
+			if !ok {
+				lane = &tenant_models.BoardSwimlane{Key: key, Columns: make([]tenant_models.BoardLaneColumn, len(board.Columns))}
+				for j, laneColumn := range board.Columns {
+					lane.Columns[j] = tenant_models.BoardLaneColumn{ColumnID: laneColumn.ID, Cards: []tenant_models.BoardCard{}}
+				}
+				lanes[key] = lane
+				keys = append(keys, key)
+			}
+			lane.Columns[i].Cards = append(lane.Columns[i].Cards, ticket.ToCard())
+		}
+	}
+
+	// An empty board still shows its columns
+	if len(keys) == 0 {
+		lane := tenant_models.BoardSwimlane{Columns: make([]tenant_models.BoardLaneColumn, len(board.Columns))}
+		for j, column := range board.Columns {
+			lane.Columns[j] = tenant_models.BoardLaneColumn{ColumnID: column.ID, Cards: []tenant_models.BoardCard{}}
+		}
+		return []tenant_models.BoardSwimlane{lane}, nil
+	}
+
+	titles := map[string]string{}
+	if board.Swimlanes == tenant_models.SwimlaneEpic {
+		var err error
+		if titles, err = s.repo.GetTicketTitles(tenantID, keys, viewer); err != nil {
+			return nil, fmt.Errorf("failed to load epics: %w", err)
+		}
+	}
+
+	sort.SliceStable(keys, func(a, b int) bool {
+		ka, kb := keys[a], keys[b]
+		if (ka == "") != (kb == "") {
+			return kb == ""
+		}
+		if board.Swimlanes == tenant_models.SwimlanePriority {
+			pa, okA := priorityLanes[tenant_models.TicketPriority(ka)]
+			pb, okB := priorityLanes[tenant_models.TicketPriority(kb)]
+			if okA != okB {
+				return okA
+			}
+			if okA {
+				return pa < pb
+			}
+		}
+		if board.Swimlanes == tenant_models.SwimlaneEpic && titles[ka] != titles[kb] {
+			return titles[ka] < titles[kb]
+		}
+		return ka < kb
+	})
+
+	swimlanes := make([]tenant_models.BoardSwimlane, 0, len(keys))
+	for _, key := range keys {
+		lane := lanes[key]
+		lane.Title = titles[key]
+		swimlanes = append(swimlanes, *lane)
+	}
+	return swimlanes, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
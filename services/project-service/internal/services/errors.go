@@ -0,0 +1,20 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrValidation marks errors caused by invalid client input
+	ErrValidation = errors.New("validation failed")
+
+	// ErrAccessDenied marks authorization failures. Its text matches the plain
+	// "access denied" errors handlers already compare against.
+	ErrAccessDenied = errors.New("access denied")
+)
+
+// validationError wraps a message with ErrValidation so handlers can answer 400
+func validationError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrValidation, fmt.Sprintf(format, args...))
+}
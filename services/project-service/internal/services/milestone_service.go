@@ -0,0 +1,260 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"project-service/internal/repositories"
+)
+
+type MilestoneService interface {
+	ListMilestones(userID, tenantID, projectID string) ([]*tenant_models.MilestoneView, error)
+	CreateMilestone(userID, tenantID, projectID string, req *tenant_models.MilestoneCreateRequest) (*tenant_models.Milestone, error)
+	GetMilestone(userID, tenantID, projectID, milestoneID string) (*tenant_models.MilestoneView, error)
+	UpdateMilestone(userID, tenantID, projectID, milestoneID string, req *tenant_models.MilestoneUpdateRequest) (*tenant_models.Milestone, error)
+	DeleteMilestone(userID, tenantID, projectID, milestoneID string) error
+
+	AddTickets(userID, tenantID, projectID, milestoneID string, req *tenant_models.MilestoneTicketsRequest) error
+	RemoveTicket(userID, tenantID, projectID, milestoneID, ticketID string) error
+
+	// GetRoadmap returns the project's timeline for Gantt charts
+	GetRoadmap(userID, tenantID, projectID string) (*tenant_models.Roadmap, error)
+}
+
+type milestoneService struct {
+	repo     repositories.MilestoneRepository
+	projects repositories.ProjectRepository
+	logger   *zap.Logger
+}
+
+func NewMilestoneService(repo repositories.MilestoneRepository, projects repositories.ProjectRepository, logger *zap.Logger) MilestoneService {
+	return &milestoneService{
+		repo:     repo,
+		projects: projects,
+		logger:   logger,
+	}
+}
+
+func (s *milestoneService) ListMilestones(userID, tenantID, projectID string) ([]*tenant_models.MilestoneView, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	return s.milestoneViews(tenantID, projectID)
+}
+
+func (s *milestoneService) CreateMilestone(userID, tenantID, projectID string, req *tenant_models.MilestoneCreateRequest) (*tenant_models.Milestone, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+	if err := checkMilestoneDates(req.StartDate, req.TargetDate); err != nil {
+		return nil, err
+	}
+
+	milestone := &tenant_models.Milestone{
+		ProjectID:   projectID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		StartDate:   req.StartDate,
+		TargetDate:  req.TargetDate,
+		CreatedBy:   userID,
+	}
+	if err := s.repo.CreateMilestone(tenantID, milestone); err != nil {
+		return nil, fmt.Errorf("failed to create milestone: %w", err)
+	}
+
+	s.logger.Info("Milestone created",
+		zap.String("milestone_id", milestone.ID),
+		zap.String("project_id", projectID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	return milestone, nil
+}
+
+func (s *milestoneService) GetMilestone(userID, tenantID, projectID, milestoneID string) (*tenant_models.MilestoneView, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	milestone, err := s.repo.GetMilestone(tenantID, projectID, milestoneID)
+	if err != nil {
+		return nil, err
+	}
+	done, err := s.repo.DoneStatuses(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.MilestoneTickets(tenantID, projectID, done)
+	if err != nil {
+		return nil, err
+	}
+
+	return milestoneView(milestone, totals[milestone.ID], time.Now()), nil
+}
+
+func (s *milestoneService) UpdateMilestone(userID, tenantID, projectID, milestoneID string, req *tenant_models.MilestoneUpdateRequest) (*tenant_models.Milestone, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	milestone, err := s.repo.GetMilestone(tenantID, projectID, milestoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		milestone.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		milestone.Description = *req.Description
+	}
+	if req.StartDate != nil {
+		milestone.StartDate = req.StartDate
+	}
+	if req.TargetDate != nil {
+		milestone.TargetDate = *req.TargetDate
+	}
+	if err := checkMilestoneDates(milestone.StartDate, milestone.TargetDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateMilestone(tenantID, milestone); err != nil {
+		return nil, fmt.Errorf("failed to update milestone: %w", err)
+	}
+	return milestone, nil
+}
+
+// DeleteMilestone removes the milestone; its tickets stay in the project
+func (s *milestoneService) DeleteMilestone(userID, tenantID, projectID, milestoneID string) error {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return ErrAccessDenied
+	}
+
+	milestone, err := s.repo.GetMilestone(tenantID, projectID, milestoneID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteMilestone(tenantID, milestone, userID); err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+
+	s.logger.Info("Milestone deleted",
+		zap.String("milestone_id", milestoneID),
+		zap.String("project_id", projectID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+// AddTickets attaches project tickets to the milestone, moving them from any other
+func (s *milestoneService) AddTickets(userID, tenantID, projectID, milestoneID string, req *tenant_models.MilestoneTicketsRequest) error {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return ErrAccessDenied
+	}
+
+	milestone, err := s.repo.GetMilestone(tenantID, projectID, milestoneID)
+	if err != nil {
+		return err
+	}
+
+	ids := uniqueStrings(req.TicketIDs)
+	tickets, err := s.repo.ProjectTickets(tenantID, projectID, ids)
+	if err != nil {
+		return err
+	}
+	if len(tickets) != len(ids) {
+		return validationError("every ticket must belong to the project")
+	}
+
+	if err := s.repo.AttachTickets(tenantID, tickets, &milestone.ID, userID); err != nil {
+		return fmt.Errorf("failed to add tickets to milestone: %w", err)
+	}
+	return nil
+}
+
+func (s *milestoneService) RemoveTicket(userID, tenantID, projectID, milestoneID, ticketID string) error {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return ErrAccessDenied
+	}
+
+	milestone, err := s.repo.GetMilestone(tenantID, projectID, milestoneID)
+	if err != nil {
+		return err
+	}
+	tickets, err := s.repo.ProjectTickets(tenantID, projectID, []string{ticketID})
+	if err != nil {
+		return err
+	}
+	if len(tickets) == 0 || stringValue(tickets[0].MilestoneID) != milestone.ID {
+		return validationError("the ticket is not in this milestone")
+	}
+
+	if err := s.repo.AttachTickets(tenantID, tickets, nil, userID); err != nil {
+		return fmt.Errorf("failed to remove ticket from milestone: %w", err)
+	}
+	return nil
+}
+
+// milestoneViews returns the project's milestones with their progress
+func (s *milestoneService) milestoneViews(tenantID, projectID string) ([]*tenant_models.MilestoneView, error) {
+	milestones, err := s.repo.ListMilestones(tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	done, err := s.repo.DoneStatuses(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.MilestoneTickets(tenantID, projectID, done)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	views := make([]*tenant_models.MilestoneView, 0, len(milestones))
+	for _, milestone := range milestones {
+		views = append(views, milestoneView(milestone, totals[milestone.ID], now))
+	}
+	return views, nil
+}
+
+// milestoneView rolls the milestone's progress up from its tickets
+func milestoneView(milestone *tenant_models.Milestone, totals *repositories.MilestoneTickets, now time.Time) *tenant_models.MilestoneView {
+	view := &tenant_models.MilestoneView{
+		Milestone: *milestone,
+		State:     tenant_models.MilestoneOpen,
+	}
+	if totals != nil {
+		view.Progress = tenant_models.MilestoneProgress{
+			TotalTickets:       totals.TotalTickets,
+			DoneTickets:        totals.DoneTickets,
+			EstimatedHours:     totals.EstimatedHours,
+			DoneEstimatedHours: totals.DoneEstimatedHours,
+		}
+		if totals.TotalTickets > 0 {
+			view.Progress.Percent = math.Round(float64(totals.DoneTickets)/float64(totals.TotalTickets)*1000) / 10
+		}
+	}
+
+	switch {
+	case view.Progress.TotalTickets > 0 && view.Progress.DoneTickets == view.Progress.TotalTickets:
+		view.State = tenant_models.MilestoneDone
+	case now.After(milestone.TargetDate):
+		view.State = tenant_models.MilestoneOverdue
+	}
+	return view
+}
+
+func checkMilestoneDates(start *time.Time, target time.Time) error {
+	if start != nil && target.Before(*start) {
+		return validationError("target_date cannot be before start_date")
+	}
+	return nil
+}
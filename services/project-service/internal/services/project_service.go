@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/zen/shared/pkg/models"
 	"github.com/zen/shared/pkg/tenant_models"
 	"project-service/internal/repositories"
 )
@@ -32,17 +33,50 @@ type ProjectService interface {
 
 type projectService struct {
 	repo   repositories.ProjectRepository
+	boards repositories.BoardRepository
 	logger *zap.Logger
 }
 
-func NewProjectService(repo repositories.ProjectRepository, logger *zap.Logger) ProjectService {
+func NewProjectService(repo repositories.ProjectRepository, boards repositories.BoardRepository, logger *zap.Logger) ProjectService {
 	return &projectService{
 		repo:   repo,
+		boards: boards,
 		logger: logger,
 	}
 }
 
 func (s *projectService) CreateProject(userID, tenantID string, req *tenant_models.ProjectCreateRequest) (*tenant_models.ProjectResponse, error) {
+	project, err := newProject(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save project
+	err = s.repo.CreateProject(tenantID, project)
+	if err != nil {
+		s.logger.Error("Failed to create project", zap.Error(err))
+		return nil, err
+	}
+
+	// Add lead as project member
+	if err := s.repo.AddProjectMember(tenantID, leadMember(project)); err != nil {
+		s.logger.Warn("Failed to add lead as project member", zap.Error(err))
+	}
+
+	// Kanban and scrum projects start with a board over the built-in statuses
+	if project.Methodology == tenant_models.MethodologyKanban || project.Methodology == tenant_models.MethodologyScrum {
+		if err := s.boards.CreateBoard(tenantID, defaultBoard(project.ID, userID)); err != nil {
+			s.logger.Warn("Failed to create default board", zap.Error(err))
+		}
+	}
+
+	response := project.ToResponse()
+	return &response, nil
+}
+
+// newProject builds the project the request describes, with the default type and
+// methodology where none is given
+func newProject(userID string, req *tenant_models.ProjectCreateRequest) (*tenant_models.Project, error) {
 	// Validate input
 	if req.Name == "" {
 		return nil, errors.New("project name is required")
@@ -59,6 +93,7 @@ func (s *projectService) CreateProject(userID, tenantID string, req *tenant_mode
 		Description: req.Description,
 		ProjectType: req.ProjectType,
 		Methodology: req.Methodology,
+		Labels:      models.StringArray(uniqueStrings(req.Labels)),
 		Status:      tenant_models.ProjectStatusActive, // Default to active
 		LeadID:      req.LeadID,
 		CreatedBy:   userID,
@@ -73,29 +108,19 @@ func (s *projectService) CreateProject(userID, tenantID string, req *tenant_mode
 	if project.Methodology == "" {
 		project.Methodology = tenant_models.MethodologyKanban
 	}
+	return project, nil
+}
 
-	// Save project
-	err := s.repo.CreateProject(tenantID, project)
-	if err != nil {
-		s.logger.Error("Failed to create project", zap.Error(err))
-		return nil, err
-	}
-
-	// Add lead as project member
-	member := &tenant_models.ProjectMember{
+// leadMember makes the project's lead a member of it
+func leadMember(project *tenant_models.Project) *tenant_models.ProjectMember {
+	return &tenant_models.ProjectMember{
 		ID:        uuid.New().String(),
 		ProjectID: project.ID,
-		UserID:    req.LeadID,
-		Role:      "lead",
+		UserID:    project.LeadID,
+		Role:      tenant_models.ProjectRoleLead,
 		AddedAt:   time.Now(),
+		AddedBy:   project.CreatedBy,
 	}
-	
-	if err := s.repo.AddProjectMember(tenantID, member); err != nil {
-		s.logger.Warn("Failed to add lead as project member", zap.Error(err))
-	}
-
-	response := project.ToResponse()
-	return &response, nil
 }
 
 func (s *projectService) GetProject(userID, tenantID, projectID string) (*tenant_models.ProjectResponse, error) {
@@ -144,6 +169,9 @@ func (s *projectService) UpdateProject(userID, tenantID, projectID string, req *
 	if req.LeadID != nil {
 		project.LeadID = *req.LeadID
 	}
+	if req.Labels != nil {
+		project.Labels = models.StringArray(uniqueStrings(*req.Labels))
+	}
 
 	project.UpdatedAt = time.Now()
 
@@ -288,8 +316,17 @@ func (s *projectService) GetUserProjectStats(userID, tenantID string, dateFrom,
 
 
 func (s *projectService) userCanAccessProject(userID, tenantID, projectID string) bool {
+	return canAccessProject(s.repo, userID, tenantID, projectID)
+}
+
+func (s *projectService) userCanModifyProject(userID, tenantID, projectID string) bool {
+	return canModifyProject(s.repo, userID, tenantID, projectID)
+}
+
+// canAccessProject reports whether the user leads, created or belongs to the project
+func canAccessProject(repo repositories.ProjectRepository, userID, tenantID, projectID string) bool {
 	// Check if user is project lead or creator
-	project, err := s.repo.GetProject(tenantID, projectID)
+	project, err := repo.GetProject(tenantID, projectID)
 	if err != nil {
 		return false
 	}
@@ -298,13 +335,33 @@ func (s *projectService) userCanAccessProject(userID, tenantID, projectID string
 	}
 
 	// Check if user is project member
-	_, err = s.repo.GetProjectMember(tenantID, projectID, userID)
+	_, err = repo.GetProjectMember(tenantID, projectID, userID)
 	return err == nil
 }
 
-func (s *projectService) userCanModifyProject(userID, tenantID, projectID string) bool {
+// ticketViewer describes the user for ticket lists; the project's lead and members with
+// the lead role see its restricted tickets
+func ticketViewer(repo repositories.ProjectRepository, userID, tenantID, projectID string) repositories.TicketViewer {
+	viewer := repositories.TicketViewer{UserID: userID}
+	project, err := repo.GetProject(tenantID, projectID)
+	if err != nil {
+		return viewer
+	}
+	if project.LeadID == userID {
+		viewer.Lead = true
+		return viewer
+	}
+
+	member, err := repo.GetProjectMember(tenantID, projectID, userID)
+	viewer.Lead = err == nil && member.Role == tenant_models.ProjectRoleLead
+	return viewer
+}
+
+// canModifyProject reports whether the user leads or created the project, or is one of
+// its admins or leads
+func canModifyProject(repo repositories.ProjectRepository, userID, tenantID, projectID string) bool {
 	// Check if user is project lead or creator
-	project, err := s.repo.GetProject(tenantID, projectID)
+	project, err := repo.GetProject(tenantID, projectID)
 	if err != nil {
 		return false
 	}
@@ -313,7 +370,7 @@ func (s *projectService) userCanModifyProject(userID, tenantID, projectID string
 	}
 
 	// Check if user is admin member
-	member, err := s.repo.GetProjectMember(tenantID, projectID, userID)
+	member, err := repo.GetProjectMember(tenantID, projectID, userID)
 	if err != nil {
 		return false
 	}
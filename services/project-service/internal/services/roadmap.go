@@ -0,0 +1,274 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// workdayHours turns estimates into bar lengths for tickets missing a start or due date
+const workdayHours = 8
+
+// roadmapTask is a bar being scheduled: its planned dates and, after the critical path
+// pass, its earliest and latest dates
+type roadmapTask struct {
+	bar          *tenant_models.RoadmapBar
+	duration     time.Duration
+	predecessors []*roadmapTask
+	successors   []*roadmapTask
+
+	earliestStart, earliestEnd time.Time
+	latestStart, latestEnd     time.Time
+}
+
+// GetRoadmap lays the project's scheduled tickets out as bars, links them by their
+// "blocks" links and finds the critical path: the chain of dependent tickets that decides
+// when the project ends. Unfinished predecessors push their successors back.
+func (s *milestoneService) GetRoadmap(userID, tenantID, projectID string) (*tenant_models.Roadmap, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	milestones, err := s.milestoneViews(tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	tickets, err := s.repo.ScheduledTickets(tenantID, projectID, ticketViewer(s.projects, userID, tenantID, projectID))
+	if err != nil {
+		return nil, err
+	}
+	doneNames, err := s.repo.DoneStatuses(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	unscheduled, err := s.repo.CountUnscheduled(tenantID, projectID, doneNames)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(doneNames))
+	for _, name := range doneNames {
+		done[name] = true
+	}
+
+	roadmap := &tenant_models.Roadmap{
+		ProjectID:          projectID,
+		Bars:               make([]tenant_models.RoadmapBar, 0, len(tickets)),
+		Dependencies:       []tenant_models.RoadmapDependency{},
+		Milestones:         make([]tenant_models.RoadmapMilestone, 0, len(milestones)),
+		CriticalPath:       []string{},
+		UnscheduledTickets: unscheduled,
+	}
+
+	ids := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		start, end := ticketSchedule(ticket)
+		roadmap.Bars = append(roadmap.Bars, tenant_models.RoadmapBar{
+			TicketID:       ticket.ID,
+			TicketNumber:   ticket.TicketNumber,
+			Title:          ticket.Title,
+			Status:         ticket.Status,
+			AssigneeID:     ticket.AssigneeID,
+			ParentTicketID: ticket.ParentTicketID,
+			MilestoneID:    ticket.MilestoneID,
+			Start:          start,
+			End:            end,
+			Done:           done[string(ticket.Status)],
+		})
+		ids = append(ids, ticket.ID)
+	}
+
+	tasks := make(map[string]*roadmapTask, len(roadmap.Bars))
+	order := make([]*roadmapTask, 0, len(roadmap.Bars))
+	for i := range roadmap.Bars {
+		bar := &roadmap.Bars[i]
+		task := &roadmapTask{bar: bar, duration: bar.End.Sub(bar.Start)}
+		tasks[bar.TicketID] = task
+		order = append(order, task)
+	}
+
+	links, err := s.repo.Dependencies(tenantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		from, to := tasks[link.SourceTicketID], tasks[link.TargetTicketID]
+		if from == nil || to == nil {
+			continue
+		}
+		from.successors = append(from.successors, to)
+		to.predecessors = append(to.predecessors, from)
+		roadmap.Dependencies = append(roadmap.Dependencies, tenant_models.RoadmapDependency{
+			FromTicketID: from.bar.TicketID,
+			ToTicketID:   to.bar.TicketID,
+			Type:         "finish_to_start",
+			Violated:     !from.bar.Done && to.bar.Start.Before(from.bar.End),
+		})
+	}
+
+	roadmap.CriticalPath = scheduleTasks(topologicalOrder(order))
+
+	// Milestones are projected to land when their last ticket does
+	for _, view := range milestones {
+		milestone := tenant_models.RoadmapMilestone{MilestoneView: *view}
+		for i := range roadmap.Bars {
+			bar := &roadmap.Bars[i]
+			if stringValue(bar.MilestoneID) != view.ID {
+				continue
+			}
+			if milestone.ProjectedDate == nil || bar.ProjectedEnd.After(*milestone.ProjectedDate) {
+				end := bar.ProjectedEnd
+				milestone.ProjectedDate = &end
+			}
+		}
+		milestone.AtRisk = view.State != tenant_models.MilestoneDone &&
+			milestone.ProjectedDate != nil && milestone.ProjectedDate.After(view.TargetDate)
+		roadmap.Milestones = append(roadmap.Milestones, milestone)
+	}
+
+	// The timeline spans every bar and milestone
+	extend := func(start, end time.Time) {
+		if roadmap.Start == nil || start.Before(*roadmap.Start) {
+			roadmap.Start = &start
+		}
+		if roadmap.End == nil || end.After(*roadmap.End) {
+			roadmap.End = &end
+		}
+	}
+	for _, bar := range roadmap.Bars {
+		extend(bar.Start, bar.ProjectedEnd)
+	}
+	for _, milestone := range roadmap.Milestones {
+		start := milestone.TargetDate
+		if milestone.StartDate != nil {
+			start = *milestone.StartDate
+		}
+		extend(start, milestone.TargetDate)
+	}
+
+	return roadmap, nil
+}
+
+// ticketSchedule returns the ticket's planned dates. A missing start or due date is
+// derived from the estimate, a working day per eight hours, or a single day without one.
+// Tickets are due no earlier than they start, which writes enforce; a ticket saved before
+// that check with its due date first is treated as having no due date.
+func ticketSchedule(ticket *tenant_models.Ticket) (time.Time, time.Time) {
+	days := 1
+	if ticket.EstimatedHours != nil && *ticket.EstimatedHours > 0 {
+		days = int(math.Ceil(*ticket.EstimatedHours / workdayHours))
+	}
+	length := time.Duration(days) * 24 * time.Hour
+
+	switch {
+	case ticket.StartDate != nil && ticket.DueDate != nil && !ticket.DueDate.Before(*ticket.StartDate):
+		return *ticket.StartDate, *ticket.DueDate
+	case ticket.StartDate != nil:
+		return *ticket.StartDate, ticket.StartDate.Add(length)
+	default:
+		return ticket.DueDate.Add(-length), *ticket.DueDate
+	}
+}
+
+// topologicalOrder sorts the tasks so each comes after its predecessors. "blocks" links
+// cannot form cycles, but any task caught in one is appended as it is.
+func topologicalOrder(tasks []*roadmapTask) []*roadmapTask {
+	pending := make(map[*roadmapTask]int, len(tasks))
+	var ready []*roadmapTask
+	for _, task := range tasks {
+		pending[task] = len(task.predecessors)
+		if len(task.predecessors) == 0 {
+			ready = append(ready, task)
+		}
+	}
+
+	ordered := make([]*roadmapTask, 0, len(tasks))
+	placed := make(map[*roadmapTask]bool, len(tasks))
+	for len(ready) > 0 {
+		task := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, task)
+		placed[task] = true
+		for _, next := range task.successors {
+			pending[next]--
+			if pending[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	for _, task := range tasks {
+		if !placed[task] {
+			ordered = append(ordered, task)
+		}
+	}
+	return ordered
+}
+
+// scheduleTasks runs the critical path method over tasks in topological order. It sets
+// each bar's projected dates, slack and critical flag, and returns the critical path.
+func scheduleTasks(ordered []*roadmapTask) []string {
+	if len(ordered) == 0 {
+		return []string{}
+	}
+
+	// Forward pass: a task starts as planned, or once its unfinished predecessors end
+	var finish time.Time
+	for _, task := range ordered {
+		task.earliestStart = task.bar.Start
+		if !task.bar.Done {
+			for _, before := range task.predecessors {
+				if !before.bar.Done && before.earliestEnd.After(task.earliestStart) {
+					task.earliestStart = before.earliestEnd
+				}
+			}
+		}
+		task.earliestEnd = task.earliestStart.Add(task.duration)
+		if task.earliestEnd.After(finish) {
+			finish = task.earliestEnd
+		}
+	}
+
+	// Backward pass: the latest a task can end without delaying what follows it
+	for i := len(ordered) - 1; i >= 0; i-- {
+		task := ordered[i]
+		task.latestEnd = finish
+		for _, after := range task.successors {
+			if !after.latestStart.IsZero() && after.latestStart.Before(task.latestEnd) {
+				task.latestEnd = after.latestStart
+			}
+		}
+		task.latestStart = task.latestEnd.Add(-task.duration)
+	}
+
+	var last *roadmapTask
+	for _, task := range ordered {
+		slack := task.latestStart.Sub(task.earliestStart)
+		task.bar.ProjectedStart = task.earliestStart
+		task.bar.ProjectedEnd = task.earliestEnd
+		task.bar.SlackHours = math.Round(slack.Hours()*100) / 100
+		task.bar.Critical = slack < time.Minute
+		if task.bar.Critical && (last == nil || task.earliestEnd.After(last.earliestEnd)) {
+			last = task
+		}
+	}
+
+	// Walk back from the critical task ending last through the predecessors driving it
+	var path []string
+	seen := make(map[*roadmapTask]bool)
+	for task := last; task != nil && !seen[task]; {
+		seen[task] = true
+		path = append(path, task.bar.TicketID)
+		var driver *roadmapTask
+		for _, before := range task.predecessors {
+			if before.bar.Critical && !before.earliestEnd.Before(task.earliestStart) {
+				driver = before
+				break
+			}
+		}
+		task = driver
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
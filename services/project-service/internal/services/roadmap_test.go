@@ -0,0 +1,144 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+var roadmapStart = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+func roadmapDay(n int) time.Time {
+	return roadmapStart.AddDate(0, 0, n)
+}
+
+// newRoadmapTask plans a bar from day start to day end
+func newRoadmapTask(id string, start, end int, done bool) *roadmapTask {
+	bar := &tenant_models.RoadmapBar{TicketID: id, Start: roadmapDay(start), End: roadmapDay(end), Done: done}
+	return &roadmapTask{bar: bar, duration: bar.End.Sub(bar.Start)}
+}
+
+// blocks links before to after as a "blocks" link would
+func blocks(before, after *roadmapTask) {
+	before.successors = append(before.successors, after)
+	after.predecessors = append(after.predecessors, before)
+}
+
+func checkProjection(t *testing.T, task *roadmapTask, start, end int, slackHours float64, critical bool) {
+	t.Helper()
+	bar := task.bar
+	if !bar.ProjectedStart.Equal(roadmapDay(start)) || !bar.ProjectedEnd.Equal(roadmapDay(end)) {
+		t.Errorf("%s projected %s..%s, want %s..%s", bar.TicketID,
+			bar.ProjectedStart.Format(time.DateOnly), bar.ProjectedEnd.Format(time.DateOnly),
+			roadmapDay(start).Format(time.DateOnly), roadmapDay(end).Format(time.DateOnly))
+	}
+	if bar.SlackHours != slackHours || bar.Critical != critical {
+		t.Errorf("%s slack = %vh critical = %v, want %vh %v", bar.TicketID, bar.SlackHours, bar.Critical, slackHours, critical)
+	}
+}
+
+func TestScheduleChain(t *testing.T) {
+	// b is planned to start before a ends, and c right after b
+	a := newRoadmapTask("a", 0, 3, false)
+	b := newRoadmapTask("b", 1, 3, false)
+	c := newRoadmapTask("c", 3, 4, false)
+	blocks(a, b)
+	blocks(b, c)
+
+	path := scheduleTasks(topologicalOrder([]*roadmapTask{c, b, a}))
+
+	checkProjection(t, a, 0, 3, 0, true)
+	checkProjection(t, b, 3, 5, 0, true)
+	checkProjection(t, c, 5, 6, 0, true)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(path, want) {
+		t.Errorf("critical path = %v, want %v", path, want)
+	}
+}
+
+func TestScheduleParallelBranch(t *testing.T) {
+	// a and b both block c; b is shorter, so it can slip by a day
+	a := newRoadmapTask("a", 0, 2, false)
+	b := newRoadmapTask("b", 0, 1, false)
+	c := newRoadmapTask("c", 2, 5, false)
+	blocks(a, c)
+	blocks(b, c)
+
+	path := scheduleTasks(topologicalOrder([]*roadmapTask{c, b, a}))
+
+	checkProjection(t, a, 0, 2, 0, true)
+	checkProjection(t, b, 0, 1, 24, false)
+	checkProjection(t, c, 2, 5, 0, true)
+	if want := []string{"a", "c"}; !reflect.DeepEqual(path, want) {
+		t.Errorf("critical path = %v, want %v", path, want)
+	}
+}
+
+func TestScheduleDonePredecessor(t *testing.T) {
+	// a is done although planned to run until day 4, so b keeps its planned dates
+	a := newRoadmapTask("a", 0, 4, true)
+	b := newRoadmapTask("b", 1, 2, false)
+	c := newRoadmapTask("c", 2, 3, false)
+	blocks(a, b)
+	blocks(b, c)
+
+	scheduleTasks(topologicalOrder([]*roadmapTask{a, b, c}))
+
+	checkProjection(t, b, 1, 2, 24, false)
+	checkProjection(t, c, 2, 3, 24, false)
+}
+
+func TestScheduleEmpty(t *testing.T) {
+	if path := scheduleTasks(topologicalOrder(nil)); path == nil || len(path) != 0 {
+		t.Errorf("critical path = %#v, want an empty path", path)
+	}
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	a := newRoadmapTask("a", 0, 1, false)
+	b := newRoadmapTask("b", 1, 2, false)
+	c := newRoadmapTask("c", 2, 3, false)
+	d := newRoadmapTask("d", 0, 1, false)
+	e := newRoadmapTask("e", 0, 1, false)
+	blocks(a, b)
+	blocks(b, c)
+	// A cycle cannot come from "blocks" links, but must not lose its tasks
+	blocks(d, e)
+	blocks(e, d)
+
+	var got []string
+	for _, task := range topologicalOrder([]*roadmapTask{c, d, b, e, a}) {
+		got = append(got, task.bar.TicketID)
+	}
+	if want := []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("topologicalOrder = %v, want %v", got, want)
+	}
+}
+
+func TestTicketSchedule(t *testing.T) {
+	hours := func(h float64) *float64 { return &h }
+	at := func(day int) *time.Time {
+		d := roadmapDay(day)
+		return &d
+	}
+	tests := []struct {
+		name       string
+		ticket     tenant_models.Ticket
+		start, end int
+	}{
+		{"both dates", tenant_models.Ticket{StartDate: at(1), DueDate: at(4)}, 1, 4},
+		{"start and estimate", tenant_models.Ticket{StartDate: at(1), EstimatedHours: hours(12)}, 1, 3},
+		{"start only", tenant_models.Ticket{StartDate: at(1)}, 1, 2},
+		{"due and estimate", tenant_models.Ticket{DueDate: at(5), EstimatedHours: hours(24)}, 2, 5},
+		{"due before start", tenant_models.Ticket{StartDate: at(4), DueDate: at(2)}, 4, 5},
+	}
+	for _, tt := range tests {
+		start, end := ticketSchedule(&tt.ticket)
+		if !start.Equal(roadmapDay(tt.start)) || !end.Equal(roadmapDay(tt.end)) {
+			t.Errorf("%s: ticketSchedule = %s..%s, want %s..%s", tt.name,
+				start.Format(time.DateOnly), end.Format(time.DateOnly),
+				roadmapDay(tt.start).Format(time.DateOnly), roadmapDay(tt.end).Format(time.DateOnly))
+		}
+	}
+}
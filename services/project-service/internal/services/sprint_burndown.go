@@ -0,0 +1,164 @@
+package services
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// burndownFields are the ticket history fields a sprint's scope and progress follow from
+var burndownFields = []string{"sprint_id", "story_points", "status"}
+
+// ticketTimeline replays one ticket's history of the burndown fields
+type ticketTimeline struct {
+	ticket  *tenant_models.Ticket
+	changes map[string][]*tenant_models.TicketHistory // By field, oldest first
+}
+
+// valueAt returns the field's value at t: the newest change made by then, or when the
+// field only changed later, the value it had before that first change
+func (tl *ticketTimeline) valueAt(field string, t time.Time, current string) string {
+	changes := tl.changes[field]
+	for i := len(changes) - 1; i >= 0; i-- {
+		if !changes[i].ChangedAt.After(t) {
+			return stringValue(changes[i].NewValue)
+		}
+	}
+	if len(changes) > 0 {
+		return stringValue(changes[0].OldValue)
+	}
+	return current
+}
+
+// existsAt reports whether the ticket had been created and not yet deleted at t
+func (tl *ticketTimeline) existsAt(t time.Time) bool {
+	if tl.ticket.CreatedAt.After(t) {
+		return false
+	}
+	return !tl.ticket.DeletedAt.Valid || tl.ticket.DeletedAt.Time.After(t)
+}
+
+// GetBurndown rebuilds the sprint's scope and completed work at the end of each day from
+// ticket history, so tickets added, removed or re-estimated mid-sprint show up on the
+// day it happened
+func (s *sprintService) GetBurndown(userID, tenantID, projectID, sprintID string) (*tenant_models.SprintBurndown, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	sprint, err := s.repo.GetSprint(tenantID, projectID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.StartedAt == nil || sprint.EndDate == nil {
+		return nil, validationError("the sprint has not started")
+	}
+
+	tickets, err := s.repo.ScopeTickets(tenantID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(tickets))
+	timelines := make(map[string]*ticketTimeline, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+		timelines[ticket.ID] = &ticketTimeline{ticket: ticket, changes: make(map[string][]*tenant_models.TicketHistory)}
+	}
+	history, err := s.repo.TicketHistory(tenantID, ids, burndownFields)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range history {
+		tl := timelines[entry.TicketID]
+		tl.changes[entry.FieldName] = append(tl.changes[entry.FieldName], entry)
+	}
+	done, err := s.doneStatuses(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := *sprint.StartedAt
+	end := *sprint.EndDate
+	now := time.Now()
+	if sprint.CompletedAt != nil {
+		// A sprint finished early or late is charted up to when it completed
+		end = *sprint.CompletedAt
+		now = *sprint.CompletedAt
+	} else if now.After(end) {
+		end = now
+	}
+
+	burndown := &tenant_models.SprintBurndown{
+		SprintID:        sprint.ID,
+		StartedAt:       start,
+		EndDate:         end,
+		CommittedPoints: sprint.CommittedPoints,
+	}
+
+	firstDay := start.UTC().Truncate(24 * time.Hour)
+	for day := firstDay; !day.After(end); day = day.AddDate(0, 0, 1) {
+		at := day.AddDate(0, 0, 1)
+		if at.After(end) {
+			at = end
+		}
+
+		// The ideal line falls from the committed points to zero at the planned end
+		ideal := sprint.CommittedPoints
+		if planned := sprint.EndDate.Sub(start); planned > 0 {
+			elapsed := at.Sub(start)
+			if elapsed > planned {
+				elapsed = planned
+			}
+			ideal = sprint.CommittedPoints * (1 - float64(elapsed)/float64(planned))
+		}
+		entry := tenant_models.SprintBurndownDay{
+			Date:        day.Format("2006-01-02"),
+			IdealPoints: ideal,
+		}
+
+		if !day.After(now) {
+			if at.After(now) {
+				at = now
+			}
+			scope, completed, scopeTickets, completedTickets := sprintStateAt(sprint.ID, timelines, done, at)
+			remaining := scope - completed
+			entry.ScopePoints = &scope
+			entry.CompletedPoints = &completed
+			entry.RemainingPoints = &remaining
+			entry.ScopeTickets = &scopeTickets
+			entry.CompletedTickets = &completedTickets
+		}
+		burndown.Days = append(burndown.Days, entry)
+	}
+	return burndown, nil
+}
+
+// sprintStateAt totals the points and tickets in the sprint at t, and how many of them
+// were done
+func sprintStateAt(sprintID string, timelines map[string]*ticketTimeline, done map[string]bool, t time.Time) (float64, float64, int, int) {
+	var scope, completed float64
+	var scopeTickets, completedTickets int
+	for _, tl := range timelines {
+		if !tl.existsAt(t) {
+			continue
+		}
+		if tl.valueAt("sprint_id", t, stringValue(tl.ticket.SprintID)) != sprintID {
+			continue
+		}
+
+		current := ""
+		if tl.ticket.StoryPoints != nil {
+			current = strconv.FormatFloat(*tl.ticket.StoryPoints, 'f', -1, 64)
+		}
+		estimate, _ := strconv.ParseFloat(tl.valueAt("story_points", t, current), 64)
+
+		scope += estimate
+		scopeTickets++
+		if done[tl.valueAt("status", t, string(tl.ticket.Status))] {
+			completed += estimate
+			completedTickets++
+		}
+	}
+	return scope, completed, scopeTickets, completedTickets
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"project-service/internal/repositories"
+)
+
+const (
+	defaultVelocitySprints = 5
+	maxVelocitySprints     = 20
+)
+
+type SprintService interface {
+	ListSprints(userID, tenantID, projectID string, state tenant_models.SprintState) ([]*tenant_models.Sprint, error)
+	CreateSprint(userID, tenantID, projectID string, req *tenant_models.SprintCreateRequest) (*tenant_models.Sprint, error)
+	GetSprint(userID, tenantID, projectID, sprintID string) (*tenant_models.SprintView, error)
+	UpdateSprint(userID, tenantID, projectID, sprintID string, req *tenant_models.SprintUpdateRequest) (*tenant_models.Sprint, error)
+	DeleteSprint(userID, tenantID, projectID, sprintID string) error
+
+	// StartSprint makes a planned sprint the project's active one; CompleteSprint closes
+	// the active sprint and carries its unfinished tickets over
+	StartSprint(userID, tenantID, projectID, sprintID string, req *tenant_models.SprintStartRequest) (*tenant_models.Sprint, error)
+	CompleteSprint(userID, tenantID, projectID, sprintID string, req *tenant_models.SprintCompleteRequest) (*tenant_models.Sprint, error)
+
+	// Sprint planning
+	AddTickets(userID, tenantID, projectID, sprintID string, req *tenant_models.SprintTicketsRequest) error
+	RemoveTicket(userID, tenantID, projectID, sprintID, ticketID string) error
+	GetBacklog(userID, tenantID, projectID string, limit, offset int) ([]tenant_models.BoardCard, int64, error)
+
+	// Reports
+	GetBurndown(userID, tenantID, projectID, sprintID string) (*tenant_models.SprintBurndown, error)
+	GetVelocity(userID, tenantID, projectID string, sprints int) (*tenant_models.ProjectVelocity, error)
+}
+
+type sprintService struct {
+	repo     repositories.SprintRepository
+	projects repositories.ProjectRepository
+	logger   *zap.Logger
+}
+
+func NewSprintService(repo repositories.SprintRepository, projects repositories.ProjectRepository, logger *zap.Logger) SprintService {
+	return &sprintService{
+		repo:     repo,
+		projects: projects,
+		logger:   logger,
+	}
+}
+
+func (s *sprintService) ListSprints(userID, tenantID, projectID string, state tenant_models.SprintState) ([]*tenant_models.Sprint, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	switch state {
+	case "", tenant_models.SprintPlanned, tenant_models.SprintActive, tenant_models.SprintCompleted:
+	default:
+		return nil, validationError("unknown sprint state %q", state)
+	}
+
+	return s.repo.ListSprints(tenantID, projectID, state)
+}
+
+func (s *sprintService) CreateSprint(userID, tenantID, projectID string, req *tenant_models.SprintCreateRequest) (*tenant_models.Sprint, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	project, err := s.projects.GetProject(tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Methodology != tenant_models.MethodologyScrum {
+		return nil, validationError("sprints are only available on scrum projects")
+	}
+	if err := checkSprintDates(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	sprint := &tenant_models.Sprint{
+		ProjectID: projectID,
+		Name:      strings.TrimSpace(req.Name),
+		Goal:      req.Goal,
+		State:     tenant_models.SprintPlanned,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		CreatedBy: userID,
+	}
+	if err := s.repo.CreateSprint(tenantID, sprint); err != nil {
+		return nil, fmt.Errorf("failed to create sprint: %w", err)
+	}
+
+	s.logger.Info("Sprint created",
+		zap.String("sprint_id", sprint.ID),
+		zap.String("project_id", projectID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	return sprint, nil
+}
+
+// GetSprint returns the sprint with its tickets and progress
+func (s *sprintService) GetSprint(userID, tenantID, projectID, sprintID string) (*tenant_models.SprintView, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	sprint, err := s.repo.GetSprint(tenantID, projectID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	tickets, err := s.repo.SprintTickets(tenantID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	done, err := s.doneStatuses(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Progress covers the whole sprint; restricted tickets are only listed to those who see them
+	viewer := ticketViewer(s.projects, userID, tenantID, projectID)
+	view := &tenant_models.SprintView{
+		Sprint:  *sprint,
+		Tickets: make([]tenant_models.BoardCard, 0, len(tickets)),
+	}
+	for _, ticket := range tickets {
+		if viewer.Sees(ticket) {
+			view.Tickets = append(view.Tickets, ticket.ToCard())
+		}
+
+		view.Progress.TotalTickets++
+		if ticket.StoryPoints == nil {
+			view.Progress.UnestimatedTickets++
+		} else {
+			view.Progress.TotalPoints += *ticket.StoryPoints
+		}
+		if done[string(ticket.Status)] {
+			view.Progress.DoneTickets++
+			view.Progress.DonePoints += points(ticket)
+		}
+	}
+	return view, nil
+}
+
+func (s *sprintService) UpdateSprint(userID, tenantID, projectID, sprintID string, req *tenant_models.SprintUpdateRequest) (*tenant_models.Sprint, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	sprint, err := s.repo.GetSprint(tenantID, projectID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State == tenant_models.SprintCompleted {
+		return nil, validationError("completed sprints cannot be changed")
+	}
+
+	if req.Name != nil {
+		sprint.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Goal != nil {
+		sprint.Goal = *req.Goal
+	}
+	if req.StartDate != nil {
+		if sprint.State == tenant_models.SprintActive {
+			return nil, validationError("the start date of an active sprint cannot be changed")
+		}
+		sprint.StartDate = req.StartDate
+	}
+	if req.EndDate != nil {
+		sprint.EndDate = req.EndDate
+	}
+	if err := checkSprintDates(sprint.StartDate, sprint.EndDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateSprint(tenantID, sprint); err != nil {
+		return nil, fmt.Errorf("failed to update sprint: %w", err)
+	}
+	return sprint, nil
+}
+
+// DeleteSprint removes a planned sprint; its tickets go back to the backlog
+func (s *sprintService) DeleteSprint(userID, tenantID, projectID, sprintID string) error {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return ErrAccessDenied
+	}
+
+	sprint, err := s.repo.GetSprint(tenantID, projectID, sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.State != tenant_models.SprintPlanned {
+		return validationError("only planned sprints can be deleted")
+	}
+
+	if err := s.repo.DeleteSprint(tenantID, sprint, userID); err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+
+	s.logger.Info("Sprint deleted",
+		zap.String("sprint_id", sprintID),
+		zap.String("project_id", projectID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+// StartSprint activates the sprint and records what it was committed to
+func (s *sprintService) StartSprint(userID, tenantID, projectID, sprintID string, req *tenant_models.SprintStartRequest) (*tenant_models.Sprint, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	sprint, err := s.repo.GetSprint(tenantID, projectID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State != tenant_models.SprintPlanned {
+		return nil, validationError("only planned sprints can be started")
+	}
+
+	active, err := s.repo.ActiveSprint(tenantID, projectID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if active != nil {
+		return nil, validationError("sprint %q is still active; complete it first", active.Name)
+	}
+
+	now := time.Now()
+	if req.StartDate != nil {
+		sprint.StartDate = req.StartDate
+	}
+	if sprint.StartDate == nil {
+		sprint.StartDate = &now
+	}
+	if req.EndDate != nil {
+		sprint.EndDate = req.EndDate
+	}
+	if sprint.EndDate == nil {
+		return nil, validationError("an end date is required to start a sprint")
+	}
+	if err := checkSprintDates(sprint.StartDate, sprint.EndDate); err != nil {
+		return nil, err
+	}
+	if !sprint.EndDate.After(now) {
+		return nil, validationError("end_date must be in the future")
+	}
+
+	tickets, err := s.repo.SprintTickets(tenantID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	sprint.CommittedTickets = len(tickets)
+	sprint.CommittedPoints = 0
+	for _, ticket := range tickets {
+		sprint.CommittedPoints += points(ticket)
+	}
+
+	sprint.State = tenant_models.SprintActive
+	sprint.StartedAt = &now
+	if err := s.repo.UpdateSprint(tenantID, sprint); err != nil {
+		return nil, fmt.Errorf("failed to start sprint: %w", err)
+	}
+
+	s.logger.Info("Sprint started",
+		zap.String("sprint_id", sprintID),
+		zap.String("project_id", projectID),
+		zap.String("tenant_id", tenantID),
+		zap.Int("tickets", sprint.CommittedTickets),
+		zap.Float64("points", sprint.CommittedPoints))
+
+	return sprint, nil
+}
+
+// CompleteSprint records what got done and moves the rest to the chosen planned sprint
+// or back to the backlog
+func (s *sprintService) CompleteSprint(userID, tenantID, projectID, sprintID string, req *tenant_models.SprintCompleteRequest) (*tenant_models.Sprint, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	sprint, err := s.repo.GetSprint(tenantID, projectID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State != tenant_models.SprintActive {
+		return nil, validationError("only the active sprint can be completed")
+	}
+
+	if req.CarryOverSprintID != nil {
+		target, err := s.repo.GetSprint(tenantID, projectID, *req.CarryOverSprintID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, validationError("carry_over_sprint_id is not a sprint of this project")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if target.State != tenant_models.SprintPlanned {
+			return nil, validationError("unfinished tickets can only move to a planned sprint")
+		}
+	}
+
+	tickets, err := s.repo.SprintTickets(tenantID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	done, err := s.doneStatuses(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var carryOver []*tenant_models.Ticket
+	sprint.CompletedPoints, sprint.CompletedTickets = 0, 0
+	for _, ticket := range tickets {
+		if done[string(ticket.Status)] {
+			sprint.CompletedTickets++
+			sprint.CompletedPoints += points(ticket)
+		} else {
+			carryOver = append(carryOver, ticket)
+		}
+	}
+
+	now := time.Now()
+	sprint.State = tenant_models.SprintCompleted
+	sprint.CompletedAt = &now
+	sprint.CarriedOverTickets = len(carryOver)
+	if err := s.repo.CompleteSprint(tenantID, sprint, carryOver, req.CarryOverSprintID, userID); err != nil {
+		return nil, fmt.Errorf("failed to complete sprint: %w", err)
+	}
+
+	s.logger.Info("Sprint completed",
+		zap.String("sprint_id", sprintID),
+		zap.String("project_id", projectID),
+		zap.String("tenant_id", tenantID),
+		zap.Int("completed_tickets", sprint.CompletedTickets),
+		zap.Int("carried_over", sprint.CarriedOverTickets))
+
+	return sprint, nil
+}
+
+// AddTickets pulls project tickets into a sprint that is not completed yet. Tickets
+// already in another sprint move over.
+func (s *sprintService) AddTickets(userID, tenantID, projectID, sprintID string, req *tenant_models.SprintTicketsRequest) error {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return ErrAccessDenied
+	}
+
+	sprint, err := s.repo.GetSprint(tenantID, projectID, sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.State == tenant_models.SprintCompleted {
+		return validationError("tickets cannot be added to a completed sprint")
+	}
+
+	ids := uniqueStrings(req.TicketIDs)
+	tickets, err := s.repo.ProjectTickets(tenantID, projectID, ids)
+	if err != nil {
+		return err
+	}
+	if len(tickets) != len(ids) {
+		return validationError("every ticket must belong to the project")
+	}
+
+	if err := s.repo.MoveTickets(tenantID, tickets, &sprint.ID, userID); err != nil {
+		return fmt.Errorf("failed to add tickets to sprint: %w", err)
+	}
+	return nil
+}
+
+// RemoveTicket returns one of the sprint's tickets to the backlog
+func (s *sprintService) RemoveTicket(userID, tenantID, projectID, sprintID, ticketID string) error {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return ErrAccessDenied
+	}
+
+	sprint, err := s.repo.GetSprint(tenantID, projectID, sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.State == tenant_models.SprintCompleted {
+		return validationError("tickets cannot be removed from a completed sprint")
+	}
+
+	tickets, err := s.repo.ProjectTickets(tenantID, projectID, []string{ticketID})
+	if err != nil {
+		return err
+	}
+	if len(tickets) == 0 || stringValue(tickets[0].SprintID) != sprint.ID {
+		return validationError("the ticket is not in this sprint")
+	}
+
+	if err := s.repo.MoveTickets(tenantID, tickets, nil, userID); err != nil {
+		return fmt.Errorf("failed to remove ticket from sprint: %w", err)
+	}
+	return nil
+}
+
+// GetBacklog returns the project's unfinished tickets that no open sprint holds, in
+// board order
+func (s *sprintService) GetBacklog(userID, tenantID, projectID string, limit, offset int) ([]tenant_models.BoardCard, int64, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, 0, ErrAccessDenied
+	}
+
+	done, err := s.repo.DoneStatuses(tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	viewer := ticketViewer(s.projects, userID, tenantID, projectID)
+	tickets, total, err := s.repo.BacklogTickets(tenantID, projectID, done, viewer, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cards := make([]tenant_models.BoardCard, 0, len(tickets))
+	for _, ticket := range tickets {
+		cards = append(cards, ticket.ToCard())
+	}
+	return cards, total, nil
+}
+
+// GetVelocity reports the points completed in the project's last sprints
+func (s *sprintService) GetVelocity(userID, tenantID, projectID string, sprints int) (*tenant_models.ProjectVelocity, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	if sprints <= 0 {
+		sprints = defaultVelocitySprints
+	}
+	if sprints > maxVelocitySprints {
+		sprints = maxVelocitySprints
+	}
+
+	completed, err := s.repo.CompletedSprints(tenantID, projectID, sprints)
+	if err != nil {
+		return nil, err
+	}
+
+	velocity := &tenant_models.ProjectVelocity{
+		Sprints: make([]tenant_models.SprintVelocity, 0, len(completed)),
+	}
+	var total float64
+	for i := len(completed) - 1; i >= 0; i-- {
+		sprint := completed[i]
+		velocity.Sprints = append(velocity.Sprints, tenant_models.SprintVelocity{
+			SprintID:         sprint.ID,
+			Name:             sprint.Name,
+			CompletedAt:      sprint.CompletedAt,
+			CommittedPoints:  sprint.CommittedPoints,
+			CompletedPoints:  sprint.CompletedPoints,
+			CompletedTickets: sprint.CompletedTickets,
+		})
+		total += sprint.CompletedPoints
+	}
+	if len(completed) > 0 {
+		velocity.AveragePoints = total / float64(len(completed))
+	}
+	return velocity, nil
+}
+
+// doneStatuses returns the statuses that count as finished work as a set
+func (s *sprintService) doneStatuses(tenantID string) (map[string]bool, error) {
+	names, err := s.repo.DoneStatuses(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[string]bool, len(names))
+	for _, name := range names {
+		done[name] = true
+	}
+	return done, nil
+}
+
+func checkSprintDates(start, end *time.Time) error {
+	if start != nil && end != nil && !end.After(*start) {
+		return validationError("end_date must be after start_date")
+	}
+	return nil
+}
+
+// points is the ticket's story point estimate; unestimated tickets count for nothing
+func points(ticket *tenant_models.Ticket) float64 {
+	if ticket.StoryPoints == nil {
+		return 0
+	}
+	return *ticket.StoryPoints
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,753 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/rank"
+	"github.com/zen/shared/pkg/tenant_models"
+	"project-service/internal/repositories"
+)
+
+// statusCategories are the categories a template's custom statuses may be in
+var statusCategories = map[tenant_models.StatusCategory]bool{
+	tenant_models.StatusCategoryOpen:       true,
+	tenant_models.StatusCategoryInProgress: true,
+	tenant_models.StatusCategoryResolved:   true,
+	tenant_models.StatusCategoryClosed:     true,
+}
+
+type TemplateService interface {
+	ListTemplates(userID, tenantID string) ([]*tenant_models.ProjectTemplate, error)
+	CreateTemplate(userID, tenantID string, req *tenant_models.ProjectTemplateCreateRequest) (*tenant_models.ProjectTemplate, error)
+	GetTemplate(userID, tenantID, templateID string) (*tenant_models.ProjectTemplate, error)
+	UpdateTemplate(userID, tenantID, templateID string, req *tenant_models.ProjectTemplateUpdateRequest) (*tenant_models.ProjectTemplate, error)
+	DeleteTemplate(userID, tenantID, templateID string) error
+
+	// SaveAsTemplate stores the project's setup as a new template
+	SaveAsTemplate(userID, tenantID, projectID string, req *tenant_models.ProjectTemplateSaveRequest) (*tenant_models.ProjectTemplate, error)
+
+	// CreateFromTemplate creates a project set up as the template describes
+	CreateFromTemplate(userID, tenantID, templateID string, req *tenant_models.ProjectFromTemplateRequest) (*tenant_models.ProjectResponse, error)
+
+	// CloneProject creates a project set up like an existing one
+	CloneProject(userID, tenantID, projectID string, req *tenant_models.ProjectCloneRequest) (*tenant_models.ProjectResponse, error)
+}
+
+type templateService struct {
+	repo     repositories.TemplateRepository
+	projects repositories.ProjectRepository
+	boards   repositories.BoardRepository
+	logger   *zap.Logger
+}
+
+func NewTemplateService(repo repositories.TemplateRepository, projects repositories.ProjectRepository, boards repositories.BoardRepository, logger *zap.Logger) TemplateService {
+	return &templateService{
+		repo:     repo,
+		projects: projects,
+		boards:   boards,
+		logger:   logger,
+	}
+}
+
+func (s *templateService) ListTemplates(userID, tenantID string) ([]*tenant_models.ProjectTemplate, error) {
+	return s.repo.ListTemplates(tenantID)
+}
+
+func (s *templateService) CreateTemplate(userID, tenantID string, req *tenant_models.ProjectTemplateCreateRequest) (*tenant_models.ProjectTemplate, error) {
+	template := &tenant_models.ProjectTemplate{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		ProjectType: req.ProjectType,
+		Methodology: req.Methodology,
+		Labels:      models.StringArray(uniqueStrings(req.Labels)),
+		Members:     req.Members,
+		Statuses:    req.Statuses,
+		TicketTypes: req.TicketTypes,
+		Boards:      req.Boards,
+		Tickets:     req.Tickets,
+		Links:       req.Links,
+		CreatedBy:   userID,
+	}
+	if err := s.storeTemplate(tenantID, template); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Project template created",
+		zap.String("template_id", template.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	return template, nil
+}
+
+func (s *templateService) GetTemplate(userID, tenantID, templateID string) (*tenant_models.ProjectTemplate, error) {
+	return s.repo.GetTemplate(tenantID, templateID)
+}
+
+func (s *templateService) UpdateTemplate(userID, tenantID, templateID string, req *tenant_models.ProjectTemplateUpdateRequest) (*tenant_models.ProjectTemplate, error) {
+	template, err := s.repo.GetTemplate(tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		template.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+	if req.ProjectType != nil {
+		template.ProjectType = *req.ProjectType
+	}
+	if req.Methodology != nil {
+		template.Methodology = *req.Methodology
+	}
+	if req.Labels != nil {
+		template.Labels = models.StringArray(uniqueStrings(*req.Labels))
+	}
+	if req.Members != nil {
+		template.Members = *req.Members
+	}
+	if req.Statuses != nil {
+		template.Statuses = *req.Statuses
+	}
+	if req.TicketTypes != nil {
+		template.TicketTypes = *req.TicketTypes
+	}
+	if req.Boards != nil {
+		template.Boards = *req.Boards
+	}
+	if req.Tickets != nil {
+		template.Tickets = *req.Tickets
+	}
+	if req.Links != nil {
+		template.Links = *req.Links
+	}
+
+	if err := s.storeTemplate(tenantID, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *templateService) DeleteTemplate(userID, tenantID, templateID string) error {
+	if err := s.repo.DeleteTemplate(tenantID, templateID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Project template deleted",
+		zap.String("template_id", templateID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+func (s *templateService) SaveAsTemplate(userID, tenantID, projectID string, req *tenant_models.ProjectTemplateSaveRequest) (*tenant_models.ProjectTemplate, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	project, err := s.projects.GetProject(tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	// Every member can read templates, so restricted tickets are never saved in one
+	template, err := s.captureProject(tenantID, project, req.IncludeOpenTickets, nil)
+	if err != nil {
+		return nil, err
+	}
+	template.Name = strings.TrimSpace(req.Name)
+	if req.Description != "" {
+		template.Description = req.Description
+	}
+	template.SourceProjectID = &project.ID
+	template.CreatedBy = userID
+
+	if err := s.storeTemplate(tenantID, template); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Project saved as template",
+		zap.String("template_id", template.ID),
+		zap.String("project_id", projectID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	return template, nil
+}
+
+func (s *templateService) CreateFromTemplate(userID, tenantID, templateID string, req *tenant_models.ProjectFromTemplateRequest) (*tenant_models.ProjectResponse, error) {
+	template, err := s.repo.GetTemplate(tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.instantiate(userID, tenantID, template, req)
+}
+
+func (s *templateService) CloneProject(userID, tenantID, projectID string, req *tenant_models.ProjectCloneRequest) (*tenant_models.ProjectResponse, error) {
+	if !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	project, err := s.projects.GetProject(tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	viewer := ticketViewer(s.projects, userID, tenantID, projectID)
+	template, err := s.captureProject(tenantID, project, req.IncludeOpenTickets, &viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.instantiate(userID, tenantID, template, &req.ProjectFromTemplateRequest)
+}
+
+// storeTemplate checks the template and creates or saves it
+func (s *templateService) storeTemplate(tenantID string, template *tenant_models.ProjectTemplate) error {
+	if template.ProjectType == "" {
+		template.ProjectType = tenant_models.ProjectTypeSoftware
+	}
+	if template.Methodology == "" {
+		template.Methodology = tenant_models.MethodologyKanban
+	}
+	if err := s.checkTemplate(tenantID, template); err != nil {
+		return err
+	}
+
+	taken, err := s.repo.TemplateNameTaken(tenantID, template.Name, template.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return validationError("a template named %q already exists", template.Name)
+	}
+
+	if template.ID == "" {
+		err = s.repo.CreateTemplate(tenantID, template)
+	} else {
+		err = s.repo.UpdateTemplate(tenantID, template)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save template: %w", err)
+	}
+	return nil
+}
+
+// checkTemplate makes sure projects can be created from the template: its statuses and
+// refs are known, and its links neither loop nor give a ticket two parents
+func (s *templateService) checkTemplate(tenantID string, template *tenant_models.ProjectTemplate) error {
+	known, err := knownStatuses(s.boards, tenantID)
+	if err != nil {
+		return err
+	}
+	builtin := make(map[string]bool, len(builtinStatuses))
+	for _, status := range builtinStatuses {
+		builtin[string(status)] = true
+	}
+	for _, status := range template.Statuses {
+		if builtin[status.Name] {
+			return validationError("%q is a built-in status", status.Name)
+		}
+		if !statusCategories[status.Category] {
+			return validationError("status %q: invalid category %q", status.Name, status.Category)
+		}
+		known[status.Name] = true
+	}
+
+	for _, board := range template.Boards {
+		if _, err := boardColumns(known, nil, board.Columns); err != nil {
+			return err
+		}
+	}
+
+	refs := make(map[string]bool, len(template.Tickets))
+	for _, ticket := range template.Tickets {
+		if refs[ticket.Ref] {
+			return validationError("two tickets have the ref %q", ticket.Ref)
+		}
+		refs[ticket.Ref] = true
+
+		if ticket.Status != "" && !known[string(ticket.Status)] {
+			return validationError("ticket %q: unknown status %q", ticket.Ref, ticket.Status)
+		}
+		if ticket.StartInDays != nil && ticket.DueInDays != nil && *ticket.DueInDays < *ticket.StartInDays {
+			return validationError("ticket %q is due before it starts", ticket.Ref)
+		}
+	}
+
+	linked := map[string]bool{}
+	parents := map[string]string{}
+	graphs := map[tenant_models.TicketLinkType]map[string][]string{}
+	for _, link := range template.Links {
+		if !refs[link.SourceRef] || !refs[link.TargetRef] {
+			return validationError("link %q to %q: unknown ticket ref", link.SourceRef, link.TargetRef)
+		}
+		if link.SourceRef == link.TargetRef {
+			return validationError("ticket %q cannot be linked to itself", link.SourceRef)
+		}
+		key := link.SourceRef + "\x00" + link.TargetRef + "\x00" + string(link.LinkType)
+		if linked[key] {
+			return validationError("tickets %q and %q are linked twice", link.SourceRef, link.TargetRef)
+		}
+		linked[key] = true
+
+		if link.LinkType == tenant_models.LinkTypeSubtask {
+			if parent, ok := parents[link.TargetRef]; ok && parent != link.SourceRef {
+				return validationError("ticket %q has two parents", link.TargetRef)
+			}
+			parents[link.TargetRef] = link.SourceRef
+		}
+		if graphs[link.LinkType] == nil {
+			graphs[link.LinkType] = map[string][]string{}
+		}
+		graphs[link.LinkType][link.SourceRef] = append(graphs[link.LinkType][link.SourceRef], link.TargetRef)
+	}
+	for _, linkType := range []tenant_models.TicketLinkType{tenant_models.LinkTypeSubtask, tenant_models.LinkTypeBlocks} {
+		if hasCycle(graphs[linkType]) {
+			return validationError("%s links cannot form a cycle", linkType)
+		}
+	}
+	return nil
+}
+
+// captureProject describes the project as a template, its key and name replaced with
+// placeholders. Open tickets are included as starter tickets, dated from today.
+func (s *templateService) captureProject(tenantID string, project *tenant_models.Project, includeTickets bool, viewer *repositories.TicketViewer) (*tenant_models.ProjectTemplate, error) {
+	names := newPlaceholders(project)
+	template := &tenant_models.ProjectTemplate{
+		Description: names.apply(project.Description),
+		ProjectType: project.ProjectType,
+		Methodology: project.Methodology,
+	}
+
+	labels, err := s.repo.ProjectLabels(tenantID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	template.Labels = models.StringArray(uniqueStrings(names.applyAll(labels)))
+
+	members, err := s.projects.ListProjectMembers(tenantID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		template.Members = append(template.Members, tenant_models.TemplateMember{
+			UserID: member.UserID,
+			Role:   member.Role,
+		})
+	}
+
+	boards, err := s.boards.ListBoards(tenantID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	var boardStatuses []string
+	for _, board := range boards {
+		columns := make([]tenant_models.BoardColumnRequest, 0, len(board.Columns))
+		for _, column := range board.Columns {
+			columns = append(columns, tenant_models.BoardColumnRequest{
+				Name:     column.Name,
+				Statuses: column.Statuses,
+				WIPLimit: column.WIPLimit,
+			})
+			boardStatuses = append(boardStatuses, column.Statuses...)
+		}
+		template.Boards = append(template.Boards, tenant_models.BoardCreateRequest{
+			Name:        names.apply(board.Name),
+			Description: names.apply(board.Description),
+			Swimlanes:   board.Swimlanes,
+			IsDefault:   board.IsDefault,
+			Columns:     columns,
+		})
+	}
+
+	statuses, err := s.repo.ProjectStatuses(tenantID, project.ID, uniqueStrings(boardStatuses))
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		template.Statuses = append(template.Statuses, tenant_models.CustomStatusCreateRequest{
+			Name:      status.Name,
+			Category:  status.Category,
+			Color:     status.Color,
+			SortOrder: status.SortOrder,
+		})
+	}
+
+	ticketTypes, err := s.repo.ProjectTicketTypes(tenantID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ticketType := range ticketTypes {
+		template.TicketTypes = append(template.TicketTypes, tenant_models.CustomTicketTypeCreateRequest{
+			Name:        ticketType.Name,
+			Description: ticketType.Description,
+			Icon:        ticketType.Icon,
+			Color:       ticketType.Color,
+		})
+	}
+
+	if includeTickets {
+		if err := s.captureTickets(tenantID, project, names, viewer, template); err != nil {
+			return nil, err
+		}
+	}
+	return template, nil
+}
+
+// captureTickets adds the project's open tickets and the links between them to the
+// template. Tickets are referred to by their number. Restricted tickets are only kept
+// when they show to the viewer; without one they are all left out.
+func (s *templateService) captureTickets(tenantID string, project *tenant_models.Project, names *placeholders, viewer *repositories.TicketViewer, template *tenant_models.ProjectTemplate) error {
+	tickets, err := s.repo.OpenTickets(tenantID, project.ID)
+	if err != nil {
+		return err
+	}
+
+	today := startOfDay(time.Now())
+	refs := make(map[string]string, len(tickets))
+	ids := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		if ticket.Visibility == tenant_models.VisibilityRestricted && (viewer == nil || !viewer.Sees(ticket)) {
+			continue
+		}
+		ref := strconv.Itoa(ticket.TicketNumber)
+		refs[ticket.ID] = ref
+		ids = append(ids, ticket.ID)
+
+		template.Tickets = append(template.Tickets, tenant_models.TemplateTicket{
+			Ref:            ref,
+			Title:          names.apply(ticket.Title),
+			Description:    names.apply(ticket.Description),
+			TicketType:     ticket.TicketType,
+			Priority:       ticket.Priority,
+			Status:         ticket.Status,
+			Visibility:     ticket.Visibility,
+			AssigneeID:     ticket.AssigneeID,
+			Labels:         names.applyAll(ticket.Labels),
+			CustomFields:   ticket.CustomFields,
+			EstimatedHours: ticket.EstimatedHours,
+			StoryPoints:    ticket.StoryPoints,
+			StartInDays:    daysFrom(today, ticket.StartDate),
+			DueInDays:      daysFrom(today, ticket.DueDate),
+		})
+	}
+
+	links, err := s.repo.TicketLinks(tenantID, ids)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		template.Links = append(template.Links, tenant_models.TemplateTicketLink{
+			SourceRef: refs[link.SourceTicketID],
+			TargetRef: refs[link.TargetTicketID],
+			LinkType:  link.LinkType,
+		})
+	}
+	return nil
+}
+
+// instantiate creates the project the request names, set up as the template describes
+func (s *templateService) instantiate(userID, tenantID string, template *tenant_models.ProjectTemplate, req *tenant_models.ProjectFromTemplateRequest) (*tenant_models.ProjectResponse, error) {
+	if err := s.checkTemplate(tenantID, template); err != nil {
+		return nil, err
+	}
+
+	names := strings.NewReplacer(
+		tenant_models.TemplateProjectKey, req.Key,
+		tenant_models.TemplateProjectName, req.Name,
+	)
+	description := template.Description
+	if req.Description != nil {
+		description = *req.Description
+	}
+	labels := make([]string, 0, len(template.Labels))
+	for _, label := range template.Labels {
+		labels = append(labels, names.Replace(label))
+	}
+
+	project, err := newProject(userID, &tenant_models.ProjectCreateRequest{
+		Key:         req.Key,
+		Name:        req.Name,
+		Description: names.Replace(description),
+		ProjectType: template.ProjectType,
+		Methodology: template.Methodology,
+		Labels:      labels,
+		LeadID:      req.LeadID,
+	})
+	if err != nil {
+		return nil, validationError("%s", err)
+	}
+
+	now := time.Now()
+	setup := &repositories.ProjectSetup{}
+	for _, status := range template.Statuses {
+		setup.Statuses = append(setup.Statuses, &tenant_models.CustomStatus{
+			Name:      status.Name,
+			Category:  status.Category,
+			Color:     status.Color,
+			SortOrder: status.SortOrder,
+			IsActive:  true,
+		})
+	}
+	for _, ticketType := range template.TicketTypes {
+		setup.TicketTypes = append(setup.TicketTypes, &tenant_models.CustomTicketType{
+			Name:        ticketType.Name,
+			Description: ticketType.Description,
+			Icon:        ticketType.Icon,
+			Color:       ticketType.Color,
+			IsActive:    true,
+		})
+	}
+
+	// The lead joins first; the template's members keep their roles
+	setup.Members = []*tenant_models.ProjectMember{leadMember(project)}
+	members := map[string]bool{project.LeadID: true}
+	for _, member := range template.Members {
+		if members[member.UserID] {
+			continue
+		}
+		members[member.UserID] = true
+		role := member.Role
+		if role == "" {
+			role = tenant_models.ProjectRoleMember
+		}
+		setup.Members = append(setup.Members, &tenant_models.ProjectMember{
+			ID:        uuid.New().String(),
+			ProjectID: project.ID,
+			UserID:    member.UserID,
+			Role:      role,
+			AddedAt:   now,
+			AddedBy:   userID,
+		})
+	}
+
+	// Templates without boards leave the project with the default one, as CreateProject does
+	setup.Boards = templateBoards(project, template.Boards, names, userID)
+	if len(setup.Boards) == 0 && (project.Methodology == tenant_models.MethodologyKanban || project.Methodology == tenant_models.MethodologyScrum) {
+		setup.Boards = append(setup.Boards, defaultBoard(project.ID, userID))
+	}
+
+	start := startOfDay(now)
+	if req.StartDate != nil {
+		start = startOfDay(*req.StartDate)
+	}
+	setup.Tickets, setup.Links = templateTickets(project, template, names, members, start, userID)
+
+	if err := s.repo.CreateProject(tenantID, project, setup, userID); err != nil {
+		s.logger.Error("Failed to create project from template", zap.Error(err))
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	s.logger.Info("Project created from template",
+		zap.String("project_id", project.ID),
+		zap.String("template", template.Name),
+		zap.Int("tickets", len(setup.Tickets)),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	response := project.ToResponse()
+	return &response, nil
+}
+
+// templateBoards builds the template's boards for the project; the first default board
+// stays the default, or the first board when none is
+func templateBoards(project *tenant_models.Project, requests []tenant_models.BoardCreateRequest, names *strings.Replacer, userID string) []*tenant_models.Board {
+	boards := make([]*tenant_models.Board, 0, len(requests))
+	hasDefault := false
+	for _, req := range requests {
+		board := &tenant_models.Board{
+			ProjectID:   project.ID,
+			Name:        names.Replace(strings.TrimSpace(req.Name)),
+			Description: names.Replace(req.Description),
+			Swimlanes:   req.Swimlanes,
+			IsDefault:   req.IsDefault && !hasDefault,
+			CreatedBy:   userID,
+		}
+		if board.Swimlanes == "" {
+			board.Swimlanes = tenant_models.SwimlaneNone
+		}
+		for i, column := range req.Columns {
+			board.Columns = append(board.Columns, tenant_models.BoardColumn{
+				Name:     strings.TrimSpace(column.Name),
+				Statuses: models.StringArray(column.Statuses),
+				WIPLimit: column.WIPLimit,
+				Position: i,
+			})
+		}
+		hasDefault = hasDefault || board.IsDefault
+		boards = append(boards, board)
+	}
+	if !hasDefault && len(boards) > 0 {
+		boards[0].IsDefault = true
+	}
+	return boards
+}
+
+// templateTickets builds the template's tickets and links for the project, in board
+// order. Assignees who are not members of the project are dropped.
+func templateTickets(project *tenant_models.Project, template *tenant_models.ProjectTemplate, names *strings.Replacer, members map[string]bool, start time.Time, userID string) ([]*tenant_models.Ticket, []*tenant_models.TicketLink) {
+	ranks := rank.Spread(len(template.Tickets))
+	tickets := make([]*tenant_models.Ticket, 0, len(template.Tickets))
+	byRef := make(map[string]*tenant_models.Ticket, len(template.Tickets))
+	for i, item := range template.Tickets {
+		labels := make(models.StringArray, 0, len(item.Labels))
+		for _, label := range item.Labels {
+			labels = append(labels, names.Replace(label))
+		}
+
+		reporterID := userID
+		ticket := &tenant_models.Ticket{
+			ID:             uuid.New().String(),
+			Title:          names.Replace(item.Title),
+			Description:    names.Replace(item.Description),
+			TicketType:     item.TicketType,
+			Priority:       item.Priority,
+			Status:         item.Status,
+			Visibility:     item.Visibility,
+			Channel:        tenant_models.ChannelWeb,
+			ProjectID:      &project.ID,
+			ReporterID:     &reporterID,
+			Labels:         labels,
+			CustomFields:   item.CustomFields,
+			EstimatedHours: item.EstimatedHours,
+			StoryPoints:    item.StoryPoints,
+			StartDate:      dayAfter(start, item.StartInDays),
+			DueDate:        dayAfter(start, item.DueInDays),
+			BoardRank:      ranks[i],
+		}
+		if ticket.TicketType == "" {
+			ticket.TicketType = tenant_models.TicketTypeTask
+		}
+		if ticket.Priority == "" {
+			ticket.Priority = tenant_models.PriorityMedium
+		}
+		if ticket.Status == "" {
+			ticket.Status = tenant_models.StatusOpen
+		}
+		if ticket.Visibility == "" {
+			ticket.Visibility = tenant_models.VisibilityStandard
+		}
+		if ticket.CustomFields == nil {
+			ticket.CustomFields = make(models.JSONB)
+		}
+		if item.AssigneeID != nil && members[*item.AssigneeID] {
+			ticket.AssigneeID = item.AssigneeID
+		}
+		tickets = append(tickets, ticket)
+		byRef[item.Ref] = ticket
+	}
+
+	links := make([]*tenant_models.TicketLink, 0, len(template.Links))
+	for _, link := range template.Links {
+		source, target := byRef[link.SourceRef], byRef[link.TargetRef]
+		if link.LinkType == tenant_models.LinkTypeSubtask {
+			target.ParentTicketID = &source.ID
+		}
+		links = append(links, &tenant_models.TicketLink{
+			SourceTicketID: source.ID,
+			TargetTicketID: target.ID,
+			LinkType:       link.LinkType,
+			CreatedBy:      userID,
+		})
+	}
+	return tickets, links
+}
+
+// placeholders turns a project's name and key back into template placeholders. Both
+// are replaced in one pass so a key cannot match inside a placeholder.
+type placeholders struct {
+	project *tenant_models.Project
+	pattern *regexp.Regexp
+}
+
+func newPlaceholders(project *tenant_models.Project) *placeholders {
+	pattern := `\b` + regexp.QuoteMeta(project.Key) + `\b`
+	if project.Name != "" {
+		pattern = regexp.QuoteMeta(project.Name) + "|" + pattern
+	}
+	return &placeholders{project: project, pattern: regexp.MustCompile(pattern)}
+}
+
+func (p *placeholders) apply(text string) string {
+	return p.pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if match == p.project.Name {
+			return tenant_models.TemplateProjectName
+		}
+		return tenant_models.TemplateProjectKey
+	})
+}
+
+func (p *placeholders) applyAll(texts []string) []string {
+	applied := make([]string, 0, len(texts))
+	for _, text := range texts {
+		applied = append(applied, p.apply(text))
+	}
+	return applied
+}
+
+// hasCycle reports whether following the edges can lead back to where it started
+func hasCycle(edges map[string][]string) bool {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(edges))
+	var visit func(node string) bool
+	visit = func(node string) bool {
+		switch state[node] {
+		case visiting:
+			return true
+		case visited:
+			return false
+		}
+		state[node] = visiting
+		for _, next := range edges[node] {
+			if visit(next) {
+				return true
+			}
+		}
+		state[node] = visited
+		return false
+	}
+
+	for node := range edges {
+		if visit(node) {
+			return true
+		}
+	}
+	return false
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysFrom counts the whole days from day to date
+func daysFrom(day time.Time, date *time.Time) *int {
+	if date == nil {
+		return nil
+	}
+	days := int(math.Floor(date.Sub(day).Hours() / 24))
+	return &days
+}
+
+func dayAfter(day time.Time, days *int) *time.Time {
+	if days == nil {
+		return nil
+	}
+	date := day.AddDate(0, 0, *days)
+	return &date
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"project-service/internal/repositories"
+)
+
+const (
+	// maxWorklogMinutes is the longest single worklog; timers left running longer are
+	// logged at this length
+	maxWorklogMinutes = 24 * 60
+
+	maxRollupTickets = 50
+)
+
+type TimeService interface {
+	// Worklogs on a project's tickets
+	ListWorklogs(userID, tenantID, projectID, ticketID string) ([]*tenant_models.Worklog, error)
+	LogWork(userID, tenantID, projectID, ticketID string, req *tenant_models.WorklogCreateRequest) (*tenant_models.Worklog, error)
+	UpdateWorklog(userID, tenantID, projectID, ticketID, worklogID string, req *tenant_models.WorklogUpdateRequest) (*tenant_models.Worklog, error)
+	DeleteWorklog(userID, tenantID, projectID, ticketID, worklogID string) error
+
+	// Timers; a user has at most one running, and starting another stops it
+	GetTimer(userID, tenantID string) (*tenant_models.WorkTimer, error)
+	StartTimer(userID, tenantID string, req *tenant_models.TimerStartRequest) (*tenant_models.WorkTimer, error)
+	StopTimer(userID, tenantID string) (*tenant_models.Worklog, error)
+	DiscardTimer(userID, tenantID string) error
+
+	// Reports
+	GetTimesheet(tenantID, userID string, week time.Time) (*tenant_models.Timesheet, error)
+	GetProjectTime(userID, tenantID, projectID string, from, to *time.Time) (*tenant_models.ProjectTimeRollup, error)
+}
+
+type timeService struct {
+	repo     repositories.WorklogRepository
+	projects repositories.ProjectRepository
+	logger   *zap.Logger
+}
+
+func NewTimeService(repo repositories.WorklogRepository, projects repositories.ProjectRepository, logger *zap.Logger) TimeService {
+	return &timeService{
+		repo:     repo,
+		projects: projects,
+		logger:   logger,
+	}
+}
+
+func (s *timeService) ListWorklogs(userID, tenantID, projectID, ticketID string) ([]*tenant_models.Worklog, error) {
+	if _, err := s.projectTicket(userID, tenantID, projectID, ticketID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListWorklogs(tenantID, ticketID)
+}
+
+// LogWork records time the user spent on the ticket
+func (s *timeService) LogWork(userID, tenantID, projectID, ticketID string, req *tenant_models.WorklogCreateRequest) (*tenant_models.Worklog, error) {
+	if _, err := s.projectTicket(userID, tenantID, projectID, ticketID); err != nil {
+		return nil, err
+	}
+	if err := checkWorklogStart(req.StartedAt); err != nil {
+		return nil, err
+	}
+
+	worklog := &tenant_models.Worklog{
+		TicketID:        ticketID,
+		UserID:          userID,
+		StartedAt:       req.StartedAt,
+		DurationMinutes: req.DurationMinutes,
+		Billable:        req.Billable,
+		Note:            req.Note,
+	}
+	if err := s.repo.CreateWorklog(tenantID, worklog, userID); err != nil {
+		return nil, fmt.Errorf("failed to log work: %w", err)
+	}
+	return worklog, nil
+}
+
+// UpdateWorklog changes a worklog; users edit their own, project leads and admins any
+func (s *timeService) UpdateWorklog(userID, tenantID, projectID, ticketID, worklogID string, req *tenant_models.WorklogUpdateRequest) (*tenant_models.Worklog, error) {
+	worklog, err := s.ownWorklog(userID, tenantID, projectID, ticketID, worklogID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.StartedAt != nil {
+		if err := checkWorklogStart(*req.StartedAt); err != nil {
+			return nil, err
+		}
+		worklog.StartedAt = *req.StartedAt
+	}
+	if req.DurationMinutes != nil {
+		worklog.DurationMinutes = *req.DurationMinutes
+	}
+	if req.Billable != nil {
+		worklog.Billable = *req.Billable
+	}
+	if req.Note != nil {
+		worklog.Note = *req.Note
+	}
+
+	if err := s.repo.UpdateWorklog(tenantID, worklog, userID); err != nil {
+		return nil, fmt.Errorf("failed to update worklog: %w", err)
+	}
+	return worklog, nil
+}
+
+func (s *timeService) DeleteWorklog(userID, tenantID, projectID, ticketID, worklogID string) error {
+	worklog, err := s.ownWorklog(userID, tenantID, projectID, ticketID, worklogID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteWorklog(tenantID, worklog, userID); err != nil {
+		return fmt.Errorf("failed to delete worklog: %w", err)
+	}
+	return nil
+}
+
+// GetTimer returns the user's running timer, or nil when none is running
+func (s *timeService) GetTimer(userID, tenantID string) (*tenant_models.WorkTimer, error) {
+	timer, err := s.repo.GetTimer(tenantID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return timer, err
+}
+
+// StartTimer starts timing work on a ticket. A timer already running is stopped and its
+// time logged.
+func (s *timeService) StartTimer(userID, tenantID string, req *tenant_models.TimerStartRequest) (*tenant_models.WorkTimer, error) {
+	ticket, err := s.repo.GetTicket(tenantID, req.TicketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.ProjectID == nil {
+		return nil, validationError("time can only be tracked on project tickets")
+	}
+	if !canAccessProject(s.projects, userID, tenantID, *ticket.ProjectID) {
+		return nil, ErrAccessDenied
+	}
+	if !ticketViewer(s.projects, userID, tenantID, *ticket.ProjectID).Sees(ticket) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	running, err := s.GetTimer(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var stopped *tenant_models.Worklog
+	if running != nil {
+		stopped = timerWorklog(running, now)
+	}
+
+	timer := &tenant_models.WorkTimer{
+		UserID:    userID,
+		TicketID:  req.TicketID,
+		StartedAt: now,
+		Billable:  req.Billable,
+		Note:      req.Note,
+	}
+	if err := s.repo.StartTimer(tenantID, timer, stopped); err != nil {
+		return nil, fmt.Errorf("failed to start timer: %w", err)
+	}
+	return timer, nil
+}
+
+// StopTimer stops the user's timer and logs its time
+func (s *timeService) StopTimer(userID, tenantID string) (*tenant_models.Worklog, error) {
+	timer, err := s.repo.GetTimer(tenantID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, validationError("no timer is running")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	worklog := timerWorklog(timer, time.Now())
+	if err := s.repo.StopTimer(tenantID, timer, worklog); err != nil {
+		return nil, fmt.Errorf("failed to stop timer: %w", err)
+	}
+	return worklog, nil
+}
+
+// DiscardTimer stops the user's timer without logging its time
+func (s *timeService) DiscardTimer(userID, tenantID string) error {
+	timer, err := s.repo.GetTimer(tenantID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return validationError("no timer is running")
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.repo.StopTimer(tenantID, timer, nil)
+}
+
+// GetTimesheet returns the time the user logged in the week containing the given day
+func (s *timeService) GetTimesheet(tenantID, userID string, week time.Time) (*tenant_models.Timesheet, error) {
+	day := week.UTC().Truncate(24 * time.Hour)
+	start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)) // Monday
+	end := start.AddDate(0, 0, 7)
+
+	worklogs, err := s.repo.UserWorklogs(tenantID, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	timesheet := &tenant_models.Timesheet{
+		UserID:    userID,
+		WeekStart: start.Format("2006-01-02"),
+		WeekEnd:   end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Days:      make([]tenant_models.TimesheetDay, 7),
+		Tickets:   []tenant_models.TimesheetLine{},
+	}
+	for i := range timesheet.Days {
+		timesheet.Days[i].Date = start.AddDate(0, 0, i).Format("2006-01-02")
+	}
+
+	lines := make(map[string]*tenant_models.TimesheetLine)
+	var order []string
+	for _, worklog := range worklogs {
+		weekday := int(worklog.StartedAt.UTC().Sub(start) / (24 * time.Hour))
+
+		timesheet.Days[weekday].TotalMinutes += worklog.DurationMinutes
+		timesheet.TotalMinutes += worklog.DurationMinutes
+		if worklog.Billable {
+			timesheet.Days[weekday].BillableMinutes += worklog.DurationMinutes
+			timesheet.BillableMinutes += worklog.DurationMinutes
+		}
+
+		line, ok := lines[worklog.TicketID]
+		if !ok {
+			line = &tenant_models.TimesheetLine{TicketID: worklog.TicketID}
+			lines[worklog.TicketID] = line
+			order = append(order, worklog.TicketID)
+		}
+		line.DailyMinutes[weekday] += worklog.DurationMinutes
+		line.TotalMinutes += worklog.DurationMinutes
+	}
+
+	tickets, err := s.repo.GetTickets(tenantID, order)
+	if err != nil {
+		return nil, err
+	}
+	for _, ticket := range tickets {
+		line := lines[ticket.ID]
+		line.TicketNumber = ticket.TicketNumber
+		line.Title = ticket.Title
+		line.ProjectID = ticket.ProjectID
+	}
+	for _, ticketID := range order {
+		timesheet.Tickets = append(timesheet.Tickets, *lines[ticketID])
+	}
+	return timesheet, nil
+}
+
+// GetProjectTime rolls the project's logged time up against its estimates. from and to
+// limit the logged time to a period; estimates always count in full.
+func (s *timeService) GetProjectTime(userID, tenantID, projectID string, from, to *time.Time) (*tenant_models.ProjectTimeRollup, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	tickets, err := s.repo.ProjectTicketTime(tenantID, projectID, from, to, ticketViewer(s.projects, userID, tenantID, projectID))
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.repo.ProjectUserTime(tenantID, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rollup := &tenant_models.ProjectTimeRollup{
+		ProjectID: projectID,
+		Users:     make([]tenant_models.UserTimeRollup, 0, len(users)),
+		Tickets:   make([]tenant_models.TicketTimeRollup, 0, maxRollupTickets),
+	}
+	var loggedMinutes, billableMinutes, unestimatedMinutes int64
+	for _, ticket := range tickets {
+		loggedMinutes += ticket.LoggedMinutes
+		billableMinutes += ticket.BillableMinutes
+		logged := minutesToHours(ticket.LoggedMinutes)
+
+		if ticket.EstimatedHours == nil {
+			unestimatedMinutes += ticket.LoggedMinutes
+		} else {
+			rollup.EstimatedTickets++
+			rollup.EstimatedHours += *ticket.EstimatedHours
+			if logged > *ticket.EstimatedHours {
+				rollup.OverEstimateTickets++
+			} else {
+				rollup.RemainingHours += *ticket.EstimatedHours - logged
+			}
+		}
+
+		// Tickets come most logged first
+		if len(rollup.Tickets) < maxRollupTickets {
+			rollup.Tickets = append(rollup.Tickets, tenant_models.TicketTimeRollup{
+				TicketID:       ticket.TicketID,
+				TicketNumber:   ticket.TicketNumber,
+				Title:          ticket.Title,
+				Status:         ticket.Status,
+				EstimatedHours: ticket.EstimatedHours,
+				LoggedHours:    logged,
+			})
+		}
+	}
+	rollup.LoggedHours = minutesToHours(loggedMinutes)
+	rollup.BillableHours = minutesToHours(billableMinutes)
+	rollup.UnestimatedLogged = minutesToHours(unestimatedMinutes)
+	rollup.EstimatedHours = roundHours(rollup.EstimatedHours)
+	rollup.RemainingHours = roundHours(rollup.RemainingHours)
+
+	for _, user := range users {
+		rollup.Users = append(rollup.Users, tenant_models.UserTimeRollup{
+			UserID:        user.UserID,
+			LoggedHours:   minutesToHours(user.LoggedMinutes),
+			BillableHours: minutesToHours(user.BillableMinutes),
+		})
+	}
+	return rollup, nil
+}
+
+// projectTicket checks the user can see the project and the ticket, and that the ticket
+// belongs to the project. Restricted tickets the user cannot see are not found.
+func (s *timeService) projectTicket(userID, tenantID, projectID, ticketID string) (*tenant_models.Ticket, error) {
+	if !canAccessProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+
+	ticket, err := s.repo.GetTicket(tenantID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if stringValue(ticket.ProjectID) != projectID || !ticketViewer(s.projects, userID, tenantID, projectID).Sees(ticket) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return ticket, nil
+}
+
+// ownWorklog returns a worklog the user may change: their own, or any on a project they
+// can modify
+func (s *timeService) ownWorklog(userID, tenantID, projectID, ticketID, worklogID string) (*tenant_models.Worklog, error) {
+	if _, err := s.projectTicket(userID, tenantID, projectID, ticketID); err != nil {
+		return nil, err
+	}
+
+	worklog, err := s.repo.GetWorklog(tenantID, ticketID, worklogID)
+	if err != nil {
+		return nil, err
+	}
+	if worklog.UserID != userID && !canModifyProject(s.projects, userID, tenantID, projectID) {
+		return nil, ErrAccessDenied
+	}
+	return worklog, nil
+}
+
+// timerWorklog turns a timer stopped at the given time into a worklog
+func timerWorklog(timer *tenant_models.WorkTimer, stoppedAt time.Time) *tenant_models.Worklog {
+	minutes := int(math.Ceil(stoppedAt.Sub(timer.StartedAt).Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes > maxWorklogMinutes {
+		minutes = maxWorklogMinutes
+	}
+
+	return &tenant_models.Worklog{
+		TicketID:        timer.TicketID,
+		UserID:          timer.UserID,
+		StartedAt:       timer.StartedAt,
+		DurationMinutes: minutes,
+		Billable:        timer.Billable,
+		Note:            timer.Note,
+	}
+}
+
+func checkWorklogStart(startedAt time.Time) error {
+	if startedAt.After(time.Now().Add(time.Minute)) {
+		return validationError("started_at cannot be in the future")
+	}
+	return nil
+}
+
+func minutesToHours(minutes int64) float64 {
+	return roundHours(float64(minutes) / 60)
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
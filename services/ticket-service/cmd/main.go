@@ -65,8 +65,80 @@ func main() {
 
 	// Initialize repository, service, and handler
 	ticketRepo := repositories.NewTicketRepository(tenantDBManager)
-	ticketService := services.NewTicketService(ticketRepo, logger)
-	ticketHandler := handlers.NewTicketHandler(ticketService, logger)
+	projectMemberRepo := repositories.NewProjectMemberRepository(tenantDBManager)
+	slaRepo := repositories.NewSLARepository(tenantDBManager)
+	workflowRepo := repositories.NewWorkflowRepository(tenantDBManager)
+	ticketLinkRepo := repositories.NewTicketLinkRepository(tenantDBManager)
+	bulkJobRepo := repositories.NewBulkJobRepository(tenantDBManager)
+	emailMessageRepo := repositories.NewEmailMessageRepository(tenantDBManager)
+	savedViewRepo := repositories.NewSavedViewRepository(tenantDBManager)
+	automationRepo := repositories.NewAutomationRepository(tenantDBManager)
+	routingRepo := repositories.NewRoutingRepository(tenantDBManager)
+	macroRepo := repositories.NewMacroRepository(tenantDBManager)
+	portalLoginRepo := repositories.NewPortalLoginRepository(tenantDBManager)
+	csatRepo := repositories.NewCSATRepository(tenantDBManager)
+	importRepo := repositories.NewImportRepository(tenantDBManager)
+	exportRepo := repositories.NewExportRepository(tenantDBManager)
+	customFieldRepo := repositories.NewCustomFieldRepository(tenantDBManager)
+	boardRepo := repositories.NewBoardRepository(tenantDBManager)
+	tenantRepo := repositories.NewTenantRepository(masterDBManager.GetMasterDB())
+	userRepo := repositories.NewUserRepository(masterDBManager.GetMasterDB())
+	emailSettings := services.EmailSettings{
+		InboundDomain: cfg.Email.InboundDomain,
+		FromAddress:   cfg.Email.FromAddress,
+		FromName:      cfg.Email.FromName,
+	}
+	mailer := services.NewSMTPMailer(services.SMTPConfig{
+		Host:     cfg.Email.SMTPHost,
+		Port:     cfg.Email.SMTPPort,
+		Username: cfg.Email.SMTPUsername,
+		Password: cfg.Email.SMTPPassword,
+	})
+	attachmentStore := services.NewDiskAttachmentStore(cfg.AttachmentStoragePath)
+	slaService := services.NewSLAService(slaRepo, logger)
+	workflowService := services.NewWorkflowService(workflowRepo, logger)
+	emailReplyService := services.NewEmailReplyService(tenantRepo, emailMessageRepo, mailer, emailSettings, logger)
+	automationService := services.NewAutomationService(automationRepo, userRepo, emailReplyService, services.AutomationSettings{
+		WebhookTimeout:       time.Duration(cfg.Automation.WebhookTimeout) * time.Second,
+		AllowPrivateWebhooks: cfg.Automation.AllowPrivateWebhooks,
+	}, logger)
+	routingService := services.NewRoutingService(routingRepo, ticketRepo, projectMemberRepo, logger)
+	csatService := services.NewCSATService(csatRepo, workflowService, emailReplyService, services.SurveySettings{
+		Secret:    cfg.CSAT.SurveySecret,
+		SurveyURL: cfg.CSAT.SurveyURL,
+	}, logger)
+	customFieldService := services.NewCustomFieldService(customFieldRepo, userRepo, logger)
+	ticketService := services.NewTicketService(ticketRepo, ticketLinkRepo, bulkJobRepo, projectMemberRepo, boardRepo, slaService, workflowService, emailReplyService, automationService, routingService, csatService, customFieldService, logger)
+	macroService := services.NewMacroService(macroRepo, ticketService, userRepo, logger)
+	viewService := services.NewViewService(savedViewRepo, ticketRepo, projectMemberRepo, customFieldService, logger)
+	inboundEmailService := services.NewInboundEmailService(ticketService, ticketRepo, emailMessageRepo, tenantRepo, attachmentStore, emailSettings, logger)
+	portalJWTService := auth.NewPortalJWTService(cfg.Portal.JWTSecret, time.Duration(cfg.Portal.SessionTTL)*time.Hour)
+	portalService := services.NewPortalService(tenantRepo, portalLoginRepo, ticketService, ticketRepo, attachmentStore, emailReplyService, portalJWTService, services.PortalSettings{
+		BaseURL:       cfg.Portal.BaseURL,
+		CodeTTL:       time.Duration(cfg.Portal.CodeTTL) * time.Minute,
+		MaxAttempts:   cfg.Portal.MaxAttempts,
+		LoginsPerHour: cfg.Portal.LoginsPerHour,
+	}, logger)
+	importService := services.NewImportService(importRepo, workflowRepo, userRepo, attachmentStore, logger)
+	exportService := services.NewExportService(exportRepo, ticketRepo, projectMemberRepo, userRepo, emailReplyService, services.ExportSettings{
+		StoragePath: cfg.Export.StoragePath,
+		DownloadURL: cfg.Export.DownloadURL,
+		LinkSecret:  cfg.Export.LinkSecret,
+		Retention:   time.Duration(cfg.Export.RetentionDays) * 24 * time.Hour,
+	}, logger)
+	ticketHandler := handlers.NewTicketHandler(ticketService, customFieldService, logger)
+	slaHandler := handlers.NewSLAHandler(slaService, logger)
+	workflowHandler := handlers.NewWorkflowHandler(workflowService, logger)
+	viewHandler := handlers.NewViewHandler(viewService, logger)
+	automationHandler := handlers.NewAutomationHandler(automationService, logger)
+	routingHandler := handlers.NewRoutingHandler(routingService, ticketService, logger)
+	macroHandler := handlers.NewMacroHandler(macroService, logger)
+	csatHandler := handlers.NewCSATHandler(csatService, ticketService, logger)
+	importHandler := handlers.NewImportHandler(importService, int64(cfg.Import.MaxUploadBytes), logger)
+	customFieldHandler := handlers.NewCustomFieldHandler(customFieldService, logger)
+	exportHandler := handlers.NewExportHandler(exportService, customFieldService, logger)
+	portalHandler := handlers.NewPortalHandler(portalService, int64(cfg.Portal.MaxUploadBytes), logger)
+	inboundEmailHandler := handlers.NewInboundEmailHandler(inboundEmailService, cfg.Email.InboundSecret, int64(cfg.Email.MaxMessageBytes), logger)
 
 	// Initialize Gin router
 	router := gin.New()
@@ -108,8 +180,14 @@ func main() {
 		// Ticket status management
 		tickets.PATCH("/:id/assign", ticketHandler.AssignTicket)
 		tickets.PATCH("/:id/status", ticketHandler.UpdateTicketStatus)
+		tickets.GET("/:id/transitions", ticketHandler.GetTicketTransitions)
 		tickets.PATCH("/:id/priority", ticketHandler.UpdateTicketPriority)
 		
+		// Links to other tickets
+		tickets.GET("/:id/links", ticketHandler.GetTicketLinks)
+		tickets.POST("/:id/links", ticketHandler.CreateTicketLink)
+		tickets.DELETE("/:id/links/:link_id", ticketHandler.DeleteTicketLink)
+		
 		// Comments
 		tickets.GET("/:id/comments", ticketHandler.GetTicketComments)
 		tickets.POST("/:id/comments", ticketHandler.CreateTicketComment)
@@ -121,9 +199,210 @@ func main() {
 		tickets.POST("/:id/attachments", ticketHandler.UploadAttachment)
 		tickets.DELETE("/attachments/:attachment_id", ticketHandler.DeleteAttachment)
 		
+		// Bulk operations
+		tickets.POST("/bulk", ticketHandler.BulkUpdateTickets)
+		tickets.GET("/bulk/:job_id", ticketHandler.GetBulkJob)
+		
 		// Search and filtering
 		tickets.GET("/search", ticketHandler.SearchTickets)
 		tickets.GET("/stats", ticketHandler.GetTicketStats)
+		tickets.GET("/export", exportHandler.ExportTickets)
+		
+		// Audit trail
+		tickets.GET("/:id/history", ticketHandler.GetTicketHistory)
+		
+		// SLA state
+		tickets.GET("/:id/sla", ticketHandler.GetTicketSLA)
+	}
+
+	// SLA policies and business calendars
+	sla := v1.Group("/sla")
+	sla.Use(middleware.AuthMiddleware(jwtService))
+	sla.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	sla.Use(middleware.RequireManager())
+	{
+		sla.GET("/policies", slaHandler.ListPolicies)
+		sla.POST("/policies", middleware.RequireOwnerOrAdmin(), slaHandler.CreatePolicy)
+		sla.GET("/policies/:id", slaHandler.GetPolicy)
+		sla.PUT("/policies/:id", middleware.RequireOwnerOrAdmin(), slaHandler.UpdatePolicy)
+		sla.DELETE("/policies/:id", middleware.RequireOwnerOrAdmin(), slaHandler.DeletePolicy)
+		
+		sla.GET("/calendars", slaHandler.ListCalendars)
+		sla.POST("/calendars", middleware.RequireOwnerOrAdmin(), slaHandler.CreateCalendar)
+		sla.GET("/calendars/:id", slaHandler.GetCalendar)
+		sla.PUT("/calendars/:id", middleware.RequireOwnerOrAdmin(), slaHandler.UpdateCalendar)
+		sla.DELETE("/calendars/:id", middleware.RequireOwnerOrAdmin(), slaHandler.DeleteCalendar)
+		sla.POST("/calendars/:id/holidays", middleware.RequireOwnerOrAdmin(), slaHandler.AddHoliday)
+		sla.DELETE("/calendars/:id/holidays/:holiday_id", middleware.RequireOwnerOrAdmin(), slaHandler.RemoveHoliday)
+	}
+
+	// Custom statuses and the workflows that connect them
+	workflows := v1.Group("/workflows")
+	workflows.Use(middleware.AuthMiddleware(jwtService))
+	workflows.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	workflows.Use(middleware.RequireManager())
+	{
+		workflows.GET("/", workflowHandler.ListWorkflows)
+		workflows.POST("/", middleware.RequireOwnerOrAdmin(), workflowHandler.CreateWorkflow)
+		workflows.GET("/:id", workflowHandler.GetWorkflow)
+		workflows.PUT("/:id", middleware.RequireOwnerOrAdmin(), workflowHandler.UpdateWorkflow)
+		workflows.DELETE("/:id", middleware.RequireOwnerOrAdmin(), workflowHandler.DeleteWorkflow)
+		
+		workflows.GET("/statuses", workflowHandler.ListStatuses)
+		workflows.POST("/statuses", middleware.RequireOwnerOrAdmin(), workflowHandler.CreateStatus)
+		workflows.PUT("/statuses/:id", middleware.RequireOwnerOrAdmin(), workflowHandler.UpdateStatus)
+		workflows.DELETE("/statuses/:id", middleware.RequireOwnerOrAdmin(), workflowHandler.DeleteStatus)
+	}
+
+	// Typed custom fields; every member can read the schema to fill in tickets
+	customFields := v1.Group("/custom-fields")
+	customFields.Use(middleware.AuthMiddleware(jwtService))
+	customFields.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	{
+		customFields.GET("/", customFieldHandler.ListFields)
+		customFields.POST("/", middleware.RequireOwnerOrAdmin(), customFieldHandler.CreateField)
+		customFields.GET("/:id", customFieldHandler.GetField)
+		customFields.PUT("/:id", middleware.RequireOwnerOrAdmin(), customFieldHandler.UpdateField)
+		customFields.DELETE("/:id", middleware.RequireOwnerOrAdmin(), customFieldHandler.DeleteField)
+	}
+
+	// Automation rules and the log of their firings
+	automations := v1.Group("/automations")
+	automations.Use(middleware.AuthMiddleware(jwtService))
+	automations.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	automations.Use(middleware.RequireManager())
+	{
+		automations.GET("/", automationHandler.ListRules)
+		automations.POST("/", middleware.RequireOwnerOrAdmin(), automationHandler.CreateRule)
+		automations.GET("/logs", automationHandler.ListLogs)
+		automations.GET("/:id", automationHandler.GetRule)
+		automations.PUT("/:id", middleware.RequireOwnerOrAdmin(), automationHandler.UpdateRule)
+		automations.DELETE("/:id", middleware.RequireOwnerOrAdmin(), automationHandler.DeleteRule)
+		automations.GET("/:id/logs", automationHandler.ListLogs)
+	}
+
+	// Agent groups and how they route new tickets
+	agentGroups := v1.Group("/agent-groups")
+	agentGroups.Use(middleware.AuthMiddleware(jwtService))
+	agentGroups.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	agentGroups.Use(middleware.RequireManager())
+	{
+		agentGroups.GET("/", routingHandler.ListGroups)
+		agentGroups.POST("/", middleware.RequireOwnerOrAdmin(), routingHandler.CreateGroup)
+		agentGroups.GET("/:id", routingHandler.GetGroup)
+		agentGroups.PUT("/:id", middleware.RequireOwnerOrAdmin(), routingHandler.UpdateGroup)
+		agentGroups.DELETE("/:id", middleware.RequireOwnerOrAdmin(), routingHandler.DeleteGroup)
+		agentGroups.POST("/:id/members", middleware.RequireOwnerOrAdmin(), routingHandler.AddMember)
+		agentGroups.PUT("/:id/members/:user_id", middleware.RequireOwnerOrAdmin(), routingHandler.UpdateMember)
+		agentGroups.DELETE("/:id/members/:user_id", middleware.RequireOwnerOrAdmin(), routingHandler.RemoveMember)
+	}
+
+	// Agent availability; going offline hands open group tickets to other agents
+	agents := v1.Group("/agents")
+	agents.Use(middleware.AuthMiddleware(jwtService))
+	agents.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	{
+		agents.GET("/availability", middleware.RequireManager(), routingHandler.ListAvailability)
+		agents.GET("/:user_id/availability", routingHandler.GetAvailability)
+		agents.PUT("/:user_id/availability", routingHandler.UpdateAvailability)
+	}
+
+	// Canned responses, shared across the tenant or personal
+	macros := v1.Group("/macros")
+	macros.Use(middleware.AuthMiddleware(jwtService))
+	macros.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	{
+		macros.GET("/", macroHandler.ListMacros)
+		macros.POST("/", macroHandler.CreateMacro)
+		macros.GET("/:id", macroHandler.GetMacro)
+		macros.PUT("/:id", macroHandler.UpdateMacro)
+		macros.DELETE("/:id", macroHandler.DeleteMacro)
+		macros.POST("/:id/apply", macroHandler.ApplyMacro)
+	}
+
+	// Saved ticket views and built-in personal queues
+	views := v1.Group("/views")
+	views.Use(middleware.AuthMiddleware(jwtService))
+	views.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	{
+		views.GET("/", viewHandler.ListViews)
+		views.POST("/", viewHandler.CreateView)
+		views.GET("/counts", viewHandler.GetViewCounts)
+		views.GET("/:id", viewHandler.GetView)
+		views.PUT("/:id", viewHandler.UpdateView)
+		views.DELETE("/:id", viewHandler.DeleteView)
+		views.GET("/:id/tickets", viewHandler.GetViewTickets)
+	}
+
+	// Satisfaction survey settings and answers
+	csat := v1.Group("/csat")
+	csat.Use(middleware.AuthMiddleware(jwtService))
+	csat.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	csat.Use(middleware.RequireManager())
+	{
+		csat.GET("/settings", csatHandler.GetSettings)
+		csat.PUT("/settings", middleware.RequireOwnerOrAdmin(), csatHandler.UpdateSettings)
+		csat.GET("/surveys", csatHandler.ListSurveys)
+	}
+
+	// Imports of other helpdesks' exports
+	imports := v1.Group("/imports")
+	imports.Use(middleware.AuthMiddleware(jwtService))
+	imports.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	imports.Use(middleware.RequireOwnerOrAdmin())
+	{
+		imports.GET("/", importHandler.ListImports)
+		imports.POST("/", importHandler.CreateImport)
+		imports.GET("/:id", importHandler.GetImport)
+		imports.POST("/:id/resume", importHandler.ResumeImport)
+	}
+
+	// Saved exports delivered on a schedule, and the files they produce
+	exports := v1.Group("/exports")
+	exportsAdmin := exports.Group("")
+	exportsAdmin.Use(middleware.AuthMiddleware(jwtService))
+	exportsAdmin.Use(middleware.TenantMiddleware(masterDBManager, jwtService))
+	exportsAdmin.Use(middleware.RequireOwnerOrAdmin())
+	{
+		exportsAdmin.GET("/schedules", exportHandler.ListSchedules)
+		exportsAdmin.POST("/schedules", exportHandler.CreateSchedule)
+		exportsAdmin.GET("/schedules/:id", exportHandler.GetSchedule)
+		exportsAdmin.PUT("/schedules/:id", exportHandler.UpdateSchedule)
+		exportsAdmin.DELETE("/schedules/:id", exportHandler.DeleteSchedule)
+		exportsAdmin.POST("/schedules/:id/run", exportHandler.RunSchedule)
+		exportsAdmin.GET("/files", exportHandler.ListFiles)
+		exportsAdmin.GET("/files/:id/download", exportHandler.DownloadFile)
+	}
+	// Emailed download links; the signed token is the only credential
+	exports.GET("/download/:token", exportHandler.DownloadByToken)
+
+	// Surveys as customers answer them; the signed token in the link is the only credential
+	surveys := v1.Group("/surveys")
+	{
+		surveys.GET("/:token", csatHandler.GetSurvey)
+		surveys.POST("/:token", csatHandler.AnswerSurvey)
+	}
+
+	// Raw messages piped in by the local MTA; authenticated by shared secret, not JWT
+	inbound := v1.Group("/inbound")
+	{
+		inbound.POST("/email", inboundEmailHandler.ReceiveEmail)
+	}
+
+	// Customer portal; sign-in is public and the rest takes a portal token, never an agent one
+	portal := v1.Group("/portal")
+	{
+		portal.POST("/login", portalHandler.RequestLogin)
+		portal.POST("/verify", portalHandler.Verify)
+	}
+	portalTickets := portal.Group("/tickets")
+	portalTickets.Use(middleware.PortalAuthMiddleware(portalJWTService))
+	{
+		portalTickets.GET("/", portalHandler.ListTickets)
+		portalTickets.GET("/:id", portalHandler.GetTicket)
+		portalTickets.POST("/:id/comments", portalHandler.Reply)
+		portalTickets.POST("/:id/attachments", portalHandler.UploadAttachment)
+		portalTickets.GET("/:id/attachments/:attachment_id", portalHandler.DownloadAttachment)
 	}
 
 	// Create HTTP server
@@ -140,12 +419,20 @@ func main() {
 		}
 	}()
 
+	// Run scheduled automation rules in the background
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	automationScheduler := services.NewAutomationScheduler(tenantRepo, ticketService, slaService, time.Duration(cfg.Automation.ScheduleInterval)*time.Second, logger)
+	go automationScheduler.Start(schedulerCtx)
+	exportScheduler := services.NewExportScheduler(tenantRepo, exportService, time.Duration(cfg.Export.ScheduleInterval)*time.Second, logger)
+	go exportScheduler.Start(schedulerCtx)
+
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	
 	logger.Info("Shutting down Ticket Service...")
+	stopScheduler()
 
 	// Graceful shutdown with 30 second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
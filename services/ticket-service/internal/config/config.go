@@ -10,7 +10,16 @@ type Config struct {
 	MasterDatabase DatabaseConfig
 	Redis          RedisConfig
 	Logger         LoggerConfig
+	Email          EmailConfig
+	Automation     AutomationConfig
+	Portal         PortalConfig
+	CSAT           CSATConfig
+	Import         ImportConfig
+	Export         ExportConfig
 	EncryptionKey  string
+
+	// Where uploaded and emailed attachments are written
+	AttachmentStoragePath string
 }
 
 type ServerConfig struct {
@@ -41,6 +50,61 @@ type LoggerConfig struct {
 	Format string // json or console
 }
 
+// EmailConfig covers the email channel. Each tenant receives mail at any address on
+// <subdomain>.<InboundDomain>; replies go out through SMTPHost and are disabled without it.
+type EmailConfig struct {
+	InboundDomain   string
+	InboundSecret   string // Shared with the MTA that posts raw messages to the inbound endpoint
+	MaxMessageBytes int
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	FromAddress  string // Defaults to the tenant's inbound address
+	FromName     string // Defaults to the tenant name
+}
+
+// AutomationConfig limits the outbound calls automation rules make and sets how often
+// scheduled rules run
+type AutomationConfig struct {
+	WebhookTimeout       int  // Seconds
+	AllowPrivateWebhooks bool // Let webhooks reach loopback and private networks, for local development
+	ScheduleInterval     int  // Seconds between scheduled rule runs and SLA breach refreshes; 0 disables them
+}
+
+// PortalConfig covers the customer portal. Portal tokens are signed with their own secret
+// so they can never be mistaken for agent tokens.
+type PortalConfig struct {
+	JWTSecret      string
+	BaseURL        string // Portal web app that magic links open; empty sends codes only
+	CodeTTL        int    // Minutes a sign-in code or link stays valid
+	SessionTTL     int    // Hours a portal session lasts
+	MaxAttempts    int    // Wrong codes allowed per sign-in
+	LoginsPerHour  int    // Sign-in emails per address per hour
+	MaxUploadBytes int
+}
+
+// CSATConfig covers satisfaction surveys; without a SurveyURL none are sent
+type CSATConfig struct {
+	SurveySecret string // Signs survey links
+	SurveyURL    string // Survey page that links open with ?token=
+}
+
+// ImportConfig covers ticket imports from other helpdesks' exports
+type ImportConfig struct {
+	MaxUploadBytes int
+}
+
+// ExportConfig covers ticket exports and the saved exports run on a schedule
+type ExportConfig struct {
+	StoragePath      string // Where finished export files are kept
+	DownloadURL      string // Base of the download links emailed to recipients; empty sends none
+	LinkSecret       string // Signs download links
+	RetentionDays    int    // Days a finished file is kept
+	ScheduleInterval int    // Seconds between checks for due exports; 0 disables them
+}
+
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
@@ -67,7 +131,47 @@ func Load() *Config {
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "json"),
 		},
-		EncryptionKey: getEnv("ENCRYPTION_KEY", "your-32-byte-encryption-key-here"),
+		Email: EmailConfig{
+			InboundDomain:   getEnv("EMAIL_INBOUND_DOMAIN", ""),
+			InboundSecret:   getEnv("EMAIL_INBOUND_SECRET", ""),
+			MaxMessageBytes: getEnvAsInt("EMAIL_MAX_MESSAGE_BYTES", 25<<20),
+			SMTPHost:        getEnv("SMTP_HOST", ""),
+			SMTPPort:        getEnv("SMTP_PORT", "587"),
+			SMTPUsername:    getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:    getEnv("SMTP_PASSWORD", ""),
+			FromAddress:     getEnv("EMAIL_FROM_ADDRESS", ""),
+			FromName:        getEnv("EMAIL_FROM_NAME", ""),
+		},
+		Automation: AutomationConfig{
+			WebhookTimeout:       getEnvAsInt("AUTOMATION_WEBHOOK_TIMEOUT", 10),
+			AllowPrivateWebhooks: getEnvAsBool("AUTOMATION_ALLOW_PRIVATE_WEBHOOKS", false),
+			ScheduleInterval:     getEnvAsInt("AUTOMATION_SCHEDULE_INTERVAL", 300),
+		},
+		Portal: PortalConfig{
+			JWTSecret:      getEnv("PORTAL_JWT_SECRET", "your-portal-jwt-secret-change-in-production"),
+			BaseURL:        getEnv("PORTAL_BASE_URL", ""),
+			CodeTTL:        getEnvAsInt("PORTAL_CODE_TTL", 15),
+			SessionTTL:     getEnvAsInt("PORTAL_SESSION_TTL", 24),
+			MaxAttempts:    getEnvAsInt("PORTAL_MAX_ATTEMPTS", 5),
+			LoginsPerHour:  getEnvAsInt("PORTAL_LOGINS_PER_HOUR", 5),
+			MaxUploadBytes: getEnvAsInt("PORTAL_MAX_UPLOAD_BYTES", 10<<20),
+		},
+		CSAT: CSATConfig{
+			SurveySecret: getEnv("CSAT_SURVEY_SECRET", "your-csat-survey-secret-change-in-production"),
+			SurveyURL:    getEnv("CSAT_SURVEY_URL", ""),
+		},
+		Import: ImportConfig{
+			MaxUploadBytes: getEnvAsInt("IMPORT_MAX_UPLOAD_BYTES", 200<<20),
+		},
+		Export: ExportConfig{
+			StoragePath:      getEnv("EXPORT_STORAGE_PATH", "./data/exports"),
+			DownloadURL:      getEnv("EXPORT_DOWNLOAD_URL", ""),
+			LinkSecret:       getEnv("EXPORT_LINK_SECRET", "your-export-link-secret-change-in-production"),
+			RetentionDays:    getEnvAsInt("EXPORT_RETENTION_DAYS", 30),
+			ScheduleInterval: getEnvAsInt("EXPORT_SCHEDULE_INTERVAL", 300),
+		},
+		EncryptionKey:         getEnv("ENCRYPTION_KEY", "your-32-byte-encryption-key-here"),
+		AttachmentStoragePath: getEnv("ATTACHMENT_STORAGE_PATH", "./data/attachments"),
 	}
 }
 
@@ -85,4 +189,12 @@ func getEnvAsInt(key string, defaultValue int) int {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/repositories"
+	"ticket-service/internal/services"
+)
+
+type AutomationHandler struct {
+	service services.AutomationService
+	logger  *zap.Logger
+}
+
+func NewAutomationHandler(service services.AutomationService, logger *zap.Logger) *AutomationHandler {
+	return &AutomationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// getUserAndTenantContext extracts the authenticated user and tenant
+func (h *AutomationHandler) getUserAndTenantContext(c *gin.Context) (string, string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", false
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", false
+	}
+
+	return userID, tenantContext.TenantID, true
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *AutomationHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Automation rule not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// ListRules handles GET /automations
+func (h *AutomationHandler) ListRules(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	rules, err := h.service.ListRules(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list automation rules")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"rules": rules})
+}
+
+// CreateRule handles POST /automations
+func (h *AutomationHandler) CreateRule(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.AutomationRuleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	rule, err := h.service.CreateRule(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create automation rule")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"rule": rule})
+}
+
+// GetRule handles GET /automations/:id
+func (h *AutomationHandler) GetRule(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	rule, err := h.service.GetRule(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get automation rule")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"rule": rule})
+}
+
+// UpdateRule handles PUT /automations/:id
+func (h *AutomationHandler) UpdateRule(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.AutomationRuleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	rule, err := h.service.UpdateRule(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update automation rule")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"rule": rule})
+}
+
+// DeleteRule handles DELETE /automations/:id
+func (h *AutomationHandler) DeleteRule(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteRule(userID, tenantID, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete automation rule")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Automation rule deleted successfully"})
+}
+
+// ListLogs handles GET /automations/logs and GET /automations/:id/logs
+func (h *AutomationHandler) ListLogs(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset := (page - 1) * limit
+
+	filters := repositories.AutomationLogFilters{
+		RuleID:   c.Param("id"),
+		TicketID: c.Query("ticket_id"),
+		Status:   c.Query("status"),
+	}
+	if filters.RuleID == "" {
+		filters.RuleID = c.Query("rule_id")
+	}
+
+	logs, total, err := h.service.ListLogs(userID, tenantID, filters, limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to list automation logs")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"logs": logs,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/repositories"
+	"ticket-service/internal/services"
+)
+
+// CSATHandler serves survey settings and answers to managers, and the survey itself to
+// customers, who reach it without a login through the signed link they were emailed
+type CSATHandler struct {
+	service services.CSATService
+	tickets services.TicketService
+	logger  *zap.Logger
+}
+
+func NewCSATHandler(service services.CSATService, tickets services.TicketService, logger *zap.Logger) *CSATHandler {
+	return &CSATHandler{
+		service: service,
+		tickets: tickets,
+		logger:  logger,
+	}
+}
+
+// getUserAndTenantContext extracts the authenticated user and tenant
+func (h *CSATHandler) getUserAndTenantContext(c *gin.Context) (string, string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", false
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", false
+	}
+
+	return userID, tenantContext.TenantID, true
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *CSATHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Survey not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// GetSettings handles GET /csat/settings
+func (h *CSATHandler) GetSettings(c *gin.Context) {
+	_, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	settings, err := h.service.GetSettings(tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get CSAT settings")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"settings": settings})
+}
+
+// UpdateSettings handles PUT /csat/settings
+func (h *CSATHandler) UpdateSettings(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.CSATSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update CSAT settings")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"settings": settings})
+}
+
+// ListSurveys handles GET /csat/surveys, optionally filtered by ticket_id, agent_id,
+// project_id, responded=true and max_rating
+func (h *CSATHandler) ListSurveys(c *gin.Context) {
+	_, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	filters := repositories.CSATSurveyFilters{
+		TicketID:      c.Query("ticket_id"),
+		AgentID:       c.Query("agent_id"),
+		ProjectID:     c.Query("project_id"),
+		RespondedOnly: c.Query("responded") == "true",
+	}
+	if maxRating := c.Query("max_rating"); maxRating != "" {
+		rating, err := strconv.Atoi(maxRating)
+		if err != nil || rating < tenant_models.CSATMinRating || rating > tenant_models.CSATMaxRating {
+			utils.BadRequestResponse(c, "max_rating must be between 1 and 5")
+			return
+		}
+		filters.MaxRating = rating
+		filters.RespondedOnly = true
+	}
+
+	surveys, total, err := h.service.ListSurveys(tenantID, filters, limit, (page-1)*limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to list CSAT surveys")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"surveys": surveys,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetSurvey handles GET /surveys/:token for the customer's survey page
+func (h *CSATHandler) GetSurvey(c *gin.Context) {
+	survey, err := h.tickets.GetSurvey(c.Param("token"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get survey")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"survey": survey})
+}
+
+// AnswerSurvey handles POST /surveys/:token
+func (h *CSATHandler) AnswerSurvey(c *gin.Context) {
+	var req tenant_models.CSATAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	survey, err := h.tickets.AnswerSurvey(c.Param("token"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to answer survey")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"survey": survey})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+type CustomFieldHandler struct {
+	service services.CustomFieldService
+	logger  *zap.Logger
+}
+
+func NewCustomFieldHandler(service services.CustomFieldService, logger *zap.Logger) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// getUserAndTenantContext extracts the authenticated user and tenant
+func (h *CustomFieldHandler) getUserAndTenantContext(c *gin.Context) (string, string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", false
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", false
+	}
+
+	return userID, tenantContext.TenantID, true
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *CustomFieldHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Custom field not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// ListFields handles GET /custom-fields. "project_id" narrows the list to the fields
+// offered in that project and "include_inactive" adds switched-off fields.
+func (h *CustomFieldHandler) ListFields(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	fields, err := h.service.ListFields(userID, tenantID, c.Query("project_id"), c.Query("include_inactive") == "true")
+	if err != nil {
+		h.respondError(c, err, "Failed to list custom fields")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"custom_fields": fields})
+}
+
+// CreateField handles POST /custom-fields
+func (h *CustomFieldHandler) CreateField(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.CustomFieldCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	field, err := h.service.CreateField(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create custom field")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"custom_field": field})
+}
+
+// GetField handles GET /custom-fields/:id
+func (h *CustomFieldHandler) GetField(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	field, err := h.service.GetField(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get custom field")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"custom_field": field})
+}
+
+// UpdateField handles PUT /custom-fields/:id
+func (h *CustomFieldHandler) UpdateField(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.CustomFieldUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	field, err := h.service.UpdateField(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update custom field")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"custom_field": field})
+}
+
+// DeleteField handles DELETE /custom-fields/:id
+func (h *CustomFieldHandler) DeleteField(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteField(userID, tenantID, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete custom field")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Custom field deleted successfully"})
+}
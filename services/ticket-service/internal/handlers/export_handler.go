@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+// exportContentTypes are the response types of each export format
+var exportContentTypes = map[tenant_models.ExportFormat]string{
+	tenant_models.ExportFormatCSV:    "text/csv; charset=utf-8",
+	tenant_models.ExportFormatNDJSON: "application/x-ndjson",
+	tenant_models.ExportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ExportHandler streams ticket exports and manages saved, scheduled exports
+type ExportHandler struct {
+	service services.ExportService
+	fields  services.CustomFieldService
+	logger  *zap.Logger
+}
+
+func NewExportHandler(service services.ExportService, fields services.CustomFieldService, logger *zap.Logger) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+		fields:  fields,
+		logger:  logger,
+	}
+}
+
+// getActor returns the acting user with their tenant role
+func (h *ExportHandler) getActor(c *gin.Context) (services.Actor, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return services.Actor{}, fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return services.Actor{}, fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return services.Actor{
+		UserID:   userID,
+		TenantID: tenantContext.TenantID,
+		Role:     tenantContext.UserRole,
+	}, nil
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *ExportHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Export not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// ExportTickets handles GET /tickets/export. It takes the ticket list filters plus
+// "format" (csv, ndjson or xlsx; csv by default) and "include_comments", and streams the
+// file as it is read.
+func (h *ExportHandler) ExportTickets(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	filters, err := parseTicketFilters(c)
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+	filters.CustomFields, err = h.fields.ParseFilters(actor, customFieldParams(c))
+	if err != nil {
+		h.respondError(c, err, "Failed to export tickets")
+		return
+	}
+
+	format := tenant_models.ExportFormat(strings.ToLower(c.DefaultQuery("format", "csv")))
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		utils.BadRequestResponse(c, "format must be csv, ndjson or xlsx")
+		return
+	}
+	options := services.ExportOptions{
+		Format:          format,
+		IncludeComments: c.Query("include_comments") == "true",
+	}
+
+	filename := fmt.Sprintf("tickets-%s.%s", time.Now().UTC().Format("2006-01-02"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	count, err := h.service.ExportTickets(actor, filters, options, c.Writer)
+	if err != nil {
+		// Once rows have gone out the status is sent; all that is left is to cut the
+		// download short so the client sees it fail
+		if c.Writer.Written() {
+			h.logger.Error("Ticket export failed part way",
+				zap.String("tenant_id", actor.TenantID),
+				zap.Int("exported", count),
+				zap.Error(err))
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		h.respondError(c, err, "Failed to export tickets")
+	}
+}
+
+// ListSchedules handles GET /exports/schedules
+func (h *ExportHandler) ListSchedules(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	schedules, err := h.service.ListSchedules(actor)
+	if err != nil {
+		h.respondError(c, err, "Failed to list scheduled exports")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"schedules": schedules})
+}
+
+// CreateSchedule handles POST /exports/schedules
+func (h *ExportHandler) CreateSchedule(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.ScheduledExportCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	schedule, err := h.service.CreateSchedule(actor, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create scheduled export")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"schedule": schedule})
+}
+
+// GetSchedule handles GET /exports/schedules/:id
+func (h *ExportHandler) GetSchedule(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(actor, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get scheduled export")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"schedule": schedule})
+}
+
+// UpdateSchedule handles PUT /exports/schedules/:id
+func (h *ExportHandler) UpdateSchedule(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.ScheduledExportUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	schedule, err := h.service.UpdateSchedule(actor, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update scheduled export")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"schedule": schedule})
+}
+
+// DeleteSchedule handles DELETE /exports/schedules/:id
+func (h *ExportHandler) DeleteSchedule(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteSchedule(actor, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete scheduled export")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Scheduled export deleted successfully"})
+}
+
+// RunSchedule handles POST /exports/schedules/:id/run
+func (h *ExportHandler) RunSchedule(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	file, err := h.service.RunSchedule(actor, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to run scheduled export")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"file": file})
+}
+
+// ListFiles handles GET /exports/files, optionally for one schedule via "schedule_id"
+func (h *ExportHandler) ListFiles(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	files, total, err := h.service.ListFiles(actor, c.Query("schedule_id"), limit, (page-1)*limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to list export files")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"files": files,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// DownloadFile handles GET /exports/files/:id/download
+func (h *ExportHandler) DownloadFile(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	file, err := h.service.OpenFile(actor, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get export file")
+		return
+	}
+
+	c.FileAttachment(file.FilePath, file.Filename)
+}
+
+// DownloadByToken handles GET /exports/download/:token, the link emailed to a scheduled
+// export's recipients; the signed token is the only credential
+func (h *ExportHandler) DownloadByToken(c *gin.Context) {
+	file, err := h.service.OpenFileByToken(c.Param("token"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get export file")
+		return
+	}
+
+	c.FileAttachment(file.FilePath, file.Filename)
+}
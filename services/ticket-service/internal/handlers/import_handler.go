@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+// ImportHandler accepts helpdesk exports to import and reports on the import jobs
+type ImportHandler struct {
+	service        services.ImportService
+	maxUploadBytes int64
+	logger         *zap.Logger
+}
+
+func NewImportHandler(service services.ImportService, maxUploadBytes int64, logger *zap.Logger) *ImportHandler {
+	return &ImportHandler{
+		service:        service,
+		maxUploadBytes: maxUploadBytes,
+		logger:         logger,
+	}
+}
+
+// getUserAndTenantContext extracts the authenticated user and tenant
+func (h *ImportHandler) getUserAndTenantContext(c *gin.Context) (string, string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", false
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", false
+	}
+
+	return userID, tenantContext.TenantID, true
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *ImportHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Import not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// CreateImport handles POST /imports. The multipart form carries the export in "file",
+// and optionally "format" (csv or json, otherwise taken from the file extension),
+// "mapping" as JSON, "dry_run" and "async".
+func (h *ImportHandler) CreateImport(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	// Leave room for the multipart framing around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadBytes+64<<10)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File exceeds the size limit")
+			return
+		}
+		utils.BadRequestResponse(c, "A file is required in the \"file\" field")
+		return
+	}
+	if header.Size > h.maxUploadBytes {
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File exceeds the size limit")
+		return
+	}
+
+	req := tenant_models.ImportRequest{
+		Format: tenant_models.ImportFormat(strings.ToLower(c.PostForm("format"))),
+		DryRun: c.PostForm("dry_run") == "true",
+		Async:  c.PostForm("async") == "true",
+	}
+	if req.Format == "" {
+		req.Format = tenant_models.ImportFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), "."))
+	}
+	if mapping := c.PostForm("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &req.Mapping); err != nil {
+			utils.BadRequestResponse(c, "Invalid mapping: "+err.Error())
+			return
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		utils.BadRequestResponse(c, "Failed to read file")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.BadRequestResponse(c, "Failed to read file")
+		return
+	}
+
+	job, err := h.service.CreateImport(userID, tenantID, &req, header.Filename, data)
+	if err != nil {
+		h.respondError(c, err, "Failed to start import")
+		return
+	}
+
+	if job.Status == tenant_models.ImportJobQueued {
+		utils.AcceptedResponse(c, gin.H{"import": job})
+		return
+	}
+	utils.CreatedResponse(c, gin.H{"import": job})
+}
+
+// ListImports handles GET /imports; row errors are only included per import
+func (h *ImportHandler) ListImports(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	imports, total, err := h.service.ListImports(userID, tenantID, limit, (page-1)*limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to list imports")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"imports": imports,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetImport handles GET /imports/:id with the job's progress and row error report
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	job, err := h.service.GetImport(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get import")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"import": job})
+}
+
+// ResumeImport handles POST /imports/:id/resume
+func (h *ImportHandler) ResumeImport(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	job, err := h.service.ResumeImport(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to resume import")
+		return
+	}
+
+	utils.AcceptedResponse(c, gin.H{"import": job})
+}
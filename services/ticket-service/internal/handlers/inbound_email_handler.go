@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+// InboundEmailHandler accepts raw messages from the local MTA. It sits outside the JWT
+// routes: the MTA authenticates with a shared secret and the tenant comes from the recipient.
+type InboundEmailHandler struct {
+	service  services.InboundEmailService
+	secret   string
+	maxBytes int64
+	logger   *zap.Logger
+}
+
+func NewInboundEmailHandler(service services.InboundEmailService, secret string, maxBytes int64, logger *zap.Logger) *InboundEmailHandler {
+	return &InboundEmailHandler{
+		service:  service,
+		secret:   secret,
+		maxBytes: maxBytes,
+		logger:   logger,
+	}
+}
+
+// ReceiveEmail handles POST /inbound/email with the raw RFC 5322 message as the body and
+// the envelope recipient, when known, in ?recipient=
+func (h *InboundEmailHandler) ReceiveEmail(c *gin.Context) {
+	if h.secret == "" {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Inbound email is not configured")
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Inbound-Secret")), []byte(h.secret)) != 1 {
+		utils.UnauthorizedResponse(c, "Invalid inbound secret")
+		return
+	}
+
+	raw, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Message exceeds the size limit")
+			return
+		}
+		utils.BadRequestResponse(c, "Failed to read message")
+		return
+	}
+	if len(raw) == 0 {
+		utils.BadRequestResponse(c, "Message is empty")
+		return
+	}
+
+	result, err := h.service.ProcessMessage(c.Query("recipient"), raw)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+		h.logger.Error("Failed to process inbound email", zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to process inbound email")
+		return
+	}
+
+	if result.Action == services.InboundEmailCreated {
+		utils.CreatedResponse(c, gin.H{"result": result})
+		return
+	}
+	utils.SuccessResponse(c, gin.H{"result": result})
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+type MacroHandler struct {
+	service services.MacroService
+	logger  *zap.Logger
+}
+
+func NewMacroHandler(service services.MacroService, logger *zap.Logger) *MacroHandler {
+	return &MacroHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// getActor builds the acting user from the authenticated request
+func (h *MacroHandler) getActor(c *gin.Context) (services.Actor, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return services.Actor{}, fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return services.Actor{}, fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return services.Actor{
+		UserID:   userID,
+		TenantID: tenantContext.TenantID,
+		Role:     tenantContext.UserRole,
+	}, nil
+}
+
+// respondError maps service errors onto HTTP responses; denials carry their reason
+func (h *MacroHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Macro or ticket not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// ListMacros handles GET /macros
+func (h *MacroHandler) ListMacros(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	macros, err := h.service.ListMacros(actor)
+	if err != nil {
+		h.respondError(c, err, "Failed to list macros")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"macros": macros})
+}
+
+// CreateMacro handles POST /macros
+func (h *MacroHandler) CreateMacro(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.MacroCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	macro, err := h.service.CreateMacro(actor, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create macro")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"macro": macro})
+}
+
+// GetMacro handles GET /macros/:id
+func (h *MacroHandler) GetMacro(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	macro, err := h.service.GetMacro(actor, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get macro")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"macro": macro})
+}
+
+// UpdateMacro handles PUT /macros/:id
+func (h *MacroHandler) UpdateMacro(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.MacroUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	macro, err := h.service.UpdateMacro(actor, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update macro")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"macro": macro})
+}
+
+// DeleteMacro handles DELETE /macros/:id
+func (h *MacroHandler) DeleteMacro(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteMacro(actor, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete macro")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Macro deleted successfully"})
+}
+
+// ApplyMacro handles POST /macros/:id/apply
+func (h *MacroHandler) ApplyMacro(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.MacroApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	result, err := h.service.ApplyMacro(actor, c.Param("id"), req.TicketID)
+	if err != nil {
+		h.respondError(c, err, "Failed to apply macro")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"ticket": result.Ticket, "comment": result.Comment})
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+// PortalHandler serves the customer portal. Its routes sit outside the agent JWT routes:
+// sign-in is public and everything else takes a portal token.
+type PortalHandler struct {
+	service        services.PortalService
+	maxUploadBytes int64
+	logger         *zap.Logger
+}
+
+func NewPortalHandler(service services.PortalService, maxUploadBytes int64, logger *zap.Logger) *PortalHandler {
+	return &PortalHandler{
+		service:        service,
+		maxUploadBytes: maxUploadBytes,
+		logger:         logger,
+	}
+}
+
+// getCustomer builds the signed-in customer from the portal token
+func (h *PortalHandler) getCustomer(c *gin.Context) (services.PortalCustomer, bool) {
+	tenantID, ok := middleware.GetPortalTenantID(c)
+	if !ok {
+		return services.PortalCustomer{}, false
+	}
+	email, ok := middleware.GetPortalEmail(c)
+	if !ok {
+		return services.PortalCustomer{}, false
+	}
+	return services.PortalCustomer{TenantID: tenantID, Email: email}, true
+}
+
+// respondError maps service errors onto HTTP responses. Failed sign-ins answer 401.
+func (h *PortalHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.UnauthorizedResponse(c, err.Error())
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// RequestLogin handles POST /portal/login. The answer is the same whether or not the
+// address has tickets.
+func (h *PortalHandler) RequestLogin(c *gin.Context) {
+	var req tenant_models.PortalLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	if err := h.service.RequestLogin(&req); err != nil {
+		h.respondError(c, err, "Failed to start sign-in")
+		return
+	}
+
+	utils.AcceptedResponse(c, nil, "If this address has support requests, a sign-in code is on its way")
+}
+
+// Verify handles POST /portal/verify
+func (h *PortalHandler) Verify(c *gin.Context) {
+	var req tenant_models.PortalVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	session, err := h.service.Verify(&req)
+	if err != nil {
+		h.respondError(c, err, "Failed to verify sign-in")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"session": session})
+}
+
+// ListTickets handles GET /portal/tickets
+func (h *PortalHandler) ListTickets(c *gin.Context) {
+	customer, ok := h.getCustomer(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	tickets, total, err := h.service.ListTickets(customer, limit, (page-1)*limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to list tickets")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"tickets": tickets,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetTicket handles GET /portal/tickets/:id with its public comments and attachments
+func (h *PortalHandler) GetTicket(c *gin.Context) {
+	customer, ok := h.getCustomer(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	ticket, err := h.service.GetTicket(customer, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get ticket")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"ticket": ticket})
+}
+
+// Reply handles POST /portal/tickets/:id/comments
+func (h *PortalHandler) Reply(c *gin.Context) {
+	customer, ok := h.getCustomer(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.PortalReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	comment, err := h.service.Reply(customer, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to add reply")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"comment": comment})
+}
+
+// UploadAttachment handles POST /portal/tickets/:id/attachments with the file in the
+// multipart field "file"
+func (h *PortalHandler) UploadAttachment(c *gin.Context) {
+	customer, ok := h.getCustomer(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	// Leave room for the multipart framing around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadBytes+64<<10)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File exceeds the size limit")
+			return
+		}
+		utils.BadRequestResponse(c, "A file is required in the \"file\" field")
+		return
+	}
+	if header.Size > h.maxUploadBytes {
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File exceeds the size limit")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		utils.BadRequestResponse(c, "Failed to read file")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.BadRequestResponse(c, "Failed to read file")
+		return
+	}
+
+	attachment, err := h.service.UploadAttachment(customer, c.Param("id"), header.Filename, header.Header.Get("Content-Type"), data)
+	if err != nil {
+		h.respondError(c, err, "Failed to upload attachment")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"attachment": attachment})
+}
+
+// DownloadAttachment handles GET /portal/tickets/:id/attachments/:attachment_id
+func (h *PortalHandler) DownloadAttachment(c *gin.Context) {
+	customer, ok := h.getCustomer(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	attachment, err := h.service.OpenAttachment(customer, c.Param("id"), c.Param("attachment_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get attachment")
+		return
+	}
+
+	// Imported attachments stay with the helpdesk they came from
+	if attachment.IsReference() {
+		c.Redirect(http.StatusFound, attachment.FilePath)
+		return
+	}
+	c.FileAttachment(attachment.FilePath, attachment.OriginalFilename)
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+// RoutingHandler serves agent groups and agent availability
+type RoutingHandler struct {
+	service services.RoutingService
+	tickets services.TicketService
+	logger  *zap.Logger
+}
+
+func NewRoutingHandler(service services.RoutingService, tickets services.TicketService, logger *zap.Logger) *RoutingHandler {
+	return &RoutingHandler{
+		service: service,
+		tickets: tickets,
+		logger:  logger,
+	}
+}
+
+// getUserAndTenantContext extracts the authenticated user and tenant
+func (h *RoutingHandler) getUserAndTenantContext(c *gin.Context) (string, string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", false
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", false
+	}
+
+	return userID, tenantContext.TenantID, true
+}
+
+// getActor builds the acting user from the authenticated request
+func (h *RoutingHandler) getActor(c *gin.Context) (services.Actor, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return services.Actor{}, fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return services.Actor{}, fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return services.Actor{
+		UserID:   userID,
+		TenantID: tenantContext.TenantID,
+		Role:     tenantContext.UserRole,
+	}, nil
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *RoutingHandler) respondError(c *gin.Context, err error, message, notFound string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, notFound)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// Agent group handlers
+
+// ListGroups handles GET /agent-groups
+func (h *RoutingHandler) ListGroups(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	groups, err := h.service.ListGroups(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list agent groups", "Agent group not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"groups": groups})
+}
+
+// CreateGroup handles POST /agent-groups
+func (h *RoutingHandler) CreateGroup(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.AgentGroupCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	group, err := h.service.CreateGroup(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create agent group", "Agent group not found")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"group": group})
+}
+
+// GetGroup handles GET /agent-groups/:id
+func (h *RoutingHandler) GetGroup(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	group, err := h.service.GetGroup(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get agent group", "Agent group not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"group": group})
+}
+
+// UpdateGroup handles PUT /agent-groups/:id
+func (h *RoutingHandler) UpdateGroup(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.AgentGroupUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	group, err := h.service.UpdateGroup(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update agent group", "Agent group not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"group": group})
+}
+
+// DeleteGroup handles DELETE /agent-groups/:id
+func (h *RoutingHandler) DeleteGroup(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteGroup(userID, tenantID, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete agent group", "Agent group not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Agent group deleted successfully"})
+}
+
+// AddMember handles POST /agent-groups/:id/members
+func (h *RoutingHandler) AddMember(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.AgentGroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	member, err := h.service.AddMember(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to add group member", "Agent group not found")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"member": member})
+}
+
+// UpdateMember handles PUT /agent-groups/:id/members/:user_id
+func (h *RoutingHandler) UpdateMember(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.AgentGroupMemberUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	member, err := h.service.UpdateMember(userID, tenantID, c.Param("id"), c.Param("user_id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update group member", "Group member not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"member": member})
+}
+
+// RemoveMember handles DELETE /agent-groups/:id/members/:user_id
+func (h *RoutingHandler) RemoveMember(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.RemoveMember(userID, tenantID, c.Param("id"), c.Param("user_id")); err != nil {
+		h.respondError(c, err, "Failed to remove group member", "Group member not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Group member removed successfully"})
+}
+
+// Agent availability handlers
+
+// ListAvailability handles GET /agents/availability
+func (h *RoutingHandler) ListAvailability(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	agents, err := h.service.ListAvailability(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list agent availability", "Agent not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"agents": agents})
+}
+
+// GetAvailability handles GET /agents/:user_id/availability; "me" means the caller
+func (h *RoutingHandler) GetAvailability(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	availability, err := h.tickets.GetAgentAvailability(actor, agentParam(c, actor))
+	if err != nil {
+		h.respondError(c, err, "Failed to get agent availability", "Agent not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"availability": availability})
+}
+
+// UpdateAvailability handles PUT /agents/:user_id/availability; "me" means the caller
+func (h *RoutingHandler) UpdateAvailability(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.AgentAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	availability, err := h.tickets.UpdateAgentAvailability(actor, agentParam(c, actor), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update agent availability", "Agent not found")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"availability": availability})
+}
+
+func agentParam(c *gin.Context, actor services.Actor) string {
+	if agentID := c.Param("user_id"); agentID != "me" {
+		return agentID
+	}
+	return actor.UserID
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+type SLAHandler struct {
+	service services.SLAService
+	logger  *zap.Logger
+}
+
+func NewSLAHandler(service services.SLAService, logger *zap.Logger) *SLAHandler {
+	return &SLAHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// getUserAndTenantContext extracts the authenticated user and tenant
+func (h *SLAHandler) getUserAndTenantContext(c *gin.Context) (string, string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", false
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", false
+	}
+
+	return userID, tenantContext.TenantID, true
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *SLAHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Resource not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// ListPolicies handles GET /sla/policies
+func (h *SLAHandler) ListPolicies(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	policies, err := h.service.ListPolicies(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list SLA policies")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"policies": policies})
+}
+
+// CreatePolicy handles POST /sla/policies
+func (h *SLAHandler) CreatePolicy(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.SLAPolicyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	policy, err := h.service.CreatePolicy(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create SLA policy")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"policy": policy})
+}
+
+// GetPolicy handles GET /sla/policies/:id
+func (h *SLAHandler) GetPolicy(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	policy, err := h.service.GetPolicy(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get SLA policy")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"policy": policy})
+}
+
+// UpdatePolicy handles PUT /sla/policies/:id
+func (h *SLAHandler) UpdatePolicy(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.SLAPolicyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update SLA policy")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"policy": policy})
+}
+
+// DeletePolicy handles DELETE /sla/policies/:id
+func (h *SLAHandler) DeletePolicy(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeletePolicy(userID, tenantID, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete SLA policy")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "SLA policy deleted successfully"})
+}
+
+// ListCalendars handles GET /sla/calendars
+func (h *SLAHandler) ListCalendars(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	calendars, err := h.service.ListCalendars(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list business calendars")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"calendars": calendars})
+}
+
+// CreateCalendar handles POST /sla/calendars
+func (h *SLAHandler) CreateCalendar(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.BusinessCalendarCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	calendar, err := h.service.CreateCalendar(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create business calendar")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"calendar": calendar})
+}
+
+// GetCalendar handles GET /sla/calendars/:id
+func (h *SLAHandler) GetCalendar(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	calendar, err := h.service.GetCalendar(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get business calendar")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"calendar": calendar})
+}
+
+// UpdateCalendar handles PUT /sla/calendars/:id
+func (h *SLAHandler) UpdateCalendar(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.BusinessCalendarUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	calendar, err := h.service.UpdateCalendar(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update business calendar")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"calendar": calendar})
+}
+
+// DeleteCalendar handles DELETE /sla/calendars/:id
+func (h *SLAHandler) DeleteCalendar(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteCalendar(userID, tenantID, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete business calendar")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Business calendar deleted successfully"})
+}
+
+// AddHoliday handles POST /sla/calendars/:id/holidays
+func (h *SLAHandler) AddHoliday(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.BusinessHolidayCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	holiday, err := h.service.AddHoliday(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to add holiday")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"holiday": holiday})
+}
+
+// RemoveHoliday handles DELETE /sla/calendars/:id/holidays/:holiday_id
+func (h *SLAHandler) RemoveHoliday(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.RemoveHoliday(userID, tenantID, c.Param("id"), c.Param("holiday_id")); err != nil {
+		h.respondError(c, err, "Failed to remove holiday")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Holiday removed successfully"})
+}
@@ -1,16 +1,19 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 
 	"github.com/zen/shared/pkg/middleware"
-	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
 	"github.com/zen/shared/pkg/utils"
 	"ticket-service/internal/repositories"
 	"ticket-service/internal/services"
@@ -18,34 +21,55 @@ import (
 
 type TicketHandler struct {
 	service services.TicketService
+	fields  services.CustomFieldService
 	logger  *zap.Logger
 }
 
-func NewTicketHandler(service services.TicketService, logger *zap.Logger) *TicketHandler {
+func NewTicketHandler(service services.TicketService, fields services.CustomFieldService, logger *zap.Logger) *TicketHandler {
 	return &TicketHandler{
 		service: service,
+		fields:  fields,
 		logger:  logger,
 	}
 }
 
-// Helper function to get user and tenant context
-func (h *TicketHandler) getUserAndTenantContext(c *gin.Context) (string, string, error) {
+// Helper function to get the acting user with their tenant role
+func (h *TicketHandler) getActor(c *gin.Context) (services.Actor, error) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
-		return "", "", fmt.Errorf("user ID not found in context")
+		return services.Actor{}, fmt.Errorf("user ID not found in context")
 	}
 
 	tenantContext, err := middleware.GetTenantContext(c)
 	if err != nil {
-		return "", "", fmt.Errorf("tenant context not found: %w", err)
+		return services.Actor{}, fmt.Errorf("tenant context not found: %w", err)
 	}
 
-	return userID, tenantContext.TenantID, nil
+	return services.Actor{
+		UserID:   userID,
+		TenantID: tenantContext.TenantID,
+		Role:     tenantContext.UserRole,
+	}, nil
+}
+
+// respondError maps service errors onto HTTP responses; denials carry their reason
+func (h *TicketHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Ticket not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
 }
 
 // ListTickets handles GET /tickets
 func (h *TicketHandler) ListTickets(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -56,7 +80,40 @@ func (h *TicketHandler) ListTickets(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset := (page - 1) * limit
 
-	// Parse filters
+	filters, err := parseTicketFilters(c)
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+	filters.CustomFields, err = h.fields.ParseFilters(actor, customFieldParams(c))
+	if err != nil {
+		h.respondError(c, err, "Failed to list tickets")
+		return
+	}
+	filters.Sort, err = h.fields.ParseSort(actor, c.Query("sort"), c.Query("order") == "desc")
+	if err != nil {
+		h.respondError(c, err, "Failed to list tickets")
+		return
+	}
+
+	tickets, total, err := h.service.ListTickets(actor, limit, offset, filters)
+	if err != nil {
+		h.respondError(c, err, "Failed to list tickets")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"tickets": tickets,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// parseTicketFilters reads the ticket list filters from the query string
+func parseTicketFilters(c *gin.Context) (repositories.TicketFilters, error) {
 	filters := repositories.TicketFilters{
 		Status:     c.Query("status"),
 		Priority:   c.Query("priority"),
@@ -64,6 +121,7 @@ func (h *TicketHandler) ListTickets(c *gin.Context) {
 		AssigneeID: c.Query("assignee_id"),
 		ReporterID: c.Query("reporter_id"),
 		ProjectID:  c.Query("project_id"),
+		GroupID:    c.Query("group_id"),
 		Category:   c.Query("category"),
 	}
 
@@ -79,41 +137,48 @@ func (h *TicketHandler) ListTickets(c *gin.Context) {
 		}
 	}
 
-	tickets, total, err := h.service.ListTickets(userID, tenantID, limit, offset, filters)
-	if err != nil {
-		h.logger.Error("Failed to list tickets", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to list tickets")
-		return
+	// Parse SLA filters
+	if within := c.Query("breaching_within"); within != "" {
+		minutes, err := strconv.Atoi(within)
+		if err != nil || minutes < 0 {
+			return filters, fmt.Errorf("breaching_within must be a non-negative number of minutes")
+		}
+		window := time.Duration(minutes) * time.Minute
+		filters.BreachingWithin = &window
 	}
+	filters.SLABreached = c.Query("sla_breached") == "true"
 
-	utils.SuccessResponse(c, gin.H{
-		"tickets": tickets,
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-		},
-	})
+	return filters, nil
+}
+
+// customFieldParams collects the custom_fields.<key> filters from the query string, by key
+func customFieldParams(c *gin.Context) map[string]string {
+	params := map[string]string{}
+	for name, values := range c.Request.URL.Query() {
+		if key, ok := strings.CutPrefix(name, "custom_fields."); ok && len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+	return params
 }
 
 // CreateTicket handles POST /tickets
 func (h *TicketHandler) CreateTicket(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
 	}
 
-	var req models.TicketCreateRequest
+	var req tenant_models.TicketCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
 		return
 	}
 
-	ticket, err := h.service.CreateTicket(userID, tenantID, &req)
+	ticket, err := h.service.CreateTicket(actor, &req)
 	if err != nil {
-		h.logger.Error("Failed to create ticket", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create ticket")
+		h.respondError(c, err, "Failed to create ticket")
 		return
 	}
 
@@ -122,7 +187,7 @@ func (h *TicketHandler) CreateTicket(c *gin.Context) {
 
 // GetTicket handles GET /tickets/:id
 func (h *TicketHandler) GetTicket(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -134,14 +199,9 @@ func (h *TicketHandler) GetTicket(c *gin.Context) {
 		return
 	}
 
-	ticket, err := h.service.GetTicket(userID, tenantID, ticketID)
+	ticket, err := h.service.GetTicket(actor, ticketID)
 	if err != nil {
-		if err.Error() == "access denied: user cannot view this ticket" {
-			utils.ForbiddenResponse(c, "Access denied")
-			return
-		}
-		h.logger.Error("Failed to get ticket", zap.Error(err))
-		utils.NotFoundResponse(c, "Ticket not found")
+		h.respondError(c, err, "Failed to get ticket")
 		return
 	}
 
@@ -150,7 +210,7 @@ func (h *TicketHandler) GetTicket(c *gin.Context) {
 
 // UpdateTicket handles PUT /tickets/:id
 func (h *TicketHandler) UpdateTicket(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -162,20 +222,15 @@ func (h *TicketHandler) UpdateTicket(c *gin.Context) {
 		return
 	}
 
-	var req models.TicketUpdateRequest
+	var req tenant_models.TicketUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
 		return
 	}
 
-	ticket, err := h.service.UpdateTicket(userID, tenantID, ticketID, &req)
+	ticket, err := h.service.UpdateTicket(actor, ticketID, &req)
 	if err != nil {
-		if err.Error() == "access denied: user cannot update this ticket" {
-			utils.ForbiddenResponse(c, "Access denied")
-			return
-		}
-		h.logger.Error("Failed to update ticket", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update ticket")
+		h.respondError(c, err, "Failed to update ticket")
 		return
 	}
 
@@ -184,7 +239,7 @@ func (h *TicketHandler) UpdateTicket(c *gin.Context) {
 
 // DeleteTicket handles DELETE /tickets/:id
 func (h *TicketHandler) DeleteTicket(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -196,14 +251,9 @@ func (h *TicketHandler) DeleteTicket(c *gin.Context) {
 		return
 	}
 
-	err = h.service.DeleteTicket(userID, tenantID, ticketID)
+	err = h.service.DeleteTicket(actor, ticketID)
 	if err != nil {
-		if err.Error() == "access denied: user cannot delete this ticket" {
-			utils.ForbiddenResponse(c, "Access denied")
-			return
-		}
-		h.logger.Error("Failed to delete ticket", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete ticket")
+		h.respondError(c, err, "Failed to delete ticket")
 		return
 	}
 
@@ -212,7 +262,7 @@ func (h *TicketHandler) DeleteTicket(c *gin.Context) {
 
 // AssignTicket handles PATCH /tickets/:id/assign
 func (h *TicketHandler) AssignTicket(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -232,10 +282,9 @@ func (h *TicketHandler) AssignTicket(c *gin.Context) {
 		return
 	}
 
-	ticket, err := h.service.AssignTicket(userID, tenantID, ticketID, req.AssigneeID)
+	ticket, err := h.service.AssignTicket(actor, ticketID, req.AssigneeID)
 	if err != nil {
-		h.logger.Error("Failed to assign ticket", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to assign ticket")
+		h.respondError(c, err, "Failed to assign ticket")
 		return
 	}
 
@@ -244,7 +293,7 @@ func (h *TicketHandler) AssignTicket(c *gin.Context) {
 
 // UpdateTicketStatus handles PATCH /tickets/:id/status
 func (h *TicketHandler) UpdateTicketStatus(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -256,27 +305,166 @@ func (h *TicketHandler) UpdateTicketStatus(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Status models.TicketStatus `json:"status" binding:"required"`
-	}
+	var req tenant_models.TicketTransitionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
 		return
 	}
 
-	ticket, err := h.service.UpdateTicketStatus(userID, tenantID, ticketID, req.Status)
+	ticket, err := h.service.UpdateTicketStatus(actor, ticketID, &req)
 	if err != nil {
-		h.logger.Error("Failed to update ticket status", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update ticket status")
+		h.respondError(c, err, "Failed to update ticket status")
 		return
 	}
 
 	utils.SuccessResponse(c, gin.H{"ticket": ticket})
 }
 
+// GetTicketTransitions handles GET /tickets/:id/transitions
+func (h *TicketHandler) GetTicketTransitions(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	ticketID := c.Param("id")
+	if ticketID == "" {
+		utils.BadRequestResponse(c, "Ticket ID is required")
+		return
+	}
+
+	transitions, err := h.service.GetTicketTransitions(actor, ticketID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get ticket transitions")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"transitions": transitions})
+}
+
+// BulkUpdateTickets handles POST /tickets/bulk
+func (h *TicketHandler) BulkUpdateTickets(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.TicketBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	job, err := h.service.BulkUpdateTickets(actor, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to run bulk operation")
+		return
+	}
+
+	// Jobs still queued run in the background; poll GET /tickets/bulk/:job_id
+	if job.Status == tenant_models.BulkJobQueued {
+		utils.AcceptedResponse(c, gin.H{"job": job})
+		return
+	}
+	utils.SuccessResponse(c, gin.H{"job": job})
+}
+
+// GetBulkJob handles GET /tickets/bulk/:job_id
+func (h *TicketHandler) GetBulkJob(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	job, err := h.service.GetBulkJob(actor, c.Param("job_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get bulk job")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"job": job})
+}
+
+// GetTicketLinks handles GET /tickets/:id/links
+func (h *TicketHandler) GetTicketLinks(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	ticketID := c.Param("id")
+	if ticketID == "" {
+		utils.BadRequestResponse(c, "Ticket ID is required")
+		return
+	}
+
+	links, err := h.service.GetTicketLinks(actor, ticketID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get ticket links")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"links": links.Links, "subtasks": links.Subtasks})
+}
+
+// CreateTicketLink handles POST /tickets/:id/links
+func (h *TicketHandler) CreateTicketLink(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	ticketID := c.Param("id")
+	if ticketID == "" {
+		utils.BadRequestResponse(c, "Ticket ID is required")
+		return
+	}
+
+	var req tenant_models.TicketLinkCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	link, err := h.service.CreateTicketLink(actor, ticketID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to link tickets")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"link": link})
+}
+
+// DeleteTicketLink handles DELETE /tickets/:id/links/:link_id
+func (h *TicketHandler) DeleteTicketLink(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	ticketID := c.Param("id")
+	if ticketID == "" {
+		utils.BadRequestResponse(c, "Ticket ID is required")
+		return
+	}
+
+	if err := h.service.DeleteTicketLink(actor, ticketID, c.Param("link_id")); err != nil {
+		h.respondError(c, err, "Failed to remove ticket link")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Ticket link removed successfully"})
+}
+
 // UpdateTicketPriority handles PATCH /tickets/:id/priority
 func (h *TicketHandler) UpdateTicketPriority(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -289,17 +477,16 @@ func (h *TicketHandler) UpdateTicketPriority(c *gin.Context) {
 	}
 
 	var req struct {
-		Priority models.TicketPriority `json:"priority" binding:"required"`
+		Priority tenant_models.TicketPriority `json:"priority" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
 		return
 	}
 
-	ticket, err := h.service.UpdateTicketPriority(userID, tenantID, ticketID, req.Priority)
+	ticket, err := h.service.UpdateTicketPriority(actor, ticketID, req.Priority)
 	if err != nil {
-		h.logger.Error("Failed to update ticket priority", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update ticket priority")
+		h.respondError(c, err, "Failed to update ticket priority")
 		return
 	}
 
@@ -308,7 +495,7 @@ func (h *TicketHandler) UpdateTicketPriority(c *gin.Context) {
 
 // GetTicketComments handles GET /tickets/:id/comments
 func (h *TicketHandler) GetTicketComments(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -322,10 +509,9 @@ func (h *TicketHandler) GetTicketComments(c *gin.Context) {
 
 	includeInternal := c.Query("include_internal") == "true"
 
-	comments, err := h.service.GetComments(userID, tenantID, ticketID, includeInternal)
+	comments, err := h.service.GetComments(actor, ticketID, includeInternal)
 	if err != nil {
-		h.logger.Error("Failed to get ticket comments", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get comments")
+		h.respondError(c, err, "Failed to get comments")
 		return
 	}
 
@@ -334,7 +520,7 @@ func (h *TicketHandler) GetTicketComments(c *gin.Context) {
 
 // CreateTicketComment handles POST /tickets/:id/comments
 func (h *TicketHandler) CreateTicketComment(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -346,16 +532,15 @@ func (h *TicketHandler) CreateTicketComment(c *gin.Context) {
 		return
 	}
 
-	var req models.TicketCommentCreateRequest
+	var req tenant_models.CommentCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
 		return
 	}
 
-	comment, err := h.service.CreateComment(userID, tenantID, ticketID, &req)
+	comment, err := h.service.CreateComment(actor, ticketID, &req)
 	if err != nil {
-		h.logger.Error("Failed to create comment", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create comment")
+		h.respondError(c, err, "Failed to create comment")
 		return
 	}
 
@@ -364,7 +549,7 @@ func (h *TicketHandler) CreateTicketComment(c *gin.Context) {
 
 // UpdateComment handles PUT /comments/:comment_id
 func (h *TicketHandler) UpdateComment(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -384,7 +569,7 @@ func (h *TicketHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.service.UpdateComment(userID, tenantID, commentID, req.Content)
+	comment, err := h.service.UpdateComment(actor, commentID, req.Content)
 	if err != nil {
 		h.logger.Error("Failed to update comment", zap.Error(err))
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update comment")
@@ -396,7 +581,7 @@ func (h *TicketHandler) UpdateComment(c *gin.Context) {
 
 // DeleteComment handles DELETE /comments/:comment_id
 func (h *TicketHandler) DeleteComment(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -408,7 +593,7 @@ func (h *TicketHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	err = h.service.DeleteComment(userID, tenantID, commentID)
+	err = h.service.DeleteComment(actor, commentID)
 	if err != nil {
 		h.logger.Error("Failed to delete comment", zap.Error(err))
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete comment")
@@ -420,7 +605,7 @@ func (h *TicketHandler) DeleteComment(c *gin.Context) {
 
 // SearchTickets handles GET /tickets/search
 func (h *TicketHandler) SearchTickets(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
@@ -436,10 +621,9 @@ func (h *TicketHandler) SearchTickets(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset := (page - 1) * limit
 
-	tickets, total, err := h.service.SearchTickets(userID, tenantID, query, limit, offset)
+	tickets, total, err := h.service.SearchTickets(actor, query, limit, offset)
 	if err != nil {
-		h.logger.Error("Failed to search tickets", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search tickets")
+		h.respondError(c, err, "Failed to search tickets")
 		return
 	}
 
@@ -456,35 +640,118 @@ func (h *TicketHandler) SearchTickets(c *gin.Context) {
 
 // GetTicketStats handles GET /tickets/stats
 func (h *TicketHandler) GetTicketStats(c *gin.Context) {
-	userID, tenantID, err := h.getUserAndTenantContext(c)
+	actor, err := h.getActor(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "Authentication required")
 		return
 	}
 
-	// Parse date range
-	var dateFrom, dateTo *time.Time
+	filters := repositories.TicketStatsFilters{
+		ProjectID:  c.Query("project_id"),
+		AssigneeID: c.Query("assignee_id"),
+		Interval:   c.Query("interval"),
+	}
+	if filters.AssigneeID == "me" {
+		filters.AssigneeID = actor.UserID
+	}
+
+	// Parse date range; a bare date covers that whole day
 	if from := c.Query("date_from"); from != "" {
-		if t, err := time.Parse(time.RFC3339, from); err == nil {
-			dateFrom = &t
+		t, err := parseStatsDate(from, false)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid date_from: use RFC3339 or YYYY-MM-DD")
+			return
 		}
+		filters.DateFrom = &t
 	}
 	if to := c.Query("date_to"); to != "" {
-		if t, err := time.Parse(time.RFC3339, to); err == nil {
-			dateTo = &t
+		t, err := parseStatsDate(to, true)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid date_to: use RFC3339 or YYYY-MM-DD")
+			return
 		}
+		filters.DateTo = &t
 	}
 
-	stats, err := h.service.GetTicketStats(userID, tenantID, dateFrom, dateTo)
+	stats, err := h.service.GetTicketStats(actor, filters)
 	if err != nil {
-		h.logger.Error("Failed to get ticket stats", zap.Error(err))
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get ticket statistics")
+		h.respondError(c, err, "Failed to get ticket statistics")
 		return
 	}
 
 	utils.SuccessResponse(c, gin.H{"stats": stats})
 }
 
+// parseStatsDate accepts RFC3339 timestamps or plain dates; endOfDay moves a plain date to its last instant
+func parseStatsDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// GetTicketHistory handles GET /tickets/:id/history
+func (h *TicketHandler) GetTicketHistory(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	ticketID := c.Param("id")
+	if ticketID == "" {
+		utils.BadRequestResponse(c, "Ticket ID is required")
+		return
+	}
+
+	timeline, err := h.service.GetTicketTimeline(actor, ticketID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get ticket history")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"timeline": timeline})
+}
+
+// GetTicketSLA handles GET /tickets/:id/sla
+func (h *TicketHandler) GetTicketSLA(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	ticketID := c.Param("id")
+	if ticketID == "" {
+		utils.BadRequestResponse(c, "Ticket ID is required")
+		return
+	}
+
+	sla, err := h.service.GetTicketSLA(actor, ticketID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.NotFoundResponse(c, "Ticket has no SLA")
+			return
+		}
+		if errors.Is(err, services.ErrAccessDenied) {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		h.logger.Error("Failed to get ticket SLA", zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get ticket SLA")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"sla": sla})
+}
+
 // Placeholder handlers for attachment functionality
 func (h *TicketHandler) GetTicketAttachments(c *gin.Context) {
 	utils.SuccessResponse(c, gin.H{"attachments": []string{}})
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+type ViewHandler struct {
+	service services.ViewService
+	logger  *zap.Logger
+}
+
+func NewViewHandler(service services.ViewService, logger *zap.Logger) *ViewHandler {
+	return &ViewHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Helper function to get the acting user with their tenant role
+func (h *ViewHandler) getActor(c *gin.Context) (services.Actor, error) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return services.Actor{}, fmt.Errorf("user ID not found in context")
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return services.Actor{}, fmt.Errorf("tenant context not found: %w", err)
+	}
+
+	return services.Actor{
+		UserID:   userID,
+		TenantID: tenantContext.TenantID,
+		Role:     tenantContext.UserRole,
+	}, nil
+}
+
+// respondError maps service errors onto HTTP responses; denials carry their reason
+func (h *ViewHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAccessDenied):
+		utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "View not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// ListViews handles GET /views
+func (h *ViewHandler) ListViews(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	views, err := h.service.ListViews(actor)
+	if err != nil {
+		h.respondError(c, err, "Failed to list views")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"views": views})
+}
+
+// CreateView handles POST /views
+func (h *ViewHandler) CreateView(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.SavedViewCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	view, err := h.service.CreateView(actor, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create view")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"view": view})
+}
+
+// GetView handles GET /views/:id
+func (h *ViewHandler) GetView(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	view, err := h.service.GetView(actor, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get view")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"view": view})
+}
+
+// UpdateView handles PUT /views/:id
+func (h *ViewHandler) UpdateView(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.SavedViewUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	view, err := h.service.UpdateView(actor, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update view")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"view": view})
+}
+
+// DeleteView handles DELETE /views/:id
+func (h *ViewHandler) DeleteView(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteView(actor, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete view")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "View deleted successfully"})
+}
+
+// GetViewTickets handles GET /views/:id/tickets
+func (h *ViewHandler) GetViewTickets(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	tickets, total, err := h.service.GetViewTickets(actor, c.Param("id"), limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to get view tickets")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"tickets": tickets,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetViewCounts handles GET /views/counts
+func (h *ViewHandler) GetViewCounts(c *gin.Context) {
+	actor, err := h.getActor(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	counts, err := h.service.GetViewCounts(actor)
+	if err != nil {
+		h.respondError(c, err, "Failed to count view tickets")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"counts": counts})
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/middleware"
+	"github.com/zen/shared/pkg/tenant_models"
+	"github.com/zen/shared/pkg/utils"
+	"ticket-service/internal/services"
+)
+
+type WorkflowHandler struct {
+	service services.WorkflowService
+	logger  *zap.Logger
+}
+
+func NewWorkflowHandler(service services.WorkflowService, logger *zap.Logger) *WorkflowHandler {
+	return &WorkflowHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// getUserAndTenantContext extracts the authenticated user and tenant
+func (h *WorkflowHandler) getUserAndTenantContext(c *gin.Context) (string, string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		return "", "", false
+	}
+
+	tenantContext, err := middleware.GetTenantContext(c)
+	if err != nil {
+		return "", "", false
+	}
+
+	return userID, tenantContext.TenantID, true
+}
+
+// respondError maps service errors onto HTTP responses
+func (h *WorkflowHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundResponse(c, "Resource not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		utils.ErrorResponse(c, http.StatusInternalServerError, message)
+	}
+}
+
+// ListWorkflows handles GET /workflows
+func (h *WorkflowHandler) ListWorkflows(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	workflows, err := h.service.ListWorkflows(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list workflows")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"workflows": workflows})
+}
+
+// CreateWorkflow handles POST /workflows
+func (h *WorkflowHandler) CreateWorkflow(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.WorkflowCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	workflow, err := h.service.CreateWorkflow(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create workflow")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"workflow": workflow})
+}
+
+// GetWorkflow handles GET /workflows/:id
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	workflow, err := h.service.GetWorkflow(userID, tenantID, c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get workflow")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"workflow": workflow})
+}
+
+// UpdateWorkflow handles PUT /workflows/:id
+func (h *WorkflowHandler) UpdateWorkflow(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.WorkflowUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	workflow, err := h.service.UpdateWorkflow(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update workflow")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"workflow": workflow})
+}
+
+// DeleteWorkflow handles DELETE /workflows/:id
+func (h *WorkflowHandler) DeleteWorkflow(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteWorkflow(userID, tenantID, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete workflow")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Workflow deleted successfully"})
+}
+
+// ListStatuses handles GET /workflows/statuses
+func (h *WorkflowHandler) ListStatuses(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	statuses, err := h.service.ListStatuses(userID, tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list statuses")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"statuses": statuses})
+}
+
+// CreateStatus handles POST /workflows/statuses
+func (h *WorkflowHandler) CreateStatus(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.CustomStatusCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	status, err := h.service.CreateStatus(userID, tenantID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create status")
+		return
+	}
+
+	utils.CreatedResponse(c, gin.H{"status": status})
+}
+
+// UpdateStatus handles PUT /workflows/statuses/:id
+func (h *WorkflowHandler) UpdateStatus(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	var req tenant_models.CustomStatusUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	status, err := h.service.UpdateStatus(userID, tenantID, c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update status")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"status": status})
+}
+
+// DeleteStatus handles DELETE /workflows/statuses/:id
+func (h *WorkflowHandler) DeleteStatus(c *gin.Context) {
+	userID, tenantID, ok := h.getUserAndTenantContext(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteStatus(userID, tenantID, c.Param("id")); err != nil {
+		h.respondError(c, err, "Failed to delete status")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"message": "Status deleted successfully"})
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// AutomationLogFilters narrows the automation log; empty fields match everything
+type AutomationLogFilters struct {
+	RuleID   string
+	TicketID string
+	Status   string
+}
+
+// AutomationRepository stores automation rules and the log of their firings
+type AutomationRepository interface {
+	CreateRule(tenantID string, rule *tenant_models.AutomationRule) error
+	GetRule(tenantID, ruleID string) (*tenant_models.AutomationRule, error)
+	UpdateRule(tenantID string, rule *tenant_models.AutomationRule) error
+	DeleteRule(tenantID, ruleID string) error
+	ListRules(tenantID string, trigger tenant_models.AutomationTrigger, activeOnly bool) ([]*tenant_models.AutomationRule, error)
+
+	CreateLog(tenantID string, log *tenant_models.AutomationLog) error
+	ListLogs(tenantID string, filters AutomationLogFilters, limit, offset int) ([]*tenant_models.AutomationLog, int64, error)
+	CountFirings(tenantID, ruleID, ticketID string, since time.Time) (int64, error)
+	LastFirings(tenantID, ruleID string, ticketIDs []string) (map[string]time.Time, error)
+}
+
+type automationRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewAutomationRepository(tenantDBManager *database.TenantDatabaseManager) AutomationRepository {
+	return &automationRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *automationRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *automationRepository) CreateRule(tenantID string, rule *tenant_models.AutomationRule) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(rule).Error
+}
+
+func (r *automationRepository) GetRule(tenantID, ruleID string) (*tenant_models.AutomationRule, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rule tenant_models.AutomationRule
+	err = db.Where("id = ?", ruleID).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *automationRepository) UpdateRule(tenantID string, rule *tenant_models.AutomationRule) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(rule).Error
+}
+
+func (r *automationRepository) DeleteRule(tenantID, ruleID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", ruleID).Delete(&tenant_models.AutomationRule{}).Error
+}
+
+// ListRules returns rules in evaluation order; an empty trigger lists every rule
+func (r *automationRepository) ListRules(tenantID string, trigger tenant_models.AutomationTrigger, activeOnly bool) ([]*tenant_models.AutomationRule, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Model(&tenant_models.AutomationRule{})
+	if trigger != "" {
+		query = query.Where("trigger = ?", trigger)
+	}
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var rules []*tenant_models.AutomationRule
+	err = query.Order("position ASC, created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *automationRepository) CreateLog(tenantID string, log *tenant_models.AutomationLog) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(log).Error
+}
+
+func (r *automationRepository) ListLogs(tenantID string, filters AutomationLogFilters, limit, offset int) ([]*tenant_models.AutomationLog, int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := db.Model(&tenant_models.AutomationLog{})
+	if filters.RuleID != "" {
+		query = query.Where("rule_id = ?", filters.RuleID)
+	}
+	if filters.TicketID != "" {
+		query = query.Where("ticket_id = ?", filters.TicketID)
+	}
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*tenant_models.AutomationLog
+	err = query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&logs).Error
+	return logs, total, err
+}
+
+// CountFirings counts how often a rule ran actions on a ticket since the given time
+func (r *automationRepository) CountFirings(tenantID, ruleID, ticketID string, since time.Time) (int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Model(&tenant_models.AutomationLog{}).
+		Where("rule_id = ? AND ticket_id = ? AND status <> ? AND created_at >= ?", ruleID, ticketID, tenant_models.AutomationSkipped, since).
+		Count(&count).Error
+	return count, err
+}
+
+// LastFirings returns when the rule last ran actions on each of the tickets, keyed by ticket ID
+func (r *automationRepository) LastFirings(tenantID, ruleID string, ticketIDs []string) (map[string]time.Time, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	firings := make(map[string]time.Time, len(ticketIDs))
+	if len(ticketIDs) == 0 {
+		return firings, nil
+	}
+
+	var rows []struct {
+		TicketID string
+		FiredAt  time.Time
+	}
+	err = db.Model(&tenant_models.AutomationLog{}).
+		Select("ticket_id, MAX(created_at) AS fired_at").
+		Where("rule_id = ? AND ticket_id IN ? AND status <> ?", ruleID, ticketIDs, tenant_models.AutomationSkipped).
+		Group("ticket_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		firings[row.TicketID] = row.FiredAt
+	}
+	return firings, nil
+}
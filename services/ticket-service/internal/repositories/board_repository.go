@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/rank"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rebalanceBatch is how many tickets one statement re-ranks
+const rebalanceBatch = 500
+
+// LimitedColumn is a board column with a WIP limit, along with its board's name
+type LimitedColumn struct {
+	tenant_models.BoardColumn
+	BoardName string
+}
+
+// BoardRepository reads what ticket moves must respect on the project's boards, which
+// project-service manages, and keeps the tickets' board ranks
+type BoardRepository interface {
+	// LimitedColumns returns the project's board columns holding the status that have a WIP limit
+	LimitedColumns(tenantID, projectID string, status tenant_models.TicketStatus) ([]*LimitedColumn, error)
+	CountInStatuses(tenantID, projectID string, statuses []string, excludeTicketID string) (int64, error)
+
+	// LastRank returns the highest board rank in the project, or "" when none is ranked
+	LastRank(tenantID, projectID string) (string, error)
+	// GetRanks returns the board ranks of the project's tickets among ticketIDs, by ticket ID
+	GetRanks(tenantID, projectID string, ticketIDs []string) (map[string]string, error)
+	// Rebalance spreads fresh, distinct ranks over the project's tickets in board order,
+	// ranking unranked tickets last
+	Rebalance(tenantID, projectID string) error
+}
+
+type boardRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewBoardRepository(tenantDBManager *database.TenantDatabaseManager) BoardRepository {
+	return &boardRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *boardRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *boardRepository) LimitedColumns(tenantID, projectID string, status tenant_models.TicketStatus) ([]*LimitedColumn, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	holds, err := json.Marshal([]string{string(status)})
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*LimitedColumn
+	err = db.Table("board_columns").
+		Select("board_columns.*, boards.name AS board_name").
+		Joins("JOIN boards ON boards.id = board_columns.board_id").
+		Where("boards.project_id = ? AND board_columns.wip_limit > 0", projectID).
+		Where("board_columns.statuses @> ?", string(holds)).
+		Scan(&columns).Error
+	return columns, err
+}
+
+func (r *boardRepository) CountInStatuses(tenantID, projectID string, statuses []string, excludeTicketID string) (int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Model(&tenant_models.Ticket{}).
+		Where("project_id = ? AND status IN ? AND id <> ?", projectID, statuses, excludeTicketID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *boardRepository) LastRank(tenantID, projectID string) (string, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	return lastRank(db, projectID)
+}
+
+// lastRank returns the highest board rank in the project. Soft-deleted tickets keep their
+// rank, so a restored ticket never collides.
+func lastRank(db *gorm.DB, projectID string) (string, error) {
+	var last *string
+	err := db.Unscoped().Model(&tenant_models.Ticket{}).
+		Where("project_id = ?", projectID).
+		Select("max(board_rank)").
+		Scan(&last).Error
+	if err != nil || last == nil {
+		return "", err
+	}
+	return *last, nil
+}
+
+func (r *boardRepository) GetRanks(tenantID, projectID string, ticketIDs []string) (map[string]string, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		ID        string
+		BoardRank string
+	}
+	err = db.Model(&tenant_models.Ticket{}).
+		Where("project_id = ? AND id IN ?", projectID, ticketIDs).
+		Select("id, board_rank").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ranks := make(map[string]string, len(rows))
+	for _, row := range rows {
+		ranks[row.ID] = row.BoardRank
+	}
+	return ranks, nil
+}
+
+func (r *boardRepository) Rebalance(tenantID, projectID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Locking the rows holds off ranking in the project until the new ranks are in
+		var ticketIDs []string
+		err := tx.Unscoped().Model(&tenant_models.Ticket{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("project_id = ?", projectID).
+			Order("coalesce(board_rank, '') = '' ASC, board_rank ASC, ticket_number ASC").
+			Pluck("id", &ticketIDs).Error
+		if err != nil {
+			return err
+		}
+
+		ranks := rank.Spread(len(ticketIDs))
+		for start := 0; start < len(ticketIDs); start += rebalanceBatch {
+			end := min(start+rebalanceBatch, len(ticketIDs))
+			rows := make([]string, 0, end-start)
+			args := make([]interface{}, 0, 2*(end-start))
+			for i := start; i < end; i++ {
+				rows = append(rows, "(?::uuid, ?)")
+				args = append(args, ticketIDs[i], ranks[i])
+			}
+			query := `UPDATE tickets SET board_rank = v.board_rank FROM (VALUES ` + strings.Join(rows, ", ") +
+				`) AS v(id, board_rank) WHERE tickets.id = v.id`
+			if err := tx.Exec(query, args...).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// BulkJobRepository persists bulk ticket operations so their progress survives the request
+type BulkJobRepository interface {
+	Create(tenantID string, job *tenant_models.BulkJob) error
+	// Update saves an unfinished job; false means it had already finished and was left alone
+	Update(tenantID string, job *tenant_models.BulkJob) (bool, error)
+	// Heartbeat marks an unfinished job as still being worked on
+	Heartbeat(tenantID, jobID string) error
+	// FailStalled fails an unfinished job not saved since before; false means it was
+	// saved since, or has finished
+	FailStalled(tenantID, jobID string, before time.Time, message string) (bool, error)
+	Get(tenantID, jobID string) (*tenant_models.BulkJob, error)
+}
+
+var unfinishedBulkJob = []tenant_models.BulkJobStatus{tenant_models.BulkJobQueued, tenant_models.BulkJobRunning}
+
+type bulkJobRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewBulkJobRepository(tenantDBManager *database.TenantDatabaseManager) BulkJobRepository {
+	return &bulkJobRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *bulkJobRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *bulkJobRepository) Create(tenantID string, job *tenant_models.BulkJob) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(job).Error
+}
+
+func (r *bulkJobRepository) Update(tenantID string, job *tenant_models.BulkJob) (bool, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	result := db.Model(job).Where("status IN ?", unfinishedBulkJob).Select("*").Updates(job)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *bulkJobRepository) Heartbeat(tenantID, jobID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&tenant_models.BulkJob{}).
+		Where("id = ? AND status IN ?", jobID, unfinishedBulkJob).
+		Update("updated_at", time.Now()).Error
+}
+
+func (r *bulkJobRepository) FailStalled(tenantID, jobID string, before time.Time, message string) (bool, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	result := db.Model(&tenant_models.BulkJob{}).
+		Where("id = ? AND status IN ? AND updated_at < ?", jobID, unfinishedBulkJob, before).
+		Updates(map[string]interface{}{
+			"status":       tenant_models.BulkJobFailed,
+			"error":        message,
+			"completed_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *bulkJobRepository) Get(tenantID, jobID string) (*tenant_models.BulkJob, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var job tenant_models.BulkJob
+	err = db.Where("id = ?", jobID).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// CSATSurveyFilters narrows the survey list; empty fields match everything
+type CSATSurveyFilters struct {
+	TicketID      string
+	AgentID       string
+	ProjectID     string
+	RespondedOnly bool
+	MaxRating     int // Only answers rated this or lower; 0 for any
+}
+
+// CSATRepository stores satisfaction surveys and the tenant's survey settings
+type CSATRepository interface {
+	CreateSurvey(tenantID string, survey *tenant_models.CSATSurvey) error
+	GetSurvey(tenantID, surveyID string) (*tenant_models.CSATSurvey, error)
+	UpdateSurvey(tenantID string, survey *tenant_models.CSATSurvey) error
+	ListSurveys(tenantID string, filters CSATSurveyFilters, limit, offset int) ([]*tenant_models.CSATSurvey, int64, error)
+	RecordAnswer(tenantID, surveyID string, rating int, comment string, at time.Time) (bool, error)
+
+	GetSettings(tenantID string) (*tenant_models.CSATSettings, error)
+	SaveSettings(tenantID string, settings *tenant_models.CSATSettings) error
+}
+
+type csatRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewCSATRepository(tenantDBManager *database.TenantDatabaseManager) CSATRepository {
+	return &csatRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *csatRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *csatRepository) CreateSurvey(tenantID string, survey *tenant_models.CSATSurvey) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(survey).Error
+}
+
+func (r *csatRepository) GetSurvey(tenantID, surveyID string) (*tenant_models.CSATSurvey, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var survey tenant_models.CSATSurvey
+	err = db.Where("id = ?", surveyID).First(&survey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &survey, nil
+}
+
+func (r *csatRepository) UpdateSurvey(tenantID string, survey *tenant_models.CSATSurvey) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(survey).Error
+}
+
+func (r *csatRepository) ListSurveys(tenantID string, filters CSATSurveyFilters, limit, offset int) ([]*tenant_models.CSATSurvey, int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := db.Model(&tenant_models.CSATSurvey{})
+	if filters.TicketID != "" {
+		query = query.Where("ticket_id = ?", filters.TicketID)
+	}
+	if filters.AgentID != "" {
+		query = query.Where("agent_id = ?", filters.AgentID)
+	}
+	if filters.ProjectID != "" {
+		query = query.Where("project_id = ?", filters.ProjectID)
+	}
+	if filters.RespondedOnly {
+		query = query.Where("responded_at IS NOT NULL")
+	}
+	if filters.MaxRating > 0 {
+		query = query.Where("rating <= ?", filters.MaxRating)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var surveys []*tenant_models.CSATSurvey
+	err = query.Order("COALESCE(responded_at, created_at) DESC").Limit(limit).Offset(offset).Find(&surveys).Error
+	return surveys, total, err
+}
+
+// RecordAnswer stores the rating unless the survey was already answered; false means it was
+func (r *csatRepository) RecordAnswer(tenantID, surveyID string, rating int, comment string, at time.Time) (bool, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	result := db.Model(&tenant_models.CSATSurvey{}).
+		Where("id = ? AND responded_at IS NULL", surveyID).
+		Updates(map[string]interface{}{
+			"rating":       rating,
+			"comment":      comment,
+			"responded_at": at,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetSettings returns the tenant's survey settings, or the defaults if it has none
+func (r *csatRepository) GetSettings(tenantID string) (*tenant_models.CSATSettings, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings tenant_models.CSATSettings
+	err = db.Order("created_at ASC").First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tenant_models.DefaultCSATSettings(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *csatRepository) SaveSettings(tenantID string, settings *tenant_models.CSATSettings) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(settings).Error
+}
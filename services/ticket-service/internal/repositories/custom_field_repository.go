@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// customFieldKeyPattern keeps keys safe to write into SQL and index names as they are
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,47}$`)
+
+// ValidCustomFieldKey reports whether key can name a custom field
+func ValidCustomFieldKey(key string) bool {
+	return customFieldKeyPattern.MatchString(key)
+}
+
+// customFieldExpr is the ticket expression holding a custom field's value. It is the
+// expression of the field's index, so queries must use it verbatim to hit the index.
+func customFieldExpr(key string) string {
+	return "(custom_fields -> '" + key + "')"
+}
+
+// CustomFieldRepository stores the tenant's custom field definitions
+type CustomFieldRepository interface {
+	Create(tenantID string, field *tenant_models.CustomFieldDefinition) error
+	GetByID(tenantID, fieldID string) (*tenant_models.CustomFieldDefinition, error)
+	Update(tenantID string, field *tenant_models.CustomFieldDefinition) error
+	Delete(tenantID, fieldID string) error
+	List(tenantID string, activeOnly bool) ([]*tenant_models.CustomFieldDefinition, error)
+
+	// Expression indexes for sorting and ranges on one field
+	CreateIndex(tenantID, key string) error
+	DropIndex(tenantID, key string) error
+}
+
+type customFieldRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewCustomFieldRepository(tenantDBManager *database.TenantDatabaseManager) CustomFieldRepository {
+	return &customFieldRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *customFieldRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *customFieldRepository) Create(tenantID string, field *tenant_models.CustomFieldDefinition) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(field).Error
+}
+
+func (r *customFieldRepository) GetByID(tenantID, fieldID string) (*tenant_models.CustomFieldDefinition, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var field tenant_models.CustomFieldDefinition
+	err = db.Where("id = ?", fieldID).First(&field).Error
+	if err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+func (r *customFieldRepository) Update(tenantID string, field *tenant_models.CustomFieldDefinition) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(field).Error
+}
+
+// Delete removes the definition; tickets keep their values as untyped custom fields
+func (r *customFieldRepository) Delete(tenantID, fieldID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	result := db.Where("id = ?", fieldID).Delete(&tenant_models.CustomFieldDefinition{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *customFieldRepository) List(tenantID string, activeOnly bool) ([]*tenant_models.CustomFieldDefinition, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Order("position ASC, name ASC")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var fields []*tenant_models.CustomFieldDefinition
+	err = query.Find(&fields).Error
+	return fields, err
+}
+
+// CreateIndex builds the field's expression index without locking tickets against
+// writes, so it may take a while on large tenants
+func (r *customFieldRepository) CreateIndex(tenantID, key string) error {
+	if !ValidCustomFieldKey(key) {
+		return fmt.Errorf("invalid custom field key %q", key)
+	}
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tickets_cf_%s ON tickets (%s)", key, customFieldExpr(key))).Error
+}
+
+func (r *customFieldRepository) DropIndex(tenantID, key string) error {
+	if !ValidCustomFieldKey(key) {
+		return fmt.Errorf("invalid custom field key %q", key)
+	}
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS idx_tickets_cf_%s", key)).Error
+}
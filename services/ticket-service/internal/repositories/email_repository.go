@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// EmailMessageRepository stores the messages exchanged on tickets for threading
+type EmailMessageRepository interface {
+	Create(tenantID string, message *tenant_models.EmailMessage) error
+	FindByMessageIDs(tenantID string, messageIDs []string) (*tenant_models.EmailMessage, error)
+	ListByTicket(tenantID, ticketID string) ([]*tenant_models.EmailMessage, error)
+}
+
+type emailMessageRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewEmailMessageRepository(tenantDBManager *database.TenantDatabaseManager) EmailMessageRepository {
+	return &emailMessageRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *emailMessageRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *emailMessageRepository) Create(tenantID string, message *tenant_models.EmailMessage) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(message).Error
+}
+
+// FindByMessageIDs returns the most recent stored message with any of the IDs, or
+// gorm.ErrRecordNotFound when none is known
+func (r *emailMessageRepository) FindByMessageIDs(tenantID string, messageIDs []string) (*tenant_models.EmailMessage, error) {
+	if len(messageIDs) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var message tenant_models.EmailMessage
+	err = db.Where("message_id IN ?", messageIDs).Order("created_at DESC").First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *emailMessageRepository) ListByTicket(tenantID, ticketID string) ([]*tenant_models.EmailMessage, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []*tenant_models.EmailMessage
+	err = db.Where("ticket_id = ?", ticketID).Order("created_at ASC").Find(&messages).Error
+	return messages, err
+}
@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// ExportRepository stores scheduled ticket exports and the files they produce
+type ExportRepository interface {
+	CreateSchedule(tenantID string, schedule *tenant_models.ScheduledExport) error
+	GetSchedule(tenantID, scheduleID string) (*tenant_models.ScheduledExport, error)
+	UpdateSchedule(tenantID string, schedule *tenant_models.ScheduledExport) error
+	DeleteSchedule(tenantID, scheduleID string) error
+	ListSchedules(tenantID string) ([]*tenant_models.ScheduledExport, error)
+
+	// Scheduled runs
+	ListDueSchedules(tenantID string, now time.Time) ([]*tenant_models.ScheduledExport, error)
+	ClaimRun(tenantID, scheduleID string, due, next time.Time) (bool, error)
+
+	// Files
+	CreateFile(tenantID string, file *tenant_models.ExportFile) error
+	GetFile(tenantID, fileID string) (*tenant_models.ExportFile, error)
+	ListFiles(tenantID, scheduleID string, limit, offset int) ([]*tenant_models.ExportFile, int64, error)
+	ListExpiredFiles(tenantID string, now time.Time) ([]*tenant_models.ExportFile, error)
+	DeleteFile(tenantID, fileID string) error
+}
+
+type exportRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewExportRepository(tenantDBManager *database.TenantDatabaseManager) ExportRepository {
+	return &exportRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *exportRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *exportRepository) CreateSchedule(tenantID string, schedule *tenant_models.ScheduledExport) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(schedule).Error
+}
+
+func (r *exportRepository) GetSchedule(tenantID, scheduleID string) (*tenant_models.ScheduledExport, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule tenant_models.ScheduledExport
+	err = db.Where("id = ?", scheduleID).First(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *exportRepository) UpdateSchedule(tenantID string, schedule *tenant_models.ScheduledExport) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(schedule).Error
+}
+
+// DeleteSchedule removes the schedule; files it already produced stay until they expire
+func (r *exportRepository) DeleteSchedule(tenantID, scheduleID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	result := db.Where("id = ?", scheduleID).Delete(&tenant_models.ScheduledExport{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *exportRepository) ListSchedules(tenantID string) ([]*tenant_models.ScheduledExport, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedules []*tenant_models.ScheduledExport
+	err = db.Order("name ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *exportRepository) ListDueSchedules(tenantID string, now time.Time) ([]*tenant_models.ScheduledExport, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedules []*tenant_models.ScheduledExport
+	err = db.Where("enabled = ? AND next_run_at <= ?", true, now).Order("next_run_at ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+// ClaimRun moves the schedule's next run from due to next unless another instance
+// already did; false means the run belongs to someone else
+func (r *exportRepository) ClaimRun(tenantID, scheduleID string, due, next time.Time) (bool, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	result := db.Model(&tenant_models.ScheduledExport{}).
+		Where("id = ? AND next_run_at = ?", scheduleID, due).
+		Update("next_run_at", next)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *exportRepository) CreateFile(tenantID string, file *tenant_models.ExportFile) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(file).Error
+}
+
+func (r *exportRepository) GetFile(tenantID, fileID string) (*tenant_models.ExportFile, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var file tenant_models.ExportFile
+	err = db.Where("id = ?", fileID).First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *exportRepository) ListFiles(tenantID, scheduleID string, limit, offset int) ([]*tenant_models.ExportFile, int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := db.Model(&tenant_models.ExportFile{})
+	if scheduleID != "" {
+		query = query.Where("scheduled_export_id = ?", scheduleID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var files []*tenant_models.ExportFile
+	err = query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&files).Error
+	return files, total, err
+}
+
+func (r *exportRepository) ListExpiredFiles(tenantID string, now time.Time) ([]*tenant_models.ExportFile, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*tenant_models.ExportFile
+	err = db.Where("expires_at <= ?", now).Find(&files).Error
+	return files, err
+}
+
+func (r *exportRepository) DeleteFile(tenantID, fileID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", fileID).Delete(&tenant_models.ExportFile{}).Error
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/rank"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// ImportedTicket is one imported row with everything that belongs to it
+type ImportedTicket struct {
+	Ticket      *tenant_models.Ticket
+	Comments    []*ImportedComment
+	Attachments []*tenant_models.Attachment
+	History     []*tenant_models.TicketHistory
+	Record      *tenant_models.ImportRecord
+}
+
+type ImportedComment struct {
+	Comment     *tenant_models.Comment
+	Attachments []*tenant_models.Attachment
+}
+
+// ImportRepository persists ticket import jobs and writes the tickets they import
+type ImportRepository interface {
+	CreateJob(tenantID string, job *tenant_models.ImportJob) error
+	UpdateJob(tenantID string, job *tenant_models.ImportJob) error
+	GetJob(tenantID, jobID string) (*tenant_models.ImportJob, error)
+	ListJobs(tenantID string, limit, offset int) ([]*tenant_models.ImportJob, int64, error)
+
+	// ListRecords finds rows of the job already imported and, by external ID, tickets
+	// any import already brought in
+	ListRecords(tenantID, jobID string, fromRow, toRow int, externalIDs []string) ([]*tenant_models.ImportRecord, error)
+	// ImportTicket writes the ticket, its comments, attachments and history and the
+	// import record in one transaction
+	ImportTicket(tenantID string, imported *ImportedTicket) error
+
+	// Names tickets are matched against
+	ListPriorities(tenantID string) ([]*tenant_models.CustomPriority, error)
+	ListTicketTypes(tenantID string) ([]*tenant_models.CustomTicketType, error)
+}
+
+type importRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewImportRepository(tenantDBManager *database.TenantDatabaseManager) ImportRepository {
+	return &importRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *importRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *importRepository) CreateJob(tenantID string, job *tenant_models.ImportJob) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(job).Error
+}
+
+func (r *importRepository) UpdateJob(tenantID string, job *tenant_models.ImportJob) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(job).Error
+}
+
+func (r *importRepository) GetJob(tenantID, jobID string) (*tenant_models.ImportJob, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var job tenant_models.ImportJob
+	err = db.Where("id = ?", jobID).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *importRepository) ListJobs(tenantID string, limit, offset int) ([]*tenant_models.ImportJob, int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := db.Model(&tenant_models.ImportJob{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// The row error report can be large; it is only returned for a single job
+	var jobs []*tenant_models.ImportJob
+	err = db.Omit("errors").Order("created_at DESC").Limit(limit).Offset(offset).Find(&jobs).Error
+	return jobs, total, err
+}
+
+func (r *importRepository) ListRecords(tenantID, jobID string, fromRow, toRow int, externalIDs []string) ([]*tenant_models.ImportRecord, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Where("job_id = ? AND row_number BETWEEN ? AND ?", jobID, fromRow, toRow)
+	if len(externalIDs) > 0 {
+		query = query.Or("external_id IN ?", externalIDs)
+	}
+
+	var records []*tenant_models.ImportRecord
+	err = query.Find(&records).Error
+	return records, err
+}
+
+func (r *importRepository) ImportTicket(tenantID string, imported *ImportedTicket) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Imported tickets join the bottom of their project's boards
+		if projectID := imported.Ticket.ProjectID; projectID != nil && imported.Ticket.BoardRank == "" {
+			last, err := lastRank(tx, *projectID)
+			if err != nil {
+				return err
+			}
+			imported.Ticket.BoardRank = rank.After(last)
+		}
+
+		if err := tx.Create(imported.Ticket).Error; err != nil {
+			return err
+		}
+		ticketID := imported.Ticket.ID
+
+		for _, attachment := range imported.Attachments {
+			attachment.TicketID = &ticketID
+		}
+		if len(imported.Attachments) > 0 {
+			if err := tx.Create(imported.Attachments).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, entry := range imported.Comments {
+			entry.Comment.TicketID = ticketID
+			if err := tx.Create(entry.Comment).Error; err != nil {
+				return err
+			}
+			commentID := entry.Comment.ID
+			for _, attachment := range entry.Attachments {
+				attachment.CommentID = &commentID
+			}
+			if len(entry.Attachments) > 0 {
+				if err := tx.Create(entry.Attachments).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, entry := range imported.History {
+			entry.TicketID = ticketID
+		}
+		if err := createHistory(tx, imported.History); err != nil {
+			return err
+		}
+
+		imported.Record.TicketID = ticketID
+		return tx.Create(imported.Record).Error
+	})
+}
+
+func (r *importRepository) ListPriorities(tenantID string) ([]*tenant_models.CustomPriority, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var priorities []*tenant_models.CustomPriority
+	err = db.Where("is_active = ?", true).Order("level ASC").Find(&priorities).Error
+	return priorities, err
+}
+
+func (r *importRepository) ListTicketTypes(tenantID string) ([]*tenant_models.CustomTicketType, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []*tenant_models.CustomTicketType
+	err = db.Where("is_active = ?", true).Order("name ASC").Find(&types).Error
+	return types, err
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// MacroRepository stores canned responses
+type MacroRepository interface {
+	Create(tenantID string, macro *tenant_models.Macro) error
+	Get(tenantID, macroID string) (*tenant_models.Macro, error)
+	Update(tenantID string, macro *tenant_models.Macro) error
+	Delete(tenantID, macroID string) error
+	ListVisible(tenantID, userID string) ([]*tenant_models.Macro, error)
+	IncrementUsage(tenantID, macroID string) error
+}
+
+type macroRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewMacroRepository(tenantDBManager *database.TenantDatabaseManager) MacroRepository {
+	return &macroRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *macroRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *macroRepository) Create(tenantID string, macro *tenant_models.Macro) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(macro).Error
+}
+
+func (r *macroRepository) Get(tenantID, macroID string) (*tenant_models.Macro, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var macro tenant_models.Macro
+	err = db.Where("id = ?", macroID).First(&macro).Error
+	if err != nil {
+		return nil, err
+	}
+	return &macro, nil
+}
+
+func (r *macroRepository) Update(tenantID string, macro *tenant_models.Macro) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(macro).Error
+}
+
+func (r *macroRepository) Delete(tenantID, macroID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", macroID).Delete(&tenant_models.Macro{}).Error
+}
+
+// ListVisible returns the shared macros and the user's personal ones, most used first
+func (r *macroRepository) ListVisible(tenantID, userID string) ([]*tenant_models.Macro, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var macros []*tenant_models.Macro
+	err = db.Where("scope = ? OR (scope = ? AND owner_id = ?)", tenant_models.MacroShared, tenant_models.MacroPersonal, userID).
+		Order("usage_count DESC, name ASC").
+		Find(&macros).Error
+	return macros, err
+}
+
+func (r *macroRepository) IncrementUsage(tenantID, macroID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&tenant_models.Macro{}).Where("id = ?", macroID).
+		UpdateColumn("usage_count", gorm.Expr("usage_count + 1")).Error
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// PortalLoginRepository stores pending customer portal sign-ins
+type PortalLoginRepository interface {
+	Create(tenantID string, login *tenant_models.PortalLogin) error
+	GetByTokenHash(tenantID, tokenHash string) (*tenant_models.PortalLogin, error)
+	GetLatest(tenantID, email string) (*tenant_models.PortalLogin, error)
+	CountSince(tenantID, email string, since time.Time) (int64, error)
+	RecordAttempt(tenantID, loginID string) error
+	MarkUsed(tenantID, loginID string, usedAt time.Time) (bool, error)
+}
+
+type portalLoginRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewPortalLoginRepository(tenantDBManager *database.TenantDatabaseManager) PortalLoginRepository {
+	return &portalLoginRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *portalLoginRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *portalLoginRepository) Create(tenantID string, login *tenant_models.PortalLogin) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(login).Error
+}
+
+func (r *portalLoginRepository) GetByTokenHash(tenantID, tokenHash string) (*tenant_models.PortalLogin, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var login tenant_models.PortalLogin
+	err = db.Where("token_hash = ?", tokenHash).First(&login).Error
+	if err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
+
+// GetLatest returns the address's most recent sign-in; only it accepts a code
+func (r *portalLoginRepository) GetLatest(tenantID, email string) (*tenant_models.PortalLogin, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var login tenant_models.PortalLogin
+	err = db.Where("email = ?", email).Order("created_at DESC").First(&login).Error
+	if err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
+
+// CountSince counts the sign-ins started for the address since the given time
+func (r *portalLoginRepository) CountSince(tenantID, email string, since time.Time) (int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Model(&tenant_models.PortalLogin{}).
+		Where("email = ? AND created_at >= ?", email, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *portalLoginRepository) RecordAttempt(tenantID, loginID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&tenant_models.PortalLogin{}).
+		Where("id = ?", loginID).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// MarkUsed consumes the sign-in; false means another request already used it
+func (r *portalLoginRepository) MarkUsed(tenantID, loginID string, usedAt time.Time) (bool, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	result := db.Model(&tenant_models.PortalLogin{}).
+		Where("id = ? AND used_at IS NULL", loginID).
+		UpdateColumn("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
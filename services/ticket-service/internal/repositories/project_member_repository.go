@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// ProjectMemberRepository reads project membership for ticket authorization
+type ProjectMemberRepository interface {
+	GetMembership(tenantID, projectID, userID string) (*tenant_models.ProjectMember, error)
+	ListMemberships(tenantID, userID string) ([]*tenant_models.ProjectMember, error)
+}
+
+type projectMemberRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewProjectMemberRepository(tenantDBManager *database.TenantDatabaseManager) ProjectMemberRepository {
+	return &projectMemberRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *projectMemberRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+// GetMembership returns gorm.ErrRecordNotFound when the user is not a member of the project
+func (r *projectMemberRepository) GetMembership(tenantID, projectID, userID string) (*tenant_models.ProjectMember, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var member tenant_models.ProjectMember
+	err = db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *projectMemberRepository) ListMemberships(tenantID, userID string) ([]*tenant_models.ProjectMember, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*tenant_models.ProjectMember
+	err = db.Where("user_id = ?", userID).Find(&members).Error
+	return members, err
+}
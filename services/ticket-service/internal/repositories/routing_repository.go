@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoutingRepository stores agent groups, their members and agent availability
+type RoutingRepository interface {
+	// Groups
+	CreateGroup(tenantID string, group *tenant_models.AgentGroup) error
+	GetGroup(tenantID, groupID string) (*tenant_models.AgentGroup, error)
+	UpdateGroup(tenantID string, group *tenant_models.AgentGroup) error
+	DeleteGroup(tenantID, groupID string) error
+	ListGroups(tenantID string, activeOnly bool) ([]*tenant_models.AgentGroup, error)
+
+	// Members
+	AddMember(tenantID string, member *tenant_models.AgentGroupMember) error
+	GetMember(tenantID, groupID, userID string) (*tenant_models.AgentGroupMember, error)
+	UpdateMember(tenantID string, member *tenant_models.AgentGroupMember) error
+	RemoveMember(tenantID, groupID, userID string) error
+	MarkAssigned(tenantID, memberID string, at time.Time) error
+
+	// Availability
+	GetAvailability(tenantID string, userIDs []string) (map[string]*tenant_models.AgentAvailability, error)
+	ListAvailability(tenantID string) ([]*tenant_models.AgentAvailability, error)
+	SaveAvailability(tenantID string, availability *tenant_models.AgentAvailability) error
+}
+
+type routingRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewRoutingRepository(tenantDBManager *database.TenantDatabaseManager) RoutingRepository {
+	return &routingRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *routingRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+// orderedMembers preloads group members in the order they joined
+func orderedMembers(db *gorm.DB) *gorm.DB {
+	return db.Order("added_at ASC")
+}
+
+// Group methods
+func (r *routingRepository) CreateGroup(tenantID string, group *tenant_models.AgentGroup) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(group).Error
+}
+
+func (r *routingRepository) GetGroup(tenantID, groupID string) (*tenant_models.AgentGroup, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var group tenant_models.AgentGroup
+	err = db.Preload("Members", orderedMembers).Where("id = ?", groupID).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *routingRepository) UpdateGroup(tenantID string, group *tenant_models.AgentGroup) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Omit("Members").Save(group).Error
+}
+
+// DeleteGroup removes the group and its members; its tickets stay where they are but
+// leave the group's queue
+func (r *routingRepository) DeleteGroup(tenantID, groupID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupID).Delete(&tenant_models.AgentGroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&tenant_models.Ticket{}).Where("group_id = ?", groupID).Update("group_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", groupID).Delete(&tenant_models.AgentGroup{}).Error
+	})
+}
+
+// ListGroups returns groups in matching order
+func (r *routingRepository) ListGroups(tenantID string, activeOnly bool) ([]*tenant_models.AgentGroup, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Model(&tenant_models.AgentGroup{}).Preload("Members", orderedMembers)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var groups []*tenant_models.AgentGroup
+	err = query.Order("sort_order ASC, created_at ASC").Find(&groups).Error
+	return groups, err
+}
+
+// Member methods
+func (r *routingRepository) AddMember(tenantID string, member *tenant_models.AgentGroupMember) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(member).Error
+}
+
+func (r *routingRepository) GetMember(tenantID, groupID, userID string) (*tenant_models.AgentGroupMember, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var member tenant_models.AgentGroupMember
+	err = db.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *routingRepository) UpdateMember(tenantID string, member *tenant_models.AgentGroupMember) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(member).Error
+}
+
+func (r *routingRepository) RemoveMember(tenantID, groupID, userID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	result := db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&tenant_models.AgentGroupMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkAssigned records that the member just received a ticket, moving them to the back
+// of the round-robin order
+func (r *routingRepository) MarkAssigned(tenantID, memberID string, at time.Time) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&tenant_models.AgentGroupMember{}).Where("id = ?", memberID).Update("last_assigned_at", at).Error
+}
+
+// Availability methods
+
+// GetAvailability returns the recorded availability of the given agents, keyed by user ID;
+// agents without a record are absent from the map
+func (r *routingRepository) GetAvailability(tenantID string, userIDs []string) (map[string]*tenant_models.AgentAvailability, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make(map[string]*tenant_models.AgentAvailability, len(userIDs))
+	if len(userIDs) == 0 {
+		return availability, nil
+	}
+
+	var records []*tenant_models.AgentAvailability
+	if err := db.Where("user_id IN ?", userIDs).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		availability[record.UserID] = record
+	}
+	return availability, nil
+}
+
+func (r *routingRepository) ListAvailability(tenantID string) ([]*tenant_models.AgentAvailability, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*tenant_models.AgentAvailability
+	err = db.Order("updated_at DESC").Find(&records).Error
+	return records, err
+}
+
+func (r *routingRepository) SaveAvailability(tenantID string, availability *tenant_models.AgentAvailability) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "max_open_tickets", "updated_at"}),
+	}).Create(availability).Error
+}
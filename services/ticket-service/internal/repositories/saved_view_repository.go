@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// SavedViewRepository stores named ticket views
+type SavedViewRepository interface {
+	Create(tenantID string, view *tenant_models.SavedView) error
+	Get(tenantID, viewID string) (*tenant_models.SavedView, error)
+	Update(tenantID string, view *tenant_models.SavedView) error
+	Delete(tenantID, viewID string) error
+	ListVisible(tenantID, userID string, projectIDs []string) ([]*tenant_models.SavedView, error)
+	CountOwned(tenantID, userID string) (int64, error)
+}
+
+type savedViewRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewSavedViewRepository(tenantDBManager *database.TenantDatabaseManager) SavedViewRepository {
+	return &savedViewRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *savedViewRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *savedViewRepository) Create(tenantID string, view *tenant_models.SavedView) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(view).Error
+}
+
+func (r *savedViewRepository) Get(tenantID, viewID string) (*tenant_models.SavedView, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var view tenant_models.SavedView
+	err = db.Where("id = ?", viewID).First(&view).Error
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+func (r *savedViewRepository) Update(tenantID string, view *tenant_models.SavedView) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(view).Error
+}
+
+func (r *savedViewRepository) Delete(tenantID, viewID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", viewID).Delete(&tenant_models.SavedView{}).Error
+}
+
+// ListVisible returns the user's personal views, tenant-wide views and team views of the
+// given projects; nil projectIDs includes every team view
+func (r *savedViewRepository) ListVisible(tenantID, userID string, projectIDs []string) ([]*tenant_models.SavedView, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	condition := "(visibility = ? AND owner_id = ?) OR visibility = ?"
+	vars := []interface{}{tenant_models.ViewPersonal, userID, tenant_models.ViewTenant}
+	if projectIDs == nil {
+		condition += " OR visibility = ?"
+		vars = append(vars, tenant_models.ViewTeam)
+	} else if len(projectIDs) > 0 {
+		condition += " OR (visibility = ? AND project_id IN ?)"
+		vars = append(vars, tenant_models.ViewTeam, projectIDs)
+	}
+
+	var views []*tenant_models.SavedView
+	err = db.Where(condition, vars...).Order("position ASC, name ASC").Find(&views).Error
+	return views, err
+}
+
+// CountOwned counts the views the user created, whatever they are shared with
+func (r *savedViewRepository) CountOwned(tenantID, userID string) (int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Model(&tenant_models.SavedView{}).Where("owner_id = ?", userID).Count(&count).Error
+	return count, err
+}
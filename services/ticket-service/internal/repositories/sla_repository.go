@@ -0,0 +1,268 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+type SLARepository interface {
+	// Policies
+	CreatePolicy(tenantID string, policy *tenant_models.SLAPolicy) error
+	GetPolicy(tenantID, policyID string) (*tenant_models.SLAPolicy, error)
+	UpdatePolicy(tenantID string, policy *tenant_models.SLAPolicy) error
+	DeletePolicy(tenantID, policyID string) error
+	ListPolicies(tenantID string, activeOnly bool) ([]*tenant_models.SLAPolicy, error)
+
+	// Business calendars
+	CreateCalendar(tenantID string, calendar *tenant_models.BusinessCalendar) error
+	GetCalendar(tenantID, calendarID string) (*tenant_models.BusinessCalendar, error)
+	UpdateCalendar(tenantID string, calendar *tenant_models.BusinessCalendar) error
+	DeleteCalendar(tenantID, calendarID string) error
+	ListCalendars(tenantID string) ([]*tenant_models.BusinessCalendar, error)
+	CreateHoliday(tenantID string, holiday *tenant_models.BusinessHoliday) error
+	DeleteHoliday(tenantID, calendarID, holidayID string) error
+
+	// Ticket SLA state
+	GetTicketSLA(tenantID, ticketID string) (*tenant_models.TicketSLA, error)
+	SaveTicketSLA(tenantID string, sla *tenant_models.TicketSLA) error
+	DeleteTicketSLA(tenantID, ticketID string) error
+	MarkBreaches(tenantID string, now time.Time) (int64, error)
+}
+
+type slaRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewSLARepository(tenantDBManager *database.TenantDatabaseManager) SLARepository {
+	return &slaRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *slaRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+// Policy methods
+func (r *slaRepository) CreatePolicy(tenantID string, policy *tenant_models.SLAPolicy) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(policy).Error
+}
+
+func (r *slaRepository) GetPolicy(tenantID, policyID string) (*tenant_models.SLAPolicy, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy tenant_models.SLAPolicy
+	err = db.Where("id = ?", policyID).First(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *slaRepository) UpdatePolicy(tenantID string, policy *tenant_models.SLAPolicy) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(policy).Error
+}
+
+func (r *slaRepository) DeletePolicy(tenantID, policyID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", policyID).Delete(&tenant_models.SLAPolicy{}).Error
+}
+
+func (r *slaRepository) ListPolicies(tenantID string, activeOnly bool) ([]*tenant_models.SLAPolicy, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Model(&tenant_models.SLAPolicy{})
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var policies []*tenant_models.SLAPolicy
+	err = query.Order("sort_order ASC, created_at ASC").Find(&policies).Error
+	return policies, err
+}
+
+// Calendar methods
+func (r *slaRepository) CreateCalendar(tenantID string, calendar *tenant_models.BusinessCalendar) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(calendar).Error
+}
+
+func (r *slaRepository) GetCalendar(tenantID, calendarID string) (*tenant_models.BusinessCalendar, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var calendar tenant_models.BusinessCalendar
+	err = db.Preload("Holidays", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date ASC")
+	}).Where("id = ?", calendarID).First(&calendar).Error
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+func (r *slaRepository) UpdateCalendar(tenantID string, calendar *tenant_models.BusinessCalendar) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Omit("Holidays").Save(calendar).Error
+}
+
+func (r *slaRepository) DeleteCalendar(tenantID, calendarID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	var inUse int64
+	db.Model(&tenant_models.SLAPolicy{}).Where("calendar_id = ?", calendarID).Count(&inUse)
+	if inUse > 0 {
+		return errors.New("calendar is used by one or more SLA policies")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("calendar_id = ?", calendarID).Delete(&tenant_models.BusinessHoliday{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", calendarID).Delete(&tenant_models.BusinessCalendar{}).Error
+	})
+}
+
+func (r *slaRepository) ListCalendars(tenantID string) ([]*tenant_models.BusinessCalendar, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var calendars []*tenant_models.BusinessCalendar
+	err = db.Preload("Holidays").Order("name ASC").Find(&calendars).Error
+	return calendars, err
+}
+
+func (r *slaRepository) CreateHoliday(tenantID string, holiday *tenant_models.BusinessHoliday) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(holiday).Error
+}
+
+func (r *slaRepository) DeleteHoliday(tenantID, calendarID, holidayID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ? AND calendar_id = ?", holidayID, calendarID).Delete(&tenant_models.BusinessHoliday{}).Error
+}
+
+// Ticket SLA methods
+func (r *slaRepository) GetTicketSLA(tenantID, ticketID string) (*tenant_models.TicketSLA, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sla tenant_models.TicketSLA
+	err = db.Where("ticket_id = ?", ticketID).First(&sla).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sla, nil
+}
+
+func (r *slaRepository) SaveTicketSLA(tenantID string, sla *tenant_models.TicketSLA) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(sla).Error
+}
+
+func (r *slaRepository) DeleteTicketSLA(tenantID, ticketID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("ticket_id = ?", ticketID).Delete(&tenant_models.TicketSLA{}).Error
+}
+
+// MarkBreaches records breaches for running SLA clocks whose targets have passed
+func (r *slaRepository) MarkBreaches(tenantID string, now time.Time) (int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var marked int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&tenant_models.TicketSLA{}).
+			Where("paused_at IS NULL AND first_responded_at IS NULL AND first_response_breached_at IS NULL").
+			Where("first_response_target_at < ?", now).
+			Updates(map[string]interface{}{
+				"first_response_breached_at": gorm.Expr("first_response_target_at"),
+				"status":                     tenant_models.SLAStatusBreached,
+				"updated_at":                 now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		marked += result.RowsAffected
+
+		result = tx.Model(&tenant_models.TicketSLA{}).
+			Where("paused_at IS NULL AND resolved_at IS NULL AND resolution_breached_at IS NULL").
+			Where("resolution_target_at < ?", now).
+			Updates(map[string]interface{}{
+				"resolution_breached_at": gorm.Expr("resolution_target_at"),
+				"status":                 tenant_models.SLAStatusBreached,
+				"updated_at":             now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		marked += result.RowsAffected
+		return nil
+	})
+
+	return marked, err
+}
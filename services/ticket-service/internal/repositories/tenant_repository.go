@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"github.com/zen/shared/pkg/models"
+	"gorm.io/gorm"
+)
+
+// TenantRepository reads tenant records from the master database for work that happens
+// without a tenant context, such as inbound email and scheduled automations
+type TenantRepository interface {
+	GetByID(tenantID string) (*models.Tenant, error)
+	GetBySubdomain(subdomain string) (*models.Tenant, error)
+	ListActive() ([]*models.Tenant, error)
+}
+
+type tenantRepository struct {
+	masterDB *gorm.DB
+}
+
+func NewTenantRepository(masterDB *gorm.DB) TenantRepository {
+	return &tenantRepository{
+		masterDB: masterDB,
+	}
+}
+
+func (r *tenantRepository) GetByID(tenantID string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.masterDB.Where("id = ?", tenantID).First(&tenant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// GetBySubdomain matches case-insensitively, as mail domains are
+func (r *tenantRepository) GetBySubdomain(subdomain string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.masterDB.Where("LOWER(subdomain) = LOWER(?)", subdomain).First(&tenant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) ListActive() ([]*models.Tenant, error) {
+	var tenants []*models.Tenant
+	err := r.masterDB.Where("status = ?", models.TenantStatusActive).Find(&tenants).Error
+	return tenants, err
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// TicketLinkRepository stores typed relationships between tickets. Subtask links also
+// maintain the child's parent_ticket_id in the same transaction.
+type TicketLinkRepository interface {
+	CreateLink(tenantID string, link *tenant_models.TicketLink, history ...*tenant_models.TicketHistory) error
+	GetLink(tenantID, linkID string) (*tenant_models.TicketLink, error)
+	DeleteLink(tenantID string, link *tenant_models.TicketLink, history ...*tenant_models.TicketHistory) error
+	ListLinks(tenantID, ticketID string) ([]*tenant_models.TicketLink, error)
+	FindLink(tenantID, ticketA, ticketB string) (*tenant_models.TicketLink, error)
+	CountLinks(tenantID, sourceTicketID string, linkType tenant_models.TicketLinkType) (int64, error)
+	Reaches(tenantID, fromTicketID, toTicketID string, linkType tenant_models.TicketLinkType) (bool, error)
+	ListChildren(tenantID, parentTicketID string) ([]*tenant_models.Ticket, error)
+}
+
+type ticketLinkRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewTicketLinkRepository(tenantDBManager *database.TenantDatabaseManager) TicketLinkRepository {
+	return &ticketLinkRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *ticketLinkRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+func (r *ticketLinkRepository) CreateLink(tenantID string, link *tenant_models.TicketLink, history ...*tenant_models.TicketHistory) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(link).Error; err != nil {
+			return err
+		}
+		if link.LinkType == tenant_models.LinkTypeSubtask {
+			err := tx.Model(&tenant_models.Ticket{}).Where("id = ?", link.TargetTicketID).
+				Update("parent_ticket_id", link.SourceTicketID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return createHistory(tx, history)
+	})
+}
+
+func (r *ticketLinkRepository) GetLink(tenantID, linkID string) (*tenant_models.TicketLink, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var link tenant_models.TicketLink
+	err = db.Preload("SourceTicket").Preload("TargetTicket").Where("id = ?", linkID).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ticketLinkRepository) DeleteLink(tenantID string, link *tenant_models.TicketLink, history ...*tenant_models.TicketHistory) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", link.ID).Delete(&tenant_models.TicketLink{}).Error; err != nil {
+			return err
+		}
+		if link.LinkType == tenant_models.LinkTypeSubtask {
+			err := tx.Model(&tenant_models.Ticket{}).
+				Where("id = ? AND parent_ticket_id = ?", link.TargetTicketID, link.SourceTicketID).
+				Update("parent_ticket_id", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+		return createHistory(tx, history)
+	})
+}
+
+// ListLinks returns every link the ticket is on either side of, with both tickets loaded
+func (r *ticketLinkRepository) ListLinks(tenantID, ticketID string) ([]*tenant_models.TicketLink, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []*tenant_models.TicketLink
+	err = db.Preload("SourceTicket").Preload("TargetTicket").
+		Where("source_ticket_id = ? OR target_ticket_id = ?", ticketID, ticketID).
+		Order("link_type ASC, created_at ASC").
+		Find(&links).Error
+	return links, err
+}
+
+// FindLink returns any link between the two tickets, in either direction
+func (r *ticketLinkRepository) FindLink(tenantID, ticketA, ticketB string) (*tenant_models.TicketLink, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var link tenant_models.TicketLink
+	err = db.Where("(source_ticket_id = ? AND target_ticket_id = ?) OR (source_ticket_id = ? AND target_ticket_id = ?)",
+		ticketA, ticketB, ticketB, ticketA).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ticketLinkRepository) CountLinks(tenantID, sourceTicketID string, linkType tenant_models.TicketLinkType) (int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Model(&tenant_models.TicketLink{}).
+		Where("source_ticket_id = ? AND link_type = ?", sourceTicketID, linkType).
+		Count(&count).Error
+	return count, err
+}
+
+// Reaches reports whether toTicketID can be reached from fromTicketID by following links
+// of one type from source to target; adding the reverse edge would then close a cycle
+func (r *ticketLinkRepository) Reaches(tenantID, fromTicketID, toTicketID string, linkType tenant_models.TicketLinkType) (bool, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	// UNION rather than UNION ALL stops the walk on any cycle already stored
+	var reaches bool
+	err = db.Raw(`WITH RECURSIVE chain(id) AS (
+		SELECT target_ticket_id FROM ticket_links WHERE source_ticket_id = ? AND link_type = ?
+		UNION
+		SELECT l.target_ticket_id FROM ticket_links l JOIN chain c ON l.source_ticket_id = c.id WHERE l.link_type = ?
+	)
+	SELECT EXISTS (SELECT 1 FROM chain WHERE id = ?)`, fromTicketID, linkType, linkType, toTicketID).
+		Scan(&reaches).Error
+	return reaches, err
+}
+
+func (r *ticketLinkRepository) ListChildren(tenantID, parentTicketID string) ([]*tenant_models.Ticket, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*tenant_models.Ticket
+	err = db.Where("parent_ticket_id = ?", parentTicketID).Order("ticket_number ASC").Find(&children).Error
+	return children, err
+}
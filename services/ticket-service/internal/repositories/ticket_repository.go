@@ -1,42 +1,70 @@
 package repositories
 
 import (
+	"encoding/json"
 	"fmt"
+	"html"
+	"strings"
 	"time"
 
 	"github.com/zen/shared/pkg/database"
-	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TicketRepository interface {
-	// Ticket CRUD
-	Create(tenantID string, ticket *models.Ticket) error
-	GetByID(tenantID, ticketID string) (*models.Ticket, error)
-	Update(tenantID string, ticket *models.Ticket) error
-	Delete(tenantID, ticketID string) error
-	List(tenantID string, limit, offset int, filters TicketFilters) ([]*models.Ticket, int64, error)
+	// Ticket CRUD; history entries are written in the same transaction as the change
+	Create(tenantID string, ticket *tenant_models.Ticket, history ...*tenant_models.TicketHistory) error
+	GetByID(tenantID, ticketID string) (*tenant_models.Ticket, error)
+	GetByNumber(tenantID string, ticketNumber int) (*tenant_models.Ticket, error)
+	Update(tenantID string, ticket *tenant_models.Ticket, history ...*tenant_models.TicketHistory) error
+	Delete(tenantID, ticketID string, history ...*tenant_models.TicketHistory) error
+	List(tenantID string, limit, offset int, filters TicketFilters) ([]*tenant_models.Ticket, int64, error)
+	ListIDs(tenantID string, filters TicketFilters, limit int) ([]string, error)
+	
+	// Scheduled automations walk open tickets in ID order
+	ListOpenAfter(tenantID, afterID string, limit int) ([]*tenant_models.Ticket, error)
+	GetActivity(tenantID string, ticketIDs []string) (map[string]*TicketActivity, error)
+	
+	// Routing looks at each agent's unresolved tickets
+	CountOpenByAssignees(tenantID string, assigneeIDs []string) (map[string]int64, error)
+	ListOpenByAssignee(tenantID, assigneeID string) ([]*tenant_models.Ticket, error)
 	
 	// Ticket search and filtering
-	Search(tenantID, query string, limit, offset int) ([]*models.Ticket, int64, error)
-	GetByStatus(tenantID string, status models.TicketStatus, limit, offset int) ([]*models.Ticket, error)
-	GetByAssignee(tenantID, assigneeID string, limit, offset int) ([]*models.Ticket, error)
-	GetByReporter(tenantID, reporterID string, limit, offset int) ([]*models.Ticket, error)
-	GetByProject(tenantID, projectID string, limit, offset int) ([]*models.Ticket, error)
+	Search(tenantID string, search TicketSearch, limit, offset int) ([]*TicketSearchHit, int64, error)
+	CountSearch(tenantID string, search TicketSearch) (int64, error)
+	GetByStatus(tenantID string, status tenant_models.TicketStatus, limit, offset int) ([]*tenant_models.Ticket, error)
+	GetByAssignee(tenantID, assigneeID string, limit, offset int) ([]*tenant_models.Ticket, error)
+	GetByReporter(tenantID, reporterID string, limit, offset int) ([]*tenant_models.Ticket, error)
+	GetByProject(tenantID, projectID string, limit, offset int) ([]*tenant_models.Ticket, error)
+	
+	// Exports walk the filtered tickets in ticket number order, a batch at a time
+	ListForExport(tenantID string, filters TicketFilters, afterNumber, limit int) ([]*tenant_models.Ticket, error)
+	CustomFieldKeys(tenantID string, filters TicketFilters) ([]string, error)
+	GetCommentsForTickets(tenantID string, ticketIDs []string, includeInternal bool) ([]*tenant_models.Comment, error)
+	
+	// The customer portal lists a requester's tickets by their address
+	ListByCustomerEmail(tenantID, email string, limit, offset int) ([]*tenant_models.Ticket, int64, error)
 	
 	// Comments
-	CreateComment(tenantID string, comment *models.TicketComment) error
-	GetComments(tenantID, ticketID string, includeInternal bool) ([]*models.TicketComment, error)
-	UpdateComment(tenantID string, comment *models.TicketComment) error
+	CreateComment(tenantID string, comment *tenant_models.Comment, history ...*tenant_models.TicketHistory) error
+	GetComments(tenantID, ticketID string, includeInternal bool) ([]*tenant_models.Comment, error)
+	UpdateComment(tenantID string, comment *tenant_models.Comment) error
 	DeleteComment(tenantID, commentID string) error
 	
 	// Attachments
-	CreateAttachment(tenantID string, attachment *models.TicketAttachment) error
-	GetAttachments(tenantID, ticketID string) ([]*models.TicketAttachment, error)
+	CreateAttachment(tenantID string, attachment *tenant_models.Attachment) error
+	GetAttachments(tenantID, ticketID string) ([]*tenant_models.Attachment, error)
+	GetAttachment(tenantID, attachmentID string) (*tenant_models.Attachment, error)
+	GetCommentAttachments(tenantID string, commentIDs []string) ([]*tenant_models.Attachment, error)
 	DeleteAttachment(tenantID, attachmentID string) error
 	
+	// Audit trail
+	GetHistory(tenantID, ticketID string) ([]*tenant_models.TicketHistory, error)
+	
 	// Statistics
-	GetTicketStats(tenantID string, dateFrom, dateTo *time.Time) (TicketStats, error)
+	GetTicketStats(tenantID string, filters TicketStatsFilters) (TicketStats, error)
 }
 
 type TicketFilters struct {
@@ -46,24 +74,137 @@ type TicketFilters struct {
 	AssigneeID string
 	ReporterID string
 	ProjectID  string
+	GroupID    string
 	Category   string
 	Tags       []string
 	DateFrom   *time.Time
 	DateTo     *time.Time
+
+	// SLA filters
+	BreachingWithin *time.Duration // Running SLA targets due within this window
+	SLABreached     bool           // Only tickets with a breached SLA target
+
+	CustomFields []CustomFieldFilter
+
+	// Scope limits results to tickets the caller may see; nil means unrestricted
+	Scope *TicketAccessScope
+
+	// Sort overrides the default order of newest first
+	Sort *TicketSort
+}
+
+// CustomFieldFilter restricts tickets on one custom field. Values are typed as the
+// field stores them and match by JSON containment, which the custom_fields GIN index
+// serves; a multi-select value matches when it holds any of them.
+type CustomFieldFilter struct {
+	Key    string
+	Values []interface{}
+	Array  bool        // The field holds a list of values
+	From   interface{} // Inclusive range bounds for number and date fields
+	To     interface{}
+	IsNull bool // Also match tickets without a value
+	Negate bool
+}
+
+// TicketActivity holds when a ticket last saw each kind of activity
+type TicketActivity struct {
+	TicketID          string
+	LastActivityAt    time.Time  // Latest change or comment
+	StatusChangedAt   *time.Time // Nil when the status never changed
+	CustomerRepliedAt *time.Time // Latest public comment by the reporter or the customer's address
+	AgentRepliedAt    *time.Time // Latest public comment by anyone else, automations excepted
+}
+
+// TicketAccessScope describes which tickets a non-admin user can see
+type TicketAccessScope struct {
+	UserID             string   // Reporter or assignee always sees the ticket
+	ProjectIDs         []string // Projects the user belongs to
+	LeadProjectIDs     []string // Projects where the user may also see restricted tickets
+	IncludeUnprojected bool     // Tickets outside any project (the shared support queue)
+}
+
+// TicketSearch is a parsed full-text search over tickets
+type TicketSearch struct {
+	// Text uses websearch syntax: plain words, "quoted phrases" and -excluded words
+	Text         string
+	Conditions   []SearchCondition
+	DateRanges   []DateRange
+	CustomFields []CustomFieldFilter
+	Scope        *TicketAccessScope
+
+	// Sort overrides the default order of relevance, or recency without search text
+	Sort *TicketSort
+}
+
+// DateRange restricts a search to tickets whose date field falls in [From, To)
+type DateRange struct {
+	Field  string // One of DateFields
+	From   *time.Time
+	To     *time.Time
+	IsNull bool // Match tickets without the date instead
+	Negate bool
+}
+
+// TicketSort orders tickets by one of SortFields, or by a custom field given as
+// custom_fields.<key>
+type TicketSort struct {
+	Field      string
+	Descending bool
+}
+
+// SearchCondition restricts a search to tickets whose field matches any of the values
+type SearchCondition struct {
+	Field  string // One of SearchFields
+	Values []string
+	Negate bool
+}
+
+// SearchFields maps search operators onto ticket columns
+var SearchFields = map[string]string{
+	"status":   "status",
+	"priority": "priority",
+	"type":     "ticket_type",
+	"category": "category",
+	"channel":  "channel",
+	"assignee": "assignee_id",
+	"reporter": "reporter_id",
+	"project":  "project_id",
+	"label":    "labels",
 }
 
-type TicketStats struct {
-	TotalTickets       int64            `json:"total_tickets"`
-	OpenTickets        int64            `json:"open_tickets"`
-	InProgressTickets  int64            `json:"in_progress_tickets"`
-	ResolvedTickets    int64            `json:"resolved_tickets"`
-	ClosedTickets      int64            `json:"closed_tickets"`
-	ByStatus           map[string]int64 `json:"by_status"`
-	ByPriority         map[string]int64 `json:"by_priority"`
-	ByType             map[string]int64 `json:"by_type"`
-	AverageResolutionTime time.Duration `json:"average_resolution_time"`
+// DateFields maps date search operators onto ticket columns
+var DateFields = map[string]string{
+	"created":  "created_at",
+	"updated":  "updated_at",
+	"resolved": "resolved_at",
+	"due":      "due_date",
 }
 
+// SortFields maps sort keys onto ticket order expressions
+var SortFields = map[string]string{
+	"created":  "created_at",
+	"updated":  "updated_at",
+	"due":      "due_date",
+	"number":   "ticket_number",
+	"title":    "title",
+	"status":   "status",
+	"priority": "CASE priority WHEN 'blocker' THEN 5 WHEN 'critical' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END",
+}
+
+// TicketSearchHit is a matched ticket with its relevance and highlighted fragments
+type TicketSearchHit struct {
+	Ticket         *tenant_models.Ticket
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+// Highlight delimiters; ts_headline output is escaped before they become <mark> tags
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
 type ticketRepository struct {
 	tenantDBManager *database.TenantDatabaseManager
 }
@@ -78,57 +219,231 @@ func (r *ticketRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
 	return r.tenantDBManager.GetTenantDB(tenantID)
 }
 
-func (r *ticketRepository) Create(tenantID string, ticket *models.Ticket) error {
+func (r *ticketRepository) Create(tenantID string, ticket *tenant_models.Ticket, history ...*tenant_models.TicketHistory) error {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	ticket.TenantID = tenantID
-	return db.Create(ticket).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ticket).Error; err != nil {
+			return err
+		}
+		// The ticket ID is only known once the row exists
+		for _, entry := range history {
+			entry.TicketID = ticket.ID
+		}
+		return createHistory(tx, history)
+	})
+}
+
+func (r *ticketRepository) GetByID(tenantID, ticketID string) (*tenant_models.Ticket, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	var ticket tenant_models.Ticket
+	err = db.Where("id = ?", ticketID).First(&ticket).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
 }
 
-func (r *ticketRepository) GetByID(tenantID, ticketID string) (*models.Ticket, error) {
+func (r *ticketRepository) GetByNumber(tenantID string, ticketNumber int) (*tenant_models.Ticket, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	var ticket models.Ticket
-	err = db.Where("id = ? AND tenant_id = ?", ticketID, tenantID).First(&ticket).Error
+	var ticket tenant_models.Ticket
+	err = db.Where("ticket_number = ?", ticketNumber).First(&ticket).Error
 	if err != nil {
 		return nil, err
 	}
 	return &ticket, nil
 }
 
-func (r *ticketRepository) Update(tenantID string, ticket *models.Ticket) error {
+func (r *ticketRepository) Update(tenantID string, ticket *tenant_models.Ticket, history ...*tenant_models.TicketHistory) error {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	return db.Where("tenant_id = ?", tenantID).Save(ticket).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(ticket).Error; err != nil {
+			return err
+		}
+		return createHistory(tx, history)
+	})
 }
 
-func (r *ticketRepository) Delete(tenantID, ticketID string) error {
+func (r *ticketRepository) Delete(tenantID, ticketID string, history ...*tenant_models.TicketHistory) error {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	return db.Where("id = ? AND tenant_id = ?", ticketID, tenantID).Delete(&models.Ticket{}).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", ticketID).Delete(&tenant_models.Ticket{}).Error; err != nil {
+			return err
+		}
+		return createHistory(tx, history)
+	})
 }
 
-func (r *ticketRepository) List(tenantID string, limit, offset int, filters TicketFilters) ([]*models.Ticket, int64, error) {
+func (r *ticketRepository) List(tenantID string, limit, offset int, filters TicketFilters) ([]*tenant_models.Ticket, int64, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	query := db.Where("tenant_id = ?", tenantID)
+	query := applyTicketFilters(db, db.Model(&tenant_models.Ticket{}), filters)
+	
+	// Get total count
+	var total int64
+	countQuery := query
+	err = countQuery.Model(&tenant_models.Ticket{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	// Get tickets
+	if filters.Sort != nil {
+		query = query.Order(sortClause(*filters.Sort))
+	} else {
+		query = query.Order("created_at DESC")
+	}
+	var tickets []*tenant_models.Ticket
+	err = query.Limit(limit).Offset(offset).Find(&tickets).Error
+	
+	return tickets, total, err
+}
+
+// ListIDs returns the IDs of up to limit tickets matching the filters, oldest first
+func (r *ticketRepository) ListIDs(tenantID string, filters TicketFilters, limit int) ([]string, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	var ids []string
+	err = applyTicketFilters(db, db.Model(&tenant_models.Ticket{}), filters).
+		Order("created_at ASC").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ListOpenAfter returns up to limit tickets that are not closed, with IDs after afterID
+func (r *ticketRepository) ListOpenAfter(tenantID, afterID string, limit int) ([]*tenant_models.Ticket, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	query := db.Where("closed_at IS NULL")
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	
+	var tickets []*tenant_models.Ticket
+	err = query.Order("id ASC").Limit(limit).Find(&tickets).Error
+	return tickets, err
+}
+
+// CountOpenByAssignees counts each agent's unresolved tickets, keyed by user ID
+func (r *ticketRepository) CountOpenByAssignees(tenantID string, assigneeIDs []string) (map[string]int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	counts := make(map[string]int64, len(assigneeIDs))
+	if len(assigneeIDs) == 0 {
+		return counts, nil
+	}
+	
+	var rows []struct {
+		AssigneeID string
+		Count      int64
+	}
+	err = db.Model(&tenant_models.Ticket{}).
+		Select("assignee_id, COUNT(*) AS count").
+		Where("assignee_id IN ? AND resolved_at IS NULL AND closed_at IS NULL", assigneeIDs).
+		Group("assignee_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	
+	for _, row := range rows {
+		counts[row.AssigneeID] = row.Count
+	}
+	return counts, nil
+}
+
+// ListOpenByAssignee returns every unresolved ticket assigned to the agent
+func (r *ticketRepository) ListOpenByAssignee(tenantID, assigneeID string) ([]*tenant_models.Ticket, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	var tickets []*tenant_models.Ticket
+	err = db.Where("assignee_id = ? AND resolved_at IS NULL AND closed_at IS NULL", assigneeID).
+		Order("created_at ASC").
+		Find(&tickets).Error
+	return tickets, err
+}
+
+// GetActivity reports the latest activity of each ticket, keyed by ticket ID
+func (r *ticketRepository) GetActivity(tenantID string, ticketIDs []string) (map[string]*TicketActivity, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	activity := make(map[string]*TicketActivity, len(ticketIDs))
+	if len(ticketIDs) == 0 {
+		return activity, nil
+	}
+	
+	// A comment is the customer's when it comes from the reporter or the customer's address
+	const byCustomer = `(c.author_id = t.reporter_id OR (t.customer_email <> '' AND LOWER(COALESCE(c.author_email, '')) = LOWER(t.customer_email)))`
+	var rows []*TicketActivity
+	err = db.Raw(`
+		SELECT t.id AS ticket_id,
+		       GREATEST(t.updated_at, (
+		           SELECT MAX(c.created_at) FROM comments c
+		           WHERE c.ticket_id = t.id AND c.deleted_at IS NULL
+		       )) AS last_activity_at,
+		       (
+		           SELECT MAX(h.changed_at) FROM ticket_history h
+		           WHERE h.ticket_id = t.id AND h.field_name = 'status'
+		       ) AS status_changed_at,
+		       (
+		           SELECT MAX(c.created_at) FROM comments c
+		           WHERE c.ticket_id = t.id AND c.deleted_at IS NULL AND NOT c.is_internal AND `+byCustomer+`
+		       ) AS customer_replied_at,
+		       (
+		           SELECT MAX(c.created_at) FROM comments c
+		           WHERE c.ticket_id = t.id AND c.deleted_at IS NULL AND NOT c.is_internal
+		             AND c.author_id <> ? AND NOT `+byCustomer+`
+		       ) AS agent_replied_at
+		FROM tickets t
+		WHERE t.id IN ?`, tenant_models.SystemUserID, ticketIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
 	
-	// Apply filters
+	for _, row := range rows {
+		activity[row.TicketID] = row
+	}
+	return activity, nil
+}
+
+// applyTicketFilters restricts a ticket query to the filters and the caller's access scope
+func applyTicketFilters(db *gorm.DB, query *gorm.DB, filters TicketFilters) *gorm.DB {
 	if filters.Status != "" {
 		query = query.Where("status = ?", filters.Status)
 	}
@@ -136,7 +451,7 @@ func (r *ticketRepository) List(tenantID string, limit, offset int, filters Tick
 		query = query.Where("priority = ?", filters.Priority)
 	}
 	if filters.Type != "" {
-		query = query.Where("type = ?", filters.Type)
+		query = query.Where("ticket_type = ?", filters.Type)
 	}
 	if filters.AssigneeID != "" {
 		query = query.Where("assignee_id = ?", filters.AssigneeID)
@@ -147,6 +462,9 @@ func (r *ticketRepository) List(tenantID string, limit, offset int, filters Tick
 	if filters.ProjectID != "" {
 		query = query.Where("project_id = ?", filters.ProjectID)
 	}
+	if filters.GroupID != "" {
+		query = query.Where("group_id = ?", filters.GroupID)
+	}
 	if filters.Category != "" {
 		query = query.Where("category = ?", filters.Category)
 	}
@@ -156,106 +474,399 @@ func (r *ticketRepository) List(tenantID string, limit, offset int, filters Tick
 	if filters.DateTo != nil {
 		query = query.Where("created_at <= ?", filters.DateTo)
 	}
+	if filters.BreachingWithin != nil {
+		deadline := time.Now().Add(*filters.BreachingWithin)
+		breaching := db.Model(&tenant_models.TicketSLA{}).Select("ticket_id").
+			Where("paused_at IS NULL").
+			Where("(first_responded_at IS NULL AND first_response_breached_at IS NULL AND first_response_target_at <= ?) OR "+
+				"(resolved_at IS NULL AND resolution_breached_at IS NULL AND resolution_target_at <= ?)", deadline, deadline)
+		query = query.Where("id IN (?)", breaching)
+	}
+	if filters.SLABreached {
+		breached := db.Model(&tenant_models.TicketSLA{}).Select("ticket_id").
+			Where("first_response_breached_at IS NOT NULL OR resolution_breached_at IS NOT NULL")
+		query = query.Where("id IN (?)", breached)
+	}
+	for _, filter := range filters.CustomFields {
+		query = applyCustomFieldFilter(query, filter)
+	}
+	return applyAccessScope(query, filters.Scope)
+}
+
+// applyCustomFieldFilter adds a custom field condition. A negated filter keeps tickets
+// without a value unless IsNull is set.
+func applyCustomFieldFilter(query *gorm.DB, filter CustomFieldFilter) *gorm.DB {
+	if !ValidCustomFieldKey(filter.Key) {
+		query.AddError(fmt.Errorf("invalid custom field key %q", filter.Key))
+		return query
+	}
+	field := customFieldExpr(filter.Key)
+
+	var conditions []string
+	var vars []interface{}
+	if filter.IsNull {
+		conditions = append(conditions, "coalesce("+field+", 'null') = 'null'")
+	}
+	for _, value := range filter.Values {
+		if filter.Array {
+			value = []interface{}{value}
+		}
+		contained, err := json.Marshal(map[string]interface{}{filter.Key: value})
+		if err != nil {
+			query.AddError(err)
+			return query
+		}
+		conditions = append(conditions, "custom_fields @> ?")
+		vars = append(vars, string(contained))
+	}
+	if filter.From != nil || filter.To != nil {
+		// jsonb orders values of different types by type first, so pin the type
+		bound := filter.From
+		if bound == nil {
+			bound = filter.To
+		}
+		valueType := "string"
+		if _, isNumber := bound.(float64); isNumber {
+			valueType = "number"
+		}
+		bounds := []string{"jsonb_typeof(" + field + ") = ?"}
+		vars = append(vars, valueType)
+		for _, limit := range []struct {
+			op    string
+			value interface{}
+		}{{">=", filter.From}, {"<=", filter.To}} {
+			if limit.value == nil {
+				continue
+			}
+			encoded, err := json.Marshal(limit.value)
+			if err != nil {
+				query.AddError(err)
+				return query
+			}
+			bounds = append(bounds, field+" "+limit.op+" CAST(? AS jsonb)")
+			vars = append(vars, string(encoded))
+		}
+		conditions = append(conditions, "("+strings.Join(bounds, " AND ")+")")
+	}
+	if len(conditions) == 0 {
+		return query
+	}
+
+	condition := strings.Join(conditions, " OR ")
+	if filter.Negate {
+		return query.Where("NOT coalesce(("+condition+"), false)", vars...)
+	}
+	return query.Where("("+condition+")", vars...)
+}
+
+// sortClause orders by the sort field, then newest first; tickets without a value go last
+func sortClause(sort TicketSort) clause.OrderBy {
+	expression, ok := SortFields[sort.Field]
+	if key := strings.TrimPrefix(sort.Field, "custom_fields."); !ok && key != sort.Field && ValidCustomFieldKey(key) {
+		expression = customFieldExpr(key)
+	} else if !ok {
+		expression = "created_at"
+	}
+
+	direction := " ASC NULLS LAST"
+	if sort.Descending {
+		direction = " DESC NULLS LAST"
+	}
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                expression + direction + ", created_at DESC",
+		WithoutParentheses: true,
+	}}
+}
+
+// Search ranks tickets by full-text relevance; without search text it filters and sorts by recency
+func (r *ticketRepository) Search(tenantID string, search TicketSearch, limit, offset int) ([]*TicketSearchHit, int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	tsQuery := fmt.Sprintf("websearch_to_tsquery('%s', ?)", database.TicketSearchConfig)
+	searchQuery := applySearch(db, search)
 	
-	// Get total count
 	var total int64
-	countQuery := query
-	err = countQuery.Model(&models.Ticket{}).Count(&total).Error
+	err = searchQuery.Model(&tenant_models.Ticket{}).Count(&total).Error
 	if err != nil {
 		return nil, 0, err
 	}
 	
-	// Get tickets
-	var tickets []*models.Ticket
-	err = query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&tickets).Error
+	if search.Sort != nil {
+		searchQuery = searchQuery.Order(sortClause(*search.Sort))
+	} else if search.Text != "" {
+		// A single expression, since gorm drops an ORDER BY expression when columns are merged in
+		searchQuery = searchQuery.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank_cd(search_vector, " + tsQuery + ") DESC, created_at DESC",
+			Vars:               []interface{}{search.Text},
+			WithoutParentheses: true,
+		}})
+	} else {
+		searchQuery = searchQuery.Order("created_at DESC")
+	}
 	
-	return tickets, total, err
+	var tickets []*tenant_models.Ticket
+	err = searchQuery.Limit(limit).Offset(offset).Find(&tickets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	hits := make([]*TicketSearchHit, 0, len(tickets))
+	for _, ticket := range tickets {
+		hits = append(hits, &TicketSearchHit{Ticket: ticket})
+	}
+	if search.Text == "" || len(tickets) == 0 {
+		return hits, total, nil
+	}
+	
+	// Headlines are costly, so they are only built for the page being returned
+	ids := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+	
+	var highlights []struct {
+		ID             string
+		Rank           float64
+		TitleHighlight string
+		Snippet        string
+	}
+	options := fmt.Sprintf("StartSel=%s, StopSel=%s", highlightStart, highlightStop)
+	err = db.Raw(fmt.Sprintf(`
+		SELECT t.id,
+		       ts_rank_cd(t.search_vector, q.query) AS rank,
+		       ts_headline('%[1]s', t.title, q.query, 'HighlightAll=true, %[2]s') AS title_highlight,
+		       ts_headline('%[1]s', concat_ws(' ', t.description, (
+		           SELECT string_agg(c.body, ' ' ORDER BY c.created_at)
+		           FROM comments c
+		           WHERE c.ticket_id = t.id AND NOT c.is_internal AND c.deleted_at IS NULL
+		       )), q.query, 'MaxFragments=2, MaxWords=30, MinWords=10, %[2]s') AS snippet
+		FROM tickets t, websearch_to_tsquery('%[1]s', ?) AS q(query)
+		WHERE t.id IN ?`, database.TicketSearchConfig, options), search.Text, ids).Scan(&highlights).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build search highlights: %w", err)
+	}
+	
+	byID := make(map[string]int, len(hits))
+	for i, hit := range hits {
+		byID[hit.Ticket.ID] = i
+	}
+	for _, highlight := range highlights {
+		if i, ok := byID[highlight.ID]; ok {
+			hits[i].Rank = highlight.Rank
+			hits[i].TitleHighlight = markHighlights(highlight.TitleHighlight)
+			hits[i].Snippet = markHighlights(highlight.Snippet)
+		}
+	}
+	
+	return hits, total, nil
 }
 
-func (r *ticketRepository) Search(tenantID, query string, limit, offset int) ([]*models.Ticket, int64, error) {
+// CountSearch counts the tickets a search matches without loading them
+func (r *ticketRepository) CountSearch(tenantID string, search TicketSearch) (int64, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get tenant database: %w", err)
+		return 0, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	searchQuery := db.Where("tenant_id = ?", tenantID).
-		Where("title ILIKE ? OR description ILIKE ?", "%"+query+"%", "%"+query+"%")
-	
 	var total int64
-	err = searchQuery.Model(&models.Ticket{}).Count(&total).Error
+	err = applySearch(db, search).Count(&total).Error
+	return total, err
+}
+
+// applySearch builds the filtered ticket query shared by Search and CountSearch
+func applySearch(db *gorm.DB, search TicketSearch) *gorm.DB {
+	query := db.Model(&tenant_models.Ticket{})
+	if search.Text != "" {
+		query = query.Where(fmt.Sprintf("search_vector @@ websearch_to_tsquery('%s', ?)", database.TicketSearchConfig), search.Text)
+	}
+	for _, condition := range search.Conditions {
+		query = applySearchCondition(query, condition)
+	}
+	for _, dateRange := range search.DateRanges {
+		query = applyDateRange(query, dateRange)
+	}
+	for _, filter := range search.CustomFields {
+		query = applyCustomFieldFilter(query, filter)
+	}
+	return applyAccessScope(query, search.Scope)
+}
+
+// applyDateRange adds a date operator; a negated range still excludes tickets without the date
+func applyDateRange(query *gorm.DB, dateRange DateRange) *gorm.DB {
+	column := DateFields[dateRange.Field]
+	if dateRange.IsNull {
+		if dateRange.Negate {
+			return query.Where(column + " IS NOT NULL")
+		}
+		return query.Where(column + " IS NULL")
+	}
+
+	var bounds []string
+	var vars []interface{}
+	if dateRange.From != nil {
+		bounds = append(bounds, column+" >= ?")
+		vars = append(vars, *dateRange.From)
+	}
+	if dateRange.To != nil {
+		bounds = append(bounds, column+" < ?")
+		vars = append(vars, *dateRange.To)
+	}
+	if len(bounds) == 0 {
+		return query
+	}
+	condition := strings.Join(bounds, " AND ")
+	if dateRange.Negate {
+		condition = column + " IS NOT NULL AND NOT (" + condition + ")"
+	}
+	return query.Where(condition, vars...)
+}
+
+func (r *ticketRepository) GetByStatus(tenantID string, status tenant_models.TicketStatus, limit, offset int) ([]*tenant_models.Ticket, error) {
+	db, err := r.getTenantDB(tenantID)
 	if err != nil {
-		return nil, 0, err
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	var tickets []*models.Ticket
-	err = searchQuery.Order("created_at DESC").Limit(limit).Offset(offset).Find(&tickets).Error
+	var tickets []*tenant_models.Ticket
+	err = db.Where("status = ?", status).
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&tickets).Error
 	
-	return tickets, total, err
+	return tickets, err
 }
 
-func (r *ticketRepository) GetByStatus(tenantID string, status models.TicketStatus, limit, offset int) ([]*models.Ticket, error) {
+func (r *ticketRepository) GetByAssignee(tenantID, assigneeID string, limit, offset int) ([]*tenant_models.Ticket, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	var tickets []*models.Ticket
-	err = db.Where("tenant_id = ? AND status = ?", tenantID, status).
+	var tickets []*tenant_models.Ticket
+	err = db.Where("assignee_id = ?", assigneeID).
 		Order("created_at DESC").Limit(limit).Offset(offset).Find(&tickets).Error
 	
 	return tickets, err
 }
 
-func (r *ticketRepository) GetByAssignee(tenantID, assigneeID string, limit, offset int) ([]*models.Ticket, error) {
+func (r *ticketRepository) GetByReporter(tenantID, reporterID string, limit, offset int) ([]*tenant_models.Ticket, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	var tickets []*models.Ticket
-	err = db.Where("tenant_id = ? AND assignee_id = ?", tenantID, assigneeID).
+	var tickets []*tenant_models.Ticket
+	err = db.Where("reporter_id = ?", reporterID).
 		Order("created_at DESC").Limit(limit).Offset(offset).Find(&tickets).Error
 	
 	return tickets, err
 }
 
-func (r *ticketRepository) GetByReporter(tenantID, reporterID string, limit, offset int) ([]*models.Ticket, error) {
+func (r *ticketRepository) GetByProject(tenantID, projectID string, limit, offset int) ([]*tenant_models.Ticket, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	var tickets []*models.Ticket
-	err = db.Where("tenant_id = ? AND reporter_id = ?", tenantID, reporterID).
+	var tickets []*tenant_models.Ticket
+	err = db.Where("project_id = ?", projectID).
 		Order("created_at DESC").Limit(limit).Offset(offset).Find(&tickets).Error
 	
 	return tickets, err
 }
 
-func (r *ticketRepository) GetByProject(tenantID, projectID string, limit, offset int) ([]*models.Ticket, error) {
+// ListByCustomerEmail matches the address case-insensitively, newest tickets first
+func (r *ticketRepository) ListByCustomerEmail(tenantID, email string, limit, offset int) ([]*tenant_models.Ticket, int64, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+		return nil, 0, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	var tickets []*models.Ticket
-	err = db.Where("tenant_id = ? AND project_id = ?", tenantID, projectID).
-		Order("created_at DESC").Limit(limit).Offset(offset).Find(&tickets).Error
+	query := db.Model(&tenant_models.Ticket{}).Where("LOWER(customer_email) = LOWER(?)", email)
+	
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	
+	var tickets []*tenant_models.Ticket
+	err = query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&tickets).Error
+	return tickets, total, err
+}
+
+// ListForExport returns up to limit matching tickets numbered after afterNumber
+func (r *ticketRepository) ListForExport(tenantID string, filters TicketFilters, afterNumber, limit int) ([]*tenant_models.Ticket, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
 	
+	var tickets []*tenant_models.Ticket
+	err = applyTicketFilters(db, db.Model(&tenant_models.Ticket{}), filters).
+		Where("ticket_number > ?", afterNumber).
+		Order("ticket_number ASC").Limit(limit).Find(&tickets).Error
 	return tickets, err
 }
 
+// CustomFieldKeys lists the custom field names used by the matching tickets, sorted
+func (r *ticketRepository) CustomFieldKeys(tenantID string, filters TicketFilters) ([]string, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	matching := applyTicketFilters(db, db.Model(&tenant_models.Ticket{}), filters).
+		Where("jsonb_typeof(custom_fields) = 'object'").
+		Select("jsonb_object_keys(custom_fields) AS field_key")
+	
+	var keys []string
+	err = db.Table("(?) AS fields", matching).Distinct("field_key").Order("field_key").Pluck("field_key", &keys).Error
+	return keys, err
+}
+
+// GetCommentsForTickets returns the comments of several tickets, oldest first
+func (r *ticketRepository) GetCommentsForTickets(tenantID string, ticketIDs []string, includeInternal bool) ([]*tenant_models.Comment, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	
+	var comments []*tenant_models.Comment
+	if len(ticketIDs) == 0 {
+		return comments, nil
+	}
+	query := db.Where("ticket_id IN ?", ticketIDs)
+	if !includeInternal {
+		query = query.Where("is_internal = ?", false)
+	}
+	err = query.Order("created_at ASC").Find(&comments).Error
+	return comments, err
+}
+
 // Comment methods
-func (r *ticketRepository) CreateComment(tenantID string, comment *models.TicketComment) error {
+// CreateComment stores the comment; history entries without a new value get the comment ID
+func (r *ticketRepository) CreateComment(tenantID string, comment *tenant_models.Comment, history ...*tenant_models.TicketHistory) error {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	return db.Create(comment).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		for _, entry := range history {
+			if entry.NewValue == nil {
+				commentID := comment.ID
+				entry.NewValue = &commentID
+			}
+		}
+		return createHistory(tx, history)
+	})
 }
 
-func (r *ticketRepository) GetComments(tenantID, ticketID string, includeInternal bool) ([]*models.TicketComment, error) {
+func (r *ticketRepository) GetComments(tenantID, ticketID string, includeInternal bool) ([]*tenant_models.Comment, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant database: %w", err)
@@ -266,13 +877,13 @@ func (r *ticketRepository) GetComments(tenantID, ticketID string, includeInterna
 		query = query.Where("is_internal = ?", false)
 	}
 	
-	var comments []*models.TicketComment
+	var comments []*tenant_models.Comment
 	err = query.Order("created_at ASC").Find(&comments).Error
 	
 	return comments, err
 }
 
-func (r *ticketRepository) UpdateComment(tenantID string, comment *models.TicketComment) error {
+func (r *ticketRepository) UpdateComment(tenantID string, comment *tenant_models.Comment) error {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant database: %w", err)
@@ -287,11 +898,11 @@ func (r *ticketRepository) DeleteComment(tenantID, commentID string) error {
 		return fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	return db.Where("id = ?", commentID).Delete(&models.TicketComment{}).Error
+	return db.Where("id = ?", commentID).Delete(&tenant_models.Comment{}).Error
 }
 
 // Attachment methods
-func (r *ticketRepository) CreateAttachment(tenantID string, attachment *models.TicketAttachment) error {
+func (r *ticketRepository) CreateAttachment(tenantID string, attachment *tenant_models.Attachment) error {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant database: %w", err)
@@ -300,94 +911,139 @@ func (r *ticketRepository) CreateAttachment(tenantID string, attachment *models.
 	return db.Create(attachment).Error
 }
 
-func (r *ticketRepository) GetAttachments(tenantID, ticketID string) ([]*models.TicketAttachment, error) {
+func (r *ticketRepository) GetAttachments(tenantID, ticketID string) ([]*tenant_models.Attachment, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	var attachments []*models.TicketAttachment
-	err = db.Where("ticket_id = ?", ticketID).Order("created_at ASC").Find(&attachments).Error
+	var attachments []*tenant_models.Attachment
+	err = db.Where("ticket_id = ?", ticketID).Order("uploaded_at ASC").Find(&attachments).Error
 	
 	return attachments, err
 }
 
-func (r *ticketRepository) DeleteAttachment(tenantID, attachmentID string) error {
+func (r *ticketRepository) GetAttachment(tenantID, attachmentID string) (*tenant_models.Attachment, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
-		return fmt.Errorf("failed to get tenant database: %w", err)
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	return db.Where("id = ?", attachmentID).Delete(&models.TicketAttachment{}).Error
+	var attachment tenant_models.Attachment
+	err = db.Where("id = ?", attachmentID).First(&attachment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
 }
 
-// Statistics
-func (r *ticketRepository) GetTicketStats(tenantID string, dateFrom, dateTo *time.Time) (TicketStats, error) {
+// GetCommentAttachments returns the files attached to any of the comments
+func (r *ticketRepository) GetCommentAttachments(tenantID string, commentIDs []string) ([]*tenant_models.Attachment, error) {
 	db, err := r.getTenantDB(tenantID)
 	if err != nil {
-		return TicketStats{}, fmt.Errorf("failed to get tenant database: %w", err)
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	var stats TicketStats
+	var attachments []*tenant_models.Attachment
+	if len(commentIDs) == 0 {
+		return attachments, nil
+	}
+	err = db.Where("comment_id IN ?", commentIDs).Order("uploaded_at ASC").Find(&attachments).Error
 	
-	query := db.Where("tenant_id = ?", tenantID)
-	if dateFrom != nil {
-		query = query.Where("created_at >= ?", dateFrom)
+	return attachments, err
+}
+
+func (r *ticketRepository) DeleteAttachment(tenantID, attachmentID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant database: %w", err)
 	}
-	if dateTo != nil {
-		query = query.Where("created_at <= ?", dateTo)
+	
+	return db.Where("id = ?", attachmentID).Delete(&tenant_models.Attachment{}).Error
+}
+
+// History methods
+func (r *ticketRepository) GetHistory(tenantID, ticketID string) ([]*tenant_models.TicketHistory, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
 	}
 	
-	// Total tickets
-	query.Model(&models.Ticket{}).Count(&stats.TotalTickets)
+	var history []*tenant_models.TicketHistory
+	err = db.Where("ticket_id = ?", ticketID).Order("changed_at ASC").Find(&history).Error
 	
-	// By status
-	stats.ByStatus = make(map[string]int64)
-	var statusCounts []struct {
-		Status string
-		Count  int64
+	return history, err
+}
+
+// createHistory writes audit entries inside the caller's transaction
+func createHistory(tx *gorm.DB, history []*tenant_models.TicketHistory) error {
+	if len(history) == 0 {
+		return nil
 	}
-	query.Model(&models.Ticket{}).Select("status, COUNT(*) as count").Group("status").Find(&statusCounts)
-	for _, sc := range statusCounts {
-		stats.ByStatus[sc.Status] = sc.Count
-		
-		// Set individual status counts
-		switch sc.Status {
-		case string(models.TicketStatusOpen):
-			stats.OpenTickets = sc.Count
-		case string(models.TicketStatusInProgress):
-			stats.InProgressTickets = sc.Count
-		case string(models.TicketStatusResolved):
-			stats.ResolvedTickets = sc.Count
-		case string(models.TicketStatusClosed):
-			stats.ClosedTickets = sc.Count
-		}
+	return tx.Create(history).Error
+}
+
+// Statistics
+// applySearchCondition adds one search operator; an empty value matches a missing field
+func applySearchCondition(query *gorm.DB, condition SearchCondition) *gorm.DB {
+	column, ok := SearchFields[condition.Field]
+	if !ok || len(condition.Values) == 0 {
+		return query
 	}
 	
-	// By priority
-	stats.ByPriority = make(map[string]int64)
-	var priorityCounts []struct {
-		Priority string
-		Count    int64
+	var values []string
+	matchEmpty := false
+	for _, value := range condition.Values {
+		if value == "" {
+			matchEmpty = true
+		} else {
+			values = append(values, value)
+		}
 	}
-	query.Model(&models.Ticket{}).Select("priority, COUNT(*) as count").Group("priority").Find(&priorityCounts)
-	for _, pc := range priorityCounts {
-		stats.ByPriority[pc.Priority] = pc.Count
+	
+	var expr clause.Expression
+	switch {
+	case column == "labels":
+		expr = clause.Expr{
+			SQL:  "EXISTS (SELECT 1 FROM jsonb_array_elements_text(labels) AS label WHERE label IN ?)",
+			Vars: []interface{}{condition.Values},
+		}
+	case matchEmpty && len(values) > 0:
+		expr = clause.Expr{SQL: column + " IS NULL OR " + column + " IN ?", Vars: []interface{}{values}}
+	case matchEmpty:
+		expr = clause.Expr{SQL: column + " IS NULL"}
+	default:
+		expr = clause.Expr{SQL: column + " IN ?", Vars: []interface{}{values}}
 	}
 	
-	// By type
-	stats.ByType = make(map[string]int64)
-	var typeCounts []struct {
-		Type  string
-		Count int64
+	if condition.Negate {
+		// NOT (x IN ...) is NULL for missing values, which would drop them from the results
+		if column != "labels" && !matchEmpty {
+			return query.Where(clause.Expr{SQL: column + " IS NULL OR NOT (?)", Vars: []interface{}{expr}})
+		}
+		return query.Not(expr)
 	}
-	query.Model(&models.Ticket{}).Select("type, COUNT(*) as count").Group("type").Find(&typeCounts)
-	for _, tc := range typeCounts {
-		stats.ByType[tc.Type] = tc.Count
+	return query.Where(expr)
+}
+
+// markHighlights escapes a ts_headline result and turns its delimiters into <mark> tags
+func markHighlights(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
+
+// applyAccessScope restricts a ticket query to what the scope allows
+func applyAccessScope(query *gorm.DB, scope *TicketAccessScope) *gorm.DB {
+	if scope == nil {
+		return query
 	}
-	
-	// TODO: Calculate average resolution time
-	stats.AverageResolutionTime = 0
-	
-	return stats, nil
-}
\ No newline at end of file
+
+	return query.Where(
+		"reporter_id = ? OR assignee_id = ? OR "+
+			"((project_id IN ? OR (? AND project_id IS NULL)) AND (visibility <> ? OR project_id IN ?))",
+		scope.UserID, scope.UserID,
+		scope.ProjectIDs, scope.IncludeUnprojected,
+		tenant_models.VisibilityRestricted, scope.LeadProjectIDs,
+	)
+}
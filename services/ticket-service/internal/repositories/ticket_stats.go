@@ -0,0 +1,552 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+// Trend intervals accepted by TicketStatsFilters
+const (
+	StatsIntervalDay  = "day"
+	StatsIntervalWeek = "week"
+)
+
+// TicketStatsFilters narrows ticket reporting. The date range applies to creation for
+// volume, first response and SLA figures, to resolution for resolution times and
+// throughput, and its end is the "as of" moment for the backlog.
+type TicketStatsFilters struct {
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	ProjectID  string
+	AssigneeID string
+	Interval   string // StatsIntervalDay or StatsIntervalWeek buckets for the trend
+}
+
+type TicketStats struct {
+	TotalTickets          int64            `json:"total_tickets"`
+	OpenTickets           int64            `json:"open_tickets"`
+	InProgressTickets     int64            `json:"in_progress_tickets"`
+	ResolvedTickets       int64            `json:"resolved_tickets"`
+	ClosedTickets         int64            `json:"closed_tickets"`
+	ByStatus              map[string]int64 `json:"by_status"`
+	ByPriority            map[string]int64 `json:"by_priority"`
+	ByType                map[string]int64 `json:"by_type"`
+	AverageResolutionTime time.Duration    `json:"average_resolution_time"`
+
+	ResolutionTime    DurationStats      `json:"resolution_time"`
+	FirstResponseTime DurationStats      `json:"first_response_time"`
+	Trend             []TrendPoint       `json:"trend"`
+	BacklogAge        []BacklogAgeBucket `json:"backlog_age"`
+	Agents            []AgentStats       `json:"agents"`
+	SLACompliance     SLACompliance      `json:"sla_compliance"`
+	CSAT              CSATStats          `json:"csat"`
+}
+
+// DurationStats summarises a set of durations in seconds
+type DurationStats struct {
+	Count          int64   `json:"count"`
+	AverageSeconds float64 `json:"average_seconds"`
+	MedianSeconds  float64 `json:"median_seconds"`
+	P90Seconds     float64 `json:"p90_seconds"`
+	P95Seconds     float64 `json:"p95_seconds"`
+}
+
+// TrendPoint counts tickets created and resolved in one day or week
+type TrendPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	Created     int64     `json:"created"`
+	Resolved    int64     `json:"resolved"`
+}
+
+// BacklogAgeBucket counts unresolved tickets by how long they have been open
+type BacklogAgeBucket struct {
+	Label   string `json:"label"`
+	MinDays int    `json:"min_days"`
+	MaxDays *int   `json:"max_days"` // Nil for the open-ended oldest bucket
+	Count   int64  `json:"count"`
+}
+
+// AgentStats is one assignee's throughput over the reporting period
+type AgentStats struct {
+	AssigneeID               string  `json:"assignee_id"`
+	Resolved                 int64   `json:"resolved"`
+	Open                     int64   `json:"open"`
+	AverageResolutionSeconds float64 `json:"average_resolution_seconds"`
+}
+
+// SLACompliance compares targets met against targets breached
+type SLACompliance struct {
+	FirstResponse ComplianceStats `json:"first_response"`
+	Resolution    ComplianceStats `json:"resolution"`
+}
+
+type ComplianceStats struct {
+	Met        int64    `json:"met"`
+	Breached   int64    `json:"breached"`
+	Percentage *float64 `json:"percentage"` // Nil until a target has been met or breached
+}
+
+// CSATStats summarises survey answers given in the reporting period, overall and broken
+// down by the agent and project each survey was sent for
+type CSATStats struct {
+	CSATScore
+	Agents   []AgentCSAT      `json:"agents"`
+	Projects []ProjectCSAT    `json:"projects"`
+	Trend    []CSATTrendPoint `json:"trend"`
+}
+
+// CSATScore is the share of answers that were satisfied, as a percentage
+type CSATScore struct {
+	Responses     int64    `json:"responses"`
+	Satisfied     int64    `json:"satisfied"`
+	Score         *float64 `json:"score"`          // Nil until a survey has been answered
+	AverageRating *float64 `json:"average_rating"` // Nil until a survey has been answered
+}
+
+type AgentCSAT struct {
+	AgentID string `json:"agent_id"`
+	CSATScore
+}
+
+type ProjectCSAT struct {
+	ProjectID string `json:"project_id"`
+	CSATScore
+}
+
+type CSATTrendPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	CSATScore
+}
+
+// backlogAgeLimits are the upper bounds in days of every backlog bucket but the last
+var backlogAgeLimits = []int{1, 3, 7, 30}
+
+// durationAggregates summarises a "seconds" column into DurationStats
+const durationAggregates = `COUNT(*) AS count,
+	COALESCE(AVG(seconds), 0) AS average_seconds,
+	COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds), 0) AS median_seconds,
+	COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY seconds), 0) AS p90_seconds,
+	COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY seconds), 0) AS p95_seconds`
+
+// firstPublicReply is when someone other than the reporter first answered publicly.
+// Customer replies by email or the portal and automation comments are not answers.
+const firstPublicReply = `(SELECT MIN(c.created_at) FROM comments c
+	WHERE c.ticket_id = tickets.id AND NOT c.is_internal AND c.deleted_at IS NULL
+	AND c.author_id IS DISTINCT FROM tickets.reporter_id
+	AND COALESCE(c.author_email, '') = '' AND c.author_id <> '` + tenant_models.SystemUserID + `')`
+
+func (r *ticketRepository) GetTicketStats(tenantID string, filters TicketStatsFilters) (TicketStats, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return TicketStats{}, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+
+	stats := TicketStats{
+		ByStatus:   make(map[string]int64),
+		ByPriority: make(map[string]int64),
+		ByType:     make(map[string]int64),
+	}
+
+	steps := []struct {
+		name string
+		run  func(*gorm.DB, TicketStatsFilters, *TicketStats) error
+	}{
+		{"ticket counts", statsCounts},
+		{"resolution times", statsResolutionTime},
+		{"first response times", statsFirstResponseTime},
+		{"trend", statsTrend},
+		{"backlog age", statsBacklogAge},
+		{"agent throughput", statsAgents},
+		{"SLA compliance", statsSLACompliance},
+		{"CSAT", statsCSAT},
+	}
+	for _, step := range steps {
+		if err := step.run(db, filters, &stats); err != nil {
+			return TicketStats{}, fmt.Errorf("failed to calculate %s: %w", step.name, err)
+		}
+	}
+
+	stats.AverageResolutionTime = time.Duration(stats.ResolutionTime.AverageSeconds * float64(time.Second))
+	return stats, nil
+}
+
+// statsScope selects tickets matching the project and assignee filters
+func statsScope(db *gorm.DB, filters TicketStatsFilters) *gorm.DB {
+	query := db.Model(&tenant_models.Ticket{})
+	if filters.ProjectID != "" {
+		query = query.Where("tickets.project_id = ?", filters.ProjectID)
+	}
+	if filters.AssigneeID != "" {
+		query = query.Where("tickets.assignee_id = ?", filters.AssigneeID)
+	}
+	return query
+}
+
+// inRange limits a timestamp column to the filter's date range
+func inRange(query *gorm.DB, column string, filters TicketStatsFilters) *gorm.DB {
+	if filters.DateFrom != nil {
+		query = query.Where(column+" >= ?", filters.DateFrom)
+	}
+	if filters.DateTo != nil {
+		query = query.Where(column+" <= ?", filters.DateTo)
+	}
+	return query
+}
+
+// unresolvedAt selects tickets that were open at the given moment
+func unresolvedAt(query *gorm.DB, at time.Time) *gorm.DB {
+	return query.Where("tickets.created_at <= ?", at).
+		Where("tickets.resolved_at > ? OR (tickets.resolved_at IS NULL AND tickets.status NOT IN ?)",
+			at, []string{string(tenant_models.StatusResolved), string(tenant_models.StatusClosed)})
+}
+
+func statsCounts(db *gorm.DB, filters TicketStatsFilters, stats *TicketStats) error {
+	var counts []struct {
+		Status     string
+		Priority   string
+		TicketType string
+		Count      int64
+	}
+	err := inRange(statsScope(db, filters), "tickets.created_at", filters).
+		Select("status, priority, ticket_type, COUNT(*) AS count").
+		Group("status, priority, ticket_type").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+
+	for _, count := range counts {
+		stats.TotalTickets += count.Count
+		stats.ByStatus[count.Status] += count.Count
+		stats.ByPriority[count.Priority] += count.Count
+		stats.ByType[count.TicketType] += count.Count
+	}
+	stats.OpenTickets = stats.ByStatus[string(tenant_models.StatusOpen)]
+	stats.InProgressTickets = stats.ByStatus[string(tenant_models.StatusInProgress)]
+	stats.ResolvedTickets = stats.ByStatus[string(tenant_models.StatusResolved)]
+	stats.ClosedTickets = stats.ByStatus[string(tenant_models.StatusClosed)]
+	return nil
+}
+
+func statsResolutionTime(db *gorm.DB, filters TicketStatsFilters, stats *TicketStats) error {
+	durations := inRange(statsScope(db, filters), "tickets.resolved_at", filters).
+		Where("tickets.resolved_at IS NOT NULL").
+		Select("EXTRACT(EPOCH FROM (tickets.resolved_at - tickets.created_at)) AS seconds")
+
+	return db.Table("(?) AS durations", durations).Select(durationAggregates).Scan(&stats.ResolutionTime).Error
+}
+
+func statsFirstResponseTime(db *gorm.DB, filters TicketStatsFilters, stats *TicketStats) error {
+	durations := inRange(statsScope(db, filters), "tickets.created_at", filters).
+		Select("EXTRACT(EPOCH FROM (" + firstPublicReply + " - tickets.created_at)) AS seconds")
+
+	return db.Table("(?) AS durations", durations).Where("seconds IS NOT NULL").
+		Select(durationAggregates).Scan(&stats.FirstResponseTime).Error
+}
+
+func statsTrend(db *gorm.DB, filters TicketStatsFilters, stats *TicketStats) error {
+	interval := StatsIntervalDay
+	if filters.Interval == StatsIntervalWeek {
+		interval = StatsIntervalWeek
+	}
+
+	type periodCount struct {
+		Period time.Time
+		Count  int64
+	}
+	var created, resolved []periodCount
+	err := inRange(statsScope(db, filters), "tickets.created_at", filters).
+		Select("date_trunc(?, tickets.created_at) AS period, COUNT(*) AS count", interval).
+		Group("period").Scan(&created).Error
+	if err != nil {
+		return err
+	}
+	err = inRange(statsScope(db, filters), "tickets.resolved_at", filters).
+		Where("tickets.resolved_at IS NOT NULL").
+		Select("date_trunc(?, tickets.resolved_at) AS period, COUNT(*) AS count", interval).
+		Group("period").Scan(&resolved).Error
+	if err != nil {
+		return err
+	}
+
+	points := make(map[time.Time]*TrendPoint)
+	point := func(period time.Time) *TrendPoint {
+		period = period.UTC()
+		if points[period] == nil {
+			points[period] = &TrendPoint{PeriodStart: period}
+		}
+		return points[period]
+	}
+	for _, count := range created {
+		point(count.Period).Created = count.Count
+	}
+	for _, count := range resolved {
+		point(count.Period).Resolved = count.Count
+	}
+	if len(points) == 0 {
+		stats.Trend = []TrendPoint{}
+		return nil
+	}
+
+	// Fill quiet periods so charts get an evenly spaced series
+	var first, last time.Time
+	for period := range points {
+		if first.IsZero() || period.Before(first) {
+			first = period
+		}
+		if period.After(last) {
+			last = period
+		}
+	}
+	step := 1
+	if interval == StatsIntervalWeek {
+		step = 7
+	}
+	for period := first; !period.After(last); period = period.AddDate(0, 0, step) {
+		point(period)
+	}
+
+	stats.Trend = make([]TrendPoint, 0, len(points))
+	for _, p := range points {
+		stats.Trend = append(stats.Trend, *p)
+	}
+	sort.Slice(stats.Trend, func(i, j int) bool {
+		return stats.Trend[i].PeriodStart.Before(stats.Trend[j].PeriodStart)
+	})
+	return nil
+}
+
+func statsBacklogAge(db *gorm.DB, filters TicketStatsFilters, stats *TicketStats) error {
+	asOf := time.Now()
+	if filters.DateTo != nil {
+		asOf = *filters.DateTo
+	}
+
+	stats.BacklogAge = make([]BacklogAgeBucket, 0, len(backlogAgeLimits)+1)
+	var cases strings.Builder
+	minDays := 0
+	for i, maxDays := range backlogAgeLimits {
+		maxDays := maxDays
+		fmt.Fprintf(&cases, "WHEN age_days < %d THEN %d ", maxDays, i)
+		stats.BacklogAge = append(stats.BacklogAge, BacklogAgeBucket{
+			Label:   fmt.Sprintf("%d-%dd", minDays, maxDays),
+			MinDays: minDays,
+			MaxDays: &maxDays,
+		})
+		minDays = maxDays
+	}
+	stats.BacklogAge = append(stats.BacklogAge, BacklogAgeBucket{
+		Label:   fmt.Sprintf("%dd+", minDays),
+		MinDays: minDays,
+	})
+
+	ages := unresolvedAt(statsScope(db, filters), asOf).
+		Select("EXTRACT(EPOCH FROM (?::timestamptz - tickets.created_at)) / 86400 AS age_days", asOf)
+
+	var counts []struct {
+		Bucket int
+		Count  int64
+	}
+	err := db.Table("(?) AS ages", ages).
+		Select(fmt.Sprintf("CASE %sELSE %d END AS bucket, COUNT(*) AS count", cases.String(), len(backlogAgeLimits))).
+		Group("bucket").Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+	for _, count := range counts {
+		stats.BacklogAge[count.Bucket].Count = count.Count
+	}
+	return nil
+}
+
+func statsAgents(db *gorm.DB, filters TicketStatsFilters, stats *TicketStats) error {
+	var resolved []struct {
+		AssigneeID     string
+		Resolved       int64
+		AverageSeconds float64
+	}
+	err := inRange(statsScope(db, filters), "tickets.resolved_at", filters).
+		Where("tickets.resolved_at IS NOT NULL AND tickets.assignee_id IS NOT NULL").
+		Select("tickets.assignee_id, COUNT(*) AS resolved, " +
+			"AVG(EXTRACT(EPOCH FROM (tickets.resolved_at - tickets.created_at))) AS average_seconds").
+		Group("tickets.assignee_id").Scan(&resolved).Error
+	if err != nil {
+		return err
+	}
+
+	asOf := time.Now()
+	if filters.DateTo != nil {
+		asOf = *filters.DateTo
+	}
+	var open []struct {
+		AssigneeID string
+		Open       int64
+	}
+	err = unresolvedAt(statsScope(db, filters), asOf).
+		Where("tickets.assignee_id IS NOT NULL").
+		Select("tickets.assignee_id, COUNT(*) AS open").
+		Group("tickets.assignee_id").Scan(&open).Error
+	if err != nil {
+		return err
+	}
+
+	agents := make(map[string]*AgentStats)
+	agent := func(id string) *AgentStats {
+		if agents[id] == nil {
+			agents[id] = &AgentStats{AssigneeID: id}
+		}
+		return agents[id]
+	}
+	for _, row := range resolved {
+		a := agent(row.AssigneeID)
+		a.Resolved = row.Resolved
+		a.AverageResolutionSeconds = row.AverageSeconds
+	}
+	for _, row := range open {
+		agent(row.AssigneeID).Open = row.Open
+	}
+
+	stats.Agents = make([]AgentStats, 0, len(agents))
+	for _, a := range agents {
+		stats.Agents = append(stats.Agents, *a)
+	}
+	sort.Slice(stats.Agents, func(i, j int) bool {
+		if stats.Agents[i].Resolved != stats.Agents[j].Resolved {
+			return stats.Agents[i].Resolved > stats.Agents[j].Resolved
+		}
+		return stats.Agents[i].AssigneeID < stats.Agents[j].AssigneeID
+	})
+	return nil
+}
+
+func statsSLACompliance(db *gorm.DB, filters TicketStatsFilters, stats *TicketStats) error {
+	var row struct {
+		FirstResponseMet      int64
+		FirstResponseBreached int64
+		ResolutionMet         int64
+		ResolutionBreached    int64
+	}
+	query := db.Model(&tenant_models.TicketSLA{}).
+		Joins("JOIN tickets ON tickets.id = ticket_slas.ticket_id AND tickets.deleted_at IS NULL")
+	if filters.ProjectID != "" {
+		query = query.Where("tickets.project_id = ?", filters.ProjectID)
+	}
+	if filters.AssigneeID != "" {
+		query = query.Where("tickets.assignee_id = ?", filters.AssigneeID)
+	}
+	err := inRange(query, "tickets.created_at", filters).Select(`
+		COUNT(*) FILTER (WHERE ticket_slas.first_responded_at IS NOT NULL AND ticket_slas.first_response_breached_at IS NULL) AS first_response_met,
+		COUNT(ticket_slas.first_response_breached_at) AS first_response_breached,
+		COUNT(*) FILTER (WHERE ticket_slas.resolved_at IS NOT NULL AND ticket_slas.resolution_breached_at IS NULL) AS resolution_met,
+		COUNT(ticket_slas.resolution_breached_at) AS resolution_breached`).
+		Scan(&row).Error
+	if err != nil {
+		return err
+	}
+
+	stats.SLACompliance = SLACompliance{
+		FirstResponse: complianceStats(row.FirstResponseMet, row.FirstResponseBreached),
+		Resolution:    complianceStats(row.ResolutionMet, row.ResolutionBreached),
+	}
+	return nil
+}
+
+func complianceStats(met, breached int64) ComplianceStats {
+	stats := ComplianceStats{Met: met, Breached: breached}
+	if total := met + breached; total > 0 {
+		percentage := float64(met) * 100 / float64(total)
+		stats.Percentage = &percentage
+	}
+	return stats
+}
+
+// csatAggregates summarises survey ratings into a csatRow
+const csatAggregates = `COUNT(*) AS responses,
+	COUNT(*) FILTER (WHERE csat_surveys.rating >= ?) AS satisfied,
+	COALESCE(AVG(csat_surveys.rating), 0) AS average_rating`
+
+type csatRow struct {
+	GroupKey      string
+	Period        time.Time
+	Responses     int64
+	Satisfied     int64
+	AverageRating float64
+}
+
+func (row csatRow) score() CSATScore {
+	score := CSATScore{Responses: row.Responses, Satisfied: row.Satisfied}
+	if row.Responses > 0 {
+		percentage := float64(row.Satisfied) * 100 / float64(row.Responses)
+		average := row.AverageRating
+		score.Score = &percentage
+		score.AverageRating = &average
+	}
+	return score
+}
+
+func statsCSAT(db *gorm.DB, filters TicketStatsFilters, stats *TicketStats) error {
+	interval := StatsIntervalDay
+	if filters.Interval == StatsIntervalWeek {
+		interval = StatsIntervalWeek
+	}
+
+	// Answers count against the agent and project the survey was sent for, in the period they came in
+	answers := func() *gorm.DB {
+		query := db.Model(&tenant_models.CSATSurvey{}).
+			Joins("JOIN tickets ON tickets.id = csat_surveys.ticket_id AND tickets.deleted_at IS NULL").
+			Where("csat_surveys.responded_at IS NOT NULL")
+		if filters.ProjectID != "" {
+			query = query.Where("csat_surveys.project_id = ?", filters.ProjectID)
+		}
+		if filters.AssigneeID != "" {
+			query = query.Where("csat_surveys.agent_id = ?", filters.AssigneeID)
+		}
+		return inRange(query, "csat_surveys.responded_at", filters)
+	}
+
+	var overall csatRow
+	if err := answers().Select(csatAggregates, tenant_models.CSATSatisfiedRating).Scan(&overall).Error; err != nil {
+		return err
+	}
+	var agents, projects, trend []csatRow
+	err := answers().Where("csat_surveys.agent_id IS NOT NULL").
+		Select("csat_surveys.agent_id AS group_key, "+csatAggregates, tenant_models.CSATSatisfiedRating).
+		Group("csat_surveys.agent_id").Order("responses DESC, group_key").Scan(&agents).Error
+	if err != nil {
+		return err
+	}
+	err = answers().Where("csat_surveys.project_id IS NOT NULL").
+		Select("csat_surveys.project_id AS group_key, "+csatAggregates, tenant_models.CSATSatisfiedRating).
+		Group("csat_surveys.project_id").Order("responses DESC, group_key").Scan(&projects).Error
+	if err != nil {
+		return err
+	}
+	err = answers().
+		Select("date_trunc(?, csat_surveys.responded_at) AS period, "+csatAggregates, interval, tenant_models.CSATSatisfiedRating).
+		Group("period").Order("period").Scan(&trend).Error
+	if err != nil {
+		return err
+	}
+
+	stats.CSAT = CSATStats{
+		CSATScore: overall.score(),
+		Agents:    make([]AgentCSAT, 0, len(agents)),
+		Projects:  make([]ProjectCSAT, 0, len(projects)),
+		Trend:     make([]CSATTrendPoint, 0, len(trend)),
+	}
+	for _, row := range agents {
+		stats.CSAT.Agents = append(stats.CSAT.Agents, AgentCSAT{AgentID: row.GroupKey, CSATScore: row.score()})
+	}
+	for _, row := range projects {
+		stats.CSAT.Projects = append(stats.CSAT.Projects, ProjectCSAT{ProjectID: row.GroupKey, CSATScore: row.score()})
+	}
+	for _, row := range trend {
+		stats.CSAT.Trend = append(stats.CSAT.Trend, CSATTrendPoint{PeriodStart: row.Period.UTC(), CSATScore: row.score()})
+	}
+	return nil
+}
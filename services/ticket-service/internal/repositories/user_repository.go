@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/zen/shared/pkg/models"
+	"gorm.io/gorm"
+)
+
+// UserRepository reads user records from the master database, where tenant databases
+// only hold user IDs
+type UserRepository interface {
+	GetByIDs(userIDs []string) ([]*models.User, error)
+	// GetMembersByEmails finds active members of the tenant by address, ignoring case
+	GetMembersByEmails(tenantID string, emails []string) ([]*models.User, error)
+	// GetMembersByIDs returns the users among userIDs who are active members of the tenant
+	GetMembersByIDs(tenantID string, userIDs []string) ([]*models.User, error)
+	// GetMembershipRole returns gorm.ErrRecordNotFound when the user is not an active member
+	GetMembershipRole(tenantID, userID string) (models.MembershipRole, error)
+}
+
+type userRepository struct {
+	masterDB *gorm.DB
+}
+
+func NewUserRepository(masterDB *gorm.DB) UserRepository {
+	return &userRepository{
+		masterDB: masterDB,
+	}
+}
+
+func (r *userRepository) GetByIDs(userIDs []string) ([]*models.User, error) {
+	var users []*models.User
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+	err := r.masterDB.Where("id IN ?", userIDs).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) GetMembersByEmails(tenantID string, emails []string) ([]*models.User, error) {
+	var users []*models.User
+	if len(emails) == 0 {
+		return users, nil
+	}
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+	err := r.masterDB.
+		Joins("JOIN user_tenant_memberships m ON m.user_id = users.id").
+		Where("m.tenant_id = ? AND m.status = ? AND m.deleted_at IS NULL AND LOWER(users.email) IN ?", tenantID, models.MembershipStatusActive, lowered).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) GetMembersByIDs(tenantID string, userIDs []string) ([]*models.User, error) {
+	var users []*models.User
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+	err := r.masterDB.
+		Joins("JOIN user_tenant_memberships m ON m.user_id = users.id").
+		Where("m.tenant_id = ? AND m.status = ? AND m.deleted_at IS NULL AND users.id IN ?", tenantID, models.MembershipStatusActive, userIDs).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) GetMembershipRole(tenantID, userID string) (models.MembershipRole, error) {
+	var membership models.UserTenantMembership
+	err := r.masterDB.
+		Where("tenant_id = ? AND user_id = ? AND status = ?", tenantID, userID, models.MembershipStatusActive).
+		First(&membership).Error
+	if err != nil {
+		return "", err
+	}
+	return membership.Role, nil
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/zen/shared/pkg/database"
+	"github.com/zen/shared/pkg/tenant_models"
+	"gorm.io/gorm"
+)
+
+type WorkflowRepository interface {
+	// Custom statuses
+	CreateStatus(tenantID string, status *tenant_models.CustomStatus) error
+	GetStatus(tenantID, statusID string) (*tenant_models.CustomStatus, error)
+	GetStatusByName(tenantID, name string) (*tenant_models.CustomStatus, error)
+	UpdateStatus(tenantID string, status *tenant_models.CustomStatus) error
+	DeleteStatus(tenantID, statusID string) error
+	ListStatuses(tenantID string, activeOnly bool) ([]*tenant_models.CustomStatus, error)
+	CountTicketsInStatus(tenantID, status string) (int64, error)
+
+	// Workflows
+	CreateWorkflow(tenantID string, workflow *tenant_models.Workflow) error
+	GetWorkflow(tenantID, workflowID string) (*tenant_models.Workflow, error)
+	UpdateWorkflow(tenantID string, workflow *tenant_models.Workflow, replaceTransitions bool) error
+	DeleteWorkflow(tenantID, workflowID string) error
+	ListWorkflows(tenantID string, activeOnly bool) ([]*tenant_models.Workflow, error)
+}
+
+type workflowRepository struct {
+	tenantDBManager *database.TenantDatabaseManager
+}
+
+func NewWorkflowRepository(tenantDBManager *database.TenantDatabaseManager) WorkflowRepository {
+	return &workflowRepository{
+		tenantDBManager: tenantDBManager,
+	}
+}
+
+func (r *workflowRepository) getTenantDB(tenantID string) (*gorm.DB, error) {
+	db, err := r.tenantDBManager.GetTenantDB(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant database: %w", err)
+	}
+	return db, nil
+}
+
+// Custom status methods
+func (r *workflowRepository) CreateStatus(tenantID string, status *tenant_models.CustomStatus) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Create(status).Error
+}
+
+func (r *workflowRepository) GetStatus(tenantID, statusID string) (*tenant_models.CustomStatus, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var status tenant_models.CustomStatus
+	err = db.Where("id = ?", statusID).First(&status).Error
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (r *workflowRepository) GetStatusByName(tenantID, name string) (*tenant_models.CustomStatus, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var status tenant_models.CustomStatus
+	err = db.Where("name = ?", name).First(&status).Error
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (r *workflowRepository) UpdateStatus(tenantID string, status *tenant_models.CustomStatus) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Save(status).Error
+}
+
+func (r *workflowRepository) DeleteStatus(tenantID, statusID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", statusID).Delete(&tenant_models.CustomStatus{}).Error
+}
+
+func (r *workflowRepository) ListStatuses(tenantID string, activeOnly bool) ([]*tenant_models.CustomStatus, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Model(&tenant_models.CustomStatus{})
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var statuses []*tenant_models.CustomStatus
+	err = query.Order("sort_order ASC, name ASC").Find(&statuses).Error
+	return statuses, err
+}
+
+func (r *workflowRepository) CountTicketsInStatus(tenantID, status string) (int64, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Model(&tenant_models.Ticket{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
+// Workflow methods
+func (r *workflowRepository) CreateWorkflow(tenantID string, workflow *tenant_models.Workflow) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	// Transitions are created along with the workflow through the association
+	return db.Create(workflow).Error
+}
+
+func (r *workflowRepository) GetWorkflow(tenantID, workflowID string) (*tenant_models.Workflow, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var workflow tenant_models.Workflow
+	err = db.Preload("Transitions", orderTransitions).Where("id = ?", workflowID).First(&workflow).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// UpdateWorkflow saves the workflow and, when asked, swaps its transitions for the ones it carries
+func (r *workflowRepository) UpdateWorkflow(tenantID string, workflow *tenant_models.Workflow, replaceTransitions bool) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Transitions").Save(workflow).Error; err != nil {
+			return err
+		}
+		if !replaceTransitions {
+			return nil
+		}
+
+		if err := tx.Where("workflow_id = ?", workflow.ID).Delete(&tenant_models.WorkflowTransition{}).Error; err != nil {
+			return err
+		}
+		for i := range workflow.Transitions {
+			workflow.Transitions[i].ID = ""
+			workflow.Transitions[i].WorkflowID = workflow.ID
+		}
+		if len(workflow.Transitions) == 0 {
+			return nil
+		}
+		return tx.Create(&workflow.Transitions).Error
+	})
+}
+
+func (r *workflowRepository) DeleteWorkflow(tenantID, workflowID string) error {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workflow_id = ?", workflowID).Delete(&tenant_models.WorkflowTransition{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", workflowID).Delete(&tenant_models.Workflow{}).Error
+	})
+}
+
+func (r *workflowRepository) ListWorkflows(tenantID string, activeOnly bool) ([]*tenant_models.Workflow, error) {
+	db, err := r.getTenantDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Model(&tenant_models.Workflow{}).Preload("Transitions", orderTransitions)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var workflows []*tenant_models.Workflow
+	err = query.Order("created_at ASC").Find(&workflows).Error
+	return workflows, err
+}
+
+func orderTransitions(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC")
+}
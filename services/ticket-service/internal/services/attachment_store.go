@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// AttachmentStore keeps attachment contents outside the database
+type AttachmentStore interface {
+	// Save writes the file and returns its stored name and path
+	Save(tenantID, originalFilename string, data []byte) (string, string, error)
+}
+
+type diskAttachmentStore struct {
+	root string
+}
+
+// NewDiskAttachmentStore stores attachments under root/<tenant ID>/
+func NewDiskAttachmentStore(root string) AttachmentStore {
+	return &diskAttachmentStore{root: root}
+}
+
+func (s *diskAttachmentStore) Save(tenantID, originalFilename string, data []byte) (string, string, error) {
+	dir := filepath.Join(s.root, tenantID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", "", fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+
+	// Never trust a sender's filename on disk; keep only a sane extension
+	filename := uuid.NewString()
+	if ext := strings.ToLower(filepath.Ext(originalFilename)); len(ext) <= 10 && !strings.ContainsAny(ext, `/\ `) {
+		filename += ext
+	}
+
+	path := filepath.Join(dir, filename)
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		return "", "", fmt.Errorf("failed to write attachment: %w", err)
+	}
+	return filename, path, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"ticket-service/internal/repositories"
+)
+
+// AutomationScheduler periodically records every active tenant's SLA breaches and runs
+// their scheduled automation rules
+type AutomationScheduler struct {
+	tenants  repositories.TenantRepository
+	tickets  TicketService
+	sla      SLAService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewAutomationScheduler(tenants repositories.TenantRepository, tickets TicketService, sla SLAService, interval time.Duration, logger *zap.Logger) *AutomationScheduler {
+	return &AutomationScheduler{
+		tenants:  tenants,
+		tickets:  tickets,
+		sla:      sla,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start runs the scheduler until ctx is cancelled; a non-positive interval disables it
+func (s *AutomationScheduler) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		s.logger.Info("Scheduled automations and SLA breach refresh disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce evaluates each tenant in turn; one tenant failing does not hold up the rest
+func (s *AutomationScheduler) runOnce(ctx context.Context) {
+	tenants, err := s.tenants.ListActive()
+	if err != nil {
+		s.logger.Error("Failed to list tenants for scheduled automations", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, tenant := range tenants {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.sla.RefreshBreaches(tenant.ID); err != nil {
+			s.logger.Warn("SLA breach refresh failed",
+				zap.String("tenant_id", tenant.ID),
+				zap.Error(err))
+		}
+		if err := s.tickets.RunScheduledAutomations(tenant.ID, now); err != nil {
+			s.logger.Warn("Scheduled automations failed",
+				zap.String("tenant_id", tenant.ID),
+				zap.Error(err))
+		}
+	}
+}
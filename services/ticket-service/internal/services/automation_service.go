@@ -0,0 +1,525 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// maskedSecret stands in for webhook secrets in responses; sending it back keeps the secret
+const maskedSecret = "********"
+
+// AutomationSettings limits the outbound calls rules make
+type AutomationSettings struct {
+	WebhookTimeout       time.Duration
+	AllowPrivateWebhooks bool
+}
+
+// AutomationService manages automation rules and carries out the actions that leave the
+// ticket service. Matching rules against events and changing tickets happens in the
+// ticket service, so rule changes go through the same checks and history as anyone's.
+type AutomationService interface {
+	// Rules
+	CreateRule(userID, tenantID string, req *tenant_models.AutomationRuleCreateRequest) (*tenant_models.AutomationRuleResponse, error)
+	GetRule(userID, tenantID, ruleID string) (*tenant_models.AutomationRuleResponse, error)
+	UpdateRule(userID, tenantID, ruleID string, req *tenant_models.AutomationRuleUpdateRequest) (*tenant_models.AutomationRuleResponse, error)
+	DeleteRule(userID, tenantID, ruleID string) error
+	ListRules(userID, tenantID string) ([]*tenant_models.AutomationRuleResponse, error)
+	ListLogs(userID, tenantID string, filters repositories.AutomationLogFilters, limit, offset int) ([]*tenant_models.AutomationLog, int64, error)
+
+	// Rule engine
+	RulesFor(tenantID string, trigger tenant_models.AutomationTrigger) ([]*tenant_models.AutomationRule, error)
+	RecentFirings(tenantID, ruleID, ticketID string, since time.Time) (int64, error)
+	LastFirings(tenantID, ruleID string, ticketIDs []string) (map[string]time.Time, error)
+	RecordFiring(tenantID string, log *tenant_models.AutomationLog) error
+	Deliver(tenantID string, rule *tenant_models.AutomationRule, action tenant_models.AutomationAction, ticket *tenant_models.Ticket, comment *tenant_models.Comment) error
+}
+
+type automationService struct {
+	repo       repositories.AutomationRepository
+	users      repositories.UserRepository
+	replies    EmailReplyService
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+func NewAutomationService(repo repositories.AutomationRepository, users repositories.UserRepository, replies EmailReplyService, settings AutomationSettings, logger *zap.Logger) AutomationService {
+	return &automationService{
+		repo:       repo,
+		users:      users,
+		replies:    replies,
+		httpClient: newWebhookClient(settings),
+		logger:     logger,
+	}
+}
+
+// Rule methods
+func (s *automationService) CreateRule(userID, tenantID string, req *tenant_models.AutomationRuleCreateRequest) (*tenant_models.AutomationRuleResponse, error) {
+	rule := &tenant_models.AutomationRule{
+		Name:        req.Name,
+		Description: req.Description,
+		Trigger:     req.Trigger,
+		Match:       req.Match,
+		Conditions:  req.Conditions,
+		Actions:     req.Actions,
+		Position:    req.Position,
+		IsActive:    true,
+		CreatedBy:   userID,
+	}
+	if rule.Match == "" {
+		rule.Match = tenant_models.MatchAll
+	}
+	if err := validateAutomationRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRule(tenantID, rule); err != nil {
+		return nil, fmt.Errorf("failed to create automation rule: %w", err)
+	}
+
+	s.logger.Info("Automation rule created",
+		zap.String("rule_id", rule.ID),
+		zap.String("trigger", string(rule.Trigger)),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	response := rule.ToResponse()
+	return &response, nil
+}
+
+func (s *automationService) GetRule(userID, tenantID, ruleID string) (*tenant_models.AutomationRuleResponse, error) {
+	rule, err := s.repo.GetRule(tenantID, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automation rule: %w", err)
+	}
+
+	response := rule.ToResponse()
+	return &response, nil
+}
+
+func (s *automationService) UpdateRule(userID, tenantID, ruleID string, req *tenant_models.AutomationRuleUpdateRequest) (*tenant_models.AutomationRuleResponse, error) {
+	rule, err := s.repo.GetRule(tenantID, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automation rule: %w", err)
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if req.Trigger != nil {
+		rule.Trigger = *req.Trigger
+	}
+	if req.Match != nil {
+		rule.Match = *req.Match
+	}
+	if req.Conditions != nil {
+		rule.Conditions = *req.Conditions
+	}
+	if req.Actions != nil {
+		rule.Actions = keepWebhookSecrets(rule.Actions, *req.Actions)
+	}
+	if req.Position != nil {
+		rule.Position = *req.Position
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if err := validateAutomationRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRule(tenantID, rule); err != nil {
+		return nil, fmt.Errorf("failed to update automation rule: %w", err)
+	}
+
+	response := rule.ToResponse()
+	return &response, nil
+}
+
+func (s *automationService) DeleteRule(userID, tenantID, ruleID string) error {
+	if _, err := s.repo.GetRule(tenantID, ruleID); err != nil {
+		return fmt.Errorf("failed to get automation rule: %w", err)
+	}
+	if err := s.repo.DeleteRule(tenantID, ruleID); err != nil {
+		return fmt.Errorf("failed to delete automation rule: %w", err)
+	}
+	return nil
+}
+
+func (s *automationService) ListRules(userID, tenantID string) ([]*tenant_models.AutomationRuleResponse, error) {
+	rules, err := s.repo.ListRules(tenantID, "", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automation rules: %w", err)
+	}
+
+	responses := make([]*tenant_models.AutomationRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response := rule.ToResponse()
+		responses = append(responses, &response)
+	}
+	return responses, nil
+}
+
+func (s *automationService) ListLogs(userID, tenantID string, filters repositories.AutomationLogFilters, limit, offset int) ([]*tenant_models.AutomationLog, int64, error) {
+	logs, total, err := s.repo.ListLogs(tenantID, filters, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list automation logs: %w", err)
+	}
+	return logs, total, nil
+}
+
+// Rule engine methods
+func (s *automationService) RulesFor(tenantID string, trigger tenant_models.AutomationTrigger) ([]*tenant_models.AutomationRule, error) {
+	return s.repo.ListRules(tenantID, trigger, true)
+}
+
+func (s *automationService) RecentFirings(tenantID, ruleID, ticketID string, since time.Time) (int64, error) {
+	return s.repo.CountFirings(tenantID, ruleID, ticketID, since)
+}
+
+func (s *automationService) LastFirings(tenantID, ruleID string, ticketIDs []string) (map[string]time.Time, error) {
+	return s.repo.LastFirings(tenantID, ruleID, ticketIDs)
+}
+
+func (s *automationService) RecordFiring(tenantID string, log *tenant_models.AutomationLog) error {
+	return s.repo.CreateLog(tenantID, log)
+}
+
+// Deliver sends a notify or webhook action
+func (s *automationService) Deliver(tenantID string, rule *tenant_models.AutomationRule, action tenant_models.AutomationAction, ticket *tenant_models.Ticket, comment *tenant_models.Comment) error {
+	switch action.Type {
+	case tenant_models.ActionNotify:
+		return s.notify(tenantID, rule, action, ticket, comment)
+	case tenant_models.ActionWebhook:
+		return s.callWebhook(tenantID, rule, action, ticket, comment)
+	default:
+		return fmt.Errorf("action %s is not delivered", action.Type)
+	}
+}
+
+func (s *automationService) notify(tenantID string, rule *tenant_models.AutomationRule, action tenant_models.AutomationAction, ticket *tenant_models.Ticket, comment *tenant_models.Comment) error {
+	var recipients []mail.Address
+	var userIDs []string
+	for _, recipient := range action.Recipients {
+		switch recipient {
+		case "assignee":
+			if ticket.AssigneeID != nil {
+				userIDs = append(userIDs, *ticket.AssigneeID)
+			}
+		case "reporter":
+			if ticket.ReporterID != nil && *ticket.ReporterID != tenant_models.SystemUserID {
+				userIDs = append(userIDs, *ticket.ReporterID)
+			}
+		case "customer":
+			if ticket.CustomerEmail != "" {
+				recipients = append(recipients, mail.Address{Name: ticket.CustomerName, Address: ticket.CustomerEmail})
+			}
+		default:
+			recipients = append(recipients, mail.Address{Address: recipient})
+		}
+	}
+
+	users, err := s.users.GetByIDs(userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to look up recipients: %w", err)
+	}
+	for _, user := range users {
+		recipients = append(recipients, mail.Address{Name: strings.TrimSpace(user.FirstName + " " + user.LastName), Address: user.Email})
+	}
+
+	seen := map[string]bool{}
+	unique := recipients[:0]
+	for _, recipient := range recipients {
+		address := strings.ToLower(recipient.Address)
+		if !seen[address] {
+			seen[address] = true
+			unique = append(unique, recipient)
+		}
+	}
+	if len(unique) == 0 {
+		return fmt.Errorf("no recipients to notify")
+	}
+
+	subject := action.Subject
+	if subject == "" {
+		subject = "[#{{ticket.number}}] {{ticket.title}}"
+	}
+	return s.replies.SendNotification(tenantID, unique,
+		renderAutomationText(subject, rule, ticket, comment),
+		renderAutomationText(action.Body, rule, ticket, comment))
+}
+
+// automationWebhookPayload is the JSON body webhooks receive
+type automationWebhookPayload struct {
+	Event       tenant_models.AutomationTrigger `json:"event"`
+	TenantID    string                          `json:"tenant_id"`
+	Rule        automationWebhookRule           `json:"rule"`
+	Ticket      tenant_models.TicketResponse    `json:"ticket"`
+	Comment     *tenant_models.CommentResponse  `json:"comment,omitempty"`
+	TriggeredAt time.Time                       `json:"triggered_at"`
+}
+
+type automationWebhookRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (s *automationService) callWebhook(tenantID string, rule *tenant_models.AutomationRule, action tenant_models.AutomationAction, ticket *tenant_models.Ticket, comment *tenant_models.Comment) error {
+	payload := automationWebhookPayload{
+		Event:       rule.Trigger,
+		TenantID:    tenantID,
+		Rule:        automationWebhookRule{ID: rule.ID, Name: rule.Name},
+		Ticket:      ticket.ToResponse(),
+		TriggeredAt: time.Now(),
+	}
+	if comment != nil {
+		response := comment.ToResponse()
+		payload.Comment = &response
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, action.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ZenPlatform-Automations/1.0")
+	for key, value := range action.Headers {
+		req.Header.Set(key, value)
+	}
+	if action.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(action.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newWebhookClient refuses to connect to loopback, private and link-local addresses unless
+// allowed, so rules cannot be used to reach internal services. The check runs on the
+// resolved address of every connection, redirects included.
+func newWebhookClient(settings AutomationSettings) *http.Client {
+	dialer := &net.Dialer{Timeout: settings.WebhookTimeout}
+	if !settings.AllowPrivateWebhooks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+				ip.IsLinkLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: settings.WebhookTimeout, Transport: transport}
+}
+
+// keepWebhookSecrets restores secrets sent back masked, matching webhooks by URL
+func keepWebhookSecrets(previous, actions []tenant_models.AutomationAction) []tenant_models.AutomationAction {
+	secrets := map[string]string{}
+	for _, action := range previous {
+		if action.Type == tenant_models.ActionWebhook && action.Secret != "" {
+			secrets[action.URL] = action.Secret
+		}
+	}
+	for i := range actions {
+		if actions[i].Type == tenant_models.ActionWebhook && actions[i].Secret == maskedSecret {
+			actions[i].Secret = secrets[actions[i].URL]
+		}
+	}
+	return actions
+}
+
+// validateAutomationRule checks a rule is complete before it is saved, so the engine
+// only ever meets well-formed rules
+func validateAutomationRule(rule *tenant_models.AutomationRule) error {
+	switch rule.Trigger {
+	case tenant_models.TriggerTicketCreated, tenant_models.TriggerTicketUpdated, tenant_models.TriggerCommentAdded,
+		tenant_models.TriggerScheduled:
+	default:
+		return validationError("unknown trigger %q", rule.Trigger)
+	}
+	if rule.Match != tenant_models.MatchAll && rule.Match != tenant_models.MatchAny {
+		return validationError("match must be all or any")
+	}
+	if len(rule.Actions) == 0 {
+		return validationError("a rule needs at least one action")
+	}
+
+	for i, condition := range rule.Conditions {
+		if err := validateAutomationCondition(rule.Trigger, condition); err != nil {
+			return validationError("condition %d: %v", i+1, err)
+		}
+	}
+	for i, action := range rule.Actions {
+		if err := validateAutomationAction(action); err != nil {
+			return validationError("action %d: %v", i+1, err)
+		}
+	}
+
+	// Without a time condition a scheduled rule would act on every matching ticket each time
+	// it changes, which event rules already do
+	if rule.Trigger == tenant_models.TriggerScheduled {
+		timed := false
+		for _, condition := range rule.Conditions {
+			_, isTime := automationTimeFields[condition.Field]
+			timed = timed || isTime
+		}
+		if !timed || rule.Match == tenant_models.MatchAny {
+			return validationError("scheduled rules need match all and at least one time condition, such as hours_since_updated")
+		}
+	}
+	return nil
+}
+
+func validateAutomationCondition(trigger tenant_models.AutomationTrigger, condition tenant_models.AutomationCondition) error {
+	_, ticketField := automationTicketFields[condition.Field]
+	_, commentField := automationCommentFields[condition.Field]
+	_, timeField := automationTimeFields[condition.Field]
+	customKey, customField := strings.CutPrefix(condition.Field, customFieldPrefix)
+	switch {
+	case ticketField:
+	case timeField:
+		if trigger != tenant_models.TriggerScheduled {
+			return fmt.Errorf("%s is only available on scheduled rules", condition.Field)
+		}
+		switch condition.Operator {
+		case tenant_models.OperatorGreaterThan, tenant_models.OperatorLessThan,
+			tenant_models.OperatorIsEmpty, tenant_models.OperatorIsNotEmpty:
+		default:
+			return fmt.Errorf("%s is compared with greater_than, less_than, is_empty or is_not_empty", condition.Field)
+		}
+	case commentField:
+		if trigger != tenant_models.TriggerCommentAdded {
+			return fmt.Errorf("%s is only available on comment_added", condition.Field)
+		}
+	case customField && customKey != "":
+	default:
+		return fmt.Errorf("unknown field %q", condition.Field)
+	}
+
+	switch condition.Operator {
+	case tenant_models.OperatorIsEmpty, tenant_models.OperatorIsNotEmpty:
+		return nil
+	case tenant_models.OperatorChanged:
+		if trigger != tenant_models.TriggerTicketUpdated {
+			return fmt.Errorf("changed is only available on ticket_updated")
+		}
+		return nil
+	case tenant_models.OperatorChangedTo, tenant_models.OperatorChangedFrom:
+		if trigger != tenant_models.TriggerTicketUpdated {
+			return fmt.Errorf("%s is only available on ticket_updated", condition.Operator)
+		}
+	case tenant_models.OperatorIs, tenant_models.OperatorIsNot, tenant_models.OperatorContains,
+		tenant_models.OperatorNotContains, tenant_models.OperatorStartsWith, tenant_models.OperatorEndsWith:
+	case tenant_models.OperatorMatches:
+		for _, pattern := range condition.Values {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid pattern %q", pattern)
+			}
+		}
+	case tenant_models.OperatorGreaterThan, tenant_models.OperatorLessThan:
+		for _, value := range condition.Values {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("%s needs a number, got %q", condition.Operator, value)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", condition.Operator)
+	}
+
+	if len(condition.Values) == 0 {
+		return fmt.Errorf("%s needs at least one value", condition.Operator)
+	}
+	return nil
+}
+
+func validateAutomationAction(action tenant_models.AutomationAction) error {
+	switch action.Type {
+	case tenant_models.ActionSetField:
+		if !automationSettableFields[action.Field] {
+			return fmt.Errorf("field %q cannot be set by automation", action.Field)
+		}
+		if action.Value == "" && !automationClearableFields[action.Field] {
+			return fmt.Errorf("%s needs a value", action.Field)
+		}
+	case tenant_models.ActionAddLabels, tenant_models.ActionRemoveLabels:
+		if len(action.Values) == 0 {
+			return fmt.Errorf("%s needs at least one label", action.Type)
+		}
+	case tenant_models.ActionSetCustomField:
+		if action.Field == "" {
+			return fmt.Errorf("set_custom_field needs the field key")
+		}
+	case tenant_models.ActionAddComment:
+		if strings.TrimSpace(action.Body) == "" {
+			return fmt.Errorf("add_comment needs a body")
+		}
+	case tenant_models.ActionNotify:
+		if len(action.Recipients) == 0 {
+			return fmt.Errorf("notify needs at least one recipient")
+		}
+		for _, recipient := range action.Recipients {
+			switch recipient {
+			case "assignee", "reporter", "customer":
+			default:
+				if _, err := mail.ParseAddress(recipient); err != nil {
+					return fmt.Errorf("invalid recipient %q", recipient)
+				}
+			}
+		}
+		if strings.TrimSpace(action.Body) == "" {
+			return fmt.Errorf("notify needs a body")
+		}
+	case tenant_models.ActionWebhook:
+		target, err := url.Parse(action.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("webhook needs an http or https url")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", action.Type)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// maxCalendarDays bounds calendar walks so a calendar without working days cannot loop forever
+const maxCalendarDays = 3660
+
+type workingWindow struct {
+	start time.Duration // offset from midnight
+	end   time.Duration
+}
+
+// businessHours computes elapsed and target times within a tenant's working hours.
+// A nil *businessHours behaves as a 24x7 calendar.
+type businessHours struct {
+	location          *time.Location
+	windows           map[time.Weekday]workingWindow
+	holidays          map[string]bool // YYYY-MM-DD
+	recurringHolidays map[string]bool // MM-DD
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// newBusinessHours builds a calculator from a stored calendar
+func newBusinessHours(calendar *tenant_models.BusinessCalendar) (*businessHours, error) {
+	if calendar == nil {
+		return nil, nil
+	}
+
+	location := time.UTC
+	if calendar.Timezone != "" {
+		loc, err := time.LoadLocation(calendar.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar timezone %q: %w", calendar.Timezone, err)
+		}
+		location = loc
+	}
+
+	windows, err := parseWorkingHours(calendar.WorkingHours)
+	if err != nil {
+		return nil, err
+	}
+
+	bh := &businessHours{
+		location:          location,
+		windows:           windows,
+		holidays:          make(map[string]bool),
+		recurringHolidays: make(map[string]bool),
+	}
+	for _, holiday := range calendar.Holidays {
+		if holiday.Recurring {
+			bh.recurringHolidays[holiday.Date.Format("01-02")] = true
+		} else {
+			bh.holidays[holiday.Date.Format("2006-01-02")] = true
+		}
+	}
+
+	return bh, nil
+}
+
+// parseWorkingHours validates a {"monday": {"start": "09:00", "end": "17:00"}} map
+func parseWorkingHours(hours map[string]interface{}) (map[time.Weekday]workingWindow, error) {
+	windows := make(map[time.Weekday]workingWindow)
+	for day, raw := range hours {
+		weekday, ok := weekdayNames[strings.ToLower(day)]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q in working hours", day)
+		}
+
+		entry, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("working hours for %s must be an object with start and end", day)
+		}
+		startStr, _ := entry["start"].(string)
+		endStr, _ := entry["end"].(string)
+
+		start, err := parseClock(startStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time for %s: %w", day, err)
+		}
+		end, err := parseClock(endStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time for %s: %w", day, err)
+		}
+		if end <= start {
+			return nil, fmt.Errorf("working hours for %s must end after they start", day)
+		}
+
+		windows[weekday] = workingWindow{start: start, end: end}
+	}
+
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("working hours must define at least one working day")
+	}
+
+	return windows, nil
+}
+
+// parseClock parses HH:MM into an offset from midnight; "24:00" is allowed as end of day
+func parseClock(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// window returns the working window for the day containing t, if that day is a working day
+func (bh *businessHours) window(t time.Time) (time.Time, time.Time, bool) {
+	if bh.holidays[t.Format("2006-01-02")] || bh.recurringHolidays[t.Format("01-02")] {
+		return time.Time{}, time.Time{}, false
+	}
+	w, ok := bh.windows[t.Weekday()]
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, bh.location)
+	return midnight.Add(w.start), midnight.Add(w.end), true
+}
+
+// nextMidnight returns the start of the day after t
+func (bh *businessHours) nextMidnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, bh.location)
+}
+
+// Add returns the moment d of business time after start
+func (bh *businessHours) Add(start time.Time, d time.Duration) time.Time {
+	if bh == nil {
+		return start.Add(d)
+	}
+
+	t := start.In(bh.location)
+	for i := 0; i < maxCalendarDays; i++ {
+		dayStart, dayEnd, ok := bh.window(t)
+		if ok && t.Before(dayEnd) {
+			if t.Before(dayStart) {
+				t = dayStart
+			}
+			available := dayEnd.Sub(t)
+			if d <= available {
+				return t.Add(d)
+			}
+			d -= available
+		}
+		t = bh.nextMidnight(t)
+	}
+
+	// Calendar has no usable working time; fall back to wall-clock time
+	return start.Add(d)
+}
+
+// Between returns the business time elapsed between from and to
+func (bh *businessHours) Between(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	if bh == nil {
+		return to.Sub(from)
+	}
+
+	var elapsed time.Duration
+	t := from.In(bh.location)
+	to = to.In(bh.location)
+	for i := 0; i < maxCalendarDays && t.Before(to); i++ {
+		dayStart, dayEnd, ok := bh.window(t)
+		if ok {
+			segStart := t
+			if segStart.Before(dayStart) {
+				segStart = dayStart
+			}
+			segEnd := dayEnd
+			if to.Before(segEnd) {
+				segEnd = to
+			}
+			if segEnd.After(segStart) {
+				elapsed += segEnd.Sub(segStart)
+			}
+		}
+		t = bh.nextMidnight(t)
+	}
+
+	return elapsed
+}
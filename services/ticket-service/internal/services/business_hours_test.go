@@ -0,0 +1,137 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// testCalendar works 09:00-17:00 UTC on weekdays, is closed on Tuesday 13 January 2026
+// and on New Year's Day every year
+func testCalendar(t *testing.T) *businessHours {
+	t.Helper()
+	day := map[string]interface{}{"start": "09:00", "end": "17:00"}
+	bh, err := newBusinessHours(&tenant_models.BusinessCalendar{
+		Timezone: "UTC",
+		WorkingHours: models.JSONB{
+			"monday": day, "tuesday": day, "wednesday": day, "thursday": day, "friday": day,
+		},
+		Holidays: []tenant_models.BusinessHoliday{
+			{Date: date(2026, 1, 13, 0, 0)},
+			{Date: date(2020, 1, 1, 0, 0), Recurring: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("newBusinessHours: %v", err)
+	}
+	return bh
+}
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestBusinessHoursAdd(t *testing.T) {
+	bh := testCalendar(t)
+	tests := []struct {
+		name  string
+		start time.Time
+		d     time.Duration
+		want  time.Time
+	}{
+		{"same day", date(2026, 1, 5, 10, 0), 2 * time.Hour, date(2026, 1, 5, 12, 0)},
+		{"before opening", date(2026, 1, 5, 7, 0), time.Hour, date(2026, 1, 5, 10, 0)},
+		{"up to closing", date(2026, 1, 5, 15, 0), 2 * time.Hour, date(2026, 1, 5, 17, 0)},
+		{"overnight", date(2026, 1, 5, 16, 0), 2 * time.Hour, date(2026, 1, 6, 10, 0)},
+		{"after closing", date(2026, 1, 5, 20, 0), time.Hour, date(2026, 1, 6, 10, 0)},
+		{"several days", date(2026, 1, 5, 9, 0), 20 * time.Hour, date(2026, 1, 7, 13, 0)},
+		{"over the weekend", date(2026, 1, 9, 16, 0), 2 * time.Hour, date(2026, 1, 12, 10, 0)},
+		{"from the weekend", date(2026, 1, 10, 12, 0), time.Hour, date(2026, 1, 12, 10, 0)},
+		{"over a holiday", date(2026, 1, 12, 16, 0), 2 * time.Hour, date(2026, 1, 14, 10, 0)},
+		{"over a recurring holiday", date(2025, 12, 31, 16, 0), 2 * time.Hour, date(2026, 1, 2, 10, 0)},
+	}
+	for _, tt := range tests {
+		got := bh.Add(tt.start, tt.d)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: Add(%s, %s) = %s, want %s", tt.name, tt.start, tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestBusinessHoursBetween(t *testing.T) {
+	bh := testCalendar(t)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+	}{
+		{"same day", date(2026, 1, 5, 10, 0), date(2026, 1, 5, 12, 0), 2 * time.Hour},
+		{"outside hours", date(2026, 1, 5, 18, 0), date(2026, 1, 6, 8, 0), 0},
+		{"overnight", date(2026, 1, 5, 16, 0), date(2026, 1, 6, 10, 0), 2 * time.Hour},
+		{"over the weekend", date(2026, 1, 9, 16, 0), date(2026, 1, 12, 10, 0), 2 * time.Hour},
+		{"within the weekend", date(2026, 1, 10, 9, 0), date(2026, 1, 11, 17, 0), 0},
+		{"over a holiday", date(2026, 1, 12, 16, 0), date(2026, 1, 14, 10, 0), 2 * time.Hour},
+		{"over a recurring holiday", date(2025, 12, 31, 16, 0), date(2026, 1, 2, 10, 0), 2 * time.Hour},
+		{"backwards", date(2026, 1, 5, 12, 0), date(2026, 1, 5, 10, 0), 0},
+	}
+	for _, tt := range tests {
+		got := bh.Between(tt.from, tt.to)
+		if got != tt.want {
+			t.Errorf("%s: Between(%s, %s) = %s, want %s", tt.name, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// TestBusinessHoursPause follows a clock paused on Friday afternoon and resumed on Monday
+// morning: only the working time spent paused pushes the target back
+func TestBusinessHoursPause(t *testing.T) {
+	bh := testCalendar(t)
+	created := date(2026, 1, 9, 9, 0)
+	target := bh.Add(created, 8*time.Hour)
+	if want := date(2026, 1, 9, 17, 0); !target.Equal(want) {
+		t.Fatalf("target = %s, want %s", target, want)
+	}
+
+	paused := bh.Between(date(2026, 1, 9, 15, 0), date(2026, 1, 12, 11, 0))
+	if paused != 4*time.Hour {
+		t.Fatalf("paused = %s, want 4h", paused)
+	}
+	if got, want := bh.Add(target, paused), date(2026, 1, 12, 13, 0); !got.Equal(want) {
+		t.Errorf("resumed target = %s, want %s", got, want)
+	}
+}
+
+func TestBusinessHoursNilCalendar(t *testing.T) {
+	bh, err := newBusinessHours(nil)
+	if err != nil || bh != nil {
+		t.Fatalf("newBusinessHours(nil) = %v, %v, want a nil calendar", bh, err)
+	}
+
+	saturday := date(2026, 1, 10, 22, 0)
+	if got, want := bh.Add(saturday, 3*time.Hour), date(2026, 1, 11, 1, 0); !got.Equal(want) {
+		t.Errorf("Add(%s, 3h) = %s, want %s", saturday, got, want)
+	}
+	if got := bh.Between(saturday, saturday.Add(3*time.Hour)); got != 3*time.Hour {
+		t.Errorf("Between over 3h = %s, want 3h", got)
+	}
+	if got := bh.Between(saturday, saturday.Add(-time.Hour)); got != 0 {
+		t.Errorf("Between backwards = %s, want 0", got)
+	}
+}
+
+func TestParseWorkingHoursInvalid(t *testing.T) {
+	tests := []map[string]interface{}{
+		{},
+		{"someday": map[string]interface{}{"start": "09:00", "end": "17:00"}},
+		{"monday": "09:00-17:00"},
+		{"monday": map[string]interface{}{"start": "9am", "end": "17:00"}},
+		{"monday": map[string]interface{}{"start": "17:00", "end": "09:00"}},
+	}
+	for _, hours := range tests {
+		if _, err := parseWorkingHours(hours); err == nil {
+			t.Errorf("parseWorkingHours(%v) succeeded, want an error", hours)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// SurveySettings configures the links satisfaction surveys are answered through
+type SurveySettings struct {
+	Secret    string // Signs survey links
+	SurveyURL string // Page that shows a survey; links open SurveyURL?token=... Empty sends no surveys.
+}
+
+// CSATService sends satisfaction surveys when tickets are resolved and records the
+// answers. Reopening a ticket on a bad rating happens in the ticket service, which
+// answers go through.
+type CSATService interface {
+	GetSettings(tenantID string) (*tenant_models.CSATSettings, error)
+	UpdateSettings(userID, tenantID string, req *tenant_models.CSATSettingsRequest) (*tenant_models.CSATSettings, error)
+	ListSurveys(tenantID string, filters repositories.CSATSurveyFilters, limit, offset int) ([]*tenant_models.CSATSurvey, int64, error)
+
+	SendSurvey(tenantID string, ticket *tenant_models.Ticket) error
+	OpenSurvey(token string) (string, *tenant_models.CSATSurvey, error)
+	RecordAnswer(tenantID string, survey *tenant_models.CSATSurvey, req *tenant_models.CSATAnswerRequest) (*tenant_models.CSATSurvey, *tenant_models.CSATSettings, error)
+	MarkReopened(tenantID string, survey *tenant_models.CSATSurvey) error
+}
+
+type csatService struct {
+	repo      repositories.CSATRepository
+	workflows WorkflowService
+	replies   EmailReplyService
+	settings  SurveySettings
+	logger    *zap.Logger
+}
+
+func NewCSATService(repo repositories.CSATRepository, workflows WorkflowService, replies EmailReplyService, settings SurveySettings, logger *zap.Logger) CSATService {
+	return &csatService{
+		repo:      repo,
+		workflows: workflows,
+		replies:   replies,
+		settings:  settings,
+		logger:    logger,
+	}
+}
+
+func (s *csatService) GetSettings(tenantID string) (*tenant_models.CSATSettings, error) {
+	settings, err := s.repo.GetSettings(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CSAT settings: %w", err)
+	}
+	return settings, nil
+}
+
+func (s *csatService) UpdateSettings(userID, tenantID string, req *tenant_models.CSATSettingsRequest) (*tenant_models.CSATSettings, error) {
+	settings, err := s.GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+	if req.ReopenAtOrBelow != nil {
+		settings.ReopenAtOrBelow = *req.ReopenAtOrBelow
+	}
+	if req.ReopenStatus != nil {
+		settings.ReopenStatus = *req.ReopenStatus
+	}
+	if req.ResponseDays != nil {
+		settings.ResponseDays = *req.ResponseDays
+	}
+
+	// A bad rating has to move the ticket back into work
+	category, err := s.workflows.StatusCategory(tenantID, tenant_models.TicketStatus(settings.ReopenStatus))
+	if err != nil {
+		return nil, err
+	}
+	if category == tenant_models.StatusCategoryResolved || category == tenant_models.StatusCategoryClosed {
+		return nil, validationError("reopen_status must be an open or in-progress status")
+	}
+	if settings.Enabled && s.settings.SurveyURL == "" {
+		return nil, validationError("surveys cannot be enabled until a survey page URL is configured")
+	}
+
+	settings.UpdatedBy = userID
+	if err := s.repo.SaveSettings(tenantID, settings); err != nil {
+		return nil, fmt.Errorf("failed to save CSAT settings: %w", err)
+	}
+
+	s.logger.Info("CSAT settings updated",
+		zap.String("tenant_id", tenantID),
+		zap.Bool("enabled", settings.Enabled),
+		zap.String("updated_by", userID))
+
+	return settings, nil
+}
+
+func (s *csatService) ListSurveys(tenantID string, filters repositories.CSATSurveyFilters, limit, offset int) ([]*tenant_models.CSATSurvey, int64, error) {
+	surveys, total, err := s.repo.ListSurveys(tenantID, filters, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list CSAT surveys: %w", err)
+	}
+	return surveys, total, nil
+}
+
+// SendSurvey emails the customer a survey for the ticket just resolved. Tenants with
+// surveys off and tickets without a customer address get none.
+func (s *csatService) SendSurvey(tenantID string, ticket *tenant_models.Ticket) error {
+	if ticket.CustomerEmail == "" || s.settings.SurveyURL == "" {
+		return nil
+	}
+	settings, err := s.GetSettings(tenantID)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled {
+		return nil
+	}
+
+	now := time.Now()
+	survey := &tenant_models.CSATSurvey{
+		TicketID:      ticket.ID,
+		AgentID:       ticket.AssigneeID,
+		ProjectID:     ticket.ProjectID,
+		CustomerEmail: ticket.CustomerEmail,
+		ExpiresAt:     now.AddDate(0, 0, settings.ResponseDays),
+	}
+	if err := s.repo.CreateSurvey(tenantID, survey); err != nil {
+		return fmt.Errorf("failed to create CSAT survey: %w", err)
+	}
+
+	// The ticket token in the subject threads any emailed reply onto the ticket
+	subject := fmt.Sprintf("[#%d] How did we do?", ticket.TicketNumber)
+	to := []mail.Address{{Name: ticket.CustomerName, Address: ticket.CustomerEmail}}
+	if err := s.replies.SendNotification(tenantID, to, subject, s.surveyEmail(tenantID, survey, ticket)); err != nil {
+		return fmt.Errorf("failed to email CSAT survey: %w", err)
+	}
+
+	survey.SentAt = &now
+	if err := s.repo.UpdateSurvey(tenantID, survey); err != nil {
+		return fmt.Errorf("failed to mark CSAT survey sent: %w", err)
+	}
+
+	s.logger.Info("CSAT survey sent",
+		zap.String("tenant_id", tenantID),
+		zap.String("ticket_id", ticket.ID),
+		zap.String("survey_id", survey.ID))
+	return nil
+}
+
+func (s *csatService) surveyEmail(tenantID string, survey *tenant_models.CSATSurvey, ticket *tenant_models.Ticket) string {
+	link := s.settings.SurveyURL + "?token=" + url.QueryEscape(s.signSurvey(tenantID, survey.ID))
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Your request \"%s\" has been resolved. How satisfied are you with the help you received?\n\n", ticket.Title)
+	labels := []string{"Very dissatisfied", "Dissatisfied", "Neutral", "Satisfied", "Very satisfied"}
+	for rating := tenant_models.CSATMaxRating; rating >= tenant_models.CSATMinRating; rating-- {
+		fmt.Fprintf(&body, "%d - %s: %s&rating=%d\n", rating, labels[rating-1], link, rating)
+	}
+	fmt.Fprintf(&body, "\nYou can add a comment on the same page. The link works once and expires on %s.",
+		survey.ExpiresAt.UTC().Format("2 January 2006"))
+	return body.String()
+}
+
+// signSurvey builds the survey link's token: tenant and survey IDs with an HMAC over both
+func (s *csatService) signSurvey(tenantID, surveyID string) string {
+	payload := tenantID + "." + surveyID
+	return payload + "." + s.signature(payload)
+}
+
+func (s *csatService) signature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.settings.Secret))
+	mac.Write([]byte("csat-survey:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// OpenSurvey checks a survey link's token and returns the tenant ID and survey. Tokens
+// that do not verify are reported as not found.
+func (s *csatService) OpenSurvey(token string) (string, *tenant_models.CSATSurvey, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || s.settings.Secret == "" {
+		return "", nil, gorm.ErrRecordNotFound
+	}
+	tenantID, surveyID := parts[0], parts[1]
+	expected := s.signature(tenantID + "." + surveyID)
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return "", nil, gorm.ErrRecordNotFound
+	}
+
+	survey, err := s.repo.GetSurvey(tenantID, surveyID)
+	if err != nil {
+		return "", nil, err
+	}
+	return tenantID, survey, nil
+}
+
+// RecordAnswer stores the customer's rating; each survey takes one answer before it expires
+func (s *csatService) RecordAnswer(tenantID string, survey *tenant_models.CSATSurvey, req *tenant_models.CSATAnswerRequest) (*tenant_models.CSATSurvey, *tenant_models.CSATSettings, error) {
+	if req.Rating < tenant_models.CSATMinRating || req.Rating > tenant_models.CSATMaxRating {
+		return nil, nil, validationError("rating must be between %d and %d", tenant_models.CSATMinRating, tenant_models.CSATMaxRating)
+	}
+	if survey.RespondedAt != nil {
+		return nil, nil, validationError("this survey has already been answered")
+	}
+	now := time.Now()
+	if now.After(survey.ExpiresAt) {
+		return nil, nil, validationError("this survey has expired")
+	}
+
+	recorded, err := s.repo.RecordAnswer(tenantID, survey.ID, req.Rating, strings.TrimSpace(req.Comment), now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to record CSAT answer: %w", err)
+	}
+	if !recorded {
+		return nil, nil, validationError("this survey has already been answered")
+	}
+
+	answered, err := s.repo.GetSurvey(tenantID, survey.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get CSAT survey: %w", err)
+	}
+	settings, err := s.GetSettings(tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("CSAT survey answered",
+		zap.String("tenant_id", tenantID),
+		zap.String("survey_id", survey.ID),
+		zap.Int("rating", req.Rating))
+	return answered, settings, nil
+}
+
+func (s *csatService) MarkReopened(tenantID string, survey *tenant_models.CSATSurvey) error {
+	survey.Reopened = true
+	if err := s.repo.UpdateSurvey(tenantID, survey); err != nil {
+		return fmt.Errorf("failed to update CSAT survey: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,695 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// maxCustomFieldText caps the length of text field values
+const maxCustomFieldText = 4000
+
+// indexedCustomFieldTypes get an expression index for sorting and range filters; the
+// other types are filtered through the GIN index on custom_fields alone
+var indexedCustomFieldTypes = map[tenant_models.CustomFieldType]bool{
+	tenant_models.CustomFieldNumber: true,
+	tenant_models.CustomFieldDate:   true,
+	tenant_models.CustomFieldSelect: true,
+	tenant_models.CustomFieldUser:   true,
+}
+
+// CustomFieldCheck says how CheckValues treats a ticket's custom fields
+type CustomFieldCheck struct {
+	Changed  []string // Keys whose values were set or changed; only these are validated
+	Defaults bool     // Fill in defaults for fields without a value, for new tickets
+	Required bool     // Reject the ticket when a field it requires has no value
+}
+
+// CustomFieldService manages the tenant's typed custom fields and checks the values
+// tickets give them
+type CustomFieldService interface {
+	ListFields(userID, tenantID, projectID string, includeInactive bool) ([]*tenant_models.CustomFieldDefinition, error)
+	CreateField(userID, tenantID string, req *tenant_models.CustomFieldCreateRequest) (*tenant_models.CustomFieldDefinition, error)
+	GetField(userID, tenantID, fieldID string) (*tenant_models.CustomFieldDefinition, error)
+	UpdateField(userID, tenantID, fieldID string, req *tenant_models.CustomFieldUpdateRequest) (*tenant_models.CustomFieldDefinition, error)
+	DeleteField(userID, tenantID, fieldID string) error
+
+	// Definitions returns the active fields by key
+	Definitions(tenantID string) (map[string]*tenant_models.CustomFieldDefinition, error)
+	// CheckValues validates and normalizes the ticket's custom field values in place
+	CheckValues(tenantID string, ticket *tenant_models.Ticket, check CustomFieldCheck) error
+
+	// ParseFilters turns custom field query parameters, keyed by field key, into filters
+	ParseFilters(actor Actor, params map[string]string) ([]repositories.CustomFieldFilter, error)
+	// ParseSort checks a ticket sort field, built in or custom_fields.<key>
+	ParseSort(actor Actor, field string, descending bool) (*repositories.TicketSort, error)
+}
+
+type customFieldService struct {
+	repo   repositories.CustomFieldRepository
+	users  repositories.UserRepository
+	logger *zap.Logger
+}
+
+func NewCustomFieldService(repo repositories.CustomFieldRepository, users repositories.UserRepository, logger *zap.Logger) CustomFieldService {
+	return &customFieldService{
+		repo:   repo,
+		users:  users,
+		logger: logger,
+	}
+}
+
+func (s *customFieldService) ListFields(userID, tenantID, projectID string, includeInactive bool) ([]*tenant_models.CustomFieldDefinition, error) {
+	fields, err := s.repo.List(tenantID, !includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	if projectID == "" {
+		return fields, nil
+	}
+
+	applicable := make([]*tenant_models.CustomFieldDefinition, 0, len(fields))
+	for _, field := range fields {
+		if field.AppliesTo(projectID) {
+			applicable = append(applicable, field)
+		}
+	}
+	return applicable, nil
+}
+
+func (s *customFieldService) CreateField(userID, tenantID string, req *tenant_models.CustomFieldCreateRequest) (*tenant_models.CustomFieldDefinition, error) {
+	key := strings.TrimSpace(req.Key)
+	if !repositories.ValidCustomFieldKey(key) {
+		return nil, validationError("key must start with a lowercase letter and hold only lowercase letters, digits and underscores, at most 48")
+	}
+	existing, err := s.repo.List(tenantID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	for _, field := range existing {
+		if field.Key == key {
+			return nil, validationError("a custom field with key %q already exists", key)
+		}
+	}
+
+	field := &tenant_models.CustomFieldDefinition{
+		Key:                 key,
+		Name:                strings.TrimSpace(req.Name),
+		Description:         req.Description,
+		FieldType:           req.FieldType,
+		Options:             models.StringArray(req.Options),
+		Required:            req.Required,
+		RequiredTicketTypes: models.StringArray(req.RequiredTicketTypes),
+		DefaultValue:        req.DefaultValue,
+		ProjectIDs:          models.StringArray(req.ProjectIDs),
+		Position:            req.Position,
+		IsActive:            true,
+		CreatedBy:           userID,
+	}
+	if err := s.validateField(tenantID, field); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(tenantID, field); err != nil {
+		return nil, fmt.Errorf("failed to create custom field: %w", err)
+	}
+
+	if indexedCustomFieldTypes[field.FieldType] {
+		go s.buildIndex(tenantID, field.Key)
+	}
+
+	s.logger.Info("Custom field created",
+		zap.String("field_id", field.ID),
+		zap.String("key", field.Key),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	return field, nil
+}
+
+// buildIndex runs after the field is created, as building the index can take a while
+func (s *customFieldService) buildIndex(tenantID, key string) {
+	if err := s.repo.CreateIndex(tenantID, key); err != nil {
+		s.logger.Warn("Failed to index custom field",
+			zap.String("key", key),
+			zap.String("tenant_id", tenantID),
+			zap.Error(err))
+	}
+}
+
+func (s *customFieldService) GetField(userID, tenantID, fieldID string) (*tenant_models.CustomFieldDefinition, error) {
+	return s.repo.GetByID(tenantID, fieldID)
+}
+
+// UpdateField changes a field's settings; its key and type stay as created, since
+// tickets already store values under them
+func (s *customFieldService) UpdateField(userID, tenantID, fieldID string, req *tenant_models.CustomFieldUpdateRequest) (*tenant_models.CustomFieldDefinition, error) {
+	field, err := s.repo.GetByID(tenantID, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		field.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		field.Description = *req.Description
+	}
+	if req.Options != nil {
+		field.Options = models.StringArray(*req.Options)
+	}
+	if req.Required != nil {
+		field.Required = *req.Required
+	}
+	if req.RequiredTicketTypes != nil {
+		field.RequiredTicketTypes = models.StringArray(*req.RequiredTicketTypes)
+	}
+	if req.ClearDefault {
+		field.DefaultValue = nil
+	} else if req.DefaultValue != nil {
+		field.DefaultValue = req.DefaultValue
+	}
+	if req.ProjectIDs != nil {
+		field.ProjectIDs = models.StringArray(*req.ProjectIDs)
+	}
+	if req.Position != nil {
+		field.Position = *req.Position
+	}
+	if req.IsActive != nil {
+		field.IsActive = *req.IsActive
+	}
+
+	if err := s.validateField(tenantID, field); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(tenantID, field); err != nil {
+		return nil, fmt.Errorf("failed to update custom field: %w", err)
+	}
+
+	s.logger.Info("Custom field updated",
+		zap.String("field_id", field.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("updated_by", userID))
+
+	return field, nil
+}
+
+// DeleteField removes the definition; tickets keep their values as untyped fields
+func (s *customFieldService) DeleteField(userID, tenantID, fieldID string) error {
+	field, err := s.repo.GetByID(tenantID, fieldID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(tenantID, fieldID); err != nil {
+		return err
+	}
+
+	if indexedCustomFieldTypes[field.FieldType] {
+		go func() {
+			if err := s.repo.DropIndex(tenantID, field.Key); err != nil {
+				s.logger.Warn("Failed to drop custom field index",
+					zap.String("key", field.Key),
+					zap.String("tenant_id", tenantID),
+					zap.Error(err))
+			}
+		}()
+	}
+
+	s.logger.Info("Custom field deleted",
+		zap.String("field_id", fieldID),
+		zap.String("key", field.Key),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+// validateField checks a definition and normalizes its lists and default in place
+func (s *customFieldService) validateField(tenantID string, field *tenant_models.CustomFieldDefinition) error {
+	if field.Name == "" {
+		return validationError("name is required")
+	}
+
+	options, err := uniqueValues("options", field.Options, true)
+	if err != nil {
+		return err
+	}
+	field.Options = options
+	isSelect := field.FieldType == tenant_models.CustomFieldSelect || field.FieldType == tenant_models.CustomFieldMultiSelect
+	switch {
+	case isSelect && len(field.Options) == 0:
+		return validationError("%s fields need at least one option", field.FieldType)
+	case !isSelect && len(field.Options) > 0:
+		return validationError("only select and multi-select fields take options")
+	}
+
+	if field.RequiredTicketTypes, err = uniqueValues("required_ticket_types", field.RequiredTicketTypes, false); err != nil {
+		return err
+	}
+	if field.ProjectIDs, err = uniqueValues("project_ids", field.ProjectIDs, false); err != nil {
+		return err
+	}
+	for _, projectID := range field.ProjectIDs {
+		if _, err := uuid.Parse(projectID); err != nil {
+			return validationError("project_ids: %q is not an ID", projectID)
+		}
+	}
+
+	if field.DefaultValue != nil {
+		value, err := normalizeCustomFieldValue(field, field.DefaultValue)
+		if err != nil {
+			return validationError("default_value: %s", err)
+		}
+		if value != nil && field.FieldType == tenant_models.CustomFieldUser {
+			if err := s.checkMembers(tenantID, map[string][]string{field.Key: {value.(string)}}); err != nil {
+				return err
+			}
+		}
+		field.DefaultValue = value
+	}
+	return nil
+}
+
+// uniqueValues trims the entries and rejects blanks and duplicates
+func uniqueValues(name string, values models.StringArray, ignoreCase bool) (models.StringArray, error) {
+	seen := map[string]bool{}
+	cleaned := models.StringArray{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, validationError("%s may not hold blank entries", name)
+		}
+		seenAs := value
+		if ignoreCase {
+			seenAs = strings.ToLower(value)
+		}
+		if seen[seenAs] {
+			return nil, validationError("%s lists %q twice", name, value)
+		}
+		seen[seenAs] = true
+		cleaned = append(cleaned, value)
+	}
+	return cleaned, nil
+}
+
+func (s *customFieldService) Definitions(tenantID string) (map[string]*tenant_models.CustomFieldDefinition, error) {
+	fields, err := s.repo.List(tenantID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+
+	definitions := make(map[string]*tenant_models.CustomFieldDefinition, len(fields))
+	for _, field := range fields {
+		definitions[field.Key] = field
+	}
+	return definitions, nil
+}
+
+func (s *customFieldService) CheckValues(tenantID string, ticket *tenant_models.Ticket, check CustomFieldCheck) error {
+	fields, err := s.repo.List(tenantID, true)
+	if err != nil {
+		return fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if ticket.CustomFields == nil {
+		ticket.CustomFields = make(models.JSONB)
+	}
+
+	definitions := make(map[string]*tenant_models.CustomFieldDefinition, len(fields))
+	for _, field := range fields {
+		definitions[field.Key] = field
+	}
+	projectID := stringValue(ticket.ProjectID)
+
+	members := map[string][]string{}
+	for _, key := range check.Changed {
+		field, ok := definitions[key]
+		value, present := ticket.CustomFields[key]
+		if !ok || !present {
+			continue
+		}
+
+		normalized, err := normalizeCustomFieldValue(field, value)
+		if err != nil {
+			return validationError("custom field %s: %s", key, err)
+		}
+		if normalized == nil {
+			delete(ticket.CustomFields, key)
+			continue
+		}
+		if !field.AppliesTo(projectID) {
+			return validationError("custom field %s is not used in this ticket's project", key)
+		}
+		ticket.CustomFields[key] = normalized
+		if field.FieldType == tenant_models.CustomFieldUser {
+			members[key] = append(members[key], normalized.(string))
+		}
+	}
+	if err := s.checkMembers(tenantID, members); err != nil {
+		return err
+	}
+
+	if check.Defaults {
+		for _, field := range fields {
+			if _, present := ticket.CustomFields[field.Key]; !present && field.DefaultValue != nil && field.AppliesTo(projectID) {
+				ticket.CustomFields[field.Key] = field.DefaultValue
+			}
+		}
+	}
+
+	if check.Required {
+		var missing []string
+		for _, field := range fields {
+			if _, present := ticket.CustomFields[field.Key]; !present && field.AppliesTo(projectID) && field.RequiredFor(ticket.TicketType) {
+				missing = append(missing, field.Key)
+			}
+		}
+		if len(missing) > 0 {
+			return validationError("custom fields required for %s tickets: %s", ticket.TicketType, strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// checkMembers makes sure user fields point at members of the tenant
+func (s *customFieldService) checkMembers(tenantID string, userIDs map[string][]string) error {
+	var all []string
+	for _, ids := range userIDs {
+		all = append(all, ids...)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+
+	users, err := s.users.GetMembersByIDs(tenantID, all)
+	if err != nil {
+		return fmt.Errorf("failed to look up users: %w", err)
+	}
+	found := make(map[string]bool, len(users))
+	for _, user := range users {
+		found[user.ID] = true
+	}
+
+	keys := make([]string, 0, len(userIDs))
+	for key := range userIDs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, id := range userIDs[key] {
+			if !found[id] {
+				return validationError("custom field %s: %s is not a member of this workspace", key, id)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeCustomFieldValue checks a value against the field's type and returns it as
+// stored: strings, float64 numbers, YYYY-MM-DD dates, string lists and bools. Empty
+// values come back nil, which clears the field.
+func normalizeCustomFieldValue(field *tenant_models.CustomFieldDefinition, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if text, ok := value.(string); ok && strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	switch field.FieldType {
+	case tenant_models.CustomFieldText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expects text")
+		}
+		if len([]rune(text)) > maxCustomFieldText {
+			return nil, fmt.Errorf("is longer than %d characters", maxCustomFieldText)
+		}
+		return text, nil
+
+	case tenant_models.CustomFieldNumber:
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case int:
+			number = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("expects a number")
+			}
+			number = parsed
+		default:
+			return nil, fmt.Errorf("expects a number")
+		}
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("expects a finite number")
+		}
+		return number, nil
+
+	case tenant_models.CustomFieldDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expects a date as YYYY-MM-DD")
+		}
+		return parseCustomFieldDate(text)
+
+	case tenant_models.CustomFieldSelect:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expects one of its options")
+		}
+		return matchOption(field, text)
+
+	case tenant_models.CustomFieldMultiSelect:
+		items, ok := value.([]interface{})
+		if !ok {
+			if list, isList := value.([]string); isList {
+				for _, item := range list {
+					items = append(items, item)
+				}
+			} else {
+				return nil, fmt.Errorf("expects a list of its options")
+			}
+		}
+		seen := map[string]bool{}
+		selected := []string{}
+		for _, item := range items {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expects a list of its options")
+			}
+			option, err := matchOption(field, text)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[option] {
+				seen[option] = true
+				selected = append(selected, option)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, nil
+		}
+		return selected, nil
+
+	case tenant_models.CustomFieldUser:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expects a user ID")
+		}
+		id, err := uuid.Parse(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("expects a user ID")
+		}
+		return id.String(), nil
+
+	case tenant_models.CustomFieldCheckbox:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			checked, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("expects true or false")
+			}
+			return checked, nil
+		}
+		return nil, fmt.Errorf("expects true or false")
+	}
+	return nil, fmt.Errorf("has unknown type %q", field.FieldType)
+}
+
+// parseCustomFieldDate accepts a YYYY-MM-DD day or an RFC 3339 time, keeping the day
+func parseCustomFieldDate(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if day, err := time.Parse("2006-01-02", text); err == nil {
+		return day.Format("2006-01-02"), nil
+	}
+	if moment, err := time.Parse(time.RFC3339, text); err == nil {
+		return moment.Format("2006-01-02"), nil
+	}
+	return "", fmt.Errorf("expects a date as YYYY-MM-DD")
+}
+
+// matchOption finds the option a value names, ignoring case
+func matchOption(field *tenant_models.CustomFieldDefinition, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	for _, option := range field.Options {
+		if strings.EqualFold(option, value) {
+			return option, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of its options", value)
+}
+
+// changedCustomFields lists the keys whose values differ between two versions of a
+// ticket's custom fields, including keys that were removed
+func changedCustomFields(before, after models.JSONB) []string {
+	var changed []string
+	for key, value := range after {
+		if previous, ok := before[key]; !ok || !reflect.DeepEqual(previous, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func (s *customFieldService) ParseFilters(actor Actor, params map[string]string) ([]repositories.CustomFieldFilter, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	definitions, err := s.Definitions(actor.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := make([]repositories.CustomFieldFilter, 0, len(keys))
+	for _, key := range keys {
+		field, ok := definitions[key]
+		if !ok {
+			return nil, validationError("unknown custom field %q", key)
+		}
+		filter, err := customFieldFilter(actor, field, params[key])
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func (s *customFieldService) ParseSort(actor Actor, field string, descending bool) (*repositories.TicketSort, error) {
+	if field == "" {
+		return nil, nil
+	}
+	if _, ok := repositories.SortFields[field]; ok {
+		return &repositories.TicketSort{Field: field, Descending: descending}, nil
+	}
+
+	key := strings.TrimPrefix(field, customFieldPrefix)
+	if key != field {
+		definitions, err := s.Definitions(actor.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if definition, ok := definitions[key]; ok {
+			if definition.FieldType == tenant_models.CustomFieldMultiSelect {
+				return nil, validationError("multi-select fields cannot be sorted on")
+			}
+			return &repositories.TicketSort{Field: field, Descending: descending}, nil
+		}
+	}
+	return nil, validationError("unknown sort field %q", field)
+}
+
+// customFieldFilter builds a filter from an operator value: a comma-separated list of
+// values, "none" for tickets without one, and for number and date fields a from..to
+// range, either end of which may be left open. User fields accept "me".
+func customFieldFilter(actor Actor, field *tenant_models.CustomFieldDefinition, raw string) (repositories.CustomFieldFilter, error) {
+	filter := repositories.CustomFieldFilter{
+		Key:   field.Key,
+		Array: field.FieldType == tenant_models.CustomFieldMultiSelect,
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return filter, validationError("%s: needs a value", field.Key)
+	}
+
+	ranged := field.FieldType == tenant_models.CustomFieldNumber || field.FieldType == tenant_models.CustomFieldDate
+	if from, to, isRange := strings.Cut(raw, ".."); ranged && isRange {
+		if strings.TrimSpace(from) == "" && strings.TrimSpace(to) == "" {
+			return filter, validationError("%s: a range needs at least one end", field.Key)
+		}
+		for _, end := range []struct {
+			raw    string
+			target *interface{}
+		}{{from, &filter.From}, {to, &filter.To}} {
+			if strings.TrimSpace(end.raw) == "" {
+				continue
+			}
+			value, err := normalizeCustomFieldValue(field, end.raw)
+			if err != nil {
+				return filter, validationError("%s: %s", field.Key, err)
+			}
+			*end.target = value
+		}
+		return filter, nil
+	}
+
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+			continue
+		case strings.EqualFold(item, "none"):
+			filter.IsNull = true
+			continue
+		case strings.EqualFold(item, "me") && field.FieldType == tenant_models.CustomFieldUser:
+			item = actor.UserID
+		}
+
+		var value interface{}
+		var err error
+		if field.FieldType == tenant_models.CustomFieldMultiSelect {
+			value, err = matchOption(field, item)
+		} else {
+			value, err = normalizeCustomFieldValue(field, item)
+		}
+		if err != nil {
+			return filter, validationError("%s: %s", field.Key, err)
+		}
+		filter.Values = append(filter.Values, value)
+	}
+	if len(filter.Values) == 0 && !filter.IsNull {
+		return filter, validationError("%s: needs a value", field.Key)
+	}
+	return filter, nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+// replyMarker heads every outbound reply so the customer's answer can be cut from the quoted thread
+const replyMarker = "##- Please type your reply above this line -##"
+
+// maxMIMEDepth bounds how deeply nested multiparts are walked
+const maxMIMEDepth = 10
+
+// inboundEmail is the part of a raw RFC 5322 message the email channel uses
+type inboundEmail struct {
+	MessageID  string
+	InReplyTo  string
+	References []string
+	From       *mail.Address
+	Recipients []string // Envelope-ish recipient headers first, then To and Cc
+	Subject    string
+
+	Text        string // Plain text body, or text extracted from the HTML body
+	Attachments []emailAttachment
+
+	// Auto-replies, bounces and list mail must not open tickets or they can loop
+	AutoGenerated bool
+}
+
+type emailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// parseEmail reads the headers, body and attachments of a raw message
+func parseEmail(raw []byte) (*inboundEmail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := new(mime.WordDecoder)
+	email := &inboundEmail{
+		MessageID:  firstMessageID(msg.Header.Get("Message-ID")),
+		InReplyTo:  firstMessageID(msg.Header.Get("In-Reply-To")),
+		References: messageIDs(msg.Header.Get("References")),
+	}
+
+	from, err := msg.Header.AddressList("From")
+	if err != nil || len(from) == 0 {
+		return nil, fmt.Errorf("missing or invalid From header")
+	}
+	email.From = from[0]
+
+	// A message without an ID still needs a stable one for deduplication
+	if email.MessageID == "" {
+		sum := sha256.Sum256(raw)
+		email.MessageID = hex.EncodeToString(sum[:16]) + "@generated.invalid"
+	}
+
+	if subject, err := decoder.DecodeHeader(msg.Header.Get("Subject")); err == nil {
+		email.Subject = strings.TrimSpace(subject)
+	} else {
+		email.Subject = strings.TrimSpace(msg.Header.Get("Subject"))
+	}
+
+	for _, header := range []string{"X-Original-To", "Delivered-To", "To", "Cc"} {
+		addresses, err := msg.Header.AddressList(header)
+		if err != nil {
+			continue
+		}
+		for _, address := range addresses {
+			email.Recipients = append(email.Recipients, address.Address)
+		}
+	}
+
+	email.AutoGenerated = isAutoGenerated(msg.Header)
+
+	var htmlBody string
+	err = walkMIMEPart(textproto.MIMEHeader(msg.Header), msg.Body, 0, func(part mimePart) {
+		switch {
+		case part.attachment:
+			email.Attachments = append(email.Attachments, emailAttachment{
+				Filename:    part.filename,
+				ContentType: part.mediaType,
+				Data:        part.data,
+			})
+		case part.mediaType == "text/plain" && email.Text == "":
+			email.Text = decodeCharset(part.data, part.charset)
+		case part.mediaType == "text/html" && htmlBody == "":
+			htmlBody = decodeCharset(part.data, part.charset)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(email.Text) == "" && htmlBody != "" {
+		email.Text = htmlToText(htmlBody)
+	}
+	email.Text = strings.ReplaceAll(email.Text, "\r\n", "\n")
+
+	return email, nil
+}
+
+// mimePart is a decoded leaf of the MIME tree
+type mimePart struct {
+	mediaType  string
+	charset    string
+	filename   string
+	attachment bool
+	data       []byte
+}
+
+// walkMIMEPart decodes a part and hands each leaf to visit, descending into multiparts
+func walkMIMEPart(header textproto.MIMEHeader, body io.Reader, depth int, visit func(mimePart)) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") && depth < maxMIMEDepth {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read MIME part: %w", err)
+			}
+			if err := walkMIMEPart(part.Header, part, depth+1, visit); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(transferDecoder(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("failed to decode MIME part: %w", err)
+	}
+
+	part := mimePart{
+		mediaType: mediaType,
+		charset:   params["charset"],
+		data:      data,
+	}
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	part.filename = dispositionParams["filename"]
+	if part.filename == "" {
+		part.filename = params["name"]
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(part.filename); err == nil {
+		part.filename = decoded
+	}
+
+	// Named parts and anything that is not a text body become attachments
+	isBody := mediaType == "text/plain" || mediaType == "text/html"
+	part.attachment = disposition == "attachment" || part.filename != "" || !isBody
+	if part.attachment && part.filename == "" {
+		part.filename = "attachment"
+		if extensions, _ := mime.ExtensionsByType(mediaType); len(extensions) > 0 {
+			part.filename += extensions[0]
+		} else if mediaType == "message/rfc822" {
+			part.filename += ".eml"
+		}
+	}
+	if part.attachment && len(data) == 0 {
+		return nil
+	}
+
+	visit(part)
+	return nil
+}
+
+func transferDecoder(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{source: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// base64Cleaner drops the line breaks and stray whitespace mail bodies wrap base64 in
+type base64Cleaner struct {
+	source io.Reader
+}
+
+func (r *base64Cleaner) Read(p []byte) (int, error) {
+	for {
+		n, err := r.source.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// decodeCharset converts single-byte Latin text to UTF-8; other charsets are passed through
+func decodeCharset(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return string(data)
+	}
+}
+
+func isAutoGenerated(header mail.Header) bool {
+	if value := strings.ToLower(header.Get("Auto-Submitted")); value != "" && value != "no" {
+		return true
+	}
+	switch strings.ToLower(header.Get("Precedence")) {
+	case "bulk", "junk", "list", "auto_reply":
+		return true
+	}
+	return header.Get("X-Autoreply") != "" || header.Get("X-Autorespond") != ""
+}
+
+// messageIDs extracts the IDs of a References-style header without their angle brackets
+func messageIDs(value string) []string {
+	var ids []string
+	for _, field := range strings.Fields(value) {
+		if id := strings.Trim(field, "<>,"); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func firstMessageID(value string) string {
+	if ids := messageIDs(value); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+var (
+	htmlDropped    = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlBlockEnd   = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|blockquote)>`)
+	htmlTag        = regexp.MustCompile(`<[^>]*>`)
+	htmlBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText keeps the readable text of an HTML-only message
+func htmlToText(body string) string {
+	body = htmlDropped.ReplaceAllString(body, "")
+	body = htmlBlockEnd.ReplaceAllString(body, "\n")
+	body = htmlTag.ReplaceAllString(body, "")
+	body = html.UnescapeString(body)
+
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(htmlBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+var (
+	quoteAttribution = regexp.MustCompile(`(?i)^on\s.+\swrote:$`)
+	quoteSeparator   = regexp.MustCompile(`(?i)^(-{2,}\s*original message\s*-{2,}|_{10,}|-{2,}\s*forwarded message\s*-{2,})$`)
+)
+
+// stripQuotedReply keeps only what the sender wrote: everything above our reply marker,
+// above an "On ... wrote:" attribution or Outlook separator, without quoted lines or signature
+func stripQuotedReply(text string) string {
+	if i := strings.Index(text, replyMarker); i >= 0 {
+		text = text[:i]
+	}
+
+	lines := strings.Split(text, "\n")
+	kept := make([]string, 0, len(lines))
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		// Attributions are often wrapped over two lines
+		joined := trimmed
+		if i+1 < len(lines) {
+			joined = trimmed + " " + strings.TrimSpace(lines[i+1])
+		}
+		if quoteSeparator.MatchString(trimmed) || quoteAttribution.MatchString(trimmed) ||
+			(strings.HasPrefix(strings.ToLower(trimmed), "on ") && quoteAttribution.MatchString(joined)) {
+			break
+		}
+		if line == "-- " || trimmed == "--" {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+
+	stripped := strings.TrimSpace(strings.Join(kept, "\n"))
+	if stripped == "" {
+		// Better a quoted message than an empty one
+		return strings.TrimSpace(text)
+	}
+	return stripped
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// maxReferences caps the References header on long threads; the oldest IDs are dropped first
+const maxReferences = 20
+
+// EmailSettings configures the addresses the email channel uses
+type EmailSettings struct {
+	InboundDomain string // Tenants receive mail at <subdomain>.<InboundDomain>
+	FromAddress   string // Defaults to the tenant mailbox
+	FromName      string // Defaults to the tenant name
+}
+
+// tenantMailDomain is the domain a tenant receives mail on
+func (s EmailSettings) tenantMailDomain(tenant *models.Tenant) string {
+	return strings.ToLower(tenant.Subdomain) + "." + s.InboundDomain
+}
+
+// tenantMailbox is the address customers reply to
+func (s EmailSettings) tenantMailbox(tenant *models.Tenant) string {
+	return "support@" + s.tenantMailDomain(tenant)
+}
+
+// EmailReplyService sends ticket comments to customers as replies on the ticket's email thread,
+// and standalone notifications from the tenant's support address
+type EmailReplyService interface {
+	SendCommentReply(tenantID string, ticket *tenant_models.Ticket, comment *tenant_models.Comment) error
+	SendNotification(tenantID string, to []mail.Address, subject, body string) error
+}
+
+type emailReplyService struct {
+	tenants  repositories.TenantRepository
+	messages repositories.EmailMessageRepository
+	mailer   Mailer
+	settings EmailSettings
+	logger   *zap.Logger
+}
+
+func NewEmailReplyService(tenants repositories.TenantRepository, messages repositories.EmailMessageRepository, mailer Mailer, settings EmailSettings, logger *zap.Logger) EmailReplyService {
+	settings.InboundDomain = strings.ToLower(settings.InboundDomain)
+	return &emailReplyService{
+		tenants:  tenants,
+		messages: messages,
+		mailer:   mailer,
+		settings: settings,
+		logger:   logger,
+	}
+}
+
+// SendCommentReply is a no-op when outbound mail or the inbound domain replies route to is not configured
+func (s *emailReplyService) SendCommentReply(tenantID string, ticket *tenant_models.Ticket, comment *tenant_models.Comment) error {
+	if !s.mailer.Enabled() || s.settings.InboundDomain == "" || ticket.CustomerEmail == "" {
+		return nil
+	}
+
+	tenant, err := s.tenants.GetByID(tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+	thread, err := s.messages.ListByTicket(tenantID, ticket.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get email thread: %w", err)
+	}
+
+	email := &OutboundEmail{
+		From:      s.sender(tenant),
+		To:        mail.Address{Name: ticket.CustomerName, Address: ticket.CustomerEmail},
+		ReplyTo:   s.settings.tenantMailbox(tenant),
+		Subject:   fmt.Sprintf("Re: [#%d] %s", ticket.TicketNumber, ticket.Title),
+		MessageID: fmt.Sprintf("comment-%s@%s", comment.ID, s.settings.tenantMailDomain(tenant)),
+		Body:      replyMarker + "\n\n" + comment.Body + "\n",
+	}
+	for _, message := range thread {
+		email.References = append(email.References, message.MessageID)
+	}
+	if len(email.References) > 0 {
+		email.InReplyTo = email.References[len(email.References)-1]
+	}
+	if len(email.References) > maxReferences {
+		email.References = email.References[len(email.References)-maxReferences:]
+	}
+
+	if err := s.mailer.Send(email); err != nil {
+		return err
+	}
+
+	commentID := comment.ID
+	record := &tenant_models.EmailMessage{
+		TicketID:    ticket.ID,
+		CommentID:   &commentID,
+		Direction:   tenant_models.EmailOutbound,
+		MessageID:   email.MessageID,
+		InReplyTo:   email.InReplyTo,
+		References:  strings.Join(email.References, " "),
+		FromAddress: email.From.Address,
+		ToAddress:   email.To.Address,
+		Subject:     email.Subject,
+	}
+	if err := s.messages.Create(tenantID, record); err != nil {
+		return fmt.Errorf("failed to record sent email: %w", err)
+	}
+
+	s.logger.Info("Comment emailed to customer",
+		zap.String("ticket_id", ticket.ID),
+		zap.String("comment_id", comment.ID),
+		zap.String("tenant_id", tenantID))
+	return nil
+}
+
+// SendNotification emails each recipient separately, so addresses are not disclosed to one another
+func (s *emailReplyService) SendNotification(tenantID string, to []mail.Address, subject, body string) error {
+	if !s.mailer.Enabled() {
+		return fmt.Errorf("email delivery is not configured")
+	}
+	if s.settings.FromAddress == "" && s.settings.InboundDomain == "" {
+		return fmt.Errorf("no sender address is configured")
+	}
+
+	tenant, err := s.tenants.GetByID(tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+	from := s.sender(tenant)
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	for _, recipient := range to {
+		email := &OutboundEmail{
+			From:      from,
+			To:        recipient,
+			Subject:   subject,
+			MessageID: fmt.Sprintf("notification-%s@%s", uuid.NewString(), domain),
+			Body:      body + "\n",
+		}
+		if err := s.mailer.Send(email); err != nil {
+			return fmt.Errorf("failed to email %s: %w", recipient.Address, err)
+		}
+	}
+	return nil
+}
+
+// sender is the configured From address, falling back to the tenant name and mailbox
+func (s *emailReplyService) sender(tenant *models.Tenant) mail.Address {
+	from := mail.Address{Name: s.settings.FromName, Address: s.settings.FromAddress}
+	if from.Name == "" {
+		from.Name = tenant.Name
+	}
+	if from.Address == "" {
+		from.Address = s.settings.tenantMailbox(tenant)
+	}
+	return from
+}
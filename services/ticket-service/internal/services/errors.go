@@ -0,0 +1,24 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrValidation marks errors caused by invalid client input
+	ErrValidation = errors.New("validation failed")
+
+	// ErrAccessDenied marks authorization failures; the wrapped message explains why
+	ErrAccessDenied = errors.New("access denied")
+)
+
+// validationError wraps a message with ErrValidation so handlers can answer 400
+func validationError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrValidation, fmt.Sprintf(format, args...))
+}
+
+// accessDenied wraps a reason with ErrAccessDenied so handlers can answer 403
+func accessDenied(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrAccessDenied, fmt.Sprintf(format, args...))
+}
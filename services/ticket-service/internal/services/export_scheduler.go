@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"ticket-service/internal/repositories"
+)
+
+// ExportScheduler periodically runs every active tenant's due saved exports
+type ExportScheduler struct {
+	tenants  repositories.TenantRepository
+	exports  ExportService
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewExportScheduler(tenants repositories.TenantRepository, exports ExportService, interval time.Duration, logger *zap.Logger) *ExportScheduler {
+	return &ExportScheduler{
+		tenants:  tenants,
+		exports:  exports,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start runs the scheduler until ctx is cancelled; a non-positive interval disables it
+func (s *ExportScheduler) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		s.logger.Info("Scheduled exports disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce works through the tenants in turn; one tenant failing does not hold up the rest
+func (s *ExportScheduler) runOnce(ctx context.Context) {
+	tenants, err := s.tenants.ListActive()
+	if err != nil {
+		s.logger.Error("Failed to list tenants for scheduled exports", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, tenant := range tenants {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.exports.RunDueSchedules(tenant.ID, now); err != nil {
+			s.logger.Warn("Scheduled exports failed",
+				zap.String("tenant_id", tenant.ID),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,632 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// exportBatchSize is how many tickets an export reads at a time
+const exportBatchSize = 500
+
+// ExportSettings configures where finished export files live and how they are delivered
+type ExportSettings struct {
+	StoragePath string        // Files are written under StoragePath/<tenant ID>/
+	DownloadURL string        // Download links open DownloadURL/<token>; empty emails no links
+	LinkSecret  string        // Signs download links
+	Retention   time.Duration // How long finished files are kept
+}
+
+// ExportOptions chooses the format of a ticket export and what goes in it
+type ExportOptions struct {
+	Format          tenant_models.ExportFormat
+	IncludeComments bool
+}
+
+// ExportService streams ticket exports and runs saved exports on their schedules,
+// keeping the files for download until they expire
+type ExportService interface {
+	// ExportTickets writes every ticket matching the filters that the actor may see and
+	// returns how many were written
+	ExportTickets(actor Actor, filters repositories.TicketFilters, options ExportOptions, w io.Writer) (int, error)
+
+	ListSchedules(actor Actor) ([]*tenant_models.ScheduledExport, error)
+	CreateSchedule(actor Actor, req *tenant_models.ScheduledExportCreateRequest) (*tenant_models.ScheduledExport, error)
+	GetSchedule(actor Actor, scheduleID string) (*tenant_models.ScheduledExport, error)
+	UpdateSchedule(actor Actor, scheduleID string, req *tenant_models.ScheduledExportUpdateRequest) (*tenant_models.ScheduledExport, error)
+	DeleteSchedule(actor Actor, scheduleID string) error
+	RunSchedule(actor Actor, scheduleID string) (*tenant_models.ExportFile, error)
+
+	ListFiles(actor Actor, scheduleID string, limit, offset int) ([]*tenant_models.ExportFile, int64, error)
+	OpenFile(actor Actor, fileID string) (*tenant_models.ExportFile, error)
+	OpenFileByToken(token string) (*tenant_models.ExportFile, error)
+
+	// RunDueSchedules runs the tenant's saved exports that are due and removes expired files
+	RunDueSchedules(tenantID string, now time.Time) error
+}
+
+type exportService struct {
+	repo     repositories.ExportRepository
+	tickets  repositories.TicketRepository
+	users    repositories.UserRepository
+	replies  EmailReplyService
+	policy   *ticketPolicy
+	settings ExportSettings
+	logger   *zap.Logger
+}
+
+func NewExportService(repo repositories.ExportRepository, tickets repositories.TicketRepository, members repositories.ProjectMemberRepository, users repositories.UserRepository, replies EmailReplyService, settings ExportSettings, logger *zap.Logger) ExportService {
+	return &exportService{
+		repo:     repo,
+		tickets:  tickets,
+		users:    users,
+		replies:  replies,
+		policy:   newTicketPolicy(members),
+		settings: settings,
+		logger:   logger,
+	}
+}
+
+func (s *exportService) ExportTickets(actor Actor, filters repositories.TicketFilters, options ExportOptions, w io.Writer) (int, error) {
+	if !validExportFormat(options.Format) {
+		return 0, validationError("format must be csv, ndjson or xlsx")
+	}
+
+	scope, err := s.policy.ListScope(actor)
+	if err != nil {
+		return 0, err
+	}
+	filters.Scope = scope
+
+	// Flat formats need every custom field as a column before the first row is written
+	var customFields []string
+	if options.Format != tenant_models.ExportFormatNDJSON {
+		customFields, err = s.tickets.CustomFieldKeys(actor.TenantID, filters)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list custom fields: %w", err)
+		}
+	}
+
+	writer, err := newExportWriter(options.Format, w, customFields, options.IncludeComments)
+	if err != nil {
+		return 0, err
+	}
+
+	includeInternal := s.policy.CanViewInternal(actor)
+	exported, afterNumber := 0, 0
+	for {
+		tickets, err := s.tickets.ListForExport(actor.TenantID, filters, afterNumber, exportBatchSize)
+		if err != nil {
+			return exported, fmt.Errorf("failed to list tickets: %w", err)
+		}
+		if len(tickets) == 0 {
+			break
+		}
+
+		comments := map[string][]*tenant_models.Comment{}
+		if options.IncludeComments {
+			ticketIDs := make([]string, len(tickets))
+			for i, ticket := range tickets {
+				ticketIDs[i] = ticket.ID
+			}
+			batch, err := s.tickets.GetCommentsForTickets(actor.TenantID, ticketIDs, includeInternal)
+			if err != nil {
+				return exported, fmt.Errorf("failed to list comments: %w", err)
+			}
+			for _, comment := range batch {
+				comments[comment.TicketID] = append(comments[comment.TicketID], comment)
+			}
+		}
+
+		for _, ticket := range tickets {
+			record := &tenant_models.TicketExportRecord{
+				TicketResponse: ticket.ToResponse(),
+				Comments:       comments[ticket.ID],
+			}
+			if err := writer.Write(record); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		afterNumber = tickets[len(tickets)-1].TicketNumber
+		if len(tickets) < exportBatchSize {
+			break
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return exported, err
+	}
+	return exported, nil
+}
+
+func validExportFormat(format tenant_models.ExportFormat) bool {
+	switch format {
+	case tenant_models.ExportFormatCSV, tenant_models.ExportFormatNDJSON, tenant_models.ExportFormatXLSX:
+		return true
+	}
+	return false
+}
+
+func (s *exportService) ListSchedules(actor Actor) ([]*tenant_models.ScheduledExport, error) {
+	schedules, err := s.repo.ListSchedules(actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled exports: %w", err)
+	}
+	return schedules, nil
+}
+
+func (s *exportService) CreateSchedule(actor Actor, req *tenant_models.ScheduledExportCreateRequest) (*tenant_models.ScheduledExport, error) {
+	schedule := &tenant_models.ScheduledExport{
+		Name:            strings.TrimSpace(req.Name),
+		Format:          req.Format,
+		Filter:          req.Filter,
+		IncludeComments: req.IncludeComments,
+		Frequency:       req.Frequency,
+		Weekday:         1,
+		Hour:            6,
+		Timezone:        "UTC",
+		Enabled:         true,
+		CreatedBy:       actor.UserID,
+	}
+	if req.Weekday != nil {
+		schedule.Weekday = *req.Weekday
+	}
+	if req.Hour != nil {
+		schedule.Hour = *req.Hour
+	}
+	if req.Timezone != "" {
+		schedule.Timezone = req.Timezone
+	}
+	schedule.Recipients = normalizeRecipients(req.Recipients)
+
+	if err := validateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = nextExportRun(schedule, time.Now())
+
+	if err := s.repo.CreateSchedule(actor.TenantID, schedule); err != nil {
+		return nil, fmt.Errorf("failed to create scheduled export: %w", err)
+	}
+
+	s.logger.Info("Scheduled export created",
+		zap.String("schedule_id", schedule.ID),
+		zap.String("tenant_id", actor.TenantID),
+		zap.String("created_by", actor.UserID))
+
+	return schedule, nil
+}
+
+func (s *exportService) GetSchedule(actor Actor, scheduleID string) (*tenant_models.ScheduledExport, error) {
+	return s.repo.GetSchedule(actor.TenantID, scheduleID)
+}
+
+func (s *exportService) UpdateSchedule(actor Actor, scheduleID string, req *tenant_models.ScheduledExportUpdateRequest) (*tenant_models.ScheduledExport, error) {
+	schedule, err := s.repo.GetSchedule(actor.TenantID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		schedule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Format != nil {
+		schedule.Format = *req.Format
+	}
+	if req.Filter != nil {
+		schedule.Filter = req.Filter
+	}
+	if req.IncludeComments != nil {
+		schedule.IncludeComments = *req.IncludeComments
+	}
+	if req.Frequency != nil {
+		schedule.Frequency = *req.Frequency
+	}
+	if req.Weekday != nil {
+		schedule.Weekday = *req.Weekday
+	}
+	if req.Hour != nil {
+		schedule.Hour = *req.Hour
+	}
+	if req.Timezone != nil {
+		schedule.Timezone = *req.Timezone
+	}
+	if req.Recipients != nil {
+		schedule.Recipients = normalizeRecipients(*req.Recipients)
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+
+	if err := validateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	// Any change may move the schedule, so the next run is worked out again from now
+	schedule.NextRunAt = nextExportRun(schedule, time.Now())
+
+	if err := s.repo.UpdateSchedule(actor.TenantID, schedule); err != nil {
+		return nil, fmt.Errorf("failed to update scheduled export: %w", err)
+	}
+
+	s.logger.Info("Scheduled export updated",
+		zap.String("schedule_id", schedule.ID),
+		zap.String("tenant_id", actor.TenantID),
+		zap.String("updated_by", actor.UserID))
+
+	return schedule, nil
+}
+
+func (s *exportService) DeleteSchedule(actor Actor, scheduleID string) error {
+	if err := s.repo.DeleteSchedule(actor.TenantID, scheduleID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Scheduled export deleted",
+		zap.String("schedule_id", scheduleID),
+		zap.String("tenant_id", actor.TenantID),
+		zap.String("deleted_by", actor.UserID))
+
+	return nil
+}
+
+// RunSchedule runs a saved export straight away, without moving its next scheduled run
+func (s *exportService) RunSchedule(actor Actor, scheduleID string) (*tenant_models.ExportFile, error) {
+	schedule, err := s.repo.GetSchedule(actor.TenantID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.runSchedule(actor.TenantID, schedule, time.Now())
+}
+
+func (s *exportService) ListFiles(actor Actor, scheduleID string, limit, offset int) ([]*tenant_models.ExportFile, int64, error) {
+	files, total, err := s.repo.ListFiles(actor.TenantID, scheduleID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list export files: %w", err)
+	}
+	return files, total, nil
+}
+
+func (s *exportService) OpenFile(actor Actor, fileID string) (*tenant_models.ExportFile, error) {
+	return s.openFile(actor.TenantID, fileID)
+}
+
+// OpenFileByToken checks a download link's token and returns the file. Tokens that do
+// not verify are reported as not found.
+func (s *exportService) OpenFileByToken(token string) (*tenant_models.ExportFile, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || s.settings.LinkSecret == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	tenantID, fileID := parts[0], parts[1]
+	expected := s.signature(tenantID + "." + fileID)
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.openFile(tenantID, fileID)
+}
+
+// openFile treats files past their expiry as gone even before the scheduler removes them
+func (s *exportService) openFile(tenantID, fileID string) (*tenant_models.ExportFile, error) {
+	file, err := s.repo.GetFile(tenantID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(file.ExpiresAt) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return file, nil
+}
+
+// signFile builds a download link's token: tenant and file IDs with an HMAC over both
+func (s *exportService) signFile(tenantID, fileID string) string {
+	payload := tenantID + "." + fileID
+	return payload + "." + s.signature(payload)
+}
+
+func (s *exportService) signature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.settings.LinkSecret))
+	mac.Write([]byte("export-file:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *exportService) RunDueSchedules(tenantID string, now time.Time) error {
+	schedules, err := s.repo.ListDueSchedules(tenantID, now)
+	if err != nil {
+		return fmt.Errorf("failed to list due exports: %w", err)
+	}
+
+	for _, schedule := range schedules {
+		// Claiming moves the next run first, so another instance or a failing export
+		// cannot make the same run fire again
+		next := nextExportRun(schedule, now)
+		claimed, err := s.repo.ClaimRun(tenantID, schedule.ID, schedule.NextRunAt, next)
+		if err != nil {
+			s.logger.Warn("Failed to claim scheduled export",
+				zap.String("schedule_id", schedule.ID),
+				zap.String("tenant_id", tenantID),
+				zap.Error(err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		schedule.NextRunAt = next
+
+		if _, err := s.runSchedule(tenantID, schedule, now); err != nil {
+			s.logger.Warn("Scheduled export failed",
+				zap.String("schedule_id", schedule.ID),
+				zap.String("tenant_id", tenantID),
+				zap.Error(err))
+		}
+	}
+
+	s.removeExpiredFiles(tenantID, now)
+	return nil
+}
+
+// runSchedule writes the export to a file, emails the download link to the recipients
+// and records the outcome on the schedule
+func (s *exportService) runSchedule(tenantID string, schedule *tenant_models.ScheduledExport, now time.Time) (*tenant_models.ExportFile, error) {
+	actor, err := s.scheduleActor(tenantID, schedule)
+	var file *tenant_models.ExportFile
+	if err == nil {
+		file, err = s.writeFile(actor, schedule, now)
+	}
+
+	schedule.LastRunAt = &now
+	schedule.LastError = ""
+	if err != nil {
+		schedule.LastError = err.Error()
+	}
+	if saveErr := s.repo.UpdateSchedule(tenantID, schedule); saveErr != nil {
+		s.logger.Warn("Failed to record scheduled export run",
+			zap.String("schedule_id", schedule.ID),
+			zap.String("tenant_id", tenantID),
+			zap.Error(saveErr))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deliver(tenantID, schedule, file); err != nil {
+		s.logger.Warn("Failed to email scheduled export",
+			zap.String("schedule_id", schedule.ID),
+			zap.String("tenant_id", tenantID),
+			zap.Error(err))
+	}
+
+	s.logger.Info("Scheduled export run",
+		zap.String("schedule_id", schedule.ID),
+		zap.String("file_id", file.ID),
+		zap.String("tenant_id", tenantID),
+		zap.Int("tickets", file.Tickets))
+
+	return file, nil
+}
+
+// scheduleActor is who a saved export runs as: its creator, with admin visibility as only
+// admins can set exports up. Once the creator is no longer an admin the schedule is paused.
+func (s *exportService) scheduleActor(tenantID string, schedule *tenant_models.ScheduledExport) (Actor, error) {
+	role, err := s.users.GetMembershipRole(tenantID, schedule.CreatedBy)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return Actor{}, fmt.Errorf("failed to load the export creator's role: %w", err)
+	}
+
+	actor := Actor{UserID: schedule.CreatedBy, TenantID: tenantID, Role: role}
+	if !actor.isAdmin() {
+		schedule.Enabled = false
+		return Actor{}, validationError("paused: the export's creator is no longer an admin")
+	}
+	return actor, nil
+}
+
+func (s *exportService) writeFile(actor Actor, schedule *tenant_models.ScheduledExport, now time.Time) (*tenant_models.ExportFile, error) {
+	tenantID := actor.TenantID
+	dir := filepath.Join(s.settings.StoragePath, tenantID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	path := filepath.Join(dir, uuid.NewString()+"."+string(schedule.Format))
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	options := ExportOptions{Format: schedule.Format, IncludeComments: schedule.IncludeComments}
+	count, err := s.ExportTickets(actor, exportFilters(schedule.Filter, now), options, out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to read export file: %w", err)
+	}
+
+	file := &tenant_models.ExportFile{
+		ScheduledExportID: &schedule.ID,
+		Format:            schedule.Format,
+		Filename:          exportFilename(schedule, now),
+		FilePath:          path,
+		Size:              info.Size(),
+		Tickets:           count,
+		CreatedBy:         schedule.CreatedBy,
+		ExpiresAt:         now.Add(s.settings.Retention),
+	}
+	if err := s.repo.CreateFile(tenantID, file); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to record export file: %w", err)
+	}
+	return file, nil
+}
+
+// deliver emails the recipients a link to the file; without a download URL there is
+// nothing to link to and the file is only listed in the API
+func (s *exportService) deliver(tenantID string, schedule *tenant_models.ScheduledExport, file *tenant_models.ExportFile) error {
+	if s.settings.DownloadURL == "" || len(schedule.Recipients) == 0 {
+		return nil
+	}
+
+	to := make([]mail.Address, len(schedule.Recipients))
+	for i, recipient := range schedule.Recipients {
+		to[i] = mail.Address{Address: recipient}
+	}
+	link := strings.TrimRight(s.settings.DownloadURL, "/") + "/" + s.signFile(tenantID, file.ID)
+	subject := "Ticket export: " + schedule.Name
+	body := fmt.Sprintf("Your %s ticket export \"%s\" is ready with %d tickets.\n\nDownload it here:\n%s\n\nThe link works until %s.",
+		schedule.Frequency, schedule.Name, file.Tickets, link, file.ExpiresAt.UTC().Format("2 January 2006"))
+
+	return s.replies.SendNotification(tenantID, to, subject, body)
+}
+
+func (s *exportService) removeExpiredFiles(tenantID string, now time.Time) {
+	files, err := s.repo.ListExpiredFiles(tenantID, now)
+	if err != nil {
+		s.logger.Warn("Failed to list expired export files", zap.String("tenant_id", tenantID), zap.Error(err))
+		return
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file.FilePath); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn("Failed to remove export file",
+				zap.String("file_id", file.ID),
+				zap.String("tenant_id", tenantID),
+				zap.Error(err))
+			continue
+		}
+		if err := s.repo.DeleteFile(tenantID, file.ID); err != nil {
+			s.logger.Warn("Failed to delete export file record",
+				zap.String("file_id", file.ID),
+				zap.String("tenant_id", tenantID),
+				zap.Error(err))
+		}
+	}
+}
+
+func validateSchedule(schedule *tenant_models.ScheduledExport) error {
+	if schedule.Name == "" {
+		return validationError("name is required")
+	}
+	if !validExportFormat(schedule.Format) {
+		return validationError("format must be csv, ndjson or xlsx")
+	}
+	if schedule.Frequency != tenant_models.ExportDaily && schedule.Frequency != tenant_models.ExportWeekly {
+		return validationError("frequency must be daily or weekly")
+	}
+	if schedule.Weekday < 0 || schedule.Weekday > 6 {
+		return validationError("weekday must be between 0 (Sunday) and 6")
+	}
+	if schedule.Hour < 0 || schedule.Hour > 23 {
+		return validationError("hour must be between 0 and 23")
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return validationError("unknown timezone %q", schedule.Timezone)
+	}
+	if filter := schedule.Filter; filter != nil && (filter.CreatedWithinDays < 0 || filter.CreatedWithinDays > 366) {
+		return validationError("created_within_days must be between 1 and 366, or 0 for no limit")
+	}
+	return nil
+}
+
+func normalizeRecipients(recipients []string) models.StringArray {
+	seen := map[string]bool{}
+	normalized := models.StringArray{}
+	for _, recipient := range recipients {
+		email := normalizeEmail(recipient)
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		normalized = append(normalized, email)
+	}
+	return normalized
+}
+
+// nextExportRun is the first time after the given one that the schedule fires, at the
+// schedule's hour in its timezone
+func nextExportRun(schedule *tenant_models.ScheduledExport, after time.Time) time.Time {
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := after.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), schedule.Hour, 0, 0, 0, location)
+	step := 1
+	if schedule.Frequency == tenant_models.ExportWeekly {
+		step = 7
+		next = next.AddDate(0, 0, (schedule.Weekday-int(next.Weekday())+7)%7)
+	}
+	if !next.After(after) {
+		next = next.AddDate(0, 0, step)
+	}
+	return next
+}
+
+// exportFilters turns a saved filter into list filters as of the run
+func exportFilters(filter *tenant_models.TicketExportFilter, now time.Time) repositories.TicketFilters {
+	if filter == nil {
+		return repositories.TicketFilters{}
+	}
+
+	filters := repositories.TicketFilters{
+		Status:     filter.Status,
+		Priority:   filter.Priority,
+		Type:       filter.Type,
+		AssigneeID: filter.AssigneeID,
+		ReporterID: filter.ReporterID,
+		ProjectID:  filter.ProjectID,
+		GroupID:    filter.GroupID,
+		Category:   filter.Category,
+		DateFrom:   filter.DateFrom,
+		DateTo:     filter.DateTo,
+	}
+	if filters.DateFrom == nil && filter.CreatedWithinDays > 0 {
+		from := now.AddDate(0, 0, -filter.CreatedWithinDays)
+		filters.DateFrom = &from
+	}
+	return filters
+}
+
+// exportFilename names a scheduled export's file after the schedule and the run's date
+func exportFilename(schedule *tenant_models.ScheduledExport, now time.Time) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, schedule.Name)
+	name = strings.Trim(name, "-")
+	if name == "" {
+		name = "tickets"
+	}
+
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	return fmt.Sprintf("%s-%s.%s", name, now.In(location).Format("2006-01-02"), schedule.Format)
+}
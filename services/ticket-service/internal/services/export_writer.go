@@ -0,0 +1,315 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// exportWriter writes exported tickets to a file as they are read, so an export never
+// holds more than a batch in memory
+type exportWriter interface {
+	Write(record *tenant_models.TicketExportRecord) error
+	// Close finishes the file; it does not close the underlying writer
+	Close() error
+}
+
+// exportBaseColumns are the ticket columns of the flat formats, before custom fields
+var exportBaseColumns = []string{
+	"id", "number", "title", "description", "status", "priority", "type", "category", "visibility",
+	"project_id", "assignee_id", "reporter_id", "group_id", "customer_email", "customer_name",
+	"channel", "language", "labels", "resolution", "resolved_by", "created_at", "updated_at",
+	"resolved_at", "closed_at", "due_date", "estimated_hours", "actual_hours",
+}
+
+func newExportWriter(format tenant_models.ExportFormat, w io.Writer, customFields []string, includeComments bool) (exportWriter, error) {
+	switch format {
+	case tenant_models.ExportFormatNDJSON:
+		return &ndjsonExportWriter{buffer: bufio.NewWriter(w)}, nil
+	case tenant_models.ExportFormatCSV:
+		return newCSVExportWriter(w, customFields, includeComments)
+	case tenant_models.ExportFormatXLSX:
+		return newXLSXExportWriter(w, customFields, includeComments)
+	default:
+		return nil, validationError("format must be csv, ndjson or xlsx")
+	}
+}
+
+type ndjsonExportWriter struct {
+	buffer *bufio.Writer
+}
+
+func (e *ndjsonExportWriter) Write(record *tenant_models.TicketExportRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := e.buffer.Write(line); err != nil {
+		return err
+	}
+	return e.buffer.WriteByte('\n')
+}
+
+func (e *ndjsonExportWriter) Close() error {
+	return e.buffer.Flush()
+}
+
+// exportCell is one value of a flat export row; numbers stay numbers in spreadsheets
+type exportCell struct {
+	Value  string
+	Number bool
+}
+
+// flatExport lays tickets out as rows for the CSV and XLSX formats
+type flatExport struct {
+	customFields    []string
+	includeComments bool
+}
+
+func (f flatExport) columns() []string {
+	columns := append([]string{}, exportBaseColumns...)
+	for _, field := range f.customFields {
+		columns = append(columns, customFieldPrefix+field)
+	}
+	if f.includeComments {
+		columns = append(columns, "comments")
+	}
+	return columns
+}
+
+func (f flatExport) row(record *tenant_models.TicketExportRecord) []exportCell {
+	ticket := record.TicketResponse
+	text := func(value string) exportCell { return exportCell{Value: value} }
+	optional := func(value *string) exportCell {
+		if value == nil {
+			return exportCell{}
+		}
+		return exportCell{Value: *value}
+	}
+	hours := func(value *float64) exportCell {
+		if value == nil {
+			return exportCell{}
+		}
+		return exportCell{Value: strconv.FormatFloat(*value, 'f', -1, 64), Number: true}
+	}
+
+	row := []exportCell{
+		text(ticket.ID),
+		{Value: strconv.Itoa(ticket.TicketNumber), Number: true},
+		text(ticket.Title),
+		text(ticket.Description),
+		text(string(ticket.Status)),
+		text(string(ticket.Priority)),
+		text(string(ticket.TicketType)),
+		text(ticket.Category),
+		text(string(ticket.Visibility)),
+		optional(ticket.ProjectID),
+		optional(ticket.AssigneeID),
+		optional(ticket.ReporterID),
+		optional(ticket.GroupID),
+		text(ticket.CustomerEmail),
+		text(ticket.CustomerName),
+		text(string(ticket.Channel)),
+		text(ticket.Language),
+		text(strings.Join(ticket.Labels, ", ")),
+		text(string(ticket.Resolution)),
+		optional(ticket.ResolvedBy),
+		text(formatTime(&ticket.CreatedAt)),
+		text(formatTime(&ticket.UpdatedAt)),
+		text(formatTime(ticket.ResolvedAt)),
+		text(formatTime(ticket.ClosedAt)),
+		text(formatTime(ticket.DueDate)),
+		hours(ticket.EstimatedHours),
+		hours(ticket.ActualHours),
+	}
+
+	for _, field := range f.customFields {
+		row = append(row, customFieldCell(ticket.CustomFields[field]))
+	}
+	if f.includeComments {
+		row = append(row, text(flattenComments(record.Comments)))
+	}
+	return row
+}
+
+func customFieldCell(value interface{}) exportCell {
+	switch v := value.(type) {
+	case nil:
+		return exportCell{}
+	case string:
+		return exportCell{Value: v}
+	case float64:
+		return exportCell{Value: strconv.FormatFloat(v, 'f', -1, 64), Number: true}
+	case bool:
+		return exportCell{Value: strconv.FormatBool(v)}
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return exportCell{}
+		}
+		return exportCell{Value: string(encoded)}
+	}
+}
+
+// flattenComments puts a ticket's conversation into one cell, oldest comment first
+func flattenComments(comments []*tenant_models.Comment) string {
+	parts := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		author := comment.AuthorEmail
+		if author == "" {
+			author = comment.AuthorID
+		}
+		header := comment.CreatedAt.UTC().Format(time.RFC3339) + " " + author
+		if comment.IsInternal {
+			header += " (internal)"
+		}
+		parts = append(parts, header+":\n"+comment.Body)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+type csvExportWriter struct {
+	flat   flatExport
+	writer *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer, customFields []string, includeComments bool) (*csvExportWriter, error) {
+	e := &csvExportWriter{
+		flat:   flatExport{customFields: customFields, includeComments: includeComments},
+		writer: csv.NewWriter(w),
+	}
+	if err := e.writer.Write(e.flat.columns()); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvExportWriter) Write(record *tenant_models.TicketExportRecord) error {
+	cells := e.flat.row(record)
+	values := make([]string, len(cells))
+	for i, cell := range cells {
+		values[i] = cell.Value
+		// Spreadsheets run text starting like a formula; quote it so a ticket title
+		// cannot execute on the reader's machine
+		if !cell.Number && cell.Value != "" && strings.ContainsRune("=+-@\t\r", rune(cell.Value[0])) {
+			values[i] = "'" + cell.Value
+		}
+	}
+	return e.writer.Write(values)
+}
+
+func (e *csvExportWriter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// XLSX limits that exports have to respect
+const (
+	xlsxMaxRows      = 1048576
+	xlsxMaxCellChars = 32767
+)
+
+// xlsxParts are the fixed parts of a single-sheet workbook; the sheet itself is streamed
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Tickets" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+type xlsxExportWriter struct {
+	flat  flatExport
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+func newXLSXExportWriter(w io.Writer, customFields []string, includeComments bool) (*xlsxExportWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sheet is the last entry, so rows can be written to it as they come
+	entry, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	e := &xlsxExportWriter{
+		flat:  flatExport{customFields: customFields, includeComments: includeComments},
+		zip:   archive,
+		sheet: bufio.NewWriter(entry),
+	}
+	e.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	columns := e.flat.columns()
+	header := make([]exportCell, len(columns))
+	for i, column := range columns {
+		header[i] = exportCell{Value: column}
+	}
+	if err := e.writeRow(header); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *xlsxExportWriter) Write(record *tenant_models.TicketExportRecord) error {
+	if e.rows >= xlsxMaxRows {
+		return validationError("XLSX files hold at most %d tickets; narrow the filters or use CSV", xlsxMaxRows-1)
+	}
+	return e.writeRow(e.flat.row(record))
+}
+
+func (e *xlsxExportWriter) writeRow(cells []exportCell) error {
+	e.rows++
+	e.sheet.WriteString("<row>")
+	for _, cell := range cells {
+		if cell.Number {
+			e.sheet.WriteString("<c><v>" + cell.Value + "</v></c>")
+			continue
+		}
+		value := cell.Value
+		if value == "" {
+			e.sheet.WriteString("<c/>")
+			continue
+		}
+		if len(value) > xlsxMaxCellChars {
+			value = strings.ToValidUTF8(value[:xlsxMaxCellChars], "")
+		}
+		e.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(e.sheet, []byte(value)); err != nil {
+			return err
+		}
+		e.sheet.WriteString("</t></is></c>")
+	}
+	_, err := e.sheet.WriteString("</row>")
+	return err
+}
+
+func (e *xlsxExportWriter) Close() error {
+	e.sheet.WriteString("</sheetData></worksheet>")
+	if err := e.sheet.Flush(); err != nil {
+		return fmt.Errorf("failed to write sheet: %w", err)
+	}
+	return e.zip.Close()
+}
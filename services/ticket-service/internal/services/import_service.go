@@ -0,0 +1,760 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+const (
+	importSyncLimit  = 100              // Larger files always run in the background
+	importChunkSize  = 100              // Rows processed between progress saves
+	importStallAfter = 10 * time.Minute // A running job not saved for this long has lost its worker
+)
+
+var builtinPriorities = []tenant_models.TicketPriority{
+	tenant_models.PriorityLow,
+	tenant_models.PriorityMedium,
+	tenant_models.PriorityHigh,
+	tenant_models.PriorityCritical,
+	tenant_models.PriorityBlocker,
+}
+
+var builtinTicketTypes = []tenant_models.TicketType{
+	tenant_models.TicketTypeTask,
+	tenant_models.TicketTypeBug,
+	tenant_models.TicketTypeFeature,
+	tenant_models.TicketTypeSupport,
+	tenant_models.TicketTypeQuestion,
+	tenant_models.TicketTypeIncident,
+}
+
+var validChannels = map[tenant_models.TicketChannel]bool{
+	tenant_models.ChannelEmail: true,
+	tenant_models.ChannelWeb:   true,
+	tenant_models.ChannelChat:  true,
+	tenant_models.ChannelPhone: true,
+	tenant_models.ChannelAPI:   true,
+}
+
+// ImportService brings tickets over from other helpdesks' exports. Imported tickets keep
+// their original timestamps and skip routing, SLA policies and automations, which are
+// for new work rather than history.
+type ImportService interface {
+	CreateImport(userID, tenantID string, req *tenant_models.ImportRequest, filename string, data []byte) (*tenant_models.ImportJobResponse, error)
+	GetImport(userID, tenantID, jobID string) (*tenant_models.ImportJobResponse, error)
+	ListImports(userID, tenantID string, limit, offset int) ([]tenant_models.ImportJobResponse, int64, error)
+	ResumeImport(userID, tenantID, jobID string) (*tenant_models.ImportJobResponse, error)
+}
+
+type importService struct {
+	repo      repositories.ImportRepository
+	workflows repositories.WorkflowRepository
+	users     repositories.UserRepository
+	store     AttachmentStore
+	logger    *zap.Logger
+}
+
+func NewImportService(repo repositories.ImportRepository, workflows repositories.WorkflowRepository, users repositories.UserRepository, store AttachmentStore, logger *zap.Logger) ImportService {
+	return &importService{
+		repo:      repo,
+		workflows: workflows,
+		users:     users,
+		store:     store,
+		logger:    logger,
+	}
+}
+
+// CreateImport stores the file, checks it can be read and starts the job. Small files
+// finish before returning; others return the queued job to poll.
+func (s *importService) CreateImport(userID, tenantID string, req *tenant_models.ImportRequest, filename string, data []byte) (*tenant_models.ImportJobResponse, error) {
+	if req.Format != tenant_models.ImportFormatCSV && req.Format != tenant_models.ImportFormatJSON {
+		return nil, validationError("format must be csv or json")
+	}
+	if len(data) == 0 {
+		return nil, validationError("the file is empty")
+	}
+
+	_, filePath, err := s.store.Save(tenantID, filename, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store import file: %w", err)
+	}
+
+	job := &tenant_models.ImportJob{
+		Format:           req.Format,
+		DryRun:           req.DryRun,
+		Status:           tenant_models.ImportJobQueued,
+		OriginalFilename: filename,
+		FilePath:         filePath,
+		Mapping:          req.Mapping,
+		CreatedBy:        userID,
+	}
+	total, err := countImportRows(job)
+	if err != nil {
+		os.Remove(filePath)
+		return nil, err
+	}
+	job.Total = total
+
+	if err := s.repo.CreateJob(tenantID, job); err != nil {
+		os.Remove(filePath)
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	s.logger.Info("Ticket import started",
+		zap.String("tenant_id", tenantID),
+		zap.String("job_id", job.ID),
+		zap.String("format", string(job.Format)),
+		zap.Bool("dry_run", job.DryRun),
+		zap.Int("total", job.Total))
+
+	if req.Async || total > importSyncLimit {
+		// Snapshot before the worker starts changing the job
+		response := job.ToResponse()
+		go s.runImport(tenantID, job)
+		return &response, nil
+	}
+
+	s.runImport(tenantID, job)
+	response := job.ToResponse()
+	return &response, nil
+}
+
+func (s *importService) GetImport(userID, tenantID, jobID string) (*tenant_models.ImportJobResponse, error) {
+	job, err := s.repo.GetJob(tenantID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := job.ToResponse()
+	return &response, nil
+}
+
+func (s *importService) ListImports(userID, tenantID string, limit, offset int) ([]tenant_models.ImportJobResponse, int64, error) {
+	jobs, total, err := s.repo.ListJobs(tenantID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list import jobs: %w", err)
+	}
+
+	responses := make([]tenant_models.ImportJobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = job.ToResponse()
+	}
+	return responses, total, nil
+}
+
+// ResumeImport continues a failed job, or a running one whose worker went away, from
+// the row after the last one it saved
+func (s *importService) ResumeImport(userID, tenantID, jobID string) (*tenant_models.ImportJobResponse, error) {
+	job, err := s.repo.GetJob(tenantID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.Status {
+	case tenant_models.ImportJobFailed:
+	case tenant_models.ImportJobQueued, tenant_models.ImportJobRunning:
+		if time.Since(job.UpdatedAt) < importStallAfter {
+			return nil, validationError("the import is still running")
+		}
+	default:
+		return nil, validationError("only failed or stalled imports can be resumed")
+	}
+	if _, err := os.Stat(job.FilePath); err != nil {
+		return nil, validationError("the uploaded file is no longer available; start a new import")
+	}
+
+	job.Status = tenant_models.ImportJobQueued
+	job.Error = ""
+	job.CompletedAt = nil
+	if err := s.repo.UpdateJob(tenantID, job); err != nil {
+		return nil, fmt.Errorf("failed to update import job: %w", err)
+	}
+
+	s.logger.Info("Ticket import resumed",
+		zap.String("tenant_id", tenantID),
+		zap.String("job_id", job.ID),
+		zap.Int("processed", job.Processed),
+		zap.String("resumed_by", userID))
+
+	response := job.ToResponse()
+	go s.runImport(tenantID, job)
+	return &response, nil
+}
+
+// countImportRows reads the whole file once, so unreadable files are rejected up front
+func countImportRows(job *tenant_models.ImportJob) (int, error) {
+	source, err := openImportSource(job)
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	total := 0
+	for {
+		_, err := source.Next()
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return 0, validationError("the file could not be read after row %d: %v", total, err)
+		}
+		total++
+	}
+}
+
+// importLookups holds the names statuses, priorities and types are matched against,
+// keyed by lowercased name
+type importLookups struct {
+	statuses   map[string]tenant_models.TicketStatus
+	categories map[tenant_models.TicketStatus]tenant_models.StatusCategory
+	priorities map[string]tenant_models.TicketPriority
+	types      map[string]tenant_models.TicketType
+}
+
+func (s *importService) loadLookups(tenantID string) (*importLookups, error) {
+	lookups := &importLookups{
+		statuses:   make(map[string]tenant_models.TicketStatus),
+		categories: make(map[tenant_models.TicketStatus]tenant_models.StatusCategory),
+		priorities: make(map[string]tenant_models.TicketPriority),
+		types:      make(map[string]tenant_models.TicketType),
+	}
+
+	for status, category := range builtinStatusCategories {
+		lookups.statuses[string(status)] = status
+		lookups.categories[status] = category
+	}
+	statuses, err := s.workflows.ListStatuses(tenantID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statuses: %w", err)
+	}
+	for _, custom := range statuses {
+		status := tenant_models.TicketStatus(custom.Name)
+		lookups.statuses[strings.ToLower(custom.Name)] = status
+		lookups.categories[status] = custom.Category
+	}
+
+	for _, priority := range builtinPriorities {
+		lookups.priorities[string(priority)] = priority
+	}
+	priorities, err := s.repo.ListPriorities(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list priorities: %w", err)
+	}
+	for _, custom := range priorities {
+		lookups.priorities[strings.ToLower(custom.Name)] = tenant_models.TicketPriority(custom.Name)
+	}
+
+	for _, ticketType := range builtinTicketTypes {
+		lookups.types[string(ticketType)] = ticketType
+	}
+	types, err := s.repo.ListTicketTypes(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ticket types: %w", err)
+	}
+	for _, custom := range types {
+		lookups.types[strings.ToLower(custom.Name)] = tenant_models.TicketType(custom.Name)
+	}
+	return lookups, nil
+}
+
+// runImport works through the file in chunks, saving progress after each. Rows up to
+// job.Processed were handled by an earlier run and are skipped.
+func (s *importService) runImport(tenantID string, job *tenant_models.ImportJob) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.logger.Error("Import job panicked", zap.Any("panic", recovered), zap.String("job_id", job.ID))
+			s.finishImport(tenantID, job, tenant_models.ImportJobFailed, fmt.Sprint("unexpected error: ", recovered))
+		}
+	}()
+
+	job.Status = tenant_models.ImportJobRunning
+	s.saveImport(tenantID, job)
+
+	lookups, err := s.loadLookups(tenantID)
+	if err != nil {
+		s.logger.Error("Failed to prepare import", zap.Error(err), zap.String("job_id", job.ID))
+		s.finishImport(tenantID, job, tenant_models.ImportJobFailed, "failed to load statuses and priorities")
+		return
+	}
+	source, err := openImportSource(job)
+	if err != nil {
+		s.logger.Error("Failed to open import file", zap.Error(err), zap.String("job_id", job.ID))
+		s.finishImport(tenantID, job, tenant_models.ImportJobFailed, "failed to open the uploaded file")
+		return
+	}
+	defer source.Close()
+
+	run := &importRun{job: job, lookups: lookups, seen: make(map[string]int)}
+	chunk := make([]*importRow, 0, importChunkSize)
+	for {
+		row, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.finishImport(tenantID, job, tenant_models.ImportJobFailed, fmt.Sprintf("the file could not be read after row %d", job.Processed))
+			return
+		}
+		if row.Number <= job.Processed {
+			continue
+		}
+
+		chunk = append(chunk, row)
+		if len(chunk) == importChunkSize {
+			if err := s.importChunk(tenantID, run, chunk); err != nil {
+				s.logger.Error("Import chunk failed", zap.Error(err), zap.String("job_id", job.ID))
+				s.finishImport(tenantID, job, tenant_models.ImportJobFailed, "the import was interrupted; resume it to continue")
+				return
+			}
+			chunk = chunk[:0]
+			s.saveImport(tenantID, job)
+		}
+	}
+	if err := s.importChunk(tenantID, run, chunk); err != nil {
+		s.logger.Error("Import chunk failed", zap.Error(err), zap.String("job_id", job.ID))
+		s.finishImport(tenantID, job, tenant_models.ImportJobFailed, "the import was interrupted; resume it to continue")
+		return
+	}
+
+	// Completed jobs cannot be resumed, so the upload has served its purpose
+	if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.Warn("Failed to remove import file", zap.Error(err), zap.String("job_id", job.ID))
+	}
+
+	s.finishImport(tenantID, job, tenant_models.ImportJobCompleted, "")
+	s.logger.Info("Ticket import finished",
+		zap.String("job_id", job.ID),
+		zap.Bool("dry_run", job.DryRun),
+		zap.Int("succeeded", job.Succeeded),
+		zap.Int("failed", job.Failed))
+}
+
+// importRun is the state of one pass over a job's file
+type importRun struct {
+	job     *tenant_models.ImportJob
+	lookups *importLookups
+	seen    map[string]int // External IDs met in this pass and their rows
+}
+
+// importChunk imports or, for dry runs, validates a chunk of rows. Errors are only
+// returned when the chunk could not be processed at all; row problems are recorded on
+// the job.
+func (s *importService) importChunk(tenantID string, run *importRun, rows []*importRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	members, err := s.chunkMembers(tenantID, rows)
+	if err != nil {
+		return err
+	}
+
+	var externalIDs []string
+	for _, row := range rows {
+		if row.Ticket != nil && row.Ticket.ExternalID != "" {
+			externalIDs = append(externalIDs, row.Ticket.ExternalID)
+		}
+	}
+	records, err := s.repo.ListRecords(tenantID, run.job.ID, rows[0].Number, rows[len(rows)-1].Number, externalIDs)
+	if err != nil {
+		return fmt.Errorf("failed to list import records: %w", err)
+	}
+	doneRows := make(map[int]bool)
+	imported := make(map[string]*tenant_models.ImportRecord)
+	for _, record := range records {
+		if record.JobID == run.job.ID {
+			doneRows[record.RowNumber] = true
+		}
+		if record.ExternalID != "" {
+			imported[record.ExternalID] = record
+		}
+	}
+
+	for _, row := range rows {
+		run.job.Processed = row.Number
+		if doneRows[row.Number] {
+			// Imported just before the previous run stopped
+			run.job.Succeeded++
+			continue
+		}
+		if rowErr := s.importRow(tenantID, run, row, members, imported); rowErr != nil {
+			run.job.Failed++
+			if len(run.job.Errors) < tenant_models.MaxImportRowErrors {
+				run.job.Errors = append(run.job.Errors, *rowErr)
+			}
+			continue
+		}
+		run.job.Succeeded++
+	}
+	return nil
+}
+
+// chunkMembers looks up every address the chunk mentions among the tenant's members
+func (s *importService) chunkMembers(tenantID string, rows []*importRow) (map[string]string, error) {
+	var emails []string
+	add := func(email string) {
+		if email = normalizeEmail(email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	for _, row := range rows {
+		if row.Ticket == nil {
+			continue
+		}
+		add(row.Ticket.RequesterEmail)
+		add(row.Ticket.AssigneeEmail)
+		for _, comment := range row.Ticket.Comments {
+			add(comment.AuthorEmail)
+		}
+	}
+
+	users, err := s.users.GetMembersByEmails(tenantID, emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up users: %w", err)
+	}
+	members := make(map[string]string, len(users))
+	for _, user := range users {
+		members[normalizeEmail(user.Email)] = user.ID
+	}
+	return members, nil
+}
+
+func (s *importService) importRow(tenantID string, run *importRun, row *importRow, members map[string]string, imported map[string]*tenant_models.ImportRecord) *tenant_models.ImportRowError {
+	if row.Err != nil {
+		return row.Err
+	}
+
+	source := row.Ticket
+	rowError := func(field, format string, args ...interface{}) *tenant_models.ImportRowError {
+		return &tenant_models.ImportRowError{Row: row.Number, ExternalID: source.ExternalID, Field: field, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if source.ExternalID != "" {
+		if record, ok := imported[source.ExternalID]; ok {
+			return rowError("external_id", "already imported as ticket %s", record.TicketID)
+		}
+		if earlier, ok := run.seen[source.ExternalID]; ok {
+			return rowError("external_id", "duplicate of row %d", earlier)
+		}
+		run.seen[source.ExternalID] = row.Number
+	}
+
+	ticket, rowErr := s.buildTicket(run, source, members, rowError)
+	if rowErr != nil {
+		return rowErr
+	}
+	if run.job.DryRun {
+		return nil
+	}
+
+	ticket.Record = &tenant_models.ImportRecord{JobID: run.job.ID, RowNumber: row.Number, ExternalID: source.ExternalID}
+	note := "Imported from " + run.job.OriginalFilename
+	if source.ExternalID != "" {
+		note += " (" + source.ExternalID + ")"
+	}
+	created := historyEntry("", run.job.CreatedBy, "ticket", tenant_models.ChangeTypeCreate, "", ticket.Ticket.Title)
+	created.Comment = &note
+	ticket.History = []*tenant_models.TicketHistory{created}
+
+	if err := s.repo.ImportTicket(tenantID, ticket); err != nil {
+		s.logger.Error("Failed to import ticket", zap.Error(err), zap.String("job_id", run.job.ID), zap.Int("row", row.Number))
+		return rowError("", "internal error")
+	}
+	return nil
+}
+
+// buildTicket validates a source ticket and turns it into what gets stored
+func (s *importService) buildTicket(run *importRun, source *tenant_models.ImportTicket, members map[string]string,
+	rowError func(field, format string, args ...interface{}) *tenant_models.ImportRowError) (*repositories.ImportedTicket, *tenant_models.ImportRowError) {
+	mapping := run.job.Mapping
+	if mapping == nil {
+		mapping = &tenant_models.ImportMapping{}
+	}
+
+	title := strings.TrimSpace(source.Title)
+	if title == "" {
+		return nil, rowError("title", "title is required")
+	}
+	if len(title) > 500 {
+		return nil, rowError("title", "title is longer than 500 characters")
+	}
+
+	status := tenant_models.StatusOpen
+	if name := mapValue(mapping.StatusMap, source.Status); name != "" {
+		found, ok := run.lookups.statuses[strings.ToLower(name)]
+		if !ok {
+			return nil, rowError("status", "unknown status %q", name)
+		}
+		status = found
+	}
+
+	priority := tenant_models.PriorityMedium
+	if name := mapValue(mapping.PriorityMap, source.Priority); name != "" {
+		found, ok := run.lookups.priorities[strings.ToLower(name)]
+		if !ok {
+			return nil, rowError("priority", "unknown priority %q", name)
+		}
+		if len(found) > 20 {
+			return nil, rowError("priority", "priority %q is longer than 20 characters", name)
+		}
+		priority = found
+	}
+
+	ticketType := tenant_models.TicketTypeTask
+	if name := strings.TrimSpace(source.TicketType); name != "" {
+		found, ok := run.lookups.types[strings.ToLower(name)]
+		if !ok {
+			return nil, rowError("ticket_type", "unknown ticket type %q", name)
+		}
+		ticketType = found
+	}
+
+	channel := tenant_models.TicketChannel(strings.ToLower(strings.TrimSpace(source.Channel)))
+	if channel != "" && !validChannels[channel] {
+		return nil, rowError("channel", "unknown channel %q", source.Channel)
+	}
+
+	var projectID *string
+	if source.ProjectID != "" {
+		if _, err := uuid.Parse(source.ProjectID); err != nil {
+			return nil, rowError("project_id", "project_id is not a valid ID")
+		}
+		projectID = &source.ProjectID
+	}
+
+	// Requesters who are not members stay customers known by address
+	requester := normalizeEmail(source.RequesterEmail)
+	if requester != "" {
+		if _, err := mail.ParseAddress(requester); err != nil {
+			return nil, rowError("requester_email", "invalid email address %q", source.RequesterEmail)
+		}
+	}
+	reporterID := tenant_models.SystemUserID
+	if memberID, ok := members[requester]; ok {
+		reporterID = memberID
+	}
+
+	var assigneeID *string
+	if assignee := normalizeEmail(source.AssigneeEmail); assignee != "" {
+		if memberID, ok := members[assignee]; ok {
+			assigneeID = &memberID
+		} else if !mapping.UnassignUnknown {
+			return nil, rowError("assignee_email", "%s is not a member of this workspace", source.AssigneeEmail)
+		}
+	}
+
+	// Original timestamps are kept; missing ones are filled in from those present
+	createdAt := time.Now()
+	if source.CreatedAt != nil {
+		createdAt = *source.CreatedAt
+	}
+	updatedAt := createdAt
+	if source.UpdatedAt != nil {
+		if source.UpdatedAt.Before(createdAt) {
+			return nil, rowError("updated_at", "updated_at is before created_at")
+		}
+		updatedAt = *source.UpdatedAt
+	}
+
+	ticket := &tenant_models.Ticket{
+		Title:         title,
+		Description:   source.Description,
+		TicketType:    ticketType,
+		Priority:      priority,
+		Status:        status,
+		Category:      strings.TrimSpace(source.Category),
+		Visibility:    tenant_models.VisibilityStandard,
+		ProjectID:     projectID,
+		ReporterID:    &reporterID,
+		AssigneeID:    assigneeID,
+		CustomerEmail: requester,
+		CustomerName:  strings.TrimSpace(source.RequesterName),
+		Channel:       channel,
+		Language:      strings.TrimSpace(source.Language),
+		DueDate:       source.DueDate,
+		Labels:        importLabels(source.Labels),
+		CustomFields:  source.CustomFields,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+	}
+	if len(ticket.Category) > 100 {
+		return nil, rowError("category", "category is longer than 100 characters")
+	}
+	if len(ticket.Language) > 10 {
+		return nil, rowError("language", "language is longer than 10 characters")
+	}
+	if ticket.CustomFields == nil {
+		ticket.CustomFields = make(models.JSONB)
+	}
+
+	// Resolution times only belong on tickets that are still resolved or closed
+	switch run.lookups.categories[status] {
+	case tenant_models.StatusCategoryResolved, tenant_models.StatusCategoryClosed:
+		ticket.ResolvedAt = source.ResolvedAt
+		if ticket.ResolvedAt == nil {
+			ticket.ResolvedAt = &updatedAt
+		}
+		ticket.ResolvedBy = assigneeID
+		if run.lookups.categories[status] == tenant_models.StatusCategoryClosed {
+			ticket.ClosedAt = source.ClosedAt
+			if ticket.ClosedAt == nil {
+				ticket.ClosedAt = &updatedAt
+			}
+		}
+	}
+
+	imported := &repositories.ImportedTicket{Ticket: ticket}
+	for i, attachment := range source.Attachments {
+		stored, rowErr := importAttachment(attachment, tenant_models.SystemUserID, createdAt, fmt.Sprintf("attachments[%d]", i), rowError)
+		if rowErr != nil {
+			return nil, rowErr
+		}
+		imported.Attachments = append(imported.Attachments, stored)
+	}
+
+	for i, source := range source.Comments {
+		field := fmt.Sprintf("comments[%d]", i)
+		body := strings.TrimSpace(source.Body)
+		if body == "" {
+			return nil, rowError(field+".body", "comment body is required")
+		}
+
+		// Authors who are not members are kept by address, like emailed replies
+		comment := &tenant_models.Comment{
+			Body:        body,
+			CommentType: tenant_models.CommentTypeComment,
+			AuthorID:    tenant_models.SystemUserID,
+			IsInternal:  source.IsInternal,
+			CreatedAt:   createdAt,
+		}
+		if source.IsInternal {
+			comment.CommentType = tenant_models.CommentTypeInternalNote
+		}
+		if author := normalizeEmail(source.AuthorEmail); author != "" {
+			if memberID, ok := members[author]; ok {
+				comment.AuthorID = memberID
+			} else if _, err := mail.ParseAddress(author); err != nil {
+				return nil, rowError(field+".author_email", "invalid email address %q", source.AuthorEmail)
+			} else {
+				comment.AuthorEmail = author
+			}
+		}
+		if source.CreatedAt != nil {
+			comment.CreatedAt = *source.CreatedAt
+		}
+		comment.UpdatedAt = comment.CreatedAt
+
+		entry := &repositories.ImportedComment{Comment: comment}
+		for j, attachment := range source.Attachments {
+			stored, rowErr := importAttachment(attachment, comment.AuthorID, comment.CreatedAt, fmt.Sprintf("%s.attachments[%d]", field, j), rowError)
+			if rowErr != nil {
+				return nil, rowErr
+			}
+			entry.Attachments = append(entry.Attachments, stored)
+		}
+		imported.Comments = append(imported.Comments, entry)
+	}
+	return imported, nil
+}
+
+// importAttachment keeps a reference to a file the source helpdesk still serves
+func importAttachment(source tenant_models.ImportAttachment, uploadedBy string, uploadedAt time.Time, field string,
+	rowError func(field, format string, args ...interface{}) *tenant_models.ImportRowError) (*tenant_models.Attachment, *tenant_models.ImportRowError) {
+	link, err := url.Parse(strings.TrimSpace(source.URL))
+	if err != nil || (link.Scheme != "https" && link.Scheme != "http") || link.Host == "" {
+		return nil, rowError(field+".url", "attachment url must be an http or https URL")
+	}
+	if len(link.String()) > 500 {
+		return nil, rowError(field+".url", "attachment url is longer than 500 characters")
+	}
+
+	filename := strings.TrimSpace(source.Filename)
+	if filename == "" {
+		filename = path.Base(link.Path)
+	}
+	if filename == "" || filename == "/" || filename == "." {
+		return nil, rowError(field+".filename", "attachment filename is required")
+	}
+	if len(filename) > 255 {
+		return nil, rowError(field+".filename", "attachment filename is longer than 255 characters")
+	}
+
+	mimeType := strings.TrimSpace(source.MimeType)
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	if source.CreatedAt != nil {
+		uploadedAt = *source.CreatedAt
+	}
+	return &tenant_models.Attachment{
+		Filename:         filename,
+		OriginalFilename: filename,
+		FileSize:         source.Size,
+		MimeType:         mimeType,
+		FilePath:         link.String(),
+		UploadedBy:       uploadedBy,
+		UploadedAt:       uploadedAt,
+	}, nil
+}
+
+// mapValue renames a source value through the mapping, matching keys exactly first and
+// then ignoring case
+func mapValue(renames map[string]string, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if renamed, ok := renames[value]; ok {
+		return renamed
+	}
+	for from, to := range renames {
+		if strings.EqualFold(from, value) {
+			return to
+		}
+	}
+	return value
+}
+
+func importLabels(labels []string) models.StringArray {
+	cleaned := make(models.StringArray, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		cleaned = append(cleaned, label)
+	}
+	return cleaned
+}
+
+func (s *importService) finishImport(tenantID string, job *tenant_models.ImportJob, status tenant_models.ImportJobStatus, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Error = message
+	job.CompletedAt = &now
+	s.saveImport(tenantID, job)
+}
+
+// saveImport records progress; a failed save only means a resume repeats some rows,
+// which import records make harmless
+func (s *importService) saveImport(tenantID string, job *tenant_models.ImportJob) {
+	if err := s.repo.UpdateJob(tenantID, job); err != nil {
+		s.logger.Warn("Failed to save import progress", zap.Error(err), zap.String("job_id", job.ID))
+	}
+}
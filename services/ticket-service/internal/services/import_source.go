@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// importColumns are the ticket fields a CSV mapping may name, besides custom fields
+var importColumns = map[string]bool{
+	"external_id": true, "title": true, "description": true, "status": true, "priority": true,
+	"ticket_type": true, "category": true, "requester_email": true, "requester_name": true,
+	"assignee_email": true, "project_id": true, "labels": true, "channel": true, "language": true,
+	"created_at": true, "updated_at": true, "resolved_at": true, "closed_at": true, "due_date": true,
+}
+
+// importTimeLayouts are tried in turn for CSV times when the mapping names no format
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// importRow is one ticket read from an import file. Rows that could not be read carry
+// the reason in Err instead of a ticket.
+type importRow struct {
+	Number int
+	Ticket *tenant_models.ImportTicket
+	Err    *tenant_models.ImportRowError
+}
+
+// importSource reads an import file one ticket at a time, so large files are never
+// held in memory
+type importSource interface {
+	// Next returns the next row, or io.EOF after the last one. Any other error means
+	// the rest of the file cannot be read.
+	Next() (*importRow, error)
+	Close() error
+}
+
+// openImportSource opens the job's file; a file or mapping that cannot be used is a
+// validation error
+func openImportSource(job *tenant_models.ImportJob) (importSource, error) {
+	file, err := os.Open(job.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+
+	var source importSource
+	switch job.Format {
+	case tenant_models.ImportFormatCSV:
+		source, err = newCSVImportSource(file, job.Mapping)
+	case tenant_models.ImportFormatJSON:
+		source, err = newJSONImportSource(file)
+	default:
+		err = validationError("unknown import format %q", job.Format)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return source, nil
+}
+
+type csvImportSource struct {
+	file    *os.File
+	reader  *csv.Reader
+	mapping *tenant_models.ImportMapping
+	columns map[string]int // Ticket field to column index
+	row     int
+}
+
+func newCSVImportSource(file *os.File, mapping *tenant_models.ImportMapping) (*csvImportSource, error) {
+	if mapping == nil || len(mapping.Columns) == 0 {
+		return nil, validationError("CSV imports need a column mapping")
+	}
+	if mapping.Columns["title"] == "" {
+		return nil, validationError("the column mapping must name the title column")
+	}
+
+	reader := csv.NewReader(bufio.NewReader(file))
+	reader.FieldsPerRecord = -1 // Short rows leave the missing fields empty
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, validationError("the file has no readable header row: %v", err)
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet exports often start with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		positions[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := make(map[string]int, len(mapping.Columns))
+	for field, column := range mapping.Columns {
+		if !importColumns[field] && !(strings.HasPrefix(field, customFieldPrefix) && len(field) > len(customFieldPrefix)) {
+			return nil, validationError("unknown field %q in the column mapping", field)
+		}
+		position, ok := positions[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			return nil, validationError("column %q mapped to %s is not in the file", column, field)
+		}
+		columns[field] = position
+	}
+
+	return &csvImportSource{
+		file:    file,
+		reader:  reader,
+		mapping: mapping,
+		columns: columns,
+	}, nil
+}
+
+func (s *csvImportSource) Next() (*importRow, error) {
+	record, err := s.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	s.row++
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &importRow{Number: s.row, Err: &tenant_models.ImportRowError{Row: s.row, Message: "malformed row: " + parseErr.Err.Error()}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, rowErr := s.ticket(record)
+	if rowErr != nil {
+		rowErr.Row = s.row
+		rowErr.ExternalID = s.value(record, "external_id")
+		return &importRow{Number: s.row, Err: rowErr}, nil
+	}
+	return &importRow{Number: s.row, Ticket: ticket}, nil
+}
+
+func (s *csvImportSource) Close() error {
+	return s.file.Close()
+}
+
+func (s *csvImportSource) value(record []string, field string) string {
+	position, ok := s.columns[field]
+	if !ok || position >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[position])
+}
+
+// ticket converts a CSV record into the JSON format's ticket
+func (s *csvImportSource) ticket(record []string) (*tenant_models.ImportTicket, *tenant_models.ImportRowError) {
+	ticket := &tenant_models.ImportTicket{
+		ExternalID:     s.value(record, "external_id"),
+		Title:          s.value(record, "title"),
+		Description:    s.value(record, "description"),
+		Status:         s.value(record, "status"),
+		Priority:       s.value(record, "priority"),
+		TicketType:     s.value(record, "ticket_type"),
+		Category:       s.value(record, "category"),
+		RequesterEmail: s.value(record, "requester_email"),
+		RequesterName:  s.value(record, "requester_name"),
+		AssigneeEmail:  s.value(record, "assignee_email"),
+		ProjectID:      s.value(record, "project_id"),
+		Channel:        s.value(record, "channel"),
+		Language:       s.value(record, "language"),
+	}
+
+	if labels := s.value(record, "labels"); labels != "" {
+		separator := s.mapping.LabelSeparator
+		if separator == "" {
+			separator = ","
+		}
+		ticket.Labels = strings.Split(labels, separator)
+	}
+
+	for field := range s.columns {
+		if name, ok := strings.CutPrefix(field, customFieldPrefix); ok {
+			if value := s.value(record, field); value != "" {
+				if ticket.CustomFields == nil {
+					ticket.CustomFields = make(models.JSONB)
+				}
+				ticket.CustomFields[name] = value
+			}
+		}
+	}
+
+	times := []struct {
+		field  string
+		target **time.Time
+	}{
+		{"created_at", &ticket.CreatedAt},
+		{"updated_at", &ticket.UpdatedAt},
+		{"resolved_at", &ticket.ResolvedAt},
+		{"closed_at", &ticket.ClosedAt},
+		{"due_date", &ticket.DueDate},
+	}
+	for _, t := range times {
+		value := s.value(record, t.field)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseImportTime(value, s.mapping.TimeFormat)
+		if err != nil {
+			return nil, &tenant_models.ImportRowError{Field: t.field, Message: fmt.Sprintf("cannot read time %q", value)}
+		}
+		*t.target = &parsed
+	}
+	return ticket, nil
+}
+
+func parseImportTime(value, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.Parse(layout, value)
+	}
+	var err error
+	for _, layout := range importTimeLayouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+type jsonImportSource struct {
+	file    *os.File
+	decoder *json.Decoder
+	row     int
+}
+
+// newJSONImportSource positions the decoder at the first ticket of the "tickets" array
+func newJSONImportSource(file *os.File) (*jsonImportSource, error) {
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, validationError("the file is not valid JSON: %v", err)
+	}
+
+	if token != json.Delim('[') {
+		if token != json.Delim('{') {
+			return nil, validationError(`the file must hold a "tickets" array`)
+		}
+		for {
+			if !decoder.More() {
+				return nil, validationError(`the file has no "tickets" array`)
+			}
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, validationError("the file is not valid JSON: %v", err)
+			}
+			if key == "tickets" {
+				break
+			}
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil, validationError("the file is not valid JSON: %v", err)
+			}
+		}
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			return nil, validationError(`"tickets" must be an array`)
+		}
+	}
+
+	return &jsonImportSource{file: file, decoder: decoder}, nil
+}
+
+func (s *jsonImportSource) Next() (*importRow, error) {
+	if !s.decoder.More() {
+		return nil, io.EOF
+	}
+	s.row++
+
+	var ticket tenant_models.ImportTicket
+	if err := s.decoder.Decode(&ticket); err != nil {
+		// Values that are valid JSON but not a ticket only spoil their own row
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+		return &importRow{Number: s.row, Err: &tenant_models.ImportRowError{Row: s.row, Message: "invalid ticket: " + err.Error()}}, nil
+	}
+	return &importRow{Number: s.row, Ticket: &ticket}, nil
+}
+
+func (s *jsonImportSource) Close() error {
+	return s.file.Close()
+}
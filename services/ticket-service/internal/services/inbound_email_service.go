@@ -0,0 +1,296 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+type InboundEmailAction string
+
+const (
+	InboundEmailCreated   InboundEmailAction = "created"   // Opened a new ticket
+	InboundEmailReplied   InboundEmailAction = "replied"   // Added a comment to an existing ticket
+	InboundEmailDuplicate InboundEmailAction = "duplicate" // Message-ID was already processed
+	InboundEmailIgnored   InboundEmailAction = "ignored"   // Auto-reply, bounce or list mail
+)
+
+// InboundEmailResult tells the delivering MTA what became of a message
+type InboundEmailResult struct {
+	Action       InboundEmailAction `json:"action"`
+	TenantID     string             `json:"tenant_id"`
+	TicketID     string             `json:"ticket_id,omitempty"`
+	TicketNumber int                `json:"ticket_number,omitempty"`
+	CommentID    string             `json:"comment_id,omitempty"`
+	Attachments  int                `json:"attachments"`
+}
+
+// ticketToken finds the ticket number outbound subjects carry, e.g. "Re: [#42] Printer on fire"
+var ticketToken = regexp.MustCompile(`\[#(\d+)\]`)
+
+// InboundEmailService turns raw RFC 5322 messages into tickets and ticket replies
+type InboundEmailService interface {
+	ProcessMessage(recipient string, raw []byte) (*InboundEmailResult, error)
+}
+
+type inboundEmailService struct {
+	tickets     TicketService
+	ticketRepo  repositories.TicketRepository
+	messages    repositories.EmailMessageRepository
+	tenants     repositories.TenantRepository
+	attachments AttachmentStore
+	settings    EmailSettings
+	logger      *zap.Logger
+}
+
+func NewInboundEmailService(tickets TicketService, ticketRepo repositories.TicketRepository, messages repositories.EmailMessageRepository, tenants repositories.TenantRepository, attachments AttachmentStore, settings EmailSettings, logger *zap.Logger) InboundEmailService {
+	settings.InboundDomain = strings.ToLower(settings.InboundDomain)
+	return &inboundEmailService{
+		tickets:     tickets,
+		ticketRepo:  ticketRepo,
+		messages:    messages,
+		tenants:     tenants,
+		attachments: attachments,
+		settings:    settings,
+		logger:      logger,
+	}
+}
+
+// ProcessMessage routes a message to its tenant by recipient, then threads it onto the
+// ticket it replies to or opens a new one. recipient is the envelope recipient if the
+// MTA passes it; the message's own recipient headers are tried after it.
+func (s *inboundEmailService) ProcessMessage(recipient string, raw []byte) (*InboundEmailResult, error) {
+	if s.settings.InboundDomain == "" {
+		return nil, validationError("inbound email is not configured")
+	}
+
+	email, err := parseEmail(raw)
+	if err != nil {
+		return nil, validationError("malformed message: %v", err)
+	}
+
+	tenant, address, err := s.resolveTenant(append([]string{recipient}, email.Recipients...))
+	if err != nil {
+		return nil, err
+	}
+	result := &InboundEmailResult{TenantID: tenant.ID}
+
+	if email.AutoGenerated {
+		s.logger.Info("Ignored automatic email",
+			zap.String("tenant_id", tenant.ID),
+			zap.String("message_id", email.MessageID))
+		result.Action = InboundEmailIgnored
+		return result, nil
+	}
+
+	// MTAs retry on timeouts, so a message may arrive more than once
+	existing, err := s.messages.FindByMessageIDs(tenant.ID, []string{email.MessageID})
+	if err == nil {
+		result.Action = InboundEmailDuplicate
+		result.TicketID = existing.TicketID
+		return result, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check for duplicate email: %w", err)
+	}
+
+	ticket, err := s.findThread(tenant.ID, email)
+	if err != nil {
+		return nil, err
+	}
+
+	// Email senders have no user account; the system acts for them
+	actor := Actor{UserID: tenant_models.SystemUserID, TenantID: tenant.ID, Role: models.MembershipRoleAdmin}
+	body := stripQuotedReply(email.Text)
+	if body == "" {
+		body = "(no message body)"
+	}
+	record := &tenant_models.EmailMessage{
+		Direction:   tenant_models.EmailInbound,
+		MessageID:   email.MessageID,
+		InReplyTo:   email.InReplyTo,
+		References:  strings.Join(email.References, " "),
+		FromAddress: email.From.Address,
+		ToAddress:   address,
+		Subject:     truncateRunes(email.Subject, 998),
+	}
+
+	if ticket != nil {
+		comment, err := s.tickets.CreateComment(actor, ticket.ID, &tenant_models.CommentCreateRequest{
+			Body:        body,
+			AuthorEmail: email.From.Address,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add email reply: %w", err)
+		}
+		result.Action = InboundEmailReplied
+		result.TicketID = ticket.ID
+		result.TicketNumber = ticket.TicketNumber
+		result.CommentID = comment.ID
+		record.CommentID = &comment.ID
+		result.Attachments = s.saveAttachments(tenant.ID, nil, &comment.ID, email.Attachments)
+	} else {
+		title := email.Subject
+		if title == "" {
+			title = "(no subject)"
+		}
+		customerName := email.From.Name
+		if customerName == "" {
+			customerName = email.From.Address
+		}
+		created, err := s.tickets.CreateTicket(actor, &tenant_models.TicketCreateRequest{
+			Title:         truncateRunes(title, 500),
+			Description:   body,
+			Channel:       tenant_models.ChannelEmail,
+			CustomerEmail: email.From.Address,
+			CustomerName:  truncateRunes(customerName, 255),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ticket from email: %w", err)
+		}
+		result.Action = InboundEmailCreated
+		result.TicketID = created.ID
+		result.TicketNumber = created.TicketNumber
+		result.Attachments = s.saveAttachments(tenant.ID, &created.ID, nil, email.Attachments)
+	}
+
+	// The ticket change already happened; failing now would only make the MTA redeliver it
+	record.TicketID = result.TicketID
+	if err := s.messages.Create(tenant.ID, record); err != nil {
+		s.logger.Error("Failed to record inbound email", zap.Error(err),
+			zap.String("ticket_id", result.TicketID),
+			zap.String("message_id", email.MessageID))
+	}
+
+	s.logger.Info("Inbound email processed",
+		zap.String("action", string(result.Action)),
+		zap.String("ticket_id", result.TicketID),
+		zap.String("tenant_id", tenant.ID))
+
+	return result, nil
+}
+
+// resolveTenant returns the first active tenant whose mail domain one of the addresses is on
+func (s *inboundEmailService) resolveTenant(addresses []string) (*models.Tenant, string, error) {
+	for _, address := range addresses {
+		address = strings.ToLower(strings.Trim(strings.TrimSpace(address), "<>"))
+		_, domain, ok := strings.Cut(address, "@")
+		if !ok {
+			continue
+		}
+		subdomain, ok := strings.CutSuffix(domain, "."+s.settings.InboundDomain)
+		if !ok || subdomain == "" || strings.Contains(subdomain, ".") {
+			continue
+		}
+
+		tenant, err := s.tenants.GetBySubdomain(subdomain)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get tenant: %w", err)
+		}
+		if tenant.Status != models.TenantStatusActive {
+			continue
+		}
+		return tenant, address, nil
+	}
+	return nil, "", validationError("no active tenant receives mail for this message's recipients")
+}
+
+// findThread returns the ticket a message replies to, or nil for a new conversation.
+// Known Message-IDs in In-Reply-To or References win; a subject ticket token is only
+// trusted when the sender is the ticket's customer, since numbers are guessable.
+func (s *inboundEmailService) findThread(tenantID string, email *inboundEmail) (*tenant_models.Ticket, error) {
+	var ids []string
+	if email.InReplyTo != "" {
+		ids = append(ids, email.InReplyTo)
+	}
+	ids = append(ids, email.References...)
+
+	message, err := s.messages.FindByMessageIDs(tenantID, ids)
+	switch {
+	case err == nil:
+		ticket, err := s.ticketRepo.GetByID(tenantID, message.TicketID)
+		if err == nil {
+			return ticket, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get ticket: %w", err)
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to look up email thread: %w", err)
+	}
+
+	match := ticketToken.FindStringSubmatch(email.Subject)
+	if match == nil {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(match[1])
+	if err != nil {
+		return nil, nil
+	}
+	ticket, err := s.ticketRepo.GetByNumber(tenantID, number)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	if !strings.EqualFold(ticket.CustomerEmail, email.From.Address) {
+		return nil, nil
+	}
+	return ticket, nil
+}
+
+// saveAttachments stores the message's attachments on the ticket or comment and returns how
+// many were kept; a file that cannot be stored is logged and skipped
+func (s *inboundEmailService) saveAttachments(tenantID string, ticketID, commentID *string, files []emailAttachment) int {
+	saved := 0
+	for _, file := range files {
+		filename, path, err := s.attachments.Save(tenantID, file.Filename, file.Data)
+		if err != nil {
+			s.logger.Warn("Failed to store email attachment", zap.Error(err), zap.String("filename", file.Filename))
+			continue
+		}
+
+		mimeType := file.ContentType
+		if mimeType == "" || len(mimeType) > 100 {
+			mimeType = "application/octet-stream"
+		}
+		attachment := &tenant_models.Attachment{
+			TicketID:         ticketID,
+			CommentID:        commentID,
+			Filename:         filename,
+			OriginalFilename: truncateRunes(file.Filename, 255),
+			FileSize:         int64(len(file.Data)),
+			MimeType:         mimeType,
+			FilePath:         path,
+			UploadedBy:       tenant_models.SystemUserID,
+		}
+		if err := s.ticketRepo.CreateAttachment(tenantID, attachment); err != nil {
+			s.logger.Warn("Failed to save email attachment", zap.Error(err), zap.String("filename", file.Filename))
+			continue
+		}
+		saved++
+	}
+	return saved
+}
+
+// truncateRunes shortens text to fit a size-limited column without splitting a character
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// MacroService manages canned responses and applies them to tickets
+type MacroService interface {
+	ListMacros(actor Actor) ([]*tenant_models.MacroResponse, error)
+	CreateMacro(actor Actor, req *tenant_models.MacroCreateRequest) (*tenant_models.MacroResponse, error)
+	GetMacro(actor Actor, macroID string) (*tenant_models.MacroResponse, error)
+	UpdateMacro(actor Actor, macroID string, req *tenant_models.MacroUpdateRequest) (*tenant_models.MacroResponse, error)
+	DeleteMacro(actor Actor, macroID string) error
+
+	ApplyMacro(actor Actor, macroID, ticketID string) (*tenant_models.MacroApplyResponse, error)
+}
+
+type macroService struct {
+	repo    repositories.MacroRepository
+	tickets TicketService
+	users   repositories.UserRepository
+	logger  *zap.Logger
+}
+
+func NewMacroService(repo repositories.MacroRepository, tickets TicketService, users repositories.UserRepository, logger *zap.Logger) MacroService {
+	return &macroService{
+		repo:    repo,
+		tickets: tickets,
+		users:   users,
+		logger:  logger,
+	}
+}
+
+// canEditMacro: personal macros belong to their owner, shared macros to Managers and above
+func canEditMacro(actor Actor, macro *tenant_models.Macro) bool {
+	if macro.Scope == tenant_models.MacroPersonal {
+		return macro.OwnerID == actor.UserID
+	}
+	return actor.HasRole(models.MembershipRoleManager)
+}
+
+func canUseMacros(actor Actor) error {
+	if !actor.HasRole(models.MembershipRoleAgent) {
+		return accessDenied("only agents can use macros")
+	}
+	return nil
+}
+
+func (s *macroService) ListMacros(actor Actor) ([]*tenant_models.MacroResponse, error) {
+	if err := canUseMacros(actor); err != nil {
+		return nil, err
+	}
+
+	macros, err := s.repo.ListVisible(actor.TenantID, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list macros: %w", err)
+	}
+
+	responses := make([]*tenant_models.MacroResponse, 0, len(macros))
+	for _, macro := range macros {
+		responses = append(responses, macroResponse(actor, macro))
+	}
+	return responses, nil
+}
+
+func (s *macroService) CreateMacro(actor Actor, req *tenant_models.MacroCreateRequest) (*tenant_models.MacroResponse, error) {
+	if err := canUseMacros(actor); err != nil {
+		return nil, err
+	}
+
+	macro := &tenant_models.Macro{
+		Name:            strings.TrimSpace(req.Name),
+		Description:     req.Description,
+		Scope:           req.Scope,
+		OwnerID:         actor.UserID,
+		Comment:         req.Comment,
+		CommentInternal: req.CommentInternal,
+		Status:          req.Status,
+		Priority:        req.Priority,
+		AssigneeID:      req.AssigneeID,
+		AddLabels:       models.StringArray(req.AddLabels),
+		RemoveLabels:    models.StringArray(req.RemoveLabels),
+	}
+	if macro.Scope == "" {
+		macro.Scope = tenant_models.MacroPersonal
+	}
+	if err := validateMacro(actor, macro); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(actor.TenantID, macro); err != nil {
+		return nil, fmt.Errorf("failed to create macro: %w", err)
+	}
+
+	s.logger.Info("Macro created",
+		zap.String("macro_id", macro.ID),
+		zap.String("scope", string(macro.Scope)),
+		zap.String("tenant_id", actor.TenantID))
+
+	return macroResponse(actor, macro), nil
+}
+
+func (s *macroService) GetMacro(actor Actor, macroID string) (*tenant_models.MacroResponse, error) {
+	macro, err := s.findMacro(actor, macroID)
+	if err != nil {
+		return nil, err
+	}
+	return macroResponse(actor, macro), nil
+}
+
+func (s *macroService) UpdateMacro(actor Actor, macroID string, req *tenant_models.MacroUpdateRequest) (*tenant_models.MacroResponse, error) {
+	macro, err := s.editableMacro(actor, macroID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		macro.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		macro.Description = *req.Description
+	}
+	if req.Scope != nil {
+		macro.Scope = *req.Scope
+	}
+	if req.Comment != nil {
+		macro.Comment = *req.Comment
+	}
+	if req.CommentInternal != nil {
+		macro.CommentInternal = *req.CommentInternal
+	}
+	if req.Status != nil {
+		macro.Status = *req.Status
+	}
+	if req.Priority != nil {
+		macro.Priority = *req.Priority
+	}
+	if req.AssigneeID != nil {
+		macro.AssigneeID = *req.AssigneeID
+	}
+	if req.AddLabels != nil {
+		macro.AddLabels = models.StringArray(*req.AddLabels)
+	}
+	if req.RemoveLabels != nil {
+		macro.RemoveLabels = models.StringArray(*req.RemoveLabels)
+	}
+
+	// Sharing a personal macro is checked against the new scope as well
+	if err := validateMacro(actor, macro); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(actor.TenantID, macro); err != nil {
+		return nil, fmt.Errorf("failed to update macro: %w", err)
+	}
+	return macroResponse(actor, macro), nil
+}
+
+func (s *macroService) DeleteMacro(actor Actor, macroID string) error {
+	if _, err := s.editableMacro(actor, macroID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(actor.TenantID, macroID); err != nil {
+		return fmt.Errorf("failed to delete macro: %w", err)
+	}
+	return nil
+}
+
+// ApplyMacro makes the macro's ticket changes and then posts its comment, both as the
+// actor and through the normal ticket paths, so each change is permission-checked and
+// recorded in the ticket's history
+func (s *macroService) ApplyMacro(actor Actor, macroID, ticketID string) (*tenant_models.MacroApplyResponse, error) {
+	macro, err := s.findMacro(actor, macroID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.tickets.GetTicket(actor, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update, changed := macroUpdate(actor, macro, ticket); changed {
+		ticket, err = s.tickets.UpdateTicket(actor, ticketID, update)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := &tenant_models.MacroApplyResponse{Ticket: ticket}
+	if strings.TrimSpace(macro.Comment) != "" {
+		body := renderMacroText(macro.Comment, ticket, s.agent(actor))
+		comment, err := s.tickets.CreateComment(actor, ticketID, &tenant_models.CommentCreateRequest{
+			Body:       body,
+			IsInternal: macro.CommentInternal,
+		})
+		if err != nil {
+			return nil, err
+		}
+		response.Comment = comment
+	}
+
+	if err := s.repo.IncrementUsage(actor.TenantID, macro.ID); err != nil {
+		s.logger.Warn("Failed to count macro use", zap.Error(err), zap.String("macro_id", macro.ID))
+	}
+
+	s.logger.Info("Macro applied",
+		zap.String("macro_id", macro.ID),
+		zap.String("ticket_id", ticketID),
+		zap.String("applied_by", actor.UserID))
+
+	return response, nil
+}
+
+// findMacro returns a macro the actor may use; other agents' personal macros are reported
+// as missing
+func (s *macroService) findMacro(actor Actor, macroID string) (*tenant_models.Macro, error) {
+	if err := canUseMacros(actor); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(macroID); err != nil {
+		return nil, fmt.Errorf("failed to get macro: %w", gorm.ErrRecordNotFound)
+	}
+
+	macro, err := s.repo.Get(actor.TenantID, macroID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get macro: %w", err)
+	}
+	if macro.Scope == tenant_models.MacroPersonal && macro.OwnerID != actor.UserID {
+		return nil, fmt.Errorf("failed to get macro: %w", gorm.ErrRecordNotFound)
+	}
+	return macro, nil
+}
+
+func (s *macroService) editableMacro(actor Actor, macroID string) (*tenant_models.Macro, error) {
+	macro, err := s.findMacro(actor, macroID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEditMacro(actor, macro) {
+		return nil, accessDenied("only managers can change shared macros")
+	}
+	return macro, nil
+}
+
+// agent looks up the applying agent for placeholders; a failed lookup leaves them empty
+func (s *macroService) agent(actor Actor) *models.User {
+	users, err := s.users.GetByIDs([]string{actor.UserID})
+	if err != nil || len(users) == 0 {
+		if err != nil {
+			s.logger.Warn("Failed to look up agent for macro", zap.Error(err), zap.String("user_id", actor.UserID))
+		}
+		return &models.User{ID: actor.UserID}
+	}
+	return users[0]
+}
+
+func macroResponse(actor Actor, macro *tenant_models.Macro) *tenant_models.MacroResponse {
+	response := macro.ToResponse()
+	response.CanEdit = canEditMacro(actor, macro)
+	return &response
+}
+
+func validateMacro(actor Actor, macro *tenant_models.Macro) error {
+	if macro.Name == "" {
+		return validationError("macro name is required")
+	}
+	if macro.Scope == tenant_models.MacroShared && !actor.HasRole(models.MembershipRoleManager) {
+		return accessDenied("only managers can share macros")
+	}
+	switch macro.AssigneeID {
+	case "", tenant_models.MacroAssignMe, tenant_models.MacroAssignNone:
+	default:
+		if _, err := uuid.Parse(macro.AssigneeID); err != nil {
+			return validationError("assignee must be a user ID, %q or %q", tenant_models.MacroAssignMe, tenant_models.MacroAssignNone)
+		}
+	}
+
+	macro.AddLabels = trimLabels(macro.AddLabels)
+	macro.RemoveLabels = trimLabels(macro.RemoveLabels)
+	if strings.TrimSpace(macro.Comment) == "" && macro.Status == "" && macro.Priority == "" &&
+		macro.AssigneeID == "" && len(macro.AddLabels) == 0 && len(macro.RemoveLabels) == 0 {
+		return validationError("a macro needs a comment or at least one ticket change")
+	}
+	return nil
+}
+
+func trimLabels(labels models.StringArray) models.StringArray {
+	trimmed := models.StringArray{}
+	for _, label := range labels {
+		if label = strings.TrimSpace(label); label != "" {
+			trimmed = append(trimmed, label)
+		}
+	}
+	return trimmed
+}
+
+// macroUpdate builds the ticket update the macro makes; changed is false when the macro
+// would leave every field as it is
+func macroUpdate(actor Actor, macro *tenant_models.Macro, ticket *tenant_models.TicketResponse) (*tenant_models.TicketUpdateRequest, bool) {
+	update := &tenant_models.TicketUpdateRequest{}
+	changed := false
+
+	if macro.Status != "" && macro.Status != string(ticket.Status) {
+		status := tenant_models.TicketStatus(macro.Status)
+		update.Status = &status
+		changed = true
+	}
+	if macro.Priority != "" && macro.Priority != string(ticket.Priority) {
+		priority := tenant_models.TicketPriority(macro.Priority)
+		update.Priority = &priority
+		changed = true
+	}
+
+	assigneeID := macro.AssigneeID
+	switch assigneeID {
+	case tenant_models.MacroAssignMe:
+		assigneeID = actor.UserID
+	case tenant_models.MacroAssignNone:
+		assigneeID = ""
+	}
+	if macro.AssigneeID != "" && assigneeID != stringValue(ticket.AssigneeID) {
+		update.AssigneeID = &assigneeID
+		changed = true
+	}
+
+	labels := make([]string, 0, len(ticket.Labels)+len(macro.AddLabels))
+	for _, label := range ticket.Labels {
+		if !macro.RemoveLabels.Contains(label) {
+			labels = append(labels, label)
+		}
+	}
+	for _, label := range macro.AddLabels {
+		if !containsString(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	if !sameValues(labels, ticket.Labels) {
+		update.Labels = &labels
+		changed = true
+	}
+
+	return update, changed
+}
+
+// renderMacroText fills in the ticket and agent placeholders of a macro's comment
+func renderMacroText(text string, ticket *tenant_models.TicketResponse, agent *models.User) string {
+	return strings.NewReplacer(
+		"{{ticket.id}}", ticket.ID,
+		"{{ticket.number}}", strconv.Itoa(ticket.TicketNumber),
+		"{{ticket.title}}", ticket.Title,
+		"{{ticket.status}}", string(ticket.Status),
+		"{{ticket.priority}}", string(ticket.Priority),
+		"{{ticket.type}}", string(ticket.TicketType),
+		"{{ticket.category}}", ticket.Category,
+		"{{ticket.customer_name}}", ticket.CustomerName,
+		"{{ticket.customer_email}}", ticket.CustomerEmail,
+		"{{agent.first_name}}", agent.FirstName,
+		"{{agent.last_name}}", agent.LastName,
+		"{{agent.name}}", strings.TrimSpace(agent.FirstName+" "+agent.LastName),
+		"{{agent.email}}", agent.Email,
+	).Replace(text)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// OutboundEmail is a plain text message sent on a ticket's thread
+type OutboundEmail struct {
+	From       mail.Address
+	To         mail.Address
+	ReplyTo    string
+	Subject    string
+	MessageID  string // Without angle brackets
+	InReplyTo  string
+	References []string
+	Body       string
+}
+
+// Mailer delivers outbound email
+type Mailer interface {
+	Enabled() bool
+	Send(email *OutboundEmail) error
+}
+
+// SMTPConfig holds the relay the mailer submits through; an empty Host disables delivery
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+}
+
+type smtpMailer struct {
+	config SMTPConfig
+}
+
+func NewSMTPMailer(config SMTPConfig) Mailer {
+	return &smtpMailer{config: config}
+}
+
+func (m *smtpMailer) Enabled() bool {
+	return m.config.Host != ""
+}
+
+func (m *smtpMailer) Send(email *OutboundEmail) error {
+	if !m.Enabled() {
+		return fmt.Errorf("email delivery is not configured")
+	}
+
+	message, err := buildMessage(email)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	if err := smtp.SendMail(addr, auth, email.From.Address, []string{email.To.Address}, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// buildMessage renders the RFC 5322 message with threading headers
+func buildMessage(email *OutboundEmail) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+
+	header("From", email.From.String())
+	header("To", email.To.String())
+	if email.ReplyTo != "" {
+		header("Reply-To", email.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+email.MessageID+">")
+	if email.InReplyTo != "" {
+		header("In-Reply-To", "<"+email.InReplyTo+">")
+	}
+	if len(email.References) > 0 {
+		header("References", "<"+strings.Join(email.References, "> <")+">")
+	}
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(email.Body, "\n", "\r\n"))); err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+	if err := body.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/auth"
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// PortalSettings configures customer sign-in to the support portal
+type PortalSettings struct {
+	BaseURL       string        // Portal web app; magic links open BaseURL/verify. Empty sends codes only.
+	CodeTTL       time.Duration // How long an emailed code or link stays valid
+	MaxAttempts   int           // Wrong codes allowed before the sign-in is void
+	LoginsPerHour int           // Sign-in emails sent to one address per hour
+}
+
+// PortalCustomer is a signed-in requester: an email address within a tenant
+type PortalCustomer struct {
+	TenantID string
+	Email    string
+}
+
+// PortalService lets customers without accounts follow the tickets filed under their
+// email address. They see public comments only, and everything they post is authored by
+// the system on behalf of their address, as inbound email is.
+type PortalService interface {
+	RequestLogin(req *tenant_models.PortalLoginRequest) error
+	Verify(req *tenant_models.PortalVerifyRequest) (*tenant_models.PortalSessionResponse, error)
+
+	ListTickets(customer PortalCustomer, limit, offset int) ([]tenant_models.PortalTicketResponse, int64, error)
+	GetTicket(customer PortalCustomer, ticketID string) (*tenant_models.PortalTicketDetail, error)
+	Reply(customer PortalCustomer, ticketID string, req *tenant_models.PortalReplyRequest) (*tenant_models.PortalCommentResponse, error)
+	UploadAttachment(customer PortalCustomer, ticketID, filename, mimeType string, data []byte) (*tenant_models.PortalAttachmentResponse, error)
+	OpenAttachment(customer PortalCustomer, ticketID, attachmentID string) (*tenant_models.Attachment, error)
+}
+
+type portalService struct {
+	tenants     repositories.TenantRepository
+	logins      repositories.PortalLoginRepository
+	tickets     TicketService
+	ticketRepo  repositories.TicketRepository
+	attachments AttachmentStore
+	replies     EmailReplyService
+	jwt         *auth.PortalJWTService
+	settings    PortalSettings
+	logger      *zap.Logger
+}
+
+func NewPortalService(tenants repositories.TenantRepository, logins repositories.PortalLoginRepository, tickets TicketService, ticketRepo repositories.TicketRepository, attachments AttachmentStore, replies EmailReplyService, jwt *auth.PortalJWTService, settings PortalSettings, logger *zap.Logger) PortalService {
+	settings.BaseURL = strings.TrimRight(settings.BaseURL, "/")
+	return &portalService{
+		tenants:     tenants,
+		logins:      logins,
+		tickets:     tickets,
+		ticketRepo:  ticketRepo,
+		attachments: attachments,
+		replies:     replies,
+		jwt:         jwt,
+		settings:    settings,
+		logger:      logger,
+	}
+}
+
+// errPortalLogin is the one answer to every failed sign-in, so callers cannot tell a
+// wrong code from an unknown address
+var errPortalLogin = accessDenied("invalid or expired sign-in code")
+
+// RequestLogin emails a one-time code and magic link. It succeeds without sending
+// anything when the address has no tickets or asked too often, so the answer never
+// reveals who the tenant's customers are.
+func (s *portalService) RequestLogin(req *tenant_models.PortalLoginRequest) error {
+	tenant, err := s.portalTenant(req.Tenant)
+	if err != nil {
+		return err
+	}
+	email := normalizeEmail(req.Email)
+
+	_, total, err := s.ticketRepo.ListByCustomerEmail(tenant.ID, email, 1, 0)
+	if err != nil {
+		return fmt.Errorf("failed to look up customer tickets: %w", err)
+	}
+	if total == 0 {
+		s.logger.Info("Portal sign-in requested for an address without tickets", zap.String("tenant_id", tenant.ID))
+		return nil
+	}
+
+	now := time.Now()
+	recent, err := s.logins.CountSince(tenant.ID, email, now.Add(-time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to count portal sign-ins: %w", err)
+	}
+	if s.settings.LoginsPerHour > 0 && recent >= int64(s.settings.LoginsPerHour) {
+		s.logger.Warn("Portal sign-in rate limit reached", zap.String("tenant_id", tenant.ID))
+		return nil
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return err
+	}
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	login := &tenant_models.PortalLogin{
+		Email:     email,
+		CodeHash:  hashSecret(code),
+		TokenHash: hashSecret(token),
+		ExpiresAt: now.Add(s.settings.CodeTTL),
+	}
+	if err := s.logins.Create(tenant.ID, login); err != nil {
+		return fmt.Errorf("failed to create portal sign-in: %w", err)
+	}
+
+	subject := fmt.Sprintf("Your sign-in code for %s support", tenant.Name)
+	body := s.loginEmail(tenant, code, token)
+	go func() {
+		if err := s.replies.SendNotification(tenant.ID, []mail.Address{{Address: email}}, subject, body); err != nil {
+			s.logger.Warn("Failed to email portal sign-in code", zap.Error(err), zap.String("tenant_id", tenant.ID))
+		}
+	}()
+	return nil
+}
+
+func (s *portalService) loginEmail(tenant *models.Tenant, code, token string) string {
+	minutes := int(s.settings.CodeTTL.Minutes())
+	var body strings.Builder
+	fmt.Fprintf(&body, "Your code to view your %s support requests is %s.\n", tenant.Name, code)
+	if s.settings.BaseURL != "" {
+		link := fmt.Sprintf("%s/verify?tenant=%s&token=%s", s.settings.BaseURL, url.QueryEscape(tenant.Subdomain), token)
+		fmt.Fprintf(&body, "\nOr sign in with this link:\n%s\n", link)
+	}
+	fmt.Fprintf(&body, "\nIt expires in %d minutes. If you did not ask to sign in, you can ignore this email.", minutes)
+	return body.String()
+}
+
+// Verify exchanges a magic link token, or an address and its code, for a portal session
+func (s *portalService) Verify(req *tenant_models.PortalVerifyRequest) (*tenant_models.PortalSessionResponse, error) {
+	tenant, err := s.portalTenant(req.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	var login *tenant_models.PortalLogin
+	switch {
+	case req.Token != "":
+		login, err = s.logins.GetByTokenHash(tenant.ID, hashSecret(req.Token))
+	case req.Email != "" && req.Code != "":
+		login, err = s.logins.GetLatest(tenant.ID, normalizeEmail(req.Email))
+	default:
+		return nil, validationError("either token or email and code are required")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errPortalLogin
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portal sign-in: %w", err)
+	}
+
+	now := time.Now()
+	if login.UsedAt != nil || now.After(login.ExpiresAt) {
+		return nil, errPortalLogin
+	}
+	if req.Token == "" {
+		if s.settings.MaxAttempts > 0 && login.Attempts >= s.settings.MaxAttempts {
+			return nil, errPortalLogin
+		}
+		if subtle.ConstantTimeCompare([]byte(hashSecret(req.Code)), []byte(login.CodeHash)) != 1 {
+			if err := s.logins.RecordAttempt(tenant.ID, login.ID); err != nil {
+				s.logger.Warn("Failed to record portal sign-in attempt", zap.Error(err), zap.String("login_id", login.ID))
+			}
+			return nil, errPortalLogin
+		}
+	}
+
+	// Codes and links are single use, even when two requests race
+	used, err := s.logins.MarkUsed(tenant.ID, login.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use portal sign-in: %w", err)
+	}
+	if !used {
+		return nil, errPortalLogin
+	}
+
+	token, err := s.jwt.GenerateToken(tenant.ID, login.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Customer signed in to the portal", zap.String("tenant_id", tenant.ID))
+	return &tenant_models.PortalSessionResponse{
+		Token:     token,
+		ExpiresIn: s.jwt.GetTokenTTL(),
+		Email:     login.Email,
+	}, nil
+}
+
+func (s *portalService) ListTickets(customer PortalCustomer, limit, offset int) ([]tenant_models.PortalTicketResponse, int64, error) {
+	tickets, total, err := s.ticketRepo.ListByCustomerEmail(customer.TenantID, customer.Email, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list customer tickets: %w", err)
+	}
+
+	responses := make([]tenant_models.PortalTicketResponse, 0, len(tickets))
+	for _, ticket := range tickets {
+		responses = append(responses, ticket.ToPortalResponse())
+	}
+	return responses, total, nil
+}
+
+func (s *portalService) GetTicket(customer PortalCustomer, ticketID string) (*tenant_models.PortalTicketDetail, error) {
+	ticket, err := s.customerTicket(customer, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.ticketRepo.GetComments(customer.TenantID, ticket.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	attachments, err := s.visibleAttachments(customer.TenantID, ticket.ID, comments)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &tenant_models.PortalTicketDetail{
+		PortalTicketResponse: ticket.ToPortalResponse(),
+		Comments:             make([]tenant_models.PortalCommentResponse, 0, len(comments)),
+		Attachments:          make([]tenant_models.PortalAttachmentResponse, 0, len(attachments)),
+	}
+	for _, comment := range comments {
+		detail.Comments = append(detail.Comments, portalComment(comment))
+	}
+	for _, attachment := range attachments {
+		detail.Attachments = append(detail.Attachments, attachment.ToPortalResponse())
+	}
+	return detail, nil
+}
+
+// Reply adds a public comment from the customer, through the same path as emailed replies
+func (s *portalService) Reply(customer PortalCustomer, ticketID string, req *tenant_models.PortalReplyRequest) (*tenant_models.PortalCommentResponse, error) {
+	ticket, err := s.customerTicket(customer, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, validationError("reply cannot be empty")
+	}
+
+	actor := Actor{UserID: tenant_models.SystemUserID, TenantID: customer.TenantID, Role: models.MembershipRoleAdmin}
+	created, err := s.tickets.CreateComment(actor, ticket.ID, &tenant_models.CommentCreateRequest{
+		Body:        body,
+		AuthorEmail: customer.Email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add portal reply: %w", err)
+	}
+
+	return &tenant_models.PortalCommentResponse{
+		ID:           created.ID,
+		Body:         created.Body,
+		FromCustomer: true,
+		CreatedAt:    created.CreatedAt,
+	}, nil
+}
+
+func (s *portalService) UploadAttachment(customer PortalCustomer, ticketID, filename, mimeType string, data []byte) (*tenant_models.PortalAttachmentResponse, error) {
+	ticket, err := s.customerTicket(customer, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, validationError("file is empty")
+	}
+
+	stored, path, err := s.attachments.Save(customer.TenantID, filename, data)
+	if err != nil {
+		return nil, err
+	}
+	if mimeType == "" || len(mimeType) > 100 {
+		mimeType = "application/octet-stream"
+	}
+	attachment := &tenant_models.Attachment{
+		TicketID:         &ticket.ID,
+		Filename:         stored,
+		OriginalFilename: truncateRunes(filename, 255),
+		FileSize:         int64(len(data)),
+		MimeType:         mimeType,
+		FilePath:         path,
+		UploadedBy:       tenant_models.SystemUserID,
+	}
+	if err := s.ticketRepo.CreateAttachment(customer.TenantID, attachment); err != nil {
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	s.logger.Info("Customer uploaded an attachment through the portal",
+		zap.String("ticket_id", ticket.ID),
+		zap.String("attachment_id", attachment.ID))
+
+	response := attachment.ToPortalResponse()
+	return &response, nil
+}
+
+// OpenAttachment returns an attachment the customer may download: one on the ticket itself
+// or on one of its public comments
+func (s *portalService) OpenAttachment(customer PortalCustomer, ticketID, attachmentID string) (*tenant_models.Attachment, error) {
+	ticket, err := s.customerTicket(customer, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.ticketRepo.GetAttachment(customer.TenantID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.TicketID != nil && *attachment.TicketID == ticket.ID {
+		return attachment, nil
+	}
+
+	if attachment.CommentID != nil {
+		comments, err := s.ticketRepo.GetComments(customer.TenantID, ticket.ID, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comments: %w", err)
+		}
+		for _, comment := range comments {
+			if comment.ID == *attachment.CommentID {
+				return attachment, nil
+			}
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// portalTenant resolves the tenant a customer signs in to; only active tenants have a portal
+func (s *portalService) portalTenant(subdomain string) (*models.Tenant, error) {
+	tenant, err := s.tenants.GetBySubdomain(strings.TrimSpace(subdomain))
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Status != models.TenantStatusActive {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return tenant, nil
+}
+
+// customerTicket loads a ticket filed under the customer's address. Other tickets are
+// reported as not found rather than forbidden, so their existence is not revealed.
+func (s *portalService) customerTicket(customer PortalCustomer, ticketID string) (*tenant_models.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(customer.TenantID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(ticket.CustomerEmail, customer.Email) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return ticket, nil
+}
+
+// visibleAttachments lists the files on the ticket and on its public comments
+func (s *portalService) visibleAttachments(tenantID, ticketID string, comments []*tenant_models.Comment) ([]*tenant_models.Attachment, error) {
+	attachments, err := s.ticketRepo.GetAttachments(tenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	commentIDs := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		commentIDs = append(commentIDs, comment.ID)
+	}
+	onComments, err := s.ticketRepo.GetCommentAttachments(tenantID, commentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment attachments: %w", err)
+	}
+	return append(attachments, onComments...), nil
+}
+
+// portalComment hides who on the support team wrote a comment; the customer's own
+// replies are those posted from an address rather than by an agent
+func portalComment(comment *tenant_models.Comment) tenant_models.PortalCommentResponse {
+	return tenant_models.PortalCommentResponse{
+		ID:           comment.ID,
+		Body:         comment.Body,
+		FromCustomer: comment.AuthorEmail != "",
+		CreatedAt:    comment.CreatedAt,
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// randomCode returns a six-digit one-time code
+func randomCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate sign-in code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// randomToken returns a 256-bit token for magic links
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate sign-in token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,553 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+type RoutingService interface {
+	// Agent groups
+	CreateGroup(userID, tenantID string, req *tenant_models.AgentGroupCreateRequest) (*tenant_models.AgentGroupResponse, error)
+	GetGroup(userID, tenantID, groupID string) (*tenant_models.AgentGroupResponse, error)
+	UpdateGroup(userID, tenantID, groupID string, req *tenant_models.AgentGroupUpdateRequest) (*tenant_models.AgentGroupResponse, error)
+	DeleteGroup(userID, tenantID, groupID string) error
+	ListGroups(userID, tenantID string) ([]*tenant_models.AgentGroupResponse, error)
+	AddMember(userID, tenantID, groupID string, req *tenant_models.AgentGroupMemberRequest) (*tenant_models.AgentGroupMember, error)
+	UpdateMember(userID, tenantID, groupID, memberUserID string, req *tenant_models.AgentGroupMemberUpdateRequest) (*tenant_models.AgentGroupMember, error)
+	RemoveMember(userID, tenantID, groupID, memberUserID string) error
+
+	// Agent availability
+	ListAvailability(userID, tenantID string) ([]*tenant_models.AgentAvailabilityResponse, error)
+	GetAvailability(tenantID, agentID string) (*tenant_models.AgentAvailabilityResponse, error)
+	SetAvailability(tenantID, agentID string, req *tenant_models.AgentAvailabilityRequest) (tenant_models.AgentStatus, *tenant_models.AgentAvailabilityResponse, error)
+
+	// Routing
+	Route(tenantID string, ticket *tenant_models.Ticket) (*RouteDecision, error)
+}
+
+// RouteDecision is where routing puts a ticket; an empty AssigneeID leaves it in the
+// group's queue
+type RouteDecision struct {
+	GroupID    string
+	AssigneeID string
+}
+
+type routingService struct {
+	repo    repositories.RoutingRepository
+	tickets repositories.TicketRepository
+	members repositories.ProjectMemberRepository
+	logger  *zap.Logger
+}
+
+func NewRoutingService(repo repositories.RoutingRepository, tickets repositories.TicketRepository, members repositories.ProjectMemberRepository, logger *zap.Logger) RoutingService {
+	return &routingService{
+		repo:    repo,
+		tickets: tickets,
+		members: members,
+		logger:  logger,
+	}
+}
+
+// Group methods
+func (s *routingService) CreateGroup(userID, tenantID string, req *tenant_models.AgentGroupCreateRequest) (*tenant_models.AgentGroupResponse, error) {
+	group := &tenant_models.AgentGroup{
+		Name:        req.Name,
+		Description: req.Description,
+		Strategy:    req.Strategy,
+		ProjectID:   req.ProjectID,
+		TicketTypes: models.StringArray(req.TicketTypes),
+		Priorities:  models.StringArray(req.Priorities),
+		Channels:    models.StringArray(req.Channels),
+		Labels:      models.StringArray(req.Labels),
+		SortOrder:   req.SortOrder,
+		IsActive:    true,
+	}
+	if group.Strategy == "" {
+		group.Strategy = tenant_models.AssignRoundRobin
+	}
+
+	if err := s.repo.CreateGroup(tenantID, group); err != nil {
+		s.logger.Error("Failed to create agent group", zap.Error(err), zap.String("tenant_id", tenantID))
+		return nil, fmt.Errorf("failed to create agent group: %w", err)
+	}
+
+	s.logger.Info("Agent group created",
+		zap.String("group_id", group.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	response := group.ToResponse()
+	return &response, nil
+}
+
+func (s *routingService) GetGroup(userID, tenantID, groupID string) (*tenant_models.AgentGroupResponse, error) {
+	group, err := s.repo.GetGroup(tenantID, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent group: %w", err)
+	}
+
+	response := group.ToResponse()
+	return &response, nil
+}
+
+func (s *routingService) UpdateGroup(userID, tenantID, groupID string, req *tenant_models.AgentGroupUpdateRequest) (*tenant_models.AgentGroupResponse, error) {
+	group, err := s.repo.GetGroup(tenantID, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent group: %w", err)
+	}
+
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.Strategy != nil {
+		group.Strategy = *req.Strategy
+	}
+	if req.ProjectID != nil {
+		group.ProjectID = optionalString(*req.ProjectID)
+	}
+	if req.TicketTypes != nil {
+		group.TicketTypes = models.StringArray(*req.TicketTypes)
+	}
+	if req.Priorities != nil {
+		group.Priorities = models.StringArray(*req.Priorities)
+	}
+	if req.Channels != nil {
+		group.Channels = models.StringArray(*req.Channels)
+	}
+	if req.Labels != nil {
+		group.Labels = models.StringArray(*req.Labels)
+	}
+	if req.SortOrder != nil {
+		group.SortOrder = *req.SortOrder
+	}
+	if req.IsActive != nil {
+		group.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdateGroup(tenantID, group); err != nil {
+		return nil, fmt.Errorf("failed to update agent group: %w", err)
+	}
+
+	s.logger.Info("Agent group updated",
+		zap.String("group_id", groupID),
+		zap.String("tenant_id", tenantID),
+		zap.String("updated_by", userID))
+
+	response := group.ToResponse()
+	return &response, nil
+}
+
+func (s *routingService) DeleteGroup(userID, tenantID, groupID string) error {
+	if _, err := s.repo.GetGroup(tenantID, groupID); err != nil {
+		return fmt.Errorf("failed to get agent group: %w", err)
+	}
+
+	if err := s.repo.DeleteGroup(tenantID, groupID); err != nil {
+		return fmt.Errorf("failed to delete agent group: %w", err)
+	}
+
+	s.logger.Info("Agent group deleted",
+		zap.String("group_id", groupID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+func (s *routingService) ListGroups(userID, tenantID string) ([]*tenant_models.AgentGroupResponse, error) {
+	groups, err := s.repo.ListGroups(tenantID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent groups: %w", err)
+	}
+
+	responses := make([]*tenant_models.AgentGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		response := group.ToResponse()
+		responses = append(responses, &response)
+	}
+
+	return responses, nil
+}
+
+// Member methods
+func (s *routingService) AddMember(userID, tenantID, groupID string, req *tenant_models.AgentGroupMemberRequest) (*tenant_models.AgentGroupMember, error) {
+	if _, err := s.repo.GetGroup(tenantID, groupID); err != nil {
+		return nil, fmt.Errorf("failed to get agent group: %w", err)
+	}
+
+	_, err := s.repo.GetMember(tenantID, groupID, req.UserID)
+	if err == nil {
+		return nil, validationError("user %s is already a member of this group", req.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check group membership: %w", err)
+	}
+
+	member := &tenant_models.AgentGroupMember{
+		GroupID:   groupID,
+		UserID:    req.UserID,
+		Skills:    models.StringArray(req.Skills),
+		Languages: models.StringArray(req.Languages),
+		AddedAt:   time.Now(),
+	}
+	if err := s.repo.AddMember(tenantID, member); err != nil {
+		return nil, fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	s.logger.Info("Agent added to group",
+		zap.String("group_id", groupID),
+		zap.String("user_id", req.UserID),
+		zap.String("added_by", userID))
+
+	return member, nil
+}
+
+func (s *routingService) UpdateMember(userID, tenantID, groupID, memberUserID string, req *tenant_models.AgentGroupMemberUpdateRequest) (*tenant_models.AgentGroupMember, error) {
+	member, err := s.repo.GetMember(tenantID, groupID, memberUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group member: %w", err)
+	}
+
+	if req.Skills != nil {
+		member.Skills = models.StringArray(*req.Skills)
+	}
+	if req.Languages != nil {
+		member.Languages = models.StringArray(*req.Languages)
+	}
+
+	if err := s.repo.UpdateMember(tenantID, member); err != nil {
+		return nil, fmt.Errorf("failed to update group member: %w", err)
+	}
+	return member, nil
+}
+
+func (s *routingService) RemoveMember(userID, tenantID, groupID, memberUserID string) error {
+	if err := s.repo.RemoveMember(tenantID, groupID, memberUserID); err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+
+	s.logger.Info("Agent removed from group",
+		zap.String("group_id", groupID),
+		zap.String("user_id", memberUserID),
+		zap.String("removed_by", userID))
+
+	return nil
+}
+
+// Availability methods
+
+// ListAvailability reports every agent who has set their availability, with their load
+func (s *routingService) ListAvailability(userID, tenantID string) ([]*tenant_models.AgentAvailabilityResponse, error) {
+	records, err := s.repo.ListAvailability(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent availability: %w", err)
+	}
+
+	agentIDs := make([]string, 0, len(records))
+	for _, record := range records {
+		agentIDs = append(agentIDs, record.UserID)
+	}
+	open, err := s.tickets.CountOpenByAssignees(tenantID, agentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open tickets: %w", err)
+	}
+
+	responses := make([]*tenant_models.AgentAvailabilityResponse, 0, len(records))
+	for _, record := range records {
+		responses = append(responses, availabilityResponse(record, open[record.UserID]))
+	}
+	return responses, nil
+}
+
+func (s *routingService) GetAvailability(tenantID, agentID string) (*tenant_models.AgentAvailabilityResponse, error) {
+	record, err := s.availabilityOf(tenantID, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := s.tickets.CountOpenByAssignees(tenantID, []string{agentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open tickets: %w", err)
+	}
+	return availabilityResponse(record, open[agentID]), nil
+}
+
+// SetAvailability stores the agent's availability and returns the status it replaced
+func (s *routingService) SetAvailability(tenantID, agentID string, req *tenant_models.AgentAvailabilityRequest) (tenant_models.AgentStatus, *tenant_models.AgentAvailabilityResponse, error) {
+	record, err := s.availabilityOf(tenantID, agentID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	previous := record.Status
+	record.Status = req.Status
+	if req.MaxOpenTickets != nil {
+		record.MaxOpenTickets = *req.MaxOpenTickets
+	}
+	record.UpdatedAt = time.Now()
+	if err := s.repo.SaveAvailability(tenantID, record); err != nil {
+		return "", nil, fmt.Errorf("failed to save agent availability: %w", err)
+	}
+
+	s.logger.Info("Agent availability changed",
+		zap.String("user_id", agentID),
+		zap.String("tenant_id", tenantID),
+		zap.String("status", string(record.Status)))
+
+	open, err := s.tickets.CountOpenByAssignees(tenantID, []string{agentID})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to count open tickets: %w", err)
+	}
+	return previous, availabilityResponse(record, open[agentID]), nil
+}
+
+// availabilityOf returns the agent's recorded availability, or the online default
+func (s *routingService) availabilityOf(tenantID, agentID string) (*tenant_models.AgentAvailability, error) {
+	records, err := s.repo.GetAvailability(tenantID, []string{agentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent availability: %w", err)
+	}
+	if record, ok := records[agentID]; ok {
+		return record, nil
+	}
+	return &tenant_models.AgentAvailability{UserID: agentID, Status: tenant_models.AgentOnline}, nil
+}
+
+func availabilityResponse(record *tenant_models.AgentAvailability, open int64) *tenant_models.AgentAvailabilityResponse {
+	return &tenant_models.AgentAvailabilityResponse{
+		UserID:         record.UserID,
+		Status:         record.Status,
+		MaxOpenTickets: record.MaxOpenTickets,
+		OpenTickets:    open,
+		UpdatedAt:      record.UpdatedAt,
+	}
+}
+
+// Routing
+
+// Route picks the ticket's group, the one it names or else the first matching active group,
+// and an agent in it. It returns nil when no group takes the ticket.
+func (s *routingService) Route(tenantID string, ticket *tenant_models.Ticket) (*RouteDecision, error) {
+	var group *tenant_models.AgentGroup
+	if ticket.GroupID != nil {
+		named, err := s.repo.GetGroup(tenantID, *ticket.GroupID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, validationError("agent group %s not found", *ticket.GroupID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agent group: %w", err)
+		}
+		group = named
+	} else {
+		groups, err := s.repo.ListGroups(tenantID, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list agent groups: %w", err)
+		}
+		group = matchAgentGroup(groups, ticket)
+	}
+	if group == nil {
+		return nil, nil
+	}
+
+	decision := &RouteDecision{GroupID: group.ID}
+	if !group.IsActive || group.Strategy == tenant_models.AssignManual {
+		return decision, nil
+	}
+
+	member, err := s.pickAgent(tenantID, group, ticket)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return decision, nil
+	}
+
+	decision.AssigneeID = member.UserID
+	if err := s.repo.MarkAssigned(tenantID, member.ID, time.Now()); err != nil {
+		s.logger.Warn("Failed to record round-robin turn", zap.Error(err), zap.String("group_id", group.ID))
+	}
+	return decision, nil
+}
+
+// routingCandidate is a group member who can take the ticket right now
+type routingCandidate struct {
+	member *tenant_models.AgentGroupMember
+	open   int64
+	score  int // Skills matched, skills strategy only
+}
+
+// pickAgent chooses among the group's online members with spare capacity who may work
+// in the ticket's project; it returns nil when none can take the ticket
+func (s *routingService) pickAgent(tenantID string, group *tenant_models.AgentGroup, ticket *tenant_models.Ticket) (*tenant_models.AgentGroupMember, error) {
+	if len(group.Members) == 0 {
+		return nil, nil
+	}
+
+	agentIDs := make([]string, 0, len(group.Members))
+	for _, member := range group.Members {
+		agentIDs = append(agentIDs, member.UserID)
+	}
+	availability, err := s.repo.GetAvailability(tenantID, agentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent availability: %w", err)
+	}
+	open, err := s.tickets.CountOpenByAssignees(tenantID, agentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open tickets: %w", err)
+	}
+
+	var candidates []routingCandidate
+	for i := range group.Members {
+		member := &group.Members[i]
+		if record, ok := availability[member.UserID]; ok {
+			if record.Status != tenant_models.AgentOnline {
+				continue
+			}
+			if record.MaxOpenTickets > 0 && open[member.UserID] >= int64(record.MaxOpenTickets) {
+				continue
+			}
+		}
+		if ticket.ProjectID != nil {
+			membership, err := s.members.GetMembership(tenantID, *ticket.ProjectID, member.UserID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to check project membership: %w", err)
+			}
+			if membership.Role == tenant_models.ProjectRoleViewer {
+				continue
+			}
+		}
+		candidates = append(candidates, routingCandidate{member: member, open: open[member.UserID]})
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	switch group.Strategy {
+	case tenant_models.AssignLeastOpen:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].open != candidates[j].open {
+				return candidates[i].open < candidates[j].open
+			}
+			return assignedEarlier(candidates[i].member, candidates[j].member)
+		})
+	case tenant_models.AssignSkills:
+		candidates = speakersOf(candidates, ticket.Language)
+		for i := range candidates {
+			candidates[i].score = skillScore(candidates[i].member, ticket)
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].score != candidates[j].score {
+				return candidates[i].score > candidates[j].score
+			}
+			if candidates[i].open != candidates[j].open {
+				return candidates[i].open < candidates[j].open
+			}
+			return assignedEarlier(candidates[i].member, candidates[j].member)
+		})
+	default:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return assignedEarlier(candidates[i].member, candidates[j].member)
+		})
+	}
+	return candidates[0].member, nil
+}
+
+// matchAgentGroup returns the first group whose conditions all hold for the ticket
+func matchAgentGroup(groups []*tenant_models.AgentGroup, ticket *tenant_models.Ticket) *tenant_models.AgentGroup {
+	for _, group := range groups {
+		if group.ProjectID != nil && *group.ProjectID != stringValue(ticket.ProjectID) {
+			continue
+		}
+		if len(group.TicketTypes) > 0 && !group.TicketTypes.Contains(string(ticket.TicketType)) {
+			continue
+		}
+		if len(group.Priorities) > 0 && !group.Priorities.Contains(string(ticket.Priority)) {
+			continue
+		}
+		if len(group.Channels) > 0 && !group.Channels.Contains(string(ticket.Channel)) {
+			continue
+		}
+		if len(group.Labels) > 0 && !sharesLabel(group.Labels, ticket.Labels) {
+			continue
+		}
+		return group
+	}
+	return nil
+}
+
+func sharesLabel(wanted, labels models.StringArray) bool {
+	for _, label := range labels {
+		if wanted.Contains(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignedEarlier orders members by whose turn it is: never assigned first, then the
+// longest since their last ticket
+func assignedEarlier(a, b *tenant_models.AgentGroupMember) bool {
+	switch {
+	case a.LastAssignedAt == nil && b.LastAssignedAt == nil:
+		return a.AddedAt.Before(b.AddedAt)
+	case a.LastAssignedAt == nil:
+		return true
+	case b.LastAssignedAt == nil:
+		return false
+	default:
+		return a.LastAssignedAt.Before(*b.LastAssignedAt)
+	}
+}
+
+// speakersOf narrows the candidates to those who speak the ticket's language, unless
+// nobody does
+func speakersOf(candidates []routingCandidate, language string) []routingCandidate {
+	if language == "" {
+		return candidates
+	}
+
+	var speakers []routingCandidate
+	for _, candidate := range candidates {
+		for _, spoken := range candidate.member.Languages {
+			if strings.EqualFold(spoken, language) {
+				speakers = append(speakers, candidate)
+				break
+			}
+		}
+	}
+	if len(speakers) == 0 {
+		return candidates
+	}
+	return speakers
+}
+
+// skillScore counts the ticket's type and labels the member has as skills
+func skillScore(member *tenant_models.AgentGroupMember, ticket *tenant_models.Ticket) int {
+	wanted := append([]string{string(ticket.TicketType)}, ticket.Labels...)
+	score := 0
+	for _, skill := range member.Skills {
+		for _, want := range wanted {
+			if strings.EqualFold(skill, want) {
+				score++
+				break
+			}
+		}
+	}
+	return score
+}
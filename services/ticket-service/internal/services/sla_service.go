@@ -0,0 +1,667 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// defaultPauseStatuses stop the SLA clock when a policy does not list its own
+var defaultPauseStatuses = []string{"on_hold"}
+
+type SLAService interface {
+	// Policies
+	CreatePolicy(userID, tenantID string, req *tenant_models.SLAPolicyCreateRequest) (*tenant_models.SLAPolicyResponse, error)
+	GetPolicy(userID, tenantID, policyID string) (*tenant_models.SLAPolicyResponse, error)
+	UpdatePolicy(userID, tenantID, policyID string, req *tenant_models.SLAPolicyUpdateRequest) (*tenant_models.SLAPolicyResponse, error)
+	DeletePolicy(userID, tenantID, policyID string) error
+	ListPolicies(userID, tenantID string) ([]*tenant_models.SLAPolicyResponse, error)
+
+	// Business calendars
+	CreateCalendar(userID, tenantID string, req *tenant_models.BusinessCalendarCreateRequest) (*tenant_models.BusinessCalendar, error)
+	GetCalendar(userID, tenantID, calendarID string) (*tenant_models.BusinessCalendar, error)
+	UpdateCalendar(userID, tenantID, calendarID string, req *tenant_models.BusinessCalendarUpdateRequest) (*tenant_models.BusinessCalendar, error)
+	DeleteCalendar(userID, tenantID, calendarID string) error
+	ListCalendars(userID, tenantID string) ([]*tenant_models.BusinessCalendar, error)
+	AddHoliday(userID, tenantID, calendarID string, req *tenant_models.BusinessHolidayCreateRequest) (*tenant_models.BusinessHoliday, error)
+	RemoveHoliday(userID, tenantID, calendarID, holidayID string) error
+
+	// Ticket SLA clock
+	ApplyPolicy(tenantID string, subject SLASubject) (*tenant_models.TicketSLA, error)
+	PlanPolicy(tenantID string, subject SLASubject) (*tenant_models.TicketSLA, error)
+	StartClock(tenantID string, sla *tenant_models.TicketSLA) error
+	HandleStatusChange(tenantID string, subject SLASubject, at time.Time) (*tenant_models.TicketSLA, error)
+	RecordFirstResponse(tenantID, ticketID string, at time.Time) (*tenant_models.TicketSLA, error)
+	GetTicketSLA(tenantID, ticketID string) (*tenant_models.TicketSLA, error)
+	RefreshBreaches(tenantID string) error
+}
+
+// SLASubject is the ticket data SLA policies are matched and timed against
+type SLASubject struct {
+	TicketID     string
+	Priority     string
+	TicketType   string
+	Channel      string
+	Status       string
+	Resolved     bool
+	CustomFields map[string]interface{}
+	CreatedAt    time.Time
+}
+
+type slaService struct {
+	repo   repositories.SLARepository
+	logger *zap.Logger
+}
+
+func NewSLAService(repo repositories.SLARepository, logger *zap.Logger) SLAService {
+	return &slaService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Policy methods
+func (s *slaService) CreatePolicy(userID, tenantID string, req *tenant_models.SLAPolicyCreateRequest) (*tenant_models.SLAPolicyResponse, error) {
+	if req.FirstResponseMinutes == nil && req.ResolutionMinutes == nil {
+		return nil, validationError("an SLA policy needs a first response or resolution target")
+	}
+	if req.CalendarID != nil {
+		if _, err := s.repo.GetCalendar(tenantID, *req.CalendarID); err != nil {
+			return nil, validationError("business calendar %s not found", *req.CalendarID)
+		}
+	}
+
+	policy := &tenant_models.SLAPolicy{
+		Name:                 req.Name,
+		Description:          req.Description,
+		CalendarID:           req.CalendarID,
+		Priorities:           models.StringArray(req.Priorities),
+		TicketTypes:          models.StringArray(req.TicketTypes),
+		Channels:             models.StringArray(req.Channels),
+		CustomFields:         req.CustomFields,
+		FirstResponseMinutes: req.FirstResponseMinutes,
+		ResolutionMinutes:    req.ResolutionMinutes,
+		PauseStatuses:        models.StringArray(req.PauseStatuses),
+		SortOrder:            req.SortOrder,
+		IsActive:             true,
+	}
+	if policy.CustomFields == nil {
+		policy.CustomFields = make(models.JSONB)
+	}
+	if len(policy.PauseStatuses) == 0 {
+		policy.PauseStatuses = models.StringArray(defaultPauseStatuses)
+	}
+
+	if err := s.repo.CreatePolicy(tenantID, policy); err != nil {
+		s.logger.Error("Failed to create SLA policy", zap.Error(err), zap.String("tenant_id", tenantID))
+		return nil, fmt.Errorf("failed to create SLA policy: %w", err)
+	}
+
+	s.logger.Info("SLA policy created",
+		zap.String("policy_id", policy.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	response := policy.ToResponse()
+	return &response, nil
+}
+
+func (s *slaService) GetPolicy(userID, tenantID, policyID string) (*tenant_models.SLAPolicyResponse, error) {
+	policy, err := s.repo.GetPolicy(tenantID, policyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLA policy: %w", err)
+	}
+
+	response := policy.ToResponse()
+	return &response, nil
+}
+
+func (s *slaService) UpdatePolicy(userID, tenantID, policyID string, req *tenant_models.SLAPolicyUpdateRequest) (*tenant_models.SLAPolicyResponse, error) {
+	policy, err := s.repo.GetPolicy(tenantID, policyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLA policy: %w", err)
+	}
+
+	if req.Name != nil {
+		policy.Name = *req.Name
+	}
+	if req.Description != nil {
+		policy.Description = *req.Description
+	}
+	if req.CalendarID != nil {
+		if *req.CalendarID == "" {
+			policy.CalendarID = nil
+		} else {
+			if _, err := s.repo.GetCalendar(tenantID, *req.CalendarID); err != nil {
+				return nil, validationError("business calendar %s not found", *req.CalendarID)
+			}
+			policy.CalendarID = req.CalendarID
+		}
+	}
+	if req.Priorities != nil {
+		policy.Priorities = models.StringArray(req.Priorities)
+	}
+	if req.TicketTypes != nil {
+		policy.TicketTypes = models.StringArray(req.TicketTypes)
+	}
+	if req.Channels != nil {
+		policy.Channels = models.StringArray(req.Channels)
+	}
+	if req.CustomFields != nil {
+		policy.CustomFields = *req.CustomFields
+	}
+	if req.FirstResponseMinutes != nil {
+		policy.FirstResponseMinutes = req.FirstResponseMinutes
+	}
+	if req.ResolutionMinutes != nil {
+		policy.ResolutionMinutes = req.ResolutionMinutes
+	}
+	if req.PauseStatuses != nil {
+		policy.PauseStatuses = models.StringArray(req.PauseStatuses)
+	}
+	if req.SortOrder != nil {
+		policy.SortOrder = *req.SortOrder
+	}
+	if req.IsActive != nil {
+		policy.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdatePolicy(tenantID, policy); err != nil {
+		return nil, fmt.Errorf("failed to update SLA policy: %w", err)
+	}
+
+	s.logger.Info("SLA policy updated",
+		zap.String("policy_id", policyID),
+		zap.String("tenant_id", tenantID),
+		zap.String("updated_by", userID))
+
+	response := policy.ToResponse()
+	return &response, nil
+}
+
+func (s *slaService) DeletePolicy(userID, tenantID, policyID string) error {
+	if _, err := s.repo.GetPolicy(tenantID, policyID); err != nil {
+		return fmt.Errorf("failed to get SLA policy: %w", err)
+	}
+
+	if err := s.repo.DeletePolicy(tenantID, policyID); err != nil {
+		return fmt.Errorf("failed to delete SLA policy: %w", err)
+	}
+
+	s.logger.Info("SLA policy deleted",
+		zap.String("policy_id", policyID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+func (s *slaService) ListPolicies(userID, tenantID string) ([]*tenant_models.SLAPolicyResponse, error) {
+	policies, err := s.repo.ListPolicies(tenantID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SLA policies: %w", err)
+	}
+
+	responses := make([]*tenant_models.SLAPolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		response := policy.ToResponse()
+		responses = append(responses, &response)
+	}
+
+	return responses, nil
+}
+
+// Calendar methods
+func (s *slaService) CreateCalendar(userID, tenantID string, req *tenant_models.BusinessCalendarCreateRequest) (*tenant_models.BusinessCalendar, error) {
+	calendar := &tenant_models.BusinessCalendar{
+		Name:         req.Name,
+		Timezone:     req.Timezone,
+		WorkingHours: req.WorkingHours,
+	}
+	if calendar.Timezone == "" {
+		calendar.Timezone = "UTC"
+	}
+	if _, err := newBusinessHours(calendar); err != nil {
+		return nil, validationError("%s", err.Error())
+	}
+
+	if err := s.repo.CreateCalendar(tenantID, calendar); err != nil {
+		return nil, fmt.Errorf("failed to create business calendar: %w", err)
+	}
+
+	s.logger.Info("Business calendar created",
+		zap.String("calendar_id", calendar.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	return calendar, nil
+}
+
+func (s *slaService) GetCalendar(userID, tenantID, calendarID string) (*tenant_models.BusinessCalendar, error) {
+	calendar, err := s.repo.GetCalendar(tenantID, calendarID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business calendar: %w", err)
+	}
+	return calendar, nil
+}
+
+func (s *slaService) UpdateCalendar(userID, tenantID, calendarID string, req *tenant_models.BusinessCalendarUpdateRequest) (*tenant_models.BusinessCalendar, error) {
+	calendar, err := s.repo.GetCalendar(tenantID, calendarID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business calendar: %w", err)
+	}
+
+	if req.Name != nil {
+		calendar.Name = *req.Name
+	}
+	if req.Timezone != nil {
+		calendar.Timezone = *req.Timezone
+	}
+	if req.WorkingHours != nil {
+		calendar.WorkingHours = *req.WorkingHours
+	}
+	if _, err := newBusinessHours(calendar); err != nil {
+		return nil, validationError("%s", err.Error())
+	}
+
+	if err := s.repo.UpdateCalendar(tenantID, calendar); err != nil {
+		return nil, fmt.Errorf("failed to update business calendar: %w", err)
+	}
+
+	return calendar, nil
+}
+
+func (s *slaService) DeleteCalendar(userID, tenantID, calendarID string) error {
+	if _, err := s.repo.GetCalendar(tenantID, calendarID); err != nil {
+		return fmt.Errorf("failed to get business calendar: %w", err)
+	}
+
+	if err := s.repo.DeleteCalendar(tenantID, calendarID); err != nil {
+		return fmt.Errorf("failed to delete business calendar: %w", err)
+	}
+
+	s.logger.Info("Business calendar deleted",
+		zap.String("calendar_id", calendarID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+func (s *slaService) ListCalendars(userID, tenantID string) ([]*tenant_models.BusinessCalendar, error) {
+	calendars, err := s.repo.ListCalendars(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list business calendars: %w", err)
+	}
+	return calendars, nil
+}
+
+func (s *slaService) AddHoliday(userID, tenantID, calendarID string, req *tenant_models.BusinessHolidayCreateRequest) (*tenant_models.BusinessHoliday, error) {
+	if _, err := s.repo.GetCalendar(tenantID, calendarID); err != nil {
+		return nil, fmt.Errorf("failed to get business calendar: %w", err)
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, validationError("holiday date must be YYYY-MM-DD")
+	}
+
+	holiday := &tenant_models.BusinessHoliday{
+		CalendarID: calendarID,
+		Name:       req.Name,
+		Date:       date,
+		Recurring:  req.Recurring,
+	}
+	if err := s.repo.CreateHoliday(tenantID, holiday); err != nil {
+		return nil, fmt.Errorf("failed to create holiday: %w", err)
+	}
+
+	return holiday, nil
+}
+
+func (s *slaService) RemoveHoliday(userID, tenantID, calendarID, holidayID string) error {
+	if err := s.repo.DeleteHoliday(tenantID, calendarID, holidayID); err != nil {
+		return fmt.Errorf("failed to delete holiday: %w", err)
+	}
+	return nil
+}
+
+// ApplyPolicy matches the ticket against the tenant's policies and (re)starts its SLA clock.
+// Targets are always measured from ticket creation, extended by any time spent paused.
+func (s *slaService) ApplyPolicy(tenantID string, subject SLASubject) (*tenant_models.TicketSLA, error) {
+	existing, sla, err := s.planPolicy(tenantID, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if sla == nil {
+		if existing != nil {
+			if err := s.repo.DeleteTicketSLA(tenantID, subject.TicketID); err != nil {
+				return nil, fmt.Errorf("failed to clear ticket SLA: %w", err)
+			}
+		}
+		return nil, nil
+	}
+	if sla == existing {
+		return existing, nil
+	}
+
+	if err := s.StartClock(tenantID, sla); err != nil {
+		return nil, err
+	}
+	return sla, nil
+}
+
+// PlanPolicy returns the clock ApplyPolicy would store, without storing it, so new
+// tickets can take their due date from it before they are saved
+func (s *slaService) PlanPolicy(tenantID string, subject SLASubject) (*tenant_models.TicketSLA, error) {
+	_, sla, err := s.planPolicy(tenantID, subject)
+	return sla, err
+}
+
+// StartClock stores a clock returned by PlanPolicy
+func (s *slaService) StartClock(tenantID string, sla *tenant_models.TicketSLA) error {
+	if err := s.repo.SaveTicketSLA(tenantID, sla); err != nil {
+		return fmt.Errorf("failed to save ticket SLA: %w", err)
+	}
+
+	s.logger.Info("SLA policy applied to ticket",
+		zap.String("ticket_id", sla.TicketID),
+		zap.String("policy_id", sla.PolicyID),
+		zap.String("tenant_id", tenantID))
+	return nil
+}
+
+// planPolicy returns the ticket's current clock and the one its matching policy calls
+// for: nil when no policy matches, the current clock itself when neither the policy nor
+// its targets changed. Edits to a policy's targets reach a ticket the next time it is
+// re-planned.
+func (s *slaService) planPolicy(tenantID string, subject SLASubject) (existing, sla *tenant_models.TicketSLA, err error) {
+	policies, err := s.repo.ListPolicies(tenantID, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list SLA policies: %w", err)
+	}
+
+	existing, err = s.getTicketSLA(tenantID, subject.TicketID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policy := matchSLAPolicy(policies, subject)
+	if policy == nil {
+		return existing, nil, nil
+	}
+
+	bh, err := s.loadBusinessHours(tenantID, policy.CalendarID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if existing != nil {
+		clock := *existing
+		sla = &clock
+	} else {
+		sla = &tenant_models.TicketSLA{
+			TicketID: subject.TicketID,
+			Status:   tenant_models.SLAStatusActive,
+		}
+	}
+	sla.PolicyID = policy.ID
+	sla.CalendarID = policy.CalendarID
+
+	paused := time.Duration(sla.PausedSeconds) * time.Second
+	sla.FirstResponseTargetAt = nil
+	if policy.FirstResponseMinutes != nil {
+		target := bh.Add(subject.CreatedAt, time.Duration(*policy.FirstResponseMinutes)*time.Minute+paused)
+		sla.FirstResponseTargetAt = &target
+	}
+	sla.ResolutionTargetAt = nil
+	if policy.ResolutionMinutes != nil {
+		target := bh.Add(subject.CreatedAt, time.Duration(*policy.ResolutionMinutes)*time.Minute+paused)
+		sla.ResolutionTargetAt = &target
+	}
+	if sla.PausedAt == nil && pauseStatusesFor(policy).Contains(subject.Status) {
+		now := time.Now()
+		sla.PausedAt = &now
+	}
+	sla.Status = slaStatus(sla)
+
+	if existing != nil && sameTargets(existing, sla) {
+		return existing, existing, nil
+	}
+	return existing, sla, nil
+}
+
+// sameTargets reports whether two clocks follow the same policy to the same targets
+func sameTargets(a, b *tenant_models.TicketSLA) bool {
+	return a.PolicyID == b.PolicyID && stringValue(a.CalendarID) == stringValue(b.CalendarID) &&
+		sameTime(a.FirstResponseTargetAt, b.FirstResponseTargetAt) && sameTime(a.ResolutionTargetAt, b.ResolutionTargetAt)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// HandleStatusChange pauses, resumes or completes the SLA clock after a ticket status change
+func (s *slaService) HandleStatusChange(tenantID string, subject SLASubject, at time.Time) (*tenant_models.TicketSLA, error) {
+	sla, err := s.getTicketSLA(tenantID, subject.TicketID)
+	if err != nil || sla == nil {
+		return nil, err
+	}
+
+	pauseStatuses := models.StringArray(defaultPauseStatuses)
+	if policy, err := s.repo.GetPolicy(tenantID, sla.PolicyID); err == nil {
+		pauseStatuses = pauseStatusesFor(policy)
+	}
+
+	bh, err := s.loadBusinessHours(tenantID, sla.CalendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	shouldPause := pauseStatuses.Contains(subject.Status) && !subject.Resolved
+	switch {
+	case shouldPause && sla.PausedAt == nil:
+		sla.PausedAt = &at
+	case !shouldPause && sla.PausedAt != nil:
+		paused := bh.Between(*sla.PausedAt, at)
+		sla.PausedSeconds += int64(paused / time.Second)
+		if sla.FirstResponseTargetAt != nil && sla.FirstRespondedAt == nil && sla.FirstResponseBreachedAt == nil {
+			target := bh.Add(*sla.FirstResponseTargetAt, paused)
+			sla.FirstResponseTargetAt = &target
+		}
+		if sla.ResolutionTargetAt != nil && sla.ResolvedAt == nil && sla.ResolutionBreachedAt == nil {
+			target := bh.Add(*sla.ResolutionTargetAt, paused)
+			sla.ResolutionTargetAt = &target
+		}
+		sla.PausedAt = nil
+	}
+
+	if subject.Resolved && sla.ResolvedAt == nil {
+		// Resolving a ticket also counts as responding to it
+		if sla.FirstRespondedAt == nil {
+			markFirstResponse(sla, at)
+		}
+		sla.ResolvedAt = &at
+		if sla.ResolutionTargetAt != nil && sla.ResolutionBreachedAt == nil && at.After(*sla.ResolutionTargetAt) {
+			breachedAt := *sla.ResolutionTargetAt
+			sla.ResolutionBreachedAt = &breachedAt
+		}
+	} else if !subject.Resolved && sla.ResolvedAt != nil {
+		// Reopened: the resolution clock keeps running from its original target
+		sla.ResolvedAt = nil
+	}
+
+	sla.Status = slaStatus(sla)
+	if err := s.repo.SaveTicketSLA(tenantID, sla); err != nil {
+		return nil, fmt.Errorf("failed to save ticket SLA: %w", err)
+	}
+
+	return sla, nil
+}
+
+// RecordFirstResponse stops the first response clock
+func (s *slaService) RecordFirstResponse(tenantID, ticketID string, at time.Time) (*tenant_models.TicketSLA, error) {
+	sla, err := s.getTicketSLA(tenantID, ticketID)
+	if err != nil || sla == nil || sla.FirstRespondedAt != nil {
+		return sla, err
+	}
+
+	markFirstResponse(sla, at)
+	sla.Status = slaStatus(sla)
+	if err := s.repo.SaveTicketSLA(tenantID, sla); err != nil {
+		return nil, fmt.Errorf("failed to save ticket SLA: %w", err)
+	}
+
+	return sla, nil
+}
+
+// GetTicketSLA does not write: the scheduler stores breaches through RefreshBreaches,
+// and targets that passed since its last run are reported as breached here
+func (s *slaService) GetTicketSLA(tenantID, ticketID string) (*tenant_models.TicketSLA, error) {
+	sla, err := s.repo.GetTicketSLA(tenantID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	markOverdue(sla, time.Now())
+	return sla, nil
+}
+
+// RefreshBreaches stamps breaches on running clocks whose targets have passed
+func (s *slaService) RefreshBreaches(tenantID string) error {
+	marked, err := s.repo.MarkBreaches(tenantID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mark SLA breaches: %w", err)
+	}
+	if marked > 0 {
+		s.logger.Info("SLA breaches recorded", zap.String("tenant_id", tenantID), zap.Int64("count", marked))
+	}
+	return nil
+}
+
+// getTicketSLA returns nil without error when the ticket has no SLA
+func (s *slaService) getTicketSLA(tenantID, ticketID string) (*tenant_models.TicketSLA, error) {
+	sla, err := s.repo.GetTicketSLA(tenantID, ticketID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ticket SLA: %w", err)
+	}
+	return sla, nil
+}
+
+func (s *slaService) loadBusinessHours(tenantID string, calendarID *string) (*businessHours, error) {
+	if calendarID == nil {
+		return nil, nil
+	}
+	calendar, err := s.repo.GetCalendar(tenantID, *calendarID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business calendar: %w", err)
+	}
+	return newBusinessHours(calendar)
+}
+
+// matchSLAPolicy returns the first active policy whose conditions all hold for the subject
+func matchSLAPolicy(policies []*tenant_models.SLAPolicy, subject SLASubject) *tenant_models.SLAPolicy {
+	for _, policy := range policies {
+		if len(policy.Priorities) > 0 && !policy.Priorities.Contains(subject.Priority) {
+			continue
+		}
+		if len(policy.TicketTypes) > 0 && !policy.TicketTypes.Contains(subject.TicketType) {
+			continue
+		}
+		if len(policy.Channels) > 0 && !policy.Channels.Contains(subject.Channel) {
+			continue
+		}
+		if !customFieldsMatch(policy.CustomFields, subject.CustomFields) {
+			continue
+		}
+		return policy
+	}
+	return nil
+}
+
+// customFieldsMatch checks each expected value; a list of expected values matches any of them
+func customFieldsMatch(expected, actual map[string]interface{}) bool {
+	for field, want := range expected {
+		got, ok := actual[field]
+		if !ok {
+			return false
+		}
+		if options, isList := want.([]interface{}); isList {
+			matched := false
+			for _, option := range options {
+				if fmt.Sprint(option) == fmt.Sprint(got) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+			continue
+		}
+		if fmt.Sprint(want) != fmt.Sprint(got) {
+			return false
+		}
+	}
+	return true
+}
+
+func pauseStatusesFor(policy *tenant_models.SLAPolicy) models.StringArray {
+	if len(policy.PauseStatuses) == 0 {
+		return models.StringArray(defaultPauseStatuses)
+	}
+	return policy.PauseStatuses
+}
+
+func markFirstResponse(sla *tenant_models.TicketSLA, at time.Time) {
+	sla.FirstRespondedAt = &at
+	if sla.FirstResponseTargetAt != nil && sla.FirstResponseBreachedAt == nil && at.After(*sla.FirstResponseTargetAt) {
+		breachedAt := *sla.FirstResponseTargetAt
+		sla.FirstResponseBreachedAt = &breachedAt
+	}
+}
+
+// markOverdue stamps the breaches RefreshBreaches would store for running targets past at
+func markOverdue(sla *tenant_models.TicketSLA, at time.Time) {
+	if sla.IsPaused() {
+		return
+	}
+	if sla.FirstRespondedAt == nil && sla.FirstResponseBreachedAt == nil &&
+		sla.FirstResponseTargetAt != nil && sla.FirstResponseTargetAt.Before(at) {
+		breachedAt := *sla.FirstResponseTargetAt
+		sla.FirstResponseBreachedAt = &breachedAt
+	}
+	if sla.ResolvedAt == nil && sla.ResolutionBreachedAt == nil &&
+		sla.ResolutionTargetAt != nil && sla.ResolutionTargetAt.Before(at) {
+		breachedAt := *sla.ResolutionTargetAt
+		sla.ResolutionBreachedAt = &breachedAt
+	}
+	sla.Status = slaStatus(sla)
+}
+
+// slaStatus derives the summary status from the clock state
+func slaStatus(sla *tenant_models.TicketSLA) tenant_models.TicketSLAStatus {
+	switch {
+	case sla.IsBreached():
+		return tenant_models.SLAStatusBreached
+	case sla.IsPaused():
+		return tenant_models.SLAStatusPaused
+	case sla.ResolvedAt != nil:
+		return tenant_models.SLAStatusMet
+	default:
+		return tenant_models.SLAStatusActive
+	}
+}
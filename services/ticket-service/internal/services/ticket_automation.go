@@ -0,0 +1,614 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+const (
+	maxAutomationDepth = 3  // Cascades deeper than this are treated as a loop
+	maxHourlyFirings   = 10 // Per rule and ticket; catches loops through webhooks and outside systems
+	scheduleBatchSize  = 200
+)
+
+// automationChain follows one event through the changes automations make in response,
+// so a rule acts on a ticket at most once per chain and chains stay short
+type automationChain struct {
+	depth int
+	fired map[string]bool // rule ID + ticket ID
+}
+
+// automationEvent is what a rule's trigger and conditions are evaluated against
+type automationEvent struct {
+	trigger tenant_models.AutomationTrigger
+	ticket  *tenant_models.Ticket
+	before  *tenant_models.Ticket  // ticket_updated only
+	comment *tenant_models.Comment // comment_added only
+
+	// scheduled only
+	activity *repositories.TicketActivity
+	now      time.Time
+}
+
+// automationTicketFields are the ticket fields conditions can compare
+var automationTicketFields = map[string]func(ticket *tenant_models.Ticket) []string{
+	"title":                 func(t *tenant_models.Ticket) []string { return present(t.Title) },
+	"description":           func(t *tenant_models.Ticket) []string { return present(t.Description) },
+	"status":                func(t *tenant_models.Ticket) []string { return present(string(t.Status)) },
+	"priority":              func(t *tenant_models.Ticket) []string { return present(string(t.Priority)) },
+	"type":                  func(t *tenant_models.Ticket) []string { return present(string(t.TicketType)) },
+	"category":              func(t *tenant_models.Ticket) []string { return present(t.Category) },
+	"visibility":            func(t *tenant_models.Ticket) []string { return present(string(t.Visibility)) },
+	"channel":               func(t *tenant_models.Ticket) []string { return present(string(t.Channel)) },
+	"resolution":            func(t *tenant_models.Ticket) []string { return present(string(t.Resolution)) },
+	"project_id":            func(t *tenant_models.Ticket) []string { return present(stringValue(t.ProjectID)) },
+	"assignee_id":           func(t *tenant_models.Ticket) []string { return present(stringValue(t.AssigneeID)) },
+	"group_id":              func(t *tenant_models.Ticket) []string { return present(stringValue(t.GroupID)) },
+	"reporter_id":           func(t *tenant_models.Ticket) []string { return present(stringValue(t.ReporterID)) },
+	"customer_email":        func(t *tenant_models.Ticket) []string { return present(t.CustomerEmail) },
+	"customer_email_domain": func(t *tenant_models.Ticket) []string { return present(emailDomain(t.CustomerEmail)) },
+	"customer_name":         func(t *tenant_models.Ticket) []string { return present(t.CustomerName) },
+	"language":              func(t *tenant_models.Ticket) []string { return present(t.Language) },
+	"labels":                func(t *tenant_models.Ticket) []string { return []string(t.Labels) },
+	"estimated_hours":       func(t *tenant_models.Ticket) []string { return presentHours(t.EstimatedHours) },
+	"actual_hours":          func(t *tenant_models.Ticket) []string { return presentHours(t.ActualHours) },
+}
+
+// automationCommentFields are compared on comment_added
+var automationCommentFields = map[string]func(comment *tenant_models.Comment) []string{
+	"comment.body":         func(c *tenant_models.Comment) []string { return present(c.Body) },
+	"comment.internal":     func(c *tenant_models.Comment) []string { return []string{strconv.FormatBool(c.IsInternal)} },
+	"comment.author_id":    func(c *tenant_models.Comment) []string { return present(c.AuthorID) },
+	"comment.author_email": func(c *tenant_models.Comment) []string { return present(c.AuthorEmail) },
+}
+
+// automationTimeFields are measured in hours from the returned time to now (or, for due
+// dates, from now to it) and are only available to scheduled rules. A ticket nobody has
+// replied to counts the customer's reply from its creation.
+var automationTimeFields = map[string]func(ticket *tenant_models.Ticket, activity *repositories.TicketActivity) *time.Time{
+	"hours_since_created": func(t *tenant_models.Ticket, a *repositories.TicketActivity) *time.Time { return &t.CreatedAt },
+	"hours_since_updated": func(t *tenant_models.Ticket, a *repositories.TicketActivity) *time.Time { return &a.LastActivityAt },
+	"hours_since_status_change": func(t *tenant_models.Ticket, a *repositories.TicketActivity) *time.Time {
+		if a.StatusChangedAt != nil {
+			return a.StatusChangedAt
+		}
+		return &t.CreatedAt
+	},
+	"hours_since_resolved": func(t *tenant_models.Ticket, a *repositories.TicketActivity) *time.Time { return t.ResolvedAt },
+	"hours_since_customer_reply": func(t *tenant_models.Ticket, a *repositories.TicketActivity) *time.Time {
+		if a.CustomerRepliedAt != nil {
+			return a.CustomerRepliedAt
+		}
+		return &t.CreatedAt
+	},
+	"hours_since_agent_reply": func(t *tenant_models.Ticket, a *repositories.TicketActivity) *time.Time { return a.AgentRepliedAt },
+	"hours_until_due":         func(t *tenant_models.Ticket, a *repositories.TicketActivity) *time.Time { return t.DueDate },
+}
+
+// RunScheduledAutomations evaluates the tenant's scheduled rules against its open tickets.
+// A rule acts on a ticket once, and again only after the ticket has seen new activity.
+func (s *ticketService) RunScheduledAutomations(tenantID string, now time.Time) error {
+	rules, err := s.automations.RulesFor(tenantID, tenant_models.TriggerScheduled)
+	if err != nil {
+		return fmt.Errorf("failed to load scheduled rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	actor := Actor{UserID: tenant_models.SystemUserID, TenantID: tenantID, Role: models.MembershipRoleAdmin}
+	afterID := ""
+	for {
+		tickets, err := s.repo.ListOpenAfter(tenantID, afterID, scheduleBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list open tickets: %w", err)
+		}
+		if len(tickets) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(tickets))
+		for _, ticket := range tickets {
+			ids = append(ids, ticket.ID)
+		}
+		activity, err := s.repo.GetActivity(tenantID, ids)
+		if err != nil {
+			return fmt.Errorf("failed to load ticket activity: %w", err)
+		}
+
+		chains := make(map[string]*automationChain, len(tickets))
+		for _, rule := range rules {
+			fired, err := s.automations.LastFirings(tenantID, rule.ID, ids)
+			if err != nil {
+				return fmt.Errorf("failed to load rule firings: %w", err)
+			}
+			for _, ticket := range tickets {
+				ticketActivity := activity[ticket.ID]
+				if ticketActivity == nil {
+					continue
+				}
+				if firedAt, ok := fired[ticket.ID]; ok && !firedAt.Before(ticketActivity.LastActivityAt) {
+					continue
+				}
+
+				event := automationEvent{trigger: tenant_models.TriggerScheduled, ticket: ticket, activity: ticketActivity, now: now}
+				if !ruleMatches(rule, event) {
+					continue
+				}
+				if chains[ticket.ID] == nil {
+					chains[ticket.ID] = &automationChain{fired: map[string]bool{}}
+				}
+				actor.chain = chains[ticket.ID]
+				s.fireRule(actor, actor.chain, rule, event)
+			}
+		}
+
+		if len(tickets) < scheduleBatchSize {
+			return nil
+		}
+		afterID = tickets[len(tickets)-1].ID
+	}
+}
+
+// runAutomations fires the tenant's matching rules for the event. Failures are logged,
+// never returned: the change that caused the event has already been saved.
+func (s *ticketService) runAutomations(actor Actor, event automationEvent) {
+	rules, err := s.automations.RulesFor(actor.TenantID, event.trigger)
+	if err != nil {
+		s.logger.Warn("Failed to load automation rules", zap.Error(err), zap.String("tenant_id", actor.TenantID))
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	chain := actor.chain
+	if chain == nil {
+		chain = &automationChain{fired: map[string]bool{}}
+	}
+	for _, rule := range rules {
+		if ruleMatches(rule, event) {
+			s.fireRule(actor, chain, rule, event)
+		}
+	}
+}
+
+// fireRule runs the rule's actions as the system user, unless loop protection stops it
+func (s *ticketService) fireRule(actor Actor, chain *automationChain, rule *tenant_models.AutomationRule, event automationEvent) {
+	key := rule.ID + ":" + event.ticket.ID
+	if chain.fired[key] {
+		// The rule's own changes made it match again
+		return
+	}
+	chain.fired[key] = true
+
+	log := &tenant_models.AutomationLog{
+		RuleID:   rule.ID,
+		TicketID: event.ticket.ID,
+		Trigger:  event.trigger,
+		Depth:    chain.depth,
+	}
+	if chain.depth >= maxAutomationDepth {
+		s.skipRule(actor.TenantID, log, fmt.Sprintf("stopped after %d cascading rules", maxAutomationDepth))
+		return
+	}
+	firings, err := s.automations.RecentFirings(actor.TenantID, rule.ID, event.ticket.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		s.logger.Warn("Failed to check automation firings", zap.Error(err), zap.String("rule_id", rule.ID))
+		return
+	}
+	if firings >= maxHourlyFirings {
+		s.skipRule(actor.TenantID, log, fmt.Sprintf("already fired %d times on this ticket in the last hour", firings))
+		return
+	}
+
+	system := Actor{
+		UserID:   tenant_models.SystemUserID,
+		TenantID: actor.TenantID,
+		Role:     models.MembershipRoleAdmin,
+		chain:    &automationChain{depth: chain.depth + 1, fired: chain.fired},
+	}
+
+	var deliveries []tenant_models.AutomationAction
+	log.Results = s.applyRuleChanges(system, rule, event)
+	for _, action := range rule.Actions {
+		switch action.Type {
+		case tenant_models.ActionAddComment:
+			result := tenant_models.AutomationResult{Type: action.Type, Success: true}
+			req := &tenant_models.CommentCreateRequest{
+				Body:       renderAutomationText(action.Body, rule, event.ticket, event.comment),
+				IsInternal: action.Internal,
+			}
+			if _, err := s.CreateComment(system, event.ticket.ID, req); err != nil {
+				result.Success, result.Error = false, err.Error()
+			}
+			log.Results = append(log.Results, result)
+		case tenant_models.ActionNotify, tenant_models.ActionWebhook:
+			deliveries = append(deliveries, action)
+		}
+	}
+
+	s.logger.Info("Automation rule fired",
+		zap.String("rule_id", rule.ID),
+		zap.String("rule_name", rule.Name),
+		zap.String("ticket_id", event.ticket.ID),
+		zap.Int("depth", chain.depth))
+
+	if len(deliveries) == 0 {
+		s.recordFiring(actor.TenantID, log)
+		return
+	}
+	if event.trigger == tenant_models.TriggerScheduled {
+		// Nobody is waiting on a scheduled run, and the log must be written before the next
+		// run checks whether the rule already acted
+		s.deliverActions(actor.TenantID, rule, deliveries, event.ticket, event.comment, log)
+		return
+	}
+
+	// Notifications and webhooks leave the service, so they do not hold up the request
+	ticket := *event.ticket
+	go s.deliverActions(actor.TenantID, rule, deliveries, &ticket, event.comment, log)
+}
+
+// deliverActions sends the rule's notifications and webhooks, then records the firing
+func (s *ticketService) deliverActions(tenantID string, rule *tenant_models.AutomationRule, deliveries []tenant_models.AutomationAction, ticket *tenant_models.Ticket, comment *tenant_models.Comment, log *tenant_models.AutomationLog) {
+	for _, action := range deliveries {
+		result := tenant_models.AutomationResult{Type: action.Type, Success: true}
+		if err := s.automations.Deliver(tenantID, rule, action, ticket, comment); err != nil {
+			result.Success, result.Error = false, err.Error()
+		}
+		log.Results = append(log.Results, result)
+	}
+	s.recordFiring(tenantID, log)
+}
+
+// applyRuleChanges folds the rule's field actions into one update, so the ticket gets one
+// set of history entries and one ticket_updated event however many fields change
+func (s *ticketService) applyRuleChanges(system Actor, rule *tenant_models.AutomationRule, event automationEvent) []tenant_models.AutomationResult {
+	ticket := event.ticket
+	update := &tenant_models.TicketUpdateRequest{}
+	var results []tenant_models.AutomationResult
+	labels := append(models.StringArray{}, ticket.Labels...)
+	labelsChanged := false
+	var customFields models.JSONB
+
+	for _, action := range rule.Actions {
+		switch action.Type {
+		case tenant_models.ActionSetField:
+			setAutomationField(update, action.Field, action.Value)
+		case tenant_models.ActionAddLabels:
+			for _, label := range action.Values {
+				if !containsString(labels, label) {
+					labels = append(labels, label)
+				}
+			}
+			labelsChanged = true
+		case tenant_models.ActionRemoveLabels:
+			kept := labels[:0:0]
+			for _, label := range labels {
+				if !containsString(action.Values, label) {
+					kept = append(kept, label)
+				}
+			}
+			labels = kept
+			labelsChanged = true
+		case tenant_models.ActionSetCustomField:
+			if customFields == nil {
+				customFields = make(models.JSONB, len(ticket.CustomFields)+1)
+				for key, value := range ticket.CustomFields {
+					customFields[key] = value
+				}
+			}
+			customFields[action.Field] = action.Value
+		default:
+			continue
+		}
+		results = append(results, tenant_models.AutomationResult{Type: action.Type, Success: true})
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	if labelsChanged {
+		labelList := []string(labels)
+		update.Labels = &labelList
+	}
+	if customFields != nil {
+		update.CustomFields = &customFields
+	}
+	if _, err := s.applyUpdate(system, ticket, update); err != nil {
+		for i := range results {
+			results[i].Success, results[i].Error = false, err.Error()
+		}
+	}
+	return results
+}
+
+func (s *ticketService) skipRule(tenantID string, log *tenant_models.AutomationLog, reason string) {
+	s.logger.Warn("Automation rule skipped", zap.String("rule_id", log.RuleID), zap.String("ticket_id", log.TicketID), zap.String("reason", reason))
+	log.Status = tenant_models.AutomationSkipped
+	log.Error = reason
+	s.recordFiring(tenantID, log)
+}
+
+func (s *ticketService) recordFiring(tenantID string, log *tenant_models.AutomationLog) {
+	if log.Status == "" {
+		log.Status = automationStatus(log.Results)
+	}
+	if err := s.automations.RecordFiring(tenantID, log); err != nil {
+		s.logger.Warn("Failed to record automation firing", zap.Error(err), zap.String("rule_id", log.RuleID))
+	}
+}
+
+func automationStatus(results []tenant_models.AutomationResult) tenant_models.AutomationLogStatus {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	switch {
+	case failed == 0:
+		return tenant_models.AutomationApplied
+	case failed == len(results):
+		return tenant_models.AutomationFailed
+	default:
+		return tenant_models.AutomationPartial
+	}
+}
+
+// setAutomationField maps a set_field action onto the update; an empty value clears
+// optional fields
+func setAutomationField(update *tenant_models.TicketUpdateRequest, field, value string) {
+	switch field {
+	case "status":
+		status := tenant_models.TicketStatus(value)
+		update.Status = &status
+	case "priority":
+		priority := tenant_models.TicketPriority(value)
+		update.Priority = &priority
+	case "type":
+		ticketType := tenant_models.TicketType(value)
+		update.TicketType = &ticketType
+	case "visibility":
+		visibility := tenant_models.TicketVisibility(value)
+		update.Visibility = &visibility
+	case "category":
+		update.Category = &value
+	case "assignee_id":
+		update.AssigneeID = &value
+	case "group_id":
+		update.GroupID = &value
+	case "language":
+		update.Language = &value
+	case "project_id":
+		update.ProjectID = &value
+	}
+}
+
+// automationSettableFields are the fields set_field can change
+var automationSettableFields = map[string]bool{
+	"status": true, "priority": true, "type": true, "visibility": true,
+	"category": true, "assignee_id": true, "project_id": true,
+	"group_id": true, "language": true,
+}
+
+// automationClearableFields may be set to an empty value
+var automationClearableFields = map[string]bool{
+	"category": true, "assignee_id": true, "project_id": true, "group_id": true, "language": true,
+}
+
+func ruleMatches(rule *tenant_models.AutomationRule, event automationEvent) bool {
+	if rule.Trigger != event.trigger {
+		return false
+	}
+	for _, condition := range rule.Conditions {
+		holds := conditionHolds(condition, event)
+		if rule.Match == tenant_models.MatchAny && holds {
+			return true
+		}
+		if rule.Match != tenant_models.MatchAny && !holds {
+			return false
+		}
+	}
+	return rule.Match != tenant_models.MatchAny || len(rule.Conditions) == 0
+}
+
+func conditionHolds(condition tenant_models.AutomationCondition, event automationEvent) bool {
+	values := automationFieldValues(condition.Field, event.ticket, event.comment)
+	if measure, ok := automationTimeFields[condition.Field]; ok {
+		values = nil
+		if event.activity != nil {
+			values = hoursValues(condition.Field, measure(event.ticket, event.activity), event.now)
+		}
+	}
+
+	switch condition.Operator {
+	case tenant_models.OperatorIs:
+		return anyValue(values, condition.Values, strings.EqualFold)
+	case tenant_models.OperatorIsNot:
+		return !anyValue(values, condition.Values, strings.EqualFold)
+	case tenant_models.OperatorContains:
+		return anyValue(values, condition.Values, containsFold)
+	case tenant_models.OperatorNotContains:
+		return !anyValue(values, condition.Values, containsFold)
+	case tenant_models.OperatorStartsWith:
+		return anyValue(values, condition.Values, func(value, prefix string) bool {
+			return strings.HasPrefix(strings.ToLower(value), strings.ToLower(prefix))
+		})
+	case tenant_models.OperatorEndsWith:
+		return anyValue(values, condition.Values, func(value, suffix string) bool {
+			return strings.HasSuffix(strings.ToLower(value), strings.ToLower(suffix))
+		})
+	case tenant_models.OperatorMatches:
+		return anyValue(values, condition.Values, func(value, pattern string) bool {
+			re, err := regexp.Compile(pattern)
+			return err == nil && re.MatchString(value)
+		})
+	case tenant_models.OperatorIsEmpty:
+		return len(values) == 0
+	case tenant_models.OperatorIsNotEmpty:
+		return len(values) > 0
+	case tenant_models.OperatorGreaterThan, tenant_models.OperatorLessThan:
+		return anyValue(values, condition.Values, func(value, limit string) bool {
+			number, err := strconv.ParseFloat(value, 64)
+			bound, boundErr := strconv.ParseFloat(limit, 64)
+			if err != nil || boundErr != nil {
+				return false
+			}
+			if condition.Operator == tenant_models.OperatorGreaterThan {
+				return number > bound
+			}
+			return number < bound
+		})
+	case tenant_models.OperatorChanged, tenant_models.OperatorChangedTo, tenant_models.OperatorChangedFrom:
+		if event.before == nil {
+			return false
+		}
+		previous := automationFieldValues(condition.Field, event.before, nil)
+		if sameValues(previous, values) {
+			return false
+		}
+		switch condition.Operator {
+		case tenant_models.OperatorChangedTo:
+			return anyValue(values, condition.Values, strings.EqualFold)
+		case tenant_models.OperatorChangedFrom:
+			return anyValue(previous, condition.Values, strings.EqualFold)
+		}
+		return true
+	}
+	return false
+}
+
+// automationFieldValues returns a field's non-empty values; most fields have at most one
+func automationFieldValues(field string, ticket *tenant_models.Ticket, comment *tenant_models.Comment) []string {
+	if value, ok := automationTicketFields[field]; ok {
+		return value(ticket)
+	}
+	if value, ok := automationCommentFields[field]; ok {
+		if comment == nil {
+			return nil
+		}
+		return value(comment)
+	}
+	if key, ok := strings.CutPrefix(field, customFieldPrefix); ok {
+		return customFieldValues(ticket.CustomFields[key])
+	}
+	return nil
+}
+
+func customFieldValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, customFieldValues(item)...)
+		}
+		return values
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	default:
+		return present(fmt.Sprint(v))
+	}
+}
+
+// renderAutomationText fills in the placeholders of comment and notification text
+func renderAutomationText(text string, rule *tenant_models.AutomationRule, ticket *tenant_models.Ticket, comment *tenant_models.Comment) string {
+	commentBody := ""
+	if comment != nil {
+		commentBody = comment.Body
+	}
+	return strings.NewReplacer(
+		"{{ticket.id}}", ticket.ID,
+		"{{ticket.number}}", strconv.Itoa(ticket.TicketNumber),
+		"{{ticket.title}}", ticket.Title,
+		"{{ticket.status}}", string(ticket.Status),
+		"{{ticket.priority}}", string(ticket.Priority),
+		"{{ticket.type}}", string(ticket.TicketType),
+		"{{ticket.category}}", ticket.Category,
+		"{{ticket.customer_name}}", ticket.CustomerName,
+		"{{ticket.customer_email}}", ticket.CustomerEmail,
+		"{{comment.body}}", commentBody,
+		"{{rule.name}}", rule.Name,
+	).Replace(text)
+}
+
+// hoursValues measures a time field in hours; due dates count down, everything else up
+func hoursValues(field string, at *time.Time, now time.Time) []string {
+	if at == nil {
+		return nil
+	}
+	elapsed := now.Sub(*at)
+	if field == "hours_until_due" {
+		elapsed = -elapsed
+	}
+	return []string{strconv.FormatFloat(elapsed.Hours(), 'f', -1, 64)}
+}
+
+func anyValue(values, candidates []string, match func(value, candidate string) bool) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if match(value, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(value, substr string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(substr))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func emailDomain(address string) string {
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return strings.ToLower(address[at+1:])
+	}
+	return ""
+}
+
+func present(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+func presentHours(hours *float64) []string {
+	if hours == nil {
+		return nil
+	}
+	return []string{strconv.FormatFloat(*hours, 'f', -1, 64)}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zen/shared/pkg/rank"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// checkWIPLimit refuses to move a ticket into a board column that is already at its
+// WIP limit. Only moves into a column count: moving between two statuses of the same
+// column, or lowering a limit below a column's load, holds nothing back. Moves the
+// system makes, such as automations, are not limited.
+func (s *ticketService) checkWIPLimit(actor Actor, ticket, before *tenant_models.Ticket) error {
+	if ticket.ProjectID == nil || actor.UserID == tenant_models.SystemUserID {
+		return nil
+	}
+	projectID := *ticket.ProjectID
+
+	columns, err := s.boards.LimitedColumns(actor.TenantID, projectID, ticket.Status)
+	if err != nil {
+		return fmt.Errorf("failed to load board columns: %w", err)
+	}
+	for _, column := range columns {
+		if stringValue(before.ProjectID) == projectID && column.HasStatus(before.Status) {
+			continue
+		}
+
+		count, err := s.boards.CountInStatuses(actor.TenantID, projectID, column.Statuses, ticket.ID)
+		if err != nil {
+			return fmt.Errorf("failed to count board column: %w", err)
+		}
+		if count >= int64(column.WIPLimit) {
+			return validationError("column %q of board %q is at its WIP limit of %d", column.Name, column.BoardName, column.WIPLimit)
+		}
+	}
+	return nil
+}
+
+// rankTicket sets the ticket's board rank between the requested neighbours, or at the
+// bottom of its project's boards without any
+func (s *ticketService) rankTicket(tenantID string, ticket *tenant_models.Ticket, place *tenant_models.TicketRankRequest) error {
+	if ticket.ProjectID == nil {
+		if place != nil {
+			return validationError("only tickets in a project have a place on its boards")
+		}
+		ticket.BoardRank = ""
+		return nil
+	}
+	projectID := *ticket.ProjectID
+
+	if place == nil || (place.AfterID == "" && place.BeforeID == "") {
+		last, err := s.boards.LastRank(tenantID, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to rank ticket: %w", err)
+		}
+		if last != ticket.BoardRank || last == "" {
+			ticket.BoardRank = rank.After(last)
+		}
+		return nil
+	}
+
+	var neighbours []string
+	for _, id := range []string{place.AfterID, place.BeforeID} {
+		if id == ticket.ID {
+			return validationError("a ticket cannot be placed next to itself")
+		}
+		if id != "" {
+			neighbours = append(neighbours, id)
+		}
+	}
+	ranks, err := s.boards.GetRanks(tenantID, projectID, neighbours)
+	if err != nil {
+		return fmt.Errorf("failed to rank ticket: %w", err)
+	}
+	for _, id := range neighbours {
+		if _, ok := ranks[id]; !ok {
+			return validationError("ticket %s is not on this project's boards", id)
+		}
+	}
+
+	// Unranked neighbours, or tied ones left by concurrent moves, leave no room between
+	// them; spread the project's ranks out again first
+	if needsRebalance(place, ranks) {
+		if err := s.boards.Rebalance(tenantID, projectID); err != nil {
+			return fmt.Errorf("failed to rebalance board ranks: %w", err)
+		}
+		if ranks, err = s.boards.GetRanks(tenantID, projectID, neighbours); err != nil {
+			return fmt.Errorf("failed to rank ticket: %w", err)
+		}
+	}
+
+	boardRank, err := rank.Between(ranks[place.AfterID], ranks[place.BeforeID])
+	if errors.Is(err, rank.ErrOutOfOrder) {
+		return validationError("the cards around this one have moved; reload the board")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rank ticket: %w", err)
+	}
+	ticket.BoardRank = boardRank
+	return nil
+}
+
+// needsRebalance reports whether a requested neighbour has no rank or both share one
+func needsRebalance(place *tenant_models.TicketRankRequest, ranks map[string]string) bool {
+	after, before := ranks[place.AfterID], ranks[place.BeforeID]
+	if (place.AfterID != "" && after == "") || (place.BeforeID != "" && before == "") {
+		return true
+	}
+	return after != "" && after == before
+}
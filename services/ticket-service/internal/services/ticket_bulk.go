@@ -0,0 +1,302 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+const (
+	maxBulkTickets = 5000
+	bulkSyncLimit  = 100              // Larger batches always run in the background
+	bulkChunkSize  = 50               // Tickets processed between progress saves
+	bulkHeartbeat  = time.Minute      // How often a running job shows its worker is alive
+	bulkStallAfter = 10 * time.Minute // A job without a heartbeat for this long has lost its worker
+)
+
+// BulkUpdateTickets applies one action to every selected ticket. Each ticket goes through
+// the same checks, workflow and history as a single update and succeeds or fails on its own.
+// Small batches finish before returning; others return the queued job to poll. Background
+// jobs run in the instance that accepted them and do not survive its restart.
+func (s *ticketService) BulkUpdateTickets(actor Actor, req *tenant_models.TicketBulkRequest) (*tenant_models.BulkJobResponse, error) {
+	if !s.policy.CanViewInternal(actor) {
+		return nil, accessDenied("bulk operations require the Agent role")
+	}
+	if (len(req.TicketIDs) > 0) == (req.Filter != nil) {
+		return nil, validationError("select tickets with either ticket_ids or filter")
+	}
+	switch req.Action {
+	case tenant_models.BulkActionUpdate:
+		if req.Changes.IsEmpty() {
+			return nil, validationError("changes are required for an update")
+		}
+	case tenant_models.BulkActionDelete:
+		if !req.Changes.IsEmpty() {
+			return nil, validationError("delete does not take changes")
+		}
+	default:
+		return nil, validationError("unknown action %q", req.Action)
+	}
+
+	ids, err := s.bulkTicketIDs(actor, req)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &tenant_models.BulkJob{
+		Action:    req.Action,
+		Status:    tenant_models.BulkJobQueued,
+		Total:     len(ids),
+		Changes:   req.Changes,
+		CreatedBy: actor.UserID,
+	}
+	if err := s.bulkJobs.Create(actor.TenantID, job); err != nil {
+		return nil, fmt.Errorf("failed to create bulk job: %w", err)
+	}
+
+	if req.Async || len(ids) > bulkSyncLimit {
+		// Snapshot before the worker starts changing the job
+		response := job.ToResponse()
+		go s.runBulkJob(actor, job, ids)
+		return &response, nil
+	}
+
+	s.runBulkJob(actor, job, ids)
+	response := job.ToResponse()
+	return &response, nil
+}
+
+// GetBulkJob reports a bulk job's progress to its creator or an admin
+func (s *ticketService) GetBulkJob(actor Actor, jobID string) (*tenant_models.BulkJobResponse, error) {
+	job, err := s.bulkJobs.Get(actor.TenantID, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk job: %w", err)
+	}
+	if job.CreatedBy != actor.UserID && !actor.isAdmin() {
+		return nil, accessDenied("bulk jobs are visible to their creator and admins")
+	}
+
+	// A job whose worker stopped its heartbeat, e.g. in a restart, is failed rather than
+	// left running. The write is skipped if the worker saved the job in the meantime.
+	if (job.Status == tenant_models.BulkJobQueued || job.Status == tenant_models.BulkJobRunning) &&
+		time.Since(job.UpdatedAt) >= bulkStallAfter {
+		message := "the job stopped before finishing; tickets missing from its results were not changed"
+		failed, err := s.bulkJobs.FailStalled(actor.TenantID, job.ID, time.Now().Add(-bulkStallAfter), message)
+		if err != nil {
+			s.logger.Warn("Failed to fail stalled bulk job", zap.Error(err), zap.String("job_id", job.ID))
+		} else if failed {
+			now := time.Now()
+			job.Status = tenant_models.BulkJobFailed
+			job.Error = message
+			job.CompletedAt = &now
+		}
+	}
+
+	response := job.ToResponse()
+	return &response, nil
+}
+
+// bulkTicketIDs resolves the selection; filters only match tickets the actor can see
+func (s *ticketService) bulkTicketIDs(actor Actor, req *tenant_models.TicketBulkRequest) ([]string, error) {
+	if req.Filter == nil {
+		seen := make(map[string]bool, len(req.TicketIDs))
+		ids := make([]string, 0, len(req.TicketIDs))
+		for _, id := range req.TicketIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > maxBulkTickets {
+			return nil, validationError("at most %d tickets can be changed at once", maxBulkTickets)
+		}
+		return ids, nil
+	}
+
+	filters := repositories.TicketFilters{
+		Status:      req.Filter.Status,
+		Priority:    req.Filter.Priority,
+		Type:        req.Filter.Type,
+		AssigneeID:  req.Filter.AssigneeID,
+		ReporterID:  req.Filter.ReporterID,
+		ProjectID:   req.Filter.ProjectID,
+		Category:    req.Filter.Category,
+		DateFrom:    req.Filter.DateFrom,
+		DateTo:      req.Filter.DateTo,
+		SLABreached: req.Filter.SLABreached,
+	}
+	if req.Filter.BreachingWithin != nil {
+		window := time.Duration(*req.Filter.BreachingWithin) * time.Minute
+		filters.BreachingWithin = &window
+	}
+	if filters.BreachingWithin != nil || filters.SLABreached {
+		if err := s.sla.RefreshBreaches(actor.TenantID); err != nil {
+			s.logger.Warn("Failed to refresh SLA breaches", zap.Error(err), zap.String("tenant_id", actor.TenantID))
+		}
+	}
+
+	scope, err := s.policy.ListScope(actor)
+	if err != nil {
+		return nil, err
+	}
+	filters.Scope = scope
+
+	ids, err := s.repo.ListIDs(actor.TenantID, filters, maxBulkTickets+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select tickets: %w", err)
+	}
+	if len(ids) > maxBulkTickets {
+		return nil, validationError("the filter matches more than %d tickets; narrow it down", maxBulkTickets)
+	}
+	return ids, nil
+}
+
+// runBulkJob processes the tickets in chunks, saving progress after each
+func (s *ticketService) runBulkJob(actor Actor, job *tenant_models.BulkJob, ids []string) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.logger.Error("Bulk job panicked", zap.Any("panic", recovered), zap.String("job_id", job.ID))
+			s.finishBulkJob(actor, job, tenant_models.BulkJobFailed, fmt.Sprint("unexpected error: ", recovered))
+		}
+	}()
+
+	stopHeartbeat := s.beatBulkJob(actor.TenantID, job.ID)
+	defer stopHeartbeat()
+
+	job.Status = tenant_models.BulkJobRunning
+	if !s.saveBulkJob(actor, job) {
+		return
+	}
+
+	for start := 0; start < len(ids); start += bulkChunkSize {
+		end := min(start+bulkChunkSize, len(ids))
+		for _, ticketID := range ids[start:end] {
+			result := tenant_models.TicketBulkResult{TicketID: ticketID, Success: true}
+			if err := s.applyBulkAction(actor, job, ticketID); err != nil {
+				result.Success = false
+				result.Error = s.bulkErrorMessage(err, job.ID, ticketID)
+				job.Failed++
+			} else {
+				job.Succeeded++
+			}
+			job.Results = append(job.Results, result)
+			job.Processed++
+		}
+		if end < len(ids) && !s.saveBulkJob(actor, job) {
+			return
+		}
+	}
+
+	if !s.finishBulkJob(actor, job, tenant_models.BulkJobCompleted, "") {
+		return
+	}
+	s.logger.Info("Bulk job finished",
+		zap.String("job_id", job.ID),
+		zap.String("action", string(job.Action)),
+		zap.Int("succeeded", job.Succeeded),
+		zap.Int("failed", job.Failed))
+}
+
+func (s *ticketService) applyBulkAction(actor Actor, job *tenant_models.BulkJob, ticketID string) error {
+	if job.Action == tenant_models.BulkActionDelete {
+		return s.DeleteTicket(actor, ticketID)
+	}
+
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket: %w", err)
+	}
+	_, err = s.applyUpdate(actor, ticket, bulkUpdateRequest(ticket, job.Changes))
+	return err
+}
+
+// bulkUpdateRequest turns the bulk changes into an update of one ticket, merging its labels
+func bulkUpdateRequest(ticket *tenant_models.Ticket, changes *tenant_models.TicketBulkChanges) *tenant_models.TicketUpdateRequest {
+	update := &tenant_models.TicketUpdateRequest{
+		Status:     changes.Status,
+		Priority:   changes.Priority,
+		AssigneeID: changes.AssigneeID,
+		ProjectID:  changes.ProjectID,
+	}
+	if len(changes.AddLabels) == 0 && len(changes.RemoveLabels) == 0 {
+		return update
+	}
+
+	remove := make(map[string]bool, len(changes.RemoveLabels))
+	for _, label := range changes.RemoveLabels {
+		remove[label] = true
+	}
+	labels := make([]string, 0, len(ticket.Labels)+len(changes.AddLabels))
+	present := make(map[string]bool, len(ticket.Labels))
+	for _, label := range append(append([]string{}, ticket.Labels...), changes.AddLabels...) {
+		if remove[label] || present[label] {
+			continue
+		}
+		present[label] = true
+		labels = append(labels, label)
+	}
+	update.Labels = &labels
+	return update
+}
+
+// bulkErrorMessage explains a per-ticket failure without leaking internal errors
+func (s *ticketService) bulkErrorMessage(err error, jobID, ticketID string) string {
+	switch {
+	case errors.Is(err, ErrValidation), errors.Is(err, ErrAccessDenied):
+		return err.Error()
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "ticket not found"
+	default:
+		s.logger.Error("Bulk action failed", zap.Error(err), zap.String("job_id", jobID), zap.String("ticket_id", ticketID))
+		return "internal error"
+	}
+}
+
+func (s *ticketService) finishBulkJob(actor Actor, job *tenant_models.BulkJob, status tenant_models.BulkJobStatus, message string) bool {
+	now := time.Now()
+	job.Status = status
+	job.Error = message
+	job.CompletedAt = &now
+	return s.saveBulkJob(actor, job)
+}
+
+// saveBulkJob records progress; a failed save only delays what pollers see. It returns
+// false when the job was already failed as stalled, which its worker must not overwrite.
+func (s *ticketService) saveBulkJob(actor Actor, job *tenant_models.BulkJob) bool {
+	saved, err := s.bulkJobs.Update(actor.TenantID, job)
+	if err != nil {
+		s.logger.Warn("Failed to save bulk job progress", zap.Error(err), zap.String("job_id", job.ID))
+		return true
+	}
+	if !saved {
+		s.logger.Warn("Bulk job was failed as stalled; stopping its worker", zap.String("job_id", job.ID))
+	}
+	return saved
+}
+
+// beatBulkJob keeps the job's heartbeat fresh while its worker runs, so slow tickets are
+// not mistaken for a lost worker; the returned func stops it
+func (s *ticketService) beatBulkJob(tenantID, jobID string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(bulkHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.bulkJobs.Heartbeat(tenantID, jobID); err != nil {
+					s.logger.Warn("Failed to send bulk job heartbeat", zap.Error(err), zap.String("job_id", jobID))
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// surveyIfResolved sends a satisfaction survey when the update resolved the ticket. Moving
+// on from resolved to closed sends none, as the ticket was resolved already.
+func (s *ticketService) surveyIfResolved(tenantID string, before, ticket *tenant_models.Ticket) {
+	if before.ResolvedAt != nil || ticket.ResolvedAt == nil || ticket.CustomerEmail == "" {
+		return
+	}
+
+	resolved := *ticket
+	go func() {
+		if err := s.csat.SendSurvey(tenantID, &resolved); err != nil {
+			s.logger.Warn("Failed to send CSAT survey", zap.Error(err), zap.String("ticket_id", resolved.ID))
+		}
+	}()
+}
+
+// GetSurvey returns the survey a customer's link points to
+func (s *ticketService) GetSurvey(token string) (*tenant_models.CSATSurveyForm, error) {
+	tenantID, survey, err := s.csat.OpenSurvey(token)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.repo.GetByID(tenantID, survey.TicketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	return surveyForm(survey, ticket), nil
+}
+
+// AnswerSurvey records the customer's rating, notes it on the ticket for agents and, if
+// the tenant chose to, reopens the ticket when the rating is bad
+func (s *ticketService) AnswerSurvey(token string, req *tenant_models.CSATAnswerRequest) (*tenant_models.CSATSurveyForm, error) {
+	tenantID, survey, err := s.csat.OpenSurvey(token)
+	if err != nil {
+		return nil, err
+	}
+	survey, settings, err := s.csat.RecordAnswer(tenantID, survey, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.repo.GetByID(tenantID, survey.TicketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	system := Actor{UserID: tenant_models.SystemUserID, TenantID: tenantID, Role: models.MembershipRoleAdmin}
+	note := fmt.Sprintf("Customer rated this ticket %d/%d.", req.Rating, tenant_models.CSATMaxRating)
+	if survey.Comment != "" {
+		note += "\n\n" + survey.Comment
+	}
+	if _, err := s.CreateComment(system, ticket.ID, &tenant_models.CommentCreateRequest{Body: note, IsInternal: true}); err != nil {
+		s.logger.Warn("Failed to note CSAT answer on ticket", zap.Error(err), zap.String("ticket_id", ticket.ID))
+	}
+
+	if settings.ReopenAtOrBelow > 0 && req.Rating <= settings.ReopenAtOrBelow && isResolved(ticket) {
+		status := tenant_models.TicketStatus(settings.ReopenStatus)
+		if _, err := s.applyUpdate(system, ticket, &tenant_models.TicketUpdateRequest{Status: &status}); err != nil {
+			s.logger.Warn("Failed to reopen ticket after bad CSAT rating", zap.Error(err), zap.String("ticket_id", ticket.ID))
+		} else if err := s.csat.MarkReopened(tenantID, survey); err != nil {
+			s.logger.Warn("Failed to mark CSAT survey reopened", zap.Error(err), zap.String("survey_id", survey.ID))
+		}
+	}
+
+	return surveyForm(survey, ticket), nil
+}
+
+func surveyForm(survey *tenant_models.CSATSurvey, ticket *tenant_models.Ticket) *tenant_models.CSATSurveyForm {
+	return &tenant_models.CSATSurveyForm{
+		TicketNumber: ticket.TicketNumber,
+		TicketTitle:  ticket.Title,
+		Rating:       survey.Rating,
+		Comment:      survey.Comment,
+		RespondedAt:  survey.RespondedAt,
+		ExpiresAt:    survey.ExpiresAt,
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// auditedField reads one ticket field as the string stored in ticket_history
+type auditedField struct {
+	name  string
+	value func(t *tenant_models.Ticket) string
+}
+
+var auditedTicketFields = []auditedField{
+	{"title", func(t *tenant_models.Ticket) string { return t.Title }},
+	{"description", func(t *tenant_models.Ticket) string { return t.Description }},
+	{"status", func(t *tenant_models.Ticket) string { return string(t.Status) }},
+	{"priority", func(t *tenant_models.Ticket) string { return string(t.Priority) }},
+	{"ticket_type", func(t *tenant_models.Ticket) string { return string(t.TicketType) }},
+	{"resolution", func(t *tenant_models.Ticket) string { return string(t.Resolution) }},
+	{"category", func(t *tenant_models.Ticket) string { return t.Category }},
+	{"visibility", func(t *tenant_models.Ticket) string { return string(t.Visibility) }},
+	{"channel", func(t *tenant_models.Ticket) string { return string(t.Channel) }},
+	{"assignee_id", func(t *tenant_models.Ticket) string { return stringValue(t.AssigneeID) }},
+	{"group_id", func(t *tenant_models.Ticket) string { return stringValue(t.GroupID) }},
+	{"project_id", func(t *tenant_models.Ticket) string { return stringValue(t.ProjectID) }},
+	{"parent_ticket_id", func(t *tenant_models.Ticket) string { return stringValue(t.ParentTicketID) }},
+	{"customer_email", func(t *tenant_models.Ticket) string { return t.CustomerEmail }},
+	{"customer_name", func(t *tenant_models.Ticket) string { return t.CustomerName }},
+	{"language", func(t *tenant_models.Ticket) string { return t.Language }},
+	{"start_date", func(t *tenant_models.Ticket) string { return formatTime(t.StartDate) }},
+	{"due_date", func(t *tenant_models.Ticket) string { return formatTime(t.DueDate) }},
+	{"estimated_hours", func(t *tenant_models.Ticket) string { return formatHours(t.EstimatedHours) }},
+	{"actual_hours", func(t *tenant_models.Ticket) string { return formatHours(t.ActualHours) }},
+	{"story_points", func(t *tenant_models.Ticket) string { return formatHours(t.StoryPoints) }},
+	{"sprint_id", func(t *tenant_models.Ticket) string { return stringValue(t.SprintID) }},
+	{"milestone_id", func(t *tenant_models.Ticket) string { return stringValue(t.MilestoneID) }},
+	{"labels", func(t *tenant_models.Ticket) string { return formatJSON(t.Labels) }},
+	{"custom_fields", func(t *tenant_models.Ticket) string { return formatJSON(t.CustomFields) }},
+}
+
+// diffTicket returns one history entry per audited field that differs between before and after
+func diffTicket(actorID string, before, after *tenant_models.Ticket) []*tenant_models.TicketHistory {
+	var entries []*tenant_models.TicketHistory
+	for _, field := range auditedTicketFields {
+		oldValue, newValue := field.value(before), field.value(after)
+		if oldValue == newValue {
+			continue
+		}
+
+		changeType := tenant_models.ChangeTypeUpdate
+		switch field.name {
+		case "assignee_id":
+			changeType = tenant_models.ChangeTypeAssign
+		case "status":
+			changeType = statusChangeType(before, after)
+		}
+		entries = append(entries, historyEntry(after.ID, actorID, field.name, changeType, oldValue, newValue))
+	}
+	return entries
+}
+
+// statusChangeType distinguishes resolving and reopening from other status moves
+func statusChangeType(before, after *tenant_models.Ticket) tenant_models.ChangeType {
+	wasResolved, nowResolved := isResolved(before), isResolved(after)
+	switch {
+	case nowResolved && !wasResolved:
+		return tenant_models.ChangeTypeResolve
+	case wasResolved && !nowResolved:
+		return tenant_models.ChangeTypeReopen
+	default:
+		return tenant_models.ChangeTypeUpdate
+	}
+}
+
+func historyEntry(ticketID, actorID, field string, changeType tenant_models.ChangeType, oldValue, newValue string) *tenant_models.TicketHistory {
+	return &tenant_models.TicketHistory{
+		TicketID:   ticketID,
+		FieldName:  field,
+		OldValue:   optionalString(oldValue),
+		NewValue:   optionalString(newValue),
+		ChangeType: changeType,
+		ChangedBy:  actorID,
+		ChangedAt:  time.Now(),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatHours(hours *float64) string {
+	if hours == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*hours, 'f', -1, 64)
+}
+
+// formatJSON renders labels and custom fields; empty collections record as no value
+func formatJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	switch string(data) {
+	case "null", "[]", "{}":
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// CreateTicketLink links the ticket to another. Subtask links may not form loops and a
+// ticket has one parent; blocking chains may not form cycles; a duplicate points at a
+// canonical ticket that is not itself a duplicate, and is closed as soon as it is linked.
+func (s *ticketService) CreateTicketLink(actor Actor, ticketID string, req *tenant_models.TicketLinkCreateRequest) (*tenant_models.TicketLinkResponse, error) {
+	linkType, fromSource, ok := req.Relation.Stored()
+	if !ok {
+		return nil, validationError("unknown relation %q", req.Relation)
+	}
+	if req.TargetTicketID == ticketID {
+		return nil, validationError("a ticket cannot be linked to itself")
+	}
+
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	if err := s.policy.CanUpdate(actor, ticket); err != nil {
+		return nil, err
+	}
+	other, err := s.repo.GetByID(actor.TenantID, req.TargetTicketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked ticket: %w", err)
+	}
+	if err := s.policy.CanView(actor, other); err != nil {
+		return nil, err
+	}
+
+	source, target := ticket, other
+	if !fromSource {
+		source, target = other, ticket
+	}
+
+	existing, err := s.links.FindLink(actor.TenantID, ticket.ID, other.ID)
+	if err == nil {
+		return nil, validationError("ticket #%d is already linked to #%d as %s", ticket.TicketNumber, other.TicketNumber, existing.RelationFor(ticket.ID))
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check existing links: %w", err)
+	}
+
+	if err := s.checkLink(actor, linkType, source, target, other); err != nil {
+		return nil, err
+	}
+
+	link := &tenant_models.TicketLink{
+		SourceTicketID: source.ID,
+		TargetTicketID: target.ID,
+		LinkType:       linkType,
+		CreatedBy:      actor.UserID,
+	}
+	history := linkHistory(actor.UserID, tenant_models.ChangeTypeLink, link, source, target)
+	if err := s.links.CreateLink(actor.TenantID, link, history...); err != nil {
+		return nil, fmt.Errorf("failed to create ticket link: %w", err)
+	}
+
+	s.logger.Info("Tickets linked",
+		zap.String("link_id", link.ID),
+		zap.String("source_ticket_id", source.ID),
+		zap.String("target_ticket_id", target.ID),
+		zap.String("link_type", string(linkType)))
+
+	if linkType == tenant_models.LinkTypeDuplicates {
+		if err := s.closeDuplicate(actor, source); err != nil {
+			s.logger.Warn("Failed to close duplicate ticket", zap.Error(err), zap.String("ticket_id", source.ID))
+		}
+	}
+
+	response := link.ToResponse(ticket.ID, other)
+	return &response, nil
+}
+
+// checkLink applies the rules of each link type. Links that change the other ticket
+// (its parent, or closing it as a duplicate) need update rights on it as well.
+func (s *ticketService) checkLink(actor Actor, linkType tenant_models.TicketLinkType, source, target, other *tenant_models.Ticket) error {
+	switch linkType {
+	case tenant_models.LinkTypeSubtask:
+		if target.ParentTicketID != nil {
+			return validationError("ticket #%d already has a parent", target.TicketNumber)
+		}
+		if target == other {
+			if err := s.policy.CanUpdate(actor, other); err != nil {
+				return err
+			}
+		}
+		// The new parent must not already sit below the child
+		loops, err := s.links.Reaches(actor.TenantID, target.ID, source.ID, linkType)
+		if err != nil {
+			return fmt.Errorf("failed to check subtask hierarchy: %w", err)
+		}
+		if loops {
+			return validationError("ticket #%d is a subtask of #%d, so it cannot be its parent", source.TicketNumber, target.TicketNumber)
+		}
+
+	case tenant_models.LinkTypeBlocks:
+		cycle, err := s.links.Reaches(actor.TenantID, target.ID, source.ID, linkType)
+		if err != nil {
+			return fmt.Errorf("failed to check blocking chain: %w", err)
+		}
+		if cycle {
+			return validationError("ticket #%d already blocks #%d, directly or through other tickets", target.TicketNumber, source.TicketNumber)
+		}
+
+	case tenant_models.LinkTypeDuplicates:
+		if source == other {
+			if err := s.policy.CanUpdate(actor, other); err != nil {
+				return err
+			}
+		}
+		duplicates, err := s.links.CountLinks(actor.TenantID, source.ID, linkType)
+		if err != nil {
+			return fmt.Errorf("failed to check duplicates: %w", err)
+		}
+		if duplicates > 0 {
+			return validationError("ticket #%d is already marked as a duplicate", source.TicketNumber)
+		}
+		duplicates, err = s.links.CountLinks(actor.TenantID, target.ID, linkType)
+		if err != nil {
+			return fmt.Errorf("failed to check duplicates: %w", err)
+		}
+		if duplicates > 0 {
+			return validationError("ticket #%d is itself a duplicate; link to its canonical ticket instead", target.TicketNumber)
+		}
+	}
+	return nil
+}
+
+// DeleteTicketLink removes a link from either of its tickets; a duplicate stays closed
+func (s *ticketService) DeleteTicketLink(actor Actor, ticketID, linkID string) error {
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket: %w", err)
+	}
+	if err := s.policy.CanUpdate(actor, ticket); err != nil {
+		return err
+	}
+
+	link, err := s.links.GetLink(actor.TenantID, linkID)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket link: %w", err)
+	}
+	if link.SourceTicketID != ticketID && link.TargetTicketID != ticketID {
+		return fmt.Errorf("failed to get ticket link: %w", gorm.ErrRecordNotFound)
+	}
+
+	var history []*tenant_models.TicketHistory
+	if link.SourceTicket != nil && link.TargetTicket != nil {
+		history = linkHistory(actor.UserID, tenant_models.ChangeTypeUnlink, link, link.SourceTicket, link.TargetTicket)
+	}
+	if err := s.links.DeleteLink(actor.TenantID, link, history...); err != nil {
+		return fmt.Errorf("failed to delete ticket link: %w", err)
+	}
+
+	s.logger.Info("Ticket link removed",
+		zap.String("link_id", linkID),
+		zap.String("ticket_id", ticketID),
+		zap.String("removed_by", actor.UserID))
+	return nil
+}
+
+// GetTicketLinks lists the ticket's links the actor can see and, for parents, the roll-up
+// of all their subtasks
+func (s *ticketService) GetTicketLinks(actor Actor, ticketID string) (*tenant_models.TicketLinksResponse, error) {
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	if err := s.policy.CanView(actor, ticket); err != nil {
+		return nil, err
+	}
+
+	links, err := s.links.ListLinks(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ticket links: %w", err)
+	}
+
+	response := &tenant_models.TicketLinksResponse{Links: []tenant_models.TicketLinkResponse{}}
+	for _, link := range links {
+		other := link.OtherTicket(ticketID)
+		if other == nil {
+			continue
+		}
+		if err := s.policy.CanView(actor, other); err != nil {
+			if errors.Is(err, ErrAccessDenied) {
+				continue
+			}
+			return nil, err
+		}
+		response.Links = append(response.Links, link.ToResponse(ticketID, other))
+	}
+
+	children, err := s.links.ListChildren(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subtasks: %w", err)
+	}
+	if len(children) > 0 {
+		response.Subtasks = subtaskProgress(children)
+	}
+
+	return response, nil
+}
+
+// closeDuplicate closes a ticket marked as a duplicate without consulting its workflow:
+// the canonical ticket carries the work from here on
+func (s *ticketService) closeDuplicate(actor Actor, ticket *tenant_models.Ticket) error {
+	before := *ticket
+	ticket.Status = tenant_models.StatusClosed
+	ticket.Resolution = tenant_models.ResolutionDuplicate
+	if err := s.stampStatusTimes(actor, ticket); err != nil {
+		return err
+	}
+
+	if ticket.Status != before.Status {
+		if _, err := s.sla.HandleStatusChange(actor.TenantID, slaSubjectFor(ticket), time.Now()); err != nil {
+			s.logger.Warn("Failed to update SLA clock", zap.Error(err), zap.String("ticket_id", ticket.ID))
+		}
+	}
+
+	return s.repo.Update(actor.TenantID, ticket, diffTicket(actor.UserID, &before, ticket)...)
+}
+
+// linkHistory records the link on both tickets, each from its own point of view
+func linkHistory(actorID string, changeType tenant_models.ChangeType, link *tenant_models.TicketLink, source, target *tenant_models.Ticket) []*tenant_models.TicketHistory {
+	describe := func(from, to *tenant_models.Ticket) string {
+		return fmt.Sprintf("%s #%d", link.RelationFor(from.ID), to.TicketNumber)
+	}
+
+	oldSource, newSource := "", describe(source, target)
+	oldTarget, newTarget := "", describe(target, source)
+	if changeType == tenant_models.ChangeTypeUnlink {
+		oldSource, newSource = newSource, ""
+		oldTarget, newTarget = newTarget, ""
+	}
+	return []*tenant_models.TicketHistory{
+		historyEntry(source.ID, actorID, "link", changeType, oldSource, newSource),
+		historyEntry(target.ID, actorID, "link", changeType, oldTarget, newTarget),
+	}
+}
+
+// subtaskProgress counts resolved children and sums their estimates and logged hours
+func subtaskProgress(children []*tenant_models.Ticket) *tenant_models.SubtaskProgress {
+	progress := &tenant_models.SubtaskProgress{Total: len(children)}
+	for _, child := range children {
+		if isResolved(child) {
+			progress.Resolved++
+		}
+		progress.EstimatedHours = addHours(progress.EstimatedHours, child.EstimatedHours)
+		progress.ActualHours = addHours(progress.ActualHours, child.ActualHours)
+	}
+	progress.Percentage = math.Round(float64(progress.Resolved)/float64(progress.Total)*1000) / 10
+	return progress
+}
+
+func addHours(total, hours *float64) *float64 {
+	if hours == nil {
+		return total
+	}
+	sum := *hours
+	if total != nil {
+		sum += *total
+	}
+	return &sum
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// Actor is the authenticated user a ticket operation is performed for
+type Actor struct {
+	UserID   string
+	TenantID string
+	Role     models.MembershipRole
+
+	chain *automationChain // Set when an automation rule is acting
+}
+
+// HasRole reports whether the actor's tenant role is at least role
+func (a Actor) HasRole(role models.MembershipRole) bool {
+	membership := models.UserTenantMembership{Role: a.Role}
+	return membership.HasRole(role)
+}
+
+func (a Actor) isAdmin() bool {
+	return a.HasRole(models.MembershipRoleAdmin)
+}
+
+// ticketPolicy decides what an actor may do with tickets.
+//
+// Owners and Admins can do everything. Everyone sees tickets they reported or
+// are assigned to. Beyond that, project members see their projects' tickets and
+// Agents and Managers also see tickets outside any project (the shared queue).
+// Restricted tickets are limited to reporter, assignee, project leads and admins.
+// Viewers are read-only except for the title, description and custom fields of tickets
+// they reported, and never see internal notes.
+type ticketPolicy struct {
+	members repositories.ProjectMemberRepository
+}
+
+func newTicketPolicy(members repositories.ProjectMemberRepository) *ticketPolicy {
+	return &ticketPolicy{members: members}
+}
+
+// projectRole returns the actor's role in the project, or "" if not a member
+func (p *ticketPolicy) projectRole(actor Actor, projectID string) (tenant_models.ProjectMemberRole, error) {
+	if projectID == "" {
+		return "", nil
+	}
+
+	member, err := p.members.GetMembership(actor.TenantID, projectID, actor.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check project membership: %w", err)
+	}
+	return member.Role, nil
+}
+
+func isParticipant(actor Actor, ticket *tenant_models.Ticket) bool {
+	return stringValue(ticket.ReporterID) == actor.UserID || stringValue(ticket.AssigneeID) == actor.UserID
+}
+
+// CanView returns an ErrAccessDenied error explaining why the actor cannot see the ticket
+func (p *ticketPolicy) CanView(actor Actor, ticket *tenant_models.Ticket) error {
+	if actor.isAdmin() || isParticipant(actor, ticket) {
+		return nil
+	}
+
+	role, err := p.projectRole(actor, stringValue(ticket.ProjectID))
+	if err != nil {
+		return err
+	}
+
+	if ticket.Visibility == tenant_models.VisibilityRestricted && role != tenant_models.ProjectRoleLead {
+		return accessDenied("ticket is restricted to its reporter, assignee and project leads")
+	}
+
+	if ticket.ProjectID == nil {
+		if !actor.HasRole(models.MembershipRoleAgent) {
+			return accessDenied("viewers can only see their own tickets and tickets in their projects")
+		}
+		return nil
+	}
+
+	if role == "" {
+		return accessDenied("you are not a member of this ticket's project")
+	}
+	return nil
+}
+
+// CanUpdate checks whether the actor may work the ticket: change its status, visibility,
+// project, assignment, planning or links
+func (p *ticketPolicy) CanUpdate(actor Actor, ticket *tenant_models.Ticket) error {
+	if err := p.CanView(actor, ticket); err != nil {
+		return err
+	}
+	if actor.isAdmin() {
+		return nil
+	}
+	if !actor.HasRole(models.MembershipRoleAgent) {
+		return accessDenied("viewers can only edit the title, description and custom fields of tickets they reported")
+	}
+
+	role, err := p.projectRole(actor, stringValue(ticket.ProjectID))
+	if err != nil {
+		return err
+	}
+	if role == tenant_models.ProjectRoleViewer {
+		return accessDenied("project viewers cannot modify tickets")
+	}
+	return nil
+}
+
+// CanEditContent checks whether the actor may edit the ticket's title, description and
+// custom fields, which its reporter always may
+func (p *ticketPolicy) CanEditContent(actor Actor, ticket *tenant_models.Ticket) error {
+	if err := p.CanView(actor, ticket); err != nil {
+		return err
+	}
+	if stringValue(ticket.ReporterID) == actor.UserID {
+		return nil
+	}
+	return p.CanUpdate(actor, ticket)
+}
+
+// CanDelete allows admins, and managers or project leads who can see the ticket
+func (p *ticketPolicy) CanDelete(actor Actor, ticket *tenant_models.Ticket) error {
+	if actor.isAdmin() {
+		return nil
+	}
+	if err := p.CanView(actor, ticket); err != nil {
+		return err
+	}
+
+	role, err := p.projectRole(actor, stringValue(ticket.ProjectID))
+	if err != nil {
+		return err
+	}
+	if role == tenant_models.ProjectRoleLead {
+		return nil
+	}
+	if actor.HasRole(models.MembershipRoleManager) && role != tenant_models.ProjectRoleViewer {
+		return nil
+	}
+	return accessDenied("only managers, project leads and admins can delete tickets")
+}
+
+// CanAssign checks the actor may assign the ticket and that the assignee can work on it.
+// Agents may only take tickets themselves; managers and project leads assign anyone.
+func (p *ticketPolicy) CanAssign(actor Actor, ticket *tenant_models.Ticket, assigneeID string) error {
+	if !actor.HasRole(models.MembershipRoleAgent) {
+		return accessDenied("viewers cannot assign tickets")
+	}
+	if err := p.CanUpdate(actor, ticket); err != nil {
+		return err
+	}
+
+	if !actor.isAdmin() && !actor.HasRole(models.MembershipRoleManager) && assigneeID != actor.UserID {
+		role, err := p.projectRole(actor, stringValue(ticket.ProjectID))
+		if err != nil {
+			return err
+		}
+		if role != tenant_models.ProjectRoleLead {
+			return accessDenied("agents can only assign tickets to themselves")
+		}
+	}
+
+	if assigneeID != "" && ticket.ProjectID != nil {
+		member, err := p.members.GetMembership(actor.TenantID, *ticket.ProjectID, assigneeID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return validationError("assignee is not a member of the ticket's project")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check project membership: %w", err)
+		}
+		if member.Role == tenant_models.ProjectRoleViewer {
+			return validationError("project viewers cannot be assigned tickets")
+		}
+	}
+	return nil
+}
+
+// CanCreateIn checks the actor may file a ticket in the given project
+func (p *ticketPolicy) CanCreateIn(actor Actor, projectID string) error {
+	if projectID == "" || actor.isAdmin() {
+		return nil
+	}
+
+	role, err := p.projectRole(actor, projectID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return accessDenied("you are not a member of this project")
+	}
+	return nil
+}
+
+// CanViewInternal reports whether the actor may read and write internal notes
+func (p *ticketPolicy) CanViewInternal(actor Actor) bool {
+	return actor.HasRole(models.MembershipRoleAgent)
+}
+
+// CanViewStats limits tenant-wide statistics to managers and above
+func (p *ticketPolicy) CanViewStats(actor Actor) error {
+	if !actor.HasRole(models.MembershipRoleManager) {
+		return accessDenied("ticket statistics require the Manager role")
+	}
+	return nil
+}
+
+// ListScope returns the query scope for listing tickets; nil means unrestricted
+func (p *ticketPolicy) ListScope(actor Actor) (*repositories.TicketAccessScope, error) {
+	if actor.isAdmin() {
+		return nil, nil
+	}
+
+	memberships, err := p.members.ListMemberships(actor.TenantID, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project memberships: %w", err)
+	}
+
+	scope := &repositories.TicketAccessScope{
+		UserID:             actor.UserID,
+		ProjectIDs:         []string{},
+		LeadProjectIDs:     []string{},
+		IncludeUnprojected: actor.HasRole(models.MembershipRoleAgent),
+	}
+	for _, membership := range memberships {
+		scope.ProjectIDs = append(scope.ProjectIDs, membership.ProjectID)
+		if membership.Role == tenant_models.ProjectRoleLead {
+			scope.LeadProjectIDs = append(scope.LeadProjectIDs, membership.ProjectID)
+		}
+	}
+	return scope, nil
+}
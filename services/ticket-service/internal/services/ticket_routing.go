@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+)
+
+// routeTicket puts an unassigned ticket in its agent group and, unless the group is worked
+// manually, with an available agent. Routing failures leave the ticket as it is; only an
+// unknown group is reported.
+func (s *ticketService) routeTicket(tenantID string, ticket *tenant_models.Ticket) error {
+	decision, err := s.routing.Route(tenantID, ticket)
+	if errors.Is(err, ErrValidation) {
+		return err
+	}
+	if err != nil {
+		s.logger.Warn("Failed to route ticket", zap.Error(err), zap.String("tenant_id", tenantID))
+		return nil
+	}
+	if decision == nil {
+		return nil
+	}
+
+	ticket.GroupID = &decision.GroupID
+	ticket.AssigneeID = optionalString(decision.AssigneeID)
+	return nil
+}
+
+// checkGroup rejects references to agent groups that do not exist
+func (s *ticketService) checkGroup(tenantID, groupID string) error {
+	_, err := s.routing.GetGroup(tenant_models.SystemUserID, tenantID, groupID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return validationError("agent group %s not found", groupID)
+	}
+	return err
+}
+
+// GetAgentAvailability returns an agent's availability; managers may look up anyone
+func (s *ticketService) GetAgentAvailability(actor Actor, agentID string) (*tenant_models.AgentAvailabilityResponse, error) {
+	if err := canManageAvailability(actor, agentID); err != nil {
+		return nil, err
+	}
+	return s.routing.GetAvailability(actor.TenantID, agentID)
+}
+
+// UpdateAgentAvailability sets an agent's availability. An agent going offline hands their
+// open group tickets to the rest of each group.
+func (s *ticketService) UpdateAgentAvailability(actor Actor, agentID string, req *tenant_models.AgentAvailabilityRequest) (*tenant_models.AgentAvailabilityResponse, error) {
+	if err := canManageAvailability(actor, agentID); err != nil {
+		return nil, err
+	}
+
+	previous, response, err := s.routing.SetAvailability(actor.TenantID, agentID, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != tenant_models.AgentOffline || previous == tenant_models.AgentOffline {
+		return response, nil
+	}
+
+	handed, err := s.handOffTickets(actor.TenantID, agentID)
+	if err != nil {
+		return nil, err
+	}
+	response.Reassigned = handed
+	response.OpenTickets -= int64(handed)
+	return response, nil
+}
+
+// handOffTickets re-routes an offline agent's open group tickets. Tickets no other member
+// can take go back to the group's queue; tickets outside any group stay with the agent.
+func (s *ticketService) handOffTickets(tenantID, agentID string) (int, error) {
+	tickets, err := s.repo.ListOpenByAssignee(tenantID, agentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list agent tickets: %w", err)
+	}
+
+	system := Actor{UserID: tenant_models.SystemUserID, TenantID: tenantID, Role: models.MembershipRoleAdmin}
+	handed := 0
+	for _, ticket := range tickets {
+		if ticket.GroupID == nil {
+			continue
+		}
+
+		assigneeID := ""
+		decision, err := s.routing.Route(tenantID, ticket)
+		if err != nil {
+			s.logger.Warn("Failed to route ticket", zap.Error(err), zap.String("ticket_id", ticket.ID))
+		} else if decision != nil {
+			assigneeID = decision.AssigneeID
+		}
+
+		if _, err := s.applyUpdate(system, ticket, &tenant_models.TicketUpdateRequest{AssigneeID: &assigneeID}); err != nil {
+			s.logger.Warn("Failed to hand off ticket", zap.Error(err), zap.String("ticket_id", ticket.ID))
+			continue
+		}
+		handed++
+	}
+
+	s.logger.Info("Tickets handed off from offline agent",
+		zap.String("user_id", agentID),
+		zap.String("tenant_id", tenantID),
+		zap.Int("tickets", handed))
+
+	return handed, nil
+}
+
+func canManageAvailability(actor Actor, agentID string) error {
+	if !actor.HasRole(models.MembershipRoleAgent) {
+		return accessDenied("only agents have an availability")
+	}
+	if agentID != actor.UserID && !actor.HasRole(models.MembershipRoleManager) {
+		return accessDenied("only managers can change another agent's availability")
+	}
+	return nil
+}
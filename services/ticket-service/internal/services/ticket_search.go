@@ -0,0 +1,223 @@
+package services
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// parseSearchQuery turns a search string into full-text terms and field conditions.
+//
+// Words, "quoted phrases" and -excluded words are matched against the title,
+// description and public comments. field:value operators filter on ticket fields,
+// e.g. status:open assignee:me label:billing; a comma-separated value matches any of
+// its entries, a quoted value may contain spaces and a leading - negates the operator.
+// assignee and reporter accept "me", and assignee also "none" for unassigned tickets.
+// Date operators (created, updated, resolved, due) take relative tokens resolved when
+// the query runs, so saved queries stay current; see parseDateRange. Custom fields are
+// filtered with cf.<key>:value, taking the values customFieldFilter accepts.
+func parseSearchQuery(actor Actor, raw string, fields map[string]*tenant_models.CustomFieldDefinition) (repositories.TicketSearch, error) {
+	var search repositories.TicketSearch
+	var text []string
+	now := time.Now()
+
+	for _, token := range tokenizeSearch(raw) {
+		negate := strings.HasPrefix(token, "-")
+		field, value, isOperator := strings.Cut(strings.TrimPrefix(token, "-"), ":")
+		field = strings.ToLower(field)
+		if key, isCustom := strings.CutPrefix(field, "cf."); isCustom && isOperator {
+			definition, ok := fields[key]
+			if !ok {
+				return search, validationError("unknown custom field %q", key)
+			}
+			filter, err := customFieldFilter(actor, definition, strings.Trim(value, `"`))
+			if err != nil {
+				return search, err
+			}
+			filter.Negate = negate
+			search.CustomFields = append(search.CustomFields, filter)
+			continue
+		}
+		_, known := repositories.SearchFields[field]
+		_, isDate := repositories.DateFields[field]
+		if !isOperator || (!known && !isDate) {
+			text = append(text, token)
+			continue
+		}
+
+		if isDate {
+			dateRange, err := parseDateRange(field, strings.Trim(value, `"`), now)
+			if err != nil {
+				return search, err
+			}
+			dateRange.Negate = negate
+			search.DateRanges = append(search.DateRanges, dateRange)
+			continue
+		}
+
+		var values []string
+		for _, item := range strings.Split(strings.Trim(value, `"`), ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			resolved, err := resolveSearchValue(actor, field, item)
+			if err != nil {
+				return search, err
+			}
+			values = append(values, resolved)
+		}
+		if len(values) == 0 {
+			return search, validationError("%s: needs a value", field)
+		}
+
+		search.Conditions = append(search.Conditions, repositories.SearchCondition{
+			Field:  field,
+			Values: values,
+			Negate: negate,
+		})
+	}
+
+	search.Text = strings.Join(text, " ")
+	return search, nil
+}
+
+// resolveSearchValue expands "me" and "none" and checks ID operators carry IDs
+func resolveSearchValue(actor Actor, field, value string) (string, error) {
+	switch field {
+	case "assignee", "reporter", "project":
+		switch {
+		case strings.EqualFold(value, "me") && field != "project":
+			return actor.UserID, nil
+		case strings.EqualFold(value, "none") && field != "reporter":
+			return "", nil
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			return "", validationError("%s: expects an ID, got %q", field, value)
+		}
+	}
+	return value, nil
+}
+
+// relativeDate matches tokens such as last_7d, last_24h or next_2w
+var relativeDate = regexp.MustCompile(`^(last|next)_(\d{1,4})([hdwm])$`)
+
+// parseDateRange turns a date operator value into a range. It accepts today, yesterday,
+// this_week, this_month, last_<n><h|d|w|m>, next_<n><h|d|w|m>, none, a YYYY-MM-DD day and
+// a YYYY-MM-DD..YYYY-MM-DD span, either end of which may be left open.
+func parseDateRange(field, value string, now time.Time) (repositories.DateRange, error) {
+	dateRange := repositories.DateRange{Field: field}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	span := func(from, to time.Time) (repositories.DateRange, error) {
+		dateRange.From, dateRange.To = &from, &to
+		return dateRange, nil
+	}
+
+	value = strings.ToLower(value)
+	switch value {
+	case "":
+		return dateRange, validationError("%s: needs a value", field)
+	case "none":
+		dateRange.IsNull = true
+		return dateRange, nil
+	case "today":
+		return span(today, today.AddDate(0, 0, 1))
+	case "yesterday":
+		return span(today.AddDate(0, 0, -1), today)
+	case "this_week":
+		// Weeks start on Monday
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		return span(monday, monday.AddDate(0, 0, 7))
+	case "this_month":
+		first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return span(first, first.AddDate(0, 1, 0))
+	}
+
+	if match := relativeDate.FindStringSubmatch(value); match != nil {
+		n, _ := strconv.Atoi(match[2])
+		var shifted time.Time
+		switch match[3] {
+		case "h":
+			shifted = now.Add(time.Duration(n) * time.Hour)
+		case "d":
+			shifted = now.AddDate(0, 0, n)
+		case "w":
+			shifted = now.AddDate(0, 0, 7*n)
+		case "m":
+			shifted = now.AddDate(0, n, 0)
+		}
+		if match[1] == "last" {
+			past := now.Add(now.Sub(shifted))
+			return span(past, now)
+		}
+		return span(now, shifted)
+	}
+
+	start, end, isSpan := strings.Cut(value, "..")
+	if !isSpan {
+		day, err := time.ParseInLocation("2006-01-02", value, now.Location())
+		if err != nil {
+			return dateRange, validationError("%s: unknown date %q", field, value)
+		}
+		return span(day, day.AddDate(0, 0, 1))
+	}
+	if start == "" && end == "" {
+		return dateRange, validationError("%s: a date span needs at least one end", field)
+	}
+	if start != "" {
+		from, err := time.ParseInLocation("2006-01-02", start, now.Location())
+		if err != nil {
+			return dateRange, validationError("%s: unknown date %q", field, start)
+		}
+		dateRange.From = &from
+	}
+	if end != "" {
+		// The end day is included
+		to, err := time.ParseInLocation("2006-01-02", end, now.Location())
+		if err != nil {
+			return dateRange, validationError("%s: unknown date %q", field, end)
+		}
+		to = to.AddDate(0, 0, 1)
+		dateRange.To = &to
+	}
+	return dateRange, nil
+}
+
+// tokenizeSearch splits on whitespace while keeping quoted text, including a quoted
+// operator value such as label:"needs review", in a single token
+func tokenizeSearch(raw string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		token := current.String()
+		// Close a dangling quote so the phrase still searches as one
+		if inQuotes {
+			token += `"`
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+const searchProjectID = "3f1c6a52-8d0e-4b7a-9a51-2f4f8f3c1d11"
+
+var searchFields = map[string]*tenant_models.CustomFieldDefinition{
+	"region": {Key: "region", FieldType: tenant_models.CustomFieldText},
+}
+
+func TestTokenizeSearch(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"", nil},
+		{"  printer   jam ", []string{"printer", "jam"}},
+		{`status:open "paper jam" -toner`, []string{"status:open", `"paper jam"`, "-toner"}},
+		{`label:"needs review" urgent`, []string{`label:"needs review"`, "urgent"}},
+		{`printer "paper jam`, []string{"printer", `"paper jam"`}}, // Dangling quote is closed
+	}
+	for _, tt := range tests {
+		got := tokenizeSearch(tt.raw)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenizeSearch(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	actor := Actor{UserID: "user-1"}
+	tests := []struct {
+		raw  string
+		want repositories.TicketSearch
+	}{
+		{`printer "paper jam" -toner`, repositories.TicketSearch{Text: `printer "paper jam" -toner`}},
+		{"status:open,pending", repositories.TicketSearch{Conditions: []repositories.SearchCondition{
+			{Field: "status", Values: []string{"open", "pending"}},
+		}}},
+		{"Priority:high", repositories.TicketSearch{Conditions: []repositories.SearchCondition{
+			{Field: "priority", Values: []string{"high"}},
+		}}},
+		{"-priority:low", repositories.TicketSearch{Conditions: []repositories.SearchCondition{
+			{Field: "priority", Values: []string{"low"}, Negate: true},
+		}}},
+		{`label:"needs review"`, repositories.TicketSearch{Conditions: []repositories.SearchCondition{
+			{Field: "label", Values: []string{"needs review"}},
+		}}},
+		{"assignee:me reporter:ME", repositories.TicketSearch{Conditions: []repositories.SearchCondition{
+			{Field: "assignee", Values: []string{"user-1"}},
+			{Field: "reporter", Values: []string{"user-1"}},
+		}}},
+		{"assignee:none,me", repositories.TicketSearch{Conditions: []repositories.SearchCondition{
+			{Field: "assignee", Values: []string{"", "user-1"}},
+		}}},
+		{"project:" + searchProjectID, repositories.TicketSearch{Conditions: []repositories.SearchCondition{
+			{Field: "project", Values: []string{searchProjectID}},
+		}}},
+		{"cf.region:emea,apac", repositories.TicketSearch{CustomFields: []repositories.CustomFieldFilter{
+			{Key: "region", Values: []interface{}{"emea", "apac"}},
+		}}},
+		{`-cf.region:"north america"`, repositories.TicketSearch{CustomFields: []repositories.CustomFieldFilter{
+			{Key: "region", Values: []interface{}{"north america"}, Negate: true},
+		}}},
+		// Unknown operators and words with a colon search as text
+		{"foo:bar printer", repositories.TicketSearch{Text: "foo:bar printer"}},
+		{"status:open printer", repositories.TicketSearch{
+			Text:       "printer",
+			Conditions: []repositories.SearchCondition{{Field: "status", Values: []string{"open"}}},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := parseSearchQuery(actor, tt.raw, searchFields)
+		if err != nil {
+			t.Errorf("parseSearchQuery(%q): %v", tt.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSearchQuery(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQueryDates(t *testing.T) {
+	got, err := parseSearchQuery(Actor{}, "created:today -due:none printer", searchFields)
+	if err != nil {
+		t.Fatalf("parseSearchQuery: %v", err)
+	}
+	if got.Text != "printer" || len(got.DateRanges) != 2 {
+		t.Fatalf("parseSearchQuery = %+v, want printer with two date ranges", got)
+	}
+	if created := got.DateRanges[0]; created.Field != "created" || created.From == nil || created.To == nil || created.Negate {
+		t.Errorf("created range = %+v, want today", created)
+	}
+	if due := got.DateRanges[1]; due.Field != "due" || !due.IsNull || !due.Negate {
+		t.Errorf("due range = %+v, want a negated none", due)
+	}
+}
+
+func TestParseSearchQueryInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"status:",
+		"label:,",
+		`assignee:""`,
+		"assignee:bob",
+		"reporter:none",
+		"project:me",
+		"project:not-an-id",
+		"cf.missing:value",
+		"cf.region:",
+		"created:someday",
+		"updated:last_7y",
+		"due:..",
+		"resolved:2026-13-01",
+		"created:2026-01-01..soon",
+	} {
+		if _, err := parseSearchQuery(Actor{UserID: "user-1"}, raw, searchFields); !errors.Is(err, ErrValidation) {
+			t.Errorf("parseSearchQuery(%q) error = %v, want a validation error", raw, err)
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	now := time.Date(2026, 1, 7, 15, 30, 0, 0, time.UTC) // A Wednesday
+	day := func(month time.Month, d int) *time.Time {
+		at := time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	at := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		value    string
+		from, to *time.Time
+		isNull   bool
+	}{
+		{"today", day(1, 7), day(1, 8), false},
+		{"TODAY", day(1, 7), day(1, 8), false},
+		{"yesterday", day(1, 6), day(1, 7), false},
+		{"this_week", day(1, 5), day(1, 12), false},
+		{"this_month", day(1, 1), day(2, 1), false},
+		{"last_24h", at(now.Add(-24 * time.Hour)), &now, false},
+		{"last_7d", at(now.AddDate(0, 0, -7)), &now, false},
+		{"next_2w", &now, at(now.AddDate(0, 0, 14)), false},
+		{"last_1m", at(now.AddDate(0, 0, -31)), &now, false},
+		{"2026-01-03", day(1, 3), day(1, 4), false},
+		{"2026-01-01..2026-01-31", day(1, 1), day(2, 1), false},
+		{"..2026-01-31", nil, day(2, 1), false},
+		{"2026-01-20..", day(1, 20), nil, false},
+		{"none", nil, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDateRange("created", tt.value, now)
+		if err != nil {
+			t.Errorf("parseDateRange(%q): %v", tt.value, err)
+			continue
+		}
+		if got.Field != "created" || got.IsNull != tt.isNull || !sameTime(got.From, tt.from) || !sameTime(got.To, tt.to) {
+			t.Errorf("parseDateRange(%q) = %v..%v null=%v, want %v..%v null=%v",
+				tt.value, got.From, got.To, got.IsNull, tt.from, tt.to, tt.isNull)
+		}
+	}
+}
@@ -1,141 +1,287 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
 	"ticket-service/internal/repositories"
 )
 
 type TicketService interface {
 	// Ticket CRUD
-	CreateTicket(userID, tenantID string, req *models.TicketCreateRequest) (*models.TicketResponse, error)
-	GetTicket(userID, tenantID, ticketID string) (*models.TicketResponse, error)
-	UpdateTicket(userID, tenantID, ticketID string, req *models.TicketUpdateRequest) (*models.TicketResponse, error)
-	DeleteTicket(userID, tenantID, ticketID string) error
-	ListTickets(userID, tenantID string, limit, offset int, filters repositories.TicketFilters) ([]*models.TicketResponse, int64, error)
+	CreateTicket(actor Actor, req *tenant_models.TicketCreateRequest) (*tenant_models.TicketResponse, error)
+	GetTicket(actor Actor, ticketID string) (*tenant_models.TicketResponse, error)
+	UpdateTicket(actor Actor, ticketID string, req *tenant_models.TicketUpdateRequest) (*tenant_models.TicketResponse, error)
+	DeleteTicket(actor Actor, ticketID string) error
+	ListTickets(actor Actor, limit, offset int, filters repositories.TicketFilters) ([]*tenant_models.TicketResponse, int64, error)
 	
 	// Ticket operations
-	AssignTicket(userID, tenantID, ticketID, assigneeID string) (*models.TicketResponse, error)
-	UpdateTicketStatus(userID, tenantID, ticketID string, status models.TicketStatus) (*models.TicketResponse, error)
-	UpdateTicketPriority(userID, tenantID, ticketID string, priority models.TicketPriority) (*models.TicketResponse, error)
+	AssignTicket(actor Actor, ticketID, assigneeID string) (*tenant_models.TicketResponse, error)
+	UpdateTicketStatus(actor Actor, ticketID string, req *tenant_models.TicketTransitionRequest) (*tenant_models.TicketResponse, error)
+	GetTicketTransitions(actor Actor, ticketID string) ([]tenant_models.TicketTransitionOption, error)
+	UpdateTicketPriority(actor Actor, ticketID string, priority tenant_models.TicketPriority) (*tenant_models.TicketResponse, error)
+	
+	// Bulk operations
+	BulkUpdateTickets(actor Actor, req *tenant_models.TicketBulkRequest) (*tenant_models.BulkJobResponse, error)
+	GetBulkJob(actor Actor, jobID string) (*tenant_models.BulkJobResponse, error)
+	
+	// Links between tickets
+	CreateTicketLink(actor Actor, ticketID string, req *tenant_models.TicketLinkCreateRequest) (*tenant_models.TicketLinkResponse, error)
+	DeleteTicketLink(actor Actor, ticketID, linkID string) error
+	GetTicketLinks(actor Actor, ticketID string) (*tenant_models.TicketLinksResponse, error)
 	
 	// Comments
-	CreateComment(userID, tenantID, ticketID string, req *models.TicketCommentCreateRequest) (*models.TicketCommentResponse, error)
-	GetComments(userID, tenantID, ticketID string, includeInternal bool) ([]*models.TicketCommentResponse, error)
-	UpdateComment(userID, tenantID, commentID string, content string) (*models.TicketCommentResponse, error)
-	DeleteComment(userID, tenantID, commentID string) error
+	CreateComment(actor Actor, ticketID string, req *tenant_models.CommentCreateRequest) (*tenant_models.CommentResponse, error)
+	GetComments(actor Actor, ticketID string, includeInternal bool) ([]*tenant_models.CommentResponse, error)
+	UpdateComment(actor Actor, commentID string, content string) (*tenant_models.CommentResponse, error)
+	DeleteComment(actor Actor, commentID string) error
 	
 	// Search and stats
-	SearchTickets(userID, tenantID, query string, limit, offset int) ([]*models.TicketResponse, int64, error)
-	GetTicketStats(userID, tenantID string, dateFrom, dateTo *time.Time) (repositories.TicketStats, error)
+	SearchTickets(actor Actor, query string, limit, offset int) ([]*tenant_models.TicketSearchResult, int64, error)
+	GetTicketStats(actor Actor, filters repositories.TicketStatsFilters) (repositories.TicketStats, error)
+
+	// Audit trail
+	GetTicketTimeline(actor Actor, ticketID string) ([]*tenant_models.TicketTimelineEntry, error)
+
+	// SLA
+	GetTicketSLA(actor Actor, ticketID string) (*tenant_models.TicketSLAResponse, error)
+
+	// Scheduled automations
+	RunScheduledAutomations(tenantID string, now time.Time) error
+
+	// Agent availability
+	GetAgentAvailability(actor Actor, agentID string) (*tenant_models.AgentAvailabilityResponse, error)
+	UpdateAgentAvailability(actor Actor, agentID string, req *tenant_models.AgentAvailabilityRequest) (*tenant_models.AgentAvailabilityResponse, error)
+
+	// Satisfaction surveys, answered by customers through signed links
+	GetSurvey(token string) (*tenant_models.CSATSurveyForm, error)
+	AnswerSurvey(token string, req *tenant_models.CSATAnswerRequest) (*tenant_models.CSATSurveyForm, error)
 }
 
 type ticketService struct {
-	repo   repositories.TicketRepository
-	logger *zap.Logger
+	repo      repositories.TicketRepository
+	links     repositories.TicketLinkRepository
+	bulkJobs  repositories.BulkJobRepository
+	boards    repositories.BoardRepository
+	policy    *ticketPolicy
+	sla       SLAService
+	workflows   WorkflowService
+	replies     EmailReplyService
+	automations AutomationService
+	routing     RoutingService
+	csat        CSATService
+	fields      CustomFieldService
+	logger      *zap.Logger
 }
 
-func NewTicketService(repo repositories.TicketRepository, logger *zap.Logger) TicketService {
+func NewTicketService(repo repositories.TicketRepository, links repositories.TicketLinkRepository, bulkJobs repositories.BulkJobRepository, members repositories.ProjectMemberRepository, boards repositories.BoardRepository, sla SLAService, workflows WorkflowService, replies EmailReplyService, automations AutomationService, routing RoutingService, csat CSATService, fields CustomFieldService, logger *zap.Logger) TicketService {
 	return &ticketService{
-		repo:   repo,
-		logger: logger,
+		repo:        repo,
+		links:       links,
+		bulkJobs:    bulkJobs,
+		boards:      boards,
+		policy:      newTicketPolicy(members),
+		sla:         sla,
+		workflows:   workflows,
+		replies:     replies,
+		automations: automations,
+		routing:     routing,
+		csat:        csat,
+		fields:      fields,
+		logger:      logger,
 	}
 }
 
-func (s *ticketService) CreateTicket(userID, tenantID string, req *models.TicketCreateRequest) (*models.TicketResponse, error) {
+func (s *ticketService) CreateTicket(actor Actor, req *tenant_models.TicketCreateRequest) (*tenant_models.TicketResponse, error) {
 	// Business logic validation
 	if req.Title == "" {
-		return nil, fmt.Errorf("ticket title is required")
+		return nil, validationError("ticket title is required")
 	}
 	if req.Description == "" {
-		return nil, fmt.Errorf("ticket description is required")
+		return nil, validationError("ticket description is required")
+	}
+	if err := s.policy.CanCreateIn(actor, stringValue(req.ProjectID)); err != nil {
+		return nil, err
+	}
+	if err := checkSchedule(req.StartDate, req.DueDate); err != nil {
+		return nil, err
 	}
 
 	// Set default values
-	ticket := &models.Ticket{
-		TenantID:    tenantID,
-		Title:       req.Title,
-		Description: req.Description,
-		Status:      models.TicketStatusOpen,
-		Priority:    models.TicketPriorityMedium,
-		Type:        models.TicketTypeSupport,
-		ReporterID:  userID,
-		Category:    req.Category,
-		ProjectID:   req.ProjectID,
+	reporterID := actor.UserID
+	ticket := &tenant_models.Ticket{
+		Title:          req.Title,
+		Description:    req.Description,
+		Status:         tenant_models.StatusOpen,
+		Priority:       tenant_models.PriorityMedium,
+		TicketType:     tenant_models.TicketTypeSupport,
+		Visibility:     tenant_models.VisibilityStandard,
+		Channel:        tenant_models.ChannelWeb,
+		ReporterID:     &reporterID,
+		ProjectID:      req.ProjectID,
+		Category:       req.Category,
+		CustomerEmail:  req.CustomerEmail,
+		CustomerName:   req.CustomerName,
+		Language:       req.Language,
+		GroupID:        req.GroupID,
+		StartDate:      req.StartDate,
+		EstimatedHours: req.EstimatedHours,
+		StoryPoints:    req.StoryPoints,
+		Labels:         models.StringArray(req.Labels),
+		CustomFields:   req.CustomFields,
 	}
 
 	// Override defaults if provided
 	if req.Priority != "" {
 		ticket.Priority = req.Priority
 	}
-	if req.Type != "" {
-		ticket.Type = req.Type
+	if req.TicketType != "" {
+		ticket.TicketType = req.TicketType
+	}
+	if req.Visibility != "" {
+		ticket.Visibility = req.Visibility
+	}
+	if req.Channel != "" {
+		ticket.Channel = req.Channel
+	}
+	if ticket.CustomFields == nil {
+		ticket.CustomFields = make(models.JSONB)
 	}
 
-	// Set due date based on priority if not provided
+	// Tickets the system opens, such as from inbound email, cannot be held back for
+	// fields only an agent can fill in
+	check := CustomFieldCheck{
+		Changed:  changedCustomFields(nil, ticket.CustomFields),
+		Defaults: true,
+		Required: actor.UserID != tenant_models.SystemUserID,
+	}
+	if err := s.fields.CheckValues(actor.TenantID, ticket, check); err != nil {
+		return nil, err
+	}
+
+	// The ticket's workflow decides where it starts
+	initialStatus, err := s.workflows.InitialStatus(actor.TenantID, ticket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workflow: %w", err)
+	}
+	ticket.Status = initialStatus
+
+	// New tickets join the bottom of their project's boards
+	if err := s.rankTicket(actor.TenantID, ticket, nil); err != nil {
+		return nil, err
+	}
+
+	// An explicit due date wins over the SLA resolution target
 	if req.DueDate != nil {
 		ticket.DueDate = req.DueDate
-	} else {
-		ticket.DueDate = s.calculateDueDate(ticket.Priority)
 	}
 
-	// Convert tags to JSONB
-	if req.Tags != nil && len(req.Tags) > 0 {
-		// In production, properly serialize tags to JSONB
-		ticket.Tags = make(models.JSONB)
+	if req.AssigneeID != nil && *req.AssigneeID != "" {
+		if err := s.policy.CanAssign(actor, ticket, *req.AssigneeID); err != nil {
+			return nil, err
+		}
+		ticket.AssigneeID = req.AssigneeID
+		if ticket.GroupID != nil {
+			if err := s.checkGroup(actor.TenantID, *ticket.GroupID); err != nil {
+				return nil, err
+			}
+		}
+	} else if err := s.routeTicket(actor.TenantID, ticket); err != nil {
+		// Tickets nobody picked an assignee for are routed to an agent group
+		return nil, err
 	}
 
+	// Plan the SLA clock up front so an SLA due date is saved with the ticket
+	ticket.ID = uuid.New().String()
+	ticket.CreatedAt = time.Now()
+	sla := s.planSLAPolicy(actor.TenantID, ticket)
+	applySLADueDate(ticket, sla)
+
 	// Create ticket in database
-	err := s.repo.Create(tenantID, ticket)
+	created := historyEntry("", actor.UserID, "ticket", tenant_models.ChangeTypeCreate, "", ticket.Title)
+	err = s.repo.Create(actor.TenantID, ticket, created)
 	if err != nil {
-		s.logger.Error("Failed to create ticket", zap.Error(err), zap.String("tenant_id", tenantID))
+		s.logger.Error("Failed to create ticket", zap.Error(err), zap.String("tenant_id", actor.TenantID))
 		return nil, fmt.Errorf("failed to create ticket: %w", err)
 	}
 
 	s.logger.Info("Ticket created successfully", 
 		zap.String("ticket_id", ticket.ID),
-		zap.String("tenant_id", tenantID),
-		zap.String("reporter_id", userID))
+		zap.String("tenant_id", actor.TenantID),
+		zap.String("reporter_id", actor.UserID))
 
-	// TODO: Send notifications (email, webhooks, etc.)
-	// TODO: Create activity log entry
+	// Start the SLA clock
+	if sla != nil {
+		if err := s.sla.StartClock(actor.TenantID, sla); err != nil {
+			s.logger.Warn("Failed to start SLA clock", zap.Error(err), zap.String("ticket_id", ticket.ID))
+		}
+	}
+
+	s.runAutomations(actor, automationEvent{trigger: tenant_models.TriggerTicketCreated, ticket: ticket})
 
 	response := ticket.ToResponse()
 	return &response, nil
 }
 
-func (s *ticketService) GetTicket(userID, tenantID, ticketID string) (*models.TicketResponse, error) {
-	ticket, err := s.repo.GetByID(tenantID, ticketID)
+func (s *ticketService) GetTicket(actor Actor, ticketID string) (*tenant_models.TicketResponse, error) {
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ticket: %w", err)
 	}
 
-	// Business logic: Check if user can view this ticket
-	if !s.canUserViewTicket(userID, ticket) {
-		return nil, fmt.Errorf("access denied: user cannot view this ticket")
+	if err := s.policy.CanView(actor, ticket); err != nil {
+		return nil, err
 	}
 
 	response := ticket.ToResponse()
 	return &response, nil
 }
 
-func (s *ticketService) UpdateTicket(userID, tenantID, ticketID string, req *models.TicketUpdateRequest) (*models.TicketResponse, error) {
+func (s *ticketService) UpdateTicket(actor Actor, ticketID string, req *tenant_models.TicketUpdateRequest) (*tenant_models.TicketResponse, error) {
 	// Get existing ticket
-	ticket, err := s.repo.GetByID(tenantID, ticketID)
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ticket: %w", err)
 	}
 
-	// Business logic: Check if user can update this ticket
-	if !s.canUserUpdateTicket(userID, ticket) {
-		return nil, fmt.Errorf("access denied: user cannot update this ticket")
+	return s.applyUpdate(actor, ticket, req)
+}
+
+// applyUpdate checks and applies an update to a loaded ticket, enforcing its workflow on status changes
+func (s *ticketService) applyUpdate(actor Actor, ticket *tenant_models.Ticket, req *tenant_models.TicketUpdateRequest) (*tenant_models.TicketResponse, error) {
+	ticketID := ticket.ID
+	authorize := s.policy.CanUpdate
+	if contentOnly(req) {
+		authorize = s.policy.CanEditContent
+	}
+	if err := authorize(actor, ticket); err != nil {
+		return nil, err
+	}
+	if req.AssigneeID != nil && *req.AssigneeID != stringValue(ticket.AssigneeID) {
+		if err := s.policy.CanAssign(actor, ticket, *req.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+	if req.ProjectID != nil && *req.ProjectID != stringValue(ticket.ProjectID) {
+		if err := s.policy.CanCreateIn(actor, *req.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	if req.GroupID != nil && *req.GroupID != "" && *req.GroupID != stringValue(ticket.GroupID) {
+		if err := s.checkGroup(actor.TenantID, *req.GroupID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Update fields if provided
+	before := *ticket
 	originalStatus := ticket.Status
 	if req.Title != nil {
 		ticket.Title = *req.Title
@@ -145,49 +291,131 @@ func (s *ticketService) UpdateTicket(userID, tenantID, ticketID string, req *mod
 	}
 	if req.Status != nil {
 		ticket.Status = *req.Status
-		// Set resolved/closed timestamps
-		if *req.Status == models.TicketStatusResolved && ticket.ResolvedAt == nil {
-			now := time.Now()
-			ticket.ResolvedAt = &now
-		}
-		if *req.Status == models.TicketStatusClosed && ticket.ClosedAt == nil {
-			now := time.Now()
-			ticket.ClosedAt = &now
-		}
 	}
+	originalPriority := ticket.Priority
+	originalType := ticket.TicketType
 	if req.Priority != nil {
 		ticket.Priority = *req.Priority
-		// Recalculate due date if priority changed
-		if ticket.DueDate != nil {
-			ticket.DueDate = s.calculateDueDate(ticket.Priority)
-		}
 	}
-	if req.Type != nil {
-		ticket.Type = *req.Type
+	if req.TicketType != nil {
+		ticket.TicketType = *req.TicketType
+	}
+	if req.Resolution != nil {
+		ticket.Resolution = *req.Resolution
 	}
 	if req.Category != nil {
 		ticket.Category = *req.Category
 	}
 	if req.AssigneeID != nil {
-		ticket.AssigneeID = *req.AssigneeID
+		ticket.AssigneeID = optionalString(*req.AssigneeID)
 	}
 	if req.ProjectID != nil {
-		ticket.ProjectID = *req.ProjectID
+		ticket.ProjectID = optionalString(*req.ProjectID)
+	}
+	if req.Visibility != nil {
+		ticket.Visibility = *req.Visibility
+	}
+	if req.StartDate != nil {
+		ticket.StartDate = req.StartDate
 	}
 	if req.DueDate != nil {
 		ticket.DueDate = req.DueDate
+		ticket.DueBySLA = false
+	}
+	if req.StartDate != nil || req.DueDate != nil {
+		if err := checkSchedule(ticket.StartDate, ticket.DueDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.EstimatedHours != nil {
+		ticket.EstimatedHours = req.EstimatedHours
+	}
+	if req.StoryPoints != nil {
+		ticket.StoryPoints = req.StoryPoints
+	}
+	if req.Labels != nil {
+		ticket.Labels = models.StringArray(*req.Labels)
+	}
+	if req.CustomFields != nil {
+		ticket.CustomFields = *req.CustomFields
+	}
+	if req.Language != nil {
+		ticket.Language = *req.Language
+	}
+	if req.GroupID != nil {
+		ticket.GroupID = optionalString(*req.GroupID)
+		// Moving a ticket to another group hands it to one of that group's agents
+		if ticket.GroupID != nil && stringValue(ticket.GroupID) != stringValue(before.GroupID) && req.AssigneeID == nil {
+			if err := s.routeTicket(actor.TenantID, ticket); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Required fields are only enforced when a user sets custom fields or moves the ticket
+	// to a type or project that may need others; automations fill in what they know
+	fieldsTouched := req.CustomFields != nil || ticket.TicketType != originalType || stringValue(ticket.ProjectID) != stringValue(before.ProjectID)
+	check := CustomFieldCheck{
+		Changed:  changedCustomFields(before.CustomFields, ticket.CustomFields),
+		Required: fieldsTouched && actor.UserID != tenant_models.SystemUserID,
+	}
+	if err := s.fields.CheckValues(actor.TenantID, ticket, check); err != nil {
+		return nil, err
+	}
+
+	// Check the move against the workflow once the ticket carries every requested change,
+	// so fields the transition requires can be supplied in the same request
+	if ticket.Status != originalStatus {
+		if err := s.workflows.CheckTransition(actor, ticket, originalStatus); err != nil {
+			return nil, err
+		}
+		if err := s.stampStatusTimes(actor, ticket); err != nil {
+			return nil, err
+		}
+	}
+
+	// Moves into a board column must fit its WIP limit; a ticket changing project or
+	// asking for a place is ranked on the project's boards
+	projectChanged := stringValue(ticket.ProjectID) != stringValue(before.ProjectID)
+	if ticket.Status != originalStatus || projectChanged {
+		if err := s.checkWIPLimit(actor, ticket, &before); err != nil {
+			return nil, err
+		}
+	}
+	// Sprints and milestones belong to a project, so a ticket leaving it leaves them
+	if projectChanged {
+		ticket.SprintID = nil
+		ticket.MilestoneID = nil
+	}
+	if req.Rank != nil || projectChanged {
+		if err := s.rankTicket(actor.TenantID, ticket, req.Rank); err != nil {
+			return nil, err
+		}
+	}
+
+	// Priority, type or custom field changes can move the ticket onto a different SLA policy
+	if ticket.Priority != originalPriority || fieldsTouched {
+		applySLADueDate(ticket, s.applySLAPolicy(actor.TenantID, ticket))
+	}
+	if ticket.Status != originalStatus {
+		if sla, err := s.sla.HandleStatusChange(actor.TenantID, slaSubjectFor(ticket), time.Now()); err != nil {
+			s.logger.Warn("Failed to update SLA clock", zap.Error(err), zap.String("ticket_id", ticketID))
+		} else {
+			applySLADueDate(ticket, sla)
+		}
 	}
 
-	// Save updated ticket
-	err = s.repo.Update(tenantID, ticket)
+	// Save updated ticket together with its audit trail
+	changes := diffTicket(actor.UserID, &before, ticket)
+	err := s.repo.Update(actor.TenantID, ticket, changes...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update ticket: %w", err)
 	}
 
 	s.logger.Info("Ticket updated successfully", 
 		zap.String("ticket_id", ticketID),
-		zap.String("tenant_id", tenantID),
-		zap.String("updated_by", userID))
+		zap.String("tenant_id", actor.TenantID),
+		zap.String("updated_by", actor.UserID))
 
 	// TODO: Send notifications if status changed
 	if req.Status != nil && *req.Status != originalStatus {
@@ -197,73 +425,84 @@ func (s *ticketService) UpdateTicket(userID, tenantID, ticketID string, req *mod
 			zap.String("new_status", string(*req.Status)))
 		// TODO: Send status change notifications
 	}
+	s.surveyIfResolved(actor.TenantID, &before, ticket)
+
+	if len(changes) > 0 {
+		s.runAutomations(actor, automationEvent{trigger: tenant_models.TriggerTicketUpdated, ticket: ticket, before: &before})
+	}
 
 	response := ticket.ToResponse()
 	return &response, nil
 }
 
-func (s *ticketService) DeleteTicket(userID, tenantID, ticketID string) error {
+func (s *ticketService) DeleteTicket(actor Actor, ticketID string) error {
 	// Get existing ticket to check permissions
-	ticket, err := s.repo.GetByID(tenantID, ticketID)
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
 	if err != nil {
 		return fmt.Errorf("failed to get ticket: %w", err)
 	}
 
-	// Business logic: Check if user can delete this ticket
-	if !s.canUserDeleteTicket(userID, ticket) {
-		return fmt.Errorf("access denied: user cannot delete this ticket")
+	if err := s.policy.CanDelete(actor, ticket); err != nil {
+		return err
 	}
 
-	err = s.repo.Delete(tenantID, ticketID)
+	deleted := historyEntry(ticketID, actor.UserID, "ticket", tenant_models.ChangeTypeDelete, ticket.Title, "")
+	err = s.repo.Delete(actor.TenantID, ticketID, deleted)
 	if err != nil {
 		return fmt.Errorf("failed to delete ticket: %w", err)
 	}
 
 	s.logger.Info("Ticket deleted successfully", 
 		zap.String("ticket_id", ticketID),
-		zap.String("tenant_id", tenantID),
-		zap.String("deleted_by", userID))
+		zap.String("tenant_id", actor.TenantID),
+		zap.String("deleted_by", actor.UserID))
 
 	return nil
 }
 
-func (s *ticketService) ListTickets(userID, tenantID string, limit, offset int, filters repositories.TicketFilters) ([]*models.TicketResponse, int64, error) {
-	// Business logic: Apply user-specific filters
-	// For example, regular users might only see their own tickets
-	// TODO: Check user role and apply appropriate filters
+func (s *ticketService) ListTickets(actor Actor, limit, offset int, filters repositories.TicketFilters) ([]*tenant_models.TicketResponse, int64, error) {
+	// Restrict the query to tickets the user may see so paging and totals stay accurate
+	scope, err := s.policy.ListScope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	filters.Scope = scope
+
+	if filters.BreachingWithin != nil || filters.SLABreached {
+		if err := s.sla.RefreshBreaches(actor.TenantID); err != nil {
+			s.logger.Warn("Failed to refresh SLA breaches", zap.Error(err), zap.String("tenant_id", actor.TenantID))
+		}
+	}
 
-	tickets, total, err := s.repo.List(tenantID, limit, offset, filters)
+	tickets, total, err := s.repo.List(actor.TenantID, limit, offset, filters)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list tickets: %w", err)
 	}
 
 	// Convert to response format
-	var responses []*models.TicketResponse
+	var responses []*tenant_models.TicketResponse
 	for _, ticket := range tickets {
-		if s.canUserViewTicket(userID, ticket) {
-			response := ticket.ToResponse()
-			responses = append(responses, &response)
-		}
+		response := ticket.ToResponse()
+		responses = append(responses, &response)
 	}
 
 	return responses, total, nil
 }
 
-func (s *ticketService) AssignTicket(userID, tenantID, ticketID, assigneeID string) (*models.TicketResponse, error) {
-	ticket, err := s.repo.GetByID(tenantID, ticketID)
+func (s *ticketService) AssignTicket(actor Actor, ticketID, assigneeID string) (*tenant_models.TicketResponse, error) {
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ticket: %w", err)
 	}
 
-	// Business logic: Check if user can assign tickets
-	if !s.canUserAssignTickets(userID) {
-		return nil, fmt.Errorf("access denied: user cannot assign tickets")
+	if err := s.policy.CanAssign(actor, ticket, assigneeID); err != nil {
+		return nil, err
 	}
 
-	// TODO: Validate that assigneeID is a valid user in this tenant
-
-	ticket.AssigneeID = assigneeID
-	err = s.repo.Update(tenantID, ticket)
+	before := *ticket
+	ticket.AssigneeID = optionalString(assigneeID)
+	changes := diffTicket(actor.UserID, &before, ticket)
+	err = s.repo.Update(actor.TenantID, ticket, changes...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to assign ticket: %w", err)
 	}
@@ -271,81 +510,154 @@ func (s *ticketService) AssignTicket(userID, tenantID, ticketID, assigneeID stri
 	s.logger.Info("Ticket assigned successfully", 
 		zap.String("ticket_id", ticketID),
 		zap.String("assignee_id", assigneeID),
-		zap.String("assigned_by", userID))
+		zap.String("assigned_by", actor.UserID))
 
 	// TODO: Send assignment notification to assignee
 
+	if len(changes) > 0 {
+		s.runAutomations(actor, automationEvent{trigger: tenant_models.TriggerTicketUpdated, ticket: ticket, before: &before})
+	}
+
 	response := ticket.ToResponse()
 	return &response, nil
 }
 
-func (s *ticketService) UpdateTicketStatus(userID, tenantID, ticketID string, status models.TicketStatus) (*models.TicketResponse, error) {
-	return s.UpdateTicket(userID, tenantID, ticketID, &models.TicketUpdateRequest{
-		Status: &status,
-	})
+// UpdateTicketStatus moves the ticket along its workflow, setting any fields the transition requires
+func (s *ticketService) UpdateTicketStatus(actor Actor, ticketID string, req *tenant_models.TicketTransitionRequest) (*tenant_models.TicketResponse, error) {
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	update := &tenant_models.TicketUpdateRequest{
+		Status:     &req.Status,
+		Resolution: req.Resolution,
+		AssigneeID: req.AssigneeID,
+	}
+	// Fields supplied with a transition add to the ticket's custom fields rather than replace them
+	if req.CustomFields != nil {
+		customFields := make(models.JSONB, len(ticket.CustomFields)+len(*req.CustomFields))
+		for key, value := range ticket.CustomFields {
+			customFields[key] = value
+		}
+		for key, value := range *req.CustomFields {
+			customFields[key] = value
+		}
+		update.CustomFields = &customFields
+	}
+
+	return s.applyUpdate(actor, ticket, update)
 }
 
-func (s *ticketService) UpdateTicketPriority(userID, tenantID, ticketID string, priority models.TicketPriority) (*models.TicketResponse, error) {
-	return s.UpdateTicket(userID, tenantID, ticketID, &models.TicketUpdateRequest{
+// GetTicketTransitions lists the statuses the actor may move the ticket to; read-only users get none
+func (s *ticketService) GetTicketTransitions(actor Actor, ticketID string) ([]tenant_models.TicketTransitionOption, error) {
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	if err := s.policy.CanView(actor, ticket); err != nil {
+		return nil, err
+	}
+	if err := s.policy.CanUpdate(actor, ticket); err != nil {
+		if errors.Is(err, ErrAccessDenied) {
+			return []tenant_models.TicketTransitionOption{}, nil
+		}
+		return nil, err
+	}
+
+	return s.workflows.AvailableTransitions(actor, ticket)
+}
+
+func (s *ticketService) UpdateTicketPriority(actor Actor, ticketID string, priority tenant_models.TicketPriority) (*tenant_models.TicketResponse, error) {
+	return s.UpdateTicket(actor, ticketID, &tenant_models.TicketUpdateRequest{
 		Priority: &priority,
 	})
 }
 
 // Comment methods
-func (s *ticketService) CreateComment(userID, tenantID, ticketID string, req *models.TicketCommentCreateRequest) (*models.TicketCommentResponse, error) {
+func (s *ticketService) CreateComment(actor Actor, ticketID string, req *tenant_models.CommentCreateRequest) (*tenant_models.CommentResponse, error) {
 	// Verify ticket exists and user can comment
-	ticket, err := s.repo.GetByID(tenantID, ticketID)
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ticket: %w", err)
 	}
 
-	if !s.canUserViewTicket(userID, ticket) {
-		return nil, fmt.Errorf("access denied: user cannot comment on this ticket")
+	if err := s.policy.CanView(actor, ticket); err != nil {
+		return nil, err
+	}
+	if req.IsInternal && !s.policy.CanViewInternal(actor) {
+		return nil, accessDenied("only agents can add internal notes")
 	}
 
-	comment := &models.TicketComment{
-		TicketID:   ticketID,
-		AuthorID:   userID,
-		Content:    req.Content,
-		IsInternal: req.IsInternal,
+	comment := &tenant_models.Comment{
+		TicketID:    ticketID,
+		AuthorID:    actor.UserID,
+		AuthorEmail: req.AuthorEmail,
+		Body:        req.Body,
+		CommentType: tenant_models.CommentTypeComment,
+		IsInternal:  req.IsInternal,
+	}
+	if req.IsInternal {
+		comment.CommentType = tenant_models.CommentTypeInternalNote
 	}
 
-	err = s.repo.CreateComment(tenantID, comment)
+	commented := historyEntry(ticketID, actor.UserID, "comment", tenant_models.ChangeTypeComment, "", "")
+	err = s.repo.CreateComment(actor.TenantID, comment, commented)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create comment: %w", err)
 	}
 
 	s.logger.Info("Comment created successfully", 
 		zap.String("ticket_id", ticketID),
-		zap.String("author_id", userID),
+		zap.String("author_id", actor.UserID),
 		zap.Bool("is_internal", req.IsInternal))
 
+	// A public reply from anyone but the requester is the first response; replies from
+	// the customer's address, by email or the portal, and automation comments never are
+	if !req.IsInternal && actor.UserID != stringValue(ticket.ReporterID) && req.AuthorEmail == "" && actor.UserID != tenant_models.SystemUserID {
+		if _, err := s.sla.RecordFirstResponse(actor.TenantID, ticketID, comment.CreatedAt); err != nil {
+			s.logger.Warn("Failed to record SLA first response", zap.Error(err), zap.String("ticket_id", ticketID))
+		}
+	}
+
+	// Public agent replies reach the customer on the ticket's email thread
+	if comment.IsPublic() && actor.UserID != tenant_models.SystemUserID && ticket.CustomerEmail != "" && s.policy.CanViewInternal(actor) {
+		go func() {
+			if err := s.replies.SendCommentReply(actor.TenantID, ticket, comment); err != nil {
+				s.logger.Warn("Failed to email comment to customer", zap.Error(err), zap.String("ticket_id", ticketID))
+			}
+		}()
+	}
+
+	s.runAutomations(actor, automationEvent{trigger: tenant_models.TriggerCommentAdded, ticket: ticket, comment: comment})
+
 	response := comment.ToResponse()
 	return &response, nil
 }
 
-func (s *ticketService) GetComments(userID, tenantID, ticketID string, includeInternal bool) ([]*models.TicketCommentResponse, error) {
+func (s *ticketService) GetComments(actor Actor, ticketID string, includeInternal bool) ([]*tenant_models.CommentResponse, error) {
 	// Verify ticket exists and user can view
-	ticket, err := s.repo.GetByID(tenantID, ticketID)
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ticket: %w", err)
 	}
 
-	if !s.canUserViewTicket(userID, ticket) {
-		return nil, fmt.Errorf("access denied: user cannot view comments on this ticket")
+	if err := s.policy.CanView(actor, ticket); err != nil {
+		return nil, err
 	}
 
-	// Business logic: Only agents/admins can see internal comments
-	if includeInternal && !s.canUserViewInternalComments(userID) {
+	// Internal notes are never shown to viewers or customers
+	if includeInternal && !s.policy.CanViewInternal(actor) {
 		includeInternal = false
 	}
 
-	comments, err := s.repo.GetComments(tenantID, ticketID, includeInternal)
+	comments, err := s.repo.GetComments(actor.TenantID, ticketID, includeInternal)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comments: %w", err)
 	}
 
-	var responses []*models.TicketCommentResponse
+	var responses []*tenant_models.CommentResponse
 	for _, comment := range comments {
 		response := comment.ToResponse()
 		responses = append(responses, &response)
@@ -354,85 +666,270 @@ func (s *ticketService) GetComments(userID, tenantID, ticketID string, includeIn
 	return responses, nil
 }
 
-func (s *ticketService) UpdateComment(userID, tenantID, commentID string, content string) (*models.TicketCommentResponse, error) {
+func (s *ticketService) UpdateComment(actor Actor, commentID string, content string) (*tenant_models.CommentResponse, error) {
 	// TODO: Implement comment update logic
 	return nil, fmt.Errorf("comment update not implemented yet")
 }
 
-func (s *ticketService) DeleteComment(userID, tenantID, commentID string) error {
+func (s *ticketService) DeleteComment(actor Actor, commentID string) error {
 	// TODO: Implement comment deletion logic
 	return fmt.Errorf("comment deletion not implemented yet")
 }
 
-func (s *ticketService) SearchTickets(userID, tenantID, query string, limit, offset int) ([]*models.TicketResponse, int64, error) {
-	tickets, total, err := s.repo.Search(tenantID, query, limit, offset)
+func (s *ticketService) SearchTickets(actor Actor, query string, limit, offset int) ([]*tenant_models.TicketSearchResult, int64, error) {
+	fields, err := s.fields.Definitions(actor.TenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	search, err := parseSearchQuery(actor, query, fields)
+	if err != nil {
+		return nil, 0, err
+	}
+	if search.Text == "" && len(search.Conditions) == 0 && len(search.DateRanges) == 0 && len(search.CustomFields) == 0 {
+		return nil, 0, validationError("search query is empty")
+	}
+
+	search.Scope, err = s.policy.ListScope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits, total, err := s.repo.Search(actor.TenantID, search, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to search tickets: %w", err)
 	}
 
-	var responses []*models.TicketResponse
-	for _, ticket := range tickets {
-		if s.canUserViewTicket(userID, ticket) {
-			response := ticket.ToResponse()
-			responses = append(responses, &response)
+	return searchResults(hits), total, nil
+}
+
+func searchResults(hits []*repositories.TicketSearchHit) []*tenant_models.TicketSearchResult {
+	responses := make([]*tenant_models.TicketSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		responses = append(responses, &tenant_models.TicketSearchResult{
+			TicketResponse: hit.Ticket.ToResponse(),
+			Rank:           hit.Rank,
+			Highlights: tenant_models.TicketSearchHighlights{
+				Title:   hit.TitleHighlight,
+				Snippet: hit.Snippet,
+			},
+		})
+	}
+	return responses
+}
+
+func (s *ticketService) GetTicketStats(actor Actor, filters repositories.TicketStatsFilters) (repositories.TicketStats, error) {
+	if err := s.policy.CanViewStats(actor); err != nil {
+		return repositories.TicketStats{}, err
+	}
+
+	switch filters.Interval {
+	case "":
+		filters.Interval = repositories.StatsIntervalDay
+	case repositories.StatsIntervalDay, repositories.StatsIntervalWeek:
+	default:
+		return repositories.TicketStats{}, validationError("interval must be %q or %q", repositories.StatsIntervalDay, repositories.StatsIntervalWeek)
+	}
+	if filters.DateFrom != nil && filters.DateTo != nil && filters.DateTo.Before(*filters.DateFrom) {
+		return repositories.TicketStats{}, validationError("date_to must not be before date_from")
+	}
+
+	// Compliance figures count breaches, so mark any that are overdue first
+	if err := s.sla.RefreshBreaches(actor.TenantID); err != nil {
+		s.logger.Warn("Failed to refresh SLA breaches", zap.Error(err), zap.String("tenant_id", actor.TenantID))
+	}
+
+	stats, err := s.repo.GetTicketStats(actor.TenantID, filters)
+	if err != nil {
+		return repositories.TicketStats{}, fmt.Errorf("failed to get ticket stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetTicketTimeline merges field changes and visible comments in chronological order
+func (s *ticketService) GetTicketTimeline(actor Actor, ticketID string) ([]*tenant_models.TicketTimelineEntry, error) {
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	if err := s.policy.CanView(actor, ticket); err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.GetHistory(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket history: %w", err)
+	}
+	comments, err := s.repo.GetComments(actor.TenantID, ticketID, s.policy.CanViewInternal(actor))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	timeline := make([]*tenant_models.TicketTimelineEntry, 0, len(history)+len(comments))
+	for _, entry := range history {
+		// Comments appear as themselves; their audit entries would only duplicate them
+		if entry.ChangeType == tenant_models.ChangeTypeComment {
+			continue
 		}
+		change := entry.ToResponse()
+		timeline = append(timeline, &tenant_models.TicketTimelineEntry{
+			Type:    tenant_models.TimelineEntryChange,
+			ActorID: entry.ChangedBy,
+			At:      entry.ChangedAt,
+			Change:  &change,
+		})
+	}
+	for _, comment := range comments {
+		response := comment.ToResponse()
+		timeline = append(timeline, &tenant_models.TicketTimelineEntry{
+			Type:    tenant_models.TimelineEntryComment,
+			ActorID: comment.AuthorID,
+			At:      comment.CreatedAt,
+			Comment: &response,
+		})
 	}
 
-	return responses, total, nil
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].At.Before(timeline[j].At)
+	})
+
+	return timeline, nil
 }
 
-func (s *ticketService) GetTicketStats(userID, tenantID string, dateFrom, dateTo *time.Time) (repositories.TicketStats, error) {
-	// TODO: Check if user can view stats (admin/manager role)
-	return s.repo.GetTicketStats(tenantID, dateFrom, dateTo)
+func (s *ticketService) GetTicketSLA(actor Actor, ticketID string) (*tenant_models.TicketSLAResponse, error) {
+	ticket, err := s.repo.GetByID(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	if err := s.policy.CanView(actor, ticket); err != nil {
+		return nil, err
+	}
+
+	sla, err := s.sla.GetTicketSLA(actor.TenantID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket SLA: %w", err)
+	}
+
+	response := sla.ToResponse()
+	return &response, nil
 }
 
 // Business logic helper methods
-func (s *ticketService) calculateDueDate(priority models.TicketPriority) *time.Time {
+
+// stampStatusTimes sets or clears the resolved and closed timestamps from the new status's category
+func (s *ticketService) stampStatusTimes(actor Actor, ticket *tenant_models.Ticket) error {
+	category, err := s.workflows.StatusCategory(actor.TenantID, ticket.Status)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
-	var dueDate time.Time
-
-	switch priority {
-	case models.TicketPriorityCritical:
-		dueDate = now.Add(4 * time.Hour) // 4 hours for critical
-	case models.TicketPriorityHigh:
-		dueDate = now.Add(24 * time.Hour) // 1 day for high
-	case models.TicketPriorityMedium:
-		dueDate = now.Add(72 * time.Hour) // 3 days for medium
-	case models.TicketPriorityLow:
-		dueDate = now.Add(168 * time.Hour) // 7 days for low
+	switch category {
+	case tenant_models.StatusCategoryResolved, tenant_models.StatusCategoryClosed:
+		if ticket.ResolvedAt == nil {
+			resolvedBy := actor.UserID
+			ticket.ResolvedAt = &now
+			ticket.ResolvedBy = &resolvedBy
+		}
+		if category == tenant_models.StatusCategoryClosed && ticket.ClosedAt == nil {
+			ticket.ClosedAt = &now
+		}
+		if category == tenant_models.StatusCategoryResolved {
+			ticket.ClosedAt = nil
+		}
 	default:
-		dueDate = now.Add(72 * time.Hour) // Default to 3 days
+		// Reopened
+		ticket.ResolvedAt = nil
+		ticket.ResolvedBy = nil
+		ticket.ClosedAt = nil
+	}
+	return nil
+}
+
+// applySLAPolicy (re)matches the ticket to an SLA policy; failures are logged, not fatal
+func (s *ticketService) applySLAPolicy(tenantID string, ticket *tenant_models.Ticket) *tenant_models.TicketSLA {
+	sla, err := s.sla.ApplyPolicy(tenantID, slaSubjectFor(ticket))
+	if err != nil {
+		s.logger.Warn("Failed to apply SLA policy", zap.Error(err), zap.String("ticket_id", ticket.ID))
+		return nil
+	}
+	return sla
+}
+
+// planSLAPolicy is applySLAPolicy for tickets not saved yet; the clock is returned unsaved
+func (s *ticketService) planSLAPolicy(tenantID string, ticket *tenant_models.Ticket) *tenant_models.TicketSLA {
+	sla, err := s.sla.PlanPolicy(tenantID, slaSubjectFor(ticket))
+	if err != nil {
+		s.logger.Warn("Failed to plan SLA policy", zap.Error(err), zap.String("ticket_id", ticket.ID))
+		return nil
 	}
+	return sla
+}
+
+// contentOnly reports whether an update only edits the ticket's title, description and
+// custom fields, which its reporter may do without being able to work the ticket
+func contentOnly(req *tenant_models.TicketUpdateRequest) bool {
+	rest := *req
+	rest.Title, rest.Description, rest.CustomFields = nil, nil, nil
+	return rest == tenant_models.TicketUpdateRequest{}
+}
 
-	return &dueDate
+// applySLADueDate sets the due date to the SLA resolution target unless the user chose
+// the due date or the target falls before the start date
+func applySLADueDate(ticket *tenant_models.Ticket, sla *tenant_models.TicketSLA) {
+	if sla == nil || sla.ResolutionTargetAt == nil {
+		return
+	}
+	if ticket.DueDate != nil && !ticket.DueBySLA {
+		return
+	}
+	if checkSchedule(ticket.StartDate, sla.ResolutionTargetAt) != nil {
+		return
+	}
+	ticket.DueDate = sla.ResolutionTargetAt
+	ticket.DueBySLA = true
 }
 
-func (s *ticketService) canUserViewTicket(userID string, ticket *models.Ticket) bool {
-	// Basic permission: user can view tickets they created or are assigned to
-	// TODO: Implement proper role-based permissions
-	return ticket.ReporterID == userID || ticket.AssigneeID == userID
+// slaSubjectFor extracts the fields SLA policies match on
+func slaSubjectFor(ticket *tenant_models.Ticket) SLASubject {
+	return SLASubject{
+		TicketID:     ticket.ID,
+		Priority:     string(ticket.Priority),
+		TicketType:   string(ticket.TicketType),
+		Channel:      string(ticket.Channel),
+		Status:       string(ticket.Status),
+		Resolved:     isResolved(ticket),
+		CustomFields: ticket.CustomFields,
+		CreatedAt:    ticket.CreatedAt,
+	}
 }
 
-func (s *ticketService) canUserUpdateTicket(userID string, ticket *models.Ticket) bool {
-	// Basic permission: user can update tickets they created or are assigned to
-	// TODO: Implement proper role-based permissions
-	return ticket.ReporterID == userID || ticket.AssigneeID == userID
+// isResolved also covers custom statuses, whose category is reflected in ResolvedAt
+func isResolved(ticket *tenant_models.Ticket) bool {
+	return ticket.IsResolved() || ticket.ResolvedAt != nil
 }
 
-func (s *ticketService) canUserDeleteTicket(userID string, ticket *models.Ticket) bool {
-	// Restrictive permission: only ticket creator can delete (or admin)
-	// TODO: Implement proper role-based permissions
-	return ticket.ReporterID == userID
+// checkSchedule rejects a due date before the start date
+func checkSchedule(start, due *time.Time) error {
+	if start != nil && due != nil && due.Before(*start) {
+		return validationError("due_date cannot be before start_date")
+	}
+	return nil
 }
 
-func (s *ticketService) canUserAssignTickets(userID string) bool {
-	// TODO: Check if user has agent/admin role
-	// For now, allow all users to assign tickets
-	return true
+// stringValue dereferences an optional ID, treating nil as empty
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
 }
 
-func (s *ticketService) canUserViewInternalComments(userID string) bool {
-	// TODO: Check if user has agent/admin role
-	// For now, allow all users to view internal comments
-	return true
-}
\ No newline at end of file
+// optionalString maps an empty value onto a NULL column
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+const (
+	maxOwnedViews   = 50  // Views one user may save
+	maxCountedViews = 100 // Views GetViewCounts counts; later ones report no count
+)
+
+// builtinView is a personal queue every user has without saving it
+type builtinView struct {
+	view       tenant_models.SavedView
+	agentsOnly bool
+}
+
+// builtinViews use readable IDs, which can never clash with the UUIDs of saved views
+var builtinViews = []builtinView{
+	{view: tenant_models.SavedView{ID: "my-open", Name: "My open tickets", Query: "assignee:me -status:resolved,closed", SortBy: "priority", SortDesc: true}},
+	{view: tenant_models.SavedView{ID: "reported-by-me", Name: "Reported by me", Query: "reporter:me", SortBy: "updated", SortDesc: true}},
+	{view: tenant_models.SavedView{ID: "unassigned", Name: "Unassigned", Query: "assignee:none -status:resolved,closed", SortBy: "created"}, agentsOnly: true},
+}
+
+// validViewColumns are the ticket list columns a view can show
+var validViewColumns = map[string]bool{
+	"number": true, "title": true, "status": true, "priority": true, "type": true,
+	"category": true, "assignee": true, "reporter": true, "project": true, "channel": true,
+	"customer": true, "labels": true, "due": true, "created": true, "updated": true, "sla": true,
+}
+
+// ViewService manages saved ticket views and runs them for the current user
+type ViewService interface {
+	ListViews(actor Actor) ([]*tenant_models.SavedViewResponse, error)
+	CreateView(actor Actor, req *tenant_models.SavedViewCreateRequest) (*tenant_models.SavedViewResponse, error)
+	GetView(actor Actor, viewID string) (*tenant_models.SavedViewResponse, error)
+	UpdateView(actor Actor, viewID string, req *tenant_models.SavedViewUpdateRequest) (*tenant_models.SavedViewResponse, error)
+	DeleteView(actor Actor, viewID string) error
+
+	GetViewTickets(actor Actor, viewID string, limit, offset int) ([]*tenant_models.TicketSearchResult, int64, error)
+	GetViewCounts(actor Actor) ([]tenant_models.SavedViewCount, error)
+}
+
+type viewService struct {
+	repo    repositories.SavedViewRepository
+	tickets repositories.TicketRepository
+	members repositories.ProjectMemberRepository
+	fields  CustomFieldService
+	policy  *ticketPolicy
+	logger  *zap.Logger
+}
+
+func NewViewService(repo repositories.SavedViewRepository, tickets repositories.TicketRepository, members repositories.ProjectMemberRepository, fields CustomFieldService, logger *zap.Logger) ViewService {
+	return &viewService{
+		repo:    repo,
+		tickets: tickets,
+		members: members,
+		fields:  fields,
+		policy:  newTicketPolicy(members),
+		logger:  logger,
+	}
+}
+
+// viewAccess is what the actor's project memberships allow for team views
+type viewAccess struct {
+	projectIDs     []string // nil for admins, who see every team view
+	leadProjectIDs map[string]bool
+}
+
+func (s *viewService) access(actor Actor) (*viewAccess, error) {
+	access := &viewAccess{leadProjectIDs: map[string]bool{}}
+	if actor.isAdmin() {
+		return access, nil
+	}
+
+	memberships, err := s.members.ListMemberships(actor.TenantID, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project memberships: %w", err)
+	}
+	access.projectIDs = []string{}
+	for _, membership := range memberships {
+		access.projectIDs = append(access.projectIDs, membership.ProjectID)
+		if membership.Role == tenant_models.ProjectRoleLead {
+			access.leadProjectIDs[membership.ProjectID] = true
+		}
+	}
+	return access, nil
+}
+
+func (a *viewAccess) inProject(projectID string) bool {
+	if a.projectIDs == nil {
+		return true
+	}
+	for _, id := range a.projectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *viewAccess) canSee(actor Actor, view *tenant_models.SavedView) bool {
+	switch view.Visibility {
+	case tenant_models.ViewTenant:
+		return true
+	case tenant_models.ViewTeam:
+		return a.inProject(stringValue(view.ProjectID))
+	default:
+		return view.OwnerID == actor.UserID
+	}
+}
+
+// canEdit: personal views belong to their owner; team views to their creator, project
+// leads and admins; tenant views to Managers and above
+func (a *viewAccess) canEdit(actor Actor, view *tenant_models.SavedView) bool {
+	switch view.Visibility {
+	case tenant_models.ViewTenant:
+		return actor.HasRole(models.MembershipRoleManager)
+	case tenant_models.ViewTeam:
+		return view.OwnerID == actor.UserID || actor.isAdmin() || a.leadProjectIDs[stringValue(view.ProjectID)]
+	default:
+		return view.OwnerID == actor.UserID
+	}
+}
+
+func (s *viewService) ListViews(actor Actor) ([]*tenant_models.SavedViewResponse, error) {
+	access, err := s.access(actor)
+	if err != nil {
+		return nil, err
+	}
+	views, err := s.visibleViews(actor, access)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*tenant_models.SavedViewResponse, 0, len(views))
+	for _, view := range views {
+		responses = append(responses, s.toResponse(actor, access, view))
+	}
+	return responses, nil
+}
+
+func (s *viewService) CreateView(actor Actor, req *tenant_models.SavedViewCreateRequest) (*tenant_models.SavedViewResponse, error) {
+	access, err := s.access(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &tenant_models.SavedView{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Query:       strings.TrimSpace(req.Query),
+		SortBy:      req.SortBy,
+		SortDesc:    true,
+		Columns:     models.StringArray(req.Columns),
+		Visibility:  req.Visibility,
+		OwnerID:     actor.UserID,
+		ProjectID:   req.ProjectID,
+		Position:    req.Position,
+	}
+	if req.SortDesc != nil {
+		view.SortDesc = *req.SortDesc
+	}
+	if view.Visibility == "" {
+		view.Visibility = tenant_models.ViewPersonal
+	}
+	if view.Columns == nil {
+		view.Columns = models.StringArray{}
+	}
+	if err := s.validateView(actor, access, view); err != nil {
+		return nil, err
+	}
+	owned, err := s.repo.CountOwned(actor.TenantID, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count views: %w", err)
+	}
+	if owned >= maxOwnedViews {
+		return nil, validationError("you can save at most %d views; delete one first", maxOwnedViews)
+	}
+
+	if err := s.repo.Create(actor.TenantID, view); err != nil {
+		return nil, fmt.Errorf("failed to create view: %w", err)
+	}
+
+	s.logger.Info("Saved view created",
+		zap.String("view_id", view.ID),
+		zap.String("visibility", string(view.Visibility)),
+		zap.String("tenant_id", actor.TenantID))
+
+	return s.toResponse(actor, access, view), nil
+}
+
+func (s *viewService) GetView(actor Actor, viewID string) (*tenant_models.SavedViewResponse, error) {
+	access, err := s.access(actor)
+	if err != nil {
+		return nil, err
+	}
+	view, err := s.findView(actor, access, viewID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(actor, access, view), nil
+}
+
+func (s *viewService) UpdateView(actor Actor, viewID string, req *tenant_models.SavedViewUpdateRequest) (*tenant_models.SavedViewResponse, error) {
+	access, err := s.access(actor)
+	if err != nil {
+		return nil, err
+	}
+	view, err := s.editableView(actor, access, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		view.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		view.Description = *req.Description
+	}
+	if req.Query != nil {
+		view.Query = strings.TrimSpace(*req.Query)
+	}
+	if req.SortBy != nil {
+		view.SortBy = *req.SortBy
+	}
+	if req.SortDesc != nil {
+		view.SortDesc = *req.SortDesc
+	}
+	if req.Columns != nil {
+		view.Columns = models.StringArray(*req.Columns)
+	}
+	if req.Visibility != nil {
+		view.Visibility = *req.Visibility
+	}
+	if req.ProjectID != nil {
+		view.ProjectID = optionalString(*req.ProjectID)
+	}
+	if req.Position != nil {
+		view.Position = *req.Position
+	}
+	// Changing who sees the view is checked against the new sharing as well
+	if err := s.validateView(actor, access, view); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(actor.TenantID, view); err != nil {
+		return nil, fmt.Errorf("failed to update view: %w", err)
+	}
+	return s.toResponse(actor, access, view), nil
+}
+
+func (s *viewService) DeleteView(actor Actor, viewID string) error {
+	access, err := s.access(actor)
+	if err != nil {
+		return err
+	}
+	if _, err := s.editableView(actor, access, viewID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(actor.TenantID, viewID); err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	return nil
+}
+
+// GetViewTickets runs the view's query for the actor, within the tickets they may see
+func (s *viewService) GetViewTickets(actor Actor, viewID string, limit, offset int) ([]*tenant_models.TicketSearchResult, int64, error) {
+	access, err := s.access(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	view, err := s.findView(actor, access, viewID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scope, err := s.policy.ListScope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	fields, err := s.fields.Definitions(actor.TenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	search, err := viewSearch(actor, view, scope, fields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits, total, err := s.tickets.Search(actor.TenantID, search, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to run view: %w", err)
+	}
+	return searchResults(hits), total, nil
+}
+
+// GetViewCounts counts each visible view's tickets, one query per view up to
+// maxCountedViews. A view whose query no longer parses, or past that cap, reports the
+// reason instead of failing the whole list.
+func (s *viewService) GetViewCounts(actor Actor) ([]tenant_models.SavedViewCount, error) {
+	access, err := s.access(actor)
+	if err != nil {
+		return nil, err
+	}
+	views, err := s.visibleViews(actor, access)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := s.policy.ListScope(actor)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := s.fields.Definitions(actor.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]tenant_models.SavedViewCount, 0, len(views))
+	for i, view := range views {
+		count := tenant_models.SavedViewCount{ViewID: view.ID}
+		if i >= maxCountedViews {
+			count.Error = fmt.Sprintf("only the first %d views are counted", maxCountedViews)
+			counts = append(counts, count)
+			continue
+		}
+		search, err := viewSearch(actor, view, scope, fields)
+		if err != nil {
+			count.Error = err.Error()
+			counts = append(counts, count)
+			continue
+		}
+
+		total, err := s.tickets.CountSearch(actor.TenantID, search)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count view %s: %w", view.ID, err)
+		}
+		count.Count = &total
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// visibleViews lists the built-in queues followed by the saved views the actor can see
+func (s *viewService) visibleViews(actor Actor, access *viewAccess) ([]*tenant_models.SavedView, error) {
+	var views []*tenant_models.SavedView
+	for _, builtin := range builtinViews {
+		if builtin.agentsOnly && !s.policy.CanViewInternal(actor) {
+			continue
+		}
+		view := builtin.view
+		view.Visibility = tenant_models.ViewPersonal
+		view.OwnerID = actor.UserID
+		views = append(views, &view)
+	}
+
+	saved, err := s.repo.ListVisible(actor.TenantID, actor.UserID, access.projectIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+	return append(views, saved...), nil
+}
+
+// findView returns a built-in or saved view; views the actor cannot see are reported as missing
+func (s *viewService) findView(actor Actor, access *viewAccess, viewID string) (*tenant_models.SavedView, error) {
+	for _, builtin := range builtinViews {
+		if builtin.view.ID == viewID && (!builtin.agentsOnly || s.policy.CanViewInternal(actor)) {
+			view := builtin.view
+			view.Visibility = tenant_models.ViewPersonal
+			view.OwnerID = actor.UserID
+			return &view, nil
+		}
+	}
+	if _, err := uuid.Parse(viewID); err != nil {
+		return nil, fmt.Errorf("failed to get view: %w", gorm.ErrRecordNotFound)
+	}
+
+	view, err := s.repo.Get(actor.TenantID, viewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get view: %w", err)
+	}
+	if !access.canSee(actor, view) {
+		return nil, fmt.Errorf("failed to get view: %w", gorm.ErrRecordNotFound)
+	}
+	return view, nil
+}
+
+func (s *viewService) editableView(actor Actor, access *viewAccess, viewID string) (*tenant_models.SavedView, error) {
+	view, err := s.findView(actor, access, viewID)
+	if err != nil {
+		return nil, err
+	}
+	if isBuiltinView(view.ID) {
+		return nil, validationError("built-in views cannot be changed")
+	}
+	if !access.canEdit(actor, view) {
+		return nil, accessDenied("you cannot change this view")
+	}
+	return view, nil
+}
+
+func (s *viewService) validateView(actor Actor, access *viewAccess, view *tenant_models.SavedView) error {
+	if view.Name == "" {
+		return validationError("view name is required")
+	}
+	fields, err := s.fields.Definitions(actor.TenantID)
+	if err != nil {
+		return err
+	}
+	if _, err := parseSearchQuery(actor, view.Query, fields); err != nil {
+		return err
+	}
+	if _, err := s.fields.ParseSort(actor, view.SortBy, view.SortDesc); err != nil {
+		return err
+	}
+	for _, column := range view.Columns {
+		if !validViewColumns[column] {
+			return validationError("unknown column %q", column)
+		}
+	}
+
+	switch view.Visibility {
+	case tenant_models.ViewPersonal:
+		view.ProjectID = nil
+	case tenant_models.ViewTeam:
+		if view.ProjectID == nil {
+			return validationError("team views need a project_id")
+		}
+		if !access.inProject(*view.ProjectID) {
+			return accessDenied("team views can only be shared with projects you belong to")
+		}
+	case tenant_models.ViewTenant:
+		view.ProjectID = nil
+		if !actor.HasRole(models.MembershipRoleManager) {
+			return accessDenied("tenant-wide views require the Manager role")
+		}
+	default:
+		return validationError("unknown visibility %q", view.Visibility)
+	}
+	return nil
+}
+
+func (s *viewService) toResponse(actor Actor, access *viewAccess, view *tenant_models.SavedView) *tenant_models.SavedViewResponse {
+	response := view.ToResponse()
+	response.Builtin = isBuiltinView(view.ID)
+	response.Editable = !response.Builtin && access.canEdit(actor, view)
+	return &response
+}
+
+func isBuiltinView(viewID string) bool {
+	for _, builtin := range builtinViews {
+		if builtin.view.ID == viewID {
+			return true
+		}
+	}
+	return false
+}
+
+// viewSearch resolves the view's query for the actor at the current time
+func viewSearch(actor Actor, view *tenant_models.SavedView, scope *repositories.TicketAccessScope, fields map[string]*tenant_models.CustomFieldDefinition) (repositories.TicketSearch, error) {
+	search, err := parseSearchQuery(actor, view.Query, fields)
+	if err != nil {
+		return search, err
+	}
+	if view.SortBy != "" {
+		search.Sort = &repositories.TicketSort{Field: view.SortBy, Descending: view.SortDesc}
+	}
+	search.Scope = scope
+	return search, nil
+}
@@ -0,0 +1,651 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/models"
+	"github.com/zen/shared/pkg/tenant_models"
+	"ticket-service/internal/repositories"
+)
+
+// builtinStatusCategories are the statuses every tenant has, whether or not it defines its own
+var builtinStatusCategories = map[tenant_models.TicketStatus]tenant_models.StatusCategory{
+	tenant_models.StatusOpen:       tenant_models.StatusCategoryOpen,
+	tenant_models.StatusInProgress: tenant_models.StatusCategoryInProgress,
+	tenant_models.StatusOnHold:     tenant_models.StatusCategoryInProgress,
+	tenant_models.StatusResolved:   tenant_models.StatusCategoryResolved,
+	tenant_models.StatusClosed:     tenant_models.StatusCategoryClosed,
+}
+
+// builtinStatusOrder lists the built-in statuses the way they are offered to clients
+var builtinStatusOrder = []tenant_models.TicketStatus{
+	tenant_models.StatusOpen,
+	tenant_models.StatusInProgress,
+	tenant_models.StatusOnHold,
+	tenant_models.StatusResolved,
+	tenant_models.StatusClosed,
+}
+
+var validMembershipRoles = map[models.MembershipRole]bool{
+	models.MembershipRoleOwner:   true,
+	models.MembershipRoleAdmin:   true,
+	models.MembershipRoleManager: true,
+	models.MembershipRoleAgent:   true,
+	models.MembershipRoleViewer:  true,
+}
+
+var validStatusCategories = map[tenant_models.StatusCategory]bool{
+	tenant_models.StatusCategoryOpen:       true,
+	tenant_models.StatusCategoryInProgress: true,
+	tenant_models.StatusCategoryResolved:   true,
+	tenant_models.StatusCategoryClosed:     true,
+}
+
+// customFieldPrefix marks a required field that lives in the ticket's custom fields
+const customFieldPrefix = "custom_fields."
+
+type WorkflowService interface {
+	// Custom statuses
+	CreateStatus(userID, tenantID string, req *tenant_models.CustomStatusCreateRequest) (*tenant_models.CustomStatusResponse, error)
+	UpdateStatus(userID, tenantID, statusID string, req *tenant_models.CustomStatusUpdateRequest) (*tenant_models.CustomStatusResponse, error)
+	DeleteStatus(userID, tenantID, statusID string) error
+	ListStatuses(userID, tenantID string) ([]*tenant_models.CustomStatusResponse, error)
+
+	// Workflow definitions
+	CreateWorkflow(userID, tenantID string, req *tenant_models.WorkflowCreateRequest) (*tenant_models.WorkflowResponse, error)
+	GetWorkflow(userID, tenantID, workflowID string) (*tenant_models.WorkflowResponse, error)
+	UpdateWorkflow(userID, tenantID, workflowID string, req *tenant_models.WorkflowUpdateRequest) (*tenant_models.WorkflowResponse, error)
+	DeleteWorkflow(userID, tenantID, workflowID string) error
+	ListWorkflows(userID, tenantID string) ([]*tenant_models.WorkflowResponse, error)
+
+	// Ticket transitions
+	InitialStatus(tenantID string, ticket *tenant_models.Ticket) (tenant_models.TicketStatus, error)
+	StatusCategory(tenantID string, status tenant_models.TicketStatus) (tenant_models.StatusCategory, error)
+	CheckTransition(actor Actor, ticket *tenant_models.Ticket, from tenant_models.TicketStatus) error
+	AvailableTransitions(actor Actor, ticket *tenant_models.Ticket) ([]tenant_models.TicketTransitionOption, error)
+}
+
+type workflowService struct {
+	repo   repositories.WorkflowRepository
+	logger *zap.Logger
+}
+
+func NewWorkflowService(repo repositories.WorkflowRepository, logger *zap.Logger) WorkflowService {
+	return &workflowService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Custom status methods
+func (s *workflowService) CreateStatus(userID, tenantID string, req *tenant_models.CustomStatusCreateRequest) (*tenant_models.CustomStatusResponse, error) {
+	if _, builtin := builtinStatusCategories[tenant_models.TicketStatus(req.Name)]; builtin {
+		return nil, validationError("%q is a built-in status", req.Name)
+	}
+	if !validStatusCategories[req.Category] {
+		return nil, validationError("invalid status category %q", req.Category)
+	}
+	if _, err := s.repo.GetStatusByName(tenantID, req.Name); err == nil {
+		return nil, validationError("status %q already exists", req.Name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check status name: %w", err)
+	}
+
+	status := &tenant_models.CustomStatus{
+		Name:      req.Name,
+		Category:  req.Category,
+		Color:     req.Color,
+		SortOrder: req.SortOrder,
+		IsActive:  true,
+	}
+	if err := s.repo.CreateStatus(tenantID, status); err != nil {
+		s.logger.Error("Failed to create custom status", zap.Error(err), zap.String("tenant_id", tenantID))
+		return nil, fmt.Errorf("failed to create status: %w", err)
+	}
+
+	s.logger.Info("Custom status created",
+		zap.String("status_id", status.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	response := status.ToResponse()
+	return &response, nil
+}
+
+func (s *workflowService) UpdateStatus(userID, tenantID, statusID string, req *tenant_models.CustomStatusUpdateRequest) (*tenant_models.CustomStatusResponse, error) {
+	status, err := s.repo.GetStatus(tenantID, statusID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	// Tickets and workflows refer to statuses by name
+	if req.Name != nil && *req.Name != status.Name {
+		return nil, validationError("statuses cannot be renamed; create a new status instead")
+	}
+	if req.Category != nil {
+		if !validStatusCategories[*req.Category] {
+			return nil, validationError("invalid status category %q", *req.Category)
+		}
+		status.Category = *req.Category
+	}
+	if req.Color != nil {
+		status.Color = req.Color
+	}
+	if req.SortOrder != nil {
+		status.SortOrder = *req.SortOrder
+	}
+	if req.IsActive != nil {
+		status.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdateStatus(tenantID, status); err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	s.logger.Info("Custom status updated",
+		zap.String("status_id", statusID),
+		zap.String("tenant_id", tenantID),
+		zap.String("updated_by", userID))
+
+	response := status.ToResponse()
+	return &response, nil
+}
+
+func (s *workflowService) DeleteStatus(userID, tenantID, statusID string) error {
+	status, err := s.repo.GetStatus(tenantID, statusID)
+	if err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+
+	inUse, err := s.repo.CountTicketsInStatus(tenantID, status.Name)
+	if err != nil {
+		return fmt.Errorf("failed to count tickets in status: %w", err)
+	}
+	if inUse > 0 {
+		return validationError("%d tickets are in status %q; move them or deactivate the status instead", inUse, status.Name)
+	}
+
+	workflows, err := s.repo.ListWorkflows(tenantID, false)
+	if err != nil {
+		return fmt.Errorf("failed to list workflows: %w", err)
+	}
+	for _, workflow := range workflows {
+		if workflowUsesStatus(workflow, status.Name) {
+			return validationError("status %q is used by workflow %q", status.Name, workflow.Name)
+		}
+	}
+
+	if err := s.repo.DeleteStatus(tenantID, statusID); err != nil {
+		return fmt.Errorf("failed to delete status: %w", err)
+	}
+
+	s.logger.Info("Custom status deleted",
+		zap.String("status_id", statusID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+func (s *workflowService) ListStatuses(userID, tenantID string) ([]*tenant_models.CustomStatusResponse, error) {
+	statuses, err := s.repo.ListStatuses(tenantID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statuses: %w", err)
+	}
+
+	responses := make([]*tenant_models.CustomStatusResponse, 0, len(statuses))
+	for _, status := range statuses {
+		response := status.ToResponse()
+		responses = append(responses, &response)
+	}
+	return responses, nil
+}
+
+// Workflow methods
+func (s *workflowService) CreateWorkflow(userID, tenantID string, req *tenant_models.WorkflowCreateRequest) (*tenant_models.WorkflowResponse, error) {
+	workflow := &tenant_models.Workflow{
+		Name:          req.Name,
+		Description:   req.Description,
+		ProjectID:     optionalPointer(req.ProjectID),
+		TicketType:    optionalPointer(req.TicketType),
+		InitialStatus: req.InitialStatus,
+		IsActive:      true,
+		Transitions:   buildTransitions(req.Transitions),
+	}
+	if err := s.validateWorkflow(tenantID, workflow); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateWorkflow(tenantID, workflow); err != nil {
+		s.logger.Error("Failed to create workflow", zap.Error(err), zap.String("tenant_id", tenantID))
+		return nil, fmt.Errorf("failed to create workflow: %w", err)
+	}
+
+	s.logger.Info("Workflow created",
+		zap.String("workflow_id", workflow.ID),
+		zap.String("tenant_id", tenantID),
+		zap.String("created_by", userID))
+
+	response := workflow.ToResponse()
+	return &response, nil
+}
+
+func (s *workflowService) GetWorkflow(userID, tenantID, workflowID string) (*tenant_models.WorkflowResponse, error) {
+	workflow, err := s.repo.GetWorkflow(tenantID, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	response := workflow.ToResponse()
+	return &response, nil
+}
+
+func (s *workflowService) UpdateWorkflow(userID, tenantID, workflowID string, req *tenant_models.WorkflowUpdateRequest) (*tenant_models.WorkflowResponse, error) {
+	workflow, err := s.repo.GetWorkflow(tenantID, workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	if req.Name != nil {
+		workflow.Name = *req.Name
+	}
+	if req.Description != nil {
+		workflow.Description = *req.Description
+	}
+	if req.InitialStatus != nil {
+		workflow.InitialStatus = *req.InitialStatus
+	}
+	if req.IsActive != nil {
+		workflow.IsActive = *req.IsActive
+	}
+	replaceTransitions := req.Transitions != nil
+	if replaceTransitions {
+		if len(req.Transitions) == 0 {
+			return nil, validationError("a workflow needs at least one transition")
+		}
+		workflow.Transitions = buildTransitions(req.Transitions)
+	}
+
+	if err := s.validateWorkflow(tenantID, workflow); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateWorkflow(tenantID, workflow, replaceTransitions); err != nil {
+		return nil, fmt.Errorf("failed to update workflow: %w", err)
+	}
+
+	s.logger.Info("Workflow updated",
+		zap.String("workflow_id", workflowID),
+		zap.String("tenant_id", tenantID),
+		zap.String("updated_by", userID))
+
+	response := workflow.ToResponse()
+	return &response, nil
+}
+
+func (s *workflowService) DeleteWorkflow(userID, tenantID, workflowID string) error {
+	if _, err := s.repo.GetWorkflow(tenantID, workflowID); err != nil {
+		return fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	if err := s.repo.DeleteWorkflow(tenantID, workflowID); err != nil {
+		return fmt.Errorf("failed to delete workflow: %w", err)
+	}
+
+	s.logger.Info("Workflow deleted",
+		zap.String("workflow_id", workflowID),
+		zap.String("tenant_id", tenantID),
+		zap.String("deleted_by", userID))
+
+	return nil
+}
+
+func (s *workflowService) ListWorkflows(userID, tenantID string) ([]*tenant_models.WorkflowResponse, error) {
+	workflows, err := s.repo.ListWorkflows(tenantID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	responses := make([]*tenant_models.WorkflowResponse, 0, len(workflows))
+	for _, workflow := range workflows {
+		response := workflow.ToResponse()
+		responses = append(responses, &response)
+	}
+	return responses, nil
+}
+
+// Ticket transition methods
+
+// InitialStatus returns the status a new ticket starts in under its workflow
+func (s *workflowService) InitialStatus(tenantID string, ticket *tenant_models.Ticket) (tenant_models.TicketStatus, error) {
+	workflow, err := s.resolveWorkflow(tenantID, ticket)
+	if err != nil {
+		return "", err
+	}
+	if workflow == nil || workflow.InitialStatus == "" {
+		return tenant_models.StatusOpen, nil
+	}
+	return tenant_models.TicketStatus(workflow.InitialStatus), nil
+}
+
+// StatusCategory maps built-in and custom statuses onto the four lifecycle categories
+func (s *workflowService) StatusCategory(tenantID string, status tenant_models.TicketStatus) (tenant_models.StatusCategory, error) {
+	if category, ok := builtinStatusCategories[status]; ok {
+		return category, nil
+	}
+
+	custom, err := s.repo.GetStatusByName(tenantID, string(status))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", validationError("unknown status %q", status)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+	return custom.Category, nil
+}
+
+// CheckTransition validates moving the ticket from the given status into its current one.
+// The ticket must already carry the requested changes so required fields can be checked.
+func (s *workflowService) CheckTransition(actor Actor, ticket *tenant_models.Ticket, from tenant_models.TicketStatus) error {
+	to := ticket.Status
+	if err := s.checkStatusExists(actor.TenantID, to); err != nil {
+		return err
+	}
+
+	workflow, err := s.resolveWorkflow(actor.TenantID, ticket)
+	if err != nil {
+		return err
+	}
+	// Without a workflow any move between known statuses is allowed
+	if workflow == nil {
+		return nil
+	}
+
+	var denied error
+	for i := range workflow.Transitions {
+		transition := &workflow.Transitions[i]
+		if !transition.Allows(string(from), string(to)) {
+			continue
+		}
+		if !canTakeTransition(actor, transition) {
+			denied = accessDenied("your role cannot move tickets from %q to %q", from, to)
+			continue
+		}
+		if missing := missingFields(ticket, transition.RequiredFields); len(missing) > 0 {
+			return validationError("moving to %q requires %s", to, strings.Join(missing, ", "))
+		}
+		return nil
+	}
+
+	if denied != nil {
+		return denied
+	}
+	return validationError("workflow %q does not allow moving from %q to %q", workflow.Name, from, to)
+}
+
+// AvailableTransitions lists the statuses the actor may move the ticket to next
+func (s *workflowService) AvailableTransitions(actor Actor, ticket *tenant_models.Ticket) ([]tenant_models.TicketTransitionOption, error) {
+	workflow, err := s.resolveWorkflow(actor.TenantID, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.statusCategories(actor.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []tenant_models.TicketTransitionOption{}
+	if workflow == nil {
+		for _, status := range builtinStatusOrder {
+			if status != ticket.Status {
+				options = append(options, tenant_models.TicketTransitionOption{
+					ToStatus:       string(status),
+					Category:       categories[string(status)],
+					RequiredFields: []string{},
+				})
+			}
+		}
+		statuses, err := s.repo.ListStatuses(actor.TenantID, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list statuses: %w", err)
+		}
+		for _, status := range statuses {
+			if status.Name != string(ticket.Status) {
+				options = append(options, tenant_models.TicketTransitionOption{
+					ToStatus:       status.Name,
+					Category:       status.Category,
+					RequiredFields: []string{},
+				})
+			}
+		}
+		return options, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, transition := range workflow.Transitions {
+		if seen[transition.ToStatus] || transition.ToStatus == string(ticket.Status) {
+			continue
+		}
+		if !transition.Allows(string(ticket.Status), transition.ToStatus) || !canTakeTransition(actor, &transition) {
+			continue
+		}
+		category, known := categories[transition.ToStatus]
+		if !known {
+			// Deactivated custom statuses stay in the definition but cannot be entered
+			continue
+		}
+
+		seen[transition.ToStatus] = true
+		options = append(options, tenant_models.TicketTransitionOption{
+			Name:           transition.Name,
+			ToStatus:       transition.ToStatus,
+			Category:       category,
+			RequiredFields: append([]string{}, transition.RequiredFields...),
+		})
+	}
+	return options, nil
+}
+
+// Business logic helper methods
+
+// resolveWorkflow picks the most specific active workflow for the ticket, or nil if none applies
+func (s *workflowService) resolveWorkflow(tenantID string, ticket *tenant_models.Ticket) (*tenant_models.Workflow, error) {
+	workflows, err := s.repo.ListWorkflows(tenantID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	var best *tenant_models.Workflow
+	bestScore := -1
+	for _, workflow := range workflows {
+		score := 0
+		if workflow.ProjectID != nil {
+			if *workflow.ProjectID != stringValue(ticket.ProjectID) {
+				continue
+			}
+			score += 2
+		}
+		if workflow.TicketType != nil {
+			if *workflow.TicketType != string(ticket.TicketType) {
+				continue
+			}
+			score++
+		}
+		// Workflows are listed oldest first, so the oldest wins a tie
+		if score > bestScore {
+			best, bestScore = workflow, score
+		}
+	}
+	return best, nil
+}
+
+// statusCategories maps every enterable status name to its category
+func (s *workflowService) statusCategories(tenantID string) (map[string]tenant_models.StatusCategory, error) {
+	statuses, err := s.repo.ListStatuses(tenantID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statuses: %w", err)
+	}
+
+	categories := make(map[string]tenant_models.StatusCategory, len(builtinStatusCategories)+len(statuses))
+	for status, category := range builtinStatusCategories {
+		categories[string(status)] = category
+	}
+	for _, status := range statuses {
+		categories[status.Name] = status.Category
+	}
+	return categories, nil
+}
+
+// checkStatusExists accepts built-in statuses and active custom statuses
+func (s *workflowService) checkStatusExists(tenantID string, status tenant_models.TicketStatus) error {
+	if _, ok := builtinStatusCategories[status]; ok {
+		return nil
+	}
+
+	custom, err := s.repo.GetStatusByName(tenantID, string(status))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return validationError("unknown status %q", status)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+	if !custom.IsActive {
+		return validationError("status %q is no longer in use", status)
+	}
+	return nil
+}
+
+// validateWorkflow checks statuses, roles and required fields and that no other
+// active workflow already covers the same project and ticket type
+func (s *workflowService) validateWorkflow(tenantID string, workflow *tenant_models.Workflow) error {
+	if workflow.InitialStatus != "" {
+		if err := s.checkStatusExists(tenantID, tenant_models.TicketStatus(workflow.InitialStatus)); err != nil {
+			return err
+		}
+	}
+
+	for _, transition := range workflow.Transitions {
+		if transition.FromStatus != "" {
+			if err := s.checkStatusExists(tenantID, tenant_models.TicketStatus(transition.FromStatus)); err != nil {
+				return err
+			}
+		}
+		if err := s.checkStatusExists(tenantID, tenant_models.TicketStatus(transition.ToStatus)); err != nil {
+			return err
+		}
+		if transition.FromStatus == transition.ToStatus {
+			return validationError("transition to %q must start from a different status", transition.ToStatus)
+		}
+		for _, role := range transition.AllowedRoles {
+			if !validMembershipRoles[models.MembershipRole(role)] {
+				return validationError("invalid role %q", role)
+			}
+		}
+		for _, field := range transition.RequiredFields {
+			if !isKnownTicketField(field) {
+				return validationError("unknown required field %q", field)
+			}
+		}
+	}
+
+	if !workflow.IsActive {
+		return nil
+	}
+	workflows, err := s.repo.ListWorkflows(tenantID, true)
+	if err != nil {
+		return fmt.Errorf("failed to list workflows: %w", err)
+	}
+	for _, other := range workflows {
+		if other.ID != workflow.ID &&
+			stringValue(other.ProjectID) == stringValue(workflow.ProjectID) &&
+			stringValue(other.TicketType) == stringValue(workflow.TicketType) {
+			return validationError("workflow %q already applies to the same project and ticket type", other.Name)
+		}
+	}
+	return nil
+}
+
+func buildTransitions(requests []tenant_models.WorkflowTransitionRequest) []tenant_models.WorkflowTransition {
+	transitions := make([]tenant_models.WorkflowTransition, 0, len(requests))
+	for i, req := range requests {
+		transitions = append(transitions, tenant_models.WorkflowTransition{
+			Name:           req.Name,
+			FromStatus:     req.FromStatus,
+			ToStatus:       req.ToStatus,
+			RequiredFields: models.StringArray(req.RequiredFields),
+			AllowedRoles:   models.StringArray(req.AllowedRoles),
+			SortOrder:      i,
+		})
+	}
+	return transitions
+}
+
+func workflowUsesStatus(workflow *tenant_models.Workflow, status string) bool {
+	if workflow.InitialStatus == status {
+		return true
+	}
+	for _, transition := range workflow.Transitions {
+		if transition.FromStatus == status || transition.ToStatus == status {
+			return true
+		}
+	}
+	return false
+}
+
+// canTakeTransition checks the actor holds one of the transition's roles, if it lists any
+func canTakeTransition(actor Actor, transition *tenant_models.WorkflowTransition) bool {
+	if len(transition.AllowedRoles) == 0 {
+		return true
+	}
+	for _, role := range transition.AllowedRoles {
+		if actor.HasRole(models.MembershipRole(role)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isKnownTicketField(name string) bool {
+	if strings.HasPrefix(name, customFieldPrefix) {
+		return len(name) > len(customFieldPrefix)
+	}
+	for _, field := range auditedTicketFields {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// missingFields returns the required fields that are empty on the ticket
+func missingFields(ticket *tenant_models.Ticket, required []string) []string {
+	var missing []string
+	for _, name := range required {
+		if key := strings.TrimPrefix(name, customFieldPrefix); key != name {
+			if value, ok := ticket.CustomFields[key]; !ok || value == nil || value == "" {
+				missing = append(missing, name)
+			}
+			continue
+		}
+		for _, field := range auditedTicketFields {
+			if field.name == name && field.value(ticket) == "" {
+				missing = append(missing, name)
+			}
+		}
+	}
+	return missing
+}
+
+// optionalPointer treats an empty optional value like an absent one
+func optionalPointer(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	return optionalString(*value)
+}
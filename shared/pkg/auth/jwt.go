@@ -94,6 +94,13 @@ func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		// Portal tokens are for customers; never let one through as an agent, even if
+		// both services were configured with the same secret
+		for _, audience := range claims.Audience {
+			if audience == PortalAudience {
+				return nil, fmt.Errorf("invalid token")
+			}
+		}
 		return claims, nil
 	}
 
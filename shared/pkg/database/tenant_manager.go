@@ -94,15 +94,49 @@ func (tdm *TenantDatabaseManager) migrateTenantTables(db *gorm.DB) error {
 		&tenant_models.Ticket{},
 		&tenant_models.Comment{},
 		&tenant_models.Attachment{},
+		&tenant_models.TicketHistory{},
 		&tenant_models.Project{},
 		&tenant_models.ProjectMember{},
+		&tenant_models.SLAPolicy{},
+		&tenant_models.BusinessCalendar{},
+		&tenant_models.BusinessHoliday{},
+		&tenant_models.TicketSLA{},
+		&tenant_models.CustomStatus{},
+		&tenant_models.Workflow{},
+		&tenant_models.WorkflowTransition{},
+		&tenant_models.EmailMessage{},
+		&tenant_models.TicketLink{},
+		&tenant_models.BulkJob{},
+		&tenant_models.SavedView{},
+		&tenant_models.AutomationRule{},
+		&tenant_models.AutomationLog{},
+		&tenant_models.AgentGroup{},
+		&tenant_models.AgentGroupMember{},
+		&tenant_models.AgentAvailability{},
+		&tenant_models.Macro{},
+		&tenant_models.PortalLogin{},
+		&tenant_models.CSATSurvey{},
+		&tenant_models.CSATSettings{},
+		&tenant_models.ImportJob{},
+		&tenant_models.ImportRecord{},
+		&tenant_models.ScheduledExport{},
+		&tenant_models.ExportFile{},
+		&tenant_models.CustomFieldDefinition{},
+		&tenant_models.Board{},
+		&tenant_models.BoardColumn{},
+		&tenant_models.Sprint{},
+		&tenant_models.Worklog{},
+		&tenant_models.WorkTimer{},
+		&tenant_models.Milestone{},
+		&tenant_models.ProjectTemplate{},
 		// Add more tenant-specific models as needed
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate tables: %w", err)
 	}
 
-	return nil
+	// Apply data migrations that auto-migration cannot express
+	return runTenantMigrations(db)
 }
 
 // GetTenantConfig returns the cached tenant configuration
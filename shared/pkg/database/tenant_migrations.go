@@ -0,0 +1,205 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TenantSchemaMigration records a data migration applied to a tenant database
+type TenantSchemaMigration struct {
+	Version   string    `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name used by TenantSchemaMigration to `tenant_schema_migrations`
+func (TenantSchemaMigration) TableName() string {
+	return "tenant_schema_migrations"
+}
+
+// tenantMigration is a one-off change that auto-migration cannot express, such as
+// moving data between tables. Migrations run in order, once per tenant database,
+// each inside its own transaction.
+type tenantMigration struct {
+	Version string
+	Up      func(tx *gorm.DB) error
+}
+
+var tenantMigrations = []tenantMigration{
+	{Version: "20250901000000_unify_ticket_model", Up: migrateLegacyTickets},
+	{Version: "20250915000000_ticket_search_vector", Up: migrateTicketSearch},
+	{Version: "20251001000000_ticket_custom_fields_index", Up: migrateCustomFieldIndex},
+	{Version: "20251015000000_ticket_board_rank", Up: migrateBoardRanks},
+}
+
+// runTenantMigrations applies pending data migrations after the schema is auto-migrated
+func runTenantMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&TenantSchemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	var applied []string
+	if err := db.Model(&TenantSchemaMigration{}).Pluck("version", &applied).Error; err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	done := make(map[string]bool, len(applied))
+	for _, version := range applied {
+		done[version] = true
+	}
+
+	for _, migration := range tenantMigrations {
+		if done[migration.Version] {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&TenantSchemaMigration{Version: migration.Version, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", migration.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// firstColumn returns the first of the candidate columns present on table, or NULL
+func firstColumn(tx *gorm.DB, table string, candidates ...string) string {
+	for _, column := range candidates {
+		if tx.Migrator().HasColumn(table, column) {
+			return column
+		}
+	}
+	return "NULL"
+}
+
+// migrateLegacyTickets moves data written by the old ticket-service model onto the
+// tenant ticket model: type/tags/metadata columns on tickets, and the separate
+// ticket_comments and ticket_attachments tables.
+func migrateLegacyTickets(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+
+	if migrator.HasColumn("tickets", "type") {
+		steps := []string{
+			// Legacy constraints reject the tenant model's statuses and priorities
+			`ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_status_check`,
+			`ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_priority_check`,
+			`ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_type_check`,
+			`UPDATE tickets SET ticket_type = type WHERE type IS NOT NULL AND type <> ''`,
+		}
+		if migrator.HasColumn("tickets", "tags") {
+			steps = append(steps,
+				`UPDATE tickets SET labels = tags WHERE jsonb_typeof(tags) = 'array'`)
+		}
+		if migrator.HasColumn("tickets", "metadata") {
+			steps = append(steps,
+				`UPDATE tickets SET custom_fields = COALESCE(custom_fields, '{}'::jsonb) || metadata
+				 WHERE jsonb_typeof(metadata) = 'object'`,
+				`UPDATE tickets SET channel = metadata->>'channel'
+				 WHERE (channel IS NULL OR channel = '') AND metadata->>'channel' IS NOT NULL`)
+		}
+		steps = append(steps,
+			`ALTER TABLE tickets DROP COLUMN IF EXISTS tenant_id`,
+			`ALTER TABLE tickets DROP COLUMN IF EXISTS type`,
+			`ALTER TABLE tickets DROP COLUMN IF EXISTS tags`,
+			`ALTER TABLE tickets DROP COLUMN IF EXISTS metadata`,
+		)
+		for _, step := range steps {
+			if err := tx.Exec(step).Error; err != nil {
+				return fmt.Errorf("failed to migrate tickets: %w", err)
+			}
+		}
+	}
+
+	if err := renumberTickets(tx); err != nil {
+		return err
+	}
+
+	if migrator.HasTable("ticket_comments") {
+		deletedAt := firstColumn(tx, "ticket_comments", "deleted_at")
+		err := copyLegacyRows(tx, "ticket_comments", fmt.Sprintf(`
+			INSERT INTO comments (id, ticket_id, body, comment_type, author_id, is_internal, created_at, updated_at, deleted_at)
+			SELECT id, ticket_id, content,
+			       CASE WHEN is_internal THEN 'internal_note' ELSE 'comment' END,
+			       author_id, is_internal, created_at, updated_at, %s
+			FROM ticket_comments
+			ON CONFLICT (id) DO NOTHING`, deletedAt))
+		if err != nil {
+			return fmt.Errorf("failed to migrate ticket comments: %w", err)
+		}
+	}
+
+	if migrator.HasTable("ticket_attachments") {
+		table := "ticket_attachments"
+		filename := firstColumn(tx, table, "filename", "file_name")
+		err := copyLegacyRows(tx, table, fmt.Sprintf(`
+			INSERT INTO attachments (id, ticket_id, comment_id, filename, original_filename, file_size, mime_type, file_path, uploaded_by, uploaded_at, deleted_at)
+			SELECT id, ticket_id, %s, %s, COALESCE(%s, %s), COALESCE(file_size, 0),
+			       COALESCE(content_type, 'application/octet-stream'), %s, %s, created_at, %s
+			FROM ticket_attachments
+			ON CONFLICT (id) DO NOTHING`,
+			firstColumn(tx, table, "comment_id"),
+			filename,
+			firstColumn(tx, table, "original_filename", "file_name"), filename,
+			firstColumn(tx, table, "storage_path", "file_path"),
+			firstColumn(tx, table, "uploader_id", "uploaded_by"),
+			firstColumn(tx, table, "deleted_at"),
+		))
+		if err != nil {
+			return fmt.Errorf("failed to migrate ticket attachments: %w", err)
+		}
+	}
+
+	// The legacy tables are no longer read once all their rows are copied
+	for _, table := range []string{"ticket_attachments", "ticket_comments"} {
+		if err := migrator.DropTable(table); err != nil {
+			return fmt.Errorf("failed to drop %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// copyLegacyRows runs insert, which copies the rows of a legacy table that is dropped
+// afterwards. Rows skipped because their id is already taken would be lost with the
+// table, so any skipped row fails the migration until they are resolved by hand.
+func copyLegacyRows(tx *gorm.DB, table, insert string) error {
+	var total int64
+	if err := tx.Table(table).Count(&total).Error; err != nil {
+		return err
+	}
+
+	result := tx.Exec(insert)
+	if result.Error != nil {
+		return result.Error
+	}
+	if skipped := total - result.RowsAffected; skipped > 0 {
+		return fmt.Errorf("%d of %d %s rows have ids already in use and were not copied; %s is kept until they are resolved", skipped, total, table, table)
+	}
+	return nil
+}
+
+// renumberTickets assigns ticket numbers in creation order. Adding the serial column
+// to a populated table numbers existing rows in storage order instead.
+func renumberTickets(tx *gorm.DB) error {
+	steps := []string{
+		// Negate first so the unique index never sees two rows with the same number
+		`UPDATE tickets SET ticket_number = -ticket_number`,
+		`UPDATE tickets t SET ticket_number = n.rn
+		 FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, id) AS rn FROM tickets) n
+		 WHERE t.id = n.id`,
+		`SELECT setval(s.seq::regclass, COALESCE((SELECT MAX(ticket_number) FROM tickets), 0) + 1, false)
+		 FROM (SELECT pg_get_serial_sequence('tickets', 'ticket_number') AS seq) s
+		 WHERE s.seq IS NOT NULL`,
+	}
+	for _, step := range steps {
+		if err := tx.Exec(step).Error; err != nil {
+			return fmt.Errorf("failed to renumber tickets: %w", err)
+		}
+	}
+	return nil
+}
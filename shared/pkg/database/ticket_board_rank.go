@@ -0,0 +1,54 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/zen/shared/pkg/rank"
+)
+
+// boardRankBatch is how many tickets one UPDATE ranks
+const boardRankBatch = 1000
+
+// migrateBoardRanks ranks the tickets of every project that predate boards, oldest
+// first, so cards have a stable order before anyone drags one
+func migrateBoardRanks(tx *gorm.DB) error {
+	var projectIDs []string
+	err := tx.Raw(`SELECT DISTINCT project_id FROM tickets WHERE project_id IS NOT NULL AND coalesce(board_rank, '') = ''`).
+		Scan(&projectIDs).Error
+	if err != nil {
+		return fmt.Errorf("failed to find projects to rank: %w", err)
+	}
+
+	for _, projectID := range projectIDs {
+		var ticketIDs []string
+		err := tx.Raw(`SELECT id FROM tickets WHERE project_id = ? ORDER BY ticket_number`, projectID).
+			Scan(&ticketIDs).Error
+		if err != nil {
+			return fmt.Errorf("failed to list tickets of project %s: %w", projectID, err)
+		}
+
+		ranks := rank.Spread(len(ticketIDs))
+		for start := 0; start < len(ticketIDs); start += boardRankBatch {
+			end := start + boardRankBatch
+			if end > len(ticketIDs) {
+				end = len(ticketIDs)
+			}
+
+			rows := make([]string, 0, end-start)
+			args := make([]interface{}, 0, 2*(end-start))
+			for i := start; i < end; i++ {
+				rows = append(rows, "(?::uuid, ?)")
+				args = append(args, ticketIDs[i], ranks[i])
+			}
+			query := `UPDATE tickets SET board_rank = v.board_rank FROM (VALUES ` + strings.Join(rows, ", ") +
+				`) AS v(id, board_rank) WHERE tickets.id = v.id`
+			if err := tx.Exec(query, args...).Error; err != nil {
+				return fmt.Errorf("failed to rank tickets of project %s: %w", projectID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// migrateCustomFieldIndex indexes tickets.custom_fields for containment (@>) queries,
+// which is how equality filters on custom fields are written. Sorting and ranges on a
+// single field use expression indexes created with the field's definition.
+func migrateCustomFieldIndex(tx *gorm.DB) error {
+	err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_tickets_custom_fields ON tickets USING GIN (custom_fields jsonb_path_ops)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to index custom fields: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// TicketSearchConfig is the Postgres text search configuration used to index and query tickets
+const TicketSearchConfig = "english"
+
+// migrateTicketSearch adds tickets.search_vector, a weighted tsvector over the title (A),
+// description (B) and public comments (C), kept current by triggers on both tables.
+func migrateTicketSearch(tx *gorm.DB) error {
+	steps := []string{
+		`ALTER TABLE tickets ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION ticket_search_document(ticket uuid, title text, description text)
+		RETURNS tsvector LANGUAGE sql STABLE AS $$
+			SELECT setweight(to_tsvector('%[1]s', coalesce(title, '')), 'A') ||
+			       setweight(to_tsvector('%[1]s', coalesce(description, '')), 'B') ||
+			       setweight(to_tsvector('%[1]s', coalesce((
+			           SELECT string_agg(c.body, ' ')
+			           FROM comments c
+			           WHERE c.ticket_id = ticket AND NOT c.is_internal AND c.deleted_at IS NULL
+			       ), '')), 'C')
+		$$`, TicketSearchConfig),
+
+		`CREATE OR REPLACE FUNCTION tickets_search_vector_refresh() RETURNS trigger LANGUAGE plpgsql AS $$
+		BEGIN
+			NEW.search_vector := ticket_search_document(NEW.id, NEW.title, NEW.description);
+			RETURN NEW;
+		END
+		$$`,
+		`DROP TRIGGER IF EXISTS tickets_search_vector ON tickets`,
+		`CREATE TRIGGER tickets_search_vector BEFORE INSERT OR UPDATE OF title, description ON tickets
+		 FOR EACH ROW EXECUTE FUNCTION tickets_search_vector_refresh()`,
+
+		// Soft deletes and internal/public flips are updates, so one trigger covers every comment change
+		`CREATE OR REPLACE FUNCTION comments_search_vector_refresh() RETURNS trigger LANGUAGE plpgsql AS $$
+		DECLARE
+			changed uuid;
+		BEGIN
+			IF TG_OP = 'DELETE' THEN
+				changed := OLD.ticket_id;
+			ELSE
+				changed := NEW.ticket_id;
+			END IF;
+			UPDATE tickets SET search_vector = ticket_search_document(id, title, description) WHERE id = changed;
+			RETURN NULL;
+		END
+		$$`,
+		`DROP TRIGGER IF EXISTS comments_search_vector ON comments`,
+		`CREATE TRIGGER comments_search_vector AFTER INSERT OR UPDATE OR DELETE ON comments
+		 FOR EACH ROW EXECUTE FUNCTION comments_search_vector_refresh()`,
+
+		`UPDATE tickets SET search_vector = ticket_search_document(id, title, description)`,
+		`CREATE INDEX IF NOT EXISTS idx_tickets_search_vector ON tickets USING GIN (search_vector)`,
+	}
+
+	for _, step := range steps {
+		if err := tx.Exec(step).Error; err != nil {
+			return fmt.Errorf("failed to set up ticket search: %w", err)
+		}
+	}
+	return nil
+}
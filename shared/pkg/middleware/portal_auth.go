@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zen/shared/pkg/auth"
+	"github.com/zen/shared/pkg/utils"
+)
+
+// PortalAuthMiddleware authenticates customers of the support portal. It accepts portal
+// tokens only and sets no user_id, so agent-only middleware rejects portal sessions.
+func PortalAuthMiddleware(jwtService *auth.PortalJWTService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			utils.UnauthorizedResponse(c, "Authorization header is required")
+			c.Abort()
+			return
+		}
+
+		const bearerPrefix = "Bearer "
+		if !strings.HasPrefix(authHeader, bearerPrefix) {
+			utils.UnauthorizedResponse(c, "Authorization header must be Bearer token")
+			c.Abort()
+			return
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, bearerPrefix)
+		if tokenString == "" {
+			utils.UnauthorizedResponse(c, "Token is required")
+			c.Abort()
+			return
+		}
+
+		claims, err := jwtService.ValidateToken(tokenString)
+		if err != nil {
+			utils.UnauthorizedResponse(c, "Invalid or expired token")
+			c.Abort()
+			return
+		}
+
+		// Set claims in context for downstream handlers
+		c.Set("portal_tenant_id", claims.TenantID)
+		c.Set("portal_email", claims.Email)
+		c.Set("portal_jwt_claims", claims)
+
+		c.Next()
+	}
+}
+
+// GetPortalTenantID retrieves the portal customer's tenant ID from context
+func GetPortalTenantID(c *gin.Context) (string, bool) {
+	tenantID, exists := c.Get("portal_tenant_id")
+	if !exists {
+		return "", false
+	}
+	return tenantID.(string), true
+}
+
+// GetPortalEmail retrieves the portal customer's email address from context
+func GetPortalEmail(c *gin.Context) (string, bool) {
+	email, exists := c.Get("portal_email")
+	if !exists {
+		return "", false
+	}
+	return email.(string), true
+}
+
+// GetPortalJWTClaims retrieves full portal JWT claims from context
+func GetPortalJWTClaims(c *gin.Context) (*auth.PortalClaims, bool) {
+	claims, exists := c.Get("portal_jwt_claims")
+	if !exists {
+		return nil, false
+	}
+	return claims.(*auth.PortalClaims), true
+}
@@ -33,4 +33,43 @@ func (j *JSONB) Scan(value interface{}) error {
 	}
 	
 	return json.Unmarshal(bytes, j)
-}
\ No newline at end of file
+}
+
+// StringArray type for PostgreSQL JSONB fields holding a list of strings
+type StringArray []string
+
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	return json.Marshal(a)
+}
+
+func (a *StringArray) Scan(value interface{}) error {
+	if value == nil {
+		*a = StringArray{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("unsupported type")
+	}
+
+	return json.Unmarshal(bytes, a)
+}
+
+// Contains checks if the array holds the given value
+func (a StringArray) Contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// Package rank hands out sortable position keys in the spirit of Jira's LexoRank.
+//
+// A rank is a string of base-36 digits compared byte by byte. Moving an item means
+// taking a rank between its new neighbours, so reordering rewrites a single row
+// instead of renumbering the list. Ranks never end in '0', which guarantees there is
+// always room for another rank between any two.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	base   = 36
+
+	// width is the length of the ranks Initial, After, Before and Spread give out;
+	// step spaces appended ranks so long runs of appends stay at that length
+	width = 8
+	step  = 36 * 36 * 36 * 36
+	space = step * step // 36^width
+)
+
+// ErrOutOfOrder is returned when the rank meant to come first does not sort first
+var ErrOutOfOrder = errors.New("ranks are out of order")
+
+// Initial is the rank of the first item in an empty list, in the middle of the space
+func Initial() string {
+	return encode(space / 2)
+}
+
+// After returns a rank sorting after prev, the rank of the current last item
+func After(prev string) string {
+	if prev == "" {
+		return Initial()
+	}
+	next := (head(prev)/step + 1) * step
+	if next >= space {
+		return midpoint(prev, "")
+	}
+	return encode(next)
+}
+
+// Before returns a rank sorting before next, the rank of the current first item
+func Before(next string) string {
+	if next == "" {
+		return Initial()
+	}
+	v := head(next)
+	prev := ((v+step-1)/step - 1) * step
+	if prev < step {
+		return midpoint("", next)
+	}
+	return encode(prev)
+}
+
+// Between returns a rank sorting between prev and next; either may be empty for an
+// open end of the list
+func Between(prev, next string) (string, error) {
+	if !Valid(prev) || !Valid(next) {
+		return "", errors.New("invalid rank")
+	}
+	switch {
+	case prev == "":
+		return Before(next), nil
+	case next == "":
+		return After(prev), nil
+	case prev >= next:
+		return "", ErrOutOfOrder
+	}
+	return midpoint(prev, next), nil
+}
+
+// Spread returns n ranks evenly spaced across the space, in order, for ranking a whole
+// list at once
+func Spread(n int) []string {
+	ranks := make([]string, n)
+	gap := int64(space) / int64(n+1)
+	for i := range ranks {
+		ranks[i] = encode(gap * int64(i+1))
+	}
+	return ranks
+}
+
+// Valid reports whether r is empty or a well-formed rank
+func Valid(r string) bool {
+	for i := 0; i < len(r); i++ {
+		if strings.IndexByte(digits, r[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(r, "0")
+}
+
+// encode writes v as width digits, dropping trailing zeros
+func encode(v int64) string {
+	buf := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		buf[i] = digits[v%base]
+		v /= base
+	}
+	return strings.TrimRight(string(buf), "0")
+}
+
+// head reads the first width digits of r as a number, padding short ranks with zeros
+func head(r string) int64 {
+	var v int64
+	for i := 0; i < width; i++ {
+		v *= base
+		if i < len(r) {
+			v += int64(strings.IndexByte(digits, r[i]))
+		}
+	}
+	return v
+}
+
+// midpoint returns a rank strictly between a and b, where a < b and an empty b stands
+// for the end of the space
+func midpoint(a, b string) string {
+	if b != "" {
+		// Keep the prefix the two share
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(suffix(a, n), b[n:])
+		}
+	}
+
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	db := base
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+	if db-da > 1 {
+		return string(digits[(da+db)/2])
+	}
+	// The first digits are adjacent: b's first digit alone sorts between them when b
+	// goes on, otherwise keep a's digit and find room after it
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[da]) + midpoint(suffix(a, 1), "")
+}
+
+func digitAt(r string, i int) byte {
+	if i < len(r) {
+		return r[i]
+	}
+	return '0'
+}
+
+func suffix(r string, n int) string {
+	if n < len(r) {
+		return r[n:]
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+package rank
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		prev string
+		want string
+	}{
+		{"", "i"},
+		{"1", "1001"},
+		{"11", "1101"},
+		{"z", "z001"},
+		{"zz", "zz01"},
+		{"zzzzzzzz", "zzzzzzzzi"}, // No step left at the end of the space
+	}
+	for _, tt := range tests {
+		got := After(tt.prev)
+		if got != tt.want {
+			t.Errorf("After(%q) = %q, want %q", tt.prev, got, tt.want)
+		}
+		if tt.prev != "" && got <= tt.prev {
+			t.Errorf("After(%q) = %q does not sort after it", tt.prev, got)
+		}
+		if !Valid(got) {
+			t.Errorf("After(%q) = %q is not a valid rank", tt.prev, got)
+		}
+	}
+}
+
+func TestBefore(t *testing.T) {
+	tests := []struct {
+		next string
+		want string
+	}{
+		{"", "i"},
+		{"1", "0zzz"},
+		{"11", "10zz"},
+		{"z", "yzzz"},
+		{"0001", "0000i"},
+		{"00000001", "00000000i"}, // No step left at the start of the space
+	}
+	for _, tt := range tests {
+		got := Before(tt.next)
+		if got != tt.want {
+			t.Errorf("Before(%q) = %q, want %q", tt.next, got, tt.want)
+		}
+		if tt.next != "" && got >= tt.next {
+			t.Errorf("Before(%q) = %q does not sort before it", tt.next, got)
+		}
+		if !Valid(got) {
+			t.Errorf("Before(%q) = %q is not a valid rank", tt.next, got)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		prev, next string
+		want       string
+		err        error
+	}{
+		{"", "", "i", nil},
+		{"", "1", "0zzz", nil},
+		{"z", "", "z001", nil},
+		{"1", "2", "1i", nil},
+		{"1", "11", "10i", nil},
+		{"1", "101", "100i", nil},
+		{"y", "z", "yi", nil},
+		{"", "01", "00zz", nil},
+		{"1", "1", "", ErrOutOfOrder},
+		{"11", "1", "", ErrOutOfOrder},
+		{"z", "y", "", ErrOutOfOrder},
+	}
+	for _, tt := range tests {
+		got, err := Between(tt.prev, tt.next)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Between(%q, %q) error = %v, want %v", tt.prev, tt.next, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Between(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestBetweenInvalid(t *testing.T) {
+	for _, pair := range [][2]string{{"10", ""}, {"", "A"}, {"1-", "2"}} {
+		if _, err := Between(pair[0], pair[1]); err == nil || errors.Is(err, ErrOutOfOrder) {
+			t.Errorf("Between(%q, %q) error = %v, want an invalid rank error", pair[0], pair[1], err)
+		}
+	}
+}
+
+func TestSpread(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{0, []string{}},
+		{1, []string{"i"}},
+		{3, []string{"9", "i", "r"}},
+	}
+	for _, tt := range tests {
+		got := Spread(tt.n)
+		if len(got) != len(tt.want) {
+			t.Errorf("Spread(%d) = %q, want %q", tt.n, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Spread(%d) = %q, want %q", tt.n, got, tt.want)
+				break
+			}
+		}
+	}
+
+	large := Spread(10000)
+	for i, r := range large {
+		if !Valid(r) || r == "" {
+			t.Fatalf("Spread(10000)[%d] = %q is not a valid rank", i, r)
+		}
+		if i > 0 && r <= large[i-1] {
+			t.Fatalf("Spread(10000)[%d] = %q does not sort after %q", i, r, large[i-1])
+		}
+	}
+}
+
+// TestRandomOrdering inserts items at random places, always next to the same end, or
+// always between the same two neighbours, and checks the list stays strictly ordered
+func TestRandomOrdering(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	strategies := map[string]func(n int) int{
+		"random": func(n int) int { return rng.Intn(n + 1) },
+		"front":  func(n int) int { return 0 },
+		"back":   func(n int) int { return n },
+		"second": func(n int) int { return min(1, n) },
+	}
+
+	for name, position := range strategies {
+		var list []string
+		for i := 0; i < 2000; i++ {
+			at := position(len(list))
+			var prev, next string
+			if at > 0 {
+				prev = list[at-1]
+			}
+			if at < len(list) {
+				next = list[at]
+			}
+
+			r, err := Between(prev, next)
+			if err != nil {
+				t.Fatalf("%s: Between(%q, %q): %v", name, prev, next, err)
+			}
+			if !Valid(r) || r == "" {
+				t.Fatalf("%s: Between(%q, %q) = %q is not a valid rank", name, prev, next, r)
+			}
+			if (prev != "" && r <= prev) || (next != "" && r >= next) {
+				t.Fatalf("%s: Between(%q, %q) = %q is out of order", name, prev, next, r)
+			}
+
+			list = append(list, "")
+			copy(list[at+1:], list[at:])
+			list[at] = r
+		}
+
+		if !sort.StringsAreSorted(list) {
+			t.Fatalf("%s: ranks are not sorted", name)
+		}
+		for i := 1; i < len(list); i++ {
+			if list[i] == list[i-1] {
+				t.Fatalf("%s: rank %q appears twice", name, list[i])
+			}
+		}
+	}
+}
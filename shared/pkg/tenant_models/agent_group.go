@@ -0,0 +1,182 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+	"gorm.io/gorm"
+)
+
+type AssignmentStrategy string
+
+const (
+	AssignManual     AssignmentStrategy = "manual"      // Tickets wait in the group's queue
+	AssignRoundRobin AssignmentStrategy = "round_robin" // Members take turns
+	AssignLeastOpen  AssignmentStrategy = "least_open"  // The member with the fewest open tickets
+	AssignSkills     AssignmentStrategy = "skills"      // The best skills and language match, then the fewest open tickets
+)
+
+type AgentStatus string
+
+const (
+	AgentOnline  AgentStatus = "online"
+	AgentAway    AgentStatus = "away"    // Keeps their tickets but receives no new ones
+	AgentOffline AgentStatus = "offline" // Their open group tickets are handed to other members
+)
+
+// AgentGroup is a team that new tickets are routed to. Empty match fields act as wildcards;
+// groups are evaluated in SortOrder and the first match wins.
+type AgentGroup struct {
+	ID          string             `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string             `json:"name" gorm:"not null;size:255"`
+	Description string             `json:"description" gorm:"type:text"`
+	Strategy    AssignmentStrategy `json:"strategy" gorm:"type:varchar(20);not null;default:'round_robin'"`
+
+	// Matching
+	ProjectID   *string            `json:"project_id" gorm:"type:uuid;index"`
+	TicketTypes models.StringArray `json:"ticket_types" gorm:"type:jsonb;default:'[]'"`
+	Priorities  models.StringArray `json:"priorities" gorm:"type:jsonb;default:'[]'"`
+	Channels    models.StringArray `json:"channels" gorm:"type:jsonb;default:'[]'"`
+	Labels      models.StringArray `json:"labels" gorm:"type:jsonb;default:'[]'"` // Any one of them
+
+	SortOrder int  `json:"sort_order" gorm:"default:0"`
+	IsActive  bool `json:"is_active" gorm:"default:true"`
+
+	Members []AgentGroupMember `json:"members,omitempty" gorm:"foreignKey:GroupID"`
+
+	// Timestamps
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// AgentGroupMember is an agent in a group along with what they can handle
+type AgentGroupMember struct {
+	ID      string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	GroupID string `json:"group_id" gorm:"type:uuid;not null;uniqueIndex:idx_agent_group_members_group_user"`
+	UserID  string `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_agent_group_members_group_user;index"` // FK to master.users.id
+
+	// Skills are matched against the ticket type and labels, languages against the ticket language
+	Skills    models.StringArray `json:"skills" gorm:"type:jsonb;default:'[]'"`
+	Languages models.StringArray `json:"languages" gorm:"type:jsonb;default:'[]'"`
+
+	LastAssignedAt *time.Time `json:"last_assigned_at"` // Drives round-robin turns
+	AddedAt        time.Time  `json:"added_at" gorm:"default:now()"`
+}
+
+// AgentAvailability is an agent's routing state. Agents without a record count as online
+// with no capacity limit.
+type AgentAvailability struct {
+	UserID         string      `json:"user_id" gorm:"primaryKey;type:uuid"` // FK to master.users.id
+	Status         AgentStatus `json:"status" gorm:"type:varchar(20);not null;default:'online'"`
+	MaxOpenTickets int         `json:"max_open_tickets" gorm:"default:0"` // 0 means no limit
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AgentGroupCreateRequest struct {
+	Name        string             `json:"name" binding:"required,min=1,max=255"`
+	Description string             `json:"description,omitempty"`
+	Strategy    AssignmentStrategy `json:"strategy,omitempty" binding:"omitempty,oneof=manual round_robin least_open skills"`
+	ProjectID   *string            `json:"project_id,omitempty" binding:"omitempty,uuid"`
+	TicketTypes []string           `json:"ticket_types,omitempty"`
+	Priorities  []string           `json:"priorities,omitempty"`
+	Channels    []string           `json:"channels,omitempty"`
+	Labels      []string           `json:"labels,omitempty"`
+	SortOrder   int                `json:"sort_order,omitempty"`
+}
+
+type AgentGroupUpdateRequest struct {
+	Name        *string             `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string             `json:"description,omitempty"`
+	Strategy    *AssignmentStrategy `json:"strategy,omitempty" binding:"omitempty,oneof=manual round_robin least_open skills"`
+	ProjectID   *string             `json:"project_id,omitempty" binding:"omitempty,uuid"` // Empty string clears it
+	TicketTypes *[]string           `json:"ticket_types,omitempty"`
+	Priorities  *[]string           `json:"priorities,omitempty"`
+	Channels    *[]string           `json:"channels,omitempty"`
+	Labels      *[]string           `json:"labels,omitempty"`
+	SortOrder   *int                `json:"sort_order,omitempty"`
+	IsActive    *bool               `json:"is_active,omitempty"`
+}
+
+type AgentGroupMemberRequest struct {
+	UserID    string   `json:"user_id" binding:"required,uuid"`
+	Skills    []string `json:"skills,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+}
+
+type AgentGroupMemberUpdateRequest struct {
+	Skills    *[]string `json:"skills,omitempty"`
+	Languages *[]string `json:"languages,omitempty"`
+}
+
+type AgentAvailabilityRequest struct {
+	Status         AgentStatus `json:"status" binding:"required,oneof=online away offline"`
+	MaxOpenTickets *int        `json:"max_open_tickets,omitempty" binding:"omitempty,min=0"`
+}
+
+type AgentGroupResponse struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Strategy    AssignmentStrategy `json:"strategy"`
+	ProjectID   *string            `json:"project_id"`
+	TicketTypes models.StringArray `json:"ticket_types"`
+	Priorities  models.StringArray `json:"priorities"`
+	Channels    models.StringArray `json:"channels"`
+	Labels      models.StringArray `json:"labels"`
+	SortOrder   int                `json:"sort_order"`
+	IsActive    bool               `json:"is_active"`
+	Members     []AgentGroupMember `json:"members"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// AgentAvailabilityResponse is an agent's availability along with their current load
+type AgentAvailabilityResponse struct {
+	UserID         string      `json:"user_id"`
+	Status         AgentStatus `json:"status"`
+	MaxOpenTickets int         `json:"max_open_tickets"`
+	OpenTickets    int64       `json:"open_tickets"`
+	Reassigned     int         `json:"reassigned,omitempty"` // Tickets handed over when going offline
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// TableName overrides the table name used by AgentGroup to `agent_groups`
+func (AgentGroup) TableName() string {
+	return "agent_groups"
+}
+
+// TableName overrides the table name used by AgentGroupMember to `agent_group_members`
+func (AgentGroupMember) TableName() string {
+	return "agent_group_members"
+}
+
+// TableName overrides the table name used by AgentAvailability to `agent_availability`
+func (AgentAvailability) TableName() string {
+	return "agent_availability"
+}
+
+// ToResponse converts an AgentGroup model to AgentGroupResponse
+func (g *AgentGroup) ToResponse() AgentGroupResponse {
+	response := AgentGroupResponse{
+		ID:          g.ID,
+		Name:        g.Name,
+		Description: g.Description,
+		Strategy:    g.Strategy,
+		ProjectID:   g.ProjectID,
+		TicketTypes: g.TicketTypes,
+		Priorities:  g.Priorities,
+		Channels:    g.Channels,
+		Labels:      g.Labels,
+		SortOrder:   g.SortOrder,
+		IsActive:    g.IsActive,
+		Members:     g.Members,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+	if response.Members == nil {
+		response.Members = []AgentGroupMember{}
+	}
+	return response
+}
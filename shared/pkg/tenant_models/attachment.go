@@ -1,6 +1,7 @@
 package tenant_models
 
 import (
+	"strings"
 	"time"
 	"gorm.io/gorm"
 )
@@ -17,7 +18,7 @@ type Attachment struct {
 	OriginalFilename string `json:"original_filename" gorm:"not null;size:255"` // User's original filename
 	FileSize         int64  `json:"file_size" gorm:"not null"`                  // Size in bytes
 	MimeType         string `json:"mime_type" gorm:"not null;size:100"`         // MIME type
-	FilePath         string `json:"file_path" gorm:"not null;size:500"`         // Storage path/URL
+	FilePath         string `json:"file_path" gorm:"not null;size:1000"`        // Storage path/URL
 	
 	// Uploader (References Master DB users.id)
 	UploadedBy string `json:"uploaded_by" gorm:"type:uuid;not null"`
@@ -68,6 +69,12 @@ func (a *Attachment) ToResponse() AttachmentResponse {
 	}
 }
 
+// IsReference reports whether the attachment only points at a file kept elsewhere, as
+// imported attachments do
+func (a *Attachment) IsReference() bool {
+	return strings.HasPrefix(a.FilePath, "https://") || strings.HasPrefix(a.FilePath, "http://")
+}
+
 // IsImage checks if the attachment is an image file
 func (a *Attachment) IsImage() bool {
 	imageTypes := []string{
@@ -0,0 +1,213 @@
+package tenant_models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AutomationTrigger string
+
+const (
+	TriggerTicketCreated AutomationTrigger = "ticket_created"
+	TriggerTicketUpdated AutomationTrigger = "ticket_updated"
+	TriggerCommentAdded  AutomationTrigger = "comment_added"
+
+	// Scheduled rules are evaluated periodically against open tickets and need a time
+	// condition such as hours_since_updated; they act on a ticket once, and again only
+	// after the ticket sees new activity
+	TriggerScheduled AutomationTrigger = "scheduled"
+)
+
+type ConditionMatch string
+
+const (
+	MatchAll ConditionMatch = "all"
+	MatchAny ConditionMatch = "any"
+)
+
+type ConditionOperator string
+
+const (
+	OperatorIs          ConditionOperator = "is"     // Equals any of the values
+	OperatorIsNot       ConditionOperator = "is_not" // Equals none of the values
+	OperatorContains    ConditionOperator = "contains"
+	OperatorNotContains ConditionOperator = "not_contains"
+	OperatorStartsWith  ConditionOperator = "starts_with"
+	OperatorEndsWith    ConditionOperator = "ends_with"
+	OperatorMatches     ConditionOperator = "matches" // Regular expression
+	OperatorIsEmpty     ConditionOperator = "is_empty"
+	OperatorIsNotEmpty  ConditionOperator = "is_not_empty"
+	OperatorGreaterThan ConditionOperator = "greater_than"
+	OperatorLessThan    ConditionOperator = "less_than"
+
+	// Only meaningful for ticket_updated
+	OperatorChanged     ConditionOperator = "changed"
+	OperatorChangedTo   ConditionOperator = "changed_to"
+	OperatorChangedFrom ConditionOperator = "changed_from"
+)
+
+type AutomationActionType string
+
+const (
+	ActionSetField       AutomationActionType = "set_field"        // Field, Value
+	ActionAddLabels      AutomationActionType = "add_labels"       // Values
+	ActionRemoveLabels   AutomationActionType = "remove_labels"    // Values
+	ActionSetCustomField AutomationActionType = "set_custom_field" // Field (the custom field key), Value
+	ActionAddComment     AutomationActionType = "add_comment"      // Body, Internal
+	ActionNotify         AutomationActionType = "notify"           // Recipients, Subject, Body
+	ActionWebhook        AutomationActionType = "webhook"          // URL, Headers, Secret
+)
+
+// AutomationCondition compares one ticket or comment field. Field is a ticket field such
+// as status, priority, labels or customer_email_domain, custom_fields.<key>, a comment
+// field (comment.body, comment.internal) on comment_added, or on scheduled rules a time
+// field measured in hours (hours_since_created, hours_since_updated, hours_until_due, ...).
+type AutomationCondition struct {
+	Field    string            `json:"field" binding:"required"`
+	Operator ConditionOperator `json:"operator" binding:"required"`
+	Values   []string          `json:"values,omitempty"`
+}
+
+// AutomationAction is one step a rule performs. Subject and Body may use placeholders
+// such as {{ticket.number}}, {{ticket.title}} or {{comment.body}}.
+type AutomationAction struct {
+	Type       AutomationActionType `json:"type" binding:"required"`
+	Field      string               `json:"field,omitempty"`
+	Value      string               `json:"value,omitempty"`
+	Values     []string             `json:"values,omitempty"`
+	Subject    string               `json:"subject,omitempty"`
+	Body       string               `json:"body,omitempty"`
+	Internal   bool                 `json:"internal,omitempty"`
+	Recipients []string             `json:"recipients,omitempty"` // assignee, reporter, customer or email addresses
+	URL        string               `json:"url,omitempty"`
+	Headers    map[string]string    `json:"headers,omitempty"`
+	Secret     string               `json:"secret,omitempty"` // Signs webhook payloads (X-Webhook-Signature)
+}
+
+// AutomationRule runs its actions when its trigger fires and its conditions hold.
+// Rules are evaluated in Position order against the ticket as earlier rules left it.
+type AutomationRule struct {
+	ID          string            `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string            `json:"name" gorm:"not null;size:255"`
+	Description string            `json:"description" gorm:"type:text"`
+	Trigger     AutomationTrigger `json:"trigger" gorm:"type:varchar(50);not null;index"`
+
+	Match      ConditionMatch        `json:"match" gorm:"type:varchar(10);not null;default:'all'"`
+	Conditions []AutomationCondition `json:"conditions" gorm:"type:jsonb;serializer:json"`
+	Actions    []AutomationAction    `json:"actions" gorm:"type:jsonb;serializer:json"`
+
+	Position  int    `json:"position" gorm:"default:0"`
+	IsActive  bool   `json:"is_active" gorm:"default:true"`
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null"` // References Master DB users.id
+
+	// Timestamps
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type AutomationLogStatus string
+
+const (
+	AutomationApplied AutomationLogStatus = "applied" // Every action succeeded
+	AutomationPartial AutomationLogStatus = "partial" // Some actions failed
+	AutomationFailed  AutomationLogStatus = "failed"
+	AutomationSkipped AutomationLogStatus = "skipped" // Matched, but stopped by loop protection
+)
+
+// AutomationLog records one firing of a rule on a ticket
+type AutomationLog struct {
+	ID       string              `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	RuleID   string              `json:"rule_id" gorm:"type:uuid;not null;index:idx_automation_logs_rule_ticket"`
+	TicketID string              `json:"ticket_id" gorm:"type:uuid;not null;index:idx_automation_logs_rule_ticket;index"`
+	Trigger  AutomationTrigger   `json:"trigger" gorm:"type:varchar(50);not null"`
+	Status   AutomationLogStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Depth    int                 `json:"depth"` // 0 when a person caused the event, higher for cascades
+	Results  []AutomationResult  `json:"results" gorm:"type:jsonb;serializer:json"`
+	Error    string              `json:"error" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// AutomationResult is the outcome of one action
+type AutomationResult struct {
+	Type    AutomationActionType `json:"type"`
+	Success bool                 `json:"success"`
+	Error   string               `json:"error,omitempty"`
+}
+
+type AutomationRuleCreateRequest struct {
+	Name        string                `json:"name" binding:"required,min=1,max=255"`
+	Description string                `json:"description,omitempty"`
+	Trigger     AutomationTrigger     `json:"trigger" binding:"required"`
+	Match       ConditionMatch        `json:"match,omitempty" binding:"omitempty,oneof=all any"`
+	Conditions  []AutomationCondition `json:"conditions,omitempty" binding:"dive"`
+	Actions     []AutomationAction    `json:"actions" binding:"required,min=1,dive"`
+	Position    int                   `json:"position,omitempty"`
+}
+
+type AutomationRuleUpdateRequest struct {
+	Name        *string                `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string                `json:"description,omitempty"`
+	Trigger     *AutomationTrigger     `json:"trigger,omitempty"`
+	Match       *ConditionMatch        `json:"match,omitempty" binding:"omitempty,oneof=all any"`
+	Conditions  *[]AutomationCondition `json:"conditions,omitempty" binding:"omitempty,dive"`
+	Actions     *[]AutomationAction    `json:"actions,omitempty" binding:"omitempty,min=1,dive"`
+	Position    *int                   `json:"position,omitempty"`
+	IsActive    *bool                  `json:"is_active,omitempty"`
+}
+
+type AutomationRuleResponse struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Trigger     AutomationTrigger     `json:"trigger"`
+	Match       ConditionMatch        `json:"match"`
+	Conditions  []AutomationCondition `json:"conditions"`
+	Actions     []AutomationAction    `json:"actions"`
+	Position    int                   `json:"position"`
+	IsActive    bool                  `json:"is_active"`
+	CreatedBy   string                `json:"created_by"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// TableName overrides the table name used by AutomationRule to `automation_rules`
+func (AutomationRule) TableName() string {
+	return "automation_rules"
+}
+
+// TableName overrides the table name used by AutomationLog to `automation_logs`
+func (AutomationLog) TableName() string {
+	return "automation_logs"
+}
+
+// ToResponse converts an AutomationRule model to AutomationRuleResponse. Webhook secrets
+// are write-only.
+func (r *AutomationRule) ToResponse() AutomationRuleResponse {
+	response := AutomationRuleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Trigger:     r.Trigger,
+		Match:       r.Match,
+		Conditions:  r.Conditions,
+		Actions:     make([]AutomationAction, len(r.Actions)),
+		Position:    r.Position,
+		IsActive:    r.IsActive,
+		CreatedBy:   r.CreatedBy,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+	if response.Conditions == nil {
+		response.Conditions = []AutomationCondition{}
+	}
+	for i, action := range r.Actions {
+		if action.Secret != "" {
+			action.Secret = "********"
+		}
+		response.Actions[i] = action
+	}
+	return response
+}
@@ -0,0 +1,155 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+)
+
+type SwimlaneGrouping string
+
+const (
+	SwimlaneNone     SwimlaneGrouping = "none"
+	SwimlaneAssignee SwimlaneGrouping = "assignee"
+	SwimlanePriority SwimlaneGrouping = "priority"
+	SwimlaneEpic     SwimlaneGrouping = "epic" // The ticket's parent
+)
+
+// Board is a kanban view of a project's tickets. Each column holds the tickets in its
+// statuses, ordered by Ticket.BoardRank; a status belongs to at most one column.
+type Board struct {
+	ID          string           `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ProjectID   string           `json:"project_id" gorm:"type:uuid;not null;index"`
+	Name        string           `json:"name" gorm:"not null;size:255"`
+	Description string           `json:"description" gorm:"type:text"`
+	Swimlanes   SwimlaneGrouping `json:"swimlanes" gorm:"type:varchar(20);default:'none'"`
+	IsDefault   bool             `json:"is_default" gorm:"default:false"` // The board a project opens on
+
+	Columns []BoardColumn `json:"columns" gorm:"foreignKey:BoardID;constraint:OnDelete:CASCADE"`
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BoardColumn struct {
+	ID       string             `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	BoardID  string             `json:"board_id" gorm:"type:uuid;not null;index"`
+	Name     string             `json:"name" gorm:"not null;size:100"`
+	Statuses models.StringArray `json:"statuses" gorm:"type:jsonb;default:'[]'"` // Built-in or custom status names
+	WIPLimit int                `json:"wip_limit" gorm:"default:0"`              // Most tickets moved into the column; 0 for no limit
+	Position int                `json:"position" gorm:"default:0"`
+}
+
+type BoardCreateRequest struct {
+	Name        string               `json:"name" binding:"required,min=1,max=255"`
+	Description string               `json:"description,omitempty"`
+	Swimlanes   SwimlaneGrouping     `json:"swimlanes,omitempty" binding:"omitempty,oneof=none assignee priority epic"`
+	IsDefault   bool                 `json:"is_default,omitempty"`
+	Columns     []BoardColumnRequest `json:"columns" binding:"required,min=1,max=20,dive"`
+}
+
+// BoardUpdateRequest replaces the columns when given; columns carrying an ID keep it
+type BoardUpdateRequest struct {
+	Name        *string               `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string               `json:"description,omitempty"`
+	Swimlanes   *SwimlaneGrouping     `json:"swimlanes,omitempty" binding:"omitempty,oneof=none assignee priority epic"`
+	IsDefault   *bool                 `json:"is_default,omitempty"`
+	Columns     *[]BoardColumnRequest `json:"columns,omitempty" binding:"omitempty,min=1,max=20,dive"`
+}
+
+type BoardColumnRequest struct {
+	ID       string   `json:"id,omitempty" binding:"omitempty,uuid"`
+	Name     string   `json:"name" binding:"required,min=1,max=100"`
+	Statuses []string `json:"statuses" binding:"required,min=1,dive,min=1,max=100"`
+	WIPLimit int      `json:"wip_limit,omitempty" binding:"omitempty,min=0"`
+}
+
+// BoardView is a whole board in one response: its columns with their load, and the
+// cards laid out by swimlane
+type BoardView struct {
+	Board     Board             `json:"board"`
+	Columns   []BoardColumnView `json:"columns"`
+	Swimlanes []BoardSwimlane   `json:"swimlanes"`
+}
+
+type BoardColumnView struct {
+	BoardColumn
+	TicketCount int64 `json:"ticket_count"`
+	OverLimit   bool  `json:"over_limit"` // More tickets than the WIP limit allows
+	Truncated   bool  `json:"truncated"`  // Not every card of the column was returned
+}
+
+// BoardSwimlane holds one lane's cards for every column, in column order. Key is the
+// assignee ID, priority or epic ticket ID the lane groups by; empty for cards without
+// one, and for the single lane of boards without swimlanes.
+type BoardSwimlane struct {
+	Key     string            `json:"key"`
+	Title   string            `json:"title,omitempty"`
+	Columns []BoardLaneColumn `json:"columns"`
+}
+
+type BoardLaneColumn struct {
+	ColumnID string      `json:"column_id"`
+	Cards    []BoardCard `json:"cards"`
+}
+
+type BoardCard struct {
+	ID             string             `json:"id"`
+	TicketNumber   int                `json:"ticket_number"`
+	Title          string             `json:"title"`
+	TicketType     TicketType         `json:"ticket_type"`
+	Priority       TicketPriority     `json:"priority"`
+	Status         TicketStatus       `json:"status"`
+	AssigneeID     *string            `json:"assignee_id"`
+	ParentTicketID *string            `json:"parent_ticket_id"`
+	Labels         models.StringArray `json:"labels"`
+	DueDate        *time.Time         `json:"due_date"`
+	EstimatedHours *float64           `json:"estimated_hours"`
+	StoryPoints    *float64           `json:"story_points"`
+	SprintID       *string            `json:"sprint_id"`
+	BoardRank      string             `json:"board_rank"`
+}
+
+// TableName overrides the table name used by Board to `boards`
+func (Board) TableName() string {
+	return "boards"
+}
+
+// TableName overrides the table name used by BoardColumn to `board_columns`
+func (BoardColumn) TableName() string {
+	return "board_columns"
+}
+
+// HasStatus reports whether the column holds tickets in the status
+func (c *BoardColumn) HasStatus(status TicketStatus) bool {
+	for _, s := range c.Statuses {
+		if s == string(status) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToCard converts a Ticket to the card shown on boards
+func (t *Ticket) ToCard() BoardCard {
+	return BoardCard{
+		ID:             t.ID,
+		TicketNumber:   t.TicketNumber,
+		Title:          t.Title,
+		TicketType:     t.TicketType,
+		Priority:       t.Priority,
+		Status:         t.Status,
+		AssigneeID:     t.AssigneeID,
+		ParentTicketID: t.ParentTicketID,
+		Labels:         t.Labels,
+		DueDate:        t.DueDate,
+		EstimatedHours: t.EstimatedHours,
+		StoryPoints:    t.StoryPoints,
+		SprintID:       t.SprintID,
+		BoardRank:      t.BoardRank,
+	}
+}
@@ -0,0 +1,138 @@
+package tenant_models
+
+import (
+	"time"
+)
+
+type BulkAction string
+
+const (
+	BulkActionUpdate BulkAction = "update"
+	BulkActionDelete BulkAction = "delete" // Soft delete
+)
+
+type BulkJobStatus string
+
+const (
+	BulkJobQueued    BulkJobStatus = "queued"
+	BulkJobRunning   BulkJobStatus = "running"
+	BulkJobCompleted BulkJobStatus = "completed" // Finished, possibly with per-ticket failures
+	BulkJobFailed    BulkJobStatus = "failed"    // Stopped early; see Error
+)
+
+// BulkJob tracks one bulk operation over many tickets and its per-ticket outcome
+type BulkJob struct {
+	ID     string        `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Action BulkAction    `json:"action" gorm:"type:varchar(20);not null"`
+	Status BulkJobStatus `json:"status" gorm:"type:varchar(20);not null;default:'queued'"`
+
+	// Progress
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	Error     string `json:"error" gorm:"type:text"`
+
+	Changes *TicketBulkChanges `json:"changes" gorm:"type:jsonb;serializer:json"`
+	Results []TicketBulkResult `json:"results" gorm:"type:jsonb;serializer:json"`
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null;index"`
+
+	// Timestamps
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// TicketBulkRequest selects tickets by ID or by filter and applies one action to each
+type TicketBulkRequest struct {
+	TicketIDs []string           `json:"ticket_ids,omitempty" binding:"omitempty,dive,uuid"`
+	Filter    *TicketBulkFilter  `json:"filter,omitempty"`
+	Action    BulkAction         `json:"action" binding:"required,oneof=update delete"`
+	Changes   *TicketBulkChanges `json:"changes,omitempty"`
+	Async     bool               `json:"async,omitempty"` // Large batches run in the background regardless
+}
+
+// TicketBulkFilter mirrors the ticket list filters
+type TicketBulkFilter struct {
+	Status          string     `json:"status,omitempty"`
+	Priority        string     `json:"priority,omitempty"`
+	Type            string     `json:"type,omitempty"`
+	AssigneeID      string     `json:"assignee_id,omitempty"`
+	ReporterID      string     `json:"reporter_id,omitempty"`
+	ProjectID       string     `json:"project_id,omitempty"`
+	Category        string     `json:"category,omitempty"`
+	DateFrom        *time.Time `json:"date_from,omitempty"`
+	DateTo          *time.Time `json:"date_to,omitempty"`
+	BreachingWithin *int       `json:"breaching_within,omitempty" binding:"omitempty,min=0"` // Minutes
+	SLABreached     bool       `json:"sla_breached,omitempty"`
+}
+
+// TicketBulkChanges are applied to every selected ticket; an empty AssigneeID or ProjectID clears it
+type TicketBulkChanges struct {
+	Status       *TicketStatus   `json:"status,omitempty"`
+	Priority     *TicketPriority `json:"priority,omitempty"`
+	AssigneeID   *string         `json:"assignee_id,omitempty"`
+	ProjectID    *string         `json:"project_id,omitempty"`
+	AddLabels    []string        `json:"add_labels,omitempty"`
+	RemoveLabels []string        `json:"remove_labels,omitempty"`
+}
+
+type TicketBulkResult struct {
+	TicketID string `json:"ticket_id"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+type BulkJobResponse struct {
+	ID          string             `json:"id"`
+	Action      BulkAction         `json:"action"`
+	Status      BulkJobStatus      `json:"status"`
+	Total       int                `json:"total"`
+	Processed   int                `json:"processed"`
+	Succeeded   int                `json:"succeeded"`
+	Failed      int                `json:"failed"`
+	Progress    float64            `json:"progress"` // Percentage processed
+	Error       string             `json:"error,omitempty"`
+	Results     []TicketBulkResult `json:"results"`
+	CreatedBy   string             `json:"created_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CompletedAt *time.Time         `json:"completed_at"`
+}
+
+// IsEmpty reports whether the changes would leave a ticket untouched
+func (c *TicketBulkChanges) IsEmpty() bool {
+	return c == nil || (c.Status == nil && c.Priority == nil && c.AssigneeID == nil && c.ProjectID == nil &&
+		len(c.AddLabels) == 0 && len(c.RemoveLabels) == 0)
+}
+
+// TableName overrides the table name used by BulkJob to `bulk_jobs`
+func (BulkJob) TableName() string {
+	return "bulk_jobs"
+}
+
+// ToResponse converts a BulkJob model to BulkJobResponse
+func (j *BulkJob) ToResponse() BulkJobResponse {
+	response := BulkJobResponse{
+		ID:          j.ID,
+		Action:      j.Action,
+		Status:      j.Status,
+		Total:       j.Total,
+		Processed:   j.Processed,
+		Succeeded:   j.Succeeded,
+		Failed:      j.Failed,
+		Error:       j.Error,
+		Results:     j.Results,
+		CreatedBy:   j.CreatedBy,
+		CreatedAt:   j.CreatedAt,
+		CompletedAt: j.CompletedAt,
+	}
+	if response.Results == nil {
+		response.Results = []TicketBulkResult{}
+	}
+	if j.Total > 0 {
+		response.Progress = float64(j.Processed) / float64(j.Total) * 100
+	}
+	return response
+}
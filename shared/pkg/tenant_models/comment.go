@@ -23,7 +23,8 @@ type Comment struct {
 	CommentType CommentType `json:"comment_type" gorm:"type:varchar(50);default:'comment'"`
 	
 	// Author (References Master DB users.id)
-	AuthorID string `json:"author_id" gorm:"type:uuid;not null"`
+	AuthorID    string `json:"author_id" gorm:"type:uuid;not null"`
+	AuthorEmail string `json:"author_email" gorm:"size:255"` // Sender address when the comment arrived by email
 	
 	// Visibility
 	IsInternal bool `json:"is_internal" gorm:"default:false"` // Internal notes vs public comments
@@ -35,10 +36,11 @@ type Comment struct {
 }
 
 type CommentCreateRequest struct {
-	TicketID    string      `json:"ticket_id" binding:"required,uuid"`
+	TicketID    string      `json:"ticket_id,omitempty" binding:"omitempty,uuid"` // Taken from the URL when posted under a ticket
 	Body        string      `json:"body" binding:"required,min=1"`
 	CommentType CommentType `json:"comment_type,omitempty"`
 	IsInternal  bool        `json:"is_internal,omitempty"`
+	AuthorEmail string      `json:"-"` // Set by the email channel only
 }
 
 type CommentUpdateRequest struct {
@@ -52,6 +54,7 @@ type CommentResponse struct {
 	Body        string      `json:"body"`
 	CommentType CommentType `json:"comment_type"`
 	AuthorID    string      `json:"author_id"`
+	AuthorEmail string      `json:"author_email,omitempty"`
 	IsInternal  bool        `json:"is_internal"`
 	CreatedAt   time.Time   `json:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at"`
@@ -70,6 +73,7 @@ func (c *Comment) ToResponse() CommentResponse {
 		Body:        c.Body,
 		CommentType: c.CommentType,
 		AuthorID:    c.AuthorID,
+		AuthorEmail: c.AuthorEmail,
 		IsInternal:  c.IsInternal,
 		CreatedAt:   c.CreatedAt,
 		UpdatedAt:   c.UpdatedAt,
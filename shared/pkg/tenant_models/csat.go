@@ -0,0 +1,90 @@
+package tenant_models
+
+import (
+	"time"
+)
+
+// CSAT ratings run from 1 (very dissatisfied) to 5 (very satisfied); ratings of
+// CSATSatisfiedRating and above count towards the CSAT score
+const (
+	CSATMinRating       = 1
+	CSATMaxRating       = 5
+	CSATSatisfiedRating = 4
+)
+
+// CSATSurvey asks the customer to rate how a ticket was handled. One is sent each time a
+// ticket is resolved; the customer answers once through a signed link.
+type CSATSurvey struct {
+	ID            string  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TicketID      string  `json:"ticket_id" gorm:"type:uuid;not null;index"`
+	AgentID       *string `json:"agent_id" gorm:"type:uuid;index"`   // Assignee when the ticket was resolved
+	ProjectID     *string `json:"project_id" gorm:"type:uuid;index"` // Project when the ticket was resolved
+	CustomerEmail string  `json:"customer_email" gorm:"not null;size:255"`
+
+	// Answer
+	Rating      *int       `json:"rating"`
+	Comment     string     `json:"comment" gorm:"type:text"`
+	RespondedAt *time.Time `json:"responded_at" gorm:"index"`
+	Reopened    bool       `json:"reopened" gorm:"default:false"` // The rating reopened the ticket
+
+	SentAt    *time.Time `json:"sent_at"` // Nil while the email is pending or if it failed
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CSATSettings is the tenant's survey configuration; a tenant without a row has surveys off
+type CSATSettings struct {
+	ID              string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Enabled         bool   `json:"enabled" gorm:"default:false"`
+	ReopenAtOrBelow int    `json:"reopen_at_or_below" gorm:"default:0"`          // Ratings this low reopen the ticket; 0 never does
+	ReopenStatus    string `json:"reopen_status" gorm:"size:100;default:'open'"` // Status a bad rating moves the ticket to
+	ResponseDays    int    `json:"response_days" gorm:"default:7"`               // How long the survey link stays valid
+
+	UpdatedBy string    `json:"updated_by" gorm:"type:uuid"` // References Master DB users.id
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CSATSettingsRequest struct {
+	Enabled         *bool   `json:"enabled,omitempty"`
+	ReopenAtOrBelow *int    `json:"reopen_at_or_below,omitempty" binding:"omitempty,min=0,max=4"`
+	ReopenStatus    *string `json:"reopen_status,omitempty" binding:"omitempty,min=1,max=100"`
+	ResponseDays    *int    `json:"response_days,omitempty" binding:"omitempty,min=1,max=90"`
+}
+
+// CSATAnswerRequest is the customer's answer, posted with the survey link's token
+type CSATAnswerRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment,omitempty" binding:"omitempty,max=2000"`
+}
+
+// CSATSurveyForm is what the survey page shows the customer
+type CSATSurveyForm struct {
+	TicketNumber int        `json:"ticket_number"`
+	TicketTitle  string     `json:"ticket_title"`
+	Rating       *int       `json:"rating"`
+	Comment      string     `json:"comment"`
+	RespondedAt  *time.Time `json:"responded_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+}
+
+// TableName overrides the table name used by CSATSurvey to `csat_surveys`
+func (CSATSurvey) TableName() string {
+	return "csat_surveys"
+}
+
+// TableName overrides the table name used by CSATSettings to `csat_settings`
+func (CSATSettings) TableName() string {
+	return "csat_settings"
+}
+
+// DefaultCSATSettings are the settings of a tenant that never configured surveys
+func DefaultCSATSettings() *CSATSettings {
+	return &CSATSettings{
+		ReopenStatus: string(StatusOpen),
+		ResponseDays: 7,
+	}
+}
@@ -0,0 +1,108 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+)
+
+type CustomFieldType string
+
+const (
+	CustomFieldText        CustomFieldType = "text"
+	CustomFieldNumber      CustomFieldType = "number"
+	CustomFieldDate        CustomFieldType = "date" // Stored as YYYY-MM-DD
+	CustomFieldSelect      CustomFieldType = "select"
+	CustomFieldMultiSelect CustomFieldType = "multi_select"
+	CustomFieldUser        CustomFieldType = "user" // A user ID of a tenant member
+	CustomFieldCheckbox    CustomFieldType = "checkbox"
+)
+
+// CustomFieldDefinition describes a typed custom field; tickets keep its value in
+// custom_fields under the field's key. Keys without a definition are stored as given.
+type CustomFieldDefinition struct {
+	ID          string             `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Key         string             `json:"key" gorm:"uniqueIndex;not null;size:48"` // Fixed once created
+	Name        string             `json:"name" gorm:"not null;size:255"`
+	Description string             `json:"description" gorm:"type:text"`
+	FieldType   CustomFieldType    `json:"field_type" gorm:"type:varchar(20);not null"` // Fixed once created
+	Options     models.StringArray `json:"options" gorm:"type:jsonb;default:'[]'"`      // Choices of select and multi-select fields
+
+	// Required makes the field mandatory on every ticket; RequiredTicketTypes only on
+	// tickets of those types
+	Required            bool               `json:"required" gorm:"default:false"`
+	RequiredTicketTypes models.StringArray `json:"required_ticket_types" gorm:"type:jsonb;default:'[]'"`
+
+	// DefaultValue is set on new tickets that do not give the field a value
+	DefaultValue interface{} `json:"default_value" gorm:"type:jsonb;serializer:json"`
+
+	// ProjectIDs limits the field to tickets in these projects; empty means every ticket
+	ProjectIDs models.StringArray `json:"project_ids" gorm:"type:jsonb;default:'[]'"`
+
+	Position int  `json:"position" gorm:"default:0"`
+	IsActive bool `json:"is_active" gorm:"default:true"` // Inactive fields are neither checked nor offered
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CustomFieldCreateRequest struct {
+	Key                 string          `json:"key" binding:"required,min=1,max=48"`
+	Name                string          `json:"name" binding:"required,min=1,max=255"`
+	Description         string          `json:"description,omitempty"`
+	FieldType           CustomFieldType `json:"field_type" binding:"required,oneof=text number date select multi_select user checkbox"`
+	Options             []string        `json:"options,omitempty" binding:"omitempty,max=200,dive,min=1,max=100"`
+	Required            bool            `json:"required,omitempty"`
+	RequiredTicketTypes []string        `json:"required_ticket_types,omitempty" binding:"omitempty,dive,min=1,max=50"`
+	DefaultValue        interface{}     `json:"default_value,omitempty"`
+	ProjectIDs          []string        `json:"project_ids,omitempty" binding:"omitempty,dive,uuid"`
+	Position            int             `json:"position,omitempty"`
+}
+
+type CustomFieldUpdateRequest struct {
+	Name                *string     `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description         *string     `json:"description,omitempty"`
+	Options             *[]string   `json:"options,omitempty" binding:"omitempty,max=200,dive,min=1,max=100"`
+	Required            *bool       `json:"required,omitempty"`
+	RequiredTicketTypes *[]string   `json:"required_ticket_types,omitempty" binding:"omitempty,dive,min=1,max=50"`
+	DefaultValue        interface{} `json:"default_value,omitempty"`
+	ClearDefault        bool        `json:"clear_default,omitempty"`
+	ProjectIDs          *[]string   `json:"project_ids,omitempty" binding:"omitempty,dive,uuid"`
+	Position            *int        `json:"position,omitempty"`
+	IsActive            *bool       `json:"is_active,omitempty"`
+}
+
+// AppliesTo reports whether the field is offered on tickets in the project
+func (d *CustomFieldDefinition) AppliesTo(projectID string) bool {
+	if len(d.ProjectIDs) == 0 {
+		return true
+	}
+	for _, id := range d.ProjectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredFor reports whether tickets of the type must give the field a value
+func (d *CustomFieldDefinition) RequiredFor(ticketType TicketType) bool {
+	if d.Required {
+		return true
+	}
+	for _, t := range d.RequiredTicketTypes {
+		if t == string(ticketType) {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName overrides the table name used by CustomFieldDefinition to `custom_field_definitions`
+func (CustomFieldDefinition) TableName() string {
+	return "custom_field_definitions"
+}
@@ -0,0 +1,41 @@
+package tenant_models
+
+import (
+	"time"
+)
+
+// SystemUserID stands in for the author of changes no tenant user made directly,
+// such as tickets and replies created from inbound email
+const SystemUserID = "00000000-0000-0000-0000-000000000000"
+
+type EmailDirection string
+
+const (
+	EmailInbound  EmailDirection = "inbound"
+	EmailOutbound EmailDirection = "outbound"
+)
+
+// EmailMessage records a message exchanged on a ticket so replies can be threaded
+type EmailMessage struct {
+	ID        string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TicketID  string         `json:"ticket_id" gorm:"type:uuid;not null;index"`
+	CommentID *string        `json:"comment_id" gorm:"type:uuid;index"` // Nil for the message that opened the ticket
+	Direction EmailDirection `json:"direction" gorm:"type:varchar(10);not null"`
+
+	// Threading headers, stored without angle brackets
+	MessageID  string `json:"message_id" gorm:"uniqueIndex;not null;size:998"`
+	InReplyTo  string `json:"in_reply_to" gorm:"size:998"`
+	References string `json:"references" gorm:"type:text"` // Space-separated message IDs
+
+	FromAddress string `json:"from_address" gorm:"size:255"`
+	ToAddress   string `json:"to_address" gorm:"size:255"`
+	Subject     string `json:"subject" gorm:"size:998"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name used by EmailMessage to `email_messages`
+func (EmailMessage) TableName() string {
+	return "email_messages"
+}
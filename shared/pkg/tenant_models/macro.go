@@ -0,0 +1,130 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+	"gorm.io/gorm"
+)
+
+type MacroScope string
+
+const (
+	MacroShared   MacroScope = "shared"   // Every agent in the tenant can apply it
+	MacroPersonal MacroScope = "personal" // Only its owner sees it
+)
+
+// Special macro assignees besides user IDs
+const (
+	MacroAssignMe   = "me"   // The agent applying the macro
+	MacroAssignNone = "none" // Unassigns the ticket
+)
+
+// Macro is a canned response: a templated comment plus ticket changes applied in one step.
+// Comment text may use placeholders such as {{ticket.customer_name}} or {{agent.first_name}}.
+type Macro struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string     `json:"name" gorm:"not null;size:255"`
+	Description string     `json:"description" gorm:"type:text"`
+	Scope       MacroScope `json:"scope" gorm:"type:varchar(20);not null;default:'shared';index"`
+	OwnerID     string     `json:"owner_id" gorm:"type:uuid;not null;index"` // Creator; References Master DB users.id
+
+	// Comment posted when the macro is applied; empty posts none
+	Comment         string `json:"comment" gorm:"type:text"`
+	CommentInternal bool   `json:"comment_internal" gorm:"default:false"`
+
+	// Ticket changes; empty values leave the field as it is
+	Status       string             `json:"status" gorm:"type:varchar(100)"`
+	Priority     string             `json:"priority" gorm:"type:varchar(20)"`
+	AssigneeID   string             `json:"assignee_id" gorm:"size:36"` // A user ID, "me" or "none"
+	AddLabels    models.StringArray `json:"add_labels" gorm:"type:jsonb;default:'[]'"`
+	RemoveLabels models.StringArray `json:"remove_labels" gorm:"type:jsonb;default:'[]'"`
+
+	UsageCount int `json:"usage_count" gorm:"default:0"`
+
+	// Timestamps
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type MacroCreateRequest struct {
+	Name            string     `json:"name" binding:"required,min=1,max=255"`
+	Description     string     `json:"description,omitempty"`
+	Scope           MacroScope `json:"scope,omitempty" binding:"omitempty,oneof=shared personal"`
+	Comment         string     `json:"comment,omitempty"`
+	CommentInternal bool       `json:"comment_internal,omitempty"`
+	Status          string     `json:"status,omitempty" binding:"omitempty,max=100"`
+	Priority        string     `json:"priority,omitempty" binding:"omitempty,max=20"`
+	AssigneeID      string     `json:"assignee_id,omitempty"`
+	AddLabels       []string   `json:"add_labels,omitempty"`
+	RemoveLabels    []string   `json:"remove_labels,omitempty"`
+}
+
+type MacroUpdateRequest struct {
+	Name            *string     `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description     *string     `json:"description,omitempty"`
+	Scope           *MacroScope `json:"scope,omitempty" binding:"omitempty,oneof=shared personal"`
+	Comment         *string     `json:"comment,omitempty"`
+	CommentInternal *bool       `json:"comment_internal,omitempty"`
+	Status          *string     `json:"status,omitempty" binding:"omitempty,max=100"`
+	Priority        *string     `json:"priority,omitempty" binding:"omitempty,max=20"`
+	AssigneeID      *string     `json:"assignee_id,omitempty"`
+	AddLabels       *[]string   `json:"add_labels,omitempty"`
+	RemoveLabels    *[]string   `json:"remove_labels,omitempty"`
+}
+
+type MacroApplyRequest struct {
+	TicketID string `json:"ticket_id" binding:"required,uuid"`
+}
+
+type MacroResponse struct {
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	Description     string             `json:"description"`
+	Scope           MacroScope         `json:"scope"`
+	OwnerID         string             `json:"owner_id"`
+	Comment         string             `json:"comment"`
+	CommentInternal bool               `json:"comment_internal"`
+	Status          string             `json:"status"`
+	Priority        string             `json:"priority"`
+	AssigneeID      string             `json:"assignee_id"`
+	AddLabels       models.StringArray `json:"add_labels"`
+	RemoveLabels    models.StringArray `json:"remove_labels"`
+	UsageCount      int                `json:"usage_count"`
+	CanEdit         bool               `json:"can_edit"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// MacroApplyResponse is the ticket after a macro ran, with the comment it posted if any
+type MacroApplyResponse struct {
+	Ticket  *TicketResponse  `json:"ticket"`
+	Comment *CommentResponse `json:"comment,omitempty"`
+}
+
+// TableName overrides the table name used by Macro to `macros`
+func (Macro) TableName() string {
+	return "macros"
+}
+
+// ToResponse converts a Macro model to MacroResponse
+func (m *Macro) ToResponse() MacroResponse {
+	return MacroResponse{
+		ID:              m.ID,
+		Name:            m.Name,
+		Description:     m.Description,
+		Scope:           m.Scope,
+		OwnerID:         m.OwnerID,
+		Comment:         m.Comment,
+		CommentInternal: m.CommentInternal,
+		Status:          m.Status,
+		Priority:        m.Priority,
+		AssigneeID:      m.AssigneeID,
+		AddLabels:       m.AddLabels,
+		RemoveLabels:    m.RemoveLabels,
+		UsageCount:      m.UsageCount,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+	}
+}
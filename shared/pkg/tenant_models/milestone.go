@@ -0,0 +1,118 @@
+package tenant_models
+
+import (
+	"time"
+)
+
+type MilestoneState string
+
+const (
+	MilestoneOpen    MilestoneState = "open"
+	MilestoneDone    MilestoneState = "done"    // Every ticket is resolved or closed
+	MilestoneOverdue MilestoneState = "overdue" // Past its target date with work left
+)
+
+// Milestone is a phase or deadline of a project. Tickets join it through
+// Ticket.MilestoneID and its progress rolls up from their status.
+type Milestone struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ProjectID   string     `json:"project_id" gorm:"type:uuid;not null;index"`
+	Name        string     `json:"name" gorm:"not null;size:255"`
+	Description string     `json:"description" gorm:"type:text"`
+	StartDate   *time.Time `json:"start_date"`
+	TargetDate  time.Time  `json:"target_date" gorm:"not null"`
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type MilestoneCreateRequest struct {
+	Name        string     `json:"name" binding:"required,min=1,max=255"`
+	Description string     `json:"description,omitempty"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	TargetDate  time.Time  `json:"target_date" binding:"required"`
+}
+
+type MilestoneUpdateRequest struct {
+	Name        *string    `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string    `json:"description,omitempty"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	TargetDate  *time.Time `json:"target_date,omitempty"`
+}
+
+type MilestoneTicketsRequest struct {
+	TicketIDs []string `json:"ticket_ids" binding:"required,min=1,max=500,dive,uuid"`
+}
+
+// MilestoneView is a milestone with the progress of its tickets
+type MilestoneView struct {
+	Milestone
+	State    MilestoneState    `json:"state"`
+	Progress MilestoneProgress `json:"progress"`
+}
+
+// MilestoneProgress counts the milestone's tickets; Percent is the share of them done
+type MilestoneProgress struct {
+	TotalTickets       int     `json:"total_tickets"`
+	DoneTickets        int     `json:"done_tickets"`
+	EstimatedHours     float64 `json:"estimated_hours"`
+	DoneEstimatedHours float64 `json:"done_estimated_hours"`
+	Percent            float64 `json:"percent"`
+}
+
+// Roadmap is a project's timeline for Gantt charts: a bar per scheduled ticket, the
+// finish-to-start dependencies between them ("blocks" links) and the milestones
+type Roadmap struct {
+	ProjectID          string              `json:"project_id"`
+	Start              *time.Time          `json:"start"` // Earliest bar or milestone; nil on an empty roadmap
+	End                *time.Time          `json:"end"`   // Latest projected bar end or milestone
+	Bars               []RoadmapBar        `json:"bars"`
+	Dependencies       []RoadmapDependency `json:"dependencies"`
+	Milestones         []RoadmapMilestone  `json:"milestones"`
+	CriticalPath       []string            `json:"critical_path"`       // Ticket IDs, first to last
+	UnscheduledTickets int64               `json:"unscheduled_tickets"` // Unfinished tickets with neither a start nor a due date
+}
+
+// RoadmapBar is one ticket on the timeline. Start and End are as planned, a missing one
+// derived from the estimate; the projected dates are pushed back by unfinished
+// dependencies.
+type RoadmapBar struct {
+	TicketID       string       `json:"ticket_id"`
+	TicketNumber   int          `json:"ticket_number"`
+	Title          string       `json:"title"`
+	Status         TicketStatus `json:"status"`
+	AssigneeID     *string      `json:"assignee_id"`
+	ParentTicketID *string      `json:"parent_ticket_id"`
+	MilestoneID    *string      `json:"milestone_id"`
+	Start          time.Time    `json:"start"`
+	End            time.Time    `json:"end"`
+	ProjectedStart time.Time    `json:"projected_start"`
+	ProjectedEnd   time.Time    `json:"projected_end"`
+	Done           bool         `json:"done"`
+	Critical       bool         `json:"critical"`
+	SlackHours     float64      `json:"slack_hours"` // How far the bar can slip without delaying the project
+}
+
+// RoadmapDependency reads "From must finish before To starts". Violated marks plans where
+// To starts before an unfinished From ends.
+type RoadmapDependency struct {
+	FromTicketID string `json:"from_ticket_id"`
+	ToTicketID   string `json:"to_ticket_id"`
+	Type         string `json:"type"` // finish_to_start
+	Violated     bool   `json:"violated"`
+}
+
+type RoadmapMilestone struct {
+	MilestoneView
+	ProjectedDate *time.Time `json:"projected_date"` // When its last scheduled ticket is projected to end
+	AtRisk        bool       `json:"at_risk"`        // Projected past the target date
+}
+
+// TableName overrides the table name used by Milestone to `milestones`
+func (Milestone) TableName() string {
+	return "milestones"
+}
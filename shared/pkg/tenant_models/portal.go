@@ -0,0 +1,117 @@
+package tenant_models
+
+import (
+	"time"
+)
+
+// PortalLogin is a pending sign-in to the customer portal. The customer proves they own
+// the address with either the emailed one-time code or the magic link's token; only
+// hashes of both are stored.
+type PortalLogin struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Email     string     `json:"email" gorm:"not null;size:255;index"` // Lowercased
+	CodeHash  string     `json:"-" gorm:"not null;size:64"`
+	TokenHash string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	Attempts  int        `json:"attempts" gorm:"default:0"` // Wrong codes entered so far
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PortalLoginRequest starts a sign-in; the tenant is its subdomain
+type PortalLoginRequest struct {
+	Tenant string `json:"tenant" binding:"required,max=100"`
+	Email  string `json:"email" binding:"required,email,max=255"`
+}
+
+// PortalVerifyRequest completes a sign-in with either the magic link's token or the
+// address and its one-time code
+type PortalVerifyRequest struct {
+	Tenant string `json:"tenant" binding:"required,max=100"`
+	Token  string `json:"token,omitempty" binding:"omitempty,max=128"`
+	Email  string `json:"email,omitempty" binding:"omitempty,email,max=255"`
+	Code   string `json:"code,omitempty" binding:"omitempty,len=6,numeric"`
+}
+
+type PortalReplyRequest struct {
+	Body string `json:"body" binding:"required,min=1"`
+}
+
+type PortalSessionResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"` // Seconds
+	Email     string `json:"email"`
+}
+
+// PortalTicketResponse is the customer's view of a ticket, without agent-only fields
+type PortalTicketResponse struct {
+	ID           string         `json:"id"`
+	TicketNumber int            `json:"ticket_number"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Status       TicketStatus   `json:"status"`
+	Priority     TicketPriority `json:"priority"`
+	ResolvedAt   *time.Time     `json:"resolved_at"`
+	ClosedAt     *time.Time     `json:"closed_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// PortalTicketDetail is a ticket with its public conversation and files
+type PortalTicketDetail struct {
+	PortalTicketResponse
+	Comments    []PortalCommentResponse    `json:"comments"`
+	Attachments []PortalAttachmentResponse `json:"attachments"`
+}
+
+// PortalCommentResponse is a public comment; FromCustomer tells the customer's own
+// replies apart from the support team's
+type PortalCommentResponse struct {
+	ID           string    `json:"id"`
+	Body         string    `json:"body"`
+	FromCustomer bool      `json:"from_customer"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type PortalAttachmentResponse struct {
+	ID               string    `json:"id"`
+	CommentID        *string   `json:"comment_id"`
+	OriginalFilename string    `json:"original_filename"`
+	FileSize         int64     `json:"file_size"`
+	MimeType         string    `json:"mime_type"`
+	UploadedAt       time.Time `json:"uploaded_at"`
+}
+
+// TableName overrides the table name used by PortalLogin to `portal_logins`
+func (PortalLogin) TableName() string {
+	return "portal_logins"
+}
+
+// ToPortalResponse converts a Ticket model to the customer's view of it
+func (t *Ticket) ToPortalResponse() PortalTicketResponse {
+	return PortalTicketResponse{
+		ID:           t.ID,
+		TicketNumber: t.TicketNumber,
+		Title:        t.Title,
+		Description:  t.Description,
+		Status:       t.Status,
+		Priority:     t.Priority,
+		ResolvedAt:   t.ResolvedAt,
+		ClosedAt:     t.ClosedAt,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+// ToPortalResponse converts an Attachment model to the customer's view of it, without
+// its storage path
+func (a *Attachment) ToPortalResponse() PortalAttachmentResponse {
+	return PortalAttachmentResponse{
+		ID:               a.ID,
+		CommentID:        a.CommentID,
+		OriginalFilename: a.OriginalFilename,
+		FileSize:         a.FileSize,
+		MimeType:         a.MimeType,
+		UploadedAt:       a.UploadedAt,
+	}
+}
@@ -3,6 +3,7 @@ package tenant_models
 import (
 	"time"
 	"gorm.io/gorm"
+	"github.com/zen/shared/pkg/models"
 )
 
 type ProjectStatus string
@@ -36,6 +37,7 @@ type Project struct {
 	// Configuration
 	ProjectType  ProjectType        `json:"project_type" gorm:"type:varchar(50);default:'software'"`
 	Methodology  ProjectMethodology `json:"methodology" gorm:"type:varchar(50);default:'kanban'"`
+	Labels       models.StringArray `json:"labels" gorm:"type:jsonb;default:'[]'"` // Offered when tagging the project's tickets
 	
 	// Status & Workflow
 	Status ProjectStatus `json:"status" gorm:"type:varchar(50);default:'active'"`
@@ -57,6 +59,7 @@ type ProjectCreateRequest struct {
 	Description string             `json:"description,omitempty"`
 	ProjectType ProjectType        `json:"project_type,omitempty"`
 	Methodology ProjectMethodology `json:"methodology,omitempty"`
+	Labels      []string           `json:"labels,omitempty" binding:"omitempty,max=200,dive,min=1,max=100"`
 	LeadID      string             `json:"lead_id" binding:"required,uuid"`
 }
 
@@ -65,6 +68,7 @@ type ProjectUpdateRequest struct {
 	Description *string             `json:"description,omitempty"`
 	ProjectType *ProjectType        `json:"project_type,omitempty"`
 	Methodology *ProjectMethodology `json:"methodology,omitempty"`
+	Labels      *[]string           `json:"labels,omitempty" binding:"omitempty,max=200,dive,min=1,max=100"`
 	Status      *ProjectStatus      `json:"status,omitempty"`
 	LeadID      *string             `json:"lead_id,omitempty" binding:"omitempty,uuid"`
 }
@@ -76,6 +80,7 @@ type ProjectResponse struct {
 	Description string             `json:"description"`
 	ProjectType ProjectType        `json:"project_type"`
 	Methodology ProjectMethodology `json:"methodology"`
+	Labels      []string           `json:"labels"`
 	Status      ProjectStatus      `json:"status"`
 	LeadID      string             `json:"lead_id"`
 	CreatedBy   string             `json:"created_by"`
@@ -98,6 +103,7 @@ func (p *Project) ToResponse() ProjectResponse {
 		Description: p.Description,
 		ProjectType: p.ProjectType,
 		Methodology: p.Methodology,
+		Labels:      p.Labels,
 		Status:      p.Status,
 		LeadID:      p.LeadID,
 		CreatedBy:   p.CreatedBy,
@@ -0,0 +1,132 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+)
+
+// Placeholders template text may use; they are replaced with the new project's key and
+// name, in ticket titles and descriptions, labels, board names and the project description
+const (
+	TemplateProjectKey  = "{{project.key}}"
+	TemplateProjectName = "{{project.name}}"
+)
+
+// ProjectTemplate sets up new projects: their members, boards, labels and starter
+// tickets, and the tenant's custom statuses and ticket types they rely on, which are
+// created when missing. Templates are defined through the API or saved from a project.
+type ProjectTemplate struct {
+	ID          string             `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string             `json:"name" gorm:"uniqueIndex;not null;size:255"`
+	Description string             `json:"description" gorm:"type:text"` // Also the description of projects created from it
+	ProjectType ProjectType        `json:"project_type" gorm:"type:varchar(50);default:'software'"`
+	Methodology ProjectMethodology `json:"methodology" gorm:"type:varchar(50);default:'kanban'"`
+
+	Labels      models.StringArray              `json:"labels" gorm:"type:jsonb;default:'[]'"`
+	Members     []TemplateMember                `json:"members" gorm:"type:jsonb;serializer:json"`
+	Statuses    []CustomStatusCreateRequest     `json:"statuses" gorm:"type:jsonb;serializer:json"`
+	TicketTypes []CustomTicketTypeCreateRequest `json:"ticket_types" gorm:"type:jsonb;serializer:json"`
+	Boards      []BoardCreateRequest            `json:"boards" gorm:"type:jsonb;serializer:json"` // The default board when empty
+	Tickets     []TemplateTicket                `json:"tickets" gorm:"type:jsonb;serializer:json"`
+	Links       []TemplateTicketLink            `json:"links" gorm:"type:jsonb;serializer:json"`
+
+	SourceProjectID *string `json:"source_project_id" gorm:"type:uuid"` // The project it was saved from
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TemplateMember joins new projects; the project lead is always added as lead
+type TemplateMember struct {
+	UserID string            `json:"user_id" binding:"required,uuid"`
+	Role   ProjectMemberRole `json:"role" binding:"omitempty,oneof=lead member viewer"`
+}
+
+// TemplateTicket is a starter ticket. Ref names it within the template for its links;
+// its dates are given in days from the day the project starts.
+type TemplateTicket struct {
+	Ref            string           `json:"ref" binding:"required,min=1,max=50"`
+	Title          string           `json:"title" binding:"required,min=1,max=500"`
+	Description    string           `json:"description,omitempty"`
+	TicketType     TicketType       `json:"ticket_type,omitempty" binding:"omitempty,max=50"`
+	Priority       TicketPriority   `json:"priority,omitempty" binding:"omitempty,max=20"`
+	Status         TicketStatus     `json:"status,omitempty" binding:"omitempty,max=100"`                       // open when empty
+	Visibility     TicketVisibility `json:"visibility,omitempty" binding:"omitempty,oneof=standard restricted"` // standard when empty
+	AssigneeID     *string          `json:"assignee_id,omitempty" binding:"omitempty,uuid"`
+	Labels         []string         `json:"labels,omitempty" binding:"omitempty,dive,min=1,max=100"`
+	CustomFields   models.JSONB     `json:"custom_fields,omitempty"`
+	EstimatedHours *float64         `json:"estimated_hours,omitempty" binding:"omitempty,gt=0"`
+	StoryPoints    *float64         `json:"story_points,omitempty" binding:"omitempty,gte=0,lte=1000"`
+	StartInDays    *int             `json:"start_in_days,omitempty" binding:"omitempty,min=-3650,max=3650"`
+	DueInDays      *int             `json:"due_in_days,omitempty" binding:"omitempty,min=-3650,max=3650"`
+}
+
+// TemplateTicketLink links two starter tickets by their refs; subtask links also set
+// the target's parent
+type TemplateTicketLink struct {
+	SourceRef string         `json:"source_ref" binding:"required"`
+	TargetRef string         `json:"target_ref" binding:"required"`
+	LinkType  TicketLinkType `json:"link_type" binding:"required,oneof=subtask blocks duplicates relates_to"`
+}
+
+type ProjectTemplateCreateRequest struct {
+	Name        string                          `json:"name" binding:"required,min=2,max=255"`
+	Description string                          `json:"description,omitempty"`
+	ProjectType ProjectType                     `json:"project_type,omitempty" binding:"omitempty,oneof=software support business"`
+	Methodology ProjectMethodology              `json:"methodology,omitempty" binding:"omitempty,oneof=kanban scrum waterfall"`
+	Labels      []string                        `json:"labels,omitempty" binding:"omitempty,max=200,dive,min=1,max=100"`
+	Members     []TemplateMember                `json:"members,omitempty" binding:"omitempty,max=200,dive"`
+	Statuses    []CustomStatusCreateRequest     `json:"statuses,omitempty" binding:"omitempty,max=50,dive"`
+	TicketTypes []CustomTicketTypeCreateRequest `json:"ticket_types,omitempty" binding:"omitempty,max=50,dive"`
+	Boards      []BoardCreateRequest            `json:"boards,omitempty" binding:"omitempty,max=10,dive"`
+	Tickets     []TemplateTicket                `json:"tickets,omitempty" binding:"omitempty,max=500,dive"`
+	Links       []TemplateTicketLink            `json:"links,omitempty" binding:"omitempty,max=1000,dive"`
+}
+
+// ProjectTemplateUpdateRequest replaces each list that is given
+type ProjectTemplateUpdateRequest struct {
+	Name        *string                          `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	Description *string                          `json:"description,omitempty"`
+	ProjectType *ProjectType                     `json:"project_type,omitempty" binding:"omitempty,oneof=software support business"`
+	Methodology *ProjectMethodology              `json:"methodology,omitempty" binding:"omitempty,oneof=kanban scrum waterfall"`
+	Labels      *[]string                        `json:"labels,omitempty" binding:"omitempty,max=200,dive,min=1,max=100"`
+	Members     *[]TemplateMember                `json:"members,omitempty" binding:"omitempty,max=200,dive"`
+	Statuses    *[]CustomStatusCreateRequest     `json:"statuses,omitempty" binding:"omitempty,max=50,dive"`
+	TicketTypes *[]CustomTicketTypeCreateRequest `json:"ticket_types,omitempty" binding:"omitempty,max=50,dive"`
+	Boards      *[]BoardCreateRequest            `json:"boards,omitempty" binding:"omitempty,max=10,dive"`
+	Tickets     *[]TemplateTicket                `json:"tickets,omitempty" binding:"omitempty,max=500,dive"`
+	Links       *[]TemplateTicketLink            `json:"links,omitempty" binding:"omitempty,max=1000,dive"`
+}
+
+// ProjectTemplateSaveRequest saves a project as a template. Its key and name are
+// replaced with placeholders throughout.
+type ProjectTemplateSaveRequest struct {
+	Name               string `json:"name" binding:"required,min=2,max=255"`
+	Description        string `json:"description,omitempty"`
+	IncludeOpenTickets bool   `json:"include_open_tickets,omitempty"` // As starter tickets, with the links between them
+}
+
+// ProjectFromTemplateRequest creates a project from a template
+type ProjectFromTemplateRequest struct {
+	Key         string     `json:"key" binding:"required,min=2,max=20,alphanum"`
+	Name        string     `json:"name" binding:"required,min=2,max=255"`
+	Description *string    `json:"description,omitempty"` // The template's when nil
+	LeadID      string     `json:"lead_id" binding:"required,uuid"`
+	StartDate   *time.Time `json:"start_date,omitempty"` // Ticket dates count from this day; today when nil
+}
+
+// ProjectCloneRequest copies a project's setup into a new project
+type ProjectCloneRequest struct {
+	ProjectFromTemplateRequest
+	IncludeOpenTickets bool `json:"include_open_tickets,omitempty"` // Copied with the links between them
+}
+
+// TableName overrides the table name used by ProjectTemplate to `project_templates`
+func (ProjectTemplate) TableName() string {
+	return "project_templates"
+}
@@ -0,0 +1,116 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+	"gorm.io/gorm"
+)
+
+type ViewVisibility string
+
+const (
+	ViewPersonal ViewVisibility = "personal" // Only the owner
+	ViewTeam     ViewVisibility = "team"     // Members of the view's project
+	ViewTenant   ViewVisibility = "tenant"   // Everyone in the tenant
+)
+
+// SavedView is a named ticket query. Query uses the ticket search syntax, including
+// dynamic tokens such as assignee:me or created:last_7d, so it is resolved per user and
+// at the time it runs.
+type SavedView struct {
+	ID          string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string `json:"name" gorm:"not null;size:100"`
+	Description string `json:"description" gorm:"type:text"`
+
+	// Criteria and presentation
+	Query    string             `json:"query" gorm:"type:text"`
+	SortBy   string             `json:"sort_by" gorm:"size:20"` // Empty sorts by relevance, or recency without search text
+	SortDesc bool               `json:"sort_desc"`
+	Columns  models.StringArray `json:"columns" gorm:"type:jsonb;default:'[]'"`
+
+	// Sharing
+	Visibility ViewVisibility `json:"visibility" gorm:"type:varchar(20);not null;default:'personal'"`
+	OwnerID    string         `json:"owner_id" gorm:"type:uuid;not null;index"` // References Master DB users.id
+	ProjectID  *string        `json:"project_id" gorm:"type:uuid;index"`        // Required for team views
+
+	Position int `json:"position" gorm:"default:0"` // Sidebar order
+
+	// Timestamps
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type SavedViewCreateRequest struct {
+	Name        string         `json:"name" binding:"required,min=1,max=100"`
+	Description string         `json:"description,omitempty"`
+	Query       string         `json:"query,omitempty"`
+	SortBy      string         `json:"sort_by,omitempty"`
+	SortDesc    *bool          `json:"sort_desc,omitempty"` // Defaults to true
+	Columns     []string       `json:"columns,omitempty"`
+	Visibility  ViewVisibility `json:"visibility,omitempty" binding:"omitempty,oneof=personal team tenant"`
+	ProjectID   *string        `json:"project_id,omitempty" binding:"omitempty,uuid"`
+	Position    int            `json:"position,omitempty"`
+}
+
+type SavedViewUpdateRequest struct {
+	Name        *string         `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Description *string         `json:"description,omitempty"`
+	Query       *string         `json:"query,omitempty"`
+	SortBy      *string         `json:"sort_by,omitempty"`
+	SortDesc    *bool           `json:"sort_desc,omitempty"`
+	Columns     *[]string       `json:"columns,omitempty"`
+	Visibility  *ViewVisibility `json:"visibility,omitempty" binding:"omitempty,oneof=personal team tenant"`
+	ProjectID   *string         `json:"project_id,omitempty" binding:"omitempty,uuid"`
+	Position    *int            `json:"position,omitempty"`
+}
+
+type SavedViewResponse struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Query       string             `json:"query"`
+	SortBy      string             `json:"sort_by"`
+	SortDesc    bool               `json:"sort_desc"`
+	Columns     models.StringArray `json:"columns"`
+	Visibility  ViewVisibility     `json:"visibility"`
+	OwnerID     string             `json:"owner_id"`
+	ProjectID   *string            `json:"project_id"`
+	Position    int                `json:"position"`
+	Builtin     bool               `json:"builtin"` // Built-in personal queues cannot be edited
+	Editable    bool               `json:"editable"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// SavedViewCount is a view's live ticket count for sidebar badges
+type SavedViewCount struct {
+	ViewID string `json:"view_id"`
+	Count  *int64 `json:"count"` // Nil when the view's query no longer parses or it was not counted
+	Error  string `json:"error,omitempty"`
+}
+
+// TableName overrides the table name used by SavedView to `saved_views`
+func (SavedView) TableName() string {
+	return "saved_views"
+}
+
+// ToResponse converts a SavedView model to SavedViewResponse
+func (v *SavedView) ToResponse() SavedViewResponse {
+	return SavedViewResponse{
+		ID:          v.ID,
+		Name:        v.Name,
+		Description: v.Description,
+		Query:       v.Query,
+		SortBy:      v.SortBy,
+		SortDesc:    v.SortDesc,
+		Columns:     v.Columns,
+		Visibility:  v.Visibility,
+		OwnerID:     v.OwnerID,
+		ProjectID:   v.ProjectID,
+		Position:    v.Position,
+		CreatedAt:   v.CreatedAt,
+		UpdatedAt:   v.UpdatedAt,
+	}
+}
@@ -0,0 +1,250 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+	"gorm.io/gorm"
+)
+
+type TicketSLAStatus string
+
+const (
+	SLAStatusActive   TicketSLAStatus = "active"
+	SLAStatusPaused   TicketSLAStatus = "paused"
+	SLAStatusMet      TicketSLAStatus = "met"
+	SLAStatusBreached TicketSLAStatus = "breached"
+)
+
+// SLAPolicy defines first-response and resolution targets for tickets matching its conditions.
+// Empty match fields act as wildcards; policies are evaluated in SortOrder and the first match wins.
+type SLAPolicy struct {
+	ID          string  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string  `json:"name" gorm:"not null;size:255"`
+	Description string  `json:"description" gorm:"type:text"`
+	CalendarID  *string `json:"calendar_id" gorm:"type:uuid;index"` // Business hours calendar, nil means 24x7
+
+	// Matching
+	Priorities   models.StringArray `json:"priorities" gorm:"type:jsonb;default:'[]'"`
+	TicketTypes  models.StringArray `json:"ticket_types" gorm:"type:jsonb;default:'[]'"`
+	Channels     models.StringArray `json:"channels" gorm:"type:jsonb;default:'[]'"`
+	CustomFields models.JSONB       `json:"custom_fields" gorm:"type:jsonb;default:'{}'"` // field -> required value
+
+	// Targets (in business minutes)
+	FirstResponseMinutes *int `json:"first_response_minutes"`
+	ResolutionMinutes    *int `json:"resolution_minutes"`
+
+	// Statuses that stop the clock, e.g. waiting on customer (defaults to on_hold)
+	PauseStatuses models.StringArray `json:"pause_statuses" gorm:"type:jsonb;default:'[]'"`
+
+	SortOrder int  `json:"sort_order" gorm:"default:0"`
+	IsActive  bool `json:"is_active" gorm:"default:true"`
+
+	// Timestamps
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// BusinessCalendar describes the working hours SLA timers run in
+type BusinessCalendar struct {
+	ID       string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name     string `json:"name" gorm:"uniqueIndex;not null;size:255"`
+	Timezone string `json:"timezone" gorm:"size:64;default:'UTC'"`
+
+	// Working hours per weekday, e.g. {"monday": {"start": "09:00", "end": "17:00"}}.
+	// Weekdays that are absent are non-working days.
+	WorkingHours models.JSONB `json:"working_hours" gorm:"type:jsonb;default:'{}'"`
+
+	Holidays []BusinessHoliday `json:"holidays,omitempty" gorm:"foreignKey:CalendarID"`
+
+	// Timestamps
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// BusinessHoliday is a non-working day within a business calendar
+type BusinessHoliday struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	CalendarID string    `json:"calendar_id" gorm:"type:uuid;not null;index"`
+	Name       string    `json:"name" gorm:"not null;size:255"`
+	Date       time.Time `json:"date" gorm:"type:date;not null"`
+	Recurring  bool      `json:"recurring" gorm:"default:false"` // Repeats every year on the same month/day
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TicketSLA stores the SLA clock state of a single ticket
+type TicketSLA struct {
+	ID         string  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TicketID   string  `json:"ticket_id" gorm:"type:uuid;not null;uniqueIndex"`
+	PolicyID   string  `json:"policy_id" gorm:"type:uuid;not null;index"`
+	CalendarID *string `json:"calendar_id" gorm:"type:uuid"`
+
+	Status TicketSLAStatus `json:"status" gorm:"type:varchar(20);default:'active';index"`
+
+	// First response
+	FirstResponseTargetAt   *time.Time `json:"first_response_target_at" gorm:"index"`
+	FirstRespondedAt        *time.Time `json:"first_responded_at"`
+	FirstResponseBreachedAt *time.Time `json:"first_response_breached_at"`
+
+	// Resolution
+	ResolutionTargetAt   *time.Time `json:"resolution_target_at" gorm:"index"`
+	ResolvedAt           *time.Time `json:"resolved_at"`
+	ResolutionBreachedAt *time.Time `json:"resolution_breached_at"`
+
+	// Pausing
+	PausedAt      *time.Time `json:"paused_at"`
+	PausedSeconds int64      `json:"paused_seconds" gorm:"default:0"` // Total business time spent paused
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SLAPolicyCreateRequest struct {
+	Name                 string       `json:"name" binding:"required,min=1,max=255"`
+	Description          string       `json:"description,omitempty"`
+	CalendarID           *string      `json:"calendar_id,omitempty" binding:"omitempty,uuid"`
+	Priorities           []string     `json:"priorities,omitempty"`
+	TicketTypes          []string     `json:"ticket_types,omitempty"`
+	Channels             []string     `json:"channels,omitempty"`
+	CustomFields         models.JSONB `json:"custom_fields,omitempty"`
+	FirstResponseMinutes *int         `json:"first_response_minutes,omitempty" binding:"omitempty,gt=0"`
+	ResolutionMinutes    *int         `json:"resolution_minutes,omitempty" binding:"omitempty,gt=0"`
+	PauseStatuses        []string     `json:"pause_statuses,omitempty"`
+	SortOrder            int          `json:"sort_order,omitempty"`
+}
+
+type SLAPolicyUpdateRequest struct {
+	Name                 *string       `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description          *string       `json:"description,omitempty"`
+	CalendarID           *string       `json:"calendar_id,omitempty" binding:"omitempty,uuid"`
+	Priorities           []string      `json:"priorities,omitempty"`
+	TicketTypes          []string      `json:"ticket_types,omitempty"`
+	Channels             []string      `json:"channels,omitempty"`
+	CustomFields         *models.JSONB `json:"custom_fields,omitempty"`
+	FirstResponseMinutes *int          `json:"first_response_minutes,omitempty" binding:"omitempty,gt=0"`
+	ResolutionMinutes    *int          `json:"resolution_minutes,omitempty" binding:"omitempty,gt=0"`
+	PauseStatuses        []string      `json:"pause_statuses,omitempty"`
+	SortOrder            *int          `json:"sort_order,omitempty"`
+	IsActive             *bool         `json:"is_active,omitempty"`
+}
+
+type BusinessCalendarCreateRequest struct {
+	Name         string       `json:"name" binding:"required,min=1,max=255"`
+	Timezone     string       `json:"timezone,omitempty"`
+	WorkingHours models.JSONB `json:"working_hours" binding:"required"`
+}
+
+type BusinessCalendarUpdateRequest struct {
+	Name         *string       `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Timezone     *string       `json:"timezone,omitempty"`
+	WorkingHours *models.JSONB `json:"working_hours,omitempty"`
+}
+
+type BusinessHolidayCreateRequest struct {
+	Name      string `json:"name" binding:"required,min=1,max=255"`
+	Date      string `json:"date" binding:"required"` // YYYY-MM-DD
+	Recurring bool   `json:"recurring,omitempty"`
+}
+
+type SLAPolicyResponse struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	Description          string             `json:"description"`
+	CalendarID           *string            `json:"calendar_id"`
+	Priorities           models.StringArray `json:"priorities"`
+	TicketTypes          models.StringArray `json:"ticket_types"`
+	Channels             models.StringArray `json:"channels"`
+	CustomFields         models.JSONB       `json:"custom_fields"`
+	FirstResponseMinutes *int               `json:"first_response_minutes"`
+	ResolutionMinutes    *int               `json:"resolution_minutes"`
+	PauseStatuses        models.StringArray `json:"pause_statuses"`
+	SortOrder            int                `json:"sort_order"`
+	IsActive             bool               `json:"is_active"`
+	CreatedAt            time.Time          `json:"created_at"`
+	UpdatedAt            time.Time          `json:"updated_at"`
+}
+
+type TicketSLAResponse struct {
+	TicketID                string          `json:"ticket_id"`
+	PolicyID                string          `json:"policy_id"`
+	Status                  TicketSLAStatus `json:"status"`
+	FirstResponseTargetAt   *time.Time      `json:"first_response_target_at"`
+	FirstRespondedAt        *time.Time      `json:"first_responded_at"`
+	FirstResponseBreachedAt *time.Time      `json:"first_response_breached_at"`
+	ResolutionTargetAt      *time.Time      `json:"resolution_target_at"`
+	ResolvedAt              *time.Time      `json:"resolved_at"`
+	ResolutionBreachedAt    *time.Time      `json:"resolution_breached_at"`
+	PausedAt                *time.Time      `json:"paused_at"`
+	PausedSeconds           int64           `json:"paused_seconds"`
+}
+
+// TableName overrides the table name used by SLAPolicy to `sla_policies`
+func (SLAPolicy) TableName() string {
+	return "sla_policies"
+}
+
+// TableName overrides the table name used by BusinessCalendar to `business_calendars`
+func (BusinessCalendar) TableName() string {
+	return "business_calendars"
+}
+
+// TableName overrides the table name used by BusinessHoliday to `business_holidays`
+func (BusinessHoliday) TableName() string {
+	return "business_holidays"
+}
+
+// TableName overrides the table name used by TicketSLA to `ticket_slas`
+func (TicketSLA) TableName() string {
+	return "ticket_slas"
+}
+
+// ToResponse converts an SLAPolicy model to SLAPolicyResponse
+func (p *SLAPolicy) ToResponse() SLAPolicyResponse {
+	return SLAPolicyResponse{
+		ID:                   p.ID,
+		Name:                 p.Name,
+		Description:          p.Description,
+		CalendarID:           p.CalendarID,
+		Priorities:           p.Priorities,
+		TicketTypes:          p.TicketTypes,
+		Channels:             p.Channels,
+		CustomFields:         p.CustomFields,
+		FirstResponseMinutes: p.FirstResponseMinutes,
+		ResolutionMinutes:    p.ResolutionMinutes,
+		PauseStatuses:        p.PauseStatuses,
+		SortOrder:            p.SortOrder,
+		IsActive:             p.IsActive,
+		CreatedAt:            p.CreatedAt,
+		UpdatedAt:            p.UpdatedAt,
+	}
+}
+
+// ToResponse converts a TicketSLA model to TicketSLAResponse
+func (s *TicketSLA) ToResponse() TicketSLAResponse {
+	return TicketSLAResponse{
+		TicketID:                s.TicketID,
+		PolicyID:                s.PolicyID,
+		Status:                  s.Status,
+		FirstResponseTargetAt:   s.FirstResponseTargetAt,
+		FirstRespondedAt:        s.FirstRespondedAt,
+		FirstResponseBreachedAt: s.FirstResponseBreachedAt,
+		ResolutionTargetAt:      s.ResolutionTargetAt,
+		ResolvedAt:              s.ResolvedAt,
+		ResolutionBreachedAt:    s.ResolutionBreachedAt,
+		PausedAt:                s.PausedAt,
+		PausedSeconds:           s.PausedSeconds,
+	}
+}
+
+// IsPaused checks if the SLA clock is currently stopped
+func (s *TicketSLA) IsPaused() bool {
+	return s.PausedAt != nil
+}
+
+// IsBreached checks if either SLA target has been breached
+func (s *TicketSLA) IsBreached() bool {
+	return s.FirstResponseBreachedAt != nil || s.ResolutionBreachedAt != nil
+}
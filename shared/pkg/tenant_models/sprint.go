@@ -0,0 +1,130 @@
+package tenant_models
+
+import (
+	"time"
+)
+
+type SprintState string
+
+const (
+	SprintPlanned   SprintState = "planned"
+	SprintActive    SprintState = "active"
+	SprintCompleted SprintState = "completed"
+)
+
+// Sprint is a time box of a scrum project. Tickets join it through Ticket.SprintID; the
+// committed and completed figures are snapshots taken when it starts and completes.
+type Sprint struct {
+	ID        string      `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ProjectID string      `json:"project_id" gorm:"type:uuid;not null;index"`
+	Name      string      `json:"name" gorm:"not null;size:255"`
+	Goal      string      `json:"goal" gorm:"type:text"`
+	State     SprintState `json:"state" gorm:"type:varchar(20);default:'planned';index"`
+
+	// Planned dates; a sprint needs an end date to start
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	// Snapshots
+	CommittedPoints    float64 `json:"committed_points" gorm:"type:decimal(8,2);default:0"`
+	CommittedTickets   int     `json:"committed_tickets" gorm:"default:0"`
+	CompletedPoints    float64 `json:"completed_points" gorm:"type:decimal(8,2);default:0"`
+	CompletedTickets   int     `json:"completed_tickets" gorm:"default:0"`
+	CarriedOverTickets int     `json:"carried_over_tickets" gorm:"default:0"`
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SprintCreateRequest struct {
+	Name      string     `json:"name" binding:"required,min=1,max=255"`
+	Goal      string     `json:"goal,omitempty"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+type SprintUpdateRequest struct {
+	Name      *string    `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Goal      *string    `json:"goal,omitempty"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// SprintStartRequest may set or override the planned dates when the sprint starts
+type SprintStartRequest struct {
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// SprintCompleteRequest says where unfinished tickets go: a planned sprint of the same
+// project, or the backlog when no sprint is given
+type SprintCompleteRequest struct {
+	CarryOverSprintID *string `json:"carry_over_sprint_id,omitempty" binding:"omitempty,uuid"`
+}
+
+type SprintTicketsRequest struct {
+	TicketIDs []string `json:"ticket_ids" binding:"required,min=1,max=500,dive,uuid"`
+}
+
+// SprintView is a sprint with its tickets in board order and how far along it is
+type SprintView struct {
+	Sprint   Sprint         `json:"sprint"`
+	Progress SprintProgress `json:"progress"`
+	Tickets  []BoardCard    `json:"tickets"`
+}
+
+type SprintProgress struct {
+	TotalPoints        float64 `json:"total_points"`
+	DonePoints         float64 `json:"done_points"`
+	TotalTickets       int     `json:"total_tickets"`
+	DoneTickets        int     `json:"done_tickets"`
+	UnestimatedTickets int     `json:"unestimated_tickets"`
+}
+
+// SprintBurndown has one entry per day of the sprint, measured at the end of the day.
+// A burndown plots RemainingPoints against IdealPoints; a burnup plots CompletedPoints
+// against ScopePoints. Days still to come only carry the ideal line.
+type SprintBurndown struct {
+	SprintID        string              `json:"sprint_id"`
+	StartedAt       time.Time           `json:"started_at"`
+	EndDate         time.Time           `json:"end_date"`
+	CommittedPoints float64             `json:"committed_points"`
+	Days            []SprintBurndownDay `json:"days"`
+}
+
+type SprintBurndownDay struct {
+	Date             string   `json:"date"` // YYYY-MM-DD, UTC
+	IdealPoints      float64  `json:"ideal_points"`
+	ScopePoints      *float64 `json:"scope_points,omitempty"`
+	CompletedPoints  *float64 `json:"completed_points,omitempty"`
+	RemainingPoints  *float64 `json:"remaining_points,omitempty"`
+	ScopeTickets     *int     `json:"scope_tickets,omitempty"`
+	CompletedTickets *int     `json:"completed_tickets,omitempty"`
+}
+
+// ProjectVelocity covers the project's most recently completed sprints, oldest first
+type ProjectVelocity struct {
+	Sprints       []SprintVelocity `json:"sprints"`
+	AveragePoints float64          `json:"average_points"`
+}
+
+type SprintVelocity struct {
+	SprintID         string     `json:"sprint_id"`
+	Name             string     `json:"name"`
+	CompletedAt      *time.Time `json:"completed_at"`
+	CommittedPoints  float64    `json:"committed_points"`
+	CompletedPoints  float64    `json:"completed_points"`
+	CompletedTickets int        `json:"completed_tickets"`
+}
+
+// TableName overrides the table name used by Sprint to `sprints`
+func (Sprint) TableName() string {
+	return "sprints"
+}
@@ -11,12 +11,15 @@ type TicketPriority string
 type TicketStatus string
 type TicketResolution string
 type TicketChannel string
+type TicketVisibility string
 
 const (
 	TicketTypeTask    TicketType = "task"
 	TicketTypeBug     TicketType = "bug"
 	TicketTypeFeature TicketType = "feature"
 	TicketTypeSupport TicketType = "support"
+	TicketTypeQuestion TicketType = "question"
+	TicketTypeIncident TicketType = "incident"
 )
 
 const (
@@ -30,6 +33,7 @@ const (
 const (
 	StatusOpen       TicketStatus = "open"
 	StatusInProgress TicketStatus = "in_progress"
+	StatusOnHold     TicketStatus = "on_hold"
 	StatusResolved   TicketStatus = "resolved"
 	StatusClosed     TicketStatus = "closed"
 )
@@ -49,6 +53,11 @@ const (
 	ChannelAPI   TicketChannel = "api"
 )
 
+const (
+	VisibilityStandard   TicketVisibility = "standard"   // Visible to the ticket's project members and the support team
+	VisibilityRestricted TicketVisibility = "restricted" // Only reporter, assignee, project leads and admins
+)
+
 type Ticket struct {
 	ID           string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	TicketNumber int            `json:"ticket_number" gorm:"autoIncrement;uniqueIndex"` // Auto-incrementing ticket number per tenant
@@ -58,34 +67,50 @@ type Ticket struct {
 	// Classification
 	TicketType TicketType     `json:"ticket_type" gorm:"type:varchar(50);default:'task'"`
 	Priority   TicketPriority `json:"priority" gorm:"type:varchar(20);default:'medium'"`
-	Status     TicketStatus   `json:"status" gorm:"type:varchar(50);default:'open'"`
+	Status     TicketStatus   `json:"status" gorm:"type:varchar(100);default:'open'"` // Built-in or tenant CustomStatus name
+	Category   string         `json:"category" gorm:"size:100;index"`
+	Visibility TicketVisibility `json:"visibility" gorm:"type:varchar(20);default:'standard'"`
 	
 	// Relationships
 	ProjectID      *string `json:"project_id" gorm:"type:uuid;index"`
-	ParentTicketID *string `json:"parent_ticket_id" gorm:"type:uuid"`
+	ParentTicketID *string `json:"parent_ticket_id" gorm:"type:uuid;index"` // Kept in sync with the ticket's subtask link
+	SprintID       *string `json:"sprint_id" gorm:"type:uuid;index"`        // Set through the project's sprints
+	MilestoneID    *string `json:"milestone_id" gorm:"type:uuid;index"`     // Set through the project's milestones
 	
 	// Assignment (All reference Master DB users.id)
 	ReporterID *string `json:"reporter_id" gorm:"type:uuid;not null"` // Who created the ticket
 	AssigneeID *string `json:"assignee_id" gorm:"type:uuid"`          // Who is assigned to work on it
+	GroupID    *string `json:"group_id" gorm:"type:uuid;index"`       // Agent group whose queue holds it
 	
 	// Customer Support Fields
 	CustomerEmail string        `json:"customer_email" gorm:"size:255"`
 	CustomerName  string        `json:"customer_name" gorm:"size:255"`
 	Channel       TicketChannel `json:"channel" gorm:"type:varchar(50)"`
+	Language      string        `json:"language" gorm:"size:10"` // e.g. en, de; used by skills-based routing
 	
 	// Resolution
 	Resolution TicketResolution `json:"resolution" gorm:"type:varchar(100)"`
 	ResolvedAt *time.Time       `json:"resolved_at"`
 	ResolvedBy *string          `json:"resolved_by" gorm:"type:uuid"` // FK to master.users.id
+	ClosedAt   *time.Time       `json:"closed_at"`
+	
+	// Scheduling
+	StartDate *time.Time `json:"start_date"`
+	DueDate   *time.Time `json:"due_date" gorm:"index"`
+	DueBySLA  bool       `json:"due_by_sla" gorm:"default:false"` // DueDate is the SLA resolution target and follows it
 	
 	// Time Tracking
 	EstimatedHours *float64 `json:"estimated_hours" gorm:"type:decimal(8,2)"`
 	ActualHours    *float64 `json:"actual_hours" gorm:"type:decimal(8,2)"`
+	StoryPoints    *float64 `json:"story_points" gorm:"type:decimal(6,2)"` // Scrum estimate
 	
 	// Metadata
-	Labels       models.JSONB `json:"labels" gorm:"type:jsonb;default:'[]'"`         // Flexible tagging system
+	Labels       models.StringArray `json:"labels" gorm:"type:jsonb;default:'[]'"`   // Flexible tagging system
 	CustomFields models.JSONB `json:"custom_fields" gorm:"type:jsonb;default:'{}'"`  // Tenant-specific custom fields
 	
+	// Position on the project's boards; see package rank. Compared byte by byte.
+	BoardRank string `json:"board_rank" gorm:"type:varchar(64) COLLATE \"C\";index"`
+	
 	// Timestamps
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -99,17 +124,24 @@ type TicketCreateRequest struct {
 	Priority    TicketPriority `json:"priority,omitempty"`
 	ProjectID   *string        `json:"project_id,omitempty" binding:"omitempty,uuid"`
 	AssigneeID  *string        `json:"assignee_id,omitempty" binding:"omitempty,uuid"`
+	GroupID     *string        `json:"group_id,omitempty" binding:"omitempty,uuid"` // Routed to the first matching group when empty
+	Category    string         `json:"category,omitempty" binding:"omitempty,max=100"`
+	Visibility  TicketVisibility `json:"visibility,omitempty"`
+	StartDate   *time.Time     `json:"start_date,omitempty"`
+	DueDate     *time.Time     `json:"due_date,omitempty"`
 	
 	// Customer Support Fields
 	CustomerEmail string        `json:"customer_email,omitempty" binding:"omitempty,email"`
 	CustomerName  string        `json:"customer_name,omitempty"`
 	Channel       TicketChannel `json:"channel,omitempty"`
+	Language      string        `json:"language,omitempty" binding:"omitempty,max=10"`
 	
 	// Time Tracking
 	EstimatedHours *float64 `json:"estimated_hours,omitempty" binding:"omitempty,gt=0"`
+	StoryPoints    *float64 `json:"story_points,omitempty" binding:"omitempty,gte=0,lte=1000"`
 	
 	// Metadata
-	Labels       models.JSONB `json:"labels,omitempty"`
+	Labels       []string     `json:"labels,omitempty"`
 	CustomFields models.JSONB `json:"custom_fields,omitempty"`
 }
 
@@ -120,15 +152,32 @@ type TicketUpdateRequest struct {
 	Priority     *TicketPriority   `json:"priority,omitempty"`
 	Status       *TicketStatus     `json:"status,omitempty"`
 	AssigneeID   *string           `json:"assignee_id,omitempty" binding:"omitempty,uuid"`
+	GroupID      *string           `json:"group_id,omitempty" binding:"omitempty,uuid"` // Moving groups re-routes unless an assignee is given
+	ProjectID    *string           `json:"project_id,omitempty" binding:"omitempty,uuid"`
 	Resolution   *TicketResolution `json:"resolution,omitempty"`
+	Category     *string           `json:"category,omitempty" binding:"omitempty,max=100"`
+	Visibility   *TicketVisibility `json:"visibility,omitempty"`
+	Language     *string           `json:"language,omitempty" binding:"omitempty,max=10"`
+	StartDate    *time.Time        `json:"start_date,omitempty"`
+	DueDate      *time.Time        `json:"due_date,omitempty"`
 	
-	// Time Tracking
+	// Time Tracking. ActualHours is not editable: it is the sum of the ticket's worklogs.
 	EstimatedHours *float64 `json:"estimated_hours,omitempty" binding:"omitempty,gt=0"`
-	ActualHours    *float64 `json:"actual_hours,omitempty" binding:"omitempty,gt=0"`
+	StoryPoints    *float64 `json:"story_points,omitempty" binding:"omitempty,gte=0,lte=1000"`
 	
 	// Metadata
-	Labels       *models.JSONB `json:"labels,omitempty"`
+	Labels       *[]string     `json:"labels,omitempty"`
 	CustomFields *models.JSONB `json:"custom_fields,omitempty"`
+	
+	// Board placement, e.g. for a card dropped into another column
+	Rank *TicketRankRequest `json:"rank,omitempty"`
+}
+
+// TicketRankRequest places a ticket between two cards of its board column. Either may
+// be left out at the column's ends; with neither the ticket goes to the bottom.
+type TicketRankRequest struct {
+	AfterID  string `json:"after_id,omitempty" binding:"omitempty,uuid"`  // The card that ends up above
+	BeforeID string `json:"before_id,omitempty" binding:"omitempty,uuid"` // The card that ends up below
 }
 
 type TicketResponse struct {
@@ -139,23 +188,49 @@ type TicketResponse struct {
 	TicketType   TicketType       `json:"ticket_type"`
 	Priority     TicketPriority   `json:"priority"`
 	Status       TicketStatus     `json:"status"`
+	Category     string           `json:"category"`
+	Visibility   TicketVisibility `json:"visibility"`
 	ProjectID    *string          `json:"project_id"`
+	ParentTicketID *string        `json:"parent_ticket_id"`
+	SprintID     *string          `json:"sprint_id"`
+	MilestoneID  *string          `json:"milestone_id"`
 	ReporterID   *string          `json:"reporter_id"`
 	AssigneeID   *string          `json:"assignee_id"`
+	GroupID      *string          `json:"group_id"`
 	CustomerEmail string          `json:"customer_email"`
 	CustomerName string           `json:"customer_name"`
 	Channel      TicketChannel    `json:"channel"`
+	Language     string           `json:"language"`
 	Resolution   TicketResolution `json:"resolution"`
 	ResolvedAt   *time.Time       `json:"resolved_at"`
 	ResolvedBy   *string          `json:"resolved_by"`
+	ClosedAt     *time.Time       `json:"closed_at"`
+	StartDate    *time.Time       `json:"start_date"`
+	DueDate      *time.Time       `json:"due_date"`
+	DueBySLA     bool             `json:"due_by_sla"`
 	EstimatedHours *float64       `json:"estimated_hours"`
 	ActualHours    *float64       `json:"actual_hours"`
-	Labels       models.JSONB     `json:"labels"`
+	StoryPoints    *float64       `json:"story_points"`
+	Labels       models.StringArray `json:"labels"`
 	CustomFields models.JSONB     `json:"custom_fields"`
+	BoardRank    string           `json:"board_rank"`
 	CreatedAt    time.Time        `json:"created_at"`
 	UpdatedAt    time.Time        `json:"updated_at"`
 }
 
+// TicketSearchResult is a ticket matched by full-text search. Highlights are HTML-escaped
+// with matches wrapped in <mark> tags.
+type TicketSearchResult struct {
+	TicketResponse
+	Rank       float64                `json:"rank"`
+	Highlights TicketSearchHighlights `json:"highlights"`
+}
+
+type TicketSearchHighlights struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"` // Best matching fragments of the description and public comments
+}
+
 // TableName overrides the table name used by Ticket to `tickets`
 func (Ticket) TableName() string {
 	return "tickets"
@@ -171,19 +246,32 @@ func (t *Ticket) ToResponse() TicketResponse {
 		TicketType:   t.TicketType,
 		Priority:     t.Priority,
 		Status:       t.Status,
+		Category:     t.Category,
+		Visibility:   t.Visibility,
 		ProjectID:    t.ProjectID,
+		ParentTicketID: t.ParentTicketID,
+		SprintID:     t.SprintID,
+		MilestoneID:  t.MilestoneID,
 		ReporterID:   t.ReporterID,
 		AssigneeID:   t.AssigneeID,
+		GroupID:      t.GroupID,
 		CustomerEmail: t.CustomerEmail,
 		CustomerName: t.CustomerName,
 		Channel:      t.Channel,
+		Language:     t.Language,
 		Resolution:   t.Resolution,
 		ResolvedAt:   t.ResolvedAt,
 		ResolvedBy:   t.ResolvedBy,
+		ClosedAt:     t.ClosedAt,
+		StartDate:    t.StartDate,
+		DueDate:      t.DueDate,
+		DueBySLA:     t.DueBySLA,
 		EstimatedHours: t.EstimatedHours,
 		ActualHours:    t.ActualHours,
+		StoryPoints:    t.StoryPoints,
 		Labels:       t.Labels,
 		CustomFields: t.CustomFields,
+		BoardRank:    t.BoardRank,
 		CreatedAt:    t.CreatedAt,
 		UpdatedAt:    t.UpdatedAt,
 	}
@@ -191,7 +279,7 @@ func (t *Ticket) ToResponse() TicketResponse {
 
 // IsOpen checks if the ticket is in an open state
 func (t *Ticket) IsOpen() bool {
-	return t.Status == StatusOpen || t.Status == StatusInProgress
+	return t.Status == StatusOpen || t.Status == StatusInProgress || t.Status == StatusOnHold
 }
 
 // IsResolved checks if the ticket is resolved
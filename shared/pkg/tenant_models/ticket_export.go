@@ -0,0 +1,128 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson" // One JSON ticket per line, comments nested
+	ExportFormatXLSX   ExportFormat = "xlsx"
+)
+
+type ExportFrequency string
+
+const (
+	ExportDaily  ExportFrequency = "daily"
+	ExportWeekly ExportFrequency = "weekly"
+)
+
+// TicketExportFilter mirrors the ticket list filters
+type TicketExportFilter struct {
+	Status     string     `json:"status,omitempty"`
+	Priority   string     `json:"priority,omitempty"`
+	Type       string     `json:"type,omitempty"`
+	AssigneeID string     `json:"assignee_id,omitempty"`
+	ReporterID string     `json:"reporter_id,omitempty"`
+	ProjectID  string     `json:"project_id,omitempty"`
+	GroupID    string     `json:"group_id,omitempty"`
+	Category   string     `json:"category,omitempty"`
+	DateFrom   *time.Time `json:"date_from,omitempty"`
+	DateTo     *time.Time `json:"date_to,omitempty"`
+
+	// CreatedWithinDays keeps scheduled reports rolling: only tickets created in the
+	// last N days before each run. Ignored when DateFrom is set.
+	CreatedWithinDays int `json:"created_within_days,omitempty" binding:"omitempty,min=1,max=366"`
+}
+
+// ScheduledExport is a saved ticket export that runs daily or weekly and delivers the
+// file to its recipients as a download link
+type ScheduledExport struct {
+	ID              string              `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name            string              `json:"name" gorm:"not null;size:255"`
+	Format          ExportFormat        `json:"format" gorm:"type:varchar(10);not null"`
+	Filter          *TicketExportFilter `json:"filter" gorm:"type:jsonb;serializer:json"`
+	IncludeComments bool                `json:"include_comments" gorm:"default:false"`
+
+	// Schedule, in the export's timezone
+	Frequency ExportFrequency `json:"frequency" gorm:"type:varchar(10);not null"`
+	Weekday   int             `json:"weekday" gorm:"default:1"` // 0 is Sunday; weekly exports only
+	Hour      int             `json:"hour" gorm:"default:6"`
+	Timezone  string          `json:"timezone" gorm:"size:100;default:'UTC'"`
+
+	Recipients models.StringArray `json:"recipients" gorm:"type:jsonb;default:'[]'"` // Addresses emailed a download link
+	Enabled    bool               `json:"enabled" gorm:"default:true"`
+
+	NextRunAt time.Time  `json:"next_run_at" gorm:"not null;index"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	LastError string     `json:"last_error" gorm:"type:text"`
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null;index"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ExportFile is a finished export kept for download until it expires
+type ExportFile struct {
+	ID                string       `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ScheduledExportID *string      `json:"scheduled_export_id" gorm:"type:uuid;index"`
+	Format            ExportFormat `json:"format" gorm:"type:varchar(10);not null"`
+	Filename          string       `json:"filename" gorm:"not null;size:255"` // Name the file downloads as
+	FilePath          string       `json:"-" gorm:"not null;size:500"`
+	Size              int64        `json:"size"`
+	Tickets           int          `json:"tickets"`
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null"`
+
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ScheduledExportCreateRequest struct {
+	Name            string              `json:"name" binding:"required,min=1,max=255"`
+	Format          ExportFormat        `json:"format" binding:"required,oneof=csv ndjson xlsx"`
+	Filter          *TicketExportFilter `json:"filter,omitempty"`
+	IncludeComments bool                `json:"include_comments,omitempty"`
+	Frequency       ExportFrequency     `json:"frequency" binding:"required,oneof=daily weekly"`
+	Weekday         *int                `json:"weekday,omitempty" binding:"omitempty,min=0,max=6"`
+	Hour            *int                `json:"hour,omitempty" binding:"omitempty,min=0,max=23"`
+	Timezone        string              `json:"timezone,omitempty" binding:"omitempty,max=100"`
+	Recipients      []string            `json:"recipients,omitempty" binding:"omitempty,max=50,dive,email"`
+}
+
+type ScheduledExportUpdateRequest struct {
+	Name            *string             `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Format          *ExportFormat       `json:"format,omitempty" binding:"omitempty,oneof=csv ndjson xlsx"`
+	Filter          *TicketExportFilter `json:"filter,omitempty"`
+	IncludeComments *bool               `json:"include_comments,omitempty"`
+	Frequency       *ExportFrequency    `json:"frequency,omitempty" binding:"omitempty,oneof=daily weekly"`
+	Weekday         *int                `json:"weekday,omitempty" binding:"omitempty,min=0,max=6"`
+	Hour            *int                `json:"hour,omitempty" binding:"omitempty,min=0,max=23"`
+	Timezone        *string             `json:"timezone,omitempty" binding:"omitempty,max=100"`
+	Recipients      *[]string           `json:"recipients,omitempty" binding:"omitempty,max=50,dive,email"`
+	Enabled         *bool               `json:"enabled,omitempty"`
+}
+
+// TicketExportRecord is one line of an NDJSON export
+type TicketExportRecord struct {
+	TicketResponse
+	Comments []*Comment `json:"comments,omitempty"`
+}
+
+// TableName overrides the table name used by ScheduledExport to `scheduled_exports`
+func (ScheduledExport) TableName() string {
+	return "scheduled_exports"
+}
+
+// TableName overrides the table name used by ExportFile to `export_files`
+func (ExportFile) TableName() string {
+	return "export_files"
+}
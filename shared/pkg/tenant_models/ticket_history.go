@@ -15,6 +15,8 @@ const (
 	ChangeTypeReopen     ChangeType = "reopen"
 	ChangeTypeComment    ChangeType = "comment"
 	ChangeTypeAttachment ChangeType = "attachment"
+	ChangeTypeLink       ChangeType = "link"
+	ChangeTypeUnlink     ChangeType = "unlink"
 )
 
 type TicketHistory struct {
@@ -58,6 +60,22 @@ type TicketHistoryResponse struct {
 	ChangedAt  time.Time  `json:"changed_at"`
 }
 
+type TimelineEntryType string
+
+const (
+	TimelineEntryChange  TimelineEntryType = "change"
+	TimelineEntryComment TimelineEntryType = "comment"
+)
+
+// TicketTimelineEntry is one item of a ticket's merged change and comment timeline
+type TicketTimelineEntry struct {
+	Type    TimelineEntryType      `json:"type"`
+	ActorID string                 `json:"actor_id"`
+	At      time.Time              `json:"at"`
+	Change  *TicketHistoryResponse `json:"change,omitempty"`
+	Comment *CommentResponse       `json:"comment,omitempty"`
+}
+
 // TableName overrides the table name used by TicketHistory to `ticket_history`
 func (TicketHistory) TableName() string {
 	return "ticket_history"
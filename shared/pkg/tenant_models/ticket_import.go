@@ -0,0 +1,205 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+)
+
+type ImportFormat string
+
+const (
+	ImportFormatCSV  ImportFormat = "csv"  // One ticket per row, columns named by the mapping
+	ImportFormatJSON ImportFormat = "json" // {"tickets": [ImportTicket, ...]}, or the bare array
+)
+
+type ImportJobStatus string
+
+const (
+	ImportJobQueued    ImportJobStatus = "queued"
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobCompleted ImportJobStatus = "completed" // Finished, possibly with row errors
+	ImportJobFailed    ImportJobStatus = "failed"    // Stopped early; see Error. Can be resumed.
+)
+
+// ImportJob tracks one import of tickets from another helpdesk's export. Processed is
+// also the resume point: a resumed job continues with the row after it.
+type ImportJob struct {
+	ID     string          `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Format ImportFormat    `json:"format" gorm:"type:varchar(10);not null"`
+	DryRun bool            `json:"dry_run" gorm:"default:false"` // Validate every row without creating tickets
+	Status ImportJobStatus `json:"status" gorm:"type:varchar(20);not null;default:'queued'"`
+
+	// Uploaded file, kept until the job completes so it can be resumed
+	OriginalFilename string         `json:"original_filename" gorm:"size:255"`
+	FilePath         string         `json:"-" gorm:"size:500"`
+	Mapping          *ImportMapping `json:"mapping" gorm:"type:jsonb;serializer:json"`
+
+	// Progress
+	Total     int              `json:"total"`
+	Processed int              `json:"processed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Error     string           `json:"error" gorm:"type:text"`
+	Errors    []ImportRowError `json:"errors" gorm:"type:jsonb;serializer:json"` // First MaxImportRowErrors only
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null;index"`
+
+	// Timestamps
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// MaxImportRowErrors bounds the row errors a job keeps; Failed still counts them all
+const MaxImportRowErrors = 1000
+
+// ImportRowError reports why a row was not imported. Rows count from 1 and, in CSV
+// files, do not include the header.
+type ImportRowError struct {
+	Row        int    `json:"row"`
+	ExternalID string `json:"external_id,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Message    string `json:"message"`
+}
+
+// ImportRecord remembers which ticket a row became, so resumed and repeated imports
+// never create a ticket twice
+type ImportRecord struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	JobID      string    `json:"job_id" gorm:"type:uuid;not null;uniqueIndex:idx_import_records_job_row"`
+	RowNumber  int       `json:"row" gorm:"not null;uniqueIndex:idx_import_records_job_row"`
+	ExternalID string    `json:"external_id" gorm:"size:255;index"` // ID in the source helpdesk
+	TicketID   string    `json:"ticket_id" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ImportMapping describes how the source helpdesk's data maps onto tickets.
+//
+// Columns maps ticket fields to CSV column headers and is required for CSV files. The
+// fields are external_id, title, description, status, priority, ticket_type, category,
+// requester_email, requester_name, assignee_email, project_id, labels, channel,
+// language, created_at, updated_at, resolved_at, closed_at and due_date; custom fields
+// are mapped as "custom_fields.<name>".
+//
+// StatusMap and PriorityMap rename source values before they are matched, by name and
+// ignoring case, against built-in and custom statuses and priorities.
+type ImportMapping struct {
+	Columns         map[string]string `json:"columns,omitempty"`
+	StatusMap       map[string]string `json:"status_map,omitempty"`
+	PriorityMap     map[string]string `json:"priority_map,omitempty"`
+	TimeFormat      string            `json:"time_format,omitempty"`      // Go layout for CSV times; RFC 3339 and common layouts are tried otherwise
+	LabelSeparator  string            `json:"label_separator,omitempty"`  // Splits the CSV labels column; defaults to ","
+	UnassignUnknown bool              `json:"unassign_unknown,omitempty"` // Import tickets whose assignee is not a member unassigned instead of failing them
+}
+
+// ImportTicket is one ticket of the JSON import format. Users are matched to tenant
+// members by email; authors who are not members are kept by address only.
+type ImportTicket struct {
+	ExternalID     string             `json:"external_id,omitempty"`
+	Title          string             `json:"title"`
+	Description    string             `json:"description,omitempty"`
+	Status         string             `json:"status,omitempty"`
+	Priority       string             `json:"priority,omitempty"`
+	TicketType     string             `json:"ticket_type,omitempty"`
+	Category       string             `json:"category,omitempty"`
+	RequesterEmail string             `json:"requester_email,omitempty"`
+	RequesterName  string             `json:"requester_name,omitempty"`
+	AssigneeEmail  string             `json:"assignee_email,omitempty"`
+	ProjectID      string             `json:"project_id,omitempty"`
+	Labels         []string           `json:"labels,omitempty"`
+	CustomFields   models.JSONB       `json:"custom_fields,omitempty"`
+	Channel        string             `json:"channel,omitempty"`
+	Language       string             `json:"language,omitempty"`
+	CreatedAt      *time.Time         `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time         `json:"updated_at,omitempty"`
+	ResolvedAt     *time.Time         `json:"resolved_at,omitempty"`
+	ClosedAt       *time.Time         `json:"closed_at,omitempty"`
+	DueDate        *time.Time         `json:"due_date,omitempty"`
+	Comments       []ImportComment    `json:"comments,omitempty"`
+	Attachments    []ImportAttachment `json:"attachments,omitempty"`
+}
+
+type ImportComment struct {
+	AuthorEmail string             `json:"author_email,omitempty"`
+	Body        string             `json:"body"`
+	IsInternal  bool               `json:"is_internal,omitempty"`
+	CreatedAt   *time.Time         `json:"created_at,omitempty"`
+	Attachments []ImportAttachment `json:"attachments,omitempty"`
+}
+
+// ImportAttachment references a file kept by the source helpdesk. Only the reference is
+// imported; the file stays where the URL points.
+type ImportAttachment struct {
+	Filename  string     `json:"filename"`
+	URL       string     `json:"url"`
+	MimeType  string     `json:"mime_type,omitempty"`
+	Size      int64      `json:"size,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// ImportRequest accompanies the uploaded file
+type ImportRequest struct {
+	Format  ImportFormat   `json:"format"`
+	DryRun  bool           `json:"dry_run"`
+	Async   bool           `json:"async"` // Large files run in the background regardless
+	Mapping *ImportMapping `json:"mapping"`
+}
+
+type ImportJobResponse struct {
+	ID               string           `json:"id"`
+	Format           ImportFormat     `json:"format"`
+	DryRun           bool             `json:"dry_run"`
+	Status           ImportJobStatus  `json:"status"`
+	OriginalFilename string           `json:"original_filename"`
+	Total            int              `json:"total"`
+	Processed        int              `json:"processed"`
+	Succeeded        int              `json:"succeeded"`
+	Failed           int              `json:"failed"`
+	Progress         float64          `json:"progress"` // Percentage processed
+	Error            string           `json:"error,omitempty"`
+	Errors           []ImportRowError `json:"errors"`
+	CreatedBy        string           `json:"created_by"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	CompletedAt      *time.Time       `json:"completed_at"`
+}
+
+// TableName overrides the table name used by ImportJob to `import_jobs`
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
+
+// TableName overrides the table name used by ImportRecord to `import_records`
+func (ImportRecord) TableName() string {
+	return "import_records"
+}
+
+// ToResponse converts an ImportJob model to ImportJobResponse
+func (j *ImportJob) ToResponse() ImportJobResponse {
+	response := ImportJobResponse{
+		ID:               j.ID,
+		Format:           j.Format,
+		DryRun:           j.DryRun,
+		Status:           j.Status,
+		OriginalFilename: j.OriginalFilename,
+		Total:            j.Total,
+		Processed:        j.Processed,
+		Succeeded:        j.Succeeded,
+		Failed:           j.Failed,
+		Error:            j.Error,
+		Errors:           j.Errors,
+		CreatedBy:        j.CreatedBy,
+		CreatedAt:        j.CreatedAt,
+		UpdatedAt:        j.UpdatedAt,
+		CompletedAt:      j.CompletedAt,
+	}
+	if response.Errors == nil {
+		response.Errors = []ImportRowError{}
+	}
+	if j.Total > 0 {
+		response.Progress = float64(j.Processed) / float64(j.Total) * 100
+	}
+	return response
+}
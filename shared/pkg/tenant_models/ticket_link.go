@@ -0,0 +1,160 @@
+package tenant_models
+
+import (
+	"time"
+)
+
+// TicketLinkType is how a link is stored, always read from source to target
+type TicketLinkType string
+
+const (
+	LinkTypeSubtask    TicketLinkType = "subtask"    // Source is the parent of target; mirrored in target.ParentTicketID
+	LinkTypeBlocks     TicketLinkType = "blocks"     // Source must be resolved before target can proceed
+	LinkTypeDuplicates TicketLinkType = "duplicates" // Source duplicates the canonical target
+	LinkTypeRelatesTo  TicketLinkType = "relates_to" // Symmetric; stored once
+)
+
+// TicketRelation is a link as seen from one of its tickets, read as "this ticket <relation> the other"
+type TicketRelation string
+
+const (
+	RelationParentOf     TicketRelation = "parent_of"
+	RelationChildOf      TicketRelation = "child_of"
+	RelationBlocks       TicketRelation = "blocks"
+	RelationBlockedBy    TicketRelation = "blocked_by"
+	RelationDuplicateOf  TicketRelation = "duplicate_of"
+	RelationDuplicatedBy TicketRelation = "duplicated_by"
+	RelationRelatesTo    TicketRelation = "relates_to"
+)
+
+// Stored returns the stored link type for the relation and whether the ticket the
+// relation is seen from is the link's source
+func (r TicketRelation) Stored() (TicketLinkType, bool, bool) {
+	switch r {
+	case RelationParentOf:
+		return LinkTypeSubtask, true, true
+	case RelationChildOf:
+		return LinkTypeSubtask, false, true
+	case RelationBlocks:
+		return LinkTypeBlocks, true, true
+	case RelationBlockedBy:
+		return LinkTypeBlocks, false, true
+	case RelationDuplicateOf:
+		return LinkTypeDuplicates, true, true
+	case RelationDuplicatedBy:
+		return LinkTypeDuplicates, false, true
+	case RelationRelatesTo:
+		return LinkTypeRelatesTo, true, true
+	}
+	return "", false, false
+}
+
+type TicketLink struct {
+	ID             string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	SourceTicketID string         `json:"source_ticket_id" gorm:"type:uuid;not null;uniqueIndex:idx_ticket_links_pair"`
+	TargetTicketID string         `json:"target_ticket_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_ticket_links_pair"`
+	LinkType       TicketLinkType `json:"link_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_ticket_links_pair"`
+
+	// Loaded for listings; nil when the linked ticket has been deleted
+	SourceTicket *Ticket `json:"-" gorm:"foreignKey:SourceTicketID"`
+	TargetTicket *Ticket `json:"-" gorm:"foreignKey:TargetTicketID"`
+
+	// Creator (References Master DB users.id)
+	CreatedBy string `json:"created_by" gorm:"type:uuid;not null"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TicketLinkCreateRequest struct {
+	Relation       TicketRelation `json:"relation" binding:"required,oneof=parent_of child_of blocks blocked_by duplicate_of duplicated_by relates_to"`
+	TargetTicketID string         `json:"target_ticket_id" binding:"required,uuid"`
+}
+
+// TicketLinkResponse describes a link from the point of view of the ticket it was listed for
+type TicketLinkResponse struct {
+	ID        string              `json:"id"`
+	Relation  TicketRelation      `json:"relation"`
+	Ticket    LinkedTicketSummary `json:"ticket"`
+	CreatedBy string              `json:"created_by"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+type LinkedTicketSummary struct {
+	ID           string         `json:"id"`
+	TicketNumber int            `json:"ticket_number"`
+	Title        string         `json:"title"`
+	Status       TicketStatus   `json:"status"`
+	Priority     TicketPriority `json:"priority"`
+	AssigneeID   *string        `json:"assignee_id"`
+	Resolved     bool           `json:"resolved"`
+}
+
+// SubtaskProgress rolls a parent's children up into one figure
+type SubtaskProgress struct {
+	Total          int      `json:"total"`
+	Resolved       int      `json:"resolved"`
+	Percentage     float64  `json:"percentage"`
+	EstimatedHours *float64 `json:"estimated_hours"` // Sum over children that have an estimate
+	ActualHours    *float64 `json:"actual_hours"`
+}
+
+type TicketLinksResponse struct {
+	Links    []TicketLinkResponse `json:"links"`
+	Subtasks *SubtaskProgress     `json:"subtasks,omitempty"` // Only for tickets with children
+}
+
+// TableName overrides the table name used by TicketLink to `ticket_links`
+func (TicketLink) TableName() string {
+	return "ticket_links"
+}
+
+// RelationFor returns the link's relation as seen from ticketID
+func (l *TicketLink) RelationFor(ticketID string) TicketRelation {
+	fromSource := l.SourceTicketID == ticketID
+	switch l.LinkType {
+	case LinkTypeSubtask:
+		if fromSource {
+			return RelationParentOf
+		}
+		return RelationChildOf
+	case LinkTypeBlocks:
+		if fromSource {
+			return RelationBlocks
+		}
+		return RelationBlockedBy
+	case LinkTypeDuplicates:
+		if fromSource {
+			return RelationDuplicateOf
+		}
+		return RelationDuplicatedBy
+	}
+	return RelationRelatesTo
+}
+
+// OtherTicket returns the linked ticket on the far side from ticketID
+func (l *TicketLink) OtherTicket(ticketID string) *Ticket {
+	if l.SourceTicketID == ticketID {
+		return l.TargetTicket
+	}
+	return l.SourceTicket
+}
+
+// ToResponse converts the link as seen from ticketID; other is the ticket on the far side
+func (l *TicketLink) ToResponse(ticketID string, other *Ticket) TicketLinkResponse {
+	return TicketLinkResponse{
+		ID:       l.ID,
+		Relation: l.RelationFor(ticketID),
+		Ticket: LinkedTicketSummary{
+			ID:           other.ID,
+			TicketNumber: other.TicketNumber,
+			Title:        other.Title,
+			Status:       other.Status,
+			Priority:     other.Priority,
+			AssigneeID:   other.AssigneeID,
+			Resolved:     other.IsResolved() || other.ResolvedAt != nil,
+		},
+		CreatedBy: l.CreatedBy,
+		CreatedAt: l.CreatedAt,
+	}
+}
@@ -0,0 +1,132 @@
+package tenant_models
+
+import (
+	"time"
+
+	"github.com/zen/shared/pkg/models"
+	"gorm.io/gorm"
+)
+
+// Workflow defines which status changes are legal for a set of tickets.
+// The most specific active workflow wins: project and type, then project, then
+// type, then the tenant default with neither set.
+type Workflow struct {
+	ID          string  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string  `json:"name" gorm:"not null;size:255"`
+	Description string  `json:"description" gorm:"type:text"`
+	ProjectID   *string `json:"project_id" gorm:"type:uuid;index"`
+	TicketType  *string `json:"ticket_type" gorm:"size:50"`
+
+	// Status given to new tickets; empty keeps the default of "open"
+	InitialStatus string `json:"initial_status" gorm:"size:100"`
+	IsActive      bool   `json:"is_active" gorm:"default:true"`
+
+	Transitions []WorkflowTransition `json:"transitions" gorm:"foreignKey:WorkflowID"`
+
+	// Timestamps
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// WorkflowTransition allows moving a ticket from one status to another
+type WorkflowTransition struct {
+	ID         string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	WorkflowID string `json:"workflow_id" gorm:"type:uuid;not null;index"`
+	Name       string `json:"name" gorm:"size:100"`
+	FromStatus string `json:"from_status" gorm:"size:100"` // Empty allows the transition from any status
+	ToStatus   string `json:"to_status" gorm:"not null;size:100"`
+
+	// Ticket fields that must be set once the transition is applied, e.g. "resolution"
+	// or "custom_fields.root_cause"
+	RequiredFields models.StringArray `json:"required_fields" gorm:"type:jsonb;default:'[]'"`
+	// Tenant roles that may take the transition, higher roles included; empty allows
+	// anyone who can edit the ticket
+	AllowedRoles models.StringArray `json:"allowed_roles" gorm:"type:jsonb;default:'[]'"`
+	SortOrder    int                `json:"sort_order" gorm:"default:0"`
+}
+
+type WorkflowTransitionRequest struct {
+	Name           string   `json:"name,omitempty" binding:"omitempty,max=100"`
+	FromStatus     string   `json:"from_status,omitempty"`
+	ToStatus       string   `json:"to_status" binding:"required"`
+	RequiredFields []string `json:"required_fields,omitempty"`
+	AllowedRoles   []string `json:"allowed_roles,omitempty"`
+}
+
+type WorkflowCreateRequest struct {
+	Name          string                      `json:"name" binding:"required,min=1,max=255"`
+	Description   string                      `json:"description,omitempty"`
+	ProjectID     *string                     `json:"project_id,omitempty" binding:"omitempty,uuid"`
+	TicketType    *string                     `json:"ticket_type,omitempty"`
+	InitialStatus string                      `json:"initial_status,omitempty"`
+	Transitions   []WorkflowTransitionRequest `json:"transitions" binding:"required,min=1,dive"`
+}
+
+type WorkflowUpdateRequest struct {
+	Name          *string                     `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description   *string                     `json:"description,omitempty"`
+	InitialStatus *string                     `json:"initial_status,omitempty"`
+	IsActive      *bool                       `json:"is_active,omitempty"`
+	Transitions   []WorkflowTransitionRequest `json:"transitions,omitempty" binding:"omitempty,dive"` // Replaces all transitions when set
+}
+
+// TicketTransitionRequest moves a ticket to a new status along with the fields the transition may require
+type TicketTransitionRequest struct {
+	Status       TicketStatus      `json:"status" binding:"required"`
+	Resolution   *TicketResolution `json:"resolution,omitempty"`
+	AssigneeID   *string           `json:"assignee_id,omitempty" binding:"omitempty,uuid"`
+	CustomFields *models.JSONB     `json:"custom_fields,omitempty"`
+}
+
+// TicketTransitionOption is a status the ticket can legally move to next
+type TicketTransitionOption struct {
+	Name           string         `json:"name"`
+	ToStatus       string         `json:"to_status"`
+	Category       StatusCategory `json:"category"`
+	RequiredFields []string       `json:"required_fields"`
+}
+
+type WorkflowResponse struct {
+	ID            string               `json:"id"`
+	Name          string               `json:"name"`
+	Description   string               `json:"description"`
+	ProjectID     *string              `json:"project_id"`
+	TicketType    *string              `json:"ticket_type"`
+	InitialStatus string               `json:"initial_status"`
+	IsActive      bool                 `json:"is_active"`
+	Transitions   []WorkflowTransition `json:"transitions"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// TableName overrides the table name used by Workflow to `workflows`
+func (Workflow) TableName() string {
+	return "workflows"
+}
+
+// TableName overrides the table name used by WorkflowTransition to `workflow_transitions`
+func (WorkflowTransition) TableName() string {
+	return "workflow_transitions"
+}
+
+// Allows reports whether the transition applies when leaving status from
+func (wt *WorkflowTransition) Allows(from, to string) bool {
+	return wt.ToStatus == to && (wt.FromStatus == "" || wt.FromStatus == from)
+}
+
+// ToResponse converts a Workflow model to WorkflowResponse
+func (w *Workflow) ToResponse() WorkflowResponse {
+	return WorkflowResponse{
+		ID:            w.ID,
+		Name:          w.Name,
+		Description:   w.Description,
+		ProjectID:     w.ProjectID,
+		TicketType:    w.TicketType,
+		InitialStatus: w.InitialStatus,
+		IsActive:      w.IsActive,
+		Transitions:   w.Transitions,
+		CreatedAt:     w.CreatedAt,
+		UpdatedAt:     w.UpdatedAt,
+	}
+}
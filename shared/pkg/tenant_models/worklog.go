@@ -0,0 +1,117 @@
+package tenant_models
+
+import (
+	"time"
+)
+
+// Worklog is one session of work logged on a ticket. A ticket's ActualHours is the sum
+// of its worklogs.
+type Worklog struct {
+	ID              string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TicketID        string    `json:"ticket_id" gorm:"type:uuid;not null;index"`
+	UserID          string    `json:"user_id" gorm:"type:uuid;not null;index:idx_worklogs_user_started"` // References Master DB users.id
+	StartedAt       time.Time `json:"started_at" gorm:"not null;index:idx_worklogs_user_started"`
+	DurationMinutes int       `json:"duration_minutes" gorm:"not null"`
+	Billable        bool      `json:"billable" gorm:"default:false"`
+	Note            string    `json:"note" gorm:"type:text"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkTimer is a user's running timer; stopping it logs the time as a Worklog
+type WorkTimer struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string    `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"` // One running timer per user
+	TicketID  string    `json:"ticket_id" gorm:"type:uuid;not null"`
+	StartedAt time.Time `json:"started_at" gorm:"not null"`
+	Billable  bool      `json:"billable" gorm:"default:false"`
+	Note      string    `json:"note" gorm:"type:text"`
+}
+
+type WorklogCreateRequest struct {
+	StartedAt       time.Time `json:"started_at" binding:"required"`
+	DurationMinutes int       `json:"duration_minutes" binding:"required,min=1,max=1440"`
+	Billable        bool      `json:"billable,omitempty"`
+	Note            string    `json:"note,omitempty" binding:"omitempty,max=2000"`
+}
+
+type WorklogUpdateRequest struct {
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	DurationMinutes *int       `json:"duration_minutes,omitempty" binding:"omitempty,min=1,max=1440"`
+	Billable        *bool      `json:"billable,omitempty"`
+	Note            *string    `json:"note,omitempty" binding:"omitempty,max=2000"`
+}
+
+type TimerStartRequest struct {
+	TicketID string `json:"ticket_id" binding:"required,uuid"`
+	Billable bool   `json:"billable,omitempty"`
+	Note     string `json:"note,omitempty" binding:"omitempty,max=2000"`
+}
+
+// Timesheet is a user's logged time for one week, Monday to Sunday in UTC
+type Timesheet struct {
+	UserID          string          `json:"user_id"`
+	WeekStart       string          `json:"week_start"` // YYYY-MM-DD
+	WeekEnd         string          `json:"week_end"`
+	Days            []TimesheetDay  `json:"days"`
+	Tickets         []TimesheetLine `json:"tickets"`
+	TotalMinutes    int             `json:"total_minutes"`
+	BillableMinutes int             `json:"billable_minutes"`
+}
+
+type TimesheetDay struct {
+	Date            string `json:"date"`
+	TotalMinutes    int    `json:"total_minutes"`
+	BillableMinutes int    `json:"billable_minutes"`
+}
+
+// TimesheetLine is the time logged on one ticket, per day of the week
+type TimesheetLine struct {
+	TicketID     string  `json:"ticket_id"`
+	TicketNumber int     `json:"ticket_number"`
+	Title        string  `json:"title"`
+	ProjectID    *string `json:"project_id"`
+	DailyMinutes [7]int  `json:"daily_minutes"`
+	TotalMinutes int     `json:"total_minutes"`
+}
+
+// ProjectTimeRollup compares a project's logged time against its ticket estimates
+type ProjectTimeRollup struct {
+	ProjectID           string             `json:"project_id"`
+	EstimatedHours      float64            `json:"estimated_hours"`
+	LoggedHours         float64            `json:"logged_hours"`
+	BillableHours       float64            `json:"billable_hours"`
+	RemainingHours      float64            `json:"remaining_hours"`          // Estimated hours not yet logged on each ticket
+	EstimatedTickets    int                `json:"estimated_tickets"`        // Tickets with an estimate
+	OverEstimateTickets int                `json:"over_estimate_tickets"`    // Tickets logged past their estimate
+	UnestimatedLogged   float64            `json:"unestimated_logged_hours"` // Hours logged on tickets without an estimate
+	Users               []UserTimeRollup   `json:"users"`
+	Tickets             []TicketTimeRollup `json:"tickets"`
+}
+
+type UserTimeRollup struct {
+	UserID        string  `json:"user_id"`
+	LoggedHours   float64 `json:"logged_hours"`
+	BillableHours float64 `json:"billable_hours"`
+}
+
+type TicketTimeRollup struct {
+	TicketID       string       `json:"ticket_id"`
+	TicketNumber   int          `json:"ticket_number"`
+	Title          string       `json:"title"`
+	Status         TicketStatus `json:"status"`
+	EstimatedHours *float64     `json:"estimated_hours"`
+	LoggedHours    float64      `json:"logged_hours"`
+}
+
+// TableName overrides the table name used by Worklog to `worklogs`
+func (Worklog) TableName() string {
+	return "worklogs"
+}
+
+// TableName overrides the table name used by WorkTimer to `work_timers`
+func (WorkTimer) TableName() string {
+	return "work_timers"
+}
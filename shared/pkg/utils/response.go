@@ -49,6 +49,17 @@ func CreatedResponse(c *gin.Context, data interface{}, message ...string) {
 	c.JSON(http.StatusCreated, resp)
 }
 
+func AcceptedResponse(c *gin.Context, data interface{}, message ...string) {
+	resp := Response{
+		Success: true,
+		Data:    data,
+	}
+	if len(message) > 0 {
+		resp.Message = message[0]
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
 func ErrorResponse(c *gin.Context, statusCode int, err string) {
 	resp := Response{
 		Success: false,